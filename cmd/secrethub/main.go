@@ -3,12 +3,13 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/secrethub/secrethub-cli/internals/secrethub"
 )
 
 func main() {
-	err := secrethub.NewApp().Version(secrethub.Version, secrethub.Commit).Run(os.Args[1:])
+	err := secrethub.NewApp().Version(secrethub.Version, secrethub.Commit).Run(secrethub.CommandArgs(filepath.Base(os.Args[0]), os.Args[1:]))
 	if err != nil {
 		handleError(err)
 	}
@@ -0,0 +1,124 @@
+// Package ansiblevault decrypts data encrypted with Ansible Vault.
+//
+// Both encrypted files and encrypted variables (the !vault tagged values in
+// otherwise plain YAML) use the same format: a header line followed by the
+// hex encoded salt, HMAC and ciphertext. Versions 1.1 and 1.2 of the format
+// with the AES256 cipher are supported.
+package ansiblevault
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/secrethub/secrethub-go/internals/errio"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Errors
+var (
+	errVault = errio.Namespace("ansible_vault")
+
+	ErrInvalidFormat      = errVault.Code("invalid_format").Error("the data is not encrypted with Ansible Vault or is corrupt")
+	ErrUnsupportedVersion = errVault.Code("unsupported_version").ErrorPref("Ansible Vault version %s is not supported")
+	ErrUnsupportedCipher  = errVault.Code("unsupported_cipher").ErrorPref("the Ansible Vault cipher %s is not supported")
+	ErrInvalidPassword    = errVault.Code("invalid_password").Error("the vault password is incorrect")
+)
+
+const (
+	// Header is the prefix of all data encrypted with Ansible Vault.
+	Header = "$ANSIBLE_VAULT"
+
+	keyIterations = 10000
+	keyLength     = 32
+)
+
+// IsEncrypted returns whether the data is encrypted with Ansible Vault.
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte(Header+";"))
+}
+
+// Decrypt decrypts data encrypted with Ansible Vault using the given password.
+func Decrypt(data []byte, password []byte) ([]byte, error) {
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+
+	header := strings.Split(strings.TrimSpace(lines[0]), ";")
+	if len(header) < 3 || header[0] != Header {
+		return nil, ErrInvalidFormat
+	}
+	if header[1] != "1.1" && header[1] != "1.2" {
+		return nil, ErrUnsupportedVersion(header[1])
+	}
+	if strings.TrimSpace(header[2]) != "AES256" {
+		return nil, ErrUnsupportedCipher(header[2])
+	}
+
+	var body strings.Builder
+	for _, line := range lines[1:] {
+		body.WriteString(strings.TrimSpace(line))
+	}
+
+	// The body is hex encoded twice: the salt, HMAC and ciphertext are
+	// hex encoded, joined by newlines and then hex encoded as a whole.
+	decoded, err := hex.DecodeString(body.String())
+	if err != nil {
+		return nil, ErrInvalidFormat
+	}
+	parts := strings.Split(string(decoded), "\n")
+	if len(parts) != 3 {
+		return nil, ErrInvalidFormat
+	}
+	salt, err := hex.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidFormat
+	}
+	mac, err := hex.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidFormat
+	}
+	ciphertext, err := hex.DecodeString(parts[2])
+	if err != nil || len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, ErrInvalidFormat
+	}
+
+	cipherKey, hmacKey, iv := deriveKeys(password, salt)
+
+	h := hmac.New(sha256.New, hmacKey)
+	h.Write(ciphertext)
+	if !hmac.Equal(h.Sum(nil), mac) {
+		return nil, ErrInvalidPassword
+	}
+
+	block, err := aes.NewCipher(cipherKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCTR(block, iv).XORKeyStream(plaintext, ciphertext)
+
+	return unpad(plaintext)
+}
+
+// deriveKeys derives the AES key, the HMAC key and the IV from the password.
+func deriveKeys(password, salt []byte) ([]byte, []byte, []byte) {
+	key := pbkdf2.Key(password, salt, keyIterations, 2*keyLength+aes.BlockSize, sha256.New)
+	return key[:keyLength], key[keyLength : 2*keyLength], key[2*keyLength:]
+}
+
+// unpad removes the PKCS#7 padding from the plaintext.
+func unpad(plaintext []byte) ([]byte, error) {
+	n := int(plaintext[len(plaintext)-1])
+	if n == 0 || n > aes.BlockSize || n > len(plaintext) {
+		return nil, ErrInvalidFormat
+	}
+	for _, b := range plaintext[len(plaintext)-n:] {
+		if int(b) != n {
+			return nil, ErrInvalidFormat
+		}
+	}
+	return plaintext[:len(plaintext)-n], nil
+}
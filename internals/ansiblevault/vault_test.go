@@ -0,0 +1,84 @@
+package ansiblevault
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+// vault is "db_password: s3cr3t\n" encrypted with the password "correct horse battery staple".
+const vault = `$ANSIBLE_VAULT;1.1;AES256
+30303031303230333034303530363037303830393061306230633064306530663130313131323133
+3134313531363137313831393161316231633164316531660a636463643461653235326661383432
+35643062346236636335306166303864376431396562316664313564373861386532656238326335
+6139353234303132630a316333376266333333626231326530633431303535383266646534336634
+36643932636330643036646461363866646539663734316635323334656531643531
+`
+
+func TestDecrypt(t *testing.T) {
+	password := []byte("correct horse battery staple")
+
+	cases := map[string]struct {
+		data     string
+		password []byte
+		expected string
+		err      error
+	}{
+		"file": {
+			data:     vault,
+			password: password,
+			expected: "db_password: s3cr3t\n",
+		},
+		"version 1.2 with vault id": {
+			data:     strings.Replace(vault, "1.1;AES256", "1.2;AES256;prod", 1),
+			password: password,
+			expected: "db_password: s3cr3t\n",
+		},
+		"indented variable": {
+			data:     strings.Replace(vault, "\n", "\n          ", -1),
+			password: password,
+			expected: "db_password: s3cr3t\n",
+		},
+		"wrong password": {
+			data:     vault,
+			password: []byte("wrong"),
+			err:      ErrInvalidPassword,
+		},
+		"not encrypted": {
+			data:     "db_password: s3cr3t\n",
+			password: password,
+			err:      ErrInvalidFormat,
+		},
+		"unsupported version": {
+			data:     strings.Replace(vault, "1.1", "1.0", 1),
+			password: password,
+			err:      ErrUnsupportedVersion("1.0"),
+		},
+		"unsupported cipher": {
+			data:     strings.Replace(vault, "AES256", "AES", 1),
+			password: password,
+			err:      ErrUnsupportedCipher("AES"),
+		},
+		"corrupt body": {
+			data:     strings.Replace(vault, "3030", "zz", 1),
+			password: password,
+			err:      ErrInvalidFormat,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := Decrypt([]byte(tc.data), tc.password)
+
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, string(actual), tc.expected)
+		})
+	}
+}
+
+func TestIsEncrypted(t *testing.T) {
+	assert.Equal(t, IsEncrypted([]byte(vault)), true)
+	assert.Equal(t, IsEncrypted([]byte("  \n"+vault)), true)
+	assert.Equal(t, IsEncrypted([]byte("db_password: s3cr3t\n")), false)
+}
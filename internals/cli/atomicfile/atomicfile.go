@@ -0,0 +1,71 @@
+// Package atomicfile provides a way to replace files on disk so that readers
+// either see the old or the new contents, but never a partially written file.
+package atomicfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile writes data to a temporary file in the same directory as filename
+// and renames it to filename once it has been flushed to disk. The resulting file
+// has the given permissions, regardless of the umask of the current process.
+func WriteFile(filename string, data []byte, perm os.FileMode) error {
+	return write(filename, data, perm, nil)
+}
+
+// WriteFileWithOwner does the same as WriteFile, but also changes the owner of
+// the file to the given uid and gid before it is moved into place.
+func WriteFileWithOwner(filename string, data []byte, perm os.FileMode, uid, gid int) error {
+	return write(filename, data, perm, func(f *os.File) error {
+		return f.Chown(uid, gid)
+	})
+}
+
+func write(filename string, data []byte, perm os.FileMode, prepare func(f *os.File) error) (err error) {
+	dir, base := filepath.Split(filename)
+	if dir == "" {
+		dir = "."
+	}
+
+	tmp, err := ioutil.TempFile(dir, "."+base+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	_, err = tmp.Write(data)
+	if err != nil {
+		return err
+	}
+
+	err = tmp.Chmod(perm)
+	if err != nil {
+		return err
+	}
+
+	if prepare != nil {
+		err = prepare(tmp)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tmp.Sync()
+	if err != nil {
+		return err
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filename)
+}
@@ -0,0 +1,70 @@
+package atomicfile_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/atomicfile"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "atomicfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "file")
+
+	cases := map[string]struct {
+		data []byte
+		perm os.FileMode
+	}{
+		"new file": {
+			data: []byte("first"),
+			perm: 0600,
+		},
+		"overwrite existing file": {
+			data: []byte("second"),
+			perm: 0644,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := atomicfile.WriteFile(filename, tc.data, tc.perm)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			actual, err := ioutil.ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(actual) != string(tc.data) {
+				t.Errorf("unexpected contents: %s (actual) != %s (expected)", actual, tc.data)
+			}
+
+			if runtime.GOOS != "windows" {
+				info, err := os.Stat(filename)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if info.Mode().Perm() != tc.perm {
+					t.Errorf("unexpected file mode: %s (actual) != %s (expected)", info.Mode().Perm(), tc.perm)
+				}
+			}
+
+			files, err := ioutil.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(files) != 1 {
+				t.Errorf("expected only the written file in the directory, found %d files", len(files))
+			}
+		})
+	}
+}
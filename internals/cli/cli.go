@@ -1,46 +1,237 @@
 package cli
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
 	"os"
+	"strings"
+	"sync"
+	"time"
 
-	"github.com/op/go-logging"
+	"github.com/fatih/color"
 )
 
+// Log formats
 const (
-	logFormat = `%{color}%{level:.4s} ▶ %{color:reset} %{message}`
-	logModule = "log"
+	LogFormatText = "text"
+	LogFormatJSON = "json"
 )
 
-// Logger can be used to log debug and warning messages.
+// LogFormats are all supported log formats.
+var LogFormats = []string{LogFormatText, LogFormatJSON}
+
+// ErrUnknownLogFormat is returned when a log format is not supported.
+var ErrUnknownLogFormat = fmt.Errorf("unknown log format: the options are %s", strings.Join(LogFormats, " and "))
+
+// LogLevel is the severity of a log message.
+type LogLevel int
+
+// Log levels
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarning
+	LogLevelError
+)
+
+var logLevelNames = map[LogLevel]string{
+	LogLevelDebug:   "debug",
+	LogLevelInfo:    "info",
+	LogLevelWarning: "warning",
+	LogLevelError:   "error",
+}
+
+var logLevelColors = map[LogLevel]*color.Color{
+	LogLevelDebug:   color.New(color.FgCyan),
+	LogLevelInfo:    color.New(color.FgGreen),
+	LogLevelWarning: color.New(color.FgYellow),
+	LogLevelError:   color.New(color.FgRed),
+}
+
+// String returns the name of the level, e.g. debug.
+func (l LogLevel) String() string {
+	return logLevelNames[l]
+}
+
+// Logger can be used to log leveled messages with key-value fields.
 type Logger interface {
 	// Debugf logs a message when debug mode is enabled.
 	Debugf(format string, args ...interface{})
-	// Warningf logs a message when debug mode is enabled.
+	// Infof logs an informational message.
+	Infof(format string, args ...interface{})
+	// Warningf logs a message about something that might be wrong.
 	Warningf(format string, args ...interface{})
+	// Errorf logs a message about something that failed.
+	Errorf(format string, args ...interface{})
+	// With returns a logger that adds the given key-value pairs to every message, e.g. With("path", path).
+	With(keyvals ...interface{}) Logger
 	// EnableDebug turns printing debug messages on.
 	EnableDebug()
+	// SetFormat sets the format of the messages to text or json.
+	SetFormat(format string) error
+	// SetOutput sets the destination of the messages.
+	SetOutput(w io.Writer)
 }
 
-type logger struct {
-	*logging.Logger
+// logBackend is the configuration shared by all loggers,
+// so that the flags of the application apply to the loggers of all packages.
+type logBackend struct {
+	mutex  sync.Mutex
+	level  LogLevel
+	format string
+	out    io.Writer
+	now    func() time.Time
+}
+
+var backend = &logBackend{
+	level:  LogLevelInfo,
+	format: LogFormatText,
+	out:    os.Stderr,
+	now:    time.Now,
 }
 
-func init() {
-	formatter := logging.MustStringFormatter(logFormat)
-	backend := logging.NewBackendFormatter(logging.NewLogBackend(os.Stdout, "", 0), formatter)
-	logging.SetBackend(backend)
+type logger struct {
+	backend *logBackend
+	fields  []interface{}
 }
 
-// NewLogger returns a logger with the given format, module and loglevel.
+// NewLogger returns a logger that writes messages of level info and higher
+// as text to stderr, until configured otherwise.
 func NewLogger() Logger {
-	l := logging.MustGetLogger(logModule)
-	logging.SetLevel(logging.INFO, logModule)
+	return logger{backend: backend}
+}
 
-	return logger{Logger: l}
+// Debugf logs a message when debug mode is enabled.
+func (l logger) Debugf(format string, args ...interface{}) {
+	l.log(LogLevelDebug, format, args...)
+}
+
+// Infof logs an informational message.
+func (l logger) Infof(format string, args ...interface{}) {
+	l.log(LogLevelInfo, format, args...)
+}
+
+// Warningf logs a message about something that might be wrong.
+func (l logger) Warningf(format string, args ...interface{}) {
+	l.log(LogLevelWarning, format, args...)
+}
+
+// Errorf logs a message about something that failed.
+func (l logger) Errorf(format string, args ...interface{}) {
+	l.log(LogLevelError, format, args...)
+}
+
+// With returns a logger that adds the given key-value pairs to every message.
+// A key without a value gets the value null.
+func (l logger) With(keyvals ...interface{}) Logger {
+	if len(keyvals)%2 != 0 {
+		keyvals = append(keyvals, nil)
+	}
+	fields := make([]interface{}, 0, len(l.fields)+len(keyvals))
+	return logger{
+		backend: l.backend,
+		fields:  append(append(fields, l.fields...), keyvals...),
+	}
 }
 
 // EnableDebug turns printing debug messages on.
 func (l logger) EnableDebug() {
-	logging.SetLevel(logging.DEBUG, l.Module)
-	l.Debug("Loglevel set to debug")
+	l.backend.mutex.Lock()
+	l.backend.level = LogLevelDebug
+	l.backend.mutex.Unlock()
+	l.Debugf("Loglevel set to debug")
+}
+
+// SetFormat sets the format of the messages to text or json.
+func (l logger) SetFormat(format string) error {
+	if format != LogFormatText && format != LogFormatJSON {
+		return ErrUnknownLogFormat
+	}
+	l.backend.mutex.Lock()
+	defer l.backend.mutex.Unlock()
+	l.backend.format = format
+	return nil
+}
+
+// SetOutput sets the destination of the messages.
+func (l logger) SetOutput(w io.Writer) {
+	l.backend.mutex.Lock()
+	defer l.backend.mutex.Unlock()
+	l.backend.out = w
+}
+
+func (l logger) log(level LogLevel, format string, args ...interface{}) {
+	l.backend.mutex.Lock()
+	defer l.backend.mutex.Unlock()
+
+	if level < l.backend.level {
+		return
+	}
+
+	message := fmt.Sprintf(format, args...)
+	var line string
+	if l.backend.format == LogFormatJSON {
+		line = l.formatJSON(level, message)
+	} else {
+		line = l.formatText(level, message)
+	}
+
+	_, _ = io.WriteString(l.backend.out, line+"\n")
+}
+
+// formatText formats a message as e.g. WARN ▶ cannot save usage statistics path=/tmp/stats.json
+func (l logger) formatText(level LogLevel, message string) string {
+	prefix := strings.ToUpper(level.String())
+	if len(prefix) > 4 {
+		prefix = prefix[:4]
+	}
+
+	prefix += " ▶ "
+	// Log files are not colored.
+	if l.backend.out == os.Stderr {
+		prefix = logLevelColors[level].Sprint(prefix)
+	}
+
+	line := prefix + message
+	for i := 0; i < len(l.fields); i += 2 {
+		line += fmt.Sprintf(" %v=%s", l.fields[i], formatTextValue(l.fields[i+1]))
+	}
+	return line
+}
+
+// formatTextValue quotes values that contain spaces, so that the key-value pairs can be parsed.
+func formatTextValue(value interface{}) string {
+	text := fmt.Sprint(value)
+	if err, ok := value.(error); ok {
+		text = err.Error()
+	}
+	if text == "" || strings.ContainsAny(text, " \t\n\"=") {
+		return fmt.Sprintf("%q", text)
+	}
+	return text
+}
+
+// formatJSON formats a message as a JSON object with the time, level, message and fields.
+func (l logger) formatJSON(level LogLevel, message string) string {
+	entry := map[string]interface{}{}
+	for i := 0; i < len(l.fields); i += 2 {
+		value := l.fields[i+1]
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		} else if stringer, ok := value.(fmt.Stringer); ok {
+			value = stringer.String()
+		}
+		entry[fmt.Sprint(l.fields[i])] = value
+	}
+	entry["time"] = l.backend.now().UTC().Format(time.RFC3339Nano)
+	entry["level"] = level.String()
+	entry["msg"] = message
+
+	// Marshalling a map sorts the keys, so the output is stable.
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Sprintf(`{"level":%q,"msg":%q,"log_error":%q}`, level, message, err)
+	}
+	return string(line)
 }
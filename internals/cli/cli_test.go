@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func newTestLogger(format string) (Logger, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	return logger{
+		backend: &logBackend{
+			level:  LogLevelInfo,
+			format: format,
+			out:    buf,
+			now: func() time.Time {
+				return time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+			},
+		},
+	}, buf
+}
+
+func TestLogger_Text(t *testing.T) {
+	l, buf := newTestLogger(LogFormatText)
+
+	l.Debugf("not logged")
+	l.With("dir", "company/app", "error", errors.New("access denied")).Errorf("Synchronizing %s failed", "secrets")
+	l.EnableDebug()
+	l.With("count").Debugf("done")
+
+	assert.Equal(t, buf.String(), "ERRO ▶ Synchronizing secrets failed dir=company/app error=\"access denied\"\n"+
+		"DEBU ▶ Loglevel set to debug\n"+
+		"DEBU ▶ done count=<nil>\n")
+}
+
+func TestLogger_JSON(t *testing.T) {
+	l, buf := newTestLogger(LogFormatJSON)
+
+	l.With("dir", "company/app").With("count", 3).Warningf("%d secrets skipped", 3)
+
+	assert.Equal(t, buf.String(), `{"count":3,"dir":"company/app","level":"warning","msg":"3 secrets skipped","time":"2020-01-01T12:00:00Z"}`+"\n")
+}
+
+func TestLogger_SetFormat(t *testing.T) {
+	l, _ := newTestLogger(LogFormatText)
+
+	assert.OK(t, l.SetFormat(LogFormatJSON))
+	assert.Equal(t, l.SetFormat("xml"), ErrUnknownLogFormat)
+}
@@ -2,6 +2,10 @@
 package clip
 
 import (
+	"os"
+	"os/exec"
+	"runtime"
+
 	"github.com/atotto/clipboard"
 	"github.com/secrethub/secrethub-go/internals/errio"
 )
@@ -40,7 +44,34 @@ func (c *clip) WriteAll(value []byte) error {
 	return nil
 }
 
-// NewClipboard creates a new Clipper.
+// NewClipboard creates a new Clipper for the clipboard of the current session.
+// On Linux, wl-clipboard is used in Wayland sessions and the Windows clipboard
+// is used in the Windows Subsystem for Linux, when their commands are available.
 func NewClipboard() Clipper {
+	return detectClipboard(runtime.GOOS, os.Getenv, exec.LookPath, isWSL)
+}
+
+// detectClipboard returns the Clipper to use for the given environment.
+func detectClipboard(goos string, getenv func(string) string, lookPath func(string) (string, error), isWSL func() bool) Clipper {
+	if goos != "linux" {
+		return &clip{}
+	}
+
+	available := func(commands ...string) bool {
+		for _, command := range commands {
+			_, err := lookPath(command)
+			if err != nil {
+				return false
+			}
+		}
+		return true
+	}
+
+	if getenv("WAYLAND_DISPLAY") != "" && available("wl-copy", "wl-paste") {
+		return newWaylandClip()
+	}
+	if isWSL() && available("clip.exe", "powershell.exe") {
+		return newWSLClip()
+	}
 	return &clip{}
 }
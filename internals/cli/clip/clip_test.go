@@ -0,0 +1,78 @@
+package clip
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestDetectClipboard(t *testing.T) {
+	cases := map[string]struct {
+		goos     string
+		env      map[string]string
+		commands []string
+		wsl      bool
+		expected Clipper
+	}{
+		"wayland": {
+			goos:     "linux",
+			env:      map[string]string{"WAYLAND_DISPLAY": "wayland-0"},
+			commands: []string{"wl-copy", "wl-paste"},
+			expected: newWaylandClip(),
+		},
+		"wayland without wl-clipboard": {
+			goos:     "linux",
+			env:      map[string]string{"WAYLAND_DISPLAY": "wayland-0"},
+			commands: []string{"xclip"},
+			expected: &clip{},
+		},
+		"wsl": {
+			goos:     "linux",
+			commands: []string{"clip.exe", "powershell.exe"},
+			wsl:      true,
+			expected: newWSLClip(),
+		},
+		"wsl with wayland": {
+			goos:     "linux",
+			env:      map[string]string{"WAYLAND_DISPLAY": "wayland-0"},
+			commands: []string{"clip.exe", "powershell.exe", "wl-copy", "wl-paste"},
+			wsl:      true,
+			expected: newWaylandClip(),
+		},
+		"x11": {
+			goos:     "linux",
+			env:      map[string]string{"DISPLAY": ":0"},
+			commands: []string{"xclip"},
+			expected: &clip{},
+		},
+		"windows": {
+			goos:     "windows",
+			env:      map[string]string{"WAYLAND_DISPLAY": "wayland-0"},
+			commands: []string{"wl-copy", "wl-paste"},
+			expected: &clip{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			getenv := func(key string) string {
+				return tc.env[key]
+			}
+			lookPath := func(file string) (string, error) {
+				for _, command := range tc.commands {
+					if command == file {
+						return "/usr/bin/" + file, nil
+					}
+				}
+				return "", errors.New("not found")
+			}
+			isWSL := func() bool {
+				return tc.wsl
+			}
+
+			actual := detectClipboard(tc.goos, getenv, lookPath, isWSL)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
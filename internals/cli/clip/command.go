@@ -0,0 +1,86 @@
+package clip
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// commandClip implements the Clipper interface with external commands that copy from stdin and paste to stdout.
+type commandClip struct {
+	copyCmd  []string
+	pasteCmd []string
+	// clearCmd clears the clipboard. When it is not set, the clipboard is cleared by copying an empty value.
+	clearCmd []string
+	// pasteSuffix is removed from the pasted value, for commands that end their output with a newline.
+	pasteSuffix string
+}
+
+// newWaylandClip creates a Clipper that uses wl-copy and wl-paste from wl-clipboard.
+func newWaylandClip() *commandClip {
+	return &commandClip{
+		copyCmd:  []string{"wl-copy"},
+		pasteCmd: []string{"wl-paste", "--no-newline"},
+		clearCmd: []string{"wl-copy", "--clear"},
+	}
+}
+
+// newWSLClip creates a Clipper that uses the Windows clipboard from the Windows Subsystem for Linux.
+func newWSLClip() *commandClip {
+	return &commandClip{
+		copyCmd:     []string{"clip.exe"},
+		pasteCmd:    []string{"powershell.exe", "-NoProfile", "-NonInteractive", "-Command", "Get-Clipboard -Raw"},
+		pasteSuffix: "\r\n",
+	}
+}
+
+func (c *commandClip) ReadAll() ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command(c.pasteCmd[0], c.pasteCmd[1:]...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, ErrCannotRead(commandError(err, stderr.String()))
+	}
+	return bytes.TrimSuffix(out, []byte(c.pasteSuffix)), nil
+}
+
+func (c *commandClip) WriteAll(value []byte) error {
+	args := c.copyCmd
+	if len(value) == 0 && c.clearCmd != nil {
+		args = c.clearCmd
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(value)
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return ErrCannotWrite(commandError(err, stderr.String()))
+	}
+	return nil
+}
+
+// commandError adds the output of a failed command to its error.
+func commandError(err error, stderr string) string {
+	stderr = strings.TrimSpace(stderr)
+	if stderr == "" {
+		return err.Error()
+	}
+	return err.Error() + ": " + stderr
+}
+
+// isWSL returns whether the process runs in the Windows Subsystem for Linux.
+func isWSL() bool {
+	if os.Getenv("WSL_DISTRO_NAME") != "" {
+		return true
+	}
+	release, err := ioutil.ReadFile("/proc/sys/kernel/osrelease")
+	if err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(string(release)), "microsoft")
+}
@@ -0,0 +1,178 @@
+// Package hashchain provides an append-only, tamper-evident log file.
+//
+// Every line of the file is a JSON encoded entry that contains the hash of the
+// previous entry. The hash of an entry is an HMAC-SHA256 over its sequence number,
+// the hash of the previous entry and its data, so changing, removing or reordering
+// any entry breaks the chain from that entry onwards. Because the hash is keyed,
+// someone who can write the log but does not have the key cannot rewrite the chain
+// to match, so the key must be kept somewhere else than the log.
+//
+// Removing entries from the end of the log cannot be detected from the log itself,
+// so the hash of the last entry (the head) should be recorded elsewhere and compared
+// when verifying the log.
+package hashchain
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"strconv"
+
+	"github.com/secrethub/secrethub-go/internals/errio"
+)
+
+// Errors
+var (
+	errChain        = errio.Namespace("hashchain")
+	ErrTampered     = errChain.Code("tampered").ErrorPref("the log has been tampered with at line %d: %s")
+	ErrCannotRead   = errChain.Code("cannot_read").ErrorPref("cannot read the log: %s")
+	ErrCannotAppend = errChain.Code("cannot_append").ErrorPref("cannot append to the log: %s")
+	ErrNoKey        = errChain.Code("no_key").Error("a key is required to hash the log")
+)
+
+// maxLineSize is the maximum size of a single entry.
+const maxLineSize = 16 * 1024 * 1024
+
+// genesis is the previous hash of the first entry.
+var genesis = hex.EncodeToString(make([]byte, sha256.Size))
+
+// Entry is a single entry in the log.
+type Entry struct {
+	Seq  uint64          `json:"seq"`
+	Prev string          `json:"prev"`
+	Data json.RawMessage `json:"data"`
+	Hash string          `json:"hash"`
+}
+
+// Head identifies the last entry of the log.
+type Head struct {
+	Seq  uint64
+	Hash string
+}
+
+// String returns the head as <seq>:<hash>.
+func (h Head) String() string {
+	return strconv.FormatUint(h.Seq, 10) + ":" + h.Hash
+}
+
+// hash computes the hash of an entry with the key.
+func hash(key []byte, seq uint64, prev string, data []byte) string {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(strconv.FormatUint(seq, 10) + "\n" + prev + "\n"))
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Read reads the log at path, verifies the chain with the key and calls fn for every entry.
+// It returns the head of the log. A log that does not exist is empty.
+func Read(path string, key []byte, fn func(Entry) error) (Head, error) {
+	head := Head{Hash: genesis}
+	if len(key) == 0 {
+		return head, ErrNoKey
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return head, nil
+	} else if err != nil {
+		return head, ErrCannotRead(err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		var entry Entry
+		err := json.Unmarshal(scanner.Bytes(), &entry)
+		if err != nil {
+			return head, ErrTampered(line, "the entry is not valid JSON")
+		}
+
+		if entry.Seq != head.Seq+1 {
+			return head, ErrTampered(line, "expected sequence number "+strconv.FormatUint(head.Seq+1, 10))
+		}
+		if entry.Prev != head.Hash {
+			return head, ErrTampered(line, "the previous hash does not match the hash of the previous entry")
+		}
+
+		var data bytes.Buffer
+		err = json.Compact(&data, entry.Data)
+		if err != nil || !hmac.Equal([]byte(entry.Hash), []byte(hash(key, entry.Seq, entry.Prev, data.Bytes()))) {
+			return head, ErrTampered(line, "the hash does not match the contents of the entry")
+		}
+
+		if fn != nil {
+			err = fn(entry)
+			if err != nil {
+				return head, err
+			}
+		}
+
+		head = Head{Seq: entry.Seq, Hash: entry.Hash}
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return head, ErrCannotRead(err)
+	}
+	return head, nil
+}
+
+// Append adds entries with the given data to the log at path, after the given head,
+// hashing them with the key. The head must be the head returned by Read, so that the
+// chain continues where the verified log ends. It returns the new head.
+func Append(path string, key []byte, start Head, data ...interface{}) (Head, error) {
+	if len(key) == 0 {
+		return start, ErrNoKey
+	}
+
+	head := start
+	var buf bytes.Buffer
+	for _, d := range data {
+		raw, err := json.Marshal(d)
+		if err != nil {
+			return start, ErrCannotAppend(err)
+		}
+
+		entry := Entry{
+			Seq:  head.Seq + 1,
+			Prev: head.Hash,
+			Data: raw,
+		}
+		entry.Hash = hash(key, entry.Seq, entry.Prev, raw)
+
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return start, ErrCannotAppend(err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+
+		head = Head{Seq: entry.Seq, Hash: entry.Hash}
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0640)
+	if err != nil {
+		return start, ErrCannotAppend(err)
+	}
+
+	_, err = f.Write(buf.Bytes())
+	if err == nil {
+		err = f.Sync()
+	}
+	closeErr := f.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return start, ErrCannotAppend(err)
+	}
+	return head, nil
+}
@@ -0,0 +1,124 @@
+package hashchain
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+func newTestLog(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "hashchain")
+	assert.OK(t, err)
+
+	return filepath.Join(dir, "audit.log"), func() { os.RemoveAll(dir) }
+}
+
+func TestAppendAndRead(t *testing.T) {
+	path, cleanup := newTestLog(t)
+	defer cleanup()
+
+	head, err := Read(path, testKey, nil)
+	assert.OK(t, err)
+	assert.Equal(t, head.Seq, uint64(0))
+
+	head, err = Append(path, testKey, head, map[string]string{"event": "a"}, map[string]string{"event": "b"})
+	assert.OK(t, err)
+	assert.Equal(t, head.Seq, uint64(2))
+
+	head, err = Append(path, testKey, head, map[string]string{"event": "c"})
+	assert.OK(t, err)
+	assert.Equal(t, head.Seq, uint64(3))
+
+	var events []string
+	readHead, err := Read(path, testKey, func(entry Entry) error {
+		events = append(events, string(entry.Data))
+		return nil
+	})
+	assert.OK(t, err)
+	assert.Equal(t, readHead, head)
+	assert.Equal(t, events, []string{`{"event":"a"}`, `{"event":"b"}`, `{"event":"c"}`})
+}
+
+func TestRead_Tampered(t *testing.T) {
+	cases := map[string]struct {
+		tamper func(lines []string) []string
+		key    []byte
+		err    error
+	}{
+		"changed data": {
+			tamper: func(lines []string) []string {
+				lines[1] = strings.Replace(lines[1], `"event":"b"`, `"event":"x"`, 1)
+				return lines
+			},
+			err: ErrTampered(2, "the hash does not match the contents of the entry"),
+		},
+		"removed entry": {
+			tamper: func(lines []string) []string {
+				return append(lines[:1], lines[2:]...)
+			},
+			err: ErrTampered(2, "expected sequence number 2"),
+		},
+		"reordered entries": {
+			tamper: func(lines []string) []string {
+				lines[0], lines[1] = lines[1], lines[0]
+				return lines
+			},
+			err: ErrTampered(1, "expected sequence number 1"),
+		},
+		"invalid entry": {
+			tamper: func(lines []string) []string {
+				lines[2] = "garbage"
+				return lines
+			},
+			err: ErrTampered(3, "the entry is not valid JSON"),
+		},
+		"other key": {
+			tamper: func(lines []string) []string {
+				return lines
+			},
+			key: []byte("fedcba9876543210fedcba9876543210"),
+			err: ErrTampered(1, "the hash does not match the contents of the entry"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			path, cleanup := newTestLog(t)
+			defer cleanup()
+
+			_, err := Append(path, testKey, Head{Hash: genesis}, map[string]string{"event": "a"}, map[string]string{"event": "b"}, map[string]string{"event": "c"})
+			assert.OK(t, err)
+
+			raw, err := ioutil.ReadFile(path)
+			assert.OK(t, err)
+
+			lines := tc.tamper(strings.Split(strings.TrimSuffix(string(raw), "\n"), "\n"))
+			err = ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0640)
+			assert.OK(t, err)
+
+			key := testKey
+			if tc.key != nil {
+				key = tc.key
+			}
+			_, err = Read(path, key, nil)
+			assert.Equal(t, err, tc.err)
+		})
+	}
+}
+
+func TestNoKey(t *testing.T) {
+	path, cleanup := newTestLog(t)
+	defer cleanup()
+
+	_, err := Append(path, nil, Head{Hash: genesis}, map[string]string{"event": "a"})
+	assert.Equal(t, err, ErrNoKey)
+
+	_, err = Read(path, nil, nil)
+	assert.Equal(t, err, ErrNoKey)
+}
@@ -5,6 +5,9 @@ import (
 	"time"
 )
 
+// DefaultMaskString is the text that replaces masked secrets when no other text is configured.
+const DefaultMaskString = "<redacted by SecretHub>"
+
 // Masker handles the creation and synchronization of streams that have all their writes scanned for secrets and
 // have them redacted if any matches are found. Masking of secrets is a best effort attempt. Output on all streams is
 // buffered to increase the chance of finding secrets if they are spread across multiple writes, but it cannot be
@@ -18,6 +21,7 @@ import (
 type Masker struct {
 	bufferDelay time.Duration
 	sequences   [][]byte
+	maskString  []byte
 	frames      chan frame
 	stopChan    chan struct{}
 	err         error
@@ -38,6 +42,10 @@ type Options struct {
 	// FrameBufferLength is the number of frames that can be in the buffer simultaneously.
 	// If the frame buffer is full, writing to a stream blocks until there is space.
 	FrameBufferLength int
+
+	// MaskString is the text that replaces every masked secret.
+	// Defaults to DefaultMaskString if not set.
+	MaskString string
 }
 
 // New creates a new Masker that scans all streams for the given sequences and masks them.
@@ -45,10 +53,14 @@ func New(sequences [][]byte, opts *Options) *Masker {
 	masker := &Masker{
 		bufferDelay: time.Millisecond * 50,
 		sequences:   sequences,
+		maskString:  []byte(DefaultMaskString),
 		stopChan:    make(chan struct{}),
 	}
 	frameChanlength := 1024
 	if opts != nil {
+		if opts.MaskString != "" {
+			masker.maskString = []byte(opts.MaskString)
+		}
 		if opts.DisableBuffer {
 			masker.bufferDelay = 0
 			frameChanlength = 0
@@ -71,6 +83,7 @@ func New(sequences [][]byte, opts *Options) *Masker {
 func (m *Masker) AddStream(w io.Writer) io.Writer {
 	s := stream{
 		dest:          w,
+		maskString:    m.maskString,
 		registerFrame: m.registerFrame,
 		matches:       matches{},
 		matcher:       newMatcher(m.sequences),
@@ -112,6 +112,17 @@ func TestMasker(t *testing.T) {
 			options:  &Options{DisableBuffer: true},
 			expected: "test " + maskString + " test",
 		},
+		"custom mask string": {
+			maskStrings: []string{"foo", "bar"},
+			inputFunc: func(w io.Writer) {
+				_, err := w.Write([]byte("test f"))
+				assert.OK(t, err)
+				_, err = w.Write([]byte("oo bar"))
+				assert.OK(t, err)
+			},
+			options:  &Options{MaskString: "***"},
+			expected: "test *** ***",
+		},
 		"long input": {
 			maskStrings: []string{},
 			inputFunc: func(w io.Writer) {
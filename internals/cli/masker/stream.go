@@ -11,6 +11,7 @@ import (
 // stream is a buffered io.Writer that masks all secrets written on it.
 type stream struct {
 	dest          io.Writer
+	maskString    []byte
 	buf           indexedBuffer
 	registerFrame func(*stream, time.Duration, int)
 
@@ -73,7 +74,7 @@ func (s *stream) flush(n int) error {
 			// Only write the redaction text if there were bytes between this match and the previous match
 			// or this is the first flush for the buffer.
 			if bytesBeforeMatch > 0 || s.buf.currentIndex == 0 {
-				_, err = s.dest.Write([]byte("<redacted by SecretHub>"))
+				_, err = s.dest.Write(s.maskString)
 				if err != nil {
 					return err
 				}
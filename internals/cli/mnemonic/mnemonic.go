@@ -0,0 +1,118 @@
+// Package mnemonic encodes binary secrets as a list of words that is easy to write down,
+// following BIP-0039 (https://github.com/bitcoin/bips/blob/master/bip-0039.mediawiki).
+//
+// The secret is followed by a checksum of len(secret)/4 bits taken from its SHA-256 hash,
+// and every 11 bits of the result are encoded as one of the 2048 words of the English word list.
+// A 32 byte secret is encoded as 24 words. The checksum detects most typos when decoding.
+//
+// Unlike BIP-0039 wallets, the words are not used to derive a seed: Decode returns the original secret.
+package mnemonic
+
+import (
+	"crypto/sha256"
+	"strings"
+
+	"github.com/secrethub/secrethub-go/internals/errio"
+)
+
+// Errors
+var (
+	errMnemonic         = errio.Namespace("mnemonic")
+	ErrInvalidLength    = errMnemonic.Code("invalid_length").ErrorPref("cannot encode %d bytes: the length must be a multiple of 4 bytes between 16 and 32")
+	ErrInvalidWordCount = errMnemonic.Code("invalid_word_count").ErrorPref("expected 12, 15, 18, 21 or 24 words, got %d")
+	ErrUnknownWord      = errMnemonic.Code("unknown_word").ErrorPref("word %d (%s) is not in the word list")
+	ErrChecksumMismatch = errMnemonic.Code("checksum_mismatch").Error("the checksum of the words does not match: check them for typos and make sure they are in the right order")
+)
+
+const (
+	bitsPerWord = 11
+	minLength   = 16
+	maxLength   = 32
+)
+
+var wordIndex = func() map[string]int {
+	index := make(map[string]int, len(english))
+	for i, word := range english {
+		index[word] = i
+	}
+	return index
+}()
+
+// Encode returns the words that encode the secret.
+func Encode(secret []byte) ([]string, error) {
+	if len(secret) < minLength || len(secret) > maxLength || len(secret)%4 != 0 {
+		return nil, ErrInvalidLength(len(secret))
+	}
+
+	checksumBits := len(secret) * 8 / 32
+	bits := newBitBuffer(append(append([]byte{}, secret...), checksum(secret)...))
+
+	words := make([]string, (len(secret)*8+checksumBits)/bitsPerWord)
+	for i := range words {
+		words[i] = english[bits.get(i*bitsPerWord, bitsPerWord)]
+	}
+	return words, nil
+}
+
+// Decode returns the secret encoded by the words. Words are matched case-insensitively.
+func Decode(words []string) ([]byte, error) {
+	totalBits := len(words) * bitsPerWord
+	checksumBits := totalBits / 33
+	length := (totalBits - checksumBits) / 8
+	if len(words)%3 != 0 || length < minLength || length > maxLength {
+		return nil, ErrInvalidWordCount(len(words))
+	}
+
+	bits := newBitBuffer(make([]byte, (totalBits+7)/8))
+	for i, word := range words {
+		index, ok := wordIndex[strings.ToLower(word)]
+		if !ok {
+			return nil, ErrUnknownWord(i+1, word)
+		}
+		bits.set(i*bitsPerWord, bitsPerWord, index)
+	}
+
+	secret := bits.bytes[:length]
+	expected := newBitBuffer(checksum(secret)).get(0, checksumBits)
+	if bits.get(length*8, checksumBits) != expected {
+		return nil, ErrChecksumMismatch
+	}
+	return append([]byte{}, secret...), nil
+}
+
+// checksum returns the SHA-256 hash of the secret, of which the first bits are used as checksum.
+func checksum(secret []byte) []byte {
+	sum := sha256.Sum256(secret)
+	return sum[:]
+}
+
+// bitBuffer reads and writes groups of bits in a byte slice, most significant bit first.
+type bitBuffer struct {
+	bytes []byte
+}
+
+func newBitBuffer(bytes []byte) bitBuffer {
+	return bitBuffer{bytes: bytes}
+}
+
+// get returns the n bits starting at bit offset as an integer.
+func (b bitBuffer) get(offset, n int) int {
+	value := 0
+	for i := offset; i < offset+n; i++ {
+		value <<= 1
+		if b.bytes[i/8]&(0x80>>uint(i%8)) != 0 {
+			value |= 1
+		}
+	}
+	return value
+}
+
+// set writes the lowest n bits of value starting at bit offset.
+func (b bitBuffer) set(offset, n int, value int) {
+	for i := 0; i < n; i++ {
+		if value&(1<<uint(n-1-i)) != 0 {
+			pos := offset + i
+			b.bytes[pos/8] |= 0x80 >> uint(pos%8)
+		}
+	}
+}
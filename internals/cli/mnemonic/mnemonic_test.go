@@ -0,0 +1,117 @@
+package mnemonic
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+// Test vectors from https://github.com/trezor/python-mnemonic/blob/master/vectors.json
+var vectors = []struct {
+	secret string
+	words  string
+}{
+	{
+		secret: "00000000000000000000000000000000",
+		words:  "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+	},
+	{
+		secret: "7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+		words:  "legal winner thank year wave sausage worth useful legal winner thank yellow",
+	},
+	{
+		secret: "000000000000000000000000000000000000000000000000",
+		words:  "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon agent",
+	},
+	{
+		secret: "0000000000000000000000000000000000000000000000000000000000000000",
+		words:  "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art",
+	},
+	{
+		secret: "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		words:  "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo vote",
+	},
+	{
+		secret: "68a79eaca2324873eacc50cb9c6eca8cc68ea5d936f98787c60c7ebc74e6ce7c",
+		words:  "hamster diagram private dutch cause delay private meat slide toddler razor book happy fancy gospel tennis maple dilemma loan word shrug inflict delay length",
+	},
+}
+
+func TestWordList(t *testing.T) {
+	assert.Equal(t, len(english), 2048)
+	assert.Equal(t, len(wordIndex), 2048)
+}
+
+func TestEncode(t *testing.T) {
+	for _, vector := range vectors {
+		t.Run(vector.secret, func(t *testing.T) {
+			secret, err := hex.DecodeString(vector.secret)
+			assert.OK(t, err)
+
+			words, err := Encode(secret)
+
+			assert.OK(t, err)
+			assert.Equal(t, strings.Join(words, " "), vector.words)
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	for _, vector := range vectors {
+		t.Run(vector.secret, func(t *testing.T) {
+			secret, err := Decode(strings.Fields(vector.words))
+
+			assert.OK(t, err)
+			assert.Equal(t, hex.EncodeToString(secret), vector.secret)
+		})
+	}
+}
+
+func TestEncode_InvalidLength(t *testing.T) {
+	for _, length := range []int{0, 12, 17, 36} {
+		_, err := Encode(make([]byte, length))
+		assert.Equal(t, err, ErrInvalidLength(length))
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	cases := map[string]struct {
+		words string
+		err   error
+	}{
+		"too few words": {
+			words: "abandon abandon abandon",
+			err:   ErrInvalidWordCount(3),
+		},
+		"unknown word": {
+			words: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon secrethub",
+			err:   ErrUnknownWord(12, "secrethub"),
+		},
+		"wrong checksum": {
+			words: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon",
+			err:   ErrChecksumMismatch,
+		},
+		"swapped words": {
+			words: "winner legal thank year wave sausage worth useful legal winner thank yellow",
+			err:   ErrChecksumMismatch,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Decode(strings.Fields(tc.words))
+
+			assert.Equal(t, err, tc.err)
+		})
+	}
+}
+
+func TestDecode_CaseInsensitive(t *testing.T) {
+	secret, err := Decode(strings.Fields("Legal Winner THANK year wave sausage worth useful legal winner thank yellow"))
+
+	assert.OK(t, err)
+	assert.Equal(t, bytes.Equal(secret, bytes.Repeat([]byte{0x7f}, 16)), true)
+}
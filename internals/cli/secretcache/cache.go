@@ -0,0 +1,212 @@
+// Package secretcache provides an encrypted on-disk cache for secret values.
+//
+// Every secret is stored in its own file, encrypted with AES-GCM. The file names
+// are derived from the secret paths with an HMAC, so the cache directory does not
+// reveal which secrets are cached. The key should be derived from a credential with
+// DeriveKey, so that the cache is useless to anyone without access to that credential.
+package secretcache
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/atomicfile"
+
+	"github.com/secrethub/secrethub-go/internals/errio"
+)
+
+// Errors
+var (
+	errCache         = errio.Namespace("cache")
+	ErrInvalidKey    = errCache.Code("invalid_key").Error("the cache key must be 32 bytes long")
+	ErrCannotCreate  = errCache.Code("cannot_create").ErrorPref("cannot create the cache directory %s: %s")
+	ErrCannotWrite   = errCache.Code("cannot_write").ErrorPref("cannot write to the cache: %s")
+	ErrCannotClear   = errCache.Code("cannot_clear").ErrorPref("cannot clear the cache: %s")
+	errCorruptRecord = errCache.Code("corrupt_record").Error("cache record is corrupt")
+)
+
+const (
+	// KeySize is the length of the key used to encrypt the cache.
+	KeySize = 32
+
+	fileExtension = ".cache"
+	keyLabel      = "secrethub-cli secret cache v1"
+)
+
+// Cache stores secret values in a directory on disk.
+type Cache struct {
+	dir string
+	key []byte
+	now func() time.Time
+}
+
+// record is the plaintext content of a cache file.
+type record struct {
+	Path     string    `json:"path"`
+	Data     []byte    `json:"data"`
+	CachedAt time.Time `json:"cached_at"`
+}
+
+// DeriveKey derives a cache key from an exported credential.
+func DeriveKey(credential []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(keyLabel))
+	mac.Write(credential)
+	return mac.Sum(nil)
+}
+
+// New returns a cache that stores its files in the given directory.
+// The directory is created when the first secret is stored.
+func New(dir string, key []byte) (*Cache, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+
+	return &Cache{
+		dir: dir,
+		key: key,
+		now: time.Now,
+	}, nil
+}
+
+// Get returns the cached value of the secret at the given path when it
+// was cached less than maxAge ago. Entries that cannot be decrypted, e.g.
+// because they were stored with another key, are treated as missing.
+func (c *Cache) Get(path string, maxAge time.Duration) ([]byte, bool) {
+	data, cachedAt, ok := c.Lookup(path)
+	if !ok || c.now().Sub(cachedAt) >= maxAge {
+		return nil, false
+	}
+	return data, true
+}
+
+// Lookup returns the cached value of the secret at the given path and the time
+// it was cached, regardless of its age. Entries that cannot be decrypted are
+// treated as missing.
+func (c *Cache) Lookup(path string) ([]byte, time.Time, bool) {
+	raw, err := ioutil.ReadFile(c.filename(path))
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+
+	r, err := c.open(path, raw)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+
+	if !strings.EqualFold(r.Path, path) {
+		return nil, time.Time{}, false
+	}
+	return r.Data, r.CachedAt, true
+}
+
+// Set stores the value of the secret at the given path in the cache.
+func (c *Cache) Set(path string, data []byte) error {
+	err := os.MkdirAll(c.dir, 0700)
+	if err != nil {
+		return ErrCannotCreate(c.dir, err)
+	}
+
+	raw, err := c.seal(path, record{
+		Path:     path,
+		Data:     data,
+		CachedAt: c.now().UTC(),
+	})
+	if err != nil {
+		return ErrCannotWrite(err)
+	}
+
+	err = atomicfile.WriteFile(c.filename(path), raw, 0600)
+	if err != nil {
+		return ErrCannotWrite(err)
+	}
+	return nil
+}
+
+// Clear removes all cached secrets from the directory and returns how many were removed.
+// No key is needed to clear the cache.
+func Clear(dir string) (int, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+fileExtension))
+	if err != nil {
+		return 0, ErrCannotClear(err)
+	}
+
+	for _, file := range files {
+		err = os.Remove(file)
+		if err != nil && !os.IsNotExist(err) {
+			return 0, ErrCannotClear(err)
+		}
+	}
+	return len(files), nil
+}
+
+// filename returns the file the secret at the given path is stored in.
+// Secret paths are case insensitive, so they map to the same file regardless of case.
+func (c *Cache) filename(path string) string {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(strings.ToLower(path)))
+	return filepath.Join(c.dir, hex.EncodeToString(mac.Sum(nil))+fileExtension)
+}
+
+// seal encrypts the record. The lowercased path is used as additional data,
+// so that a record cannot be moved to the file of another secret.
+func (c *Cache) seal(path string, r record) ([]byte, error) {
+	plaintext, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := c.aead()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, []byte(strings.ToLower(path))), nil
+}
+
+// open decrypts a record sealed with seal.
+func (c *Cache) open(path string, raw []byte) (*record, error) {
+	aead, err := c.aead()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(raw) < aead.NonceSize() {
+		return nil, errCorruptRecord
+	}
+
+	plaintext, err := aead.Open(nil, raw[:aead.NonceSize()], raw[aead.NonceSize():], []byte(strings.ToLower(path)))
+	if err != nil {
+		return nil, errCorruptRecord
+	}
+
+	r := &record{}
+	err = json.Unmarshal(plaintext, r)
+	if err != nil {
+		return nil, errCorruptRecord
+	}
+	return r, nil
+}
+
+func (c *Cache) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(c.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,100 @@
+package secretcache
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func newTestCache(t *testing.T, credential string) (*Cache, func()) {
+	dir, err := ioutil.TempDir("", "secretcache")
+	assert.OK(t, err)
+
+	cache, err := New(filepath.Join(dir, "cache"), DeriveKey([]byte(credential)))
+	assert.OK(t, err)
+
+	return cache, func() { os.RemoveAll(dir) }
+}
+
+func TestCache(t *testing.T) {
+	cache, cleanup := newTestCache(t, "credential")
+	defer cleanup()
+
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	_, ok := cache.Get("company/repo/secret", time.Hour)
+	assert.Equal(t, ok, false)
+
+	err := cache.Set("company/repo/secret", []byte("s3cr3t"))
+	assert.OK(t, err)
+
+	data, ok := cache.Get("company/repo/secret", time.Hour)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, data, []byte("s3cr3t"))
+
+	// Secret paths are case insensitive.
+	data, ok = cache.Get("Company/Repo/Secret", time.Hour)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, data, []byte("s3cr3t"))
+
+	_, ok = cache.Get("company/repo/other", time.Hour)
+	assert.Equal(t, ok, false)
+
+	now = now.Add(time.Hour)
+	_, ok = cache.Get("company/repo/secret", time.Hour)
+	assert.Equal(t, ok, false)
+
+	data, cachedAt, ok := cache.Lookup("company/repo/secret")
+	assert.Equal(t, ok, true)
+	assert.Equal(t, data, []byte("s3cr3t"))
+	assert.Equal(t, cachedAt, now.Add(-time.Hour))
+
+	n, err := Clear(cache.dir)
+	assert.OK(t, err)
+	assert.Equal(t, n, 1)
+
+	_, ok = cache.Get("company/repo/secret", 2*time.Hour)
+	assert.Equal(t, ok, false)
+}
+
+func TestCache_OtherKey(t *testing.T) {
+	cache, cleanup := newTestCache(t, "credential")
+	defer cleanup()
+
+	err := cache.Set("company/repo/secret", []byte("s3cr3t"))
+	assert.OK(t, err)
+
+	other, err := New(cache.dir, DeriveKey([]byte("other credential")))
+	assert.OK(t, err)
+
+	_, ok := other.Get("company/repo/secret", time.Hour)
+	assert.Equal(t, ok, false)
+}
+
+func TestCache_Encrypted(t *testing.T) {
+	cache, cleanup := newTestCache(t, "credential")
+	defer cleanup()
+
+	err := cache.Set("company/repo/secret", []byte("s3cr3t"))
+	assert.OK(t, err)
+
+	files, err := ioutil.ReadDir(cache.dir)
+	assert.OK(t, err)
+	assert.Equal(t, len(files), 1)
+
+	raw, err := ioutil.ReadFile(filepath.Join(cache.dir, files[0].Name()))
+	assert.OK(t, err)
+	assert.Equal(t, bytes.Contains(raw, []byte("s3cr3t")), false)
+	assert.Equal(t, bytes.Contains(raw, []byte("company/repo/secret")), false)
+}
+
+func TestNew_InvalidKey(t *testing.T) {
+	_, err := New("cache", []byte("too short"))
+	assert.Equal(t, err, ErrInvalidKey)
+}
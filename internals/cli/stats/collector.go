@@ -0,0 +1,82 @@
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Collector collects the usage statistics of a single invocation of the application
+// and adds them to the statistics file when the invocation ends.
+type Collector struct {
+	mutex       sync.Mutex
+	cacheHits   int
+	cacheMisses int
+	now         func() time.Time
+}
+
+// NewCollector returns a Collector for an invocation of the application.
+func NewCollector() *Collector {
+	return &Collector{
+		now: time.Now,
+	}
+}
+
+// RecordCache registers a lookup in the cache.
+func (c *Collector) RecordCache(hit bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if hit {
+		c.cacheHits++
+	} else {
+		c.cacheMisses++
+	}
+}
+
+// Save adds the invocation of the command with the given name and the cache lookups
+// to the statistics in the given file. The configuration directory is not created
+// just to store statistics, so nothing is saved when the directory does not exist.
+//
+// It returns whether a report should be shared. The report is then marked as sent,
+// so that invocations that run in the meantime do not share it again.
+func (c *Collector) Save(filename string, command string, duration time.Duration, failed bool) (bool, error) {
+	if filename == "" || command == "" {
+		return false, nil
+	}
+
+	_, err := os.Stat(filepath.Dir(filename))
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var share bool
+	err = Update(filename, func(s *Stats) error {
+		s.RecordCommand(command, duration, failed)
+		for i := 0; i < c.cacheHits; i++ {
+			s.RecordCache(true)
+		}
+		for i := 0; i < c.cacheMisses; i++ {
+			s.RecordCache(false)
+		}
+
+		now := c.now()
+		share = s.ShouldShare(now)
+		if share {
+			s.Sharing.LastSent = now.UTC()
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	c.cacheHits, c.cacheMisses = 0, 0
+	return share, nil
+}
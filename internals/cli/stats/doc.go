@@ -0,0 +1,15 @@
+// Package stats collects usage statistics of the command-line application.
+//
+// All collection code lives in this package, so that it can be audited in one place.
+// The statistics are stored in a file in the configuration directory and contain:
+//   - the number of invocations, failures and the total duration per command name (e.g. "service init"),
+//   - the number of cache hits and misses.
+//
+// Arguments, flag values, secret paths, account names and secret values are never collected.
+// The file is locked while it is updated, so that concurrent invocations do not lose counts.
+//
+// Nothing leaves the machine unless the user explicitly opts in to sharing. When sharing is
+// enabled, an anonymized Report is sent to the endpoint chosen by the user. The report is
+// identified by a random installation ID that is generated on opt-in and is not linked to
+// the SecretHub account.
+package stats
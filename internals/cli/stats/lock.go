@@ -0,0 +1,54 @@
+package stats
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+const (
+	// lockTimeout is the maximum time waited for another process to release the lock.
+	lockTimeout = 2 * time.Second
+	// lockRetryInterval is the time between two attempts to acquire the lock.
+	lockRetryInterval = 10 * time.Millisecond
+	// staleLockAge is the age after which a lock is considered to be left behind
+	// by a process that crashed. Updating the statistics takes milliseconds.
+	staleLockAge = 10 * time.Second
+)
+
+// ErrLocked is returned when the statistics file stays locked by another process.
+var ErrLocked = errors.New("the usage statistics file is locked by another process")
+
+// lock acquires an exclusive lock on the given file by creating a lock file next to it.
+// Creating a file that must not exist is atomic on all platforms, unlike advisory locks.
+// The returned function releases the lock.
+func lock(filename string) (func(), error) {
+	path := filename + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fileMode)
+		if err == nil {
+			err = f.Close()
+			if err != nil {
+				_ = os.Remove(path)
+				return nil, err
+			}
+			return func() {
+				_ = os.Remove(path)
+			}, nil
+		} else if !os.IsExist(err) {
+			return nil, err
+		}
+
+		info, err := os.Stat(path)
+		if err == nil && time.Since(info.ModTime()) > staleLockAge {
+			_ = os.Remove(path)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, ErrLocked
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
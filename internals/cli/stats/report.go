@@ -0,0 +1,78 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"time"
+)
+
+// ShareInterval is the minimum time between two reports sent to the sharing endpoint.
+const ShareInterval = 24 * time.Hour
+
+// Report contains the anonymized aggregates that are shared when the user opted in.
+type Report struct {
+	InstallID    string                   `json:"install_id"`
+	Version      string                   `json:"version"`
+	OS           string                   `json:"os"`
+	Arch         string                   `json:"arch"`
+	Since        time.Time                `json:"since"`
+	Commands     map[string]CommandReport `json:"commands"`
+	CacheHitRate float64                  `json:"cache_hit_rate"`
+}
+
+// CommandReport contains the aggregates of a single command.
+type CommandReport struct {
+	Count             int   `json:"count"`
+	Errors            int   `json:"errors"`
+	AverageDurationMS int64 `json:"average_duration_ms"`
+}
+
+// Report returns the anonymized aggregates of the statistics.
+func (s *Stats) Report(version string) Report {
+	commands := make(map[string]CommandReport, len(s.Commands))
+	for name, cmd := range s.Commands {
+		commands[name] = CommandReport{
+			Count:             cmd.Count,
+			Errors:            cmd.Errors,
+			AverageDurationMS: int64(cmd.AverageDuration() / time.Millisecond),
+		}
+	}
+
+	return Report{
+		InstallID:    s.Sharing.InstallID,
+		Version:      version,
+		OS:           runtime.GOOS,
+		Arch:         runtime.GOARCH,
+		Since:        s.Since,
+		Commands:     commands,
+		CacheHitRate: s.CacheHitRate(),
+	}
+}
+
+// ShouldShare returns whether a report should be sent to the sharing endpoint.
+func (s *Stats) ShouldShare(now time.Time) bool {
+	return s.Sharing.Enabled && s.Sharing.Endpoint != "" && now.Sub(s.Sharing.LastSent) >= ShareInterval
+}
+
+// Send posts the report as JSON to the given endpoint.
+func (r Report) Send(endpoint string) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	client := http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response from %s: %s", endpoint, resp.Status)
+	}
+	return nil
+}
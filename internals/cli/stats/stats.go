@@ -0,0 +1,208 @@
+package stats
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/atomicfile"
+)
+
+const (
+	// Filename is the name of the file the statistics are stored in.
+	Filename = "stats.json"
+
+	fileMode = os.FileMode(0600)
+)
+
+// Stats contains the usage statistics collected on this machine.
+type Stats struct {
+	// Disabled turns off the collection of statistics.
+	Disabled bool `json:"disabled,omitempty"`
+	// Since is the moment the collection of the statistics started.
+	Since       time.Time                `json:"since"`
+	Commands    map[string]*CommandStats `json:"commands"`
+	CacheHits   int                      `json:"cache_hits"`
+	CacheMisses int                      `json:"cache_misses"`
+	Sharing     Sharing                  `json:"sharing"`
+}
+
+// CommandStats contains the statistics of a single command.
+type CommandStats struct {
+	Count         int           `json:"count"`
+	Errors        int           `json:"errors"`
+	TotalDuration time.Duration `json:"total_duration"`
+}
+
+// AverageDuration returns the average duration of an invocation of the command.
+func (s CommandStats) AverageDuration() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.TotalDuration / time.Duration(s.Count)
+}
+
+// Sharing contains the consent of the user to share anonymized statistics.
+type Sharing struct {
+	Enabled bool `json:"enabled"`
+	// Endpoint is the URL the reports are sent to.
+	Endpoint string `json:"endpoint,omitempty"`
+	// InstallID is a random identifier that is not linked to the user's account.
+	InstallID string    `json:"install_id,omitempty"`
+	LastSent  time.Time `json:"last_sent,omitempty"`
+}
+
+// New returns empty statistics that start collecting now.
+func New() *Stats {
+	return &Stats{
+		Since:    time.Now().UTC(),
+		Commands: make(map[string]*CommandStats),
+	}
+}
+
+// Load reads the statistics from the given file. When the file
+// does not exist yet, empty statistics are returned.
+func Load(filename string) (*Stats, error) {
+	raw, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return New(), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	s := New()
+	err = json.Unmarshal(raw, s)
+	if err != nil {
+		return nil, err
+	}
+	if s.Commands == nil {
+		s.Commands = make(map[string]*CommandStats)
+	}
+	return s, nil
+}
+
+// Update loads the statistics from the given file, changes them with fn and saves them.
+// The file is locked in the meantime, so that concurrent invocations of the application
+// do not overwrite each other's changes. When fn returns an error, nothing is saved.
+func Update(filename string, fn func(s *Stats) error) error {
+	unlock, err := lock(filename)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	s, err := Load(filename)
+	if err != nil {
+		return err
+	}
+
+	err = fn(s)
+	if err != nil {
+		return err
+	}
+	return s.Save(filename)
+}
+
+// Save writes the statistics to the given file. Use Update to change
+// the statistics in a file that other processes can write to.
+func (s *Stats) Save(filename string) error {
+	raw, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filename, raw, fileMode)
+}
+
+// Reset clears all collected statistics, but keeps the settings.
+func (s *Stats) Reset() {
+	s.Since = time.Now().UTC()
+	s.Commands = make(map[string]*CommandStats)
+	s.CacheHits = 0
+	s.CacheMisses = 0
+}
+
+// RecordCommand registers an invocation of the command with the given name.
+func (s *Stats) RecordCommand(name string, duration time.Duration, failed bool) {
+	if s.Disabled || name == "" {
+		return
+	}
+
+	cmd, ok := s.Commands[name]
+	if !ok {
+		cmd = &CommandStats{}
+		s.Commands[name] = cmd
+	}
+
+	cmd.Count++
+	cmd.TotalDuration += duration
+	if failed {
+		cmd.Errors++
+	}
+}
+
+// RecordCache registers a lookup in the cache.
+func (s *Stats) RecordCache(hit bool) {
+	if s.Disabled {
+		return
+	}
+
+	if hit {
+		s.CacheHits++
+	} else {
+		s.CacheMisses++
+	}
+}
+
+// CacheHitRate returns the fraction of cache lookups that were a hit.
+func (s *Stats) CacheHitRate() float64 {
+	total := s.CacheHits + s.CacheMisses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.CacheHits) / float64(total)
+}
+
+// CommandNames returns the names of all recorded commands, sorted by number of invocations.
+func (s *Stats) CommandNames() []string {
+	names := make([]string, 0, len(s.Commands))
+	for name := range s.Commands {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := s.Commands[names[i]], s.Commands[names[j]]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// EnableSharing records the consent of the user to share reports with the given endpoint.
+func (s *Stats) EnableSharing(endpoint string) error {
+	id := s.Sharing.InstallID
+	if id == "" {
+		b := make([]byte, 16)
+		_, err := rand.Read(b)
+		if err != nil {
+			return err
+		}
+		id = hex.EncodeToString(b)
+	}
+
+	s.Sharing = Sharing{
+		Enabled:   true,
+		Endpoint:  endpoint,
+		InstallID: id,
+	}
+	return nil
+}
+
+// DisableSharing withdraws the consent to share reports.
+func (s *Stats) DisableSharing() {
+	s.Sharing = Sharing{}
+}
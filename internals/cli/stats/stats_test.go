@@ -0,0 +1,241 @@
+package stats
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStats_RecordCommand(t *testing.T) {
+	s := New()
+	s.RecordCommand("read", 2*time.Second, false)
+	s.RecordCommand("read", 4*time.Second, true)
+	s.RecordCommand("write", time.Second, false)
+	s.RecordCommand("", time.Second, false)
+
+	if len(s.Commands) != 2 {
+		t.Fatalf("expected 2 commands, got %d", len(s.Commands))
+	}
+
+	read := s.Commands["read"]
+	if read.Count != 2 || read.Errors != 1 || read.AverageDuration() != 3*time.Second {
+		t.Errorf("unexpected stats for read: %+v", read)
+	}
+
+	names := s.CommandNames()
+	if len(names) != 2 || names[0] != "read" || names[1] != "write" {
+		t.Errorf("unexpected command names: %v", names)
+	}
+}
+
+func TestStats_Disabled(t *testing.T) {
+	s := New()
+	s.Disabled = true
+	s.RecordCommand("read", time.Second, false)
+	s.RecordCache(true)
+
+	if len(s.Commands) != 0 || s.CacheHits != 0 {
+		t.Errorf("expected nothing to be recorded, got %+v", s)
+	}
+}
+
+func TestStats_CacheHitRate(t *testing.T) {
+	s := New()
+	if s.CacheHitRate() != 0 {
+		t.Errorf("expected hit rate 0 without lookups, got %f", s.CacheHitRate())
+	}
+
+	s.RecordCache(true)
+	s.RecordCache(true)
+	s.RecordCache(true)
+	s.RecordCache(false)
+
+	if s.CacheHitRate() != 0.75 {
+		t.Errorf("expected hit rate 0.75, got %f", s.CacheHitRate())
+	}
+}
+
+func TestStats_SaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, Filename)
+
+	s, err := Load(filename)
+	if err != nil {
+		t.Fatalf("unexpected error loading non-existing file: %s", err)
+	}
+
+	s.RecordCommand("run", time.Second, false)
+	err = s.EnableSharing("https://collector.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.Save(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Commands["run"].Count != 1 {
+		t.Errorf("expected run to be loaded, got %+v", loaded.Commands)
+	}
+	if !loaded.Sharing.Enabled || loaded.Sharing.InstallID != s.Sharing.InstallID {
+		t.Errorf("expected sharing settings to be loaded, got %+v", loaded.Sharing)
+	}
+}
+
+func TestStats_ShouldShare(t *testing.T) {
+	now := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	s := New()
+	if s.ShouldShare(now) {
+		t.Error("expected no sharing without opt-in")
+	}
+
+	err := s.EnableSharing("https://collector.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.ShouldShare(now) {
+		t.Error("expected sharing after opt-in")
+	}
+
+	s.Sharing.LastSent = now.Add(-time.Hour)
+	if s.ShouldShare(now) {
+		t.Error("expected no sharing within the share interval")
+	}
+
+	s.DisableSharing()
+	if s.ShouldShare(now) || s.Sharing.InstallID != "" {
+		t.Errorf("expected sharing settings to be cleared on opt-out, got %+v", s.Sharing)
+	}
+}
+
+func TestUpdate_Concurrent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, Filename)
+
+	const n = 20
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			errs <- Update(filename, func(s *Stats) error {
+				s.RecordCommand("read", time.Second, false)
+				return nil
+			})
+		}()
+	}
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err := Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Commands["read"].Count != n {
+		t.Errorf("expected %d invocations, got %d", n, s.Commands["read"].Count)
+	}
+
+	_, err = os.Stat(filename + ".lock")
+	if !os.IsNotExist(err) {
+		t.Errorf("expected the lock to be released, got %v", err)
+	}
+}
+
+func TestUpdate_StaleLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, Filename)
+
+	err = ioutil.WriteFile(filename+".lock", nil, fileMode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale := time.Now().Add(-2 * staleLockAge)
+	err = os.Chtimes(filename+".lock", stale, stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Update(filename, func(s *Stats) error {
+		s.RecordCommand("read", time.Second, false)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected a stale lock to be removed, got %s", err)
+	}
+}
+
+func TestCollector_Save(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, Filename)
+
+	// The configuration directory is not created to store statistics.
+	c := NewCollector()
+	share, err := c.Save(filepath.Join(dir, "missing", Filename), "read", time.Second, false)
+	if err != nil || share {
+		t.Fatalf("expected nothing to be saved without a configuration directory, got %t, %v", share, err)
+	}
+
+	err = Update(filename, func(s *Stats) error {
+		return s.EnableSharing("https://collector.example.com")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.RecordCache(true)
+	c.RecordCache(false)
+	share, err = c.Save(filename, "read", time.Second, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !share {
+		t.Error("expected a report to be due after opt-in")
+	}
+
+	share, err = c.Save(filename, "read", time.Second, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if share {
+		t.Error("expected the report to be marked as sent")
+	}
+
+	s, err := Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := s.Commands["read"]
+	if read.Count != 2 || read.Errors != 1 {
+		t.Errorf("unexpected stats for read: %+v", read)
+	}
+	if s.CacheHits != 1 || s.CacheMisses != 1 {
+		t.Errorf("expected the cache lookups to be saved once, got %d hits and %d misses", s.CacheHits, s.CacheMisses)
+	}
+}
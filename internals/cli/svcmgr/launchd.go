@@ -0,0 +1,162 @@
+package svcmgr
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	launchdDaemonDir = "/Library/LaunchDaemons"
+	launchdLogDir    = "/Library/Logs/secrethub"
+)
+
+var launchdPIDPattern = regexp.MustCompile(`"PID"\s*=\s*[0-9]+;`)
+
+// launchd manages services as launchd daemons.
+type launchd struct {
+	dir    string
+	logDir string
+	run    func(name string, args ...string) (string, error)
+}
+
+func newLaunchd() *launchd {
+	return &launchd{
+		dir:    launchdDaemonDir,
+		logDir: launchdLogDir,
+		run:    runCommand,
+	}
+}
+
+func (l *launchd) plistFile(name string) string {
+	return filepath.Join(l.dir, name+".plist")
+}
+
+// Install writes a property list for the service and loads it.
+func (l *launchd) Install(config Config, overwrite bool) error {
+	path := l.plistFile(config.Name)
+	_, err := os.Stat(path)
+	exists := err == nil
+	if exists && !overwrite {
+		return ErrAlreadyInstalled(config.Name)
+	}
+
+	if exists {
+		// Unload the old definition, so that loading the new one takes effect.
+		_, _ = l.run("launchctl", "unload", path)
+	}
+
+	err = os.MkdirAll(l.logDir, 0755)
+	if err != nil {
+		return err
+	}
+
+	plist := launchdPlist(config, filepath.Join(l.logDir, config.Name+".log"))
+	err = ioutil.WriteFile(path, plist, 0644)
+	if err != nil {
+		return err
+	}
+
+	_, err = l.run("launchctl", "load", "-w", path)
+	return err
+}
+
+// Uninstall unloads the service and removes its property list.
+func (l *launchd) Uninstall(name string) error {
+	path := l.plistFile(name)
+	_, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return ErrNotInstalled(name)
+	}
+
+	_, err = l.run("launchctl", "unload", "-w", path)
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+// Status returns whether the property list exists and the service has a running process.
+func (l *launchd) Status(name string) (Status, error) {
+	status := Status{
+		Manager:  "launchd",
+		Location: l.plistFile(name),
+	}
+
+	_, err := os.Stat(status.Location)
+	if os.IsNotExist(err) {
+		return status, nil
+	} else if err != nil {
+		return status, err
+	}
+	status.Installed = true
+
+	// The output only contains a PID when the service is running.
+	out, err := l.run("launchctl", "list", name)
+	status.Running = err == nil && launchdPIDPattern.MatchString(out)
+	return status, nil
+}
+
+// launchdPlist returns the property list for the service. The service is
+// started at boot and restarted whenever it exits with a failure.
+func launchdPlist(config Config, logFile string) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprint(&buf, xml.Header)
+	fmt.Fprintln(&buf, `<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">`)
+	fmt.Fprintln(&buf, `<plist version="1.0">`)
+	fmt.Fprintln(&buf, "<dict>")
+
+	fmt.Fprintf(&buf, "\t<key>Label</key>\n\t<string>%s</string>\n", plistEscape(config.Name))
+
+	fmt.Fprintln(&buf, "\t<key>ProgramArguments</key>")
+	fmt.Fprintln(&buf, "\t<array>")
+	for _, arg := range append([]string{config.Executable}, config.Args...) {
+		fmt.Fprintf(&buf, "\t\t<string>%s</string>\n", plistEscape(arg))
+	}
+	fmt.Fprintln(&buf, "\t</array>")
+
+	if len(config.Env) > 0 {
+		fmt.Fprintln(&buf, "\t<key>EnvironmentVariables</key>")
+		fmt.Fprintln(&buf, "\t<dict>")
+		for _, key := range sortedKeys(config.Env) {
+			fmt.Fprintf(&buf, "\t\t<key>%s</key>\n\t\t<string>%s</string>\n", plistEscape(key), plistEscape(config.Env[key]))
+		}
+		fmt.Fprintln(&buf, "\t</dict>")
+	}
+
+	if config.User != "" {
+		fmt.Fprintf(&buf, "\t<key>UserName</key>\n\t<string>%s</string>\n", plistEscape(config.User))
+	}
+
+	fmt.Fprintln(&buf, "\t<key>RunAtLoad</key>\n\t<true/>")
+	fmt.Fprintln(&buf, "\t<key>KeepAlive</key>\n\t<dict>\n\t\t<key>SuccessfulExit</key>\n\t\t<false/>\n\t</dict>")
+	fmt.Fprintf(&buf, "\t<key>ThrottleInterval</key>\n\t<integer>%d</integer>\n", int(RestartDelay.Seconds()))
+	fmt.Fprintln(&buf, "\t<key>ProcessType</key>\n\t<string>Background</string>")
+	// launchd expects the umask as a decimal number.
+	fmt.Fprintf(&buf, "\t<key>Umask</key>\n\t<integer>%d</integer>\n", 0077)
+	fmt.Fprintf(&buf, "\t<key>StandardOutPath</key>\n\t<string>%s</string>\n", plistEscape(logFile))
+	fmt.Fprintf(&buf, "\t<key>StandardErrorPath</key>\n\t<string>%s</string>\n", plistEscape(logFile))
+
+	fmt.Fprintln(&buf, "</dict>")
+	fmt.Fprintln(&buf, "</plist>")
+
+	return buf.Bytes()
+}
+
+// plistEscape escapes the characters that have a special meaning in XML.
+func plistEscape(s string) string {
+	return strings.NewReplacer(
+		"&", "&amp;",
+		"<", "&lt;",
+		">", "&gt;",
+		`"`, "&quot;",
+		"'", "&apos;",
+	).Replace(s)
+}
@@ -0,0 +1,70 @@
+package svcmgr
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestLaunchdPlist(t *testing.T) {
+	plist := launchdPlist(Config{
+		Name:       "secrethub-sync",
+		Executable: "/usr/local/bin/secrethub",
+		Args:       []string{"ssh", "sync-authorized-keys", "<company>&co"},
+		Env: map[string]string{
+			"SECRETHUB_CONFIG_DIR": "/var/root/.secrethub",
+		},
+		User: "deploy",
+	}, "/Library/Logs/secrethub/secrethub-sync.log")
+
+	// The property list must be well-formed XML.
+	decoder := xml.NewDecoder(strings.NewReader(string(plist)))
+	for {
+		_, err := decoder.Token()
+		if err != nil {
+			assert.Equal(t, err.Error(), "EOF")
+			break
+		}
+	}
+
+	expected := []string{
+		"<key>Label</key>\n\t<string>secrethub-sync</string>",
+		"\t\t<string>/usr/local/bin/secrethub</string>\n\t\t<string>ssh</string>\n\t\t<string>sync-authorized-keys</string>\n\t\t<string>&lt;company&gt;&amp;co</string>\n",
+		"<key>SECRETHUB_CONFIG_DIR</key>\n\t\t<string>/var/root/.secrethub</string>",
+		"<key>UserName</key>\n\t<string>deploy</string>",
+		"<key>SuccessfulExit</key>\n\t\t<false/>",
+		"<key>ThrottleInterval</key>\n\t<integer>5</integer>",
+		"<key>Umask</key>\n\t<integer>63</integer>",
+		"<key>StandardErrorPath</key>\n\t<string>/Library/Logs/secrethub/secrethub-sync.log</string>",
+	}
+
+	for _, s := range expected {
+		if !strings.Contains(string(plist), s) {
+			t.Errorf("property list does not contain %q:\n%s", s, plist)
+		}
+	}
+}
+
+func TestLaunchdStatus(t *testing.T) {
+	cases := map[string]struct {
+		output   string
+		expected bool
+	}{
+		"running": {
+			output:   "{\n\t\"Label\" = \"secrethub-sync\";\n\t\"PID\" = 1234;\n};",
+			expected: true,
+		},
+		"stopped": {
+			output:   "{\n\t\"Label\" = \"secrethub-sync\";\n\t\"LastExitStatus\" = 256;\n};",
+			expected: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, launchdPIDPattern.MatchString(tc.output), tc.expected)
+		})
+	}
+}
@@ -0,0 +1,11 @@
+// +build !windows
+
+package svcmgr
+
+import "os/exec"
+
+// Run runs the command of a service. Service managers other than the one
+// of Windows manage the process directly, so the command is simply run.
+func Run(name string, cmd *exec.Cmd) error {
+	return cmd.Run()
+}
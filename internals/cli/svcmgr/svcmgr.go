@@ -0,0 +1,80 @@
+// Package svcmgr installs long-running processes as services with the service
+// manager of the operating system: systemd on Linux, launchd on macOS and the
+// Service Control Manager on Windows. Services are restarted when they fail
+// and are started again when the machine boots.
+package svcmgr
+
+import (
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/secrethub/secrethub-go/internals/errio"
+)
+
+// Errors
+var (
+	errSvcmgr           = errio.Namespace("svcmgr")
+	ErrUnsupportedOS    = errSvcmgr.Code("unsupported_os").ErrorPref("installing services is not supported on %s")
+	ErrAlreadyInstalled = errSvcmgr.Code("already_installed").ErrorPref("the service %s is already installed")
+	ErrNotInstalled     = errSvcmgr.Code("not_installed").ErrorPref("the service %s is not installed")
+	ErrCommandFailed    = errSvcmgr.Code("command_failed").ErrorPref("%s failed: %s")
+	ErrUserNotSupported = errSvcmgr.Code("user_not_supported").Error("running a service as another user is not supported on this operating system")
+)
+
+// RestartDelay is the time the service manager waits before restarting a failed service.
+const RestartDelay = 5 * time.Second
+
+// Config describes a service.
+type Config struct {
+	// Name uniquely identifies the service, e.g. secrethub-sync.
+	Name string
+	// Description is a human readable description of the service.
+	Description string
+	// Executable is the absolute path to the program to run.
+	Executable string
+	// Args are passed to the executable.
+	Args []string
+	// Env contains additional environment variables for the service.
+	Env map[string]string
+	// User is the user to run the service as. When empty, the service
+	// runs as the default user of the service manager.
+	User string
+	// WritablePaths are the paths the service needs to write to. On systems
+	// that sandbox services, all other paths are read-only to the service.
+	WritablePaths []string
+}
+
+// Status describes the state of an installed service.
+type Status struct {
+	Installed bool
+	Running   bool
+	// Manager is the name of the service manager the service is installed with.
+	Manager string
+	// Location is the file or registry key the service is defined in.
+	Location string
+}
+
+// Manager installs and removes services.
+type Manager interface {
+	// Install registers the service, enables it to start at boot and starts it.
+	// When overwrite is set, an existing service with the same name is replaced.
+	Install(config Config, overwrite bool) error
+	// Uninstall stops the service and removes it.
+	Uninstall(name string) error
+	// Status returns the state of the service.
+	Status(name string) (Status, error)
+}
+
+// runCommand runs a command of the service manager and returns its output.
+func runCommand(name string, args ...string) (string, error) {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", ErrCommandFailed(name+" "+strings.Join(args, " "), msg)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,6 @@
+package svcmgr
+
+// New returns the service manager of the operating system.
+func New() (Manager, error) {
+	return newLaunchd(), nil
+}
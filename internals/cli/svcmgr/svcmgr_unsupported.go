@@ -0,0 +1,10 @@
+// +build !linux,!darwin,!windows
+
+package svcmgr
+
+import "runtime"
+
+// New returns the service manager of the operating system.
+func New() (Manager, error) {
+	return nil, ErrUnsupportedOS(runtime.GOOS)
+}
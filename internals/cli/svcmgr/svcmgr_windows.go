@@ -0,0 +1,240 @@
+package svcmgr
+
+import (
+	"os"
+	"os/exec"
+	"sort"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+const serviceRegistryKey = `SYSTEM\CurrentControlSet\Services\`
+
+// resetPeriod is the number of seconds without failures after which the
+// failure count of a service is reset.
+const resetPeriod = 24 * 60 * 60
+
+// New returns the service manager of the operating system.
+func New() (Manager, error) {
+	return scm{}, nil
+}
+
+// scm manages services with the Windows Service Control Manager.
+type scm struct{}
+
+// Install creates or updates the service, configures it to restart on failures and starts it.
+func (scm) Install(config Config, overwrite bool) error {
+	if config.User != "" {
+		return ErrUserNotSupported
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(config.Name)
+	if err == nil {
+		defer s.Close()
+		if !overwrite {
+			return ErrAlreadyInstalled(config.Name)
+		}
+
+		// Stop the running service, so that starting it again uses the new configuration.
+		_, _ = s.Control(svc.Stop)
+
+		current, err := s.Config()
+		if err != nil {
+			return err
+		}
+		current.DisplayName = config.Name
+		current.Description = config.Description
+		current.StartType = mgr.StartAutomatic
+		current.BinaryPathName = commandLine(config.Executable, config.Args)
+		err = s.UpdateConfig(current)
+		if err != nil {
+			return err
+		}
+	} else {
+		s, err = m.CreateService(config.Name, config.Executable, mgr.Config{
+			DisplayName: config.Name,
+			Description: config.Description,
+			StartType:   mgr.StartAutomatic,
+		}, config.Args...)
+		if err != nil {
+			return err
+		}
+		defer s.Close()
+	}
+
+	err = setEnvironment(config.Name, config.Env)
+	if err != nil {
+		return err
+	}
+
+	err = s.SetRecoveryActions([]mgr.RecoveryAction{
+		{Type: mgr.ServiceRestart, Delay: RestartDelay},
+		{Type: mgr.ServiceRestart, Delay: RestartDelay},
+		{Type: mgr.ServiceRestart, Delay: RestartDelay},
+	}, resetPeriod)
+	if err != nil {
+		return err
+	}
+
+	return s.Start()
+}
+
+// Uninstall stops the service and marks it for deletion.
+func (scm) Uninstall(name string) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(name)
+	if err == windows.ERROR_SERVICE_DOES_NOT_EXIST {
+		return ErrNotInstalled(name)
+	} else if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	_, _ = s.Control(svc.Stop)
+
+	return s.Delete()
+}
+
+// Status returns whether the service exists and is running.
+func (scm) Status(name string) (Status, error) {
+	status := Status{
+		Manager:  "Service Control Manager",
+		Location: `HKEY_LOCAL_MACHINE\` + serviceRegistryKey + name,
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return status, err
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(name)
+	if err == windows.ERROR_SERVICE_DOES_NOT_EXIST {
+		return status, nil
+	} else if err != nil {
+		return status, err
+	}
+	defer s.Close()
+	status.Installed = true
+
+	state, err := s.Query()
+	if err != nil {
+		return status, err
+	}
+	status.Running = state.State == svc.Running
+	return status, nil
+}
+
+// setEnvironment sets the environment variables of the service, which the
+// Service Control Manager reads from the registry key of the service.
+func setEnvironment(name string, env map[string]string) error {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, serviceRegistryKey+name, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+
+	if len(env) == 0 {
+		err = key.DeleteValue("Environment")
+		if err == registry.ErrNotExist {
+			return nil
+		}
+		return err
+	}
+
+	values := make([]string, 0, len(env))
+	for k, v := range env {
+		values = append(values, k+"="+v)
+	}
+	sort.Strings(values)
+	return key.SetStringsValue("Environment", values)
+}
+
+// commandLine returns the command line the Service Control Manager runs the service with.
+func commandLine(executable string, args []string) string {
+	cmdLine := syscall.EscapeArg(executable)
+	for _, arg := range args {
+		cmdLine += " " + syscall.EscapeArg(arg)
+	}
+	return cmdLine
+}
+
+// Run runs the command of a service. When started by the Service Control
+// Manager, the process reports its state to it and stops the command when
+// the service is stopped. Otherwise, the command is simply run.
+func Run(name string, cmd *exec.Cmd) error {
+	interactive, err := svc.IsAnInteractiveSession()
+	if err != nil {
+		return err
+	}
+	if interactive {
+		return cmd.Run()
+	}
+
+	return svc.Run(name, &handler{cmd: cmd})
+}
+
+// handler runs a command as a Windows service.
+type handler struct {
+	cmd *exec.Cmd
+}
+
+// Execute starts the command and waits for it to exit or for the service to be stopped.
+func (h *handler) Execute(args []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	const accepted = svc.AcceptStop | svc.AcceptShutdown
+
+	changes <- svc.Status{State: svc.StartPending}
+
+	err := h.cmd.Start()
+	if err != nil {
+		return true, 1
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- h.cmd.Wait()
+	}()
+
+	changes <- svc.Status{State: svc.Running, Accepts: accepted}
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				// Exit without reporting the service as stopped, so that the
+				// Service Control Manager considers it failed and restarts it.
+				os.Exit(1)
+			}
+			return false, 0
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				changes <- req.CurrentStatus
+				// Reply twice, see the documentation of svc.Handler.
+				time.Sleep(100 * time.Millisecond)
+				changes <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				changes <- svc.Status{State: svc.StopPending}
+				_ = h.cmd.Process.Kill()
+				<-done
+				return false, 0
+			}
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package svcmgr
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SystemdUnitDir is the directory with the unit files of the system administrator.
+const SystemdUnitDir = "/etc/systemd/system"
+
+// systemd manages services as systemd units.
+type systemd struct {
+	dir string
+	run func(name string, args ...string) (string, error)
+}
+
+func newSystemd() *systemd {
+	return &systemd{
+		dir: SystemdUnitDir,
+		run: runCommand,
+	}
+}
+
+func (s *systemd) unitFile(name string) string {
+	return filepath.Join(s.dir, name+".service")
+}
+
+// Install writes a unit file for the service, enables it and starts it.
+func (s *systemd) Install(config Config, overwrite bool) error {
+	path := s.unitFile(config.Name)
+	_, err := os.Stat(path)
+	exists := err == nil
+	if exists && !overwrite {
+		return ErrAlreadyInstalled(config.Name)
+	}
+
+	err = ioutil.WriteFile(path, systemdUnit(config), 0644)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.run("systemctl", "daemon-reload")
+	if err != nil {
+		return err
+	}
+
+	_, err = s.run("systemctl", "enable", config.Name)
+	if err != nil {
+		return err
+	}
+
+	// Restarting also starts a service that is not yet running.
+	_, err = s.run("systemctl", "restart", config.Name)
+	return err
+}
+
+// Uninstall stops and disables the service and removes its unit file.
+func (s *systemd) Uninstall(name string) error {
+	path := s.unitFile(name)
+	_, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return ErrNotInstalled(name)
+	}
+
+	_, err = s.run("systemctl", "disable", "--now", name)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.run("systemctl", "daemon-reload")
+	return err
+}
+
+// Status returns whether the unit file exists and the service is active.
+func (s *systemd) Status(name string) (Status, error) {
+	status := Status{
+		Manager:  "systemd",
+		Location: s.unitFile(name),
+	}
+
+	_, err := os.Stat(status.Location)
+	if os.IsNotExist(err) {
+		return status, nil
+	} else if err != nil {
+		return status, err
+	}
+	status.Installed = true
+
+	// is-active exits with a non-zero status code when the service is not running.
+	out, err := s.run("systemctl", "is-active", name)
+	status.Running = err == nil && strings.TrimSpace(out) == "active"
+	return status, nil
+}
+
+// systemdUnit returns the unit file for the service. The service is restarted
+// whenever it fails and is sandboxed: it cannot gain new privileges, has a
+// private /tmp and /dev and can only write to its writable paths.
+func systemdUnit(config Config) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintln(&buf, "[Unit]")
+	fmt.Fprintf(&buf, "Description=%s\n", config.Description)
+	fmt.Fprintln(&buf, "Wants=network-online.target")
+	fmt.Fprintln(&buf, "After=network-online.target")
+	// Never stop restarting the service, it may be waiting for the network to come back.
+	fmt.Fprintln(&buf, "StartLimitIntervalSec=0")
+	fmt.Fprintln(&buf)
+
+	fmt.Fprintln(&buf, "[Service]")
+	fmt.Fprintln(&buf, "Type=simple")
+	args := make([]string, 0, len(config.Args)+1)
+	for _, arg := range append([]string{config.Executable}, config.Args...) {
+		// Only ExecStart expands variables, so $ is escaped here.
+		args = append(args, SystemdQuote(strings.Replace(arg, "$", "$$", -1)))
+	}
+	fmt.Fprintf(&buf, "ExecStart=%s\n", strings.Join(args, " "))
+	fmt.Fprintln(&buf, "Restart=on-failure")
+	fmt.Fprintf(&buf, "RestartSec=%d\n", int(RestartDelay.Seconds()))
+	for _, key := range sortedKeys(config.Env) {
+		fmt.Fprintf(&buf, "Environment=%s\n", SystemdQuote(key+"="+config.Env[key]))
+	}
+	if config.User != "" {
+		fmt.Fprintf(&buf, "User=%s\n", config.User)
+	}
+	fmt.Fprintln(&buf, "UMask=0077")
+	fmt.Fprintln(&buf, "NoNewPrivileges=yes")
+	fmt.Fprintln(&buf, "PrivateTmp=yes")
+	fmt.Fprintln(&buf, "PrivateDevices=yes")
+	fmt.Fprintln(&buf, "ProtectSystem=strict")
+	fmt.Fprintln(&buf, "ProtectHome=read-only")
+	for _, path := range config.WritablePaths {
+		fmt.Fprintf(&buf, "ReadWritePaths=%s\n", SystemdQuote(path))
+	}
+	fmt.Fprintln(&buf, "ProtectKernelTunables=yes")
+	fmt.Fprintln(&buf, "ProtectKernelModules=yes")
+	fmt.Fprintln(&buf, "ProtectControlGroups=yes")
+	fmt.Fprintln(&buf, "RestrictSUIDSGID=yes")
+	fmt.Fprintln(&buf, "RestrictRealtime=yes")
+	fmt.Fprintln(&buf, "LockPersonality=yes")
+	fmt.Fprintln(&buf)
+
+	fmt.Fprintln(&buf, "[Install]")
+	fmt.Fprintln(&buf, "WantedBy=multi-user.target")
+
+	return buf.Bytes()
+}
+
+// ReloadSystemd makes systemd reload its unit files, e.g. after they have been changed.
+func ReloadSystemd() error {
+	_, err := runCommand("systemctl", "daemon-reload")
+	return err
+}
+
+// SystemdQuote quotes a value for use in a unit file when necessary.
+// Specifiers (%) are escaped so that they are passed as is.
+func SystemdQuote(s string) string {
+	s = strings.Replace(s, "%", "%%", -1)
+	if s != "" && !strings.ContainsAny(s, " \t\n\"'\\;") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`).Replace(s) + `"`
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,131 @@
+package svcmgr
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestSystemdUnit(t *testing.T) {
+	unit := string(systemdUnit(Config{
+		Name:        "secrethub-sync",
+		Description: "Synchronizes SSH keys.",
+		Executable:  "/usr/local/bin/secrethub",
+		Args:        []string{"ssh", "sync-authorized-keys", "company/ssh keys", "--interval=15m", "$HOME", "100%"},
+		Env: map[string]string{
+			"SECRETHUB_CONFIG_DIR": "/root/.secrethub",
+			"A":                    "with space",
+		},
+		User:          "deploy",
+		WritablePaths: []string{"/home"},
+	}))
+
+	expected := []string{
+		"Description=Synchronizes SSH keys.",
+		`ExecStart=/usr/local/bin/secrethub ssh sync-authorized-keys "company/ssh keys" --interval=15m $$HOME 100%%`,
+		"Restart=on-failure",
+		"RestartSec=5",
+		`Environment="A=with space"` + "\nEnvironment=SECRETHUB_CONFIG_DIR=/root/.secrethub\n",
+		"User=deploy",
+		"NoNewPrivileges=yes",
+		"ProtectSystem=strict",
+		"ReadWritePaths=/home",
+		"WantedBy=multi-user.target",
+	}
+
+	for _, line := range expected {
+		if !strings.Contains(unit, line) {
+			t.Errorf("unit file does not contain %q:\n%s", line, unit)
+		}
+	}
+}
+
+func TestSystemdQuote(t *testing.T) {
+	cases := map[string]struct {
+		in       string
+		expected string
+	}{
+		"plain": {
+			in:       "/usr/bin/secrethub",
+			expected: "/usr/bin/secrethub",
+		},
+		"empty": {
+			in:       "",
+			expected: `""`,
+		},
+		"space": {
+			in:       "a b",
+			expected: `"a b"`,
+		},
+		"quotes and backslashes": {
+			in:       `a"b\c`,
+			expected: `"a\"b\\c"`,
+		},
+		"specifier": {
+			in:       "%h",
+			expected: "%%h",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, SystemdQuote(tc.in), tc.expected)
+		})
+	}
+}
+
+func TestSystemdInstall(t *testing.T) {
+	dir, err := ioutil.TempDir("", "svcmgr")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	var commands []string
+	s := &systemd{
+		dir: dir,
+		run: func(name string, args ...string) (string, error) {
+			commands = append(commands, name+" "+strings.Join(args, " "))
+			return "active\n", nil
+		},
+	}
+
+	config := Config{
+		Name:       "secrethub-sync",
+		Executable: "/usr/local/bin/secrethub",
+	}
+
+	err = s.Install(config, false)
+	assert.OK(t, err)
+	assert.Equal(t, commands, []string{
+		"systemctl daemon-reload",
+		"systemctl enable secrethub-sync",
+		"systemctl restart secrethub-sync",
+	})
+
+	_, err = os.Stat(filepath.Join(dir, "secrethub-sync.service"))
+	assert.OK(t, err)
+
+	err = s.Install(config, false)
+	assert.Equal(t, err, ErrAlreadyInstalled("secrethub-sync"))
+
+	err = s.Install(config, true)
+	assert.OK(t, err)
+
+	status, err := s.Status("secrethub-sync")
+	assert.OK(t, err)
+	assert.Equal(t, status.Installed, true)
+	assert.Equal(t, status.Running, true)
+
+	err = s.Uninstall("secrethub-sync")
+	assert.OK(t, err)
+
+	status, err = s.Status("secrethub-sync")
+	assert.OK(t, err)
+	assert.Equal(t, status.Installed, false)
+
+	err = s.Uninstall("secrethub-sync")
+	assert.Equal(t, err, ErrNotInstalled("secrethub-sync"))
+}
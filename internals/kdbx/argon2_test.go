@@ -0,0 +1,44 @@
+package kdbx
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// Test vectors from RFC 9106, section 5.
+func TestArgon2Key(t *testing.T) {
+	password := bytes.Repeat([]byte{0x01}, 32)
+	salt := bytes.Repeat([]byte{0x02}, 16)
+	secret := bytes.Repeat([]byte{0x03}, 8)
+	data := bytes.Repeat([]byte{0x04}, 12)
+
+	cases := map[string]struct {
+		mode     int
+		expected string
+	}{
+		"argon2d": {
+			mode:     argon2d,
+			expected: "512b391b6f1162975371d30919734294f868e3be3984f3c1a13a4db9fabe4acb",
+		},
+		"argon2i": {
+			mode:     argon2i,
+			expected: "c814d9d1dc7f37aa13f0d77f2494bda1c8de6b016dd388d29952a4c4672b6ce8",
+		},
+		"argon2id": {
+			mode:     argon2id,
+			expected: "0d640df58d78766c08c037a34a8b53c9d01ef0452d75b65eb52520e96b01e659",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			key := argon2Key(tc.mode, argon2Version13, password, salt, secret, data, 3, 32, 4, 32)
+
+			actual := hex.EncodeToString(key)
+			if actual != tc.expected {
+				t.Errorf("unexpected key:\n%s (actual)\n%s (expected)", actual, tc.expected)
+			}
+		})
+	}
+}
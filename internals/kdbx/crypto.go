@@ -0,0 +1,157 @@
+package kdbx
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/sha512"
+
+	"golang.org/x/crypto/twofish"
+)
+
+var (
+	cipherAES256   = []byte{0x31, 0xc1, 0xf2, 0xe6, 0xbf, 0x71, 0x43, 0x50, 0xbe, 0x58, 0x05, 0x21, 0x6a, 0xfc, 0x5a, 0xff}
+	cipherChaCha20 = []byte{0xd6, 0x03, 0x8a, 0x2b, 0x8b, 0x6f, 0x4c, 0xb5, 0xa5, 0x24, 0x33, 0x9a, 0x31, 0xdb, 0xb5, 0x9a}
+	cipherTwofish  = []byte{0xad, 0x68, 0xf2, 0x9f, 0x57, 0x6f, 0x4b, 0xb9, 0xa3, 0x6a, 0xd4, 0x7a, 0xf9, 0x65, 0x34, 0x6c}
+
+	kdfAES      = []byte{0xc9, 0xd9, 0xf3, 0x9a, 0x62, 0x8a, 0x44, 0x60, 0xbf, 0x74, 0x0d, 0x08, 0xc1, 0x8a, 0x4f, 0xea}
+	kdfArgon2d  = []byte{0xef, 0x63, 0x6d, 0xdf, 0x8c, 0x29, 0x44, 0x4b, 0x91, 0xf7, 0xa9, 0xa4, 0x03, 0xe3, 0x0a, 0x0c}
+	kdfArgon2id = []byte{0x9e, 0x29, 0x8b, 0x19, 0x56, 0xdb, 0x47, 0x73, 0xb2, 0x3d, 0xfc, 0x3e, 0xc6, 0xf0, 0xa1, 0xe6}
+)
+
+// Inner random stream IDs, used to protect values inside the XML.
+const (
+	innerStreamNone     = 0
+	innerStreamSalsa20  = 2
+	innerStreamChaCha20 = 3
+)
+
+// maxArgon2Memory is the maximum amount of memory in bytes the Argon2 KDF of a database can use.
+// The memory is allocated before the key is checked, so without a limit any file could make us
+// allocate up to 4 TiB. KeePass uses 64 MiB by default.
+const maxArgon2Memory = 1 << 30
+
+// salsa20Nonce is the fixed nonce of the Salsa20 inner random stream.
+var salsa20Nonce = []byte{0xe8, 0x30, 0x09, 0x4b, 0x97, 0x20, 0x5d, 0x2a}
+
+// aesKDF transforms the composite key with the given number of AES-256 encryption rounds.
+func aesKDF(compositeKey, seed []byte, rounds uint64) ([]byte, error) {
+	block, err := aes.NewCipher(seed)
+	if err != nil {
+		return nil, ErrInvalidKDFParameters
+	}
+
+	key := make([]byte, len(compositeKey))
+	copy(key, compositeKey)
+	for i := uint64(0); i < rounds; i++ {
+		block.Encrypt(key[:16], key[:16])
+		block.Encrypt(key[16:], key[16:])
+	}
+
+	sum := sha256.Sum256(key)
+	return sum[:], nil
+}
+
+// deriveKey transforms the composite key with the KDF described by the KDBX 4 parameters.
+func deriveKey(params variantDictionary, compositeKey []byte) ([]byte, error) {
+	id := params.bytes("$UUID")
+	salt := params.bytes("S")
+
+	switch {
+	case bytes.Equal(id, kdfAES):
+		rounds, ok := params.uint("R")
+		if !ok || len(salt) != 32 {
+			return nil, ErrInvalidKDFParameters
+		}
+		return aesKDF(compositeKey, salt, rounds)
+	case bytes.Equal(id, kdfArgon2d), bytes.Equal(id, kdfArgon2id):
+		iterations, okI := params.uint("I")
+		memory, okM := params.uint("M")
+		parallelism, okP := params.uint("P")
+		version, okV := params.uint("V")
+		if !okI || !okM || !okP || !okV || iterations == 0 || parallelism == 0 ||
+			iterations > 1<<32-1 || memory/1024 > 1<<32-1 || parallelism > 1<<24 ||
+			(version != argon2Version10 && version != argon2Version13) {
+			return nil, ErrInvalidKDFParameters
+		}
+
+		// Argon2 uses at least 8 blocks of 1 KiB per lane.
+		required := memory
+		if 8*1024*parallelism > required {
+			required = 8 * 1024 * parallelism
+		}
+		if required > maxArgon2Memory {
+			return nil, ErrKDFMemoryTooLarge(required>>20, maxArgon2Memory>>20)
+		}
+
+		mode := argon2d
+		if bytes.Equal(id, kdfArgon2id) {
+			mode = argon2id
+		}
+
+		return argon2Key(mode, uint32(version), compositeKey, salt, params.bytes("K"), params.bytes("A"),
+			uint32(iterations), uint32(memory/1024), uint32(parallelism), 32), nil
+	default:
+		return nil, ErrUnsupportedKDF(id)
+	}
+}
+
+// decryptPayload decrypts the database payload with the cipher identified by the given ID.
+// A padding error is reported as invalid credentials, because that is the most likely cause.
+func decryptPayload(cipherID, key, iv, payload []byte) ([]byte, error) {
+	var block cipher.Block
+	var err error
+	switch {
+	case bytes.Equal(cipherID, cipherAES256):
+		block, err = aes.NewCipher(key)
+	case bytes.Equal(cipherID, cipherTwofish):
+		block, err = twofish.NewCipher(key)
+	case bytes.Equal(cipherID, cipherChaCha20):
+		if len(iv) != 12 {
+			return nil, ErrCorruptHeader
+		}
+		plain := make([]byte, len(payload))
+		newChaCha20(key, iv).XORKeyStream(plain, payload)
+		return plain, nil
+	default:
+		return nil, ErrUnsupportedCipher(cipherID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(iv) != block.BlockSize() || len(payload) == 0 || len(payload)%block.BlockSize() != 0 {
+		return nil, ErrCorruptDatabase
+	}
+
+	plain := make([]byte, len(payload))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, payload)
+
+	padding := int(plain[len(plain)-1])
+	if padding == 0 || padding > block.BlockSize() {
+		return nil, ErrInvalidCredentials
+	}
+	for _, b := range plain[len(plain)-padding:] {
+		if int(b) != padding {
+			return nil, ErrInvalidCredentials
+		}
+	}
+	return plain[:len(plain)-padding], nil
+}
+
+// newInnerStream returns the stream cipher that protects values inside the XML.
+func newInnerStream(id uint32, key []byte) (cipher.Stream, error) {
+	switch id {
+	case innerStreamNone:
+		return nil, nil
+	case innerStreamSalsa20:
+		sum := sha256.Sum256(key)
+		return newSalsa20(sum[:], salsa20Nonce), nil
+	case innerStreamChaCha20:
+		sum := sha512.Sum512(key)
+		return newChaCha20(sum[:32], sum[32:44]), nil
+	default:
+		return nil, ErrUnsupportedInnerStream(id)
+	}
+}
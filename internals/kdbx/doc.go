@@ -0,0 +1,6 @@
+// Package kdbx reads KeePass databases in the KDBX 3.1 and KDBX 4 formats.
+//
+// Databases encrypted with AES-256, ChaCha20 or Twofish and keys derived
+// with AES-KDF, Argon2d or Argon2id are supported. The database is
+// decrypted entirely in memory and is never written to disk.
+package kdbx
@@ -0,0 +1,30 @@
+package kdbx
+
+import (
+	"fmt"
+
+	"github.com/secrethub/secrethub-go/internals/errio"
+)
+
+// Errors
+var (
+	errKDBX = errio.Namespace("kdbx")
+
+	ErrInvalidSignature          = errKDBX.Code("invalid_signature").Error("not a KeePass database: the file signature is invalid")
+	ErrUnsupportedVersion        = errKDBX.Code("unsupported_version").ErrorPref("KeePass database version %s is not supported, convert it to KDBX 3.1 or KDBX 4")
+	ErrCorruptHeader             = errKDBX.Code("corrupt_header").Error("the header of the database is corrupt")
+	ErrCorruptDatabase           = errKDBX.Code("corrupt_database").Error("the database is corrupt")
+	ErrInvalidCredentials        = errKDBX.Code("invalid_credentials").Error("the master password or key file is incorrect")
+	ErrUnsupportedCipher         = errKDBX.Code("unsupported_cipher").ErrorPref("the cipher %x of the database is not supported")
+	ErrUnsupportedKDF            = errKDBX.Code("unsupported_kdf").ErrorPref("the key derivation function %x of the database is not supported")
+	ErrInvalidKDFParameters      = errKDBX.Code("invalid_kdf_parameters").Error("the key derivation parameters of the database are invalid")
+	ErrKDFMemoryTooLarge         = errKDBX.Code("kdf_memory_too_large").ErrorPref("the key derivation function of the database requires %d MiB of memory, more than the maximum of %d MiB")
+	ErrUnsupportedInnerStream    = errKDBX.Code("unsupported_inner_stream").ErrorPref("the inner random stream %d of the database is not supported")
+	ErrNoKeyComponents           = errKDBX.Code("no_key_components").Error("a master password or key file is required to open the database")
+	ErrInvalidKeyFile            = errKDBX.Code("invalid_key_file").Error("the key file is invalid")
+	ErrUnsupportedKeyFileVersion = errKDBX.Code("unsupported_key_file_version").ErrorPref("key file version %s is not supported")
+)
+
+func formatVersion(major, minor uint16) string {
+	return fmt.Sprintf("%d.%d", major, minor)
+}
@@ -0,0 +1,279 @@
+package kdbx
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+const (
+	signature1       uint32 = 0x9AA2D903
+	signature2       uint32 = 0xB54BFB67
+	signature2Legacy uint32 = 0xB54BFB65 // KeePass 1.x (.kdb)
+)
+
+// Outer header field IDs.
+const (
+	fieldEndOfHeader         = 0
+	fieldCipherID            = 2
+	fieldCompressionFlags    = 3
+	fieldMasterSeed          = 4
+	fieldTransformSeed       = 5
+	fieldTransformRounds     = 6
+	fieldEncryptionIV        = 7
+	fieldProtectedStreamKey  = 8
+	fieldStreamStartBytes    = 9
+	fieldInnerRandomStreamID = 10
+	fieldKdfParameters       = 11
+)
+
+// Inner header field IDs (KDBX 4).
+const (
+	innerFieldEnd             = 0
+	innerFieldRandomStreamID  = 1
+	innerFieldRandomStreamKey = 2
+	innerFieldBinary          = 3
+)
+
+// header contains the fields of the unencrypted outer header.
+type header struct {
+	majorVersion uint16
+	minorVersion uint16
+
+	cipherID     []byte
+	compressed   bool
+	masterSeed   []byte
+	encryptionIV []byte
+
+	// KDBX 3.1 only.
+	transformSeed       []byte
+	transformRounds     uint64
+	protectedStreamKey  []byte
+	streamStartBytes    []byte
+	innerRandomStreamID uint32
+
+	// KDBX 4 only.
+	kdfParameters variantDictionary
+}
+
+// readHeader parses the outer header and returns it together with its length in bytes.
+func readHeader(data []byte) (*header, int, error) {
+	r := bytes.NewReader(data)
+
+	var preamble struct {
+		Signature1 uint32
+		Signature2 uint32
+		Minor      uint16
+		Major      uint16
+	}
+	err := binary.Read(r, binary.LittleEndian, &preamble)
+	if err != nil || preamble.Signature1 != signature1 {
+		return nil, 0, ErrInvalidSignature
+	}
+	if preamble.Signature2 == signature2Legacy {
+		return nil, 0, ErrUnsupportedVersion("1.x")
+	}
+	if preamble.Signature2 != signature2 {
+		return nil, 0, ErrInvalidSignature
+	}
+	if preamble.Major != 3 && preamble.Major != 4 {
+		return nil, 0, ErrUnsupportedVersion(formatVersion(preamble.Major, preamble.Minor))
+	}
+
+	h := &header{
+		majorVersion: preamble.Major,
+		minorVersion: preamble.Minor,
+	}
+
+	for {
+		var id uint8
+		err = binary.Read(r, binary.LittleEndian, &id)
+		if err != nil {
+			return nil, 0, ErrCorruptHeader
+		}
+
+		var size uint32
+		if h.majorVersion >= 4 {
+			err = binary.Read(r, binary.LittleEndian, &size)
+		} else {
+			var size16 uint16
+			err = binary.Read(r, binary.LittleEndian, &size16)
+			size = uint32(size16)
+		}
+		if err != nil || int64(size) > int64(r.Len()) {
+			return nil, 0, ErrCorruptHeader
+		}
+
+		value := make([]byte, size)
+		_, err = r.Read(value)
+		if err != nil && size > 0 {
+			return nil, 0, ErrCorruptHeader
+		}
+
+		if id == fieldEndOfHeader {
+			break
+		}
+
+		err = h.setField(id, value)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	err = h.validate()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return h, len(data) - r.Len(), nil
+}
+
+func (h *header) setField(id uint8, value []byte) error {
+	switch id {
+	case fieldCipherID:
+		h.cipherID = value
+	case fieldCompressionFlags:
+		if len(value) != 4 {
+			return ErrCorruptHeader
+		}
+		h.compressed = binary.LittleEndian.Uint32(value) == 1
+	case fieldMasterSeed:
+		h.masterSeed = value
+	case fieldTransformSeed:
+		h.transformSeed = value
+	case fieldTransformRounds:
+		if len(value) != 8 {
+			return ErrCorruptHeader
+		}
+		h.transformRounds = binary.LittleEndian.Uint64(value)
+	case fieldEncryptionIV:
+		h.encryptionIV = value
+	case fieldProtectedStreamKey:
+		h.protectedStreamKey = value
+	case fieldStreamStartBytes:
+		h.streamStartBytes = value
+	case fieldInnerRandomStreamID:
+		if len(value) != 4 {
+			return ErrCorruptHeader
+		}
+		h.innerRandomStreamID = binary.LittleEndian.Uint32(value)
+	case fieldKdfParameters:
+		params, err := readVariantDictionary(value)
+		if err != nil {
+			return err
+		}
+		h.kdfParameters = params
+	}
+	// Comments and public custom data are ignored.
+	return nil
+}
+
+func (h *header) validate() error {
+	if len(h.cipherID) != 16 || len(h.masterSeed) != 32 || len(h.encryptionIV) == 0 {
+		return ErrCorruptHeader
+	}
+
+	if h.majorVersion == 3 {
+		if len(h.transformSeed) != 32 || len(h.streamStartBytes) != 32 || len(h.protectedStreamKey) == 0 {
+			return ErrCorruptHeader
+		}
+	} else if h.kdfParameters == nil {
+		return ErrCorruptHeader
+	}
+	return nil
+}
+
+// Value types of a variant dictionary.
+const (
+	variantUInt32    = 0x04
+	variantUInt64    = 0x05
+	variantBool      = 0x08
+	variantInt32     = 0x0C
+	variantInt64     = 0x0D
+	variantString    = 0x18
+	variantByteArray = 0x42
+)
+
+// variantDictionary is a typed key/value map used by KDBX 4 to store the KDF parameters.
+type variantDictionary map[string]interface{}
+
+func readVariantDictionary(data []byte) (variantDictionary, error) {
+	r := bytes.NewReader(data)
+
+	var version uint16
+	err := binary.Read(r, binary.LittleEndian, &version)
+	if err != nil || version>>8 != 0x01 {
+		return nil, ErrCorruptHeader
+	}
+
+	dict := variantDictionary{}
+	for {
+		var typ uint8
+		err = binary.Read(r, binary.LittleEndian, &typ)
+		if err != nil {
+			return nil, ErrCorruptHeader
+		}
+		if typ == 0 {
+			return dict, nil
+		}
+
+		name, err := readSizedBytes(r)
+		if err != nil {
+			return nil, err
+		}
+		value, err := readSizedBytes(r)
+		if err != nil {
+			return nil, err
+		}
+
+		switch typ {
+		case variantUInt32, variantInt32:
+			if len(value) != 4 {
+				return nil, ErrCorruptHeader
+			}
+			dict[string(name)] = uint64(binary.LittleEndian.Uint32(value))
+		case variantUInt64, variantInt64:
+			if len(value) != 8 {
+				return nil, ErrCorruptHeader
+			}
+			dict[string(name)] = binary.LittleEndian.Uint64(value)
+		case variantBool:
+			if len(value) != 1 {
+				return nil, ErrCorruptHeader
+			}
+			dict[string(name)] = value[0] != 0
+		case variantString:
+			dict[string(name)] = string(value)
+		case variantByteArray:
+			dict[string(name)] = value
+		default:
+			return nil, ErrCorruptHeader
+		}
+	}
+}
+
+func readSizedBytes(r *bytes.Reader) ([]byte, error) {
+	var size int32
+	err := binary.Read(r, binary.LittleEndian, &size)
+	if err != nil || size < 0 || int64(size) > int64(r.Len()) {
+		return nil, ErrCorruptHeader
+	}
+
+	value := make([]byte, size)
+	_, err = r.Read(value)
+	if err != nil && size > 0 {
+		return nil, ErrCorruptHeader
+	}
+	return value, nil
+}
+
+// uint returns the unsigned integer value stored under the given name.
+func (d variantDictionary) uint(name string) (uint64, bool) {
+	v, ok := d[name].(uint64)
+	return v, ok
+}
+
+// bytes returns the byte array stored under the given name.
+func (d variantDictionary) bytes(name string) []byte {
+	v, _ := d[name].([]byte)
+	return v
+}
@@ -0,0 +1,317 @@
+package kdbx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math"
+)
+
+// Database is a decrypted KeePass database.
+type Database struct {
+	// Root is the top-level group containing all other groups and entries.
+	Root *Group
+}
+
+// Group is a folder of entries and sub-groups.
+type Group struct {
+	UUID    string
+	Name    string
+	Notes   string
+	Groups  []*Group
+	Entries []*Entry
+	// RecycleBin is true when the group is the recycle bin of the database.
+	RecycleBin bool
+}
+
+// Entry is a single record in the database, e.g. a login.
+type Entry struct {
+	UUID        string
+	Fields      []Field
+	Attachments []Attachment
+}
+
+// Field is a string value of an entry. The standard fields are
+// Title, UserName, Password, URL and Notes.
+type Field struct {
+	Key       string
+	Value     string
+	Protected bool
+}
+
+// Attachment is a file attached to an entry.
+type Attachment struct {
+	Name string
+	Data []byte
+}
+
+// Get returns the value of the field with the given key,
+// or an empty string when the entry has no such field.
+func (e *Entry) Get(key string) string {
+	for _, field := range e.Fields {
+		if field.Key == key {
+			return field.Value
+		}
+	}
+	return ""
+}
+
+// Title returns the title of the entry.
+func (e *Entry) Title() string {
+	return e.Get("Title")
+}
+
+// Open decrypts the database read from r with the given key.
+func Open(r io.Reader, key *Key) (*Database, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	h, headerLen, err := readHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.majorVersion == 3 {
+		return openV3(h, data[headerLen:], key)
+	}
+	return openV4(h, data, headerLen, key)
+}
+
+// openV3 decrypts the payload of a KDBX 3.1 database.
+func openV3(h *header, payload []byte, key *Key) (*Database, error) {
+	transformed, err := aesKDF(key.hash(), h.transformSeed, h.transformRounds)
+	if err != nil {
+		return nil, err
+	}
+	masterKey := sha256.Sum256(append(append([]byte{}, h.masterSeed...), transformed...))
+
+	plain, err := decryptPayload(h.cipherID, masterKey[:], h.encryptionIV, payload)
+	if err != nil {
+		return nil, err
+	}
+	if len(plain) < len(h.streamStartBytes) || !hmac.Equal(plain[:len(h.streamStartBytes)], h.streamStartBytes) {
+		return nil, ErrInvalidCredentials
+	}
+
+	content, err := readHashedBlocks(plain[len(h.streamStartBytes):])
+	if err != nil {
+		return nil, err
+	}
+
+	if h.compressed {
+		content, err = gunzip(content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	stream, err := newInnerStream(h.innerRandomStreamID, h.protectedStreamKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseXML(content, stream, nil)
+}
+
+// openV4 verifies and decrypts a KDBX 4 database.
+func openV4(h *header, data []byte, headerLen int, key *Key) (*Database, error) {
+	if len(data) < headerLen+64 {
+		return nil, ErrCorruptHeader
+	}
+
+	headerSum := sha256.Sum256(data[:headerLen])
+	if !hmac.Equal(headerSum[:], data[headerLen:headerLen+32]) {
+		return nil, ErrCorruptHeader
+	}
+
+	transformed, err := deriveKey(h.kdfParameters, key.hash())
+	if err != nil {
+		return nil, err
+	}
+
+	seeded := append(append([]byte{}, h.masterSeed...), transformed...)
+	masterKey := sha256.Sum256(seeded)
+	hmacKey := sha512.Sum512(append(seeded, 0x01))
+
+	mac := hmac.New(sha256.New, hmacBlockKey(math.MaxUint64, hmacKey[:]))
+	mac.Write(data[:headerLen])
+	if !hmac.Equal(mac.Sum(nil), data[headerLen+32:headerLen+64]) {
+		return nil, ErrInvalidCredentials
+	}
+
+	payload, err := readHMACBlocks(data[headerLen+64:], hmacKey[:])
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := decryptPayload(h.cipherID, masterKey[:], h.encryptionIV, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.compressed {
+		plain, err = gunzip(plain)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r := bytes.NewReader(plain)
+	stream, binaries, err := readInnerHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseXML(plain[len(plain)-r.Len():], stream, binaries)
+}
+
+// readHashedBlocks reads the hashed block stream of KDBX 3.1, verifying the hash of each block.
+func readHashedBlocks(data []byte) ([]byte, error) {
+	r := bytes.NewReader(data)
+	var out bytes.Buffer
+	for {
+		var blockHeader struct {
+			Index uint32
+			Hash  [32]byte
+			Size  int32
+		}
+		err := binary.Read(r, binary.LittleEndian, &blockHeader)
+		if err != nil || blockHeader.Size < 0 || int64(blockHeader.Size) > int64(r.Len()) {
+			return nil, ErrCorruptDatabase
+		}
+
+		if blockHeader.Size == 0 {
+			return out.Bytes(), nil
+		}
+
+		block := make([]byte, blockHeader.Size)
+		_, err = io.ReadFull(r, block)
+		if err != nil {
+			return nil, ErrCorruptDatabase
+		}
+
+		sum := sha256.Sum256(block)
+		if !hmac.Equal(sum[:], blockHeader.Hash[:]) {
+			return nil, ErrCorruptDatabase
+		}
+		out.Write(block)
+	}
+}
+
+// readHMACBlocks reads the HMAC block stream of KDBX 4, verifying the HMAC of each block.
+func readHMACBlocks(data []byte, hmacKey []byte) ([]byte, error) {
+	r := bytes.NewReader(data)
+	var out bytes.Buffer
+	for index := uint64(0); ; index++ {
+		var blockHeader struct {
+			MAC  [32]byte
+			Size int32
+		}
+		err := binary.Read(r, binary.LittleEndian, &blockHeader)
+		if err != nil || blockHeader.Size < 0 || int64(blockHeader.Size) > int64(r.Len()) {
+			return nil, ErrCorruptDatabase
+		}
+
+		block := make([]byte, blockHeader.Size)
+		_, err = io.ReadFull(r, block)
+		if err != nil {
+			return nil, ErrCorruptDatabase
+		}
+
+		var prefix [12]byte
+		binary.LittleEndian.PutUint64(prefix[:8], index)
+		binary.LittleEndian.PutUint32(prefix[8:], uint32(blockHeader.Size))
+
+		mac := hmac.New(sha256.New, hmacBlockKey(index, hmacKey))
+		mac.Write(prefix[:])
+		mac.Write(block)
+		if !hmac.Equal(mac.Sum(nil), blockHeader.MAC[:]) {
+			return nil, ErrCorruptDatabase
+		}
+
+		if blockHeader.Size == 0 {
+			return out.Bytes(), nil
+		}
+		out.Write(block)
+	}
+}
+
+// hmacBlockKey returns the HMAC key for the block with the given index.
+func hmacBlockKey(index uint64, hmacKey []byte) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], index)
+	h := sha512.New()
+	h.Write(buf[:])
+	h.Write(hmacKey)
+	return h.Sum(nil)
+}
+
+// readInnerHeader reads the inner header of KDBX 4, which contains the key
+// of the inner random stream and the attachments of the database.
+func readInnerHeader(r *bytes.Reader) (cipher.Stream, [][]byte, error) {
+	var streamID uint32
+	var streamKey []byte
+	var binaries [][]byte
+	for {
+		var id uint8
+		err := binary.Read(r, binary.LittleEndian, &id)
+		if err != nil {
+			return nil, nil, ErrCorruptDatabase
+		}
+		var size uint32
+		err = binary.Read(r, binary.LittleEndian, &size)
+		if err != nil || int64(size) > int64(r.Len()) {
+			return nil, nil, ErrCorruptDatabase
+		}
+		value := make([]byte, size)
+		_, err = io.ReadFull(r, value)
+		if err != nil {
+			return nil, nil, ErrCorruptDatabase
+		}
+
+		switch id {
+		case innerFieldEnd:
+			stream, err := newInnerStream(streamID, streamKey)
+			if err != nil {
+				return nil, nil, err
+			}
+			return stream, binaries, nil
+		case innerFieldRandomStreamID:
+			if size != 4 {
+				return nil, nil, ErrCorruptDatabase
+			}
+			streamID = binary.LittleEndian.Uint32(value)
+		case innerFieldRandomStreamKey:
+			streamKey = value
+		case innerFieldBinary:
+			if size == 0 {
+				return nil, nil, ErrCorruptDatabase
+			}
+			// The first byte contains flags, e.g. whether the binary should be
+			// protected in memory. The binary itself is not encrypted.
+			binaries = append(binaries, value[1:])
+		}
+	}
+}
+
+func gunzip(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrCorruptDatabase
+	}
+	defer r.Close()
+
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, ErrCorruptDatabase
+	}
+	return out, nil
+}
@@ -0,0 +1,393 @@
+package kdbx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+// testXML returns a database XML document. Protected values are encrypted
+// in document order with protect. Attachment refers to binary with ID 0.
+func testXML(protect func(string) string, metaBinaries string) string {
+	return `<?xml version="1.0" encoding="utf-8" standalone="yes"?>
+<KeePassFile>
+	<Meta>
+		<RecycleBinEnabled>True</RecycleBinEnabled>
+		<RecycleBinUUID>cmVjeWNsZWJpbg==</RecycleBinUUID>` + metaBinaries + `
+	</Meta>
+	<Root>
+		<Group>
+			<UUID>cm9vdA==</UUID>
+			<Name>Database</Name>
+			<Entry>
+				<UUID>ZW50cnkx</UUID>
+				<String><Key>Title</Key><Value>Mail</Value></String>
+				<String><Key>UserName</Key><Value>alice</Value></String>
+				<String><Key>Password</Key><Value Protected="True">` + protect("p4ssw0rd") + `</Value></String>
+				<Binary><Key>key.pem</Key><Value Ref="0"/></Binary>
+				<History>
+					<Entry>
+						<String><Key>Password</Key><Value Protected="True">` + protect("old") + `</Value></String>
+					</Entry>
+				</History>
+			</Entry>
+			<Group>
+				<UUID>c3Vi</UUID>
+				<Name>Servers</Name>
+				<Entry>
+					<String><Key>Title</Key><Value>db</Value></String>
+					<String><Key>Password</Key><Value Protected="True">` + protect("s3cr3t & <more>") + `</Value></String>
+				</Entry>
+			</Group>
+			<Group>
+				<UUID>cmVjeWNsZWJpbg==</UUID>
+				<Name>Recycle Bin</Name>
+			</Group>
+		</Group>
+	</Root>
+</KeePassFile>`
+}
+
+func protector(stream cipher.Stream) func(string) string {
+	return func(value string) string {
+		out := make([]byte, len(value))
+		stream.XORKeyStream(out, []byte(value))
+		return base64.StdEncoding.EncodeToString(out)
+	}
+}
+
+func writeField(buf *bytes.Buffer, id uint8, value []byte, v4 bool) {
+	buf.WriteByte(id)
+	if v4 {
+		_ = binary.Write(buf, binary.LittleEndian, uint32(len(value)))
+	} else {
+		_ = binary.Write(buf, binary.LittleEndian, uint16(len(value)))
+	}
+	buf.Write(value)
+}
+
+func uint32Bytes(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	return b
+}
+
+func uint64Bytes(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, v)
+	return b
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write(data)
+	assert.OK(t, err)
+	assert.OK(t, w.Close())
+	return buf.Bytes()
+}
+
+// buildV3 creates a KDBX 3.1 database with AES-KDF, AES-256 and a Salsa20 inner stream.
+func buildV3(t *testing.T, key *Key) []byte {
+	masterSeed := bytes.Repeat([]byte{1}, 32)
+	transformSeed := bytes.Repeat([]byte{2}, 32)
+	iv := bytes.Repeat([]byte{3}, 16)
+	streamKey := bytes.Repeat([]byte{4}, 32)
+	startBytes := bytes.Repeat([]byte{5}, 32)
+	rounds := uint64(1000)
+
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, []uint32{signature1, signature2, 0x00030001})
+	writeField(&buf, fieldCipherID, cipherAES256, false)
+	writeField(&buf, fieldCompressionFlags, uint32Bytes(1), false)
+	writeField(&buf, fieldMasterSeed, masterSeed, false)
+	writeField(&buf, fieldTransformSeed, transformSeed, false)
+	writeField(&buf, fieldTransformRounds, uint64Bytes(rounds), false)
+	writeField(&buf, fieldEncryptionIV, iv, false)
+	writeField(&buf, fieldProtectedStreamKey, streamKey, false)
+	writeField(&buf, fieldStreamStartBytes, startBytes, false)
+	writeField(&buf, fieldInnerRandomStreamID, uint32Bytes(innerStreamSalsa20), false)
+	writeField(&buf, fieldEndOfHeader, []byte("\r\n\r\n"), false)
+
+	stream, err := newInnerStream(innerStreamSalsa20, streamKey)
+	assert.OK(t, err)
+	protect := protector(stream)
+
+	// The attachment is stored compressed and protected in the meta data.
+	attachment := protect(string(gzipBytes(t, []byte("attachment"))))
+	doc := testXML(protect, `<Binaries><Binary ID="0" Compressed="True" Protected="True">`+attachment+`</Binary></Binaries>`)
+	content := gzipBytes(t, []byte(doc))
+
+	var blocks bytes.Buffer
+	blocks.Write(startBytes)
+	hash := sha256.Sum256(content)
+	_ = binary.Write(&blocks, binary.LittleEndian, uint32(0))
+	blocks.Write(hash[:])
+	_ = binary.Write(&blocks, binary.LittleEndian, int32(len(content)))
+	blocks.Write(content)
+	_ = binary.Write(&blocks, binary.LittleEndian, uint32(1))
+	blocks.Write(make([]byte, 32))
+	_ = binary.Write(&blocks, binary.LittleEndian, int32(0))
+
+	plain := blocks.Bytes()
+	padding := aes.BlockSize - len(plain)%aes.BlockSize
+	plain = append(plain, bytes.Repeat([]byte{byte(padding)}, padding)...)
+
+	transformed, err := aesKDF(key.hash(), transformSeed, rounds)
+	assert.OK(t, err)
+	masterKey := sha256.Sum256(append(append([]byte{}, masterSeed...), transformed...))
+	block, err := aes.NewCipher(masterKey[:])
+	assert.OK(t, err)
+	encrypted := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, plain)
+
+	buf.Write(encrypted)
+	return buf.Bytes()
+}
+
+// buildV4 creates a KDBX 4 database with Argon2d, ChaCha20 and a ChaCha20 inner stream.
+func buildV4(t *testing.T, key *Key) []byte {
+	masterSeed := bytes.Repeat([]byte{1}, 32)
+	salt := bytes.Repeat([]byte{2}, 32)
+	iv := bytes.Repeat([]byte{3}, 12)
+	streamKey := bytes.Repeat([]byte{4}, 64)
+
+	var kdf bytes.Buffer
+	_ = binary.Write(&kdf, binary.LittleEndian, uint16(0x0100))
+	writeVariant := func(typ uint8, name string, value []byte) {
+		kdf.WriteByte(typ)
+		_ = binary.Write(&kdf, binary.LittleEndian, int32(len(name)))
+		kdf.WriteString(name)
+		_ = binary.Write(&kdf, binary.LittleEndian, int32(len(value)))
+		kdf.Write(value)
+	}
+	writeVariant(variantByteArray, "$UUID", kdfArgon2d)
+	writeVariant(variantByteArray, "S", salt)
+	writeVariant(variantUInt64, "I", uint64Bytes(2))
+	writeVariant(variantUInt64, "M", uint64Bytes(64*1024))
+	writeVariant(variantUInt32, "P", uint32Bytes(2))
+	writeVariant(variantUInt32, "V", uint32Bytes(argon2Version13))
+	kdf.WriteByte(0)
+
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, []uint32{signature1, signature2, 0x00040000})
+	writeField(&buf, fieldCipherID, cipherChaCha20, true)
+	writeField(&buf, fieldCompressionFlags, uint32Bytes(0), true)
+	writeField(&buf, fieldMasterSeed, masterSeed, true)
+	writeField(&buf, fieldEncryptionIV, iv, true)
+	writeField(&buf, fieldKdfParameters, kdf.Bytes(), true)
+	writeField(&buf, fieldEndOfHeader, []byte("\r\n\r\n"), true)
+	header := buf.Bytes()
+
+	transformed, err := deriveKey(variantDictionary{
+		"$UUID": kdfArgon2d,
+		"S":     salt,
+		"I":     uint64(2),
+		"M":     uint64(64 * 1024),
+		"P":     uint64(2),
+		"V":     uint64(argon2Version13),
+	}, key.hash())
+	assert.OK(t, err)
+	seeded := append(append([]byte{}, masterSeed...), transformed...)
+	masterKey := sha256.Sum256(seeded)
+	hmacKey := sha512.Sum512(append(seeded, 0x01))
+
+	headerSum := sha256.Sum256(header)
+	mac := hmac.New(sha256.New, hmacBlockKey(math.MaxUint64, hmacKey[:]))
+	mac.Write(header)
+	headerMAC := mac.Sum(nil)
+
+	stream, err := newInnerStream(innerStreamChaCha20, streamKey)
+	assert.OK(t, err)
+
+	var inner bytes.Buffer
+	writeField(&inner, innerFieldRandomStreamID, uint32Bytes(innerStreamChaCha20), true)
+	writeField(&inner, innerFieldRandomStreamKey, streamKey, true)
+	writeField(&inner, innerFieldBinary, append([]byte{0x01}, "attachment"...), true)
+	writeField(&inner, innerFieldEnd, nil, true)
+	inner.WriteString(testXML(protector(stream), ""))
+
+	encrypted := make([]byte, inner.Len())
+	newChaCha20(masterKey[:], iv).XORKeyStream(encrypted, inner.Bytes())
+
+	out := bytes.NewBuffer(append([]byte{}, header...))
+	out.Write(headerSum[:])
+	out.Write(headerMAC)
+	for i, block := range [][]byte{encrypted, nil} {
+		var prefix [12]byte
+		binary.LittleEndian.PutUint64(prefix[:8], uint64(i))
+		binary.LittleEndian.PutUint32(prefix[8:], uint32(len(block)))
+		mac := hmac.New(sha256.New, hmacBlockKey(uint64(i), hmacKey[:]))
+		mac.Write(prefix[:])
+		mac.Write(block)
+		out.Write(mac.Sum(nil))
+		_ = binary.Write(out, binary.LittleEndian, int32(len(block)))
+		out.Write(block)
+	}
+	return out.Bytes()
+}
+
+func TestOpen(t *testing.T) {
+	key, err := NewKey([]byte("master"), nil)
+	assert.OK(t, err)
+	wrongKey, err := NewKey([]byte("wrong"), nil)
+	assert.OK(t, err)
+
+	cases := map[string]struct {
+		build func(*testing.T, *Key) []byte
+	}{
+		"kdbx 3.1": {
+			build: buildV3,
+		},
+		"kdbx 4": {
+			build: buildV4,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			data := tc.build(t, key)
+
+			db, err := Open(bytes.NewReader(data), key)
+			assert.OK(t, err)
+
+			root := db.Root
+			assert.Equal(t, root.Name, "Database")
+			assert.Equal(t, len(root.Entries), 1)
+			assert.Equal(t, len(root.Groups), 2)
+
+			mail := root.Entries[0]
+			assert.Equal(t, mail.Title(), "Mail")
+			assert.Equal(t, mail.Get("UserName"), "alice")
+			assert.Equal(t, mail.Get("Password"), "p4ssw0rd")
+			assert.Equal(t, mail.Attachments, []Attachment{{Name: "key.pem", Data: []byte("attachment")}})
+
+			servers := root.Groups[0]
+			assert.Equal(t, servers.Name, "Servers")
+			assert.Equal(t, servers.RecycleBin, false)
+			assert.Equal(t, servers.Entries[0].Get("Password"), "s3cr3t & <more>")
+
+			assert.Equal(t, root.Groups[1].RecycleBin, true)
+
+			_, err = Open(bytes.NewReader(data), wrongKey)
+			assert.Equal(t, err, ErrInvalidCredentials)
+		})
+	}
+}
+
+func TestOpen_InvalidSignature(t *testing.T) {
+	key, err := NewKey([]byte("master"), nil)
+	assert.OK(t, err)
+
+	_, err = Open(bytes.NewReader([]byte("not a database")), key)
+	assert.Equal(t, err, ErrInvalidSignature)
+}
+
+func TestDeriveKey_Argon2MemoryTooLarge(t *testing.T) {
+	cases := map[string]struct {
+		memory      uint64
+		parallelism uint64
+		err         error
+	}{
+		"memory": {
+			memory:      4 << 30,
+			parallelism: 2,
+			err:         ErrKDFMemoryTooLarge(uint64(4096), uint64(1024)),
+		},
+		"parallelism": {
+			memory:      64 << 20,
+			parallelism: 1 << 24,
+			err:         ErrKDFMemoryTooLarge(uint64(131072), uint64(1024)),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			_, err := deriveKey(variantDictionary{
+				"$UUID": kdfArgon2id,
+				"S":     make([]byte, 32),
+				"I":     uint64(2),
+				"M":     tc.memory,
+				"P":     tc.parallelism,
+				"V":     uint64(argon2Version13),
+			}, make([]byte, 32))
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+		})
+	}
+}
+
+func TestNewKey(t *testing.T) {
+	raw := bytes.Repeat([]byte{0xab}, 32)
+	hashed := sha256.Sum256([]byte("some random file"))
+
+	cases := map[string]struct {
+		keyFile  string
+		expected []byte
+		err      error
+	}{
+		"binary": {
+			keyFile:  string(raw),
+			expected: raw,
+		},
+		"hex": {
+			keyFile:  fmt.Sprintf("%x", raw),
+			expected: raw,
+		},
+		"xml v1": {
+			keyFile: `<?xml version="1.0" encoding="utf-8"?>
+<KeyFile><Meta><Version>1.00</Version></Meta><Key><Data>` + base64.StdEncoding.EncodeToString(raw) + `</Data></Key></KeyFile>`,
+			expected: raw,
+		},
+		"xml v2": {
+			keyFile: `<?xml version="1.0" encoding="utf-8"?>
+<KeyFile><Meta><Version>2.0</Version></Meta><Key><Data Hash="` + fmt.Sprintf("%x", sha256Prefix(raw)) + `">
+	ABABABAB ABABABAB ABABABAB ABABABAB
+	ABABABAB ABABABAB ABABABAB ABABABAB
+</Data></Key></KeyFile>`,
+			expected: raw,
+		},
+		"xml v2 invalid hash": {
+			keyFile: `<?xml version="1.0" encoding="utf-8"?>
+<KeyFile><Meta><Version>2.0</Version></Meta><Key><Data Hash="00000000">ABAB</Data></Key></KeyFile>`,
+			err: ErrInvalidKeyFile,
+		},
+		"other": {
+			keyFile:  "some random file",
+			expected: hashed[:],
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			key, err := NewKey(nil, []byte(tc.keyFile))
+			assert.Equal(t, err, tc.err)
+			if tc.err == nil {
+				assert.Equal(t, key.components, [][]byte{tc.expected})
+			}
+		})
+	}
+}
+
+func TestNewKey_NoComponents(t *testing.T) {
+	_, err := NewKey(nil, nil)
+	assert.Equal(t, err, ErrNoKeyComponents)
+}
+
+func sha256Prefix(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return sum[:4]
+}
@@ -0,0 +1,113 @@
+package kdbx
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"strings"
+)
+
+// Key is the composite master key used to unlock a database.
+type Key struct {
+	components [][]byte
+}
+
+// NewKey creates a composite key from a master password and the contents of a key file.
+// Pass a nil password or key file to leave out that component.
+func NewKey(password []byte, keyFile []byte) (*Key, error) {
+	key := &Key{}
+
+	if password != nil {
+		h := sha256.Sum256(password)
+		key.components = append(key.components, h[:])
+	}
+
+	if keyFile != nil {
+		component, err := parseKeyFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		key.components = append(key.components, component)
+	}
+
+	if len(key.components) == 0 {
+		return nil, ErrNoKeyComponents
+	}
+
+	return key, nil
+}
+
+// hash returns the SHA-256 hash of the concatenated key components.
+func (k *Key) hash() []byte {
+	h := sha256.New()
+	for _, component := range k.components {
+		h.Write(component)
+	}
+	return h.Sum(nil)
+}
+
+// keyFileXML is the XML key file format used by KeePass 2.x.
+type keyFileXML struct {
+	XMLName xml.Name `xml:"KeyFile"`
+	Version string   `xml:"Meta>Version"`
+	Data    struct {
+		Hash  string `xml:"Hash,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"Key>Data"`
+}
+
+// parseKeyFile returns the key component of a key file. Supported are
+// XML key files (version 1.0 and 2.0), 32-byte binary key files and
+// 64-character hexadecimal key files. Any other file is hashed.
+func parseKeyFile(data []byte) ([]byte, error) {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("<?xml")) || bytes.HasPrefix(trimmed, []byte("<KeyFile")) {
+		var kf keyFileXML
+		err := xml.Unmarshal(trimmed, &kf)
+		if err == nil {
+			return parseKeyFileXML(kf)
+		}
+	}
+
+	if len(data) == 32 {
+		return data, nil
+	}
+
+	if len(data) == 64 {
+		key, err := hex.DecodeString(string(data))
+		if err == nil {
+			return key, nil
+		}
+	}
+
+	h := sha256.Sum256(data)
+	return h[:], nil
+}
+
+func parseKeyFileXML(kf keyFileXML) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(kf.Version, "1."):
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(kf.Data.Value))
+		if err != nil {
+			return nil, ErrInvalidKeyFile
+		}
+		return key, nil
+	case strings.HasPrefix(kf.Version, "2."):
+		key, err := hex.DecodeString(strings.Join(strings.Fields(kf.Data.Value), ""))
+		if err != nil {
+			return nil, ErrInvalidKeyFile
+		}
+
+		if kf.Data.Hash != "" {
+			h := sha256.Sum256(key)
+			if !strings.EqualFold(hex.EncodeToString(h[:4]), kf.Data.Hash) {
+				return nil, ErrInvalidKeyFile
+			}
+		}
+		return key, nil
+	default:
+		return nil, ErrUnsupportedKeyFileVersion(kf.Version)
+	}
+}
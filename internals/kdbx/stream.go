@@ -0,0 +1,96 @@
+package kdbx
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"golang.org/x/crypto/salsa20/salsa"
+)
+
+// blockStream turns a function generating 64-byte key stream blocks into a cipher.Stream.
+type blockStream struct {
+	next func(block *[64]byte)
+	buf  [64]byte
+	used int
+}
+
+func newBlockStream(next func(block *[64]byte)) *blockStream {
+	return &blockStream{
+		next: next,
+		used: 64,
+	}
+}
+
+// XORKeyStream XORs each byte in src with a byte from the key stream.
+func (s *blockStream) XORKeyStream(dst, src []byte) {
+	for i := range src {
+		if s.used == 64 {
+			s.next(&s.buf)
+			s.used = 0
+		}
+		dst[i] = src[i] ^ s.buf[s.used]
+		s.used++
+	}
+}
+
+// newSalsa20 returns a Salsa20 key stream for the given 32-byte key and 8-byte nonce.
+func newSalsa20(key, nonce []byte) *blockStream {
+	var k [32]byte
+	copy(k[:], key)
+	var counter [16]byte
+	copy(counter[:8], nonce)
+	var zero [64]byte
+	n := uint64(0)
+
+	return newBlockStream(func(block *[64]byte) {
+		binary.LittleEndian.PutUint64(counter[8:], n)
+		salsa.XORKeyStream(block[:], zero[:], &counter, &k)
+		n++
+	})
+}
+
+// newChaCha20 returns a ChaCha20 (RFC 8439) key stream for the
+// given 32-byte key and 12-byte nonce, starting at block counter 0.
+func newChaCha20(key, nonce []byte) *blockStream {
+	var state [16]uint32
+	state[0], state[1], state[2], state[3] = 0x61707865, 0x3320646e, 0x79622d32, 0x6b206574
+	for i := 0; i < 8; i++ {
+		state[4+i] = binary.LittleEndian.Uint32(key[i*4:])
+	}
+	for i := 0; i < 3; i++ {
+		state[13+i] = binary.LittleEndian.Uint32(nonce[i*4:])
+	}
+
+	return newBlockStream(func(block *[64]byte) {
+		chacha20Block(&state, block)
+		state[12]++
+	})
+}
+
+func chacha20Block(state *[16]uint32, out *[64]byte) {
+	x := *state
+	for i := 0; i < 10; i++ {
+		chacha20QuarterRound(&x, 0, 4, 8, 12)
+		chacha20QuarterRound(&x, 1, 5, 9, 13)
+		chacha20QuarterRound(&x, 2, 6, 10, 14)
+		chacha20QuarterRound(&x, 3, 7, 11, 15)
+		chacha20QuarterRound(&x, 0, 5, 10, 15)
+		chacha20QuarterRound(&x, 1, 6, 11, 12)
+		chacha20QuarterRound(&x, 2, 7, 8, 13)
+		chacha20QuarterRound(&x, 3, 4, 9, 14)
+	}
+	for i := range x {
+		binary.LittleEndian.PutUint32(out[i*4:], x[i]+state[i])
+	}
+}
+
+func chacha20QuarterRound(x *[16]uint32, a, b, c, d int) {
+	x[a] += x[b]
+	x[d] = bits.RotateLeft32(x[d]^x[a], 16)
+	x[c] += x[d]
+	x[b] = bits.RotateLeft32(x[b]^x[c], 12)
+	x[a] += x[b]
+	x[d] = bits.RotateLeft32(x[d]^x[a], 8)
+	x[c] += x[d]
+	x[b] = bits.RotateLeft32(x[b]^x[c], 7)
+}
@@ -0,0 +1,33 @@
+package kdbx
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// Test vector from RFC 8439, section 2.4.2.
+func TestChaCha20(t *testing.T) {
+	key, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	nonce, _ := hex.DecodeString("000000000000004a00000000")
+	plaintext := "Ladies and Gentlemen of the class of '99: If I could offer you only one tip for the future, sunscreen would be it."
+	expected := "6e2e359a2568f98041ba0728dd0d6981e97e7aec1d4360c20a27afccfd9fae0b" +
+		"f91b65c5524733ab8f593dabcd62b3571639d624e65152ab8f530c359f0861d8" +
+		"07ca0dbf500d6a6156a38e088a22b65e52bc514d16ccf806818ce91ab7793736" +
+		"5af90bbf74a35be6b40b8eedf2785e42874d"
+
+	stream := newChaCha20(key, nonce)
+
+	// The test vector starts at block counter 1.
+	var skip [64]byte
+	stream.XORKeyStream(skip[:], skip[:])
+
+	// Encrypt in uneven parts to test the buffering of the key stream.
+	ciphertext := make([]byte, len(plaintext))
+	stream.XORKeyStream(ciphertext[:10], []byte(plaintext[:10]))
+	stream.XORKeyStream(ciphertext[10:], []byte(plaintext[10:]))
+
+	actual := hex.EncodeToString(ciphertext)
+	if actual != expected {
+		t.Errorf("unexpected ciphertext:\n%s (actual)\n%s (expected)", actual, expected)
+	}
+}
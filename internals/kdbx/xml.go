@@ -0,0 +1,238 @@
+package kdbx
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type xmlFile struct {
+	Meta struct {
+		RecycleBinEnabled string      `xml:"RecycleBinEnabled"`
+		RecycleBinUUID    string      `xml:"RecycleBinUUID"`
+		Binaries          []xmlBinary `xml:"Binaries>Binary"`
+	} `xml:"Meta"`
+	Root struct {
+		Groups []xmlGroup `xml:"Group"`
+	} `xml:"Root"`
+}
+
+type xmlGroup struct {
+	UUID    string     `xml:"UUID"`
+	Name    string     `xml:"Name"`
+	Notes   string     `xml:"Notes"`
+	Entries []xmlEntry `xml:"Entry"`
+	Groups  []xmlGroup `xml:"Group"`
+}
+
+// xmlEntry is an entry of a group. The history of the entry is ignored.
+type xmlEntry struct {
+	UUID     string         `xml:"UUID"`
+	Strings  []xmlString    `xml:"String"`
+	Binaries []xmlBinaryRef `xml:"Binary"`
+}
+
+type xmlString struct {
+	Key   string `xml:"Key"`
+	Value struct {
+		Protected string `xml:"Protected,attr"`
+		Content   string `xml:",chardata"`
+	} `xml:"Value"`
+}
+
+type xmlBinaryRef struct {
+	Key   string `xml:"Key"`
+	Value struct {
+		Ref string `xml:"Ref,attr"`
+	} `xml:"Value"`
+}
+
+// xmlBinary is an attachment stored in the XML of a KDBX 3.1 database.
+type xmlBinary struct {
+	ID         string `xml:"ID,attr"`
+	Compressed string `xml:"Compressed,attr"`
+	Protected  string `xml:"Protected,attr"`
+	Content    string `xml:",chardata"`
+}
+
+// parseXML parses the decrypted XML document of a database. Protected values are decrypted
+// with the inner stream. Binaries contains the attachments from the KDBX 4 inner header.
+func parseXML(data []byte, stream cipher.Stream, binaries [][]byte) (*Database, error) {
+	if stream != nil {
+		var err error
+		data, err = unprotectXML(data, stream)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var file xmlFile
+	err := xml.Unmarshal(data, &file)
+	if err != nil {
+		return nil, ErrCorruptDatabase
+	}
+
+	attachments := map[string][]byte{}
+	for i, binary := range binaries {
+		attachments[strconv.Itoa(i)] = binary
+	}
+	for _, binary := range file.Meta.Binaries {
+		content, err := base64.StdEncoding.DecodeString(strings.TrimSpace(binary.Content))
+		if err != nil {
+			return nil, ErrCorruptDatabase
+		}
+		if isTrue(binary.Compressed) {
+			content, err = gunzip(content)
+			if err != nil {
+				return nil, err
+			}
+		}
+		attachments[binary.ID] = content
+	}
+
+	recycleBin := ""
+	if isTrue(file.Meta.RecycleBinEnabled) {
+		recycleBin = file.Meta.RecycleBinUUID
+	}
+
+	root := &Group{}
+	for _, g := range file.Root.Groups {
+		group, err := convertGroup(g, attachments, recycleBin)
+		if err != nil {
+			return nil, err
+		}
+		root.Groups = append(root.Groups, group)
+	}
+
+	// KeePass databases have a single root group.
+	if len(root.Groups) == 1 {
+		root = root.Groups[0]
+	}
+
+	return &Database{Root: root}, nil
+}
+
+func convertGroup(g xmlGroup, attachments map[string][]byte, recycleBin string) (*Group, error) {
+	group := &Group{
+		UUID:       g.UUID,
+		Name:       g.Name,
+		Notes:      g.Notes,
+		RecycleBin: recycleBin != "" && g.UUID == recycleBin,
+	}
+
+	for _, e := range g.Entries {
+		entry := &Entry{
+			UUID: e.UUID,
+		}
+		for _, s := range e.Strings {
+			value := s.Value.Content
+			protected := isTrue(s.Value.Protected)
+			if protected {
+				// Protected values have been decrypted by unprotectXML, but are still base64 encoded.
+				decoded, err := base64.StdEncoding.DecodeString(value)
+				if err != nil {
+					return nil, ErrCorruptDatabase
+				}
+				value = string(decoded)
+			}
+			entry.Fields = append(entry.Fields, Field{
+				Key:       s.Key,
+				Value:     value,
+				Protected: protected,
+			})
+		}
+		for _, b := range e.Binaries {
+			data, ok := attachments[b.Value.Ref]
+			if !ok {
+				return nil, ErrCorruptDatabase
+			}
+			entry.Attachments = append(entry.Attachments, Attachment{
+				Name: b.Key,
+				Data: data,
+			})
+		}
+		group.Entries = append(group.Entries, entry)
+	}
+
+	for _, sub := range g.Groups {
+		subGroup, err := convertGroup(sub, attachments, recycleBin)
+		if err != nil {
+			return nil, err
+		}
+		group.Groups = append(group.Groups, subGroup)
+	}
+
+	return group, nil
+}
+
+// unprotectXML decrypts all protected values in the XML document with the inner stream.
+// The values must be decrypted in document order, as they share a single key stream.
+// Decrypted values are base64 encoded again, so they can contain any byte.
+func unprotectXML(data []byte, stream cipher.Stream) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var out bytes.Buffer
+	encoder := xml.NewEncoder(&out)
+
+	protected := false
+	var content bytes.Buffer
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, ErrCorruptDatabase
+		}
+
+		switch t := token.(type) {
+		case xml.ProcInst:
+			// The XML declaration is not needed to parse the document.
+			continue
+		case xml.StartElement:
+			for _, attr := range t.Attr {
+				if attr.Name.Local == "Protected" && isTrue(attr.Value) {
+					protected = true
+					content.Reset()
+				}
+			}
+		case xml.CharData:
+			if protected {
+				content.Write(t)
+				continue
+			}
+		case xml.EndElement:
+			if protected {
+				ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimSpace(content.String()))
+				if err != nil {
+					return nil, ErrCorruptDatabase
+				}
+				plaintext := make([]byte, len(ciphertext))
+				stream.XORKeyStream(plaintext, ciphertext)
+
+				err = encoder.EncodeToken(xml.CharData(base64.StdEncoding.EncodeToString(plaintext)))
+				if err != nil {
+					return nil, err
+				}
+				protected = false
+			}
+		}
+
+		err = encoder.EncodeToken(xml.CopyToken(token))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := encoder.Flush()
+	if err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func isTrue(value string) bool {
+	return strings.EqualFold(value, "true")
+}
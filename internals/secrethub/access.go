@@ -0,0 +1,181 @@
+package secrethub
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/errio"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+)
+
+// Errors
+var (
+	errAccess = errio.Namespace("access")
+
+	ErrInvalidAccessRequestID  = errAccess.Code("invalid_request_id").ErrorPref("invalid access request %q: request IDs have the format <namespace>/<repo>:<number>")
+	ErrAccessRequestNotFound   = errAccess.Code("request_not_found").ErrorPref("access request %s does not exist")
+	ErrAccessRequestNotPending = errAccess.Code("request_not_pending").ErrorPref("access request %s has already been %s")
+	ErrAccessReasonRequired    = errAccess.Code("reason_required").Error("a reason is required: set it with --reason")
+	ErrAccessRequestOtherRepo  = errAccess.Code("request_other_repo").ErrorPref("access request %s is for %s, which is not in %s")
+	ErrAccessRequestForged     = errAccess.Code("request_forged").ErrorPref("access request %s of %s was written by %s: only the requesting account can request access for itself")
+)
+
+const (
+	accessRequestPending  = "pending"
+	accessRequestApproved = "approved"
+	accessRequestDenied   = "denied"
+)
+
+// AccessCommand handles requests for access to directories.
+type AccessCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+}
+
+// NewAccessCommand creates a new AccessCommand.
+func NewAccessCommand(io ui.IO, newClient newClientFunc) *AccessCommand {
+	return &AccessCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command and its sub-commands on the provided Registerer.
+func (cmd *AccessCommand) Register(r command.Registerer) {
+	clause := r.Command("access", "Request access to a directory and approve or deny requests.")
+	clause.HelpLong("Access requests are stored with the metadata of the repository in the " + metadataRepoName + " repository of the namespace, " +
+		"together with who approved or denied them, when and why. Requesting access requires write permission on that repository. " +
+		"Because everyone who can request access can also change the requests of others, approving a request first checks in the audit log " +
+		"that it was written by the requesting account itself and has not been changed since. " +
+		"Approving a request sets the requested access rule, which is recorded in the audit log of the repository like any other access rule.")
+	NewAccessRequestCommand(cmd.io, cmd.newClient).Register(clause)
+	NewAccessListCommand(cmd.io, cmd.newClient).Register(clause)
+	NewAccessApproveCommand(cmd.io, cmd.newClient).Register(clause)
+	NewAccessDenyCommand(cmd.io, cmd.newClient).Register(clause)
+}
+
+// accessRequest is a request of an account for a permission on a directory.
+type accessRequest struct {
+	ID          int       `json:"id"`
+	Path        string    `json:"path"`
+	Permission  string    `json:"permission"`
+	Account     string    `json:"account"`
+	Reason      string    `json:"reason"`
+	RequestedAt time.Time `json:"requested_at"`
+	Status      string    `json:"status"`
+	// DecidedBy, DecidedAt and DecisionReason are set when the request is approved or denied.
+	DecidedBy      string     `json:"decided_by,omitempty"`
+	DecidedAt      *time.Time `json:"decided_at,omitempty"`
+	DecisionReason string     `json:"decision_reason,omitempty"`
+}
+
+// accessRequestID identifies an access request by its repository and its number in the repository.
+type accessRequestID struct {
+	repo   api.RepoPath
+	number int
+}
+
+// parseAccessRequestID parses a request ID with the format <namespace>/<repo>:<number>.
+func parseAccessRequestID(value string) (accessRequestID, error) {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 {
+		return accessRequestID{}, ErrInvalidAccessRequestID(value)
+	}
+
+	var repo api.RepoPath
+	err := repo.Set(parts[0])
+	if err != nil {
+		return accessRequestID{}, ErrInvalidAccessRequestID(value)
+	}
+
+	number, err := strconv.Atoi(parts[1])
+	if err != nil || number < 1 {
+		return accessRequestID{}, ErrInvalidAccessRequestID(value)
+	}
+
+	return accessRequestID{repo: repo, number: number}, nil
+}
+
+// String returns the request ID in the format accepted by parseAccessRequestID.
+func (id accessRequestID) String() string {
+	return id.repo.Value() + ":" + strconv.Itoa(id.number)
+}
+
+// repoOfPath returns the path of the repository a directory or secret is in.
+func repoOfPath(path string) api.RepoPath {
+	elements := strings.SplitN(path, "/", 3)
+	return api.RepoPath(api.JoinPaths(elements[0], elements[1]))
+}
+
+// addAccessRequest adds the request to the metadata and returns its ID.
+func (m *repoMetadata) addAccessRequest(repo api.RepoPath, request *accessRequest) accessRequestID {
+	request.ID = 1
+	for _, existing := range m.AccessRequests {
+		if existing.ID >= request.ID {
+			request.ID = existing.ID + 1
+		}
+	}
+	m.AccessRequests = append(m.AccessRequests, request)
+	return accessRequestID{repo: repo, number: request.ID}
+}
+
+// pendingAccessRequest returns the pending request with the given ID.
+func (m *repoMetadata) pendingAccessRequest(id accessRequestID) (*accessRequest, error) {
+	for _, request := range m.AccessRequests {
+		if request.ID != id.number {
+			continue
+		}
+		if request.Status != accessRequestPending {
+			return nil, ErrAccessRequestNotPending(id, request.Status)
+		}
+		return request, nil
+	}
+	return nil, ErrAccessRequestNotFound(id)
+}
+
+// verifyAccessRequest checks that the pending request was written by the account that requests
+// access and has not been changed since, and that it is for a directory in the repository of its ID.
+func verifyAccessRequest(client secrethub.ClientInterface, id accessRequestID, request *accessRequest) error {
+	var path api.DirPath
+	err := path.Set(request.Path)
+	if err != nil || repoOfPath(path.Value()) != id.repo {
+		return ErrAccessRequestOtherRepo(id, request.Path, id.repo)
+	}
+
+	author, err := metadataAuthor(client, id.repo.Value(), func(metadata *repoMetadata) bool {
+		existing, err := metadata.pendingAccessRequest(id)
+		return err == nil && existing.sameAs(request)
+	})
+	if err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(author, request.Account) {
+		return ErrAccessRequestForged(id, request.Account, author)
+	}
+	return nil
+}
+
+// sameAs returns whether the requests are for the same access by the same account.
+func (r *accessRequest) sameAs(other *accessRequest) bool {
+	return r.ID == other.ID &&
+		r.Path == other.Path &&
+		r.Permission == other.Permission &&
+		strings.EqualFold(r.Account, other.Account) &&
+		r.Reason == other.Reason &&
+		r.RequestedAt.Equal(other.RequestedAt)
+}
+
+// decide records the decision on the request.
+func (r *accessRequest) decide(status, decidedBy, reason string, decidedAt time.Time) {
+	decidedAt = decidedAt.UTC()
+	r.Status = status
+	r.DecidedBy = decidedBy
+	r.DecidedAt = &decidedAt
+	r.DecisionReason = reason
+}
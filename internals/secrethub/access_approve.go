@@ -0,0 +1,110 @@
+package secrethub
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// AccessApproveCommand approves an access request by setting the requested access rule.
+type AccessApproveCommand struct {
+	id        string
+	reason    string
+	force     bool
+	io        ui.IO
+	newClient newClientFunc
+	now       func() time.Time
+}
+
+// NewAccessApproveCommand creates a new AccessApproveCommand.
+func NewAccessApproveCommand(io ui.IO, newClient newClientFunc) *AccessApproveCommand {
+	return &AccessApproveCommand{
+		io:        io,
+		newClient: newClient,
+		now:       time.Now,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *AccessApproveCommand) Register(r command.Registerer) {
+	clause := r.Command("approve", "Approve an access request by setting the requested access rule.")
+	clause.Arg("request-id", "The ID of the request, with the format <namespace>/<repo>:<number>.").Required().StringVar(&cmd.id)
+	clause.Flag("reason", "A note to record with the approval.").StringVar(&cmd.reason)
+	registerForceFlag(clause).BoolVar(&cmd.force)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run sets the requested access rule and records the approval.
+func (cmd *AccessApproveCommand) Run() error {
+	id, err := parseAccessRequestID(cmd.id)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	metadata, err := readRepoMetadata(client, id.repo.Value())
+	if err != nil {
+		return err
+	}
+
+	request, err := metadata.pendingAccessRequest(id)
+	if err != nil {
+		return err
+	}
+
+	var permission api.Permission
+	err = permission.Set(request.Permission)
+	if err != nil {
+		return err
+	}
+
+	err = verifyAccessRequest(client, id, request)
+	if err != nil {
+		return err
+	}
+
+	if !cmd.force {
+		msg := fmt.Sprintf("%s requests %s access to %s, because: %s\nDo you want to give %s %s access to %s?",
+			request.Account, permission, request.Path, request.Reason, request.Account, permission, request.Path)
+		confirmed, err := ui.AskYesNo(cmd.io, msg, ui.DefaultNo)
+		if err == ui.ErrCannotAsk {
+			return ErrCannotDoWithoutForce
+		} else if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			fmt.Fprintln(cmd.io.Output(), "Aborting.")
+			return nil
+		}
+	}
+
+	user, err := client.Me().GetUser()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.AccessRules().Set(request.Path, permission.String(), request.Account)
+	if err != nil {
+		return err
+	}
+
+	request.decide(accessRequestApproved, user.Username, cmd.reason, cmd.now())
+	err = writeRepoMetadata(client, id.repo.Value(), metadata)
+	if err != nil {
+		fmt.Fprintf(cmd.io.Output(), "Gave %s %s access to %s, but failed to record the approval of request %s.\n", request.Account, permission, request.Path, id)
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Approved request %s: %s now has %s access to %s.\n", id, request.Account, permission, request.Path)
+	return nil
+}
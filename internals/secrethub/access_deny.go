@@ -0,0 +1,73 @@
+package secrethub
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// AccessDenyCommand denies an access request.
+type AccessDenyCommand struct {
+	id        string
+	reason    string
+	io        ui.IO
+	newClient newClientFunc
+	now       func() time.Time
+}
+
+// NewAccessDenyCommand creates a new AccessDenyCommand.
+func NewAccessDenyCommand(io ui.IO, newClient newClientFunc) *AccessDenyCommand {
+	return &AccessDenyCommand{
+		io:        io,
+		newClient: newClient,
+		now:       time.Now,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *AccessDenyCommand) Register(r command.Registerer) {
+	clause := r.Command("deny", "Deny an access request.")
+	clause.Arg("request-id", "The ID of the request, with the format <namespace>/<repo>:<number>.").Required().StringVar(&cmd.id)
+	clause.Flag("reason", "Why the request is denied.").StringVar(&cmd.reason)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run records the denial of the request.
+func (cmd *AccessDenyCommand) Run() error {
+	id, err := parseAccessRequestID(cmd.id)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	metadata, err := readRepoMetadata(client, id.repo.Value())
+	if err != nil {
+		return err
+	}
+
+	request, err := metadata.pendingAccessRequest(id)
+	if err != nil {
+		return err
+	}
+
+	user, err := client.Me().GetUser()
+	if err != nil {
+		return err
+	}
+
+	request.decide(accessRequestDenied, user.Username, cmd.reason, cmd.now())
+	err = writeRepoMetadata(client, id.repo.Value(), metadata)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Denied request %s of %s for %s access to %s.\n", id, request.Account, request.Permission, request.Path)
+	return nil
+}
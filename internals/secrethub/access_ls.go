@@ -0,0 +1,87 @@
+package secrethub
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// AccessListCommand lists the access requests of a repository.
+type AccessListCommand struct {
+	repo          api.RepoPath
+	all           bool
+	useTimestamps bool
+	io            ui.IO
+	newClient     newClientFunc
+}
+
+// NewAccessListCommand creates a new AccessListCommand.
+func NewAccessListCommand(io ui.IO, newClient newClientFunc) *AccessListCommand {
+	return &AccessListCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *AccessListCommand) Register(r command.Registerer) {
+	clause := r.Command("ls", "List the pending access requests of a repository.")
+	clause.Alias("list")
+	clause.Arg("repo-path", "The repository to list the access requests of.").Required().PlaceHolder(repoPathPlaceHolder).SetValue(&cmd.repo)
+	clause.Flag("all", "Also list approved and denied requests.").Short('a').BoolVar(&cmd.all)
+	registerTimestampFlag(clause).BoolVar(&cmd.useTimestamps)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run prints the access requests.
+func (cmd *AccessListCommand) Run() error {
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	metadata, err := readRepoMetadata(client, cmd.repo.Value())
+	if err != nil {
+		return err
+	}
+
+	return printAccessRequests(cmd.io.Output(), cmd.repo, metadata.AccessRequests, cmd.all, NewTimeFormatter(cmd.useTimestamps))
+}
+
+// printAccessRequests prints a table of the access requests. Requests that are
+// approved or denied are only printed when all is set.
+func printAccessRequests(w io.Writer, repo api.RepoPath, requests []*accessRequest, all bool, timeFormatter TimeFormatter) error {
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	header := []string{"ID", "ACCOUNT", "PERMISSION", "PATH", "REQUESTED", "REASON"}
+	if all {
+		header = append(header, "STATUS", "DECIDED BY")
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	for _, request := range requests {
+		if request.Status != accessRequestPending && !all {
+			continue
+		}
+
+		row := []string{
+			accessRequestID{repo: repo, number: request.ID}.String(),
+			request.Account,
+			request.Permission,
+			request.Path,
+			timeFormatter.Format(request.RequestedAt),
+			request.Reason,
+		}
+		if all {
+			row = append(row, request.Status, request.DecidedBy)
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
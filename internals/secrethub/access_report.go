@@ -0,0 +1,156 @@
+package secrethub
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+const (
+	formatCSV = "csv"
+)
+
+// AccessReportCommand prints the effective permissions of all accounts on every directory in a namespace.
+type AccessReportCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+	namespace api.Namespace
+	format    string
+}
+
+// NewAccessReportCommand creates a new AccessReportCommand.
+func NewAccessReportCommand(io ui.IO, newClient newClientFunc) *AccessReportCommand {
+	return &AccessReportCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *AccessReportCommand) Register(r command.Registerer) {
+	clause := r.Command("access-report", "Report who has access to every directory in a namespace, e.g. for access reviews.")
+	clause.HelpLong("Prints the effective permission of every account on every directory of every repository in the namespace. " +
+		"An access rule on a directory also applies to all directories below it, so a directory lists the accounts with " +
+		"an access rule on it or on one of its parent directories, with the highest of their permissions.\n" +
+		"\n" +
+		"The csv format is a matrix with a row per directory and a column per account. " +
+		"The json format lists the directories with the permission of every account that has access to them.")
+	clause.Arg("namespace", "The namespace (organization or username) to report on.").Required().SetValue(&cmd.namespace)
+	clause.Flag("format", "The format of the report. Options are: csv and json.").HintOptions(formatCSV, formatJSON).Default(formatCSV).StringVar(&cmd.format)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// accessReportDir is the effective permissions of the accounts with access to a directory.
+type accessReportDir struct {
+	Directory   string            `json:"directory"`
+	Permissions map[string]string `json:"permissions"`
+}
+
+// Run prints the access report.
+func (cmd *AccessReportCommand) Run() error {
+	if cmd.format != formatCSV && cmd.format != formatJSON {
+		return errNoSuchFormat(cmd.format)
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	repos, err := client.Repos().List(cmd.namespace.Value())
+	if err != nil {
+		return err
+	}
+	sort.Slice(repos, func(i, j int) bool {
+		return strings.ToLower(repos[i].Name) < strings.ToLower(repos[j].Name)
+	})
+
+	report := []accessReportDir{}
+	for _, repo := range repos {
+		rules, dirs, err := listACLRules(client, repo.Path())
+		if err != nil {
+			return err
+		}
+		report = append(report, effectivePermissions(rules, dirs)...)
+	}
+
+	if cmd.format == formatJSON {
+		encoder := json.NewEncoder(cmd.io.Output())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	return writeAccessReportCSV(cmd.io.Output(), report)
+}
+
+// effectivePermissions returns the effective permissions on the directories,
+// given the access rules on them and their parent directories.
+func effectivePermissions(rules []aclRule, dirs []api.DirPath) []accessReportDir {
+	report := make([]accessReportDir, len(dirs))
+	for i, dir := range dirs {
+		permissions := map[string]api.Permission{}
+		for _, rule := range rules {
+			if !isParentDir(rule.path, dir) {
+				continue
+			}
+			if rule.permission > permissions[rule.account] {
+				permissions[rule.account] = rule.permission
+			}
+		}
+
+		report[i] = accessReportDir{
+			Directory:   dir.Value(),
+			Permissions: make(map[string]string, len(permissions)),
+		}
+		for account, permission := range permissions {
+			report[i].Permissions[account] = permission.String()
+		}
+	}
+	return report
+}
+
+// isParentDir returns whether parent is the directory itself or one of the directories it is in.
+func isParentDir(parent, dir api.DirPath) bool {
+	return strings.HasPrefix(strings.ToLower(dir.Value())+"/", strings.ToLower(parent.Value())+"/")
+}
+
+// writeAccessReportCSV writes the report as a matrix with a row per directory and a column per account.
+func writeAccessReportCSV(w io.Writer, report []accessReportDir) error {
+	seen := map[string]bool{}
+	var accounts []string
+	for _, dir := range report {
+		for account := range dir.Permissions {
+			if !seen[account] {
+				seen[account] = true
+				accounts = append(accounts, account)
+			}
+		}
+	}
+	sort.Strings(accounts)
+
+	csvWriter := csv.NewWriter(w)
+	err := csvWriter.Write(append([]string{"directory"}, accounts...))
+	if err != nil {
+		return err
+	}
+	for _, dir := range report {
+		row := make([]string, len(accounts)+1)
+		row[0] = dir.Directory
+		for i, account := range accounts {
+			row[i+1] = dir.Permissions[account]
+		}
+		err = csvWriter.Write(row)
+		if err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
@@ -0,0 +1,69 @@
+package secrethub
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestEffectivePermissions(t *testing.T) {
+	// Arrange
+	rules := []aclRule{
+		{path: "namespace/repo", account: "dev1", permission: api.PermissionRead},
+		{path: "namespace/repo/prod", account: "dev1", permission: api.PermissionWrite},
+		{path: "namespace/repo/prod", account: "s-1234", permission: api.PermissionRead},
+		{path: "namespace/repo/prod-old", account: "dev2", permission: api.PermissionAdmin},
+	}
+	dirs := []api.DirPath{
+		"namespace/repo",
+		"namespace/repo/prod",
+		"namespace/repo/prod/db",
+	}
+
+	// Act
+	actual := effectivePermissions(rules, dirs)
+
+	// Assert
+	assert.Equal(t, actual, []accessReportDir{
+		{
+			Directory:   "namespace/repo",
+			Permissions: map[string]string{"dev1": "read"},
+		},
+		{
+			Directory:   "namespace/repo/prod",
+			Permissions: map[string]string{"dev1": "write", "s-1234": "read"},
+		},
+		{
+			Directory:   "namespace/repo/prod/db",
+			Permissions: map[string]string{"dev1": "write", "s-1234": "read"},
+		},
+	})
+}
+
+func TestWriteAccessReportCSV(t *testing.T) {
+	// Arrange
+	report := []accessReportDir{
+		{
+			Directory:   "namespace/repo",
+			Permissions: map[string]string{"dev1": "admin"},
+		},
+		{
+			Directory:   "namespace/repo/prod",
+			Permissions: map[string]string{"dev1": "admin", "s-1234": "read"},
+		},
+	}
+	buf := bytes.Buffer{}
+
+	// Act
+	err := writeAccessReportCSV(&buf, report)
+
+	// Assert
+	assert.OK(t, err)
+	assert.Equal(t, buf.String(), ""+
+		"directory,dev1,s-1234\n"+
+		"namespace/repo,admin,\n"+
+		"namespace/repo/prod,admin,read\n",
+	)
+}
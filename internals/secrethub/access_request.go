@@ -0,0 +1,80 @@
+package secrethub
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// AccessRequestCommand requests a permission on a directory.
+type AccessRequestCommand struct {
+	path       api.DirPath
+	permission api.Permission
+	reason     string
+	io         ui.IO
+	newClient  newClientFunc
+	now        func() time.Time
+}
+
+// NewAccessRequestCommand creates a new AccessRequestCommand.
+func NewAccessRequestCommand(io ui.IO, newClient newClientFunc) *AccessRequestCommand {
+	return &AccessRequestCommand{
+		io:        io,
+		newClient: newClient,
+		now:       time.Now,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *AccessRequestCommand) Register(r command.Registerer) {
+	clause := r.Command("request", "Request access to a directory.")
+	clause.Arg("dir-path", "The directory to request access to.").Required().PlaceHolder(optionalDirPathPlaceHolder).SetValue(&cmd.path)
+	clause.Flag("permission", "The permission to request. Options are: read, write and admin.").Default("read").HintOptions("read", "write", "admin").SetValue(&cmd.permission)
+	clause.Flag("reason", "Why you need access, e.g. a link to a ticket.").StringVar(&cmd.reason)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run records the access request in the metadata of the repository.
+func (cmd *AccessRequestCommand) Run() error {
+	if cmd.reason == "" {
+		return ErrAccessReasonRequired
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	user, err := client.Me().GetUser()
+	if err != nil {
+		return err
+	}
+
+	metadata, err := readRepoMetadata(client, cmd.path.Value())
+	if err != nil {
+		return err
+	}
+
+	id := metadata.addAccessRequest(repoOfPath(cmd.path.Value()), &accessRequest{
+		Path:        cmd.path.Value(),
+		Permission:  cmd.permission.String(),
+		Account:     user.Username,
+		Reason:      cmd.reason,
+		RequestedAt: cmd.now().UTC(),
+		Status:      accessRequestPending,
+	})
+
+	err = writeRepoMetadata(client, cmd.path.Value(), metadata)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Requested %s access to %s. The ID of the request is %s.\n", cmd.permission, cmd.path, id)
+	fmt.Fprintf(cmd.io.Output(), "An admin of the repository can approve it with `%s access approve %s`.\n", ApplicationName, id)
+	return nil
+}
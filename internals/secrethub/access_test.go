@@ -0,0 +1,218 @@
+package secrethub
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestParseAccessRequestID(t *testing.T) {
+	cases := map[string]struct {
+		value    string
+		expected accessRequestID
+		err      error
+	}{
+		"valid": {
+			value:    "namespace/repo:12",
+			expected: accessRequestID{repo: "namespace/repo", number: 12},
+		},
+		"no number": {
+			value: "namespace/repo",
+			err:   ErrInvalidAccessRequestID("namespace/repo"),
+		},
+		"not a number": {
+			value: "namespace/repo:one",
+			err:   ErrInvalidAccessRequestID("namespace/repo:one"),
+		},
+		"zero": {
+			value: "namespace/repo:0",
+			err:   ErrInvalidAccessRequestID("namespace/repo:0"),
+		},
+		"directory instead of repo": {
+			value: "namespace/repo/dir:1",
+			err:   ErrInvalidAccessRequestID("namespace/repo/dir:1"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			actual, err := parseAccessRequestID(tc.value)
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
+
+func TestRepoMetadata_AccessRequests(t *testing.T) {
+	// Setup
+	metadata := &repoMetadata{}
+
+	// Act
+	first := metadata.addAccessRequest("namespace/repo", &accessRequest{Status: accessRequestPending})
+	second := metadata.addAccessRequest("namespace/repo", &accessRequest{Status: accessRequestPending})
+	metadata.AccessRequests[0].decide(accessRequestDenied, "dev1", "", time.Now())
+
+	// Assert
+	assert.Equal(t, first.String(), "namespace/repo:1")
+	assert.Equal(t, second.String(), "namespace/repo:2")
+
+	_, err := metadata.pendingAccessRequest(first)
+	assert.Equal(t, err, ErrAccessRequestNotPending(first, accessRequestDenied))
+
+	request, err := metadata.pendingAccessRequest(second)
+	assert.OK(t, err)
+	assert.Equal(t, request.ID, 2)
+
+	missing := accessRequestID{repo: "namespace/repo", number: 3}
+	_, err = metadata.pendingAccessRequest(missing)
+	assert.Equal(t, err, ErrAccessRequestNotFound(missing))
+}
+
+func TestVerifyAccessRequest(t *testing.T) {
+	id := accessRequestID{repo: "namespace/repo", number: 1}
+	requestedAt := time.Date(2018, 1, 1, 1, 1, 1, 0, time.UTC)
+	read := &accessRequest{
+		ID:          1,
+		Path:        "namespace/repo/prod",
+		Permission:  "read",
+		Account:     "dev2",
+		Reason:      "ticket 123",
+		RequestedAt: requestedAt,
+		Status:      accessRequestPending,
+	}
+	admin := *read
+	admin.Permission = "admin"
+
+	cases := map[string]struct {
+		versions [][]*accessRequest
+		authors  []string
+		request  *accessRequest
+		err      error
+	}{
+		"written by requester": {
+			versions: [][]*accessRequest{nil, {read}, {read}},
+			authors:  []string{"dev1", "dev2", "dev1"},
+			request:  read,
+		},
+		"written by other account": {
+			versions: [][]*accessRequest{nil, {read}},
+			authors:  []string{"dev1", "dev3"},
+			request:  read,
+			err:      ErrAccessRequestForged(id, "dev2", "dev3"),
+		},
+		"changed after request": {
+			versions: [][]*accessRequest{{read}, {&admin}},
+			authors:  []string{"dev2", "dev3"},
+			request:  &admin,
+			err:      ErrAccessRequestForged(id, "dev2", "dev3"),
+		},
+		"directory in other repo": {
+			request: &accessRequest{ID: 1, Path: "namespace/other", Account: "dev2"},
+			err:     ErrAccessRequestOtherRepo(id, "namespace/other", "namespace/repo"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			var versions []*api.SecretVersion
+			var events []api.Audit
+			for i, requests := range tc.versions {
+				data, err := json.Marshal(repoMetadata{AccessRequests: requests})
+				assert.OK(t, err)
+				versions = append(versions, &api.SecretVersion{Version: i + 1, Data: data})
+				events = append([]api.Audit{{
+					Action: api.AuditActionCreate,
+					Actor:  api.AuditActor{Type: "user", User: &api.User{Username: tc.authors[i]}},
+					Subject: api.AuditSubject{
+						Type:          api.AuditSubjectSecretVersion,
+						SecretVersion: &api.EncryptedSecretVersion{Version: i + 1},
+					},
+				}}, events...)
+			}
+
+			client := fakeclient.Client{
+				SecretService: &fakeclient.SecretService{
+					VersionService: &fakeclient.SecretVersionService{
+						ListWithDataFunc: func(path string) ([]*api.SecretVersion, error) {
+							assert.Equal(t, path, "namespace/secrethub-metadata/repo")
+							return versions, nil
+						},
+					},
+					AuditEventIterator: &fakeclient.AuditEventIterator{Events: events},
+				},
+			}
+
+			// Act
+			err := verifyAccessRequest(client, id, tc.request)
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+		})
+	}
+}
+
+func TestPrintAccessRequests(t *testing.T) {
+	requestedAt := time.Date(2018, 1, 1, 1, 1, 1, 1, time.UTC)
+	requests := []*accessRequest{
+		{
+			ID:          1,
+			Path:        "namespace/repo/prod",
+			Permission:  "write",
+			Account:     "dev1",
+			Reason:      "deploy",
+			RequestedAt: requestedAt,
+			Status:      accessRequestApproved,
+			DecidedBy:   "dev2",
+		},
+		{
+			ID:          2,
+			Path:        "namespace/repo",
+			Permission:  "read",
+			Account:     "dev3",
+			Reason:      "onboarding",
+			RequestedAt: requestedAt,
+			Status:      accessRequestPending,
+		},
+	}
+
+	cases := map[string]struct {
+		all      bool
+		expected string
+	}{
+		"pending": {
+			expected: "" +
+				"ID                ACCOUNT  PERMISSION  PATH            REQUESTED             REASON\n" +
+				"namespace/repo:2  dev3     read        namespace/repo  2018-01-01T01:01:01Z  onboarding\n",
+		},
+		"all": {
+			all: true,
+			expected: "" +
+				"ID                ACCOUNT  PERMISSION  PATH                 REQUESTED             REASON      STATUS    DECIDED BY\n" +
+				"namespace/repo:1  dev1     write       namespace/repo/prod  2018-01-01T01:01:01Z  deploy      approved  dev2\n" +
+				"namespace/repo:2  dev3     read        namespace/repo       2018-01-01T01:01:01Z  onboarding  pending   \n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			buf := bytes.Buffer{}
+
+			// Act
+			err := printAccessRequests(&buf, "namespace/repo", requests, tc.all, NewTimestampFormatter())
+
+			// Assert
+			assert.OK(t, err)
+			assert.Equal(t, buf.String(), tc.expected)
+		})
+	}
+}
@@ -27,4 +27,5 @@ func (cmd *AccountCommand) Register(r command.Registerer) {
 	NewAccountInspectCommand(cmd.io, cmd.newClient).Register(clause)
 	NewAccountInitCommand(cmd.io, cmd.newClient, cmd.credentialStore).Register(clause)
 	NewAccountEmailVerifyCommand(cmd.io, cmd.newClient).Register(clause)
+	NewAccountDevicesCommand(cmd.io, cmd.newClient).Register(clause)
 }
@@ -0,0 +1,30 @@
+package secrethub
+
+import (
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// AccountDevicesCommand is an alias of the credential commands that list and disable
+// the credentials of the devices connected to your account.
+type AccountDevicesCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+}
+
+// NewAccountDevicesCommand creates a new AccountDevicesCommand.
+func NewAccountDevicesCommand(io ui.IO, newClient newClientFunc) *AccountDevicesCommand {
+	return &AccountDevicesCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command and its sub-commands on the provided Registerer.
+func (cmd *AccountDevicesCommand) Register(r command.Registerer) {
+	clause := r.Command("devices", "Manage the devices that can access your account. Alias of `"+ApplicationName+" credential ls` and `"+ApplicationName+" credential disable`.")
+	clause.HelpLong("Every device you connect to your account, e.g. with `" + ApplicationName + " init`, has its own credential. " +
+		"Backup codes are credentials too. Disable the credential of a lost or compromised device to stop it from accessing your account.")
+	NewCredentialListCommand(cmd.io, cmd.newClient).Register(clause)
+	NewCredentialDisableCommand(cmd.io, cmd.newClient).Register(clause)
+}
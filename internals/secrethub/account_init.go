@@ -74,7 +74,7 @@ func (cmd *AccountInitCommand) Run() error {
 	if !cmd.isContinue {
 		credentialPath := cmd.credentialStore.ConfigDir().Credential().Path()
 
-		if cmd.credentialStore.ConfigDir().Credential().Exists() {
+		if storedCredentialBackend(cmd.credentialStore.ConfigDir()).Exists() {
 			client, err := cmd.newClient()
 			if err != nil {
 				return err
@@ -223,7 +223,7 @@ func (cmd *AccountInitCommand) Run() error {
 			fmt.Fprintf(cmd.io.Output(), "\n%s\n", out)
 		}
 	} else {
-		if !cmd.credentialStore.ConfigDir().Credential().Exists() {
+		if !storedCredentialBackend(cmd.credentialStore.ConfigDir()).Exists() {
 			return ErrCredentialNotGenerated
 		}
 	}
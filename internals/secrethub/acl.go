@@ -25,5 +25,8 @@ func (cmd *ACLCommand) Register(r command.Registerer) {
 	NewACLCheckCommand(cmd.io, cmd.newClient).Register(clause)
 	NewACLListCommand(cmd.io, cmd.newClient).Register(clause)
 	NewACLRmCommand(cmd.io, cmd.newClient).Register(clause)
+	NewACLApplyCommand(cmd.io, cmd.newClient).Register(clause)
+	NewACLDiffCommand(cmd.io, cmd.newClient).Register(clause)
 	NewACLSetCommand(cmd.io, cmd.newClient).Register(clause)
+	NewACLWhoCommand(cmd.io, cmd.newClient).Register(clause)
 }
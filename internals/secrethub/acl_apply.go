@@ -0,0 +1,454 @@
+package secrethub
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Errors
+var (
+	ErrInvalidACLPolicy      = errMain.Code("invalid_acl_policy").ErrorPref("invalid policy %s: %v")
+	ErrACLPolicyDirNotFound  = errMain.Code("acl_policy_dir_not_found").ErrorPref("the directory %s in the policy does not exist")
+	ErrACLPolicyRemovesAdmin = errMain.Code("acl_policy_removes_admin").ErrorPref("the policy removes your admin access to %s, after which you can no longer manage its access rules: give %s admin on / in the policy")
+)
+
+const (
+	aclChangeCreate = "+"
+	aclChangeUpdate = "~"
+	aclChangeDelete = "-"
+)
+
+// aclPolicy declares all access rules of a repository. For example:
+//
+//	repo: company/app
+//	directories:
+//	  - path: /
+//	    users:
+//	      alice: admin
+//	  - path: prod
+//	    groups:
+//	      backend: read
+//	    services:
+//	      s-abcdef123456: read
+//
+// Paths are relative to the repository. Groups are the groups of the organization
+// the repository is in and give every member the permission. When an account gets
+// more than one permission on a directory, it gets the highest of them.
+type aclPolicy struct {
+	Repo        string         `yaml:"repo"`
+	Directories []aclPolicyDir `yaml:"directories"`
+}
+
+// aclPolicyDir declares the access rules on a directory.
+type aclPolicyDir struct {
+	Path     string            `yaml:"path"`
+	Users    map[string]string `yaml:"users,omitempty"`
+	Groups   map[string]string `yaml:"groups,omitempty"`
+	Services map[string]string `yaml:"services,omitempty"`
+}
+
+// aclRule is an access rule of an account on a directory.
+type aclRule struct {
+	path       api.DirPath
+	account    string
+	permission api.Permission
+}
+
+// key returns the case-insensitive identity of the rule: its directory and account.
+func (r aclRule) key() string {
+	return strings.ToLower(r.path.Value() + "\x00" + r.account)
+}
+
+// aclChange is a difference between the policy and the existing access rules.
+type aclChange struct {
+	action string
+	rule   aclRule
+	// old is the existing permission of a rule that is updated.
+	old api.Permission
+}
+
+// readACLPolicy reads a policy from a YAML file.
+func readACLPolicy(filename string) (*aclPolicy, error) {
+	raw, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, ErrCannotReadFile(filename, err)
+	}
+
+	policy := &aclPolicy{}
+	err = yaml.UnmarshalStrict(raw, policy)
+	if err != nil {
+		return nil, ErrInvalidACLPolicy(filename, err)
+	}
+	return policy, nil
+}
+
+// usesGroups returns whether the policy declares access rules for groups.
+func (p *aclPolicy) usesGroups() bool {
+	for _, dir := range p.Directories {
+		if len(dir.Groups) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// rules returns the access rules declared by the policy, validating it along the way.
+// The groups are the groups of the organization of the repository.
+func (p *aclPolicy) rules(groups *orgGroups) ([]aclRule, error) {
+	var repo api.RepoPath
+	err := repo.Set(p.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := map[string]aclRule{}
+	add := func(rule aclRule) {
+		existing, ok := rules[rule.key()]
+		if !ok || rule.permission > existing.permission {
+			rules[rule.key()] = rule
+		}
+	}
+
+	dirs := map[string]bool{}
+	for _, dir := range p.Directories {
+		path, err := api.NewDirPath(strings.TrimSuffix(repo.Value()+"/"+strings.Trim(dir.Path, "/"), "/"))
+		if err != nil {
+			return nil, err
+		}
+		if dirs[strings.ToLower(path.Value())] {
+			return nil, fmt.Errorf("the directory %s is declared more than once", dir.Path)
+		}
+		dirs[strings.ToLower(path.Value())] = true
+
+		for user, value := range dir.Users {
+			if api.AccountName(user).IsService() {
+				return nil, fmt.Errorf("%s in the users of %s is a service account: declare it under services", user, dir.Path)
+			}
+			permission, err := parseACLPolicyPermission(dir.Path, user, value)
+			if err != nil {
+				return nil, err
+			}
+			add(aclRule{path: path, account: user, permission: permission})
+		}
+
+		for service, value := range dir.Services {
+			if !api.AccountName(service).IsService() {
+				return nil, fmt.Errorf("%s in the services of %s is not the ID of a service account", service, dir.Path)
+			}
+			permission, err := parseACLPolicyPermission(dir.Path, service, value)
+			if err != nil {
+				return nil, err
+			}
+			add(aclRule{path: path, account: service, permission: permission})
+		}
+
+		for group, value := range dir.Groups {
+			orgGroup, err := groups.get(repo.GetNamespace(), group)
+			if err != nil {
+				return nil, err
+			}
+			permission, err := parseACLPolicyPermission(dir.Path, group, value)
+			if err != nil {
+				return nil, err
+			}
+			for _, member := range orgGroup.Members {
+				add(aclRule{path: path, account: member, permission: permission})
+			}
+		}
+	}
+
+	result := make([]aclRule, 0, len(rules))
+	for _, rule := range rules {
+		var account api.AccountName
+		err = account.Set(rule.account)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, rule)
+	}
+	sortACLRules(result)
+	return result, nil
+}
+
+// parseACLPolicyPermission parses the permission of an account or group on a directory.
+func parseACLPolicyPermission(dir, account, value string) (api.Permission, error) {
+	var permission api.Permission
+	err := permission.Set(value)
+	if err != nil {
+		return permission, fmt.Errorf("invalid permission for %s on %s: %v", account, dir, err)
+	}
+	return permission, nil
+}
+
+// sortACLRules sorts rules by directory and account.
+func sortACLRules(rules []aclRule) {
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].key() < rules[j].key()
+	})
+}
+
+// listACLRules returns all access rules in the repository and the paths of all its directories, sorted.
+func listACLRules(client secrethub.ClientInterface, repo api.RepoPath) ([]aclRule, []api.DirPath, error) {
+	rules, err := client.AccessRules().List(repo.GetDirPath().Value(), -1, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tree, err := client.Dirs().GetTree(repo.GetDirPath().Value(), -1, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dirs := make([]api.DirPath, 0, len(tree.Dirs))
+	for id := range tree.Dirs {
+		path, err := tree.AbsDirPath(id)
+		if err != nil {
+			return nil, nil, err
+		}
+		dirs = append(dirs, path)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		return strings.ToLower(dirs[i].Value()) < strings.ToLower(dirs[j].Value())
+	})
+
+	result := make([]aclRule, len(rules))
+	for i, rule := range rules {
+		path, err := tree.AbsDirPath(rule.DirID)
+		if err != nil {
+			return nil, nil, err
+		}
+		result[i] = aclRule{path: path, account: rule.Account.Name.Value(), permission: rule.Permission}
+	}
+	sortACLRules(result)
+	return result, dirs, nil
+}
+
+// diffACLRules returns the changes that turn the existing rules into the desired rules.
+// Rules are created and updated before rules are deleted, so that no access is lost halfway.
+func diffACLRules(desired, existing []aclRule) []aclChange {
+	existingByKey := make(map[string]aclRule, len(existing))
+	for _, rule := range existing {
+		existingByKey[rule.key()] = rule
+	}
+
+	var changes []aclChange
+	desiredKeys := make(map[string]bool, len(desired))
+	for _, rule := range desired {
+		desiredKeys[rule.key()] = true
+
+		old, ok := existingByKey[rule.key()]
+		if !ok {
+			changes = append(changes, aclChange{action: aclChangeCreate, rule: rule})
+		} else if old.permission != rule.permission {
+			changes = append(changes, aclChange{action: aclChangeUpdate, rule: rule, old: old.permission})
+		}
+	}
+
+	for _, rule := range existing {
+		if !desiredKeys[rule.key()] {
+			changes = append(changes, aclChange{action: aclChangeDelete, rule: rule})
+		}
+	}
+	return changes
+}
+
+// aclPolicyChanges returns the repository of the policy file and the changes that
+// make its access rules match the policy.
+func aclPolicyChanges(client secrethub.ClientInterface, file string) (api.RepoPath, []aclChange, error) {
+	policy, err := readACLPolicy(file)
+	if err != nil {
+		return "", nil, err
+	}
+
+	repo := api.RepoPath(policy.Repo)
+	groups := &orgGroups{}
+	if policy.usesGroups() {
+		groups, err = readOrgGroups(client, repo.GetNamespace())
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	desired, err := policy.rules(groups)
+	if err != nil {
+		return "", nil, ErrInvalidACLPolicy(file, err)
+	}
+
+	existing, dirs, err := listACLRules(client, repo)
+	if err != nil {
+		return "", nil, err
+	}
+
+	exists := make(map[string]bool, len(dirs))
+	for _, dir := range dirs {
+		if !isInTrash(dir.Value()) {
+			exists[strings.ToLower(dir.Value())] = true
+		}
+	}
+	for _, rule := range desired {
+		if !exists[strings.ToLower(rule.path.Value())] {
+			return "", nil, ErrACLPolicyDirNotFound(rule.path)
+		}
+	}
+
+	// The trash is not managed by the policy.
+	managed := make([]aclRule, 0, len(existing))
+	for _, rule := range existing {
+		if !isInTrash(rule.path.Value()) {
+			managed = append(managed, rule)
+		}
+	}
+
+	return repo, diffACLRules(desired, managed), nil
+}
+
+// removesAdmin returns whether the changes remove the admin permission of the account on the root directory of the repository.
+func removesAdmin(changes []aclChange, repo api.RepoPath, account string) bool {
+	for _, change := range changes {
+		if !strings.EqualFold(change.rule.path.Value(), repo.Value()) || !strings.EqualFold(change.rule.account, account) {
+			continue
+		}
+		if change.action == aclChangeDelete && change.rule.permission == api.PermissionAdmin {
+			return true
+		}
+		if change.action == aclChangeUpdate && change.old == api.PermissionAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// printACLChanges prints the changes as a diff.
+func printACLChanges(w io.Writer, changes []aclChange) error {
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	for _, change := range changes {
+		permission := change.rule.permission.String()
+		if change.action == aclChangeUpdate {
+			permission = change.old.String() + " -> " + permission
+		}
+		fmt.Fprintf(tw, "%s %s\t%s\t%s\n", change.action, change.rule.path, change.rule.account, permission)
+	}
+	return tw.Flush()
+}
+
+// ACLApplyCommand reconciles the access rules of a repository with a policy file.
+type ACLApplyCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+	file      string
+	dryRun    bool
+	force     bool
+}
+
+// NewACLApplyCommand creates a new ACLApplyCommand.
+func NewACLApplyCommand(io ui.IO, newClient newClientFunc) *ACLApplyCommand {
+	return &ACLApplyCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *ACLApplyCommand) Register(r command.Registerer) {
+	clause := r.Command("apply", "Make the access rules of a repository match a policy file.")
+	clause.HelpLong("The policy is a YAML file that declares all access rules of a repository, e.g.:\n\n" +
+		"    repo: company/app\n" +
+		"    directories:\n" +
+		"      - path: /\n" +
+		"        users:\n" +
+		"          alice: admin\n" +
+		"      - path: prod\n" +
+		"        groups:\n" +
+		"          backend: read\n" +
+		"        services:\n" +
+		"          s-abcdef123456: read\n\n" +
+		"Paths are relative to the repository. Groups are the groups of the organization, managed with `" + ApplicationName + " org group`: " +
+		"their current members get the permission, so run this command again after the members of a group change. " +
+		"When an account gets more than one permission on a directory, it gets the highest.\n\n" +
+		"The differences with the existing access rules are printed before anything is changed: " +
+		"access rules that are missing are created (+), permissions that differ are updated (~) " +
+		"and access rules that are not in the policy are removed (-). " +
+		"A policy that removes your own admin access to the root directory of the repository is refused.")
+	clause.Arg("policy", "The path to the policy file.").Required().ExistingFileVar(&cmd.file)
+	clause.Flag("dry-run", "Print the changes, without changing anything.").BoolVar(&cmd.dryRun)
+	registerForceFlag(clause).BoolVar(&cmd.force)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run prints the differences between the policy and the access rules of the repository and applies them.
+func (cmd *ACLApplyCommand) Run() error {
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	repo, changes, err := aclPolicyChanges(client, cmd.file)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		fmt.Fprintf(cmd.io.Output(), "The access rules of %s match the policy.\n", repo)
+		return nil
+	}
+
+	err = printACLChanges(cmd.io.Output(), changes)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.io.Output())
+
+	me, err := client.Users().Me()
+	if err != nil {
+		return err
+	}
+	if removesAdmin(changes, repo, me.Username) {
+		return ErrACLPolicyRemovesAdmin(repo, me.Username)
+	}
+
+	if cmd.dryRun {
+		fmt.Fprintln(cmd.io.Output(), "Dry run complete! Nothing has been changed.")
+		return nil
+	}
+
+	if !cmd.force {
+		confirmed, err := ui.AskYesNo(cmd.io, fmt.Sprintf("Do you want to apply %s?", pluralize("change", "changes", len(changes))), ui.DefaultNo)
+		if err == ui.ErrCannotAsk {
+			return ErrCannotDoWithoutForce
+		} else if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			fmt.Fprintln(cmd.io.Output(), "Aborting.")
+			return nil
+		}
+	}
+
+	for _, change := range changes {
+		if change.action == aclChangeDelete {
+			err = client.AccessRules().Delete(change.rule.path.Value(), change.rule.account)
+		} else {
+			_, err = client.AccessRules().Set(change.rule.path.Value(), change.rule.permission.String(), change.rule.account)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Applied %s.\n", pluralize("change", "changes", len(changes)))
+	return nil
+}
@@ -0,0 +1,293 @@
+package secrethub
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestACLPolicy_rules(t *testing.T) {
+	groups := &orgGroups{Groups: map[string]*orgGroup{
+		"backend": {Members: []string{"dev1", "dev2"}},
+	}}
+
+	cases := map[string]struct {
+		policy   aclPolicy
+		expected []aclRule
+		err      error
+	}{
+		"users and services": {
+			policy: aclPolicy{
+				Repo: "namespace/repo",
+				Directories: []aclPolicyDir{
+					{Path: "/", Users: map[string]string{"dev1": "admin"}},
+					{Path: "prod", Services: map[string]string{"s-1234": "read"}},
+				},
+			},
+			expected: []aclRule{
+				{path: "namespace/repo", account: "dev1", permission: api.PermissionAdmin},
+				{path: "namespace/repo/prod", account: "s-1234", permission: api.PermissionRead},
+			},
+		},
+		"groups": {
+			policy: aclPolicy{
+				Repo: "namespace/repo",
+				Directories: []aclPolicyDir{
+					{Path: "prod", Groups: map[string]string{"backend": "write"}},
+				},
+			},
+			expected: []aclRule{
+				{path: "namespace/repo/prod", account: "dev1", permission: api.PermissionWrite},
+				{path: "namespace/repo/prod", account: "dev2", permission: api.PermissionWrite},
+			},
+		},
+		"highest permission": {
+			policy: aclPolicy{
+				Repo: "namespace/repo",
+				Directories: []aclPolicyDir{
+					{Path: "", Users: map[string]string{"dev1": "admin"}, Groups: map[string]string{"backend": "read"}},
+				},
+			},
+			expected: []aclRule{
+				{path: "namespace/repo", account: "dev1", permission: api.PermissionAdmin},
+				{path: "namespace/repo", account: "dev2", permission: api.PermissionRead},
+			},
+		},
+		"duplicate directory": {
+			policy: aclPolicy{
+				Repo: "namespace/repo",
+				Directories: []aclPolicyDir{
+					{Path: "prod"},
+					{Path: "prod/"},
+				},
+			},
+			err: errors.New("the directory prod/ is declared more than once"),
+		},
+		"undefined group": {
+			policy: aclPolicy{
+				Repo: "namespace/repo",
+				Directories: []aclPolicyDir{
+					{Path: "prod", Groups: map[string]string{"frontend": "read"}},
+				},
+			},
+			err: ErrGroupNotFound("namespace", "frontend"),
+		},
+		"service as user": {
+			policy: aclPolicy{
+				Repo: "namespace/repo",
+				Directories: []aclPolicyDir{
+					{Path: "prod", Users: map[string]string{"s-1234": "read"}},
+				},
+			},
+			err: errors.New("s-1234 in the users of prod is a service account: declare it under services"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			actual, err := tc.policy.rules(groups)
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			if tc.err == nil {
+				assert.Equal(t, actual, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDiffACLRules(t *testing.T) {
+	// Arrange
+	desired := []aclRule{
+		{path: "namespace/repo", account: "dev1", permission: api.PermissionAdmin},
+		{path: "namespace/repo/prod", account: "dev2", permission: api.PermissionRead},
+		{path: "namespace/repo/prod", account: "s-1234", permission: api.PermissionWrite},
+	}
+	existing := []aclRule{
+		{path: "namespace/repo", account: "dev1", permission: api.PermissionAdmin},
+		{path: "namespace/repo", account: "dev3", permission: api.PermissionWrite},
+		{path: "namespace/repo/prod", account: "s-1234", permission: api.PermissionRead},
+	}
+
+	// Act
+	actual := diffACLRules(desired, existing)
+
+	// Assert
+	assert.Equal(t, actual, []aclChange{
+		{action: aclChangeCreate, rule: desired[1]},
+		{action: aclChangeUpdate, rule: desired[2], old: api.PermissionRead},
+		{action: aclChangeDelete, rule: existing[1]},
+	})
+}
+
+func TestACLApplyCommand_Run(t *testing.T) {
+	rootID := uuid.New()
+	prodID := uuid.New()
+
+	policy := "" +
+		"repo: namespace/repo\n" +
+		"directories:\n" +
+		"  - path: /\n" +
+		"    users:\n" +
+		"      dev1: admin\n" +
+		"  - path: prod\n" +
+		"    groups:\n" +
+		"      backend: read\n" +
+		"    services:\n" +
+		"      s-1234: write\n"
+
+	cases := map[string]struct {
+		dryRun  bool
+		policy  string
+		applied []string
+		out     string
+		err     error
+	}{
+		"apply": {
+			applied: []string{
+				"set namespace/repo/prod dev2 read",
+				"set namespace/repo/prod dev3 read",
+				"set namespace/repo/prod s-1234 write",
+				"delete namespace/repo dev2",
+			},
+			out: "" +
+				"+ namespace/repo/prod  dev2    read\n" +
+				"+ namespace/repo/prod  dev3    read\n" +
+				"~ namespace/repo/prod  s-1234  read -> write\n" +
+				"- namespace/repo       dev2    write\n" +
+				"\n" +
+				"Applied 4 changes.\n",
+		},
+		"dry run": {
+			dryRun: true,
+			out: "" +
+				"+ namespace/repo/prod  dev2    read\n" +
+				"+ namespace/repo/prod  dev3    read\n" +
+				"~ namespace/repo/prod  s-1234  read -> write\n" +
+				"- namespace/repo       dev2    write\n" +
+				"\n" +
+				"Dry run complete! Nothing has been changed.\n",
+		},
+		"removes own admin": {
+			policy: "" +
+				"repo: namespace/repo\n" +
+				"directories:\n" +
+				"  - path: /\n" +
+				"    users:\n" +
+				"      dev1: write\n" +
+				"      dev2: write\n" +
+				"  - path: prod\n" +
+				"    services:\n" +
+				"      s-1234: read\n",
+			out: "" +
+				"~ namespace/repo  dev1  admin -> write\n" +
+				"\n",
+			err: ErrACLPolicyRemovesAdmin("namespace/repo", "dev1"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			dir, cleanup := testdata.tempDir(t)
+			defer cleanup()
+			file := filepath.Join(dir, "policy.yml")
+			content := policy
+			if tc.policy != "" {
+				content = tc.policy
+			}
+			err := ioutil.WriteFile(file, []byte(content), 0644)
+			assert.OK(t, err)
+
+			io := fakeui.NewIO(t)
+			io.PromptErr = ui.ErrCannotAsk
+
+			var applied []string
+			cmd := ACLApplyCommand{
+				io:     io,
+				file:   file,
+				dryRun: tc.dryRun,
+				force:  true,
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						AccessRuleService: &fakeclient.AccessRuleService{
+							ListFunc: func(path string, depth int, ancestors bool) ([]*api.AccessRule, error) {
+								if path == "namespace/secrethub-groups" {
+									return []*api.AccessRule{
+										{Account: &api.Account{Name: "dev1"}, Permission: api.PermissionAdmin},
+									}, nil
+								}
+								return []*api.AccessRule{
+									{Account: &api.Account{Name: "dev1"}, DirID: rootID, Permission: api.PermissionAdmin},
+									{Account: &api.Account{Name: "dev2"}, DirID: rootID, Permission: api.PermissionWrite},
+									{Account: &api.Account{Name: "s-1234"}, DirID: prodID, Permission: api.PermissionRead},
+								}, nil
+							},
+							SetFunc: func(path string, permission string, accountName string) (*api.AccessRule, error) {
+								applied = append(applied, "set "+path+" "+accountName+" "+permission)
+								return nil, nil
+							},
+							DeleteFunc: func(path string, accountName string) error {
+								applied = append(applied, "delete "+path+" "+accountName)
+								return nil
+							},
+						},
+						OrgService: &fakeclient.OrgService{
+							MembersService: &fakeclient.OrgMemberService{
+								ListFunc: func(org string) ([]*api.OrgMember, error) {
+									return []*api.OrgMember{
+										{User: &api.User{Username: "dev1"}, Role: api.OrgRoleAdmin},
+									}, nil
+								},
+							},
+						},
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+									assert.Equal(t, path, "namespace/secrethub-groups/groups")
+									return &api.SecretVersion{Data: []byte(`{"groups":{"backend":{"members":["dev2","dev3"]}}}`)}, nil
+								},
+							},
+						},
+						UserService: &fakeclient.UserService{
+							MeFunc: func() (*api.User, error) {
+								return &api.User{Username: "dev1"}, nil
+							},
+						},
+						DirService: &fakeclient.DirService{
+							GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+								return &api.Tree{
+									ParentPath: "namespace",
+									Dirs: map[uuid.UUID]*api.Dir{
+										rootID: {Name: "repo", DirID: rootID},
+										prodID: {Name: "prod", DirID: prodID, ParentID: &rootID},
+									},
+									RootDir: &api.Dir{Name: "repo", DirID: rootID},
+								}, nil
+							},
+						},
+					}, nil
+				},
+			}
+
+			// Act
+			err = cmd.Run()
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.Out.String(), tc.out)
+			assert.Equal(t, applied, tc.applied)
+		})
+	}
+}
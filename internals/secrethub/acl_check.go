@@ -3,6 +3,7 @@ package secrethub
 import (
 	"fmt"
 	"sort"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/secrethub/secrethub-go/pkg/secretpath"
@@ -11,12 +12,19 @@ import (
 	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
 
 	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+)
+
+// Errors
+var (
+	ErrExplainRequiresAccount = errMain.Code("explain_requires_account").Error("--explain requires an account name")
 )
 
 // ACLCheckCommand prints the access level(s) on a given directory.
 type ACLCheckCommand struct {
 	path        api.DirPath
 	accountName api.AccountName
+	explain     bool
 	io          ui.IO
 	newClient   newClientFunc
 }
@@ -34,26 +42,40 @@ func (cmd *ACLCheckCommand) Register(r command.Registerer) {
 	clause := r.Command("check", "Checks the effective permission of accounts on a path.")
 	clause.Arg("dir-path", "The path of the directory to check the effective permission for").Required().PlaceHolder(optionalDirPathPlaceHolder).SetValue(&cmd.path)
 	clause.Arg("account-name", "Check permissions of a specific account name (username or service name). When left empty, all accounts with permission on the path are printed out.").SetValue(&cmd.accountName)
+	clause.Flag("explain", "Also print the access rule that grants the account its permission. Requires an account name.").BoolVar(&cmd.explain)
 
 	command.BindAction(clause, cmd.Run)
 }
 
 // Run prints the access level(s) on the given directory.
 func (cmd *ACLCheckCommand) Run() error {
-	levels, err := cmd.listLevels()
+	if cmd.explain && cmd.accountName == "" {
+		return ErrExplainRequiresAccount
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	levels, dir, err := cmd.listLevels(client)
 	if err != nil {
 		return err
 	}
 
 	if cmd.accountName != "" {
+		permission := api.PermissionNone
 		for _, level := range levels {
 			if level.Account.Name == cmd.accountName {
-				fmt.Fprintf(cmd.io.Output(), "%s\n", level.Permission.String())
-				return nil
+				permission = level.Permission
+				break
 			}
 		}
 
-		fmt.Fprintln(cmd.io.Output(), api.PermissionNone.String())
+		fmt.Fprintln(cmd.io.Output(), permission.String())
+		if cmd.explain {
+			return cmd.printGrantingRule(client, dir, permission)
+		}
 		return nil
 	}
 
@@ -77,32 +99,101 @@ func (cmd *ACLCheckCommand) Run() error {
 	return nil
 }
 
-func (cmd *ACLCheckCommand) listLevels() ([]*api.AccessLevel, error) {
-	client, err := cmd.newClient()
-	if err != nil {
-		return nil, err
-	}
-
+// listLevels returns the access levels on the path and the directory they apply to,
+// which is the parent directory when the path is a secret.
+func (cmd *ACLCheckCommand) listLevels(client secrethub.ClientInterface) ([]*api.AccessLevel, string, error) {
 	path := cmd.path.Value()
 
 	levels, listLevelsErr := client.AccessRules().ListLevels(path)
 	if listLevelsErr == nil {
-		return levels, nil
+		return levels, path, nil
 	}
 	if !api.IsErrNotFound(listLevelsErr) {
-		return nil, listLevelsErr
+		return nil, "", listLevelsErr
 	}
 
 	isSecret, isSecretErr := client.Secrets().Exists(path)
 	if isSecretErr != nil {
-		return nil, listLevelsErr
+		return nil, "", listLevelsErr
 	}
 	if isSecret {
-		levels, err = client.AccessRules().ListLevels(secretpath.Parent(path))
+		dir := secretpath.Parent(path)
+		levels, err := client.AccessRules().ListLevels(dir)
+		if err != nil {
+			return nil, "", err
+		}
+		return levels, dir, nil
+	}
+	return nil, "", listLevelsErr
+}
+
+// printGrantingRule prints the access rule that gives the account its permission on the directory.
+func (cmd *ACLCheckCommand) printGrantingRule(client secrethub.ClientInterface, dir string, permission api.Permission) error {
+	rule, err := findGrantingRule(client, dir, cmd.accountName.Value())
+	if err != nil {
+		return err
+	}
+
+	if rule == nil || rule.permission != permission {
+		fmt.Fprintf(cmd.io.Output(), "No access rule on %s or its parent directories gives %s this permission.\n", dir, cmd.accountName)
+		return nil
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Granted by the access rule for %s on %s.\n", rule.account, rule.path)
+	return nil
+}
+
+// findGrantingRule returns the access rule that determines the effective permission of the account
+// on the directory, or nil when the account has no access rule on the directory or its parent directories.
+func findGrantingRule(client secrethub.ClientInterface, dir string, account string) (*aclRule, error) {
+	rules, err := listGrantingRules(client, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range rules {
+		if strings.EqualFold(rule.account, account) {
+			return &rule, nil
+		}
+	}
+	return nil, nil
+}
+
+// listGrantingRules returns for every account with access to the directory the access rule that
+// determines its effective permission: the rule with the highest permission on the directory and
+// its parent directories up to the root of the repository. When rules on multiple directories give
+// the same permission, the rule closest to the directory is returned. The rules are sorted by
+// permission, highest first, and then by account.
+func listGrantingRules(client secrethub.ClientInterface, dir string) ([]aclRule, error) {
+	granting := map[string]aclRule{}
+	for {
+		rules, err := client.AccessRules().List(dir, 0, false)
 		if err != nil {
 			return nil, err
 		}
-		return levels, nil
+
+		for _, rule := range rules {
+			account := strings.ToLower(rule.Account.Name.Value())
+			if existing, ok := granting[account]; !ok || rule.Permission > existing.permission {
+				granting[account] = aclRule{path: api.DirPath(dir), account: rule.Account.Name.Value(), permission: rule.Permission}
+			}
+		}
+
+		if api.DirPath(dir).IsRepoPath() || !strings.Contains(dir, "/") {
+			break
+		}
+		dir = secretpath.Parent(dir)
 	}
-	return nil, listLevelsErr
+
+	result := make([]aclRule, 0, len(granting))
+	for _, rule := range granting {
+		result = append(result, rule)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].permission != result[j].permission {
+			return result[i].permission > result[j].permission
+		}
+		return strings.ToLower(result[i].account) < strings.ToLower(result[j].account)
+	})
+	return result, nil
 }
@@ -131,3 +131,75 @@ func TestACLCheckCommand_Run(t *testing.T) {
 		})
 	}
 }
+
+func TestACLCheckCommand_Run_Explain(t *testing.T) {
+	rules := map[string][]*api.AccessRule{
+		"namespace/repo": {
+			{Account: &api.Account{Name: "dev1"}, Permission: api.PermissionRead},
+			{Account: &api.Account{Name: "dev2"}, Permission: api.PermissionAdmin},
+		},
+		"namespace/repo/prod": {
+			{Account: &api.Account{Name: "dev1"}, Permission: api.PermissionWrite},
+			{Account: &api.Account{Name: "dev2"}, Permission: api.PermissionRead},
+		},
+	}
+
+	cases := map[string]struct {
+		accountName api.AccountName
+		permission  api.Permission
+		out         string
+	}{
+		"rule on directory": {
+			accountName: "dev1",
+			permission:  api.PermissionWrite,
+			out: "write\n" +
+				"Granted by the access rule for dev1 on namespace/repo/prod.\n",
+		},
+		"inherited rule": {
+			accountName: "dev2",
+			permission:  api.PermissionAdmin,
+			out: "admin\n" +
+				"Granted by the access rule for dev2 on namespace/repo.\n",
+		},
+		"no rule": {
+			accountName: "dev3",
+			permission:  api.PermissionNone,
+			out: "none\n" +
+				"No access rule on namespace/repo/prod/db or its parent directories gives dev3 this permission.\n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			io := fakeui.NewIO(t)
+			cmd := ACLCheckCommand{
+				path:        "namespace/repo/prod/db",
+				accountName: tc.accountName,
+				explain:     true,
+				io:          io,
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						AccessRuleService: &fakeclient.AccessRuleService{
+							ListLevelsFunc: func(path string) ([]*api.AccessLevel, error) {
+								return []*api.AccessLevel{
+									{Account: &api.Account{Name: tc.accountName}, Permission: tc.permission},
+								}, nil
+							},
+							ListFunc: func(path string, depth int, ancestors bool) ([]*api.AccessRule, error) {
+								return rules[path], nil
+							},
+						},
+					}, nil
+				},
+			}
+
+			// Act
+			err := cmd.Run()
+
+			// Assert
+			assert.OK(t, err)
+			assert.Equal(t, io.Out.String(), tc.out)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package secrethub
+
+import (
+	"fmt"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// Errors
+var (
+	ErrACLPolicyDrift = errMain.Code("acl_policy_drift").ErrorPref("the access rules of %s differ from the policy: %s needed")
+)
+
+// ACLDiffCommand compares the access rules of a repository with a policy file.
+type ACLDiffCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+	file      string
+}
+
+// NewACLDiffCommand creates a new ACLDiffCommand.
+func NewACLDiffCommand(io ui.IO, newClient newClientFunc) *ACLDiffCommand {
+	return &ACLDiffCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *ACLDiffCommand) Register(r command.Registerer) {
+	clause := r.Command("diff", "Compare the access rules of a repository with a policy file.")
+	clause.HelpLong("Prints the differences between the access rules of a repository and a policy file, " +
+		"in the format of `" + ApplicationName + " acl apply`: access rules that are missing (+), " +
+		"permissions that differ (~) and access rules that are not in the policy (-).\n\n" +
+		"Exits with a non-zero exit code when there are differences, e.g. to detect drift in a nightly CI job.")
+	clause.Arg("policy", "The path to the policy file.").Required().ExistingFileVar(&cmd.file)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run prints the differences between the access rules of the repository and the policy.
+func (cmd *ACLDiffCommand) Run() error {
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	repo, changes, err := aclPolicyChanges(client, cmd.file)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		fmt.Fprintf(cmd.io.Output(), "The access rules of %s match the policy.\n", repo)
+		return nil
+	}
+
+	err = printACLChanges(cmd.io.Output(), changes)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.io.Output())
+
+	return ErrACLPolicyDrift(repo, pluralize("change", "changes", len(changes)))
+}
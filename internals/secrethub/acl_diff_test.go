@@ -0,0 +1,104 @@
+package secrethub
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestACLDiffCommand_Run(t *testing.T) {
+	rootID := uuid.New()
+	prodID := uuid.New()
+
+	cases := map[string]struct {
+		policy string
+		out    string
+		err    error
+	}{
+		"match": {
+			policy: "" +
+				"repo: namespace/repo\n" +
+				"directories:\n" +
+				"  - path: /\n" +
+				"    users:\n" +
+				"      dev1: admin\n" +
+				"  - path: prod\n" +
+				"    services:\n" +
+				"      s-1234: read\n",
+			out: "The access rules of namespace/repo match the policy.\n",
+		},
+		"drift": {
+			policy: "" +
+				"repo: namespace/repo\n" +
+				"directories:\n" +
+				"  - path: /\n" +
+				"    users:\n" +
+				"      dev1: admin\n" +
+				"      dev2: read\n" +
+				"  - path: prod\n" +
+				"    services:\n" +
+				"      s-1234: write\n",
+			out: "" +
+				"+ namespace/repo       dev2    read\n" +
+				"~ namespace/repo/prod  s-1234  read -> write\n" +
+				"\n",
+			err: ErrACLPolicyDrift("namespace/repo", "2 changes"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			dir, cleanup := testdata.tempDir(t)
+			defer cleanup()
+			file := filepath.Join(dir, "policy.yml")
+			err := ioutil.WriteFile(file, []byte(tc.policy), 0644)
+			assert.OK(t, err)
+
+			io := fakeui.NewIO(t)
+			cmd := ACLDiffCommand{
+				io:   io,
+				file: file,
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						AccessRuleService: &fakeclient.AccessRuleService{
+							ListFunc: func(path string, depth int, ancestors bool) ([]*api.AccessRule, error) {
+								return []*api.AccessRule{
+									{Account: &api.Account{Name: "dev1"}, DirID: rootID, Permission: api.PermissionAdmin},
+									{Account: &api.Account{Name: "s-1234"}, DirID: prodID, Permission: api.PermissionRead},
+								}, nil
+							},
+						},
+						DirService: &fakeclient.DirService{
+							GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+								return &api.Tree{
+									ParentPath: "namespace",
+									Dirs: map[uuid.UUID]*api.Dir{
+										rootID: {Name: "repo", DirID: rootID},
+										prodID: {Name: "prod", DirID: prodID, ParentID: &rootID},
+									},
+									RootDir: &api.Dir{Name: "repo", DirID: rootID},
+								}, nil
+							},
+						},
+					}, nil
+				},
+			}
+
+			// Act
+			err = cmd.Run()
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.Out.String(), tc.out)
+		})
+	}
+}
@@ -2,8 +2,11 @@ package secrethub
 
 import (
 	"fmt"
+	"io"
 	"sort"
+	"strings"
 	"text/tabwriter"
+	"unicode/utf8"
 
 	"github.com/secrethub/secrethub-go/internals/api/uuid"
 
@@ -18,6 +21,8 @@ type ACLListCommand struct {
 	path          api.DirPath
 	depth         int
 	ancestors     bool
+	recursive     bool
+	tree          bool
 	useTimestamps bool
 	timeFormatter TimeFormatter
 	io            ui.IO
@@ -39,6 +44,9 @@ func (cmd *ACLListCommand) Register(r command.Registerer) {
 	clause.Arg("dir-path", "The path of the directory to list the access rules for").Required().PlaceHolder(optionalDirPathPlaceHolder).SetValue(&cmd.path)
 	clause.Flag("depth", "The maximum depth to which the rules of child directories should be displayed. Defaults to -1 (no limit).").Short('d').Default("-1").IntVar(&cmd.depth)
 	clause.Flag("all", "List all rules that apply on the directory, including rules on parent directories.").Short('a').BoolVar(&cmd.ancestors)
+	clause.Flag("recursive", "List the rules of all child directories, regardless of --depth.").Short('r').BoolVar(&cmd.recursive)
+	clause.Flag("tree", "Print the directory tree with the rules on each directory. "+
+		"A rule that gives an account a higher permission than it has on the parent directory is marked as overriding it.").BoolVar(&cmd.tree)
 	registerTimestampFlag(clause).BoolVar(&cmd.useTimestamps)
 
 	command.BindAction(clause, cmd.Run)
@@ -53,6 +61,9 @@ func (cmd *ACLListCommand) Run() error {
 // beforeRun configures the command using the flag values.
 func (cmd *ACLListCommand) beforeRun() {
 	cmd.timeFormatter = NewTimeFormatter(cmd.useTimestamps)
+	if cmd.recursive {
+		cmd.depth = -1
+	}
 }
 
 func (cmd *ACLListCommand) run() error {
@@ -71,6 +82,11 @@ func (cmd *ACLListCommand) run() error {
 		return err
 	}
 
+	if cmd.tree {
+		excludeTrash(tree, cmd.path.Value())
+		return printACLTree(cmd.io.Output(), cmd.path, tree, rules)
+	}
+
 	// Separate all rules into lists of rules per directory.
 	ruleIDMap := make(map[uuid.UUID][]int)
 	for i, rule := range rules {
@@ -127,3 +143,91 @@ func (cmd *ACLListCommand) run() error {
 
 	return nil
 }
+
+// printACLTree prints the directory tree with the access rules on each directory next to it.
+// Rules on directories outside of the tree, i.e. on its parent directories, are not printed,
+// but are inherited by the root of the tree.
+func printACLTree(w io.Writer, path api.DirPath, tree *api.Tree, rules []*api.AccessRule) error {
+	rulesByDir := make(map[uuid.UUID][]*api.AccessRule)
+	for _, rule := range rules {
+		rulesByDir[rule.DirID] = append(rulesByDir[rule.DirID], rule)
+	}
+
+	inTree := map[uuid.UUID]bool{}
+	var markDirs func(dir *api.Dir)
+	markDirs = func(dir *api.Dir) {
+		inTree[dir.DirID] = true
+		for _, sub := range dir.SubDirs {
+			markDirs(sub)
+		}
+	}
+	markDirs(tree.RootDir)
+
+	inherited := map[string]api.Permission{}
+	for _, rule := range rules {
+		if !inTree[rule.DirID] && rule.Permission > inherited[rule.Account.Name] {
+			inherited[rule.Account.Name] = rule.Permission
+		}
+	}
+
+	var lines [][2]string
+	var addDir func(dir *api.Dir, name, prefix, childPrefix string, inherited map[string]api.Permission)
+	addDir = func(dir *api.Dir, name, prefix, childPrefix string, inherited map[string]api.Permission) {
+		dirRules := rulesByDir[dir.DirID]
+		sort.Slice(dirRules, func(i, j int) bool {
+			return dirRules[i].Account.Name < dirRules[j].Account.Name
+		})
+
+		effective := make(map[string]api.Permission, len(inherited)+len(dirRules))
+		for account, permission := range inherited {
+			effective[account] = permission
+		}
+
+		annotations := make([]string, len(dirRules))
+		for i, rule := range dirRules {
+			annotations[i] = rule.Account.Name + ":" + rule.Permission.String()
+			if parent, ok := inherited[rule.Account.Name]; ok && parent < rule.Permission {
+				annotations[i] += " (overrides " + parent.String() + ")"
+			} else if ok && parent > rule.Permission {
+				annotations[i] += " (" + parent.String() + " inherited)"
+			}
+			if rule.Permission > effective[rule.Account.Name] {
+				effective[rule.Account.Name] = rule.Permission
+			}
+		}
+		lines = append(lines, [2]string{prefix + name + "/", strings.Join(annotations, ", ")})
+
+		sort.Sort(api.SortDirByName(dir.SubDirs))
+		for i, sub := range dir.SubDirs {
+			if i == len(dir.SubDirs)-1 {
+				addDir(sub, sub.Name, childPrefix+"└── ", childPrefix+"    ", effective)
+			} else {
+				addDir(sub, sub.Name, childPrefix+"├── ", childPrefix+"│   ", effective)
+			}
+		}
+	}
+	addDir(tree.RootDir, path.Value(), "", "", inherited)
+
+	width := 0
+	for _, line := range lines {
+		if n := utf8.RuneCountInString(line[0]); n > width {
+			width = n
+		}
+	}
+
+	for _, line := range lines {
+		if line[1] == "" {
+			_, err := fmt.Fprintln(w, line[0])
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		padding := strings.Repeat(" ", width-utf8.RuneCountInString(line[0])+4)
+		_, err := fmt.Fprintln(w, line[0]+padding+line[1])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
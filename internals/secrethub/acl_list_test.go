@@ -1,6 +1,7 @@
 package secrethub
 
 import (
+	"bytes"
 	"errors"
 	"testing"
 	"time"
@@ -168,3 +169,49 @@ func TestACLListCommand_run(t *testing.T) {
 		})
 	}
 }
+
+func TestPrintACLTree(t *testing.T) {
+	// Arrange
+	rootID := uuid.New()
+	prodID := uuid.New()
+	dbID := uuid.New()
+	stagingID := uuid.New()
+
+	tree := &api.Tree{
+		RootDir: &api.Dir{
+			Name:  "repo",
+			DirID: rootID,
+			SubDirs: []*api.Dir{
+				{Name: "staging", DirID: stagingID, ParentID: &rootID},
+				{
+					Name:     "prod",
+					DirID:    prodID,
+					ParentID: &rootID,
+					SubDirs: []*api.Dir{
+						{Name: "db", DirID: dbID, ParentID: &prodID},
+					},
+				},
+			},
+		},
+	}
+	rules := []*api.AccessRule{
+		{Account: &api.Account{Name: "dev2"}, DirID: rootID, Permission: api.PermissionWrite},
+		{Account: &api.Account{Name: "dev1"}, DirID: rootID, Permission: api.PermissionRead},
+		{Account: &api.Account{Name: "dev1"}, DirID: prodID, Permission: api.PermissionWrite},
+		{Account: &api.Account{Name: "s-1234"}, DirID: prodID, Permission: api.PermissionRead},
+		{Account: &api.Account{Name: "dev2"}, DirID: dbID, Permission: api.PermissionRead},
+	}
+	buf := bytes.Buffer{}
+
+	// Act
+	err := printACLTree(&buf, "namespace/repo", tree, rules)
+
+	// Assert
+	assert.OK(t, err)
+	assert.Equal(t, buf.String(), ""+
+		"namespace/repo/    dev1:read, dev2:write\n"+
+		"├── prod/          dev1:write (overrides read), s-1234:read\n"+
+		"│   └── db/        dev2:read (write inherited)\n"+
+		"└── staging/\n",
+	)
+}
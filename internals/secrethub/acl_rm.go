@@ -2,17 +2,20 @@ package secrethub
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
 
 	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
 )
 
 // ACLRmCommand handles removing an access rule.
 type ACLRmCommand struct {
 	path        api.DirPath
 	accountName api.AccountName
+	group       bool
 	force       bool
 	io          ui.IO
 	newClient   newClientFunc
@@ -32,6 +35,8 @@ func (cmd *ACLRmCommand) Register(r command.Registerer) {
 	clause.Alias("remove")
 	clause.Arg("dir-path", "The path of the directory to remove the access rule for").Required().PlaceHolder(optionalDirPathPlaceHolder).SetValue(&cmd.path)
 	clause.Arg("account-name", "The account name (username or service name) whose rule to remove").Required().SetValue(&cmd.accountName)
+	clause.Flag("group", "Remove the access rule of a group of the organization instead of an account. "+
+		"The account-name argument is the name of the group. The access rule is removed for every member of the group.").BoolVar(&cmd.group)
 	registerForceFlag(clause).BoolVar(&cmd.force)
 
 	command.BindAction(clause, cmd.Run)
@@ -66,6 +71,10 @@ func (cmd *ACLRmCommand) Run() error {
 
 	fmt.Fprintln(cmd.io.Output(), "Removing access rule...")
 
+	if cmd.group {
+		return cmd.removeGroupRule(client)
+	}
+
 	err = client.AccessRules().Delete(cmd.path.Value(), cmd.accountName.Value())
 	if err != nil {
 		return err
@@ -75,3 +84,36 @@ func (cmd *ACLRmCommand) Run() error {
 
 	return nil
 }
+
+// removeGroupRule removes the access rule for every member of the group and from the rules of the group.
+func (cmd *ACLRmCommand) removeGroupRule(client secrethub.ClientInterface) error {
+	org := strings.SplitN(cmd.path.Value(), "/", 2)[0]
+	groups, err := readOrgGroups(client, org)
+	if err != nil {
+		return err
+	}
+
+	group, err := groups.get(org, cmd.accountName.Value())
+	if err != nil {
+		return err
+	}
+
+	if !group.removeRule(cmd.path.Value()) {
+		return ErrGroupRuleNotFound(cmd.accountName, cmd.path)
+	}
+
+	for _, member := range group.Members {
+		err = client.AccessRules().Delete(cmd.path.Value(), member)
+		if err != nil && err != api.ErrAccessRuleNotFound {
+			return err
+		}
+	}
+
+	err = writeOrgGroups(client, org, groups)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Removal complete! The access rule for the group %s on %s has been removed.\n", cmd.accountName, cmd.path)
+	return nil
+}
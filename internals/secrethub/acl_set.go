@@ -2,16 +2,19 @@ package secrethub
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
 
 	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
 )
 
 // ACLSetCommand is a command to set access rules.
 type ACLSetCommand struct {
 	accountName api.AccountName
+	group       bool
 	force       bool
 	io          ui.IO
 	path        api.DirPath
@@ -34,6 +37,8 @@ func (cmd *ACLSetCommand) Register(r command.Registerer) {
 	clause.Arg("dir-path", "The path of the directory to set the access rule for").Required().PlaceHolder(optionalDirPathPlaceHolder).SetValue(&cmd.path)
 	clause.Arg("account-name", "The account name (username or service name) to set the access rule for").Required().SetValue(&cmd.accountName)
 	clause.Arg("permission", "The permission to set in the access rule.").Required().SetValue(&cmd.permission)
+	clause.Flag("group", "Set the access rule for a group of the organization instead of an account. "+
+		"The account-name argument is the name of the group. The access rule is set for every member of the group.").BoolVar(&cmd.group)
 	registerForceFlag(clause).BoolVar(&cmd.force)
 
 	command.BindAction(clause, cmd.Run)
@@ -70,6 +75,10 @@ func (cmd *ACLSetCommand) Run() error {
 		return err
 	}
 
+	if cmd.group {
+		return cmd.setGroupRule(client)
+	}
+
 	_, err = client.AccessRules().Set(cmd.path.Value(), cmd.permission.String(), cmd.accountName.Value())
 	if err != nil {
 		return err
@@ -80,3 +89,33 @@ func (cmd *ACLSetCommand) Run() error {
 	return nil
 
 }
+
+// setGroupRule sets the access rule for every member of the group and records it as a rule of the group.
+func (cmd *ACLSetCommand) setGroupRule(client secrethub.ClientInterface) error {
+	org := strings.SplitN(cmd.path.Value(), "/", 2)[0]
+	groups, err := readOrgGroups(client, org)
+	if err != nil {
+		return err
+	}
+
+	group, err := groups.get(org, cmd.accountName.Value())
+	if err != nil {
+		return err
+	}
+
+	for _, member := range group.Members {
+		_, err = client.AccessRules().Set(cmd.path.Value(), cmd.permission.String(), member)
+		if err != nil {
+			return err
+		}
+	}
+
+	group.setRule(cmd.path.Value(), cmd.permission.String())
+	err = writeOrgGroups(client, org, groups)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Access rule set for %s of the group!\n", pluralize("member", "members", len(group.Members)))
+	return nil
+}
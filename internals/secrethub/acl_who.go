@@ -0,0 +1,77 @@
+package secrethub
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secretpath"
+)
+
+// ACLWhoCommand lists the accounts that have access to a secret.
+type ACLWhoCommand struct {
+	path      api.SecretPath
+	level     api.Permission
+	io        ui.IO
+	newClient newClientFunc
+}
+
+// NewACLWhoCommand creates a new ACLWhoCommand.
+func NewACLWhoCommand(io ui.IO, newClient newClientFunc) *ACLWhoCommand {
+	return &ACLWhoCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *ACLWhoCommand) Register(r command.Registerer) {
+	clause := r.Command("who", "List the users and service accounts that have access to a secret.")
+	clause.HelpLong("Lists every account with an access rule on the directory of the secret or one of its parent directories, " +
+		"with its effective permission and the directory of the access rule that grants it.")
+	clause.Arg("secret-path", "The path of the secret.").Required().PlaceHolder(secretPathPlaceHolder).SetValue(&cmd.path)
+	clause.Flag("level", "Only list accounts with at least this permission. Options are: read, write and admin.").HintOptions("read", "write", "admin").SetValue(&cmd.level)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run prints the accounts with access to the secret.
+func (cmd *ACLWhoCommand) Run() error {
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	exists, err := client.Secrets().Exists(cmd.path.Value())
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return api.ErrSecretNotFound
+	}
+
+	rules, err := listGrantingRules(client, secretpath.Parent(cmd.path.Value()))
+	if err != nil {
+		return err
+	}
+
+	return printGrantingRules(cmd.io.Output(), rules, cmd.level)
+}
+
+// printGrantingRules prints the accounts of the rules with at least the given permission,
+// with the directory their permission is granted on.
+func printGrantingRules(w io.Writer, rules []aclRule, level api.Permission) error {
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(tw, "ACCOUNT\tPERMISSION\tGRANTED ON")
+	for _, rule := range rules {
+		if rule.permission < level {
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", rule.account, rule.permission, rule.path)
+	}
+	return tw.Flush()
+}
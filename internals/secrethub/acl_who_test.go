@@ -0,0 +1,86 @@
+package secrethub
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestListGrantingRules(t *testing.T) {
+	// Arrange
+	rules := map[string][]*api.AccessRule{
+		"namespace/repo": {
+			{Account: &api.Account{Name: "dev1"}, Permission: api.PermissionRead},
+			{Account: &api.Account{Name: "dev2"}, Permission: api.PermissionAdmin},
+		},
+		"namespace/repo/prod": {
+			{Account: &api.Account{Name: "dev1"}, Permission: api.PermissionWrite},
+			{Account: &api.Account{Name: "dev2"}, Permission: api.PermissionRead},
+			{Account: &api.Account{Name: "s-1234"}, Permission: api.PermissionRead},
+		},
+	}
+	client := fakeclient.Client{
+		AccessRuleService: &fakeclient.AccessRuleService{
+			ListFunc: func(path string, depth int, ancestors bool) ([]*api.AccessRule, error) {
+				return rules[path], nil
+			},
+		},
+	}
+
+	// Act
+	actual, err := listGrantingRules(client, "namespace/repo/prod/db")
+
+	// Assert
+	assert.OK(t, err)
+	assert.Equal(t, actual, []aclRule{
+		{path: "namespace/repo", account: "dev2", permission: api.PermissionAdmin},
+		{path: "namespace/repo/prod", account: "dev1", permission: api.PermissionWrite},
+		{path: "namespace/repo/prod", account: "s-1234", permission: api.PermissionRead},
+	})
+}
+
+func TestPrintGrantingRules(t *testing.T) {
+	rules := []aclRule{
+		{path: "namespace/repo", account: "dev2", permission: api.PermissionAdmin},
+		{path: "namespace/repo/prod", account: "dev1", permission: api.PermissionWrite},
+		{path: "namespace/repo/prod", account: "s-1234", permission: api.PermissionRead},
+	}
+
+	cases := map[string]struct {
+		level    api.Permission
+		expected string
+	}{
+		"all": {
+			level: api.PermissionNone,
+			expected: "" +
+				"ACCOUNT  PERMISSION  GRANTED ON\n" +
+				"dev2     admin       namespace/repo\n" +
+				"dev1     write       namespace/repo/prod\n" +
+				"s-1234   read        namespace/repo/prod\n",
+		},
+		"write": {
+			level: api.PermissionWrite,
+			expected: "" +
+				"ACCOUNT  PERMISSION  GRANTED ON\n" +
+				"dev2     admin       namespace/repo\n" +
+				"dev1     write       namespace/repo/prod\n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			buf := bytes.Buffer{}
+
+			// Act
+			err := printGrantingRules(&buf, rules, tc.level)
+
+			// Assert
+			assert.OK(t, err)
+			assert.Equal(t, buf.String(), tc.expected)
+		})
+	}
+}
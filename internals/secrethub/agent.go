@@ -0,0 +1,462 @@
+package secrethub
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/mlock"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/auth"
+	"github.com/secrethub/secrethub-go/internals/errio"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/credentials"
+	httpclient "github.com/secrethub/secrethub-go/pkg/secrethub/internals/http"
+)
+
+// Errors
+var (
+	errAgent                = errio.Namespace("agent")
+	ErrAgentRunning         = errAgent.Code("running").ErrorPref("an agent is already listening on %s")
+	ErrAgentNotRunning      = errAgent.Code("not_running").ErrorPref("no agent is listening on %s")
+	ErrAgentSocketExists    = errAgent.Code("socket_exists").ErrorPref("cannot listen on %s: the file exists and is not a socket")
+	ErrAgentSocketUnsafe    = errAgent.Code("socket_unsafe").ErrorPref("not using the agent on %s: the socket must be owned by the current user and only be accessible to them")
+	ErrAgentSocketDirUnsafe = errAgent.Code("socket_dir_unsafe").ErrorPref("cannot use an agent socket in %s: the directory must be owned by the current user and only be accessible to them (chmod 700)")
+	ErrAgentRequest         = errAgent.Code("request_failed").ErrorPref("the agent on %s responded with %s")
+	ErrAgentNotSupported    = errAgent.Code("not_supported").Error("the agent is not supported on this operating system")
+)
+
+const (
+	agentSocketName       = "agent.sock"
+	agentAuthenticatePath = "/v1/authenticate"
+	agentDecryptPath      = "/v1/decrypt"
+	agentStopPath         = "/v1/stop"
+	agentDialTimeout      = 1 * time.Second
+	agentRequestTimeout   = 5 * time.Second
+)
+
+// AgentCommand keeps the unlocked account credential in memory and uses it
+// to authenticate requests and decrypt data for other secrethub commands over a unix domain socket.
+type AgentCommand struct {
+	io              ui.IO
+	credentialStore CredentialConfig
+	logger          cli.Logger
+	lifetime        time.Duration
+}
+
+// NewAgentCommand creates a new AgentCommand.
+func NewAgentCommand(io ui.IO, credentialStore CredentialConfig, logger cli.Logger) *AgentCommand {
+	return &AgentCommand{
+		io:              io,
+		credentialStore: credentialStore,
+		logger:          logger,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *AgentCommand) Register(r command.Registerer) {
+	clause := r.Command("agent", "Keep your unlocked credential in memory, so other secrethub commands do not ask for its passphrase.")
+	clause.HelpLong("The agent asks for the passphrase of your credential once and keeps the unlocked credential in memory. " +
+		"Other secrethub commands that use the same configuration directory ask the agent over a unix domain socket " +
+		"to sign their requests and decrypt their secrets, instead of reading the credential file and asking for its passphrase, " +
+		"e.g. in scripts that run many commands. The credential itself never leaves the agent. " +
+		"Use --agent-socket or SECRETHUB_AGENT_SOCKET to use an agent that listens elsewhere.\n\n" +
+		"The socket is created in a directory that only the user running the agent can access, and can only be used by that user. " +
+		"Other commands ignore a socket that does not meet these requirements. " +
+		"The memory of the agent is locked where supported, so the credential is not written to swap. " +
+		"The agent runs in the foreground until it is interrupted, `" + ApplicationName + " logout` is run or its --lifetime has passed, e.g.:\n\n" +
+		"    secrethub agent --lifetime 8h &")
+	clause.Flag("lifetime", "Stop the agent after this duration, e.g. 8h. By default the agent runs until it is stopped.").DurationVar(&cmd.lifetime)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run unlocks the credential and uses it for other commands until the agent is stopped.
+func (cmd *AgentCommand) Run() error {
+	socket := cmd.credentialStore.AgentSocket()
+	if isAgentRunning(socket) {
+		return ErrAgentRunning(socket)
+	}
+
+	err := mlock.LockMemory()
+	if err == mlock.ErrNotSupported {
+		cmd.logger.Debugf("Locking memory is not supported on this system")
+	} else if err != nil {
+		cmd.logger.Warningf("Could not lock the memory of the agent, so the credential may be written to swap: %s", err)
+	}
+
+	key, err := cmd.credentialStore.Import()
+	if err != nil {
+		return err
+	}
+	authenticator, decrypter, err := key.Provide(nil)
+	if err != nil {
+		return err
+	}
+	agent := newAgentServer(authenticator, decrypter, cmd.logger)
+
+	listener, err := listenAgentSocket(socket)
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{Handler: agent}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGHUP, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	var expired <-chan time.Time
+	if cmd.lifetime > 0 {
+		expired = time.After(cmd.lifetime)
+	}
+
+	go func() {
+		select {
+		case <-stop:
+		case <-agent.stopped:
+			cmd.logger.Infof("The agent was asked to stop")
+		case <-expired:
+			cmd.logger.Infof("The lifetime of the agent has passed")
+		}
+		_ = server.Shutdown(context.Background())
+	}()
+
+	fmt.Fprintf(cmd.io.Output(), "Agent listening on %s\n", socket)
+
+	err = server.Serve(listener)
+	if err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// agentAuthenticateRequest is a request to authenticate, sent to the agent.
+type agentAuthenticateRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// agentServer authenticates requests and decrypts data with the credential,
+// without ever handing out the credential itself.
+type agentServer struct {
+	authenticator auth.Authenticator
+	decrypter     credentials.Decrypter
+	logger        cli.Logger
+	stopped       chan struct{}
+	stopOnce      sync.Once
+}
+
+// newAgentServer creates a new agentServer.
+func newAgentServer(authenticator auth.Authenticator, decrypter credentials.Decrypter, logger cli.Logger) *agentServer {
+	return &agentServer{
+		authenticator: authenticator,
+		decrypter:     decrypter,
+		logger:        logger,
+		stopped:       make(chan struct{}),
+	}
+}
+
+// ServeHTTP handles the requests of secrethub commands.
+func (s *agentServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var handle func(w http.ResponseWriter, r *http.Request)
+	switch r.URL.Path {
+	case agentAuthenticatePath:
+		handle = s.authenticate
+	case agentDecryptPath:
+		handle = s.decrypt
+	case agentStopPath:
+		handle = s.stop
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	handle(w, r)
+}
+
+// authenticate signs the request in the body and responds with its authenticated headers.
+func (s *agentServer) authenticate(w http.ResponseWriter, r *http.Request) {
+	var in agentAuthenticateRequest
+	err := json.NewDecoder(r.Body).Decode(&in)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	req, err := http.NewRequest(in.Method, in.URL, bytes.NewReader(in.Body))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	if in.Header != nil {
+		req.Header = in.Header
+	}
+
+	err = s.authenticator.Authenticate(req)
+	if err != nil {
+		s.logger.Warningf("Could not authenticate a request: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	s.logger.Debugf("Authenticated %s %s", req.Method, req.URL.Path)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(req.Header)
+}
+
+// decrypt decrypts the encrypted data in the body.
+func (s *agentServer) decrypt(w http.ResponseWriter, r *http.Request) {
+	var ciphertext api.EncryptedData
+	err := json.NewDecoder(r.Body).Decode(&ciphertext)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	plaintext, err := s.decrypter.Unwrap(&ciphertext)
+	if err != nil {
+		s.logger.Warningf("Could not decrypt data: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer wipe(plaintext)
+
+	s.logger.Debugf("Decrypted data")
+	w.Header().Set("Content-Type", "application/octet-stream")
+	_, _ = w.Write(plaintext)
+}
+
+// stop stops the agent, e.g. on logout.
+func (s *agentServer) stop(w http.ResponseWriter, r *http.Request) {
+	s.stopOnce.Do(func() {
+		close(s.stopped)
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// wipe overwrites the bytes with zeros.
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// listenAgentSocket listens on the unix domain socket, which only the current user can connect to.
+// The directory of the socket is created when it does not exist and must only be accessible to the current user.
+// The socket of an agent that did not stop cleanly is replaced.
+func listenAgentSocket(socket string) (net.Listener, error) {
+	dir := filepath.Dir(socket)
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+	err = checkAgentSocketDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Lstat(socket)
+	if err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, ErrAgentSocketExists(socket)
+		}
+		err = os.Remove(socket)
+		if err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return listenUnixSocket(socket)
+}
+
+// checkAgentSocketDir returns an error when the directory is not owned by the current user
+// or can be accessed by other users, as they could then replace the socket.
+func checkAgentSocketDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() || info.Mode().Perm()&0077 != 0 || !isOwnedByCurrentUser(info) {
+		return ErrAgentSocketDirUnsafe(dir)
+	}
+	return nil
+}
+
+// checkAgentSocket returns an error when the socket cannot be trusted to belong to
+// an agent of the current user, so that a socket planted by someone else is never used.
+func checkAgentSocket(socket string) error {
+	info, err := os.Lstat(socket)
+	if os.IsNotExist(err) {
+		return ErrAgentNotRunning(socket)
+	} else if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 || info.Mode().Perm()&0177 != 0 || !isOwnedByCurrentUser(info) {
+		return ErrAgentSocketUnsafe(socket)
+	}
+	return checkAgentSocketDir(filepath.Dir(socket))
+}
+
+// isAgentRunning returns whether an agent is listening on the socket.
+func isAgentRunning(socket string) bool {
+	conn, err := net.DialTimeout("unix", socket, agentDialTimeout)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
+// isOwnedByCurrentUser returns whether the file is owned by the user running the process.
+// On Windows, where files are not owned by a uid, this is always false.
+func isOwnedByCurrentUser(info os.FileInfo) bool {
+	uid, _, ok := fileOwner(info)
+	return ok && uid == os.Getuid()
+}
+
+// agentClient authenticates requests and decrypts data with the credential of
+// the agent listening on a socket. It can be used as a credentials.Provider.
+type agentClient struct {
+	socket string
+	client *http.Client
+}
+
+// dialAgent returns a client for the agent listening on the socket.
+// ErrAgentNotRunning is returned when no agent is listening
+// and ErrAgentSocketUnsafe when the socket cannot be trusted.
+func dialAgent(socket string) (*agentClient, error) {
+	err := checkAgentSocket(socket)
+	if err != nil {
+		return nil, err
+	}
+	if !isAgentRunning(socket) {
+		return nil, ErrAgentNotRunning(socket)
+	}
+
+	return &agentClient{
+		socket: socket,
+		client: &http.Client{
+			Timeout: agentRequestTimeout,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					dialer := net.Dialer{Timeout: agentDialTimeout}
+					return dialer.DialContext(ctx, "unix", socket)
+				},
+			},
+		},
+	}, nil
+}
+
+// Provide implements the credentials.Provider interface.
+func (a *agentClient) Provide(*httpclient.Client) (auth.Authenticator, credentials.Decrypter, error) {
+	return a, a, nil
+}
+
+// Authenticate lets the agent sign the request.
+func (a *agentClient) Authenticate(r *http.Request) error {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		_ = r.Body.Close()
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	resp, err := a.post(agentAuthenticatePath, agentAuthenticateRequest{
+		Method: r.Method,
+		URL:    r.URL.String(),
+		Header: r.Header,
+		Body:   body,
+	})
+	if err != nil {
+		return err
+	}
+
+	var header http.Header
+	err = json.Unmarshal(resp, &header)
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		r.Header[name] = values
+	}
+	return nil
+}
+
+// Unwrap lets the agent decrypt the ciphertext.
+func (a *agentClient) Unwrap(ciphertext *api.EncryptedData) ([]byte, error) {
+	return a.post(agentDecryptPath, ciphertext)
+}
+
+// stop asks the agent to stop.
+func (a *agentClient) stop() error {
+	_, err := a.post(agentStopPath, nil)
+	return err
+}
+
+// post sends the value as JSON to the agent and returns the body of its response.
+func (a *agentClient) post(path string, in interface{}) ([]byte, error) {
+	var body bytes.Buffer
+	if in != nil {
+		err := json.NewEncoder(&body).Encode(in)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := a.client.Post("http://agent"+path, "application/json", &body)
+	if err != nil {
+		return nil, ErrAgentNotRunning(a.socket)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return nil, ErrAgentRequest(a.socket, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// stopAgent stops the agent listening on the socket, if any.
+// It returns whether an agent was stopped.
+func stopAgent(socket string) (bool, error) {
+	if !isAgentRunning(socket) {
+		return false, nil
+	}
+
+	agent, err := dialAgent(socket)
+	if err != nil {
+		return false, err
+	}
+
+	err = agent.stop()
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
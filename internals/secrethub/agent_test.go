@@ -0,0 +1,270 @@
+// +build !windows
+
+package secrethub
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+// fakeAuthenticator signs a request by describing it in its Authorization header.
+type fakeAuthenticator struct{}
+
+func (fakeAuthenticator) Authenticate(r *http.Request) error {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Authorization", "signed "+r.Method+" "+r.URL.Path+" "+string(body))
+	return nil
+}
+
+func TestAgentServer_ServeHTTP(t *testing.T) {
+	cases := map[string]struct {
+		method string
+		path   string
+		body   string
+		status int
+		out    string
+	}{
+		"authenticate": {
+			method: http.MethodPost,
+			path:   agentAuthenticatePath,
+			body:   `{"method":"GET","url":"https://api.secrethub.io/me/user","header":{"Date":["today"]}}`,
+			status: http.StatusOK,
+			out:    `{"Authorization":["signed GET /me/user "],"Date":["today"]}` + "\n",
+		},
+		"authenticate invalid body": {
+			method: http.MethodPost,
+			path:   agentAuthenticatePath,
+			body:   "not json",
+			status: http.StatusBadRequest,
+			out:    "Bad Request\n",
+		},
+		"decrypt invalid body": {
+			method: http.MethodPost,
+			path:   agentDecryptPath,
+			body:   "not json",
+			status: http.StatusBadRequest,
+			out:    "Bad Request\n",
+		},
+		"stop": {
+			method: http.MethodPost,
+			path:   agentStopPath,
+			status: http.StatusNoContent,
+		},
+		"other method": {
+			method: http.MethodGet,
+			path:   agentAuthenticatePath,
+			status: http.StatusMethodNotAllowed,
+			out:    "Method Not Allowed\n",
+		},
+		"other path": {
+			method: http.MethodPost,
+			path:   "/v1/credential",
+			status: http.StatusNotFound,
+			out:    "404 page not found\n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			agent := newAgentServer(fakeAuthenticator{}, nil, cli.NewLogger())
+			recorder := httptest.NewRecorder()
+
+			// Act
+			agent.ServeHTTP(recorder, httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)))
+
+			// Assert
+			assert.Equal(t, recorder.Code, tc.status)
+			assert.Equal(t, recorder.Body.String(), tc.out)
+		})
+	}
+}
+
+func TestAgentClient(t *testing.T) {
+	// Setup
+	// The path of a unix domain socket is limited to about 100 characters,
+	// so the socket is not created in the testdata directory of the package.
+	dir, err := ioutil.TempDir("", "agent")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, agentSocketName)
+
+	// No agent is running yet.
+	_, err = dialAgent(socket)
+	assert.Equal(t, err, ErrAgentNotRunning(socket))
+	stopped, err := stopAgent(socket)
+	assert.OK(t, err)
+	assert.Equal(t, stopped, false)
+
+	listener, err := listenAgentSocket(socket)
+	assert.OK(t, err)
+	agent := newAgentServer(fakeAuthenticator{}, nil, cli.NewLogger())
+	server := &http.Server{Handler: agent}
+	go server.Serve(listener)
+
+	info, err := os.Stat(socket)
+	assert.OK(t, err)
+	assert.Equal(t, info.Mode().Perm(), os.FileMode(0600))
+
+	// Act
+	client, err := dialAgent(socket)
+	assert.OK(t, err)
+	req, err := http.NewRequest(http.MethodPost, "https://api.secrethub.io/repos", bytes.NewBufferString("data"))
+	assert.OK(t, err)
+	err = client.Authenticate(req)
+
+	// Assert
+	assert.OK(t, err)
+	assert.Equal(t, req.Header.Get("Authorization"), "signed POST /repos data")
+	body, err := ioutil.ReadAll(req.Body)
+	assert.OK(t, err)
+	assert.Equal(t, string(body), "data")
+
+	// The agent stops on request, e.g. on logout.
+	stopped, err = stopAgent(socket)
+	assert.OK(t, err)
+	assert.Equal(t, stopped, true)
+	<-agent.stopped
+
+	// The socket of an agent that did not stop cleanly is replaced.
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	err = server.Close()
+	assert.OK(t, err)
+	listener, err = listenAgentSocket(socket)
+	assert.OK(t, err)
+	err = listener.Close()
+	assert.OK(t, err)
+}
+
+func TestCheckAgentSocket(t *testing.T) {
+	cases := map[string]struct {
+		setup func(dir, socket string) error
+		err   func(dir, socket string) error
+	}{
+		"valid": {
+			err: func(dir, socket string) error {
+				return nil
+			},
+		},
+		"not exist": {
+			setup: func(dir, socket string) error {
+				return os.Remove(socket)
+			},
+			err: func(dir, socket string) error {
+				return ErrAgentNotRunning(socket)
+			},
+		},
+		"not a socket": {
+			setup: func(dir, socket string) error {
+				err := os.Remove(socket)
+				if err != nil {
+					return err
+				}
+				return ioutil.WriteFile(socket, []byte("not a socket"), 0600)
+			},
+			err: func(dir, socket string) error {
+				return ErrAgentSocketUnsafe(socket)
+			},
+		},
+		"socket accessible to others": {
+			setup: func(dir, socket string) error {
+				return os.Chmod(socket, 0666)
+			},
+			err: func(dir, socket string) error {
+				return ErrAgentSocketUnsafe(socket)
+			},
+		},
+		"directory accessible to others": {
+			setup: func(dir, socket string) error {
+				return os.Chmod(dir, 0777)
+			},
+			err: func(dir, socket string) error {
+				return ErrAgentSocketDirUnsafe(dir)
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			dir, err := ioutil.TempDir("", "agent")
+			assert.OK(t, err)
+			defer os.RemoveAll(dir)
+			socket := filepath.Join(dir, agentSocketName)
+
+			listener, err := listenAgentSocket(socket)
+			assert.OK(t, err)
+			listener.(*net.UnixListener).SetUnlinkOnClose(false)
+			err = listener.Close()
+			assert.OK(t, err)
+
+			if tc.setup != nil {
+				err = tc.setup(dir, socket)
+				assert.OK(t, err)
+			}
+
+			// Act
+			err = checkAgentSocket(socket)
+
+			// Assert
+			assert.Equal(t, err, tc.err(dir, socket))
+		})
+	}
+}
+
+func TestListenAgentSocket(t *testing.T) {
+	cases := map[string]struct {
+		setup func(dir, socket string) error
+		err   func(dir, socket string) error
+	}{
+		"file exists": {
+			setup: func(dir, socket string) error {
+				return ioutil.WriteFile(socket, []byte("not a socket"), 0600)
+			},
+			err: func(dir, socket string) error {
+				return ErrAgentSocketExists(socket)
+			},
+		},
+		"directory accessible to others": {
+			setup: func(dir, socket string) error {
+				return os.Chmod(dir, 0755)
+			},
+			err: func(dir, socket string) error {
+				return ErrAgentSocketDirUnsafe(dir)
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			dir, err := ioutil.TempDir("", "agent")
+			assert.OK(t, err)
+			defer os.RemoveAll(dir)
+			socket := filepath.Join(dir, agentSocketName)
+
+			err = tc.setup(dir, socket)
+			assert.OK(t, err)
+
+			// Act
+			_, err = listenAgentSocket(socket)
+
+			// Assert
+			assert.Equal(t, err, tc.err(dir, socket))
+		})
+	}
+}
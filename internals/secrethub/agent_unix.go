@@ -0,0 +1,18 @@
+// +build !windows
+
+package secrethub
+
+import (
+	"net"
+	"syscall"
+)
+
+// listenUnixSocket listens on the unix domain socket. The socket is created with
+// mode 0600, so there is no moment at which other users can connect to it.
+// The umask is process wide, which is fine as the agent creates no other files meanwhile.
+func listenUnixSocket(socket string) (net.Listener, error) {
+	umask := syscall.Umask(0177)
+	defer syscall.Umask(umask)
+
+	return net.Listen("unix", socket)
+}
@@ -0,0 +1,11 @@
+package secrethub
+
+import (
+	"net"
+)
+
+// listenUnixSocket is not supported on Windows, as the socket cannot be created
+// with permissions that only allow the current user to connect to it.
+func listenUnixSocket(socket string) (net.Listener, error) {
+	return nil, ErrAgentNotSupported
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/stats"
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 	"github.com/secrethub/secrethub-cli/internals/demo"
 
@@ -66,9 +68,12 @@ var (
 type App struct {
 	credentialStore CredentialConfig
 	clientFactory   ClientFactory
+	secretCache     SecretCache
 	cli             *cli.App
 	io              ui.IO
 	logger          cli.Logger
+	stats           *stats.Collector
+	version         string
 }
 
 // newClientFunc creates a ClientAdapater.
@@ -89,6 +94,8 @@ func NewApp() *App {
 		"Options set on the command-line take precedence over those set in the environment. " +
 		"The format for environment variables is `SECRETHUB_[COMMAND_]FLAG_NAME`."
 
+	logger := cli.NewLogger()
+	collector := stats.NewCollector()
 	app := App{
 		cli: cli.NewApp(ApplicationName, help).ExtraEnvVarFunc(
 			func(key string) bool {
@@ -96,16 +103,20 @@ func NewApp() *App {
 			},
 		),
 		credentialStore: store,
-		clientFactory:   NewClientFactory(store),
+		clientFactory:   NewClientFactory(store, logger),
+		secretCache:     NewSecretCache(store, collector),
 		io:              io,
-		logger:          cli.NewLogger(),
+		logger:          logger,
+		stats:           collector,
 	}
 
 	RegisterDebugFlag(app.cli, app.logger)
+	RegisterLogFlags(app.cli, app.logger)
 	RegisterMlockFlag(app.cli)
 	RegisterColorFlag(app.cli)
 	app.credentialStore.Register(app.cli)
 	app.clientFactory.Register(app.cli)
+	app.secretCache.Register(app.cli)
 	app.registerCommands()
 
 	app.cli.UsageTemplate(DefaultUsageTemplate)
@@ -145,17 +156,33 @@ func NewApp() *App {
 // Version adds a flag for displaying the application version number.
 func (app *App) Version(version string, commit string) *App {
 	app.cli = app.cli.Version(ApplicationName + " version " + version + ", build " + commit)
+	app.version = version
 	return app
 }
 
 // Run builds the command-line application, parses the arguments,
 // configures global behavior and executes the command given by the args.
 func (app *App) Run(args []string) error {
+	command := app.commandName(args)
+	start := time.Now()
+
 	// Parse also executes the command when parsing is successful.
 	_, err := app.cli.Parse(args)
+
+	app.recordStats(command, time.Since(start), err != nil)
 	return err
 }
 
+// commandName returns the full name of the command selected by the args,
+// without any of its arguments. Hidden commands return an empty name.
+func (app *App) commandName(args []string) string {
+	ctx, err := app.cli.ParseContext(args)
+	if err != nil || ctx.SelectedCommand == nil || ctx.SelectedCommand.Model().Hidden {
+		return ""
+	}
+	return ctx.SelectedCommand.FullCommand()
+}
+
 // Model returns the CLI application model containing all the SecretHub CLI commands, flags, and args.
 func (app *App) Model() *kingpin.ApplicationModel {
 	return app.cli.Model()
@@ -168,33 +195,64 @@ func (app *App) registerCommands() {
 	NewOrgCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 	NewRepoCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 	NewACLCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewConventionCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewMetaCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 	NewServiceCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 	NewAccountCommand(app.io, app.clientFactory.NewClient, app.credentialStore).Register(app.cli)
 	NewCredentialCommand(app.io, app.clientFactory, app.credentialStore).Register(app.cli)
 	NewConfigCommand(app.io, app.credentialStore).Register(app.cli)
+	NewSessionCommand(app.io, app.credentialStore).Register(app.cli)
 	NewEnvCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewSSHCommand(app.io, app.clientFactory.NewClient, app.logger).Register(app.cli)
+	NewKubeconfigCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewK8sCommand(app.io, app.clientFactory.NewClient, app.logger).Register(app.cli)
+	NewDockerCredentialHelperCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewGitCredentialCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewImportCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewCacheCommand(app.io, app.clientFactory.NewClient, app.secretCache).Register(app.cli)
+	NewAgentCommand(app.io, app.credentialStore, app.logger).Register(app.cli)
+	NewDaemonCommand(app.io, app.credentialStore).Register(app.cli)
+	NewSystemdCommand(app.io, app.credentialStore).Register(app.cli)
+	NewStatsCommand(app.io, app.credentialStore, func() string { return app.version }).Register(app.cli)
 
 	// Commands
 	NewInitCommand(app.io, app.clientFactory.NewUnauthenticatedClient, app.clientFactory.NewClientWithCredentials, app.credentialStore).Register(app.cli)
 	NewSignUpCommand(app.io, app.clientFactory.NewUnauthenticatedClient, app.credentialStore).Register(app.cli)
+	NewLoginCommand(app.io, app.credentialStore).Register(app.cli)
+	NewLogoutCommand(app.io, app.credentialStore).Register(app.cli)
 	NewWriteCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 	NewReadCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewEditCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 	NewGenerateSecretCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewRotateCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewExpiringCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 	NewLsCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 	NewMkDirCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 	NewRmCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewCpCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewMvCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewRestoreCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewTrashCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 	NewTreeCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewFindCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 	NewInspectCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 	NewAuditCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
-	NewInjectCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
-	NewRunCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
-	NewPrintEnvCommand(app.cli, app.io).Register(app.cli)
+	NewAccessReportCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewLintCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewDiffCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewExportCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewAccessCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewProvisionCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewSyncCommand(app.io, app.clientFactory.NewClient, app.credentialStore, app.logger).Register(app.cli)
+	NewInjectCommand(app.io, app.clientFactory.NewClient, app.secretCache).Register(app.cli)
+	NewRunCommand(app.io, app.clientFactory.NewClient, app.secretCache, app.logger).Register(app.cli)
+	NewPrintEnvCommand(app.cli, app.io, app.clientFactory.NewClient).Register(app.cli)
 
 	// Hidden commands
 	NewClearCommand(app.io).Register(app.cli)
 	NewSetCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 	NewClearClipboardCommand().Register(app.cli)
-	NewKeyringClearCommand().Register(app.cli)
+	NewKeyringClearCommand(app.credentialStore).Register(app.cli)
 
 	demo.NewCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 }
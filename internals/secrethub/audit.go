@@ -66,7 +66,11 @@ func (cmd *AuditCommand) Register(r command.Registerer) {
 		defaultLimit = pipedOutputLineLimit
 	}
 
-	clause := r.Command("audit", "Show the audit log.")
+	auditClause := r.Command("audit", "Show the audit log or mirror it to a local, tamper-evident log.")
+	NewAuditMirrorCommand(cmd.io, cmd.newClient).Register(auditClause)
+
+	clause := auditClause.Command("show", "Show the audit log of a repository or secret. This is the default audit command.")
+	clause.Default()
 	clause.Arg("repo-path or secret-path", "Path to the repository or the secret to audit "+repoPathPlaceHolder+" or "+secretPathPlaceHolder).SetValue(&cmd.path)
 	clause.Flag("per-page", "Number of audit events shown per page").Default("20").Hidden().IntVar(&cmd.perPage)
 	clause.Flag("output-format", "Specify the format in which to output the log. Options are: table and json. If the output of the command is parsed by a script an alternative of the table format must be used.").HintOptions("table", "json").Default("table").StringVar(&cmd.format)
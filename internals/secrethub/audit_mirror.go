@@ -0,0 +1,290 @@
+package secrethub
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/atomicfile"
+	"github.com/secrethub/secrethub-cli/internals/cli/hashchain"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/iterator"
+)
+
+// Errors
+var (
+	ErrInvalidAuditMirrorHead  = errAudit.Code("invalid_mirror_head").ErrorPref("invalid head %s: it must be formatted as <seq>:<hash>")
+	ErrAuditMirrorHeadMismatch = errAudit.Code("mirror_head_mismatch").ErrorPref("the log does not contain the expected head %s: %s")
+	ErrAuditMirrorKeyInMirror  = errAudit.Code("mirror_key_in_mirror").ErrorPref("the key file %s must not be in the mirror directory %s: anyone who can change the log could then also rehash it")
+	ErrAuditMirrorNoKey        = errAudit.Code("mirror_no_key").ErrorPref("the key file %s does not exist: it is created by the first `" + ApplicationName + " audit mirror sync`")
+	ErrInvalidAuditMirrorKey   = errAudit.Code("invalid_mirror_key").ErrorPref("the key file %s does not contain a valid key")
+)
+
+const (
+	auditMirrorLogFile = "audit.log"
+	// auditMirrorKeySize is the size of the key the log is hashed with in bytes.
+	auditMirrorKeySize = 32
+)
+
+// auditMirrorRecord is the data of a single entry in the audit mirror.
+type auditMirrorRecord struct {
+	EventID string    `json:"event_id"`
+	Repo    string    `json:"repo"`
+	Event   api.Audit `json:"event"`
+}
+
+// auditMirrorMembersRecord records the members of an organization and their roles.
+// Organizations have no audit log of their own, so a record is added whenever the
+// members differ from the last record.
+type auditMirrorMembersRecord struct {
+	Org        string            `json:"org"`
+	Members    map[string]string `json:"members"`
+	ObservedAt time.Time         `json:"observed_at"`
+}
+
+// AuditMirrorCommand copies the audit events of all repositories in a namespace to a local, hash-chained log.
+type AuditMirrorCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+	namespace api.Namespace
+	dir       string
+	keyFile   string
+	now       func() time.Time
+}
+
+// NewAuditMirrorCommand creates a new AuditMirrorCommand.
+func NewAuditMirrorCommand(io ui.IO, newClient newClientFunc) *AuditMirrorCommand {
+	return &AuditMirrorCommand{
+		io:        io,
+		newClient: newClient,
+		now:       time.Now,
+	}
+}
+
+// Register registers the command, its sub-commands, arguments and flags on the provided Registerer.
+func (cmd *AuditMirrorCommand) Register(r command.Registerer) {
+	mirrorClause := r.Command("mirror", "Maintain an append-only, tamper-evident local copy of the audit events of a namespace.")
+	mirrorClause.HelpLong("The events of all repositories in the namespace are appended to " + auditMirrorLogFile + " in the mirror directory. " +
+		"Every entry contains the hash of the previous entry, so changing, removing or reordering entries is detected by `" + ApplicationName + " audit mirror verify`. " +
+		"The hashes are keyed with the key in --key-file, which is created on the first sync and must be stored outside of the mirror directory, " +
+		"so that someone who can change the log cannot compute matching hashes. " +
+		"Run the command periodically to append new events; only the events since the last run are fetched.\n\n" +
+		"Organizations have no audit log of their own. For an organization, the members and their roles are recorded instead, " +
+		"whenever they differ from the last record.\n\n" +
+		"After every run, the hash of the last entry (the head) is printed. " +
+		"Record it outside of the mirror directory, e.g. in another system's log, so that removing entries from the end of the log can be detected with --head.")
+	NewAuditMirrorVerifyCommand(cmd.io).Register(mirrorClause)
+
+	clause := mirrorClause.Command("sync", "Append the new audit events of a namespace to the mirror. This is the default mirror command.")
+	clause.Default()
+	clause.Arg("namespace", "The namespace (organization or username) to mirror the audit events of.").Required().SetValue(&cmd.namespace)
+	clause.Flag("dir", "The directory to store the mirror in.").Required().StringVar(&cmd.dir)
+	clause.Flag("key-file", "The file with the key to hash the log with, outside of the mirror directory. It is created when it does not exist.").Required().StringVar(&cmd.keyFile)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run appends the new audit events to the mirror.
+func (cmd *AuditMirrorCommand) Run() error {
+	err := os.MkdirAll(cmd.dir, 0750)
+	if err != nil {
+		return err
+	}
+
+	key, err := readAuditMirrorKey(cmd.keyFile, cmd.dir, true)
+	if err != nil {
+		return err
+	}
+
+	logFile := filepath.Join(cmd.dir, auditMirrorLogFile)
+
+	// The log is verified before anything is appended to it.
+	mirrored := map[string]bool{}
+	var members map[string]string
+	head, err := hashchain.Read(logFile, key, func(entry hashchain.Entry) error {
+		var record struct {
+			EventID string            `json:"event_id"`
+			Members map[string]string `json:"members"`
+		}
+		err := json.Unmarshal(entry.Data, &record)
+		if err != nil {
+			return err
+		}
+		if record.Members != nil {
+			members = record.Members
+		} else {
+			mirrored[record.EventID] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	repos, err := client.Repos().List(cmd.namespace.String())
+	if err != nil {
+		return err
+	}
+
+	var records []auditMirrorRecord
+	for _, repo := range repos {
+		repoPath := repo.Path().Value()
+		// The events are returned from new to old, so all events after
+		// the first one that is already mirrored have been mirrored too.
+		iter := client.Repos().EventIterator(repoPath, &secrethub.AuditEventIteratorParams{})
+		for {
+			event, err := iter.Next()
+			if err == iterator.Done {
+				break
+			} else if err != nil {
+				return err
+			}
+
+			eventID := event.EventID.String()
+			if mirrored[eventID] {
+				break
+			}
+			mirrored[eventID] = true
+
+			records = append(records, auditMirrorRecord{
+				EventID: eventID,
+				Repo:    repoPath,
+				Event:   event,
+			})
+		}
+	}
+
+	sortAuditMirrorRecords(records)
+
+	data := make([]interface{}, 0, len(records)+1)
+	for _, record := range records {
+		data = append(data, record)
+	}
+
+	membersRecord, err := cmd.membersRecord(client, members)
+	if err != nil {
+		return err
+	}
+	if membersRecord != nil {
+		data = append(data, membersRecord)
+	}
+
+	if len(data) > 0 {
+		head, err = hashchain.Append(logFile, key, head, data...)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Mirrored %s.\n", pluralize("new event", "new events", len(records)))
+	if membersRecord != nil {
+		fmt.Fprintf(cmd.io.Output(), "Recorded the changed members of %s.\n", cmd.namespace)
+	}
+	fmt.Fprintf(cmd.io.Output(), "Head: %s\n", head)
+	return nil
+}
+
+// membersRecord returns a record of the members of the organization when they differ from the last recorded members.
+// It returns nil when they did not change or when the namespace is not an organization.
+func (cmd *AuditMirrorCommand) membersRecord(client secrethub.ClientInterface, last map[string]string) (*auditMirrorMembersRecord, error) {
+	_, err := client.Orgs().Get(cmd.namespace.String())
+	if api.IsErrNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	orgMembers, err := client.Orgs().Members().List(cmd.namespace.String())
+	if err != nil {
+		return nil, err
+	}
+
+	members := make(map[string]string, len(orgMembers))
+	for _, member := range orgMembers {
+		if member.User != nil {
+			members[member.User.Username] = string(member.Role)
+		}
+	}
+	if reflect.DeepEqual(members, last) {
+		return nil, nil
+	}
+
+	return &auditMirrorMembersRecord{
+		Org:        cmd.namespace.String(),
+		Members:    members,
+		ObservedAt: cmd.now().UTC(),
+	}, nil
+}
+
+// readAuditMirrorKey reads the key the audit mirror in dir is hashed with from the key file.
+// When create is set, a new random key is written to the key file when it does not exist.
+func readAuditMirrorKey(keyFile, dir string, create bool) ([]byte, error) {
+	absKeyFile, err := filepath.Abs(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if absKeyFile == absDir || strings.HasPrefix(absKeyFile, absDir+string(filepath.Separator)) {
+		return nil, ErrAuditMirrorKeyInMirror(keyFile, dir)
+	}
+
+	raw, err := ioutil.ReadFile(keyFile)
+	if os.IsNotExist(err) {
+		if !create {
+			return nil, ErrAuditMirrorNoKey(keyFile)
+		}
+
+		key := make([]byte, auditMirrorKeySize)
+		_, err = rand.Read(key)
+		if err != nil {
+			return nil, err
+		}
+		err = atomicfile.WriteFile(keyFile, []byte(hex.EncodeToString(key)+"\n"), 0600)
+		if err != nil {
+			return nil, ErrCannotWrite(keyFile, err)
+		}
+		return key, nil
+	} else if err != nil {
+		return nil, ErrCannotReadFile(keyFile, err)
+	}
+
+	key, err := hex.DecodeString(strings.TrimSpace(string(raw)))
+	if err != nil || len(key) != auditMirrorKeySize {
+		return nil, ErrInvalidAuditMirrorKey(keyFile)
+	}
+	return key, nil
+}
+
+// sortAuditMirrorRecords sorts the records chronologically, so that the mirror is
+// in the order the events happened, regardless of the order they are fetched in.
+func sortAuditMirrorRecords(records []auditMirrorRecord) {
+	sort.SliceStable(records, func(i, j int) bool {
+		if !records[i].Event.LoggedAt.Equal(records[j].Event.LoggedAt) {
+			return records[i].Event.LoggedAt.Before(records[j].Event.LoggedAt)
+		}
+		if records[i].Repo != records[j].Repo {
+			return records[i].Repo < records[j].Repo
+		}
+		return records[i].EventID < records[j].EventID
+	})
+}
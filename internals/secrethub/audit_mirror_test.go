@@ -0,0 +1,185 @@
+package secrethub
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/hashchain"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestParseAuditMirrorHead(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+
+	cases := map[string]struct {
+		value    string
+		expected hashchain.Head
+		err      error
+	}{
+		"valid": {
+			value:    "42:" + hash,
+			expected: hashchain.Head{Seq: 42, Hash: hash},
+		},
+		"upper case with newline": {
+			value:    "42:" + strings.ToUpper(hash) + "\n",
+			expected: hashchain.Head{Seq: 42, Hash: hash},
+		},
+		"no separator": {
+			value: hash,
+			err:   ErrInvalidAuditMirrorHead(hash),
+		},
+		"short hash": {
+			value: "42:abab",
+			err:   ErrInvalidAuditMirrorHead("42:abab"),
+		},
+		"zero sequence": {
+			value: "0:" + hash,
+			err:   ErrInvalidAuditMirrorHead("0:" + hash),
+		},
+		"invalid sequence": {
+			value: "x:" + hash,
+			err:   ErrInvalidAuditMirrorHead("x:" + hash),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := parseAuditMirrorHead(tc.value)
+
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
+
+func TestSortAuditMirrorRecords(t *testing.T) {
+	now := time.Date(2019, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	records := []auditMirrorRecord{
+		{EventID: "c", Repo: "company/b", Event: api.Audit{LoggedAt: now}},
+		{EventID: "d", Repo: "company/a", Event: api.Audit{LoggedAt: now.Add(time.Minute)}},
+		{EventID: "b", Repo: "company/a", Event: api.Audit{LoggedAt: now}},
+		{EventID: "a", Repo: "company/a", Event: api.Audit{LoggedAt: now.Add(-time.Minute)}},
+	}
+
+	sortAuditMirrorRecords(records)
+
+	ids := make([]string, len(records))
+	for i, record := range records {
+		ids[i] = record.EventID
+	}
+	assert.Equal(t, ids, []string{"a", "b", "c", "d"})
+}
+
+func TestReadAuditMirrorKey(t *testing.T) {
+	// Setup
+	dir, cleanup := testdata.tempDir(t)
+	defer cleanup()
+	mirrorDir := filepath.Join(dir, "mirror")
+	keyFile := filepath.Join(dir, "mirror.key")
+
+	// Act
+	_, err := readAuditMirrorKey(filepath.Join(mirrorDir, "mirror.key"), mirrorDir, true)
+	assert.Equal(t, err, ErrAuditMirrorKeyInMirror(filepath.Join(mirrorDir, "mirror.key"), mirrorDir))
+
+	_, err = readAuditMirrorKey(keyFile, mirrorDir, false)
+	assert.Equal(t, err, ErrAuditMirrorNoKey(keyFile))
+
+	created, err := readAuditMirrorKey(keyFile, mirrorDir, true)
+	assert.OK(t, err)
+	read, err := readAuditMirrorKey(keyFile, mirrorDir, false)
+	assert.OK(t, err)
+
+	// Assert
+	assert.Equal(t, len(created), auditMirrorKeySize)
+	assert.Equal(t, read, created)
+	info, err := os.Stat(keyFile)
+	assert.OK(t, err)
+	assert.Equal(t, info.Mode().Perm(), os.FileMode(0600))
+
+	err = ioutil.WriteFile(keyFile, []byte("not a key\n"), 0600)
+	assert.OK(t, err)
+	_, err = readAuditMirrorKey(keyFile, mirrorDir, false)
+	assert.Equal(t, err, ErrInvalidAuditMirrorKey(keyFile))
+}
+
+func TestAuditMirrorCommand_Run(t *testing.T) {
+	// Setup
+	dir, cleanup := testdata.tempDir(t)
+	defer cleanup()
+	mirrorDir := filepath.Join(dir, "mirror")
+	keyFile := filepath.Join(dir, "mirror.key")
+
+	now := time.Date(2019, 1, 1, 12, 0, 0, 0, time.UTC)
+	first := api.Audit{EventID: uuid.New(), LoggedAt: now.Add(-2 * time.Minute)}
+	second := api.Audit{EventID: uuid.New(), LoggedAt: now.Add(-time.Minute)}
+	third := api.Audit{EventID: uuid.New(), LoggedAt: now}
+
+	sync := func(events ...api.Audit) string {
+		io := fakeui.NewIO(t)
+		cmd := AuditMirrorCommand{
+			io:        io,
+			namespace: "company",
+			dir:       mirrorDir,
+			keyFile:   keyFile,
+			now: func() time.Time {
+				return now
+			},
+			newClient: func() (secrethub.ClientInterface, error) {
+				return fakeclient.Client{
+					RepoService: &fakeclient.RepoService{
+						ListFunc: func(namespace string) ([]*api.Repo, error) {
+							return []*api.Repo{{Owner: "company", Name: "app"}}, nil
+						},
+						AuditEventIterator: &fakeclient.AuditEventIterator{Events: events},
+					},
+					OrgService: &fakeclient.OrgService{
+						GetFunc: func(name string) (*api.Org, error) {
+							return &api.Org{Name: name}, nil
+						},
+						MembersService: &fakeclient.OrgMemberService{
+							ListFunc: func(org string) ([]*api.OrgMember, error) {
+								return []*api.OrgMember{
+									{User: &api.User{Username: "dev1"}, Role: api.OrgRoleAdmin},
+								}, nil
+							},
+						},
+					},
+				}, nil
+			},
+		}
+
+		err := cmd.Run()
+		assert.OK(t, err)
+		return io.Out.String()
+	}
+
+	// Act
+	firstOut := sync(second, first)
+	secondOut := sync(third, second, first)
+
+	// Assert
+	assert.Equal(t, strings.HasPrefix(firstOut, "Mirrored 2 new events.\nRecorded the changed members of company.\nHead: 3:"), true)
+	assert.Equal(t, strings.HasPrefix(secondOut, "Mirrored 1 new event.\nHead: 4:"), true)
+
+	io := fakeui.NewIO(t)
+	verify := AuditMirrorVerifyCommand{
+		io:      io,
+		dir:     mirrorDir,
+		keyFile: keyFile,
+		head:    strings.TrimPrefix(strings.TrimSpace(secondOut), "Mirrored 1 new event.\nHead: "),
+	}
+	err := verify.Run()
+	assert.OK(t, err)
+	assert.Equal(t, strings.HasPrefix(io.Out.String(), "Verified 4 entries.\n"), true)
+}
@@ -0,0 +1,98 @@
+package secrethub
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/hashchain"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// AuditMirrorVerifyCommand verifies that the audit mirror has not been tampered with.
+type AuditMirrorVerifyCommand struct {
+	io      ui.IO
+	dir     string
+	keyFile string
+	head    string
+}
+
+// NewAuditMirrorVerifyCommand creates a new AuditMirrorVerifyCommand.
+func NewAuditMirrorVerifyCommand(io ui.IO) *AuditMirrorVerifyCommand {
+	return &AuditMirrorVerifyCommand{
+		io: io,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *AuditMirrorVerifyCommand) Register(r command.Registerer) {
+	clause := r.Command("verify", "Verify that the audit mirror has not been tampered with.")
+	clause.HelpLong("The hash chain of the mirror is checked from the first to the last entry, with the key the mirror is hashed with. " +
+		"The command fails when an entry has been changed, removed or reordered.\n\n" +
+		"Removing entries from the end of the log cannot be detected from the log itself. " +
+		"Pass a previously recorded head with --head to verify that the log still contains it.")
+	clause.Flag("dir", "The directory the mirror is stored in.").Required().StringVar(&cmd.dir)
+	clause.Flag("key-file", "The file with the key the log is hashed with.").Required().StringVar(&cmd.keyFile)
+	clause.Flag("head", "A previously recorded head (<seq>:<hash>) that the log must contain.").StringVar(&cmd.head)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run verifies the audit mirror.
+func (cmd *AuditMirrorVerifyCommand) Run() error {
+	var expected *hashchain.Head
+	if cmd.head != "" {
+		head, err := parseAuditMirrorHead(cmd.head)
+		if err != nil {
+			return err
+		}
+		expected = &head
+	}
+
+	key, err := readAuditMirrorKey(cmd.keyFile, cmd.dir, false)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	head, err := hashchain.Read(filepath.Join(cmd.dir, auditMirrorLogFile), key, func(entry hashchain.Entry) error {
+		if expected != nil && entry.Seq == expected.Seq {
+			if entry.Hash != expected.Hash {
+				return ErrAuditMirrorHeadMismatch(expected.String(), "the entry at that position has a different hash")
+			}
+			found = true
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if expected != nil && !found {
+		return ErrAuditMirrorHeadMismatch(expected.String(), "the log has been truncated to "+pluralize("entry", "entries", int(head.Seq)))
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Verified %s.\n", pluralize("entry", "entries", int(head.Seq)))
+	fmt.Fprintf(cmd.io.Output(), "Head: %s\n", head)
+	return nil
+}
+
+// parseAuditMirrorHead parses a head formatted as <seq>:<hash>.
+func parseAuditMirrorHead(value string) (hashchain.Head, error) {
+	parts := strings.SplitN(strings.TrimSpace(value), ":", 2)
+	if len(parts) != 2 || len(parts[1]) != 64 {
+		return hashchain.Head{}, ErrInvalidAuditMirrorHead(value)
+	}
+
+	seq, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil || seq == 0 {
+		return hashchain.Head{}, ErrInvalidAuditMirrorHead(value)
+	}
+
+	return hashchain.Head{
+		Seq:  seq,
+		Hash: strings.ToLower(parts[1]),
+	}, nil
+}
@@ -0,0 +1,22 @@
+package secrethub
+
+import (
+	"strings"
+)
+
+// binaryCommands are the commands that run when the CLI is invoked under another name,
+// e.g. through a symlink. Other tools expect a binary with these names.
+var binaryCommands = map[string][]string{
+	dockerCredentialHelperBinary: {"docker-credential-helper"},
+	systemdGeneratorBinary:       {"systemd", "generator"},
+}
+
+// CommandArgs returns the arguments to run the CLI with,
+// given the name it was invoked with and the arguments it was given.
+func CommandArgs(binary string, args []string) []string {
+	command, ok := binaryCommands[strings.TrimSuffix(binary, ".exe")]
+	if !ok {
+		return args
+	}
+	return append(append([]string{}, command...), args...)
+}
@@ -0,0 +1,46 @@
+package secrethub
+
+import (
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestCommandArgs(t *testing.T) {
+	cases := map[string]struct {
+		binary   string
+		args     []string
+		expected []string
+	}{
+		"secrethub": {
+			binary:   "secrethub",
+			args:     []string{"read", "namespace/repo/secret"},
+			expected: []string{"read", "namespace/repo/secret"},
+		},
+		"docker credential helper": {
+			binary:   "docker-credential-secrethub",
+			args:     []string{"get"},
+			expected: []string{"docker-credential-helper", "get"},
+		},
+		"docker credential helper on windows": {
+			binary:   "docker-credential-secrethub.exe",
+			args:     []string{"list"},
+			expected: []string{"docker-credential-helper", "list"},
+		},
+		"systemd generator": {
+			binary:   "secrethub-systemd-generator",
+			args:     []string{"/run/systemd/generator", "/run/systemd/generator.early", "/run/systemd/generator.late"},
+			expected: []string{"systemd", "generator", "/run/systemd/generator", "/run/systemd/generator.early", "/run/systemd/generator.late"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			actual := CommandArgs(tc.binary, tc.args)
+
+			// Assert
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package secrethub
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/secretcache"
+	"github.com/secrethub/secrethub-cli/internals/cli/stats"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+)
+
+const (
+	cacheDirName = "cache"
+)
+
+// SecretCache handles the configuration of and access to the local secret cache.
+type SecretCache interface {
+	Enabled() bool
+	Open() (*secretcache.Cache, error)
+	Read(path string) ([]byte, bool, error)
+	Clear() (int, error)
+
+	Register(FlagRegisterer)
+}
+
+// NewSecretCache creates a new SecretCache.
+// The lookups in the cache are recorded in the usage statistics.
+func NewSecretCache(credentialStore CredentialConfig, stats *stats.Collector) SecretCache {
+	return &secretCache{
+		credentialStore: credentialStore,
+		stats:           stats,
+	}
+}
+
+type secretCache struct {
+	credentialStore CredentialConfig
+	ttl             time.Duration
+	cache           *secretcache.Cache
+	stats           *stats.Collector
+}
+
+// Register registers the flags for configuring the cache on the provided Registerer.
+func (c *secretCache) Register(r FlagRegisterer) {
+	r.Flag("cache-ttl", "Read secrets from the local cache when they were cached less than this duration ago, e.g. 1h. "+
+		"Secrets that are not in the cache are fetched and added to it. Use `secrethub cache warm` to fill the cache in advance. "+
+		"The cache is encrypted with a key derived from your credential. Caching is turned off by default.").DurationVar(&c.ttl)
+}
+
+// Enabled returns whether secrets should be read from the cache.
+func (c *secretCache) Enabled() bool {
+	return c.ttl > 0
+}
+
+// Open returns the cache in the configuration directory, encrypted with a key derived from the credential.
+func (c *secretCache) Open() (*secretcache.Cache, error) {
+	if c.cache != nil {
+		return c.cache, nil
+	}
+
+	key, err := c.credentialStore.Import()
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := key.Export()
+	if err != nil {
+		return nil, err
+	}
+
+	c.cache, err = secretcache.New(c.dir(), secretcache.DeriveKey(credential))
+	if err != nil {
+		return nil, err
+	}
+	return c.cache, nil
+}
+
+// Read returns the cached value of the secret when it is younger than the configured TTL.
+func (c *secretCache) Read(path string) ([]byte, bool, error) {
+	cache, err := c.Open()
+	if err != nil {
+		return nil, false, err
+	}
+
+	data, ok := cache.Get(path, c.ttl)
+	c.stats.RecordCache(ok)
+	return data, ok, nil
+}
+
+// Clear removes all secrets from the cache. It does not need the credential.
+func (c *secretCache) Clear() (int, error) {
+	return secretcache.Clear(c.dir())
+}
+
+// dir returns the directory in the configuration directory the cache is stored in.
+func (c *secretCache) dir() string {
+	return filepath.Join(c.credentialStore.ConfigDir().Path(), cacheDirName)
+}
+
+// CacheCommand handles operations on the local secret cache.
+type CacheCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+	cache     SecretCache
+}
+
+// NewCacheCommand creates a new CacheCommand.
+func NewCacheCommand(io ui.IO, newClient newClientFunc, cache SecretCache) *CacheCommand {
+	return &CacheCommand{
+		io:        io,
+		newClient: newClient,
+		cache:     cache,
+	}
+}
+
+// Register registers the command and its sub-commands on the provided Registerer.
+func (cmd *CacheCommand) Register(r command.Registerer) {
+	clause := r.Command("cache", "Manage the local secret cache.")
+	clause.HelpLong("Secrets can be read from a local cache by setting the --cache-ttl flag or the SECRETHUB_CACHE_TTL environment variable. " +
+		"This avoids fetching secrets from the API when a service starts. " +
+		"Fill the cache in advance with `secrethub cache warm`, e.g. while building an image or warming up an instance.")
+	NewCacheWarmCommand(cmd.io, cmd.newClient, cmd.cache).Register(clause)
+	NewCacheClearCommand(cmd.io, cmd.cache).Register(clause)
+}
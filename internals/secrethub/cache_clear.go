@@ -0,0 +1,40 @@
+package secrethub
+
+import (
+	"fmt"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// CacheClearCommand removes all secrets from the local cache.
+type CacheClearCommand struct {
+	io    ui.IO
+	cache SecretCache
+}
+
+// NewCacheClearCommand creates a new CacheClearCommand.
+func NewCacheClearCommand(io ui.IO, cache SecretCache) *CacheClearCommand {
+	return &CacheClearCommand{
+		io:    io,
+		cache: cache,
+	}
+}
+
+// Register registers the command on the provided Registerer.
+func (cmd *CacheClearCommand) Register(r command.Registerer) {
+	clause := r.Command("clear", "Remove all secrets from the local cache.")
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run removes the cached secrets.
+func (cmd *CacheClearCommand) Run() error {
+	n, err := cmd.cache.Clear()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Removed %s from the cache.\n", pluralize("secret", "secrets", n))
+	return nil
+}
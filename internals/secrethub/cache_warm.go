@@ -0,0 +1,166 @@
+package secrethub
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// Errors
+var (
+	ErrNothingToCache = errMain.Code("nothing_to_cache").ErrorPref("no secrets found in %s")
+)
+
+// CacheWarmCommand fetches secrets and stores them in the local cache.
+type CacheWarmCommand struct {
+	io              ui.IO
+	newClient       newClientFunc
+	cache           SecretCache
+	target          string
+	osEnv           []string
+	templateVars    map[string]string
+	templateVersion string
+}
+
+// NewCacheWarmCommand creates a new CacheWarmCommand.
+func NewCacheWarmCommand(io ui.IO, newClient newClientFunc, cache SecretCache) *CacheWarmCommand {
+	return &CacheWarmCommand{
+		io:           io,
+		newClient:    newClient,
+		cache:        cache,
+		osEnv:        os.Environ(),
+		templateVars: make(map[string]string),
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *CacheWarmCommand) Register(r command.Registerer) {
+	clause := r.Command("warm", "Fetch secrets in advance and store them in the local cache.")
+	clause.HelpLong("The argument is either the path to a directory, in which case all secrets in the directory and its subdirectories are cached, " +
+		"or a file with secret references such as a secrethub.env file or a template for `secrethub inject`, in which case every secret it refers to is cached.")
+	clause.Arg("dir-or-spec", "The path to a directory or a file that refers to the secrets to cache.").Required().StringVar(&cmd.target)
+	clause.Flag("var", "Define the value for a template variable with `VAR=VALUE`, e.g. --var env=prod").Short('v').StringMapVar(&cmd.templateVars)
+	clause.Flag("template-version", "The template syntax version to be used. The options are v1, v2, v3, latest or auto to automatically detect the version.").Default("auto").StringVar(&cmd.templateVersion)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run fetches the secrets and stores them in the cache.
+func (cmd *CacheWarmCommand) Run() error {
+	paths, err := cmd.secretPaths()
+	if err != nil {
+		return err
+	}
+
+	if len(paths) == 0 {
+		return ErrNothingToCache(cmd.target)
+	}
+
+	cache, err := cmd.cache.Open()
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		secret, err := client.Secrets().Versions().GetWithData(path)
+		if err != nil {
+			return err
+		}
+
+		err = cache.Set(path, secret.Data)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Cached %s. Set --cache-ttl or SECRETHUB_CACHE_TTL to read secrets from the cache.\n", pluralize("secret", "secrets", len(paths)))
+	return nil
+}
+
+// secretPaths returns the paths of the secrets to cache. When the target is
+// an existing file, it returns the secrets the file refers to. Otherwise, the
+// target is a directory and all secrets in it are returned.
+func (cmd *CacheWarmCommand) secretPaths() ([]string, error) {
+	info, err := os.Stat(cmd.target)
+	if err == nil && !info.IsDir() {
+		return cmd.specSecretPaths()
+	}
+
+	dirPath, err := api.NewDirPath(cmd.target)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := client.Dirs().GetTree(dirPath.Value(), -1, false)
+	if err != nil {
+		return nil, err
+	}
+	excludeTrash(tree, dirPath.Value())
+
+	return secretPathsInDir(tree.RootDir, dirPath.Value()), nil
+}
+
+// specSecretPaths returns the paths of the secrets referred to in the spec file.
+func (cmd *CacheWarmCommand) specSecretPaths() ([]string, error) {
+	raw, err := ioutil.ReadFile(cmd.target)
+	if err != nil {
+		return nil, ErrReadFile(cmd.target, err)
+	}
+
+	osEnv, _ := parseKeyValueStringsToMap(cmd.osEnv)
+	varReader, err := newVariableReader(osEnv, cmd.templateVars)
+	if err != nil {
+		return nil, err
+	}
+
+	parser, err := getTemplateParser(raw, cmd.templateVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := parser.Parse(string(raw), 1, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	sr := &recordingSecretReader{}
+	_, err = template.Evaluate(newPromptMissingVariableReader(varReader, cmd.io), sr)
+	if err != nil {
+		return nil, err
+	}
+	return sr.paths, nil
+}
+
+// recordingSecretReader records the paths of the secrets that are read,
+// without actually reading them.
+type recordingSecretReader struct {
+	paths []string
+	seen  map[string]bool
+}
+
+// ReadSecret records the path and returns an empty value.
+func (sr *recordingSecretReader) ReadSecret(path string) (string, error) {
+	if sr.seen == nil {
+		sr.seen = make(map[string]bool)
+	}
+	if !sr.seen[path] {
+		sr.seen[path] = true
+		sr.paths = append(sr.paths, path)
+	}
+	return "", nil
+}
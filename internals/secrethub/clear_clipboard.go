@@ -1,11 +1,14 @@
 package secrethub
 
 import (
+	"bytes"
 	"encoding/hex"
+	"fmt"
 	"time"
 
 	"github.com/secrethub/secrethub-cli/internals/cli/clip"
 	"github.com/secrethub/secrethub-cli/internals/cli/cloneproc"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
 
 	"golang.org/x/crypto/bcrypt"
@@ -14,6 +17,12 @@ import (
 // defaultClearClipboardAfter defines the default TTL for data written to the clipboard.
 const defaultClearClipboardAfter = 45 * time.Second
 
+// Errors
+var (
+	ErrInvalidClearAfter       = errMain.Code("invalid_clear_after").ErrorPref("invalid --clear-after %s: the clipboard must be cleared after a positive duration")
+	ErrCountdownNotInteractive = errMain.Code("countdown_not_interactive").Error("cannot show a countdown when the input or output is piped. The clipboard is still cleared in the background.")
+)
+
 // ClearClipboardCommand is a command to clear the contents of the clipboard after some time passed.
 type ClearClipboardCommand struct {
 	clipper clip.Clipper
@@ -62,6 +71,10 @@ func (cmd *ClearClipboardCommand) Run() error {
 
 // WriteClipboardAutoClear writes data to the clipboard and clears it after the timeout.
 func WriteClipboardAutoClear(data []byte, timeout time.Duration, clipper clip.Clipper) error {
+	if timeout <= 0 {
+		return ErrInvalidClearAfter(timeout)
+	}
+
 	hash, err := bcrypt.GenerateFromPassword(data, bcrypt.DefaultCost)
 	if err != nil {
 		return err
@@ -78,3 +91,63 @@ func WriteClipboardAutoClear(data []byte, timeout time.Duration, clipper clip.Cl
 
 	return err
 }
+
+// waitForClipboardClear shows a countdown until the timeout passed or [ENTER] is pressed,
+// after which the clipboard is cleared when it still contains the data.
+func waitForClipboardClear(io ui.IO, data []byte, timeout time.Duration, clipper clip.Clipper) error {
+	in, out, err := io.Prompts()
+	if err != nil {
+		return ErrCountdownNotInteractive
+	}
+
+	entered := make(chan struct{})
+	go func() {
+		_, _ = ui.Readln(in)
+		close(entered)
+	}()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	deadline := time.Now().Add(timeout)
+countdown:
+	for remaining := timeout; remaining > 0; remaining = time.Until(deadline).Round(time.Second) {
+		fmt.Fprintf(out, "\r\033[KThe clipboard is cleared in %s. Press [ENTER] to clear it now.", remaining.Round(time.Second))
+		select {
+		case <-entered:
+			break countdown
+		case <-ticker.C:
+		}
+	}
+	fmt.Fprint(out, "\r\033[K")
+
+	cleared, err := clearClipboardIfUnchanged(data, clipper)
+	if err != nil {
+		return err
+	}
+
+	if cleared {
+		fmt.Fprintln(out, "The clipboard has been cleared.")
+	} else {
+		fmt.Fprintln(out, "The clipboard has not been cleared, because its contents changed.")
+	}
+	return nil
+}
+
+// clearClipboardIfUnchanged clears the clipboard when it still contains the data and returns whether it did.
+func clearClipboardIfUnchanged(data []byte, clipper clip.Clipper) (bool, error) {
+	read, err := clipper.ReadAll()
+	if err != nil {
+		return false, err
+	}
+
+	if !bytes.Equal(read, data) {
+		return false, nil
+	}
+
+	err = clipper.WriteAll(nil)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
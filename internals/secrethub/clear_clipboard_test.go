@@ -0,0 +1,64 @@
+package secrethub
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/clip/fakeclip"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestClearClipboardIfUnchanged(t *testing.T) {
+	cases := map[string]struct {
+		clipboard string
+		cleared   bool
+		expected  string
+	}{
+		"unchanged": {
+			clipboard: "secret",
+			cleared:   true,
+			expected:  "",
+		},
+		"changed": {
+			clipboard: "copied afterwards",
+			cleared:   false,
+			expected:  "copied afterwards",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			clipper := fakeclip.NewWithValue([]byte(tc.clipboard))
+
+			cleared, err := clearClipboardIfUnchanged([]byte("secret"), clipper)
+			assert.OK(t, err)
+			assert.Equal(t, cleared, tc.cleared)
+
+			actual, err := clipper.ReadAll()
+			assert.OK(t, err)
+			assert.Equal(t, string(actual), tc.expected)
+		})
+	}
+}
+
+func TestWaitForClipboardClear_Enter(t *testing.T) {
+	clipper := fakeclip.NewWithValue([]byte("secret"))
+	io := fakeui.NewIO(t)
+	io.PromptIn.Buffer = bytes.NewBufferString("\n")
+
+	err := waitForClipboardClear(io, []byte("secret"), time.Minute, clipper)
+	assert.OK(t, err)
+	assert.Equal(t, io.PromptOut.String(), "\r\033[KThe clipboard is cleared in 1m0s. Press [ENTER] to clear it now.\r\033[KThe clipboard has been cleared.\n")
+
+	actual, err := clipper.ReadAll()
+	assert.OK(t, err)
+	assert.Equal(t, string(actual), "")
+}
+
+func TestWriteClipboardAutoClear_InvalidTimeout(t *testing.T) {
+	err := WriteClipboardAutoClear([]byte("secret"), 0, fakeclip.New())
+	assert.Equal(t, err, ErrInvalidClearAfter(time.Duration(0)))
+}
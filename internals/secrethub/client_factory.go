@@ -5,6 +5,8 @@ import (
 	"net/url"
 	"strings"
 
+	"github.com/secrethub/secrethub-cli/internals/cli"
+
 	"github.com/secrethub/secrethub-go/pkg/secrethub"
 	"github.com/secrethub/secrethub-go/pkg/secrethub/configdir"
 	"github.com/secrethub/secrethub-go/pkg/secrethub/credentials"
@@ -25,18 +27,21 @@ type ClientFactory interface {
 }
 
 // NewClientFactory creates a new ClientFactory.
-func NewClientFactory(store CredentialConfig) ClientFactory {
+func NewClientFactory(store CredentialConfig, logger cli.Logger) ClientFactory {
 	return &clientFactory{
-		store: store,
+		store:  store,
+		logger: logger,
 	}
 }
 
 type clientFactory struct {
-	client           *secrethub.Client
+	client           secrethub.ClientInterface
 	ServerURL        *url.URL
 	identityProvider string
 	proxyAddress     *url.URL
+	ignoreConvention bool
 	store            CredentialConfig
+	logger           cli.Logger
 }
 
 // Register the flags for configuration on a cli application.
@@ -44,6 +49,7 @@ func (f *clientFactory) Register(r FlagRegisterer) {
 	r.Flag("api-remote", "The SecretHub API address, don't set this unless you know what you're doing.").Hidden().URLVar(&f.ServerURL)
 	r.Flag("identity-provider", "Enable native authentication with a trusted identity provider. Options are `aws` (IAM + KMS), `gcp` (IAM + KMS) and `key`. When you run the CLI on one of the platforms, you can leverage their respective identity providers to do native keyless authentication. Defaults to key, which uses the default credential sourced from a file, command-line flag, or environment variable. ").Default("key").StringVar(&f.identityProvider)
 	r.Flag("proxy-address", "Set to the address of a proxy to connect to the API through a proxy. The prepended scheme determines the proxy type (http, https and socks5 are supported). For example: `--proxy-address http://my-proxy:1234`").URLVar(&f.proxyAddress)
+	r.Flag("ignore-naming-convention", "Write secrets and create directories that do not comply with the naming convention of their namespace. Only admins of the namespace can ignore its naming convention.").BoolVar(&f.ignoreConvention)
 }
 
 // NewClient returns a new client that is configured to use the remote that
@@ -71,7 +77,7 @@ func (f *clientFactory) NewClient() (secrethub.ClientInterface, error) {
 		} else if err != nil {
 			return nil, err
 		}
-		f.client = client
+		f.client = f.withNamingConventions(client)
 	}
 	return f.client, nil
 }
@@ -85,7 +91,7 @@ func (f *clientFactory) NewClientWithCredentials(provider credentials.Provider)
 		return nil, err
 	}
 
-	return client, nil
+	return f.withNamingConventions(client), nil
 }
 
 func (f *clientFactory) NewUnauthenticatedClient() (secrethub.ClientInterface, error) {
@@ -122,3 +128,12 @@ func (f *clientFactory) baseClientOptions() []secrethub.ClientOption {
 
 	return options
 }
+
+// withNamingConventions wraps the client to enforce the naming conventions
+// of the namespaces that are written to.
+func (f *clientFactory) withNamingConventions(client secrethub.ClientInterface) secrethub.ClientInterface {
+	return conventionClient{
+		ClientInterface: client,
+		conventions:     newNamingConventions(client, f.ignoreConvention, f.logger),
+	}
+}
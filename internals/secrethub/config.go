@@ -23,5 +23,7 @@ func NewConfigCommand(io ui.IO, store CredentialConfig) *ConfigCommand {
 func (cmd *ConfigCommand) Register(r command.Registerer) {
 	clause := r.Command("config", "Manage your local configuration.")
 	NewConfigUpdatePassphraseCommand(cmd.io, cmd.credentialStore).Register(clause)
+	NewConfigProfileCommand(cmd.io, cmd.credentialStore).Register(clause)
 	NewConfigUpgradeCommand().Register(clause)
+	NewConfigValidateCommand(cmd.io, cmd.credentialStore).Register(clause)
 }
@@ -0,0 +1,37 @@
+package secrethub
+
+import (
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// ConfigProfileCommand handles the profiles in the configuration directory.
+type ConfigProfileCommand struct {
+	io              ui.IO
+	credentialStore CredentialConfig
+}
+
+// NewConfigProfileCommand creates a new ConfigProfileCommand.
+func NewConfigProfileCommand(io ui.IO, credentialStore CredentialConfig) *ConfigProfileCommand {
+	return &ConfigProfileCommand{
+		io:              io,
+		credentialStore: credentialStore,
+	}
+}
+
+// Register registers the command and its sub-commands on the provided Registerer.
+func (cmd *ConfigProfileCommand) Register(r command.Registerer) {
+	clause := r.Command("profile", "Manage profiles to switch between accounts.")
+	clause.HelpLong("A profile has its own credential and configuration, so you can switch between accounts, " +
+		"e.g. a personal account, an organization account and a break-glass account, without setting --credential or " +
+		strings.ToUpper(ApplicationName) + "_CREDENTIAL.\n" +
+		"\n" +
+		"Commands use the profile set with --profile or " + strings.ToUpper(ApplicationName) + "_PROFILE and otherwise the profile selected with `" +
+		ApplicationName + " config profile use`. The default profile uses the configuration directory itself, " +
+		"other profiles are stored in its " + profilesDirName + " directory.")
+	NewConfigProfileAddCommand(cmd.io, cmd.credentialStore).Register(clause)
+	NewConfigProfileLsCommand(cmd.io, cmd.credentialStore).Register(clause)
+	NewConfigProfileUseCommand(cmd.io, cmd.credentialStore).Register(clause)
+}
@@ -0,0 +1,66 @@
+package secrethub
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// ConfigProfileAddCommand creates a new profile.
+type ConfigProfileAddCommand struct {
+	name            profileName
+	credentialFile  string
+	io              ui.IO
+	credentialStore CredentialConfig
+}
+
+// NewConfigProfileAddCommand creates a new ConfigProfileAddCommand.
+func NewConfigProfileAddCommand(io ui.IO, credentialStore CredentialConfig) *ConfigProfileAddCommand {
+	return &ConfigProfileAddCommand{
+		io:              io,
+		credentialStore: credentialStore,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *ConfigProfileAddCommand) Register(r command.Registerer) {
+	clause := r.Command("add", "Create a new profile.")
+	clause.Arg("name", "The name of the profile, e.g. work.").Required().SetValue(&cmd.name)
+	clause.Flag("credential-file", "Copy the credential in this file to the profile, e.g. a credential created with `"+ApplicationName+" service init`.").StringVar(&cmd.credentialFile)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run creates the configuration directory of the profile.
+func (cmd *ConfigProfileAddCommand) Run() error {
+	var credential []byte
+	if cmd.credentialFile != "" {
+		var err error
+		credential, err = ioutil.ReadFile(cmd.credentialFile)
+		if err != nil {
+			return ErrCannotReadFile(cmd.credentialFile, err)
+		}
+	}
+
+	profiles := cmd.credentialStore.Profiles()
+	name := cmd.name.String()
+	err := profiles.add(name)
+	if err != nil {
+		return err
+	}
+
+	dir := profiles.dir(name)
+	if credential != nil {
+		err = dir.Credential().Write(credential)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.io.Output(), "Created profile %s with the credential in %s.\n", name, cmd.credentialFile)
+	} else {
+		fmt.Fprintf(cmd.io.Output(), "Created profile %s. Run `%s --profile %s init` to set up an account in it.\n", name, ApplicationName, name)
+	}
+	fmt.Fprintf(cmd.io.Output(), "Use it with --profile %s or make it the default with `%s config profile use %s`.\n", name, ApplicationName, name)
+	return nil
+}
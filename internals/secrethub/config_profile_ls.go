@@ -0,0 +1,67 @@
+package secrethub
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// ConfigProfileLsCommand lists the profiles.
+type ConfigProfileLsCommand struct {
+	quiet           bool
+	io              ui.IO
+	credentialStore CredentialConfig
+}
+
+// NewConfigProfileLsCommand creates a new ConfigProfileLsCommand.
+func NewConfigProfileLsCommand(io ui.IO, credentialStore CredentialConfig) *ConfigProfileLsCommand {
+	return &ConfigProfileLsCommand{
+		io:              io,
+		credentialStore: credentialStore,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *ConfigProfileLsCommand) Register(r command.Registerer) {
+	clause := r.Command("ls", "List the profiles. The profile that is used is marked with a *.")
+	clause.Alias("list")
+	clause.Flag("quiet", "Only print the names of the profiles.").Short('q').BoolVar(&cmd.quiet)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run prints the profiles.
+func (cmd *ConfigProfileLsCommand) Run() error {
+	profiles := cmd.credentialStore.Profiles()
+	names, err := profiles.list()
+	if err != nil {
+		return err
+	}
+
+	if cmd.quiet {
+		for _, name := range names {
+			fmt.Fprintln(cmd.io.Output(), name)
+		}
+		return nil
+	}
+
+	selected := cmd.credentialStore.Profile()
+	w := tabwriter.NewWriter(cmd.io.Output(), 0, 2, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join([]string{"", "NAME", "CREDENTIAL", "DIRECTORY"}, "\t"))
+	for _, name := range names {
+		mark := ""
+		if name == selected {
+			mark = "*"
+		}
+		dir := profiles.dir(name)
+		credential := "no"
+		if storedCredentialBackend(dir).Exists() {
+			credential = "yes"
+		}
+		fmt.Fprintln(w, strings.Join([]string{mark, name, credential, dir.Path()}, "\t"))
+	}
+	return w.Flush()
+}
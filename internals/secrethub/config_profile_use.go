@@ -0,0 +1,42 @@
+package secrethub
+
+import (
+	"fmt"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// ConfigProfileUseCommand selects the profile that is used by default.
+type ConfigProfileUseCommand struct {
+	name            profileName
+	io              ui.IO
+	credentialStore CredentialConfig
+}
+
+// NewConfigProfileUseCommand creates a new ConfigProfileUseCommand.
+func NewConfigProfileUseCommand(io ui.IO, credentialStore CredentialConfig) *ConfigProfileUseCommand {
+	return &ConfigProfileUseCommand{
+		io:              io,
+		credentialStore: credentialStore,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *ConfigProfileUseCommand) Register(r command.Registerer) {
+	clause := r.Command("use", "Use a profile by default. The --profile flag still takes precedence.")
+	clause.Arg("name", "The name of the profile, or "+defaultProfileName+" for the configuration directory itself.").Required().SetValue(&cmd.name)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run stores the name of the profile as the active profile.
+func (cmd *ConfigProfileUseCommand) Run() error {
+	err := cmd.credentialStore.Profiles().setActive(cmd.name.String())
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Switched to profile %s.\n", cmd.name)
+	return nil
+}
@@ -4,7 +4,7 @@ import "github.com/secrethub/secrethub-cli/internals/secrethub/command"
 
 // Errors
 var (
-	ErrConfigUpgradeDropped = errMain.Code("config_upgrade_dropped").Error("This command no longer exists. config update-passphrase can be used to change the passphrase of your credential. To upgrade old configuration files, use a CLI with a version <= v0.25")
+	ErrConfigUpgradeDropped = errMain.Code("config_upgrade_dropped").Error("This command no longer exists. credential passphrase can be used to change the passphrase of your credential. To upgrade old configuration files, use a CLI with a version <= v0.25")
 )
 
 type ConfigUpgradeCommand struct{}
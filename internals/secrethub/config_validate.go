@@ -0,0 +1,322 @@
+package secrethub
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Errors
+var (
+	ErrConfigInvalid      = errMain.Code("config_invalid").ErrorPref("found %s")
+	ErrUnknownConfigKind  = errMain.Code("unknown_config_kind").ErrorPref("cannot determine the kind of configuration in %s: set it with --kind")
+	ErrNoConfigFilesFound = errMain.Code("no_config_files").Error("no configuration files found: pass the files to validate as arguments")
+)
+
+const (
+	configKindProvision = "provision"
+	configKindLint      = "lint"
+	configKindMapping   = "mapping"
+	configKindSyncState = "sync-state"
+
+	// projectConfigFilename is the conventional name of the provision manifest of a project.
+	projectConfigFilename = "secrethub.yml"
+)
+
+var (
+	yamlErrorLinePattern    = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	yamlUnknownFieldPattern = regexp.MustCompile(`^field (\S+) not found in type`)
+)
+
+// configSchemas validate the contents of every kind of configuration file.
+// Errors of the YAML and JSON decoders are returned as they are, so that their
+// positions can be reported.
+var configSchemas = map[string]func(file string, raw []byte) error{
+	configKindProvision: func(file string, raw []byte) error {
+		manifest := &provisionManifest{}
+		err := yaml.UnmarshalStrict(raw, manifest)
+		if err != nil {
+			return err
+		}
+		return manifest.validate()
+	},
+	configKindLint: func(file string, raw []byte) error {
+		config := &lintConfig{}
+		err := yaml.UnmarshalStrict(raw, config)
+		if err != nil {
+			return err
+		}
+		_, err = newLinter(config)
+		return err
+	},
+	configKindMapping: func(file string, raw []byte) error {
+		mapping := &importMapping{}
+		err := yaml.UnmarshalStrict(raw, mapping)
+		if err != nil {
+			return err
+		}
+		for _, rule := range mapping.Rules {
+			err = rule.validate()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	configKindSyncState: func(file string, raw []byte) error {
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.DisallowUnknownFields()
+		return decoder.Decode(&syncState{})
+	},
+}
+
+// configKinds returns the names of all kinds of configuration files in alphabetical order.
+func configKinds() []string {
+	kinds := make([]string, 0, len(configSchemas))
+	for kind := range configSchemas {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// ConfigValidateCommand checks configuration files against their schemas.
+type ConfigValidateCommand struct {
+	io              ui.IO
+	credentialStore CredentialConfig
+	files           []string
+	kind            string
+}
+
+// NewConfigValidateCommand creates a new ConfigValidateCommand.
+func NewConfigValidateCommand(io ui.IO, store CredentialConfig) *ConfigValidateCommand {
+	return &ConfigValidateCommand{
+		io:              io,
+		credentialStore: store,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *ConfigValidateCommand) Register(r command.Registerer) {
+	clause := r.Command("validate", "Check configuration files for errors.")
+	clause.HelpLong("Checks configuration files against their schemas and prints every error with its line and column. " +
+		"Without arguments, the sync state files in the configuration directory " +
+		"and the " + projectConfigFilename + " provision manifest in the current directory are checked.\n" +
+		"\n" +
+		"The kind of a file is determined from its name: " + projectConfigFilename + " is a provision manifest, " +
+		"JSON files are sync state files and YAML files with lint or mapping in their name are lint configurations and import mappings. " +
+		"Use --kind to set the kind of files with another name.")
+	clause.Arg("files", "The configuration files to check.").ExistingFilesVar(&cmd.files)
+	clause.Flag("kind", "The kind of configuration in the files: "+strings.Join(configKinds(), ", ")+".").EnumVar(&cmd.kind, configKinds()...)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run checks the configuration files and prints the errors found.
+func (cmd *ConfigValidateCommand) Run() error {
+	files := cmd.files
+	if len(files) == 0 {
+		var err error
+		files, err = cmd.defaultFiles()
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return ErrNoConfigFilesFound
+		}
+	}
+
+	invalid := 0
+	for _, file := range files {
+		kind := cmd.kind
+		if kind == "" {
+			kind = detectConfigKind(file)
+			if kind == "" {
+				return ErrUnknownConfigKind(file)
+			}
+		}
+
+		raw, err := ioutil.ReadFile(file)
+		if err != nil {
+			return ErrCannotReadFile(file, err)
+		}
+
+		issues := validateConfig(file, kind, raw)
+		if len(issues) == 0 {
+			fmt.Fprintf(cmd.io.Output(), "%s: valid %s\n", file, kind)
+			continue
+		}
+
+		invalid++
+		for _, issue := range issues {
+			fmt.Fprintln(cmd.io.Output(), issue)
+		}
+	}
+
+	if invalid > 0 {
+		return ErrConfigInvalid(pluralize("invalid file", "invalid files", invalid))
+	}
+	return nil
+}
+
+// defaultFiles returns the configuration files that exist in the configuration directory and the current directory.
+func (cmd *ConfigValidateCommand) defaultFiles() ([]string, error) {
+	configDir := cmd.credentialStore.ConfigDir().Path()
+
+	var files []string
+	_, err := os.Stat(projectConfigFilename)
+	if err == nil {
+		files = append(files, projectConfigFilename)
+	}
+
+	syncStates, err := filepath.Glob(filepath.Join(configDir, syncStateDirName, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	return append(files, syncStates...), nil
+}
+
+// detectConfigKind returns the kind of configuration file from its name,
+// or an empty string when the kind cannot be determined.
+func detectConfigKind(file string) string {
+	name := strings.ToLower(filepath.Base(file))
+	ext := filepath.Ext(name)
+	switch {
+	case name == projectConfigFilename || name == "secrethub.yaml":
+		return configKindProvision
+	case ext == ".json":
+		return configKindSyncState
+	case ext != ".yml" && ext != ".yaml":
+		return ""
+	case strings.Contains(name, "lint"):
+		return configKindLint
+	case strings.Contains(name, "mapping"):
+		return configKindMapping
+	}
+	return ""
+}
+
+// configIssue is an error at a position in a configuration file.
+// The line and column are 0 when the position is unknown.
+type configIssue struct {
+	file    string
+	line    int
+	column  int
+	message string
+}
+
+// String formats the issue as file:line:column: message.
+func (i configIssue) String() string {
+	position := i.file
+	if i.line > 0 {
+		position += ":" + strconv.Itoa(i.line)
+		if i.column > 0 {
+			position += ":" + strconv.Itoa(i.column)
+		}
+	}
+	return position + ": " + i.message
+}
+
+// validateConfig checks the contents of a configuration file of the given kind.
+func validateConfig(file string, kind string, raw []byte) []configIssue {
+	err := configSchemas[kind](file, raw)
+	if err == nil {
+		return nil
+	}
+
+	lines := strings.Split(string(raw), "\n")
+
+	switch e := err.(type) {
+	case *yaml.TypeError:
+		issues := make([]configIssue, len(e.Errors))
+		for i, msg := range e.Errors {
+			issues[i] = yamlIssue(file, lines, msg)
+		}
+		return issues
+	case *json.SyntaxError:
+		// The offset is after the character that caused the error.
+		line, column := offsetPosition(raw, e.Offset-1)
+		return []configIssue{{file: file, line: line, column: column, message: e.Error()}}
+	case *json.UnmarshalTypeError:
+		line, column := offsetPosition(raw, e.Offset)
+		return []configIssue{{file: file, line: line, column: column, message: e.Error()}}
+	}
+
+	if yamlErrorLinePattern.MatchString(err.Error()) {
+		return []configIssue{yamlIssue(file, lines, err.Error())}
+	}
+
+	// The other errors are found after decoding, so their position is not known.
+	// They are reported at the value they mention.
+	line, column := locateConfigValue(lines, err.Error())
+	return []configIssue{{file: file, line: line, column: column, message: err.Error()}}
+}
+
+// yamlIssue converts an error message of the YAML decoder, e.g. "line 3: field foo not found in type",
+// into an issue. The column is the position of the unknown field or the first character on the line.
+func yamlIssue(file string, lines []string, msg string) configIssue {
+	matches := yamlErrorLinePattern.FindStringSubmatch(msg)
+	if matches == nil {
+		return configIssue{file: file, message: msg}
+	}
+
+	line, _ := strconv.Atoi(matches[1])
+	issue := configIssue{file: file, line: line, message: matches[2]}
+	if line < 1 || line > len(lines) {
+		return issue
+	}
+
+	text := lines[line-1]
+	issue.column = len(text) - len(strings.TrimLeft(text, " \t-")) + 1
+	if field := yamlUnknownFieldPattern.FindStringSubmatch(matches[2]); field != nil {
+		if i := strings.Index(text, field[1]); i >= 0 {
+			issue.column = i + 1
+		}
+		issue.message = "unknown field " + field[1]
+	}
+	return issue
+}
+
+// offsetPosition converts a byte offset into a line and column.
+func offsetPosition(raw []byte, offset int64) (int, int) {
+	if offset > int64(len(raw)) {
+		offset = int64(len(raw))
+	} else if offset < 0 {
+		offset = 0
+	}
+	before := raw[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
+
+// locateConfigValue returns the position of the longest value in the file that
+// is mentioned in the error message, or 0, 0 when none of the values is mentioned.
+func locateConfigValue(lines []string, msg string) (int, int) {
+	var line, column, length int
+	for i, text := range lines {
+		value := text
+		if colon := strings.Index(value, ": "); colon >= 0 {
+			value = value[colon+2:]
+		}
+		value = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(value), "- "))
+		value = strings.Trim(value, `"'`)
+		if value == "" || strings.HasSuffix(value, ":") || len(value) <= length || !strings.Contains(msg, value) {
+			continue
+		}
+		line, column, length = i+1, strings.Index(text, value)+1, len(value)
+	}
+	return line, column
+}
@@ -0,0 +1,102 @@
+package secrethub
+
+import (
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestValidateConfig(t *testing.T) {
+	cases := map[string]struct {
+		kind     string
+		raw      string
+		expected []configIssue
+	}{
+		"valid": {
+			kind: configKindMapping,
+			raw: "rules:\n" +
+				"  - source: My-Mail\n" +
+				"    destination: mail\n",
+			expected: nil,
+		},
+		"unknown field": {
+			kind: configKindMapping,
+			raw: "rules:\n" +
+				"  - source: My-Mail\n" +
+				"    destinaton: mail\n",
+			expected: []configIssue{
+				{file: "mapping.yml", line: 3, column: 5, message: "unknown field destinaton"},
+			},
+		},
+		"type errors": {
+			kind: configKindMapping,
+			raw: "rules:\n" +
+				"  - source: [a, b]\n" +
+				"    destination: mail\n" +
+				"  - source: Servers\n" +
+				"    skip: maybe\n",
+			expected: []configIssue{
+				{file: "mapping.yml", line: 2, column: 5, message: "cannot unmarshal !!seq into string"},
+				{file: "mapping.yml", line: 5, column: 5, message: "cannot unmarshal !!str `maybe` into bool"},
+			},
+		},
+		"syntax error": {
+			kind: configKindMapping,
+			raw: "rules:\n" +
+				"  - source: My-Mail\n" +
+				"   destination: mail\n",
+			expected: []configIssue{
+				{file: "mapping.yml", line: 2, column: 5, message: "did not find expected '-' indicator"},
+			},
+		},
+		"invalid value": {
+			kind: configKindMapping,
+			raw: "rules:\n" +
+				"  - source: My-Mail\n" +
+				"    destination: mail\n" +
+				"  - source: Servers/db\n",
+			expected: []configIssue{
+				{file: "mapping.yml", line: 4, column: 13, message: ErrInvalidMappingRule("Servers/db", "destination is required unless skip is set").Error()},
+			},
+		},
+		"json syntax error": {
+			kind: configKindSyncState,
+			raw: "{\n" +
+				"    \"secrets\": {},\n" +
+				"}\n",
+			expected: []configIssue{
+				{file: "mapping.yml", line: 3, column: 1, message: "invalid character '}' looking for beginning of object key string"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual := validateConfig("mapping.yml", tc.kind, []byte(tc.raw))
+
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
+
+func TestDetectConfigKind(t *testing.T) {
+	cases := map[string]string{
+		"secrethub.yml":                     configKindProvision,
+		"/home/user/.secrethub/sync/a.json": configKindSyncState,
+		"lint.yml":                          configKindLint,
+		"lastpass-mapping.yaml":             configKindMapping,
+		"prod.yml":                          "",
+		"secrethub.env":                     "",
+	}
+
+	for file, expected := range cases {
+		t.Run(file, func(t *testing.T) {
+			assert.Equal(t, detectConfigKind(file), expected)
+		})
+	}
+}
+
+func TestConfigIssue_String(t *testing.T) {
+	assert.Equal(t, configIssue{file: "a.yml", line: 3, column: 5, message: "unknown field x"}.String(), "a.yml:3:5: unknown field x")
+	assert.Equal(t, configIssue{file: "a.yml", message: "invalid"}.String(), "a.yml: invalid")
+}
@@ -0,0 +1,33 @@
+package secrethub
+
+import (
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// ConventionCommand handles operations on the naming conventions of namespaces.
+type ConventionCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+}
+
+// NewConventionCommand creates a new ConventionCommand.
+func NewConventionCommand(io ui.IO, newClient newClientFunc) *ConventionCommand {
+	return &ConventionCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command and its sub-commands on the provided Registerer.
+func (cmd *ConventionCommand) Register(r command.Registerer) {
+	clause := r.Command("convention", "Manage the naming conventions of namespaces.")
+	clause.HelpLong("A naming convention restricts the names of the directories and secrets in all repositories of a namespace. " +
+		"It is checked by the CLI whenever a secret is written or a directory is created. " +
+		"Admins can ignore the convention with --ignore-naming-convention.\n" +
+		"\n" +
+		"The convention is stored in the " + conventionRepoName + " repository of the namespace, " +
+		"which must be readable by everyone who writes to the namespace.")
+	NewConventionSetCommand(cmd.io, cmd.newClient).Register(clause)
+	NewConventionShowCommand(cmd.io, cmd.newClient).Register(clause)
+}
@@ -0,0 +1,72 @@
+package secrethub
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// ConventionSetCommand sets the naming convention of a namespace.
+type ConventionSetCommand struct {
+	io        ui.IO
+	namespace api.Namespace
+	pattern   string
+	maxDepth  int
+	newClient newClientFunc
+}
+
+// NewConventionSetCommand creates a new ConventionSetCommand.
+func NewConventionSetCommand(io ui.IO, newClient newClientFunc) *ConventionSetCommand {
+	return &ConventionSetCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *ConventionSetCommand) Register(r command.Registerer) {
+	clause := r.Command("set", "Set the naming convention of a namespace.")
+	clause.HelpLong("Sets the naming convention that the directories and secrets in all repositories of the namespace must comply with. " +
+		"The names of repositories are not checked. " +
+		"Existing directories and secrets are not checked either, use secrethub lint to find those that do not comply.")
+	clause.Arg("namespace", "The namespace (organization or username) to set the naming convention of.").Required().SetValue(&cmd.namespace)
+	clause.Flag("pattern", "A regular expression that the name of every directory and secret must match, e.g. '^[a-z0-9-]+$'.").Required().StringVar(&cmd.pattern)
+	clause.Flag("max-depth", "The maximum number of levels of directories and secrets below a repository. Defaults to no maximum.").PlaceHolder("0").IntVar(&cmd.maxDepth)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run validates the convention and stores it in the namespace.
+func (cmd *ConventionSetCommand) Run() error {
+	convention, err := newNamingConvention(cmd.pattern, cmd.maxDepth)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(convention)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Repos().Create(api.JoinPaths(cmd.namespace.String(), conventionRepoName))
+	if err != nil && err != api.ErrRepoAlreadyExists {
+		return err
+	}
+
+	_, err = client.Secrets().Write(conventionPath(cmd.namespace.String()), data)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Set the naming convention of %s: %s.\n", cmd.namespace, convention)
+	return nil
+}
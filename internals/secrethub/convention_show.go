@@ -0,0 +1,54 @@
+package secrethub
+
+import (
+	"fmt"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// ConventionShowCommand prints the naming convention of a namespace.
+type ConventionShowCommand struct {
+	io        ui.IO
+	namespace api.Namespace
+	newClient newClientFunc
+}
+
+// NewConventionShowCommand creates a new ConventionShowCommand.
+func NewConventionShowCommand(io ui.IO, newClient newClientFunc) *ConventionShowCommand {
+	return &ConventionShowCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *ConventionShowCommand) Register(r command.Registerer) {
+	clause := r.Command("show", "Show the naming convention of a namespace.")
+	clause.Arg("namespace", "The namespace (organization or username) to show the naming convention of.").Required().SetValue(&cmd.namespace)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run fetches and prints the naming convention.
+func (cmd *ConventionShowCommand) Run() error {
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	convention, err := getNamingConvention(client, cmd.namespace.String())
+	if err != nil {
+		return err
+	}
+
+	if convention == nil {
+		fmt.Fprintf(cmd.io.Output(), "%s has no naming convention.\n", cmd.namespace)
+		return nil
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "The naming convention of %s: %s.\n", cmd.namespace, convention)
+	return nil
+}
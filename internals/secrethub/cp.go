@@ -0,0 +1,341 @@
+package secrethub
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+)
+
+// Errors
+var (
+	ErrCannotCopyDir     = errMain.Code("cannot_copy_dir").Error("cannot copy directory. Use the -r flag to copy directories.")
+	ErrCopyIntoItself    = errMain.Code("copy_into_itself").ErrorPref("cannot copy %s into itself")
+	ErrDestinationExists = errMain.Code("destination_exists").ErrorPref("the secret %s already exists. Use --force to add the copied versions to it.")
+	ErrCopyToVersion     = errMain.Code("copy_to_version").ErrorPref("cannot copy to the secret version %s. Use a path without a version as the destination.")
+)
+
+// CpCommand copies secrets, including all their versions, to another location.
+type CpCommand struct {
+	io          ui.IO
+	source      api.Path
+	destination api.Path
+	recursive   bool
+	force       bool
+	latest      bool
+	newClient   newClientFunc
+}
+
+// NewCpCommand creates a new CpCommand.
+func NewCpCommand(io ui.IO, newClient newClientFunc) *CpCommand {
+	return &CpCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *CpCommand) Register(r command.Registerer) {
+	clause := r.Command("cp", "Copy a secret or directory, including all versions of the secrets.")
+	clause.Alias("copy")
+	clause.HelpLong("Copies a secret, a version of a secret or a directory to another location, " +
+		"which can be in another repository or namespace. All versions of a secret are copied in order, " +
+		"so that a new secret gets the same version numbers. " +
+		"When the destination is an existing directory, the source is copied into it.\n" +
+		"\n" +
+		"Copying to a secret that already exists requires --force, in which case the copied versions are added as new versions.")
+	clause.Arg("source", "The path to the secret, secret version or directory to copy.").Required().PlaceHolder(secretPathOptionalVersionPlaceHolder).SetValue(&cmd.source)
+	clause.Arg("destination", "The path to copy to.").Required().PlaceHolder(secretPathPlaceHolder).SetValue(&cmd.destination)
+	clause.Flag("recursive", "Copy directories and their contents recursively.").Short('r').BoolVar(&cmd.recursive)
+	clause.Flag("latest", "Only copy the latest version of every secret.").BoolVar(&cmd.latest)
+	registerForceFlag(clause).BoolVar(&cmd.force)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run copies the secrets and prints a summary of what was copied.
+func (cmd *CpCommand) Run() error {
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	plan, err := planCopy(client, cmd.source, cmd.destination, cmd.recursive, cmd.force)
+	if err != nil {
+		return err
+	}
+
+	transfers, err := plan.execute(client, cmd.latest)
+	if err != nil {
+		return err
+	}
+
+	err = printTransfers(cmd.io.Output(), transfers)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "\nCopied %s.\n", pluralize("secret", "secrets", len(transfers)))
+	return nil
+}
+
+// copyPlan holds the directories and secrets to create when copying.
+type copyPlan struct {
+	// sourceDir is the directory that is copied, or empty when a single secret is copied.
+	sourceDir string
+	dirs      []string
+	transfers []secretTransfer
+}
+
+// secretTransfer is a secret, or a version of a secret, that is copied to the destination.
+type secretTransfer struct {
+	source      string
+	destination string
+	versions    int
+}
+
+// planCopy resolves the source and destination of a copy to the secrets to copy.
+// It fails before anything is written when a destination secret already exists and force is not set.
+func planCopy(client secrethub.ClientInterface, source, destination api.Path, recursive, force bool) (*copyPlan, error) {
+	var plan *copyPlan
+	var err error
+	if !source.HasVersion() {
+		sourceDir, err := source.ToDirPath()
+		if err != nil {
+			return nil, err
+		}
+
+		tree, err := client.Dirs().GetTree(sourceDir.Value(), -1, false)
+		if err == nil {
+			if !recursive {
+				return nil, ErrCannotCopyDir
+			}
+			excludeTrash(tree, sourceDir.Value())
+			plan, err = planCopyDir(client, sourceDir, tree, destination)
+			if err != nil {
+				return nil, err
+			}
+		} else if !api.IsErrNotFound(err) {
+			return nil, err
+		}
+	}
+
+	if plan == nil {
+		plan, err = planCopySecret(client, source, destination)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !force {
+		err = plan.checkDestinations(client)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return plan, nil
+}
+
+// checkDestinations returns an error when one of the destination secrets already exists.
+func (p *copyPlan) checkDestinations(client secrethub.ClientInterface) error {
+	for _, transfer := range p.transfers {
+		exists, err := client.Secrets().Exists(transfer.destination)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return ErrDestinationExists(transfer.destination)
+		}
+	}
+	return nil
+}
+
+// planCopySecret plans copying a single secret or secret version.
+// When the destination is an existing directory, the secret keeps its name in that directory.
+func planCopySecret(client secrethub.ClientInterface, source, destination api.Path) (*copyPlan, error) {
+	sourceSecret, err := source.ToSecretPath()
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if the secret exists first so we can return a generic error here instead of ErrSecretNotFound.
+	_, err = client.Secrets().Get(strings.SplitN(sourceSecret.Value(), ":", 2)[0])
+	if api.IsErrNotFound(err) {
+		return nil, ErrResourceNotFound(source)
+	} else if err != nil {
+		return nil, err
+	}
+
+	if destination.HasVersion() {
+		return nil, ErrCopyToVersion(destination)
+	}
+
+	target := destination.Value()
+	isDir, err := client.Dirs().Exists(target)
+	if err != nil && !api.IsErrNotFound(err) {
+		return nil, err
+	}
+	if isDir {
+		target = api.JoinPaths(target, sourceSecret.GetSecret())
+	}
+
+	return planSecretTransfer(sourceSecret.Value(), target)
+}
+
+// planSecretTransfer plans transferring a secret to the target path, creating its parent directory when needed.
+func planSecretTransfer(source, target string) (*copyPlan, error) {
+	destinationSecret, err := api.NewSecretPath(target)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &copyPlan{
+		transfers: []secretTransfer{{
+			source:      source,
+			destination: destinationSecret.Value(),
+		}},
+	}
+
+	dir := path.Dir(destinationSecret.Value())
+	dirPath, err := api.NewDirPath(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !dirPath.IsRepoPath() {
+		plan.dirs = []string{dir}
+	}
+	return plan, nil
+}
+
+// planCopyDir plans copying a directory with all its subdirectories and secrets.
+// When the destination is an existing directory, the directory is copied into it.
+func planCopyDir(client secrethub.ClientInterface, sourceDir api.DirPath, tree *api.Tree, destination api.Path) (*copyPlan, error) {
+	destinationDir, err := destination.ToDirPath()
+	if err != nil {
+		return nil, err
+	}
+
+	target := destinationDir.Value()
+	exists, err := client.Dirs().Exists(target)
+	if err != nil && !api.IsErrNotFound(err) {
+		return nil, err
+	}
+	if exists {
+		target = api.JoinPaths(target, sourceDir.GetDirName())
+	}
+
+	source := sourceDir.Value()
+	if isSubPath(target, source) {
+		return nil, ErrCopyIntoItself(sourceDir)
+	}
+
+	return planDirTransfer(tree.RootDir, source, target)
+}
+
+// planDirTransfer plans transferring the directory at source, with all its subdirectories and secrets, to the target path.
+func planDirTransfer(dir *api.Dir, source, target string) (*copyPlan, error) {
+	plan := &copyPlan{
+		sourceDir: source,
+	}
+
+	targetDir, err := api.NewDirPath(target)
+	if err != nil {
+		return nil, err
+	}
+	plan.dirs = dirPathsInDir(dir, target)
+	if targetDir.IsRepoPath() {
+		// The root directory is created with the repository.
+		plan.dirs = plan.dirs[1:]
+	}
+
+	for _, secretPath := range secretPathsInDir(dir, source) {
+		plan.transfers = append(plan.transfers, secretTransfer{
+			source:      secretPath,
+			destination: target + strings.TrimPrefix(secretPath, source),
+		})
+	}
+
+	return plan, nil
+}
+
+// execute creates the directories and writes the versions of the secrets in order.
+// When latest is set, only the latest version of every secret is written.
+func (p *copyPlan) execute(client secrethub.ClientInterface, latest bool) ([]secretTransfer, error) {
+	for _, dir := range p.dirs {
+		err := client.Dirs().CreateAll(dir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	transfers := make([]secretTransfer, len(p.transfers))
+	for i, transfer := range p.transfers {
+		versions, err := sourceVersions(client, transfer.source, latest)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, version := range versions {
+			_, err = client.Secrets().Write(transfer.destination, version.Data)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		transfer.versions = len(versions)
+		transfers[i] = transfer
+	}
+	return transfers, nil
+}
+
+// sourceVersions returns the versions of a secret to copy, from old to new.
+// A path with a version only returns that version.
+func sourceVersions(client secrethub.ClientInterface, path string, latest bool) ([]*api.SecretVersion, error) {
+	secretPath, err := api.NewSecretPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if latest || secretPath.HasVersion() {
+		version, err := client.Secrets().Versions().GetWithData(path)
+		if err != nil {
+			return nil, err
+		}
+		return []*api.SecretVersion{version}, nil
+	}
+
+	versions, err := client.Secrets().Versions().ListWithData(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version < versions[j].Version
+	})
+	return versions, nil
+}
+
+// printTransfers writes a table with the source, destination and number of versions of every transferred secret.
+func printTransfers(w io.Writer, transfers []secretTransfer) error {
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\t%s\t%s\n", "SOURCE", "DESTINATION", "VERSIONS")
+	for _, transfer := range transfers {
+		fmt.Fprintf(tw, "%s\t%s\t%d\n", transfer.source, transfer.destination, transfer.versions)
+	}
+	return tw.Flush()
+}
+
+// isSubPath returns whether path is equal to or inside of the directory parent.
+func isSubPath(path, parent string) bool {
+	path, parent = strings.ToLower(path), strings.ToLower(parent)
+	return path == parent || strings.HasPrefix(path, parent+"/")
+}
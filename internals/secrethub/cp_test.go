@@ -0,0 +1,382 @@
+package secrethub
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+// fakeSecretStore keeps secrets with their versions, directories and their access rules in memory.
+type fakeSecretStore struct {
+	dirs    map[string]bool
+	secrets map[string][]string
+	// rules maps the paths of directories to the permissions of accounts on them.
+	rules map[string]map[string]api.Permission
+	ids   map[string]uuid.UUID
+}
+
+func newFakeSecretStore() *fakeSecretStore {
+	return &fakeSecretStore{
+		dirs: map[string]bool{
+			"company/app":     true,
+			"company/app/db":  true,
+			"company/app/api": true,
+			"company/other":   true,
+		},
+		secrets: map[string][]string{
+			"company/app/db/password": {"v1", "v2", "v3"},
+			"company/app/db/user":     {"app"},
+			"company/app/api/key":     {"k1", "k2"},
+		},
+		rules: map[string]map[string]api.Permission{},
+		ids:   map[string]uuid.UUID{},
+	}
+}
+
+// id returns the ID of the directory at the path.
+func (s *fakeSecretStore) id(path string) uuid.UUID {
+	id, ok := s.ids[path]
+	if !ok {
+		id = uuid.New()
+		s.ids[path] = id
+	}
+	return id
+}
+
+func (s *fakeSecretStore) tree(path string) *api.Dir {
+	dir := &api.Dir{Name: path[strings.LastIndex(path, "/")+1:], DirID: s.id(path)}
+	for dirPath := range s.dirs {
+		if strings.HasPrefix(dirPath, path+"/") && !strings.Contains(strings.TrimPrefix(dirPath, path+"/"), "/") {
+			dir.SubDirs = append(dir.SubDirs, s.tree(dirPath))
+		}
+	}
+	for secretPath := range s.secrets {
+		if strings.HasPrefix(secretPath, path+"/") && !strings.Contains(strings.TrimPrefix(secretPath, path+"/"), "/") {
+			dir.Secrets = append(dir.Secrets, &api.Secret{Name: strings.TrimPrefix(secretPath, path+"/")})
+		}
+	}
+	return dir
+}
+
+func (s *fakeSecretStore) client() fakeclient.Client {
+	return fakeclient.Client{
+		AccessRuleService: &fakeclient.AccessRuleService{
+			ListFunc: func(path string, depth int, ancestors bool) ([]*api.AccessRule, error) {
+				var rules []*api.AccessRule
+				for dir, permissions := range s.rules {
+					if !isSubPath(dir, path) {
+						continue
+					}
+					for account, permission := range permissions {
+						rules = append(rules, &api.AccessRule{
+							Account:    &api.Account{Name: api.AccountName(account)},
+							DirID:      s.id(dir),
+							Permission: permission,
+						})
+					}
+				}
+				return rules, nil
+			},
+			SetFunc: func(path string, permission string, accountName string) (*api.AccessRule, error) {
+				if s.rules[path] == nil {
+					s.rules[path] = map[string]api.Permission{}
+				}
+				var p api.Permission
+				err := p.Set(permission)
+				if err != nil {
+					return nil, err
+				}
+				s.rules[path][accountName] = p
+				return nil, nil
+			},
+		},
+		DirService: &fakeclient.DirService{
+			GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+				if !s.dirs[path] {
+					return nil, api.ErrDirNotFound
+				}
+				return &api.Tree{RootDir: s.tree(path)}, nil
+			},
+			ExistsFunc: func(path string) (bool, error) {
+				return s.dirs[path], nil
+			},
+			CreateAllFunc: func(path string) error {
+				for dir := path; strings.Count(dir, "/") > 1; dir = dir[:strings.LastIndex(dir, "/")] {
+					s.dirs[dir] = true
+				}
+				return nil
+			},
+			DeleteFunc: func(path string) error {
+				for dir := range s.dirs {
+					if isSubPath(dir, path) {
+						delete(s.dirs, dir)
+					}
+				}
+				for secret := range s.secrets {
+					if isSubPath(secret, path) {
+						delete(s.secrets, secret)
+					}
+				}
+				for dir := range s.rules {
+					if isSubPath(dir, path) {
+						delete(s.rules, dir)
+					}
+				}
+				return nil
+			},
+		},
+		SecretService: &fakeclient.SecretService{
+			GetFunc: func(path string) (*api.Secret, error) {
+				if _, ok := s.secrets[path]; !ok {
+					return nil, api.ErrSecretNotFound
+				}
+				return &api.Secret{}, nil
+			},
+			ExistsFunc: func(path string) (bool, error) {
+				_, ok := s.secrets[path]
+				return ok, nil
+			},
+			WriteFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+				s.secrets[path] = append(s.secrets[path], string(data))
+				return &api.SecretVersion{Version: len(s.secrets[path])}, nil
+			},
+			DeleteFunc: func(path string) error {
+				delete(s.secrets, path)
+				return nil
+			},
+			VersionService: &fakeclient.SecretVersionService{
+				GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+					parts := strings.SplitN(path, ":", 2)
+					versions := s.secrets[parts[0]]
+					version := len(versions)
+					if len(parts) == 2 {
+						version, _ = strconv.Atoi(parts[1])
+					}
+					return &api.SecretVersion{Version: version, Data: []byte(versions[version-1])}, nil
+				},
+				ListWithDataFunc: func(path string) ([]*api.SecretVersion, error) {
+					var versions []*api.SecretVersion
+					// Return the versions from new to old to check that they are written in order.
+					for i := len(s.secrets[path]); i > 0; i-- {
+						versions = append(versions, &api.SecretVersion{Version: i, Data: []byte(s.secrets[path][i-1])})
+					}
+					return versions, nil
+				},
+			},
+		},
+	}
+}
+
+func TestCpCommand_Run(t *testing.T) {
+	cases := map[string]struct {
+		cmd             CpCommand
+		existing        map[string][]string
+		expectedSecrets map[string][]string
+		expectedOut     string
+		err             error
+	}{
+		"secret with all versions": {
+			cmd: CpCommand{
+				source:      "company/app/db/password",
+				destination: "company/other/db-password",
+			},
+			expectedSecrets: map[string][]string{
+				"company/other/db-password": {"v1", "v2", "v3"},
+			},
+			expectedOut: "SOURCE                   DESTINATION                VERSIONS\n" +
+				"company/app/db/password  company/other/db-password  3\n" +
+				"\n" +
+				"Copied 1 secret.\n",
+		},
+		"secret into existing directory": {
+			cmd: CpCommand{
+				source:      "company/app/db/password",
+				destination: "company/other",
+				latest:      true,
+			},
+			expectedSecrets: map[string][]string{
+				"company/other/password": {"v3"},
+			},
+		},
+		"secret version": {
+			cmd: CpCommand{
+				source:      "company/app/db/password:2",
+				destination: "company/other/password",
+			},
+			expectedSecrets: map[string][]string{
+				"company/other/password": {"v2"},
+			},
+		},
+		"directory": {
+			cmd: CpCommand{
+				source:      "company/app/db",
+				destination: "company/other/database",
+				recursive:   true,
+			},
+			expectedSecrets: map[string][]string{
+				"company/other/database/password": {"v1", "v2", "v3"},
+				"company/other/database/user":     {"app"},
+			},
+		},
+		"directory into existing directory": {
+			cmd: CpCommand{
+				source:      "company/app",
+				destination: "company/other",
+				recursive:   true,
+			},
+			expectedSecrets: map[string][]string{
+				"company/other/app/db/password": {"v1", "v2", "v3"},
+				"company/other/app/db/user":     {"app"},
+				"company/other/app/api/key":     {"k1", "k2"},
+			},
+		},
+		"directory without recursive": {
+			cmd: CpCommand{
+				source:      "company/app/db",
+				destination: "company/other/database",
+			},
+			err: ErrCannotCopyDir,
+		},
+		"directory into itself": {
+			cmd: CpCommand{
+				source:      "company/app/db",
+				destination: "company/app/db/backup",
+				recursive:   true,
+			},
+			err: ErrCopyIntoItself("company/app/db"),
+		},
+		"destination exists": {
+			cmd: CpCommand{
+				source:      "company/app/db/password",
+				destination: "company/other/password",
+			},
+			existing: map[string][]string{
+				"company/other/password": {"old"},
+			},
+			expectedSecrets: map[string][]string{
+				"company/other/password": {"old"},
+			},
+			err: ErrDestinationExists("company/other/password"),
+		},
+		"destination exists force": {
+			cmd: CpCommand{
+				source:      "company/app/db/password",
+				destination: "company/other/password",
+				force:       true,
+			},
+			existing: map[string][]string{
+				"company/other/password": {"old"},
+			},
+			expectedSecrets: map[string][]string{
+				"company/other/password": {"old", "v1", "v2", "v3"},
+			},
+		},
+		"source not found": {
+			cmd: CpCommand{
+				source:      "company/app/db/missing",
+				destination: "company/other/missing",
+			},
+			err: ErrResourceNotFound("company/app/db/missing"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			store := newFakeSecretStore()
+			for path, versions := range tc.existing {
+				store.secrets[path] = versions
+			}
+
+			io := fakeui.NewIO(t)
+			tc.cmd.io = io
+			tc.cmd.newClient = func() (secrethub.ClientInterface, error) {
+				return store.client(), nil
+			}
+
+			err := tc.cmd.Run()
+			assert.Equal(t, err, tc.err)
+			for path, versions := range tc.expectedSecrets {
+				assert.Equal(t, store.secrets[path], versions)
+			}
+			if tc.expectedOut != "" {
+				assert.Equal(t, io.Out.String(), tc.expectedOut)
+			}
+		})
+	}
+}
+
+func TestMvCommand_Run(t *testing.T) {
+	cases := map[string]struct {
+		cmd             MvCommand
+		expectedSecrets map[string][]string
+		removed         []string
+		err             error
+	}{
+		"secret": {
+			cmd: MvCommand{
+				source:      "company/app/db/password",
+				destination: "company/other/password",
+			},
+			expectedSecrets: map[string][]string{
+				"company/other/password": {"v1", "v2", "v3"},
+			},
+			removed: []string{"company/app/db/password"},
+		},
+		"directory": {
+			cmd: MvCommand{
+				source:      "company/app/db",
+				destination: "company/other/db",
+				recursive:   true,
+			},
+			expectedSecrets: map[string][]string{
+				"company/other/db/password": {"v1", "v2", "v3"},
+				"company/other/db/user":     {"app"},
+			},
+			removed: []string{"company/app/db/password", "company/app/db/user"},
+		},
+		"version": {
+			cmd: MvCommand{
+				source:      "company/app/db/password:1",
+				destination: "company/other/password",
+			},
+			err: ErrCannotMoveVersion,
+		},
+		"root directory": {
+			cmd: MvCommand{
+				source:      "company/app",
+				destination: "company/other/app",
+				recursive:   true,
+			},
+			err: ErrCannotMoveRootDir,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			store := newFakeSecretStore()
+
+			tc.cmd.io = fakeui.NewIO(t)
+			tc.cmd.newClient = func() (secrethub.ClientInterface, error) {
+				return store.client(), nil
+			}
+
+			err := tc.cmd.Run()
+			assert.Equal(t, err, tc.err)
+			for path, versions := range tc.expectedSecrets {
+				assert.Equal(t, store.secrets[path], versions)
+			}
+			for _, path := range tc.removed {
+				_, ok := store.secrets[path]
+				assert.Equal(t, ok, false)
+			}
+		})
+	}
+}
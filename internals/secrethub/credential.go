@@ -27,4 +27,6 @@ func (cmd *CredentialCommand) Register(r command.Registerer) {
 	NewCredentialListCommand(cmd.io, cmd.clientFactory.NewClient).Register(clause)
 	NewCredentialBackupCommand(cmd.io, cmd.clientFactory.NewClient).Register(clause)
 	NewCredentialDisableCommand(cmd.io, cmd.clientFactory.NewClient).Register(clause)
+	NewCredentialPassphraseCommand(cmd.io, cmd.credentialStore).Register(clause)
+	NewCredentialStoreCommand(cmd.io, cmd.credentialStore).Register(clause)
 }
@@ -0,0 +1,130 @@
+package secrethub
+
+import (
+	"os"
+
+	libkeyring "github.com/zalando/go-keyring"
+
+	"github.com/secrethub/secrethub-go/pkg/secrethub/configdir"
+)
+
+// Errors
+var (
+	ErrKeychainNotAvailable       = errMain.Code("keychain_not_available").Error("the OS keychain is not available on this system")
+	ErrCannotReadKeychainItem     = errMain.Code("cannot_read_keychain").ErrorPref("cannot read credential from the OS keychain: %s")
+	ErrCannotWriteKeychainItem    = errMain.Code("cannot_write_keychain").ErrorPref("cannot write credential to the OS keychain: %s")
+	ErrCannotDeleteKeychainItem   = errMain.Code("cannot_delete_keychain").ErrorPref("cannot delete credential from the OS keychain: %s")
+	ErrKeychainCredentialNotFound = errMain.Code("keychain_credential_not_found").Error("no credential found in the OS keychain")
+)
+
+const (
+	credentialBackendFile     = "file"
+	credentialBackendKeychain = "keychain"
+)
+
+// credentialBackend stores the account credential of a configuration directory.
+type credentialBackend interface {
+	// Exists returns whether the backend holds a credential.
+	Exists() bool
+	Read() ([]byte, error)
+	Write(credential []byte) error
+	Delete() error
+	String() string
+}
+
+// storedCredentialBackend returns the backend that stores the credential of the configuration directory.
+// The credential file takes precedence over the OS keychain. When neither holds a credential, the file is returned.
+func storedCredentialBackend(dir configdir.Dir) credentialBackend {
+	file := fileCredentialBackend{dir: dir}
+	if file.Exists() {
+		return file
+	}
+	keychain := newKeychainCredentialBackend(dir)
+	if keychain.Exists() {
+		return keychain
+	}
+	return file
+}
+
+// fileCredentialBackend stores the credential in the credential file of the configuration directory.
+type fileCredentialBackend struct {
+	dir configdir.Dir
+}
+
+func (b fileCredentialBackend) Exists() bool {
+	return b.dir.Credential().Exists()
+}
+
+func (b fileCredentialBackend) Read() ([]byte, error) {
+	return b.dir.Credential().Read()
+}
+
+func (b fileCredentialBackend) Write(credential []byte) error {
+	return b.dir.Credential().Write(credential)
+}
+
+func (b fileCredentialBackend) Delete() error {
+	return os.Remove(b.dir.Credential().Path())
+}
+
+func (b fileCredentialBackend) String() string {
+	return b.dir.Credential().Path()
+}
+
+// keychainCredentialBackend stores the credential in the OS keychain: the macOS Keychain,
+// the Windows Credential Manager or a Secret Service provider such as GNOME Keyring or KWallet.
+// Every configuration directory has its own item in the keychain.
+type keychainCredentialBackend struct {
+	label string
+	user  string
+}
+
+// newKeychainCredentialBackend returns the keychain backend for the credential of the configuration directory.
+func newKeychainCredentialBackend(dir configdir.Dir) keychainCredentialBackend {
+	return keychainCredentialBackend{
+		label: keyringServiceLabel,
+		user:  "credential:" + dir.Path(),
+	}
+}
+
+// IsAvailable returns true when the OS keychain is available.
+func (b keychainCredentialBackend) IsAvailable() bool {
+	return keyring{label: keyringServiceLabel}.IsAvailable()
+}
+
+func (b keychainCredentialBackend) Exists() bool {
+	_, err := libkeyring.Get(b.label, b.user)
+	return err == nil
+}
+
+func (b keychainCredentialBackend) Read() ([]byte, error) {
+	credential, err := libkeyring.Get(b.label, b.user)
+	if err == libkeyring.ErrNotFound {
+		return nil, ErrKeychainCredentialNotFound
+	} else if err != nil {
+		return nil, ErrCannotReadKeychainItem(err)
+	}
+	return []byte(credential), nil
+}
+
+func (b keychainCredentialBackend) Write(credential []byte) error {
+	err := libkeyring.Set(b.label, b.user, string(credential))
+	if err != nil {
+		return ErrCannotWriteKeychainItem(err)
+	}
+	return nil
+}
+
+func (b keychainCredentialBackend) Delete() error {
+	err := libkeyring.Delete(b.label, b.user)
+	if err == libkeyring.ErrNotFound {
+		return ErrKeychainCredentialNotFound
+	} else if err != nil {
+		return ErrCannotDeleteKeychainItem(err)
+	}
+	return nil
+}
+
+func (b keychainCredentialBackend) String() string {
+	return "the OS keychain"
+}
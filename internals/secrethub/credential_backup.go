@@ -1,15 +1,29 @@
 package secrethub
 
 import (
+	"encoding/hex"
 	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
 
+	"github.com/secrethub/secrethub-cli/internals/cli/mnemonic"
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
 	"github.com/secrethub/secrethub-go/pkg/secrethub/credentials"
 )
 
+// Errors
+var (
+	ErrCannotEncodeBackupCode = errMain.Code("cannot_encode_backup_code").ErrorPref("cannot encode the backup code as recovery words: %s")
+)
+
+// mnemonicWordsPerLine is the number of recovery words printed on a line.
+const mnemonicWordsPerLine = 6
+
 // CredentialBackupCommand creates a backup code to restore a credential from a code.
 type CredentialBackupCommand struct {
+	mnemonic  bool
 	io        ui.IO
 	newClient newClientFunc
 }
@@ -25,6 +39,8 @@ func NewCredentialBackupCommand(io ui.IO, newClient newClientFunc) *CredentialBa
 // Register registers the command, arguments and flags on the provided Registerer.
 func (cmd *CredentialBackupCommand) Register(r command.Registerer) {
 	clause := r.Command("backup", "Create a backup code for restoring your account.")
+	clause.Flag("mnemonic", "Show the backup code as a list of 24 recovery words, which are easier to write down on paper. "+
+		"Restore your account from the words with `"+ApplicationName+" init --mnemonic`.").BoolVar(&cmd.mnemonic)
 
 	command.BindAction(clause, cmd.Run)
 }
@@ -66,9 +82,76 @@ func (cmd *CredentialBackupCommand) Run() error {
 		return err
 	}
 
+	if cmd.mnemonic {
+		words, err := backupCodeToMnemonic(code)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(cmd.io.Output(), "These are the recovery words of your backup code:")
+		err = printMnemonic(cmd.io.Output(), words)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.io.Output(), "Write them down in this order and store them in a safe location! "+
+			"You can restore your account by running `secrethub init --mnemonic`.")
+		return nil
+	}
+
 	fmt.Fprintf(cmd.io.Output(), "This is your backup code: \n%s\n", code)
 	fmt.Fprintln(cmd.io.Output(), "Write it down and store it in a safe location! "+
 		"You can restore your account by running `secrethub init`.")
 
 	return nil
 }
+
+// backupCodeToMnemonic encodes the hexadecimal digits of the backup code as recovery words.
+func backupCodeToMnemonic(code string) ([]string, error) {
+	secret, err := hex.DecodeString(filterHexDigits(code))
+	if err != nil {
+		return nil, ErrCannotEncodeBackupCode(err)
+	}
+	words, err := mnemonic.Encode(secret)
+	if err != nil {
+		return nil, ErrCannotEncodeBackupCode(err)
+	}
+	return words, nil
+}
+
+// mnemonicToBackupCode returns the backup code encoded by the space separated recovery words.
+func mnemonicToBackupCode(words string) (string, error) {
+	secret, err := mnemonic.Decode(strings.Fields(words))
+	if err != nil {
+		return "", err
+	}
+	return strings.ToUpper(hex.EncodeToString(secret)), nil
+}
+
+// validateMnemonic checks whether the space separated recovery words encode a backup code.
+func validateMnemonic(words string) error {
+	_, err := mnemonicToBackupCode(words)
+	return err
+}
+
+// filterHexDigits removes all characters that are not hexadecimal digits, such as the separators of a backup code.
+func filterHexDigits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// printMnemonic prints the numbered recovery words in columns.
+func printMnemonic(w io.Writer, words []string) error {
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	for start := 0; start < len(words); start += mnemonicWordsPerLine {
+		var line []string
+		for i := start; i < len(words) && i < start+mnemonicWordsPerLine; i++ {
+			line = append(line, fmt.Sprintf("%2d. %s", i+1, words[i]))
+		}
+		fmt.Fprintln(tw, strings.Join(line, "\t"))
+	}
+	return tw.Flush()
+}
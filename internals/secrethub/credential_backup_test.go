@@ -0,0 +1,44 @@
+package secrethub
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestBackupCodeMnemonic(t *testing.T) {
+	code := "68A79EAC-A2324873-EACC50CB-9C6ECA8C-C68EA5D9-36F98787-C60C7EBC-74E6CE7C"
+
+	words, err := backupCodeToMnemonic(code)
+	assert.OK(t, err)
+	assert.Equal(t, strings.Join(words, " "), "hamster diagram private dutch cause delay private meat slide toddler razor book "+
+		"happy fancy gospel tennis maple dilemma loan word shrug inflict delay length")
+
+	restored, err := mnemonicToBackupCode(strings.Join(words, " "))
+	assert.OK(t, err)
+	assert.Equal(t, restored, filterHexDigits(code))
+}
+
+func TestBackupCodeToMnemonic_InvalidCode(t *testing.T) {
+	_, err := backupCodeToMnemonic("ABC")
+
+	assert.Equal(t, err != nil, true)
+}
+
+func TestPrintMnemonic(t *testing.T) {
+	// Arrange
+	words := []string{"legal", "winner", "thank", "year", "wave", "sausage", "worth", "useful"}
+	buf := bytes.Buffer{}
+
+	// Act
+	err := printMnemonic(&buf, words)
+
+	// Assert
+	assert.OK(t, err)
+	assert.Equal(t, buf.String(), ""+
+		" 1. legal   2. winner   3. thank   4. year   5. wave   6. sausage\n"+
+		" 7. worth   8. useful\n",
+	)
+}
@@ -28,6 +28,7 @@ func NewCredentialDisableCommand(io ui.IO, newClient newClientFunc) *CredentialD
 // Register registers the command, arguments and flags on the provided Registerer.
 func (cmd *CredentialDisableCommand) Register(r command.Registerer) {
 	clause := r.Command("disable", "Disable a credential for usage on SecretHub.")
+	clause.Alias("revoke")
 
 	fingerprintHelp := fmt.Sprintf("Fingerprint of the credential to disable. At least the first %d characters must be entered.", api.ShortCredentialFingerprintMinimumLength)
 	clause.Arg("fingerprint", fingerprintHelp).StringVar(&cmd.fingerprint)
@@ -0,0 +1,149 @@
+package secrethub
+
+import (
+	"fmt"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/pkg/secrethub/credentials"
+)
+
+// Errors
+var (
+	ErrWeakPassphrase    = errMain.Code("weak_passphrase").ErrorPref("the passphrase is too weak: its estimated strength of %.0f bits is below the minimum of %d bits")
+	ErrEmptyPassphrase   = errMain.Code("empty_passphrase").Error("the passphrase is empty: use --no-passphrase to store the credential without a passphrase")
+	ErrMinStrengthTooLow = errMain.Code("min_strength_too_low").ErrorPref("--min-strength must be at least %d bits")
+)
+
+const (
+	defaultMinPassphraseStrength = 50
+	passphraseAttempts           = 3
+)
+
+// CredentialPassphraseCommand re-encrypts the local credential with a new passphrase.
+type CredentialPassphraseCommand struct {
+	minStrength     int
+	noPassphrase    bool
+	force           bool
+	io              ui.IO
+	credentialStore CredentialConfig
+}
+
+// NewCredentialPassphraseCommand creates a new CredentialPassphraseCommand.
+func NewCredentialPassphraseCommand(io ui.IO, credentialStore CredentialConfig) *CredentialPassphraseCommand {
+	return &CredentialPassphraseCommand{
+		io:              io,
+		credentialStore: credentialStore,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *CredentialPassphraseCommand) Register(r command.Registerer) {
+	clause := r.Command("passphrase", "Change the passphrase of your local credential.")
+	clause.HelpLong("Re-encrypts your local credential, in the credential file or the OS keychain, with a new passphrase. " +
+		"The credential itself and your account key stay the same, so nothing changes on SecretHub.\n" +
+		"\n" +
+		"The new passphrase must have an estimated strength of at least --min-strength bits, " +
+		"based on its length and the kinds of characters it uses. " +
+		"The minimum can be raised, but not set below " + fmt.Sprint(defaultMinPassphraseStrength) + " bits.\n" +
+		"\n" +
+		"The cached passphrase is removed from the OS keyring, as it is no longer valid.")
+	cmd.registerFlags(clause)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// registerFlags registers the flags of the command on the provided Registerer.
+func (cmd *CredentialPassphraseCommand) registerFlags(r FlagRegisterer) {
+	r.Flag("min-strength", "The minimum estimated strength of the new passphrase in bits.").Default(fmt.Sprint(defaultMinPassphraseStrength)).IntVar(&cmd.minStrength)
+	r.Flag("no-passphrase", "Store the credential without a passphrase. Only use this when the credential is protected in another way, e.g. by the OS keychain.").BoolVar(&cmd.noPassphrase)
+	registerForceFlag(r).BoolVar(&cmd.force)
+}
+
+// Run re-encrypts the credential with the new passphrase.
+func (cmd *CredentialPassphraseCommand) Run() error {
+	if cmd.minStrength < defaultMinPassphraseStrength {
+		return ErrMinStrengthTooLow(defaultMinPassphraseStrength)
+	}
+
+	backend := storedCredentialBackend(cmd.credentialStore.ConfigDir())
+	if !backend.Exists() {
+		return ErrCredentialNotExist
+	}
+
+	key, err := cmd.credentialStore.Import()
+	if err != nil {
+		return err
+	}
+
+	if cmd.noPassphrase {
+		if !cmd.force {
+			ok, err := ui.AskYesNo(cmd.io, fmt.Sprintf("This stores your credential in %s without a passphrase. Do you want to continue?", backend), ui.DefaultNo)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				fmt.Fprintln(cmd.io.Output(), "Aborting.")
+				return nil
+			}
+		}
+	} else {
+		passphrase, err := cmd.askPassphrase()
+		if err != nil {
+			return err
+		}
+		key = key.Passphrase(credentials.FromString(passphrase))
+	}
+
+	exported, err := key.Export()
+	if err != nil {
+		return err
+	}
+	err = backend.Write(exported)
+	if err != nil {
+		return err
+	}
+
+	// The cached passphrase is the old one.
+	_ = NewKeyring(cmd.credentialStore.ConfigDir).Delete()
+
+	if cmd.noPassphrase {
+		fmt.Fprintf(cmd.io.Output(), "Removed the passphrase of your credential in %s.\n", backend)
+	} else {
+		fmt.Fprintf(cmd.io.Output(), "Changed the passphrase of your credential in %s.\n", backend)
+	}
+	return nil
+}
+
+// askPassphrase asks for a new passphrase until one is given that is strong enough.
+func (cmd *CredentialPassphraseCommand) askPassphrase() (string, error) {
+	var err error
+	for i := 0; i < passphraseAttempts; i++ {
+		var passphrase string
+		passphrase, err = ui.AskPassphrase(cmd.io, "Please enter a new passphrase for your credential: ", "Enter the same passphrase again: ", 3)
+		if err != nil {
+			return "", err
+		}
+
+		err = checkPassphraseStrength(passphrase, cmd.minStrength)
+		if err == nil {
+			return passphrase, nil
+		}
+		fmt.Fprintf(cmd.io.Output(), "%s. Try again.\n", err)
+	}
+	return "", err
+}
+
+// checkPassphraseStrength returns an error when the passphrase is empty
+// or its estimated strength is below the minimum.
+func checkPassphraseStrength(passphrase string, minStrength int) error {
+	if passphrase == "" {
+		return ErrEmptyPassphrase
+	}
+	strength := estimateEntropy(passphrase)
+	if strength < float64(minStrength) {
+		return ErrWeakPassphrase(strength, minStrength)
+	}
+	return nil
+}
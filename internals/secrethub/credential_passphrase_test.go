@@ -0,0 +1,50 @@
+package secrethub
+
+import (
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestCheckPassphraseStrength(t *testing.T) {
+	cases := map[string]struct {
+		passphrase string
+		err        error
+	}{
+		"strong": {
+			passphrase: "correct-Horse-battery-staple",
+		},
+		"empty": {
+			passphrase: "",
+			err:        ErrEmptyPassphrase,
+		},
+		"short": {
+			passphrase: "secret1",
+			err:        ErrWeakPassphrase(estimateEntropy("secret1"), defaultMinPassphraseStrength),
+		},
+		"one character class": {
+			passphrase: "abcdefghij",
+			err:        ErrWeakPassphrase(estimateEntropy("abcdefghij"), defaultMinPassphraseStrength),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := checkPassphraseStrength(tc.passphrase, defaultMinPassphraseStrength)
+			assert.Equal(t, err, tc.err)
+		})
+	}
+}
+
+func TestCredentialPassphraseCommand_Run_MinStrength(t *testing.T) {
+	// Setup
+	cmd := CredentialPassphraseCommand{
+		minStrength: 0,
+	}
+
+	// Act
+	err := cmd.Run()
+
+	// Assert
+	assert.Equal(t, err, ErrMinStrengthTooLow(defaultMinPassphraseStrength))
+}
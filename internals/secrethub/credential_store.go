@@ -1,6 +1,7 @@
 package secrethub
 
 import (
+	"path/filepath"
 	"time"
 
 	"github.com/secrethub/secrethub-go/pkg/secrethub/configdir"
@@ -20,7 +21,10 @@ type CredentialConfig interface {
 	Provider() credentials.Provider
 	Import() (credentials.Key, error)
 	ConfigDir() configdir.Dir
+	Profile() string
+	Profiles() profiles
 	PassphraseReader() credentials.Reader
+	AgentSocket() string
 
 	Register(FlagRegisterer)
 }
@@ -34,14 +38,35 @@ func NewCredentialConfig(io ui.IO) CredentialConfig {
 
 type credentialConfig struct {
 	configDir                    ConfigDir
+	profile                      profileName
 	AccountCredential            string
 	credentialPassphrase         string
 	CredentialPassphraseCacheTTL time.Duration
+	agentSocket                  string
 	io                           ui.IO
 }
 
+// ConfigDir returns the configuration directory of the selected profile.
 func (store *credentialConfig) ConfigDir() configdir.Dir {
-	return store.configDir.Dir
+	return store.Profiles().dir(store.Profile())
+}
+
+// Profile returns the name of the profile selected with --profile or, when it is not set, the active profile.
+// When the active profile cannot be read, the default profile is used.
+func (store *credentialConfig) Profile() string {
+	if store.profile != "" {
+		return store.profile.String()
+	}
+	name, err := store.Profiles().active()
+	if err != nil {
+		return defaultProfileName
+	}
+	return name
+}
+
+// Profiles returns the profiles in the configuration directory set with --config-dir.
+func (store *credentialConfig) Profiles() profiles {
+	return profiles{base: store.configDir.Dir}
 }
 
 func (store *credentialConfig) IsPassphraseSet() bool {
@@ -51,16 +76,25 @@ func (store *credentialConfig) IsPassphraseSet() bool {
 // Register registers the flags for configuring the store on the provided Registerer.
 func (store *credentialConfig) Register(r FlagRegisterer) {
 	r.Flag("config-dir", "The absolute path to a custom configuration directory. Defaults to $HOME/.secrethub").Default("").PlaceHolder("CONFIG-DIR").SetValue(&store.configDir)
+	r.Flag("profile", "Use the credential and configuration of this profile, e.g. to switch between accounts. Defaults to the profile selected with `"+ApplicationName+" config profile use`.").PlaceHolder("NAME").SetValue(&store.profile)
 	r.Flag("credential", "Use a specific account credential to authenticate to the API. This overrides the credential stored in the configuration directory.").StringVar(&store.AccountCredential)
 	r.Flag("p", "").Short('p').Hidden().NoEnvar().StringVar(&store.credentialPassphrase) // Shorthand -p is deprecated. Use --credential-passphrase instead.
 	r.Flag("credential-passphrase", "The passphrase to unlock your credential file. When set, it will not prompt for the passphrase, nor cache it in the OS keyring. Please only use this if you know what you're doing and ensure your passphrase doesn't end up in bash history.").StringVar(&store.credentialPassphrase)
+	r.Flag("agent-socket", "The socket of the `"+ApplicationName+" agent` to use. Defaults to "+agentSocketName+" in the configuration directory.").PlaceHolder("PATH").StringVar(&store.agentSocket)
 	r.Flag("credential-passphrase-cache-ttl", "Cache the credential passphrase in the OS keyring for this duration. The cache is automatically cleared after the timer runs out. Each time the passphrase is read from the cache the timer is reset. Passphrase caching is turned on by default for 5 minutes. Turn it off by setting the duration to 0.").Default("5m").DurationVar(&store.CredentialPassphraseCacheTTL)
 }
 
 // Provider retrieves a credential from the store.
-// When a credential is set, that credential is returned,
-// otherwise the credential is read from the configured file.
+// When a credential is set, that credential is returned.
+// Otherwise a running agent is used or, when there is none,
+// the credential is read from the configured file.
 func (store *credentialConfig) Provider() credentials.Provider {
+	if store.AccountCredential == "" {
+		agent, err := dialAgent(store.AgentSocket())
+		if err == nil {
+			return agent
+		}
+	}
 	return credentials.UseKey(store.getCredentialReader()).Passphrase(store.PassphraseReader())
 }
 
@@ -68,14 +102,31 @@ func (store *credentialConfig) Import() (credentials.Key, error) {
 	return credentials.ImportKey(store.getCredentialReader(), store.PassphraseReader())
 }
 
+// AgentSocket returns the path of the socket the agent listens on.
+func (store *credentialConfig) AgentSocket() string {
+	if store.agentSocket != "" {
+		return store.agentSocket
+	}
+	return filepath.Join(store.ConfigDir().Path(), agentSocketName)
+}
+
+// getCredentialReader returns the reader for the credential set with a flag or, in order of preference,
+// the credential file in the configuration directory or the credential stored in the OS keychain.
 func (store *credentialConfig) getCredentialReader() credentials.Reader {
 	if store.AccountCredential != "" {
 		return credentials.FromString(store.AccountCredential)
 	}
-	return store.configDir.Credential()
+	configDir := store.ConfigDir()
+	if !configDir.Credential().Exists() {
+		keychain := newKeychainCredentialBackend(configDir)
+		if keychain.Exists() {
+			return keychain
+		}
+	}
+	return configDir.Credential()
 }
 
 // PassphraseReader returns a PassphraseReader configured by the flags.
 func (store *credentialConfig) PassphraseReader() credentials.Reader {
-	return NewPassphraseReader(store.io, store.credentialPassphrase, store.CredentialPassphraseCacheTTL)
+	return NewPassphraseReader(store.io, store.credentialPassphrase, store.CredentialPassphraseCacheTTL, store.ConfigDir)
 }
@@ -0,0 +1,101 @@
+package secrethub
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// Errors
+var (
+	ErrUnknownCredentialBackend = errMain.Code("unknown_credential_backend").ErrorPref("unknown credential backend %s: the options are file and keychain")
+	ErrCredentialStoredTwice    = errMain.Code("credential_stored_twice").ErrorPref("found a credential in both %s and %s: remove one of them first")
+	ErrCredentialNotStored      = errMain.Code("credential_not_stored").ErrorPref("the credential read back from %s does not match the original, so it was not removed from %s")
+)
+
+// CredentialStoreCommand moves the credential of the configuration directory to another backend.
+type CredentialStoreCommand struct {
+	backend         string
+	io              ui.IO
+	credentialStore CredentialConfig
+}
+
+// NewCredentialStoreCommand creates a new CredentialStoreCommand.
+func NewCredentialStoreCommand(io ui.IO, credentialStore CredentialConfig) *CredentialStoreCommand {
+	return &CredentialStoreCommand{
+		io:              io,
+		credentialStore: credentialStore,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *CredentialStoreCommand) Register(r command.Registerer) {
+	clause := r.Command("store", "Choose where your credential is stored.")
+	clause.HelpLong("Moves your credential from the credential file in the configuration directory to the OS keychain, " +
+		"or back. In the OS keychain (the macOS Keychain, the Windows Credential Manager or a Secret Service provider " +
+		"such as GNOME Keyring or KWallet), the credential is protected by your OS login instead of only by its passphrase. " +
+		"Moving the credential to the keychain removes the credential file like any other file is removed: " +
+		"it is not overwritten first, so its encrypted contents may still be recoverable from the disk.\n" +
+		"\n" +
+		"The credential file takes precedence over the keychain when both exist.")
+	clause.Flag("backend", "Where to store the credential: `keychain` or `file`.").Required().StringVar(&cmd.backend)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run moves the credential to the selected backend.
+func (cmd *CredentialStoreCommand) Run() error {
+	file := fileCredentialBackend{dir: cmd.credentialStore.ConfigDir()}
+	keychain := newKeychainCredentialBackend(cmd.credentialStore.ConfigDir())
+
+	var from, to credentialBackend
+	switch cmd.backend {
+	case credentialBackendKeychain:
+		if !keychain.IsAvailable() {
+			return ErrKeychainNotAvailable
+		}
+		from, to = file, keychain
+	case credentialBackendFile:
+		from, to = keychain, file
+	default:
+		return ErrUnknownCredentialBackend(cmd.backend)
+	}
+
+	if !from.Exists() {
+		if to.Exists() {
+			fmt.Fprintf(cmd.io.Output(), "Your credential is already stored in %s.\n", to)
+			return nil
+		}
+		return ErrCredentialNotExist
+	}
+	if to.Exists() {
+		return ErrCredentialStoredTwice(from, to)
+	}
+
+	credential, err := from.Read()
+	if err != nil {
+		return err
+	}
+	err = to.Write(credential)
+	if err != nil {
+		return err
+	}
+
+	stored, err := to.Read()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(stored, credential) {
+		return ErrCredentialNotStored(to, from)
+	}
+
+	err = from.Delete()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Moved your credential from %s to %s.\n", from, to)
+	return nil
+}
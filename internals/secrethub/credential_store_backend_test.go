@@ -0,0 +1,69 @@
+package secrethub
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/configdir"
+
+	libkeyring "github.com/zalando/go-keyring"
+)
+
+func TestCredentialStoreCommand_Run(t *testing.T) {
+	libkeyring.MockInit()
+
+	dir, err := ioutil.TempDir("", "secrethub-credential-store-test")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	store := &credentialConfig{configDir: ConfigDir{Dir: configdir.New(dir)}}
+	credential := []byte("credential")
+	err = store.ConfigDir().Credential().Write(credential)
+	assert.OK(t, err)
+	credentialPath := store.ConfigDir().Credential().Path()
+
+	// Move the credential to the keychain.
+	io := fakeui.NewIO(t)
+	cmd := NewCredentialStoreCommand(io, store)
+	cmd.backend = credentialBackendKeychain
+	err = cmd.Run()
+	assert.OK(t, err)
+	assert.Equal(t, io.Out.String(), "Moved your credential from "+credentialPath+" to the OS keychain.\n")
+	_, err = os.Stat(credentialPath)
+	assert.Equal(t, os.IsNotExist(err), true)
+
+	actual, err := store.getCredentialReader().Read()
+	assert.OK(t, err)
+	assert.Equal(t, actual, credential)
+
+	// Moving it again does nothing.
+	io = fakeui.NewIO(t)
+	cmd = NewCredentialStoreCommand(io, store)
+	cmd.backend = credentialBackendKeychain
+	err = cmd.Run()
+	assert.OK(t, err)
+	assert.Equal(t, io.Out.String(), "Your credential is already stored in the OS keychain.\n")
+
+	// Move the credential back to the file.
+	io = fakeui.NewIO(t)
+	cmd = NewCredentialStoreCommand(io, store)
+	cmd.backend = credentialBackendFile
+	err = cmd.Run()
+	assert.OK(t, err)
+	assert.Equal(t, io.Out.String(), "Moved your credential from the OS keychain to "+credentialPath+".\n")
+	assert.Equal(t, newKeychainCredentialBackend(store.ConfigDir()).Exists(), false)
+
+	actual, err = ioutil.ReadFile(credentialPath)
+	assert.OK(t, err)
+	assert.Equal(t, actual, credential)
+
+	// Unknown backends are rejected.
+	cmd = NewCredentialStoreCommand(fakeui.NewIO(t), store)
+	cmd.backend = "floppy"
+	err = cmd.Run()
+	assert.Equal(t, err, ErrUnknownCredentialBackend("floppy"))
+}
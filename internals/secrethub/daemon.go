@@ -0,0 +1,188 @@
+package secrethub
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/svcmgr"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/errio"
+)
+
+// Errors
+var (
+	errDaemon             = errio.Namespace("daemon")
+	ErrUnknownDaemonMode  = errDaemon.Code("unknown_mode").ErrorPref("unknown daemon %s: the options are %s")
+	ErrDaemonRelativePath = errDaemon.Code("relative_path").ErrorPref("the daemon does not run in the current directory, so use an absolute path for --%s instead of %s")
+)
+
+// daemonMode is a long-running secrethub command that can be installed as a service.
+type daemonMode struct {
+	description string
+	// command is the secrethub command the daemon runs.
+	command []string
+	// defaultFlags are added to the command when they are not set by the user.
+	defaultFlags map[string]string
+	// writablePaths are the paths the daemon needs to write to.
+	writablePaths []string
+	// writableFlags returns the paths the daemon needs to write to that are set with the flags of the user, if any.
+	writableFlags func(userArgs []string) []flagPath
+}
+
+// flagPath is a path that is set with a flag.
+type flagPath struct {
+	flag string
+	path string
+}
+
+// daemonModes contains the daemons that can be installed, by name.
+// A daemon is named after the command it runs.
+var daemonModes = map[string]daemonMode{
+	"agent": {
+		description: "Keeps the credential in memory for other secrethub commands.",
+		command:     []string{"agent"},
+	},
+	"ssh-sync-authorized-keys": {
+		description: "Synchronizes SSH authorized_keys and known_hosts files from SecretHub.",
+		command:     []string{"ssh", "sync-authorized-keys"},
+		defaultFlags: map[string]string{
+			"interval": "15m",
+		},
+		// The user to install the files for is only known by the sync command itself.
+		writablePaths: []string{"/home", "/root"},
+	},
+	"sync": {
+		description: "Synchronizes a SecretHub directory with an external secret store.",
+		command:     []string{"sync"},
+		defaultFlags: map[string]string{
+			"interval": "15m",
+		},
+		writableFlags: func(userArgs []string) []flagPath {
+			var paths []flagPath
+			if backend, _ := flagValue(userArgs, "backend"); backend == syncBackendLocal {
+				target, _ := flagValue(userArgs, "target")
+				paths = append(paths, flagPath{flag: "target", path: target})
+			}
+			if stateFile, ok := flagValue(userArgs, "state-file"); ok {
+				paths = append(paths, flagPath{flag: "state-file", path: filepath.Dir(stateFile)})
+			}
+			return paths
+		},
+	},
+}
+
+// daemonModeNames returns the names of all daemon modes, sorted.
+func daemonModeNames() []string {
+	names := make([]string, 0, len(daemonModes))
+	for name := range daemonModes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// getDaemonMode returns the daemon mode with the given name.
+func getDaemonMode(name string) (daemonMode, error) {
+	mode, ok := daemonModes[name]
+	if !ok {
+		return daemonMode{}, ErrUnknownDaemonMode(name, strings.Join(daemonModeNames(), ", "))
+	}
+	return mode, nil
+}
+
+// daemonServiceName returns the service name to use for a daemon mode when no name is given.
+func daemonServiceName(mode, name string) string {
+	if name != "" {
+		return name
+	}
+	return "secrethub-" + mode
+}
+
+// args returns the secrethub arguments the daemon runs with, given the arguments of the user.
+func (m daemonMode) args(userArgs []string) []string {
+	args := append(append([]string{}, m.command...), userArgs...)
+
+	flags := make([]string, 0, len(m.defaultFlags))
+	for flag := range m.defaultFlags {
+		flags = append(flags, flag)
+	}
+	sort.Strings(flags)
+
+	for _, flag := range flags {
+		if !hasFlag(userArgs, flag) {
+			args = append(args, "--"+flag+"="+m.defaultFlags[flag])
+		}
+	}
+	return args
+}
+
+// writable returns the paths the daemon needs to write to, given the arguments of the user.
+// Paths set with flags must be absolute, because the daemon does not run in the current directory.
+func (m daemonMode) writable(userArgs []string) ([]string, error) {
+	paths := append([]string{}, m.writablePaths...)
+	if m.writableFlags == nil {
+		return paths, nil
+	}
+
+	for _, p := range m.writableFlags(userArgs) {
+		if !filepath.IsAbs(p.path) {
+			return nil, ErrDaemonRelativePath(p.flag, p.path)
+		}
+		paths = append(paths, p.path)
+	}
+	return paths, nil
+}
+
+// hasFlag returns whether the flag with the given name is set in the arguments.
+func hasFlag(args []string, name string) bool {
+	_, ok := flagValue(args, name)
+	return ok
+}
+
+// flagValue returns the value of the flag with the given name in the arguments and whether it is set.
+// The value can be given as --name=value or as --name value.
+func flagValue(args []string, name string) (string, bool) {
+	for i, arg := range args {
+		if arg == "--"+name {
+			if i+1 < len(args) {
+				return args[i+1], true
+			}
+			return "", true
+		}
+		if strings.HasPrefix(arg, "--"+name+"=") {
+			return strings.TrimPrefix(arg, "--"+name+"="), true
+		}
+	}
+	return "", false
+}
+
+// DaemonCommand handles installing secrethub daemons as services.
+type DaemonCommand struct {
+	io              ui.IO
+	credentialStore CredentialConfig
+	newManager      func() (svcmgr.Manager, error)
+}
+
+// NewDaemonCommand creates a new DaemonCommand.
+func NewDaemonCommand(io ui.IO, credentialStore CredentialConfig) *DaemonCommand {
+	return &DaemonCommand{
+		io:              io,
+		credentialStore: credentialStore,
+		newManager:      svcmgr.New,
+	}
+}
+
+// Register registers the command and its sub-commands on the provided Registerer.
+func (cmd *DaemonCommand) Register(r command.Registerer) {
+	clause := r.Command("daemon", "Install long-running secrethub commands as services.")
+	clause.HelpLong("Daemons are installed with the service manager of the operating system: systemd on Linux, launchd on macOS and the Service Control Manager on Windows. " +
+		"They are started at boot and restarted when they fail. " +
+		"The available daemons are: " + strings.Join(daemonModeNames(), ", ") + ".")
+	NewDaemonInstallCommand(cmd.io, cmd.credentialStore, cmd.newManager).Register(clause)
+	NewDaemonStatusCommand(cmd.io, cmd.newManager).Register(clause)
+	NewDaemonUninstallCommand(cmd.io, cmd.newManager).Register(clause)
+	NewDaemonServiceRunCommand().Register(clause)
+}
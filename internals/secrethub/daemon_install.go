@@ -0,0 +1,117 @@
+package secrethub
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/svcmgr"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// DaemonInstallCommand installs a daemon as a service.
+type DaemonInstallCommand struct {
+	io              ui.IO
+	credentialStore CredentialConfig
+	newManager      func() (svcmgr.Manager, error)
+	executable      func() (string, error)
+	goos            string
+	mode            string
+	args            []string
+	name            string
+	runAs           string
+	force           bool
+}
+
+// NewDaemonInstallCommand creates a new DaemonInstallCommand.
+func NewDaemonInstallCommand(io ui.IO, credentialStore CredentialConfig, newManager func() (svcmgr.Manager, error)) *DaemonInstallCommand {
+	return &DaemonInstallCommand{
+		io:              io,
+		credentialStore: credentialStore,
+		newManager:      newManager,
+		executable:      os.Executable,
+		goos:            runtime.GOOS,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *DaemonInstallCommand) Register(r command.Registerer) {
+	clause := r.Command("install", "Install a daemon as a service, enable it to start at boot and start it.")
+	clause.HelpLong("The arguments after the daemon are passed to it. Put them after -- when they start with a dash, e.g.:\n\n" +
+		"    secrethub daemon install ssh-sync-authorized-keys -- company/servers/ssh --user deploy\n\n" +
+		"The daemon uses the credential in the configuration directory, so make sure the credential is not protected by a passphrase. " +
+		"Installing services usually requires root or administrator privileges.")
+	clause.Arg("daemon", "The daemon to install.").Required().HintOptions(daemonModeNames()...).StringVar(&cmd.mode)
+	clause.Arg("args", "The arguments to pass to the daemon.").StringsVar(&cmd.args)
+	clause.Flag("name", "The name of the service. Defaults to secrethub-<daemon>.").StringVar(&cmd.name)
+	clause.Flag("run-as", "The user to run the service as. Defaults to the default user of the service manager. Not supported on Windows.").StringVar(&cmd.runAs)
+	clause.Flag("force", "Replace the service if it is already installed.").Short('f').BoolVar(&cmd.force)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run installs the daemon.
+func (cmd *DaemonInstallCommand) Run() error {
+	config, err := cmd.config()
+	if err != nil {
+		return err
+	}
+
+	manager, err := cmd.newManager()
+	if err != nil {
+		return err
+	}
+
+	err = manager.Install(config, cmd.force)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Installed and started %s.\n", config.Name)
+	return nil
+}
+
+// config returns the service configuration of the daemon.
+func (cmd *DaemonInstallCommand) config() (svcmgr.Config, error) {
+	mode, err := getDaemonMode(cmd.mode)
+	if err != nil {
+		return svcmgr.Config{}, err
+	}
+
+	executable, err := cmd.executable()
+	if err != nil {
+		return svcmgr.Config{}, err
+	}
+	executable, err = filepath.Abs(executable)
+	if err != nil {
+		return svcmgr.Config{}, err
+	}
+
+	name := daemonServiceName(cmd.mode, cmd.name)
+	configDir := cmd.credentialStore.ConfigDir().Path()
+
+	writablePaths, err := mode.writable(cmd.args)
+	if err != nil {
+		return svcmgr.Config{}, err
+	}
+
+	args := mode.args(cmd.args)
+	if cmd.goos == "windows" {
+		// Windows services have to report their state to the Service Control Manager.
+		args = append([]string{"daemon", "service-run", "--name", name, "--"}, args...)
+	}
+
+	return svcmgr.Config{
+		Name:        name,
+		Description: mode.description,
+		Executable:  executable,
+		Args:        args,
+		Env: map[string]string{
+			"SECRETHUB_CONFIG_DIR": configDir,
+		},
+		User:          cmd.runAs,
+		WritablePaths: append([]string{configDir}, writablePaths...),
+	}, nil
+}
@@ -0,0 +1,43 @@
+package secrethub
+
+import (
+	"os"
+	"os/exec"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/svcmgr"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// DaemonServiceRunCommand runs a secrethub command as a Windows service.
+// It is used by the services installed with `secrethub daemon install`.
+type DaemonServiceRunCommand struct {
+	name string
+	args []string
+}
+
+// NewDaemonServiceRunCommand creates a new DaemonServiceRunCommand.
+func NewDaemonServiceRunCommand() *DaemonServiceRunCommand {
+	return &DaemonServiceRunCommand{}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *DaemonServiceRunCommand) Register(r command.Registerer) {
+	clause := r.Command("service-run", "Run a secrethub command as a service.").Hidden()
+	clause.Arg("args", "The secrethub command to run.").Required().StringsVar(&cmd.args)
+	clause.Flag("name", "The name of the service.").Required().StringVar(&cmd.name)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run runs the command until it exits or the service is stopped.
+func (cmd *DaemonServiceRunCommand) Run() error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	c := exec.Command(executable, cmd.args...)
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	return svcmgr.Run(cmd.name, c)
+}
@@ -0,0 +1,71 @@
+package secrethub
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/svcmgr"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// DaemonStatusCommand prints whether a daemon is installed and running.
+type DaemonStatusCommand struct {
+	io         ui.IO
+	newManager func() (svcmgr.Manager, error)
+	mode       string
+	name       string
+}
+
+// NewDaemonStatusCommand creates a new DaemonStatusCommand.
+func NewDaemonStatusCommand(io ui.IO, newManager func() (svcmgr.Manager, error)) *DaemonStatusCommand {
+	return &DaemonStatusCommand{
+		io:         io,
+		newManager: newManager,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *DaemonStatusCommand) Register(r command.Registerer) {
+	clause := r.Command("status", "Show whether a daemon is installed and running.")
+	clause.Arg("daemon", "The daemon to show the status of.").Required().HintOptions(daemonModeNames()...).StringVar(&cmd.mode)
+	clause.Flag("name", "The name of the service. Defaults to secrethub-<daemon>.").StringVar(&cmd.name)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run prints the status of the daemon.
+func (cmd *DaemonStatusCommand) Run() error {
+	_, err := getDaemonMode(cmd.mode)
+	if err != nil {
+		return err
+	}
+
+	manager, err := cmd.newManager()
+	if err != nil {
+		return err
+	}
+
+	name := daemonServiceName(cmd.mode, cmd.name)
+	status, err := manager.Status(name)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cmd.io.Output(), 0, 4, 4, ' ', 0)
+	fmt.Fprintf(w, "Service:\t%s\n", name)
+	fmt.Fprintf(w, "Installed:\t%s\n", yesNo(status.Installed))
+	if status.Installed {
+		fmt.Fprintf(w, "Running:\t%s\n", yesNo(status.Running))
+		fmt.Fprintf(w, "Manager:\t%s\n", status.Manager)
+		fmt.Fprintf(w, "Location:\t%s\n", status.Location)
+	}
+	return w.Flush()
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
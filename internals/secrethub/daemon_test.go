@@ -0,0 +1,101 @@
+package secrethub
+
+import (
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestDaemonModeArgs(t *testing.T) {
+	mode := daemonMode{
+		command: []string{"ssh", "sync-authorized-keys"},
+		defaultFlags: map[string]string{
+			"interval": "15m",
+		},
+	}
+
+	cases := map[string]struct {
+		args     []string
+		expected []string
+	}{
+		"default flag": {
+			args:     []string{"company/ssh"},
+			expected: []string{"ssh", "sync-authorized-keys", "company/ssh", "--interval=15m"},
+		},
+		"flag with value": {
+			args:     []string{"company/ssh", "--interval=1h"},
+			expected: []string{"ssh", "sync-authorized-keys", "company/ssh", "--interval=1h"},
+		},
+		"flag with separate value": {
+			args:     []string{"company/ssh", "--interval", "1h"},
+			expected: []string{"ssh", "sync-authorized-keys", "company/ssh", "--interval", "1h"},
+		},
+		"other flag": {
+			args:     []string{"company/ssh", "--intervals"},
+			expected: []string{"ssh", "sync-authorized-keys", "company/ssh", "--intervals", "--interval=15m"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, mode.args(tc.args), tc.expected)
+		})
+	}
+}
+
+func TestGetDaemonMode(t *testing.T) {
+	_, err := getDaemonMode("ssh-sync-authorized-keys")
+	assert.OK(t, err)
+
+	_, err = getDaemonMode("unknown")
+	assert.Equal(t, err, ErrUnknownDaemonMode("unknown", "agent, ssh-sync-authorized-keys, sync"))
+}
+
+func TestDaemonModeWritable(t *testing.T) {
+	cases := map[string]struct {
+		mode     string
+		args     []string
+		expected []string
+		err      error
+	}{
+		"fixed paths": {
+			mode:     "ssh-sync-authorized-keys",
+			args:     []string{"company/ssh"},
+			expected: []string{"/home", "/root"},
+		},
+		"no paths": {
+			mode:     "agent",
+			expected: []string{},
+		},
+		"local target": {
+			mode:     "sync",
+			args:     []string{"company/app", "--backend", "local", "--target=/srv/secrets"},
+			expected: []string{"/srv/secrets"},
+		},
+		"remote target": {
+			mode:     "sync",
+			args:     []string{"company/app", "--backend=aws-secretsmanager", "--target=app/", "--state-file=/var/lib/secrethub/state.json"},
+			expected: []string{"/var/lib/secrethub"},
+		},
+		"relative path": {
+			mode: "sync",
+			args: []string{"company/app", "--backend=local", "--target=secrets"},
+			err:  ErrDaemonRelativePath("target", "secrets"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			mode, err := getDaemonMode(tc.mode)
+			assert.OK(t, err)
+
+			// Act
+			actual, err := mode.writable(tc.args)
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
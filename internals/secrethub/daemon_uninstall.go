@@ -0,0 +1,56 @@
+package secrethub
+
+import (
+	"fmt"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/svcmgr"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// DaemonUninstallCommand stops a daemon and removes its service.
+type DaemonUninstallCommand struct {
+	io         ui.IO
+	newManager func() (svcmgr.Manager, error)
+	mode       string
+	name       string
+}
+
+// NewDaemonUninstallCommand creates a new DaemonUninstallCommand.
+func NewDaemonUninstallCommand(io ui.IO, newManager func() (svcmgr.Manager, error)) *DaemonUninstallCommand {
+	return &DaemonUninstallCommand{
+		io:         io,
+		newManager: newManager,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *DaemonUninstallCommand) Register(r command.Registerer) {
+	clause := r.Command("uninstall", "Stop a daemon and remove its service.")
+	clause.Arg("daemon", "The daemon to uninstall.").Required().HintOptions(daemonModeNames()...).StringVar(&cmd.mode)
+	clause.Flag("name", "The name of the service. Defaults to secrethub-<daemon>.").StringVar(&cmd.name)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run uninstalls the daemon.
+func (cmd *DaemonUninstallCommand) Run() error {
+	_, err := getDaemonMode(cmd.mode)
+	if err != nil {
+		return err
+	}
+
+	manager, err := cmd.newManager()
+	if err != nil {
+		return err
+	}
+
+	name := daemonServiceName(cmd.mode, cmd.name)
+	err = manager.Uninstall(name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Uninstalled %s.\n", name)
+	return nil
+}
@@ -0,0 +1,27 @@
+package secrethub
+
+import (
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// DiffCommand handles comparing secrets.
+type DiffCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+}
+
+// NewDiffCommand creates a new DiffCommand.
+func NewDiffCommand(io ui.IO, newClient newClientFunc) *DiffCommand {
+	return &DiffCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command and its sub-commands on the provided Registerer.
+func (cmd *DiffCommand) Register(r command.Registerer) {
+	clause := r.Command("diff", "Compare secrets, versions of a secret or directories.")
+	NewDiffSecretCommand(cmd.io, cmd.newClient).Register(clause)
+	NewDiffDirCommand(cmd.io, cmd.newClient).Register(clause)
+}
@@ -0,0 +1,153 @@
+package secrethub
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/errio"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+)
+
+// Errors
+var (
+	errDiff        = errio.Namespace("diff")
+	ErrDirsDiffer  = errDiff.Code("dirs_differ").ErrorPref("found %s")
+	ErrDiffSameDir = errDiff.Code("same_dir").Error("cannot compare a directory with itself")
+)
+
+const (
+	dirDiffOnlyInLeft  = "-"
+	dirDiffOnlyInRight = "+"
+	dirDiffChanged     = "~"
+)
+
+// DiffDirCommand compares the secrets in two directories.
+type DiffDirCommand struct {
+	io        ui.IO
+	left      api.DirPath
+	right     api.DirPath
+	keysOnly  bool
+	newClient newClientFunc
+}
+
+// NewDiffDirCommand creates a new DiffDirCommand.
+func NewDiffDirCommand(io ui.IO, newClient newClientFunc) *DiffDirCommand {
+	return &DiffDirCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *DiffDirCommand) Register(r command.Registerer) {
+	clause := r.Command("dir", "Compare which secrets exist in two directories and whether their values differ.")
+	clause.HelpLong("Compares the secrets in two directories, e.g. the staging and production environment of an application. " +
+		"Secrets are matched by their path relative to the compared directories. " +
+		"Every secret that differs is printed on a line starting with:\n\n" +
+		"  -  the secret only exists in the first directory.\n" +
+		"  +  the secret only exists in the second directory.\n" +
+		"  ~  the secret exists in both directories, but with a different value.\n\n" +
+		"Values are never printed. With --keys-only, only the names of the secrets are compared and the values are not read.\n\n" +
+		"The command exits with a non-zero status code when the directories differ, so it can be used in CI.")
+	clause.Arg("dir-path", "The path to the first directory.").Required().PlaceHolder(optionalDirPathPlaceHolder).SetValue(&cmd.left)
+	clause.Arg("other-dir-path", "The path to the second directory.").Required().PlaceHolder(optionalDirPathPlaceHolder).SetValue(&cmd.right)
+	clause.Flag("keys-only", "Only compare which secrets exist, not their values.").BoolVar(&cmd.keysOnly)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run compares the directories and prints the differences.
+func (cmd *DiffDirCommand) Run() error {
+	if strings.EqualFold(cmd.left.Value(), cmd.right.Value()) {
+		return ErrDiffSameDir
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	left, err := cmd.secrets(client, cmd.left)
+	if err != nil {
+		return err
+	}
+
+	right, err := cmd.secrets(client, cmd.right)
+	if err != nil {
+		return err
+	}
+
+	diffs := diffDirs(left, right)
+	if len(diffs) == 0 {
+		fmt.Fprintf(cmd.io.Output(), "%s and %s contain the same %s.\n", cmd.left, cmd.right, pluralize("secret", "secrets", len(left)))
+		return nil
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "--- %s\n+++ %s\n", cmd.left, cmd.right)
+	for _, diff := range diffs {
+		fmt.Fprintf(cmd.io.Output(), "%s %s\n", diff.change, diff.name)
+	}
+	return ErrDirsDiffer(pluralize("difference", "differences", len(diffs)))
+}
+
+// secrets returns the secrets in a directory by their path relative to the directory.
+// The values are nil when only the keys are compared.
+func (cmd *DiffDirCommand) secrets(client secrethub.ClientInterface, dirPath api.DirPath) (map[string][]byte, error) {
+	tree, err := client.Dirs().GetTree(dirPath.Value(), -1, false)
+	if err != nil {
+		return nil, err
+	}
+	excludeTrash(tree, dirPath.Value())
+
+	secrets := map[string][]byte{}
+	for _, secretPath := range secretPathsInDir(tree.RootDir, dirPath.Value()) {
+		name := strings.TrimPrefix(secretPath, dirPath.Value()+"/")
+		if cmd.keysOnly {
+			secrets[name] = nil
+			continue
+		}
+
+		secret, err := client.Secrets().Versions().GetWithData(secretPath)
+		if err != nil {
+			return nil, err
+		}
+		secrets[name] = secret.Data
+	}
+	return secrets, nil
+}
+
+// dirDiff is a secret that differs between two directories.
+type dirDiff struct {
+	change string
+	name   string
+}
+
+// diffDirs returns the secrets that only exist in one of the directories or that have
+// a different value, sorted by name.
+func diffDirs(left, right map[string][]byte) []dirDiff {
+	var diffs []dirDiff
+	for name, value := range left {
+		other, ok := right[name]
+		if !ok {
+			diffs = append(diffs, dirDiff{change: dirDiffOnlyInLeft, name: name})
+		} else if !bytes.Equal(value, other) {
+			diffs = append(diffs, dirDiff{change: dirDiffChanged, name: name})
+		}
+	}
+	for name := range right {
+		if _, ok := left[name]; !ok {
+			diffs = append(diffs, dirDiff{change: dirDiffOnlyInRight, name: name})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].name < diffs[j].name
+	})
+	return diffs
+}
@@ -0,0 +1,105 @@
+package secrethub
+
+import (
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestDiffDirs(t *testing.T) {
+	left := map[string][]byte{
+		"db/password": []byte("staging"),
+		"db/user":     []byte("app"),
+		"legacy":      []byte("token"),
+	}
+	right := map[string][]byte{
+		"db/password": []byte("prod"),
+		"db/user":     []byte("app"),
+		"db/replica":  []byte("replica"),
+	}
+
+	assert.Equal(t, diffDirs(left, right), []dirDiff{
+		{change: dirDiffChanged, name: "db/password"},
+		{change: dirDiffOnlyInRight, name: "db/replica"},
+		{change: dirDiffOnlyInLeft, name: "legacy"},
+	})
+	assert.Equal(t, diffDirs(left, left), []dirDiff(nil))
+}
+
+func TestDiffDirCommand_Run(t *testing.T) {
+	trees := map[string]*api.Tree{
+		"company/app/staging": {
+			RootDir: &api.Dir{
+				Name: "staging",
+				Secrets: []*api.Secret{
+					{Name: "password"},
+					{Name: "debug_token"},
+				},
+			},
+		},
+		"company/app/prod": {
+			RootDir: &api.Dir{
+				Name: "prod",
+				Secrets: []*api.Secret{
+					{Name: "password"},
+				},
+			},
+		},
+	}
+
+	cases := map[string]struct {
+		keysOnly bool
+		out      string
+		err      error
+	}{
+		"keys only": {
+			keysOnly: true,
+			out:      "--- company/app/staging\n+++ company/app/prod\n- debug_token\n",
+			err:      ErrDirsDiffer("1 difference"),
+		},
+		"values": {
+			out: "--- company/app/staging\n+++ company/app/prod\n- debug_token\n~ password\n",
+			err: ErrDirsDiffer("2 differences"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			io := fakeui.NewIO(t)
+			cmd := DiffDirCommand{
+				io:       io,
+				left:     "company/app/staging",
+				right:    "company/app/prod",
+				keysOnly: tc.keysOnly,
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						DirService: &fakeclient.DirService{
+							GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+								return trees[path], nil
+							},
+						},
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+									if tc.keysOnly {
+										t.Fatalf("read %s while only comparing keys", path)
+									}
+									return &api.SecretVersion{Data: []byte(path)}, nil
+								},
+							},
+						},
+					}, nil
+				},
+			}
+
+			err := cmd.Run()
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.Out.String(), tc.out)
+		})
+	}
+}
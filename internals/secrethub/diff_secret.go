@@ -0,0 +1,245 @@
+package secrethub
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// Errors
+var (
+	ErrSecretsDiffer = errDiff.Code("secrets_differ").Error("the secrets differ")
+)
+
+const (
+	defaultDiffContext = 3
+	// maxLineDiffCells limits the memory used to compute a line-based diff.
+	// Larger secrets are only reported to differ.
+	maxLineDiffCells = 16 * 1024 * 1024
+)
+
+// DiffSecretCommand compares the contents of two secrets or secret versions.
+type DiffSecretCommand struct {
+	io        ui.IO
+	left      api.SecretPath
+	right     api.SecretPath
+	brief     bool
+	context   int
+	newClient newClientFunc
+}
+
+// NewDiffSecretCommand creates a new DiffSecretCommand.
+func NewDiffSecretCommand(io ui.IO, newClient newClientFunc) *DiffSecretCommand {
+	return &DiffSecretCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *DiffSecretCommand) Register(r command.Registerer) {
+	clause := r.Command("secret", "Print a line-based diff of two secrets or versions of a secret. This is the default diff command.")
+	clause.HelpLong("Prints the differences between the contents of two secrets in the unified diff format, " +
+		"e.g. `secrethub diff company/app/config:2 company/app/config:4`. " +
+		"Note that this prints the lines of the secrets that differ. " +
+		"Use --brief to only report whether the secrets differ. Binary secrets are always compared this way.\n" +
+		"\n" +
+		"The command exits with a non-zero status code when the secrets differ.")
+	clause.Default()
+	clause.Arg("secret-path", "The path to the first secret, optionally with a version.").Required().PlaceHolder(secretPathOptionalVersionPlaceHolder).SetValue(&cmd.left)
+	clause.Arg("other-secret-path", "The path to the second secret, optionally with a version.").Required().PlaceHolder(secretPathOptionalVersionPlaceHolder).SetValue(&cmd.right)
+	clause.Flag("brief", "Only report whether the secrets differ, without printing their contents.").Short('q').BoolVar(&cmd.brief)
+	clause.Flag("context", "The number of unchanged lines to show around every change.").Short('U').Default(fmt.Sprint(defaultDiffContext)).IntVar(&cmd.context)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run compares the secrets and prints the differences.
+func (cmd *DiffSecretCommand) Run() error {
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	left, err := client.Secrets().Versions().GetWithData(cmd.left.Value())
+	if err != nil {
+		return err
+	}
+
+	right, err := client.Secrets().Versions().GetWithData(cmd.right.Value())
+	if err != nil {
+		return err
+	}
+
+	leftName := fmt.Sprintf("%s:%d", cmd.left.Value(), left.Version)
+	rightName := fmt.Sprintf("%s:%d", cmd.right.Value(), right.Version)
+	if bytes.Equal(left.Data, right.Data) {
+		fmt.Fprintf(cmd.io.Output(), "%s and %s are identical.\n", leftName, rightName)
+		return nil
+	}
+
+	leftLines, rightLines := splitLines(left.Data), splitLines(right.Data)
+	if cmd.brief || isBinary(left.Data) || isBinary(right.Data) || len(leftLines)*len(rightLines) > maxLineDiffCells {
+		fmt.Fprintf(cmd.io.Output(), "%s and %s differ.\n", leftName, rightName)
+		return ErrSecretsDiffer
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "--- %s\n+++ %s\n", leftName, rightName)
+	writeUnifiedDiff(cmd.io.Output(), diffLines(leftLines, rightLines), cmd.context)
+	return ErrSecretsDiffer
+}
+
+// isBinary returns whether the data cannot be shown as text.
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data)
+}
+
+// splitLines splits data into lines that keep their newline, so that a missing newline at the end is a difference.
+func splitLines(data []byte) []string {
+	var lines []string
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			i = len(data) - 1
+		}
+		lines = append(lines, string(data[:i+1]))
+		data = data[i+1:]
+	}
+	return lines
+}
+
+// lineDiff is a line that is equal (' '), removed ('-') or added ('+').
+type lineDiff struct {
+	op   byte
+	text string
+}
+
+// diffLines returns the shortest edit from a to b, computed from their longest common subsequence.
+func diffLines(a, b []string) []lineDiff {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	diffs := make([]lineDiff, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diffs = append(diffs, lineDiff{op: ' ', text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diffs = append(diffs, lineDiff{op: '-', text: a[i]})
+			i++
+		default:
+			diffs = append(diffs, lineDiff{op: '+', text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diffs = append(diffs, lineDiff{op: '-', text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		diffs = append(diffs, lineDiff{op: '+', text: b[j]})
+	}
+	return diffs
+}
+
+// writeUnifiedDiff writes the changes in hunks with the given number of unchanged lines around them.
+func writeUnifiedDiff(w io.Writer, diffs []lineDiff, context int) {
+	if context < 0 {
+		context = 0
+	}
+
+	for start := 0; start < len(diffs); {
+		// Find the next change.
+		first := start
+		for first < len(diffs) && diffs[first].op == ' ' {
+			first++
+		}
+		if first == len(diffs) {
+			return
+		}
+
+		// Extend the hunk while the next change is close enough to share context.
+		last := first
+		for k := first; k < len(diffs); k++ {
+			if diffs[k].op != ' ' {
+				if k-last-1 > 2*context {
+					break
+				}
+				last = k
+			}
+		}
+
+		from := first - context
+		if from < start {
+			from = start
+		}
+		to := last + context + 1
+		if to > len(diffs) {
+			to = len(diffs)
+		}
+
+		writeHunk(w, diffs, from, to)
+		start = to
+	}
+}
+
+// writeHunk writes the lines diffs[from:to] with a header containing their positions in both secrets.
+func writeHunk(w io.Writer, diffs []lineDiff, from, to int) {
+	oldStart, newStart := 1, 1
+	for _, d := range diffs[:from] {
+		if d.op != '+' {
+			oldStart++
+		}
+		if d.op != '-' {
+			newStart++
+		}
+	}
+
+	oldCount, newCount := 0, 0
+	for _, d := range diffs[from:to] {
+		if d.op != '+' {
+			oldCount++
+		}
+		if d.op != '-' {
+			newCount++
+		}
+	}
+
+	// An empty range starts at the line before it.
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+
+	fmt.Fprintf(w, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, d := range diffs[from:to] {
+		fmt.Fprintf(w, "%c%s", d.op, d.text)
+		if len(d.text) == 0 || d.text[len(d.text)-1] != '\n' {
+			fmt.Fprint(w, "\n\\ No newline at end of secret\n")
+		}
+	}
+}
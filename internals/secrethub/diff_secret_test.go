@@ -0,0 +1,126 @@
+package secrethub
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestWriteUnifiedDiff(t *testing.T) {
+	cases := map[string]struct {
+		a        string
+		b        string
+		context  int
+		expected string
+	}{
+		"changed line": {
+			a:       "host=db\nport=5432\nuser=app\n",
+			b:       "host=db\nport=6432\nuser=app\n",
+			context: 3,
+			expected: "@@ -1,3 +1,3 @@\n" +
+				" host=db\n" +
+				"-port=5432\n" +
+				"+port=6432\n" +
+				" user=app\n",
+		},
+		"separate hunks": {
+			a:       "1\n2\n3\n4\n5\n6\n7\n8\n",
+			b:       "0\n1\n2\n3\n4\n5\n6\n7\n",
+			context: 1,
+			expected: "@@ -1,1 +1,2 @@\n" +
+				"+0\n" +
+				" 1\n" +
+				"@@ -7,2 +8,1 @@\n" +
+				" 7\n" +
+				"-8\n",
+		},
+		"missing newline": {
+			a:       "a\nb",
+			b:       "a\nb\n",
+			context: 0,
+			expected: "@@ -2,1 +2,1 @@\n" +
+				"-b\n" +
+				"\\ No newline at end of secret\n" +
+				"+b\n",
+		},
+		"added to empty": {
+			a:       "",
+			b:       "a\n",
+			context: 3,
+			expected: "@@ -0,0 +1,1 @@\n" +
+				"+a\n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			writeUnifiedDiff(buf, diffLines(splitLines([]byte(tc.a)), splitLines([]byte(tc.b))), tc.context)
+
+			assert.Equal(t, buf.String(), tc.expected)
+		})
+	}
+}
+
+func TestDiffSecretCommand_Run(t *testing.T) {
+	versions := map[string]*api.SecretVersion{
+		"company/app/config:2": {Version: 2, Data: []byte("debug=false\n")},
+		"company/app/config:3": {Version: 3, Data: []byte("debug=false\n")},
+		"company/app/config:4": {Version: 4, Data: []byte("debug=true\n")},
+		"company/app/cert:1":   {Version: 1, Data: []byte{0x30, 0x82, 0x00}},
+	}
+
+	cases := map[string]struct {
+		cmd DiffSecretCommand
+		out string
+		err error
+	}{
+		"identical": {
+			cmd: DiffSecretCommand{left: "company/app/config:2", right: "company/app/config:3"},
+			out: "company/app/config:2 and company/app/config:3 are identical.\n",
+		},
+		"diff": {
+			cmd: DiffSecretCommand{left: "company/app/config:2", right: "company/app/config:4", context: 3},
+			out: "--- company/app/config:2\n+++ company/app/config:4\n@@ -1,1 +1,1 @@\n-debug=false\n+debug=true\n",
+			err: ErrSecretsDiffer,
+		},
+		"brief": {
+			cmd: DiffSecretCommand{left: "company/app/config:2", right: "company/app/config:4", brief: true},
+			out: "company/app/config:2 and company/app/config:4 differ.\n",
+			err: ErrSecretsDiffer,
+		},
+		"binary": {
+			cmd: DiffSecretCommand{left: "company/app/cert:1", right: "company/app/config:4"},
+			out: "company/app/cert:1 and company/app/config:4 differ.\n",
+			err: ErrSecretsDiffer,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			io := fakeui.NewIO(t)
+			tc.cmd.io = io
+			tc.cmd.newClient = func() (secrethub.ClientInterface, error) {
+				return fakeclient.Client{
+					SecretService: &fakeclient.SecretService{
+						VersionService: &fakeclient.SecretVersionService{
+							GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+								return versions[path], nil
+							},
+						},
+					},
+				}, nil
+			}
+
+			err := tc.cmd.Run()
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.Out.String(), tc.out)
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package secrethub
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/errio"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+)
+
+// Errors
+var (
+	errDockerCredential = errio.Namespace("docker_credential")
+	// ErrDockerCredentialsNotFound has the exact message Docker expects from a credential helper
+	// when it has no credentials for a registry.
+	ErrDockerCredentialsNotFound     = errDockerCredential.Code("not_found").Error("credentials not found in native keychain")
+	ErrMissingDockerServerURL        = errDockerCredential.Code("missing_server_url").Error("no server URL has been provided")
+	ErrMissingDockerUsername         = errDockerCredential.Code("missing_username").Error("no username has been provided")
+	ErrInvalidDockerCredentials      = errDockerCredential.Code("invalid_credentials").ErrorPref("cannot parse the credentials: %s")
+	ErrInvalidDockerCredentialSecret = errDockerCredential.Code("invalid_secret").ErrorPref("the secret %s does not contain Docker credentials: %s")
+)
+
+const (
+	// dockerCredentialHelperBinary is the name Docker looks for when the credential helper
+	// "secrethub" is configured.
+	dockerCredentialHelperBinary = "docker-credential-secrethub"
+
+	dockerCredentialGet   = "get"
+	dockerCredentialStore = "store"
+	dockerCredentialErase = "erase"
+	dockerCredentialList  = "list"
+)
+
+// DockerCredentialHelperCommand implements the Docker credential helper protocol,
+// storing the registry credentials as secrets in a directory.
+type DockerCredentialHelperCommand struct {
+	io        ui.IO
+	action    string
+	path      api.DirPath
+	newClient newClientFunc
+}
+
+// NewDockerCredentialHelperCommand creates a new DockerCredentialHelperCommand.
+func NewDockerCredentialHelperCommand(io ui.IO, newClient newClientFunc) *DockerCredentialHelperCommand {
+	return &DockerCredentialHelperCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *DockerCredentialHelperCommand) Register(r command.Registerer) {
+	clause := r.Command("docker-credential-helper", "Store and read Docker registry credentials in SecretHub.")
+	clause.HelpLong("This command is meant to be invoked by Docker. To use it, create a symlink named " + dockerCredentialHelperBinary + " " +
+		"to the secrethub binary somewhere on your PATH, set the directory to store the credentials in with the " +
+		"SECRETHUB_DOCKER_CREDENTIAL_HELPER_PATH environment variable and configure the helper in ~/.docker/config.json:\n\n" +
+		"  {\n" +
+		"    \"credsStore\": \"secrethub\"\n" +
+		"  }\n\n" +
+		"Use \"credHelpers\" instead to only use it for some registries. " +
+		"The credentials of every registry are stored as a JSON secret in the directory, named after the address of the registry. " +
+		"The docker login and docker logout commands store and erase them.")
+	clause.Arg("action", "The action Docker requests: get, store, erase or list.").Required().HintOptions(dockerCredentialGet, dockerCredentialStore, dockerCredentialErase, dockerCredentialList).EnumVar(&cmd.action, dockerCredentialGet, dockerCredentialStore, dockerCredentialErase, dockerCredentialList)
+	clause.Flag("path", "The directory to store the credentials in.").Required().PlaceHolder(dirPathPlaceHolder).SetValue(&cmd.path)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// dockerCredentials are the credentials of a registry, as exchanged with Docker.
+// See https://github.com/docker/docker-credential-helpers
+type dockerCredentials struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+// Run performs the requested action with the input provided by Docker.
+func (cmd *DockerCredentialHelperCommand) Run() error {
+	input, err := ioutil.ReadAll(cmd.io.Input())
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	switch cmd.action {
+	case dockerCredentialGet:
+		credentials, err := getDockerCredentials(client, cmd.path, string(input))
+		if err == ErrDockerCredentialsNotFound {
+			// Docker reads the message from the output, not the exit code.
+			fmt.Fprintln(cmd.io.Output(), err)
+			return err
+		} else if err != nil {
+			return err
+		}
+		return json.NewEncoder(cmd.io.Output()).Encode(credentials)
+	case dockerCredentialStore:
+		return storeDockerCredentials(client, cmd.path, input)
+	case dockerCredentialErase:
+		return eraseDockerCredentials(client, cmd.path, string(input))
+	case dockerCredentialList:
+		registries, err := listDockerCredentials(client, cmd.path)
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(cmd.io.Output()).Encode(registries)
+	}
+	return nil
+}
+
+var dockerSecretNameReplacer = regexp.MustCompile(`[^a-zA-Z0-9\-_.]+`)
+
+// dockerCredentialSecretPath returns the path of the secret with the credentials of a registry.
+// The scheme of the server URL is ignored, so https://registry.example.com/ and registry.example.com
+// share their credentials.
+func dockerCredentialSecretPath(dir api.DirPath, serverURL string) (string, error) {
+	name := strings.TrimSpace(serverURL)
+	name = strings.TrimPrefix(name, "https://")
+	name = strings.TrimPrefix(name, "http://")
+	name = strings.Trim(name, "/")
+	if name == "" {
+		return "", ErrMissingDockerServerURL
+	}
+	return api.JoinPaths(dir.Value(), dockerSecretNameReplacer.ReplaceAllString(name, "_")), nil
+}
+
+// getDockerCredentials returns the credentials of the registry.
+func getDockerCredentials(client secrethub.ClientInterface, dir api.DirPath, serverURL string) (*dockerCredentials, error) {
+	path, err := dockerCredentialSecretPath(dir, serverURL)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := client.Secrets().Versions().GetWithData(path)
+	if api.IsErrNotFound(err) {
+		return nil, ErrDockerCredentialsNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	credentials := &dockerCredentials{}
+	err = json.Unmarshal(secret.Data, credentials)
+	if err != nil {
+		return nil, ErrInvalidDockerCredentialSecret(path, err)
+	}
+	return credentials, nil
+}
+
+// storeDockerCredentials writes the JSON encoded credentials to the secret of their registry.
+func storeDockerCredentials(client secrethub.ClientInterface, dir api.DirPath, input []byte) error {
+	credentials := dockerCredentials{}
+	err := json.NewDecoder(bytes.NewReader(input)).Decode(&credentials)
+	if err == io.EOF {
+		return ErrMissingDockerServerURL
+	} else if err != nil {
+		return ErrInvalidDockerCredentials(err)
+	}
+
+	if credentials.Username == "" {
+		return ErrMissingDockerUsername
+	}
+
+	path, err := dockerCredentialSecretPath(dir, credentials.ServerURL)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Secrets().Write(path, data)
+	return err
+}
+
+// eraseDockerCredentials deletes the credentials of the registry.
+func eraseDockerCredentials(client secrethub.ClientInterface, dir api.DirPath, serverURL string) error {
+	path, err := dockerCredentialSecretPath(dir, serverURL)
+	if err != nil {
+		return err
+	}
+
+	err = client.Secrets().Delete(path)
+	if api.IsErrNotFound(err) {
+		return ErrDockerCredentialsNotFound
+	}
+	return err
+}
+
+// listDockerCredentials returns the usernames of all stored registries by their server URL.
+func listDockerCredentials(client secrethub.ClientInterface, dir api.DirPath) (map[string]string, error) {
+	registries := map[string]string{}
+
+	tree, err := client.Dirs().GetTree(dir.Value(), 1, false)
+	if api.IsErrNotFound(err) {
+		return registries, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, secret := range tree.RootDir.Secrets {
+		path := api.JoinPaths(dir.Value(), secret.Name)
+		version, err := client.Secrets().Versions().GetWithData(path)
+		if err != nil {
+			return nil, err
+		}
+
+		credentials := dockerCredentials{}
+		err = json.Unmarshal(version.Data, &credentials)
+		if err != nil {
+			return nil, ErrInvalidDockerCredentialSecret(path, err)
+		}
+		registries[credentials.ServerURL] = credentials.Username
+	}
+	return registries, nil
+}
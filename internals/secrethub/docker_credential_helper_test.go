@@ -0,0 +1,163 @@
+package secrethub
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestDockerCredentialSecretPath(t *testing.T) {
+	cases := map[string]struct {
+		serverURL string
+		expected  string
+		err       error
+	}{
+		"docker hub": {
+			serverURL: "https://index.docker.io/v1/",
+			expected:  "namespace/repo/docker/index.docker.io_v1",
+		},
+		"host with port": {
+			serverURL: "registry.example.com:5000\n",
+			expected:  "namespace/repo/docker/registry.example.com_5000",
+		},
+		"empty": {
+			serverURL: "\n",
+			err:       ErrMissingDockerServerURL,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			actual, err := dockerCredentialSecretPath("namespace/repo/docker", tc.serverURL)
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
+
+func TestDockerCredentialHelperCommand_Run(t *testing.T) {
+	stored := `{"ServerURL":"https://index.docker.io/v1/","Username":"dev1","Secret":"p4ssw0rd"}`
+
+	cases := map[string]struct {
+		action   string
+		in       string
+		secrets  map[string]string
+		expected map[string]string
+		out      string
+		err      error
+	}{
+		"get": {
+			action: "get",
+			in:     "https://index.docker.io/v1/\n",
+			secrets: map[string]string{
+				"namespace/repo/docker/index.docker.io_v1": stored,
+			},
+			out: stored + "\n",
+		},
+		"get not found": {
+			action:  "get",
+			in:      "registry.example.com",
+			secrets: map[string]string{},
+			out:     "credentials not found in native keychain\n",
+			err:     ErrDockerCredentialsNotFound,
+		},
+		"store": {
+			action:  "store",
+			in:      `{"ServerURL":"https://index.docker.io/v1/","Username":"dev1","Secret":"p4ssw0rd"}`,
+			secrets: map[string]string{},
+			expected: map[string]string{
+				"namespace/repo/docker/index.docker.io_v1": stored,
+			},
+		},
+		"store without username": {
+			action:   "store",
+			in:       `{"ServerURL":"https://index.docker.io/v1/","Secret":"p4ssw0rd"}`,
+			secrets:  map[string]string{},
+			expected: map[string]string{},
+			err:      ErrMissingDockerUsername,
+		},
+		"erase": {
+			action: "erase",
+			in:     "https://index.docker.io/v1/",
+			secrets: map[string]string{
+				"namespace/repo/docker/index.docker.io_v1": stored,
+			},
+			expected: map[string]string{},
+		},
+		"list": {
+			action: "list",
+			secrets: map[string]string{
+				"namespace/repo/docker/index.docker.io_v1": stored,
+			},
+			out: `{"https://index.docker.io/v1/":"dev1"}` + "\n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			io := fakeui.NewIO(t)
+			io.In.Buffer = bytes.NewBufferString(tc.in)
+
+			cmd := DockerCredentialHelperCommand{
+				io:     io,
+				action: tc.action,
+				path:   "namespace/repo/docker",
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						DirService: &fakeclient.DirService{
+							GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+								dir := &api.Dir{Name: "docker"}
+								for secretPath := range tc.secrets {
+									dir.Secrets = append(dir.Secrets, &api.Secret{Name: secretPath[len(path)+1:]})
+								}
+								return &api.Tree{RootDir: dir}, nil
+							},
+						},
+						SecretService: &fakeclient.SecretService{
+							WriteFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+								tc.secrets[path] = string(data)
+								return &api.SecretVersion{}, nil
+							},
+							DeleteFunc: func(path string) error {
+								if _, ok := tc.secrets[path]; !ok {
+									return api.ErrSecretNotFound
+								}
+								delete(tc.secrets, path)
+								return nil
+							},
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+									data, ok := tc.secrets[path]
+									if !ok {
+										return nil, api.ErrSecretNotFound
+									}
+									return &api.SecretVersion{Data: []byte(data)}, nil
+								},
+							},
+						},
+					}, nil
+				},
+			}
+
+			// Act
+			err := cmd.Run()
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.Out.String(), tc.out)
+			if tc.expected != nil {
+				assert.Equal(t, tc.secrets, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package secrethub
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/errio"
+)
+
+// Errors
+var (
+	errEdit            = errio.Namespace("edit")
+	ErrEditorFailed    = errEdit.Code("editor_failed").ErrorPref("the editor exited with an error: %s")
+	ErrNoEditor        = errEdit.Code("no_editor").ErrorPref("cannot find the editor %s. Set the $EDITOR environment variable to the editor you want to use.")
+	ErrCannotCreateTmp = errEdit.Code("cannot_create_tmp").ErrorPref("cannot create a temporary file to edit the secret in: %s")
+)
+
+const (
+	// tmpfsDir is a directory that is kept in memory on most Linux systems,
+	// so that secrets that are written to it never touch the disk.
+	tmpfsDir = "/dev/shm"
+)
+
+// EditCommand opens the latest version of a secret in an editor and writes the result as a new version.
+type EditCommand struct {
+	io         ui.IO
+	path       api.SecretPath
+	tempDir    string
+	openEditor func(file string) error
+	newClient  newClientFunc
+}
+
+// NewEditCommand creates a new EditCommand.
+func NewEditCommand(io ui.IO, newClient newClientFunc) *EditCommand {
+	cmd := &EditCommand{
+		io:        io,
+		tempDir:   secureTempDir(),
+		newClient: newClient,
+	}
+	cmd.openEditor = cmd.runEditor
+	return cmd
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *EditCommand) Register(r command.Registerer) {
+	clause := r.Command("edit", "Edit a secret in your editor.")
+	clause.HelpLong("Opens the latest version of a secret in the editor set with the $VISUAL or $EDITOR environment variable. " +
+		"When you save the file and close the editor, the contents are written as a new version of the secret. " +
+		"Nothing is written when the contents did not change or when the file is empty.\n" +
+		"\n" +
+		"The secret is stored in a temporary file that can only be read by you while it is being edited. " +
+		"When available, the file is stored in memory (" + tmpfsDir + ") instead of on disk. The file is removed afterwards.")
+	clause.Arg("secret-path", "The path to the secret").Required().PlaceHolder(secretPathPlaceHolder).SetValue(&cmd.path)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run edits the secret and writes a new version when it changed.
+func (cmd *EditCommand) Run() error {
+	if cmd.path.HasVersion() {
+		return errCannotWriteToVersion
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	secret, err := client.Secrets().Versions().GetWithData(cmd.path.Value())
+	if err != nil {
+		return err
+	}
+
+	dir, err := ioutil.TempDir(cmd.tempDir, "secrethub-edit-")
+	if err != nil {
+		return ErrCannotCreateTmp(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The file gets the name of the secret, so the editor can recognize the file type by its extension.
+	file := filepath.Join(dir, cmd.path.GetSecret())
+	err = ioutil.WriteFile(file, secret.Data, 0600)
+	if err != nil {
+		return ErrCannotCreateTmp(err)
+	}
+	defer overwriteFile(file)
+
+	err = cmd.openEditor(file)
+	if err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return ErrCannotReadFile(file, err)
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		fmt.Fprintln(cmd.io.Output(), "Edit cancelled, the secret is empty.")
+		return nil
+	}
+
+	if bytes.Equal(data, secret.Data) {
+		fmt.Fprintf(cmd.io.Output(), "No changes made to %s:%d.\n", cmd.path, secret.Version)
+		return nil
+	}
+
+	version, err := client.Secrets().Write(cmd.path.Value(), data)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Edit complete! The new value has been written to %s:%d\n", cmd.path, version.Version)
+	return nil
+}
+
+// runEditor opens the file in the editor of the user and waits until it is closed.
+func (cmd *EditCommand) runEditor(file string) error {
+	args := strings.Fields(editor())
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return ErrNoEditor(args[0])
+	}
+
+	c := exec.Command(path, append(args[1:], file)...)
+	c.Stdin = cmd.io.Stdin()
+	c.Stdout = cmd.io.Stdout()
+	c.Stderr = os.Stderr
+
+	err = c.Run()
+	if err != nil {
+		return ErrEditorFailed(err)
+	}
+	return nil
+}
+
+// editor returns the editor command set by the user, which can include arguments, e.g. `code --wait`.
+func editor() string {
+	for _, envVar := range []string{"VISUAL", "EDITOR"} {
+		if value := strings.TrimSpace(os.Getenv(envVar)); value != "" {
+			return value
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
+
+// secureTempDir returns the in-memory tmpfs directory when it exists, and the default temporary directory otherwise.
+func secureTempDir() string {
+	info, err := os.Stat(tmpfsDir)
+	if err == nil && info.IsDir() {
+		return tmpfsDir
+	}
+	return ""
+}
+
+// overwriteFile overwrites the contents of a file with zeros, so they cannot be recovered
+// from the disk after the file is removed. Errors are ignored, as this is a best effort.
+func overwriteFile(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	_ = ioutil.WriteFile(path, make([]byte, info.Size()), 0600)
+}
@@ -0,0 +1,100 @@
+package secrethub
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestEditCommand_Run(t *testing.T) {
+	cases := map[string]struct {
+		path        api.SecretPath
+		edited      string
+		expectedOut string
+		written     string
+		err         error
+	}{
+		"changed": {
+			path:        "company/app/config.yml",
+			edited:      "debug: true\n",
+			expectedOut: "Edit complete! The new value has been written to company/app/config.yml:4\n",
+			written:     "debug: true\n",
+		},
+		"unchanged": {
+			path:        "company/app/config.yml",
+			edited:      "debug: false\n",
+			expectedOut: "No changes made to company/app/config.yml:3.\n",
+		},
+		"empty": {
+			path:        "company/app/config.yml",
+			edited:      "\n",
+			expectedOut: "Edit cancelled, the secret is empty.\n",
+		},
+		"version": {
+			path: "company/app/config.yml:2",
+			err:  errCannotWriteToVersion,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tempDir, err := ioutil.TempDir("", "secrethub-edit-test")
+			assert.OK(t, err)
+			defer os.RemoveAll(tempDir)
+
+			var editedFile string
+			var written string
+			io := fakeui.NewIO(t)
+			cmd := EditCommand{
+				io:      io,
+				path:    tc.path,
+				tempDir: tempDir,
+				openEditor: func(file string) error {
+					editedFile = file
+
+					info, err := os.Stat(file)
+					assert.OK(t, err)
+					assert.Equal(t, info.Mode().Perm(), os.FileMode(0600))
+
+					data, err := ioutil.ReadFile(file)
+					assert.OK(t, err)
+					assert.Equal(t, string(data), "debug: false\n")
+
+					return ioutil.WriteFile(file, []byte(tc.edited), 0600)
+				},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+									return &api.SecretVersion{Version: 3, Data: []byte("debug: false\n")}, nil
+								},
+							},
+							WriteFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+								written = string(data)
+								return &api.SecretVersion{Version: 4}, nil
+							},
+						},
+					}, nil
+				},
+			}
+
+			err = cmd.Run()
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.Out.String(), tc.expectedOut)
+			assert.Equal(t, written, tc.written)
+
+			if editedFile != "" {
+				_, err = os.Stat(editedFile)
+				assert.Equal(t, os.IsNotExist(err), true)
+			}
+		})
+	}
+}
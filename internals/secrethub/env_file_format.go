@@ -0,0 +1,133 @@
+package secrethub
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/secrethub/tpl"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Errors
+var (
+	ErrEnvFileNotObject = errRun.Code("env_file_not_object").Error("the file must contain an object of environment variables")
+	ErrEnvFileArray     = errRun.Code("env_file_array").ErrorPref("the value of %s is an array, which cannot be used as an environment variable")
+)
+
+// defaultEnvFileSeparator joins the keys of nested objects in an env file, e.g. db: {user: app} becomes db_user=app.
+const defaultEnvFileSeparator = "_"
+
+// envFileFormat returns the structured format of an env file based on its extension.
+// An empty string is returned for env files in the NAME=value format.
+func envFileFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return documentFormatJSON
+	case ".yaml", ".yml":
+		return documentFormatYAML
+	default:
+		return ""
+	}
+}
+
+// readStructuredEnvFile reads an env file in the JSON or YAML format.
+// The keys of nested objects are joined with the separator.
+func readStructuredEnvFile(path string, raw []byte, format string, separator string, varReader tpl.VariableReader, parser tpl.Parser) (EnvFile, error) {
+	vars, err := parseStructuredEnvironment(raw, format, separator)
+	if err != nil {
+		return EnvFile{}, ErrParsingTemplate(path, err)
+	}
+
+	env, err := newEnvTemplate(path, vars, varReader, parser)
+	if err != nil {
+		return EnvFile{}, ErrParsingTemplate(path, err)
+	}
+
+	return EnvFile{
+		path:      path,
+		envSource: env,
+	}, nil
+}
+
+// parseStructuredEnvironment parses the environment variables of a JSON or YAML document,
+// sorted by name. Nested objects are flattened by joining their keys with the separator.
+func parseStructuredEnvironment(raw []byte, format string, separator string) ([]envvar, error) {
+	var document interface{}
+	var err error
+	switch format {
+	case documentFormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		// Numbers are kept as they are written, instead of being converted to floats.
+		decoder.UseNumber()
+		err = decoder.Decode(&document)
+	case documentFormatYAML:
+		err = yaml.Unmarshal(raw, &document)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]string{}
+	switch document.(type) {
+	case map[string]interface{}, map[interface{}]interface{}:
+		err = flattenEnvironment(values, "", separator, document)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrEnvFileNotObject
+	}
+
+	vars := make([]envvar, 0, len(values))
+	for key, value := range values {
+		vars = append(vars, envvar{
+			key:        key,
+			value:      value,
+			lineNumber: -1,
+		})
+	}
+	sort.Slice(vars, func(i, j int) bool {
+		return vars[i].key < vars[j].key
+	})
+	return vars, nil
+}
+
+// flattenEnvironment adds the value to the environment variables. When the value is an object,
+// its fields are added with their keys appended to the name, joined by the separator.
+func flattenEnvironment(values map[string]string, name string, separator string, value interface{}) error {
+	join := func(key interface{}) string {
+		if name == "" {
+			return fmt.Sprint(key)
+		}
+		return name + separator + fmt.Sprint(key)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			err := flattenEnvironment(values, join(key), separator, field)
+			if err != nil {
+				return err
+			}
+		}
+	case map[interface{}]interface{}:
+		for key, field := range v {
+			err := flattenEnvironment(values, join(key), separator, field)
+			if err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		return ErrEnvFileArray(name)
+	case nil:
+		values[name] = ""
+	default:
+		values[name] = fmt.Sprint(v)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package secrethub
+
+import (
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestEnvFileFormat(t *testing.T) {
+	assert.Equal(t, envFileFormat("secrethub.env"), "")
+	assert.Equal(t, envFileFormat("config/app.json"), documentFormatJSON)
+	assert.Equal(t, envFileFormat("app.yaml"), documentFormatYAML)
+	assert.Equal(t, envFileFormat("app.YML"), documentFormatYAML)
+}
+
+func TestParseStructuredEnvironment(t *testing.T) {
+	cases := map[string]struct {
+		raw       string
+		format    string
+		separator string
+		expected  []envvar
+		err       error
+	}{
+		"json": {
+			raw:       `{"db": {"user": "app", "port": 5432, "tls": true}, "debug": null}`,
+			format:    documentFormatJSON,
+			separator: "_",
+			expected: []envvar{
+				{key: "db_port", value: "5432", lineNumber: -1},
+				{key: "db_tls", value: "true", lineNumber: -1},
+				{key: "db_user", value: "app", lineNumber: -1},
+				{key: "debug", value: "", lineNumber: -1},
+			},
+		},
+		"yaml": {
+			raw:       "DB:\n  USER: app\n  PASSWORD: '{{ company/app/db/password }}'\n",
+			format:    documentFormatYAML,
+			separator: "__",
+			expected: []envvar{
+				{key: "DB__PASSWORD", value: "{{ company/app/db/password }}", lineNumber: -1},
+				{key: "DB__USER", value: "app", lineNumber: -1},
+			},
+		},
+		"json array": {
+			raw:    `["a", "b"]`,
+			format: documentFormatJSON,
+			err:    ErrEnvFileNotObject,
+		},
+		"yaml nested array": {
+			raw:       "HOSTS:\n  - a\n  - b\n",
+			format:    documentFormatYAML,
+			separator: "_",
+			err:       ErrEnvFileArray("HOSTS"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := parseStructuredEnvironment([]byte(tc.raw), tc.format, tc.separator)
+			assert.Equal(t, err, tc.err)
+			if tc.err == nil {
+				assert.Equal(t, actual, tc.expected)
+			}
+		})
+	}
+}
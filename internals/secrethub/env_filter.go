@@ -0,0 +1,84 @@
+package secrethub
+
+import (
+	"path"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+)
+
+// Errors
+var (
+	ErrInvalidEnvPattern = errRun.Code("invalid_env_pattern").ErrorPref("invalid environment variable pattern %s: %s")
+)
+
+// envFilter selects the environment variables of the current process that are passed on to a command.
+// Environment variables that are sourced by SecretHub, e.g. from an env file, are always passed on.
+type envFilter struct {
+	noPrune      bool
+	allow        []string
+	denyPatterns []string
+}
+
+// registerFlags registers the flags that configure the filter.
+func (f *envFilter) registerFlags(clause *cli.CommandClause) {
+	clause.Flag("allow", "Only pass on these environment variables of the current environment to the command, e.g. --allow PATH,HOME. Can be repeated and can contain patterns, e.g. LC_*.").StringsVar(&f.allow)
+	clause.Flag("deny-pattern", "Do not pass on the environment variables of the current environment that match this pattern to the command, e.g. --deny-pattern 'AWS_*'. Can be repeated and takes precedence over --allow.").StringsVar(&f.denyPatterns)
+	clause.Flag("no-prune", "Pass on all environment variables of the current environment to the command, ignoring --allow and --deny-pattern, e.g. when these are set with environment variables.").BoolVar(&f.noPrune)
+}
+
+// passes returns whether the environment variable with the given name is passed on.
+// Patterns use shell file name syntax, in which * matches any sequence of characters.
+func (f envFilter) passes(name string) (bool, error) {
+	if f.noPrune {
+		return true, nil
+	}
+
+	denied, err := matchesAny(name, f.denyPatterns)
+	if err != nil || denied {
+		return false, err
+	}
+
+	var allow []string
+	for _, value := range f.allow {
+		for _, pattern := range strings.Split(value, ",") {
+			pattern = strings.TrimSpace(pattern)
+			if pattern != "" {
+				allow = append(allow, pattern)
+			}
+		}
+	}
+	if len(allow) == 0 {
+		return true, nil
+	}
+	return matchesAny(name, allow)
+}
+
+// filter returns the environment variables of the map that pass the filter.
+func (f envFilter) filter(env map[string]string) (map[string]string, error) {
+	res := make(map[string]string, len(env))
+	for name, value := range env {
+		ok, err := f.passes(name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			res[name] = value
+		}
+	}
+	return res, nil
+}
+
+// matchesAny returns whether the name matches any of the patterns.
+func matchesAny(name string, patterns []string) (bool, error) {
+	for _, pattern := range patterns {
+		ok, err := path.Match(pattern, name)
+		if err != nil {
+			return false, ErrInvalidEnvPattern(pattern, err)
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
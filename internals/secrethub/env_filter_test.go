@@ -0,0 +1,89 @@
+package secrethub
+
+import (
+	"path"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestEnvFilter_passes(t *testing.T) {
+	cases := map[string]struct {
+		filter   envFilter
+		name     string
+		expected bool
+		err      error
+	}{
+		"no filter": {
+			name:     "TOKEN",
+			expected: true,
+		},
+		"denied": {
+			filter: envFilter{
+				denyPatterns: []string{"AWS_*"},
+			},
+			name:     "AWS_SECRET_ACCESS_KEY",
+			expected: false,
+		},
+		"not denied": {
+			filter: envFilter{
+				denyPatterns: []string{"AWS_*"},
+			},
+			name:     "PATH",
+			expected: true,
+		},
+		"allowed": {
+			filter: envFilter{
+				allow: []string{"PATH,HOME"},
+			},
+			name:     "HOME",
+			expected: true,
+		},
+		"allowed with spaces": {
+			filter: envFilter{
+				allow: []string{"PATH, HOME"},
+			},
+			name:     "HOME",
+			expected: true,
+		},
+		"not allowed": {
+			filter: envFilter{
+				allow: []string{"PATH,HOME"},
+			},
+			name:     "TOKEN",
+			expected: false,
+		},
+		"deny takes precedence over allow": {
+			filter: envFilter{
+				allow:        []string{"AWS_REGION"},
+				denyPatterns: []string{"AWS_*"},
+			},
+			name:     "AWS_REGION",
+			expected: false,
+		},
+		"no prune": {
+			filter: envFilter{
+				noPrune:      true,
+				allow:        []string{"PATH"},
+				denyPatterns: []string{"*"},
+			},
+			name:     "TOKEN",
+			expected: true,
+		},
+		"invalid pattern": {
+			filter: envFilter{
+				denyPatterns: []string{"AWS_["},
+			},
+			name: "AWS_REGION",
+			err:  ErrInvalidEnvPattern("AWS_[", path.ErrBadPattern),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := tc.filter.passes(tc.name)
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
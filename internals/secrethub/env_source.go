@@ -9,7 +9,9 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"text/tabwriter"
 	"unicode"
 
 	"github.com/secrethub/secrethub-cli/internals/cli"
@@ -40,74 +42,164 @@ type environment struct {
 	readFile                     func(filename string) ([]byte, error)
 	osStat                       func(filename string) (os.FileInfo, error)
 	envar                        map[string]string
-	envFile                      string
+	envVars                      map[string]string
+	envFiles                     []string
+	envFileSeparator             string
 	templateVars                 map[string]string
 	templateVersion              string
 	dontPromptMissingTemplateVar bool
 	secretsDir                   string
 	secretsEnvDir                string
+	noEnvPassthrough             bool
+	filter                       envFilter
 }
 
 func newEnvironment(io ui.IO, newClient newClientFunc) *environment {
 	return &environment{
-		io:           io,
-		newClient:    newClient,
-		osEnv:        os.Environ(),
-		readFile:     ioutil.ReadFile,
-		osStat:       os.Stat,
-		templateVars: make(map[string]string),
-		envar:        make(map[string]string),
+		io:               io,
+		newClient:        newClient,
+		osEnv:            os.Environ(),
+		readFile:         ioutil.ReadFile,
+		osStat:           os.Stat,
+		templateVars:     make(map[string]string),
+		envar:            make(map[string]string),
+		envVars:          make(map[string]string),
+		envFileSeparator: defaultEnvFileSeparator,
+		secretsEnvDir:    "default",
 	}
 }
 
 func (env *environment) register(clause *cli.CommandClause) {
-	clause.Flag("envar", "Source an environment variable from a secret at a given path with `NAME=<path>`").Short('e').StringMapVar(&env.envar)
-	clause.Flag("env-file", "The path to a file with environment variable mappings of the form `NAME=value`. Template syntax can be used to inject secrets.").StringVar(&env.envFile)
-	clause.Flag("template", "").Hidden().StringVar(&env.envFile)
-	clause.Flag("var", "Define the value for a template variable with `VAR=VALUE`, e.g. --var env=prod").Short('v').StringMapVar(&env.templateVars)
-	clause.Flag("template-version", "The template syntax version to be used. The options are v1, v2, latest or auto to automatically detect the version.").Default("auto").StringVar(&env.templateVersion)
+	env.registerFlags(clause, true)
+}
+
+// registerFlags registers the flags of the environment. The shorthands -e and -v can be left out
+// for commands that already use them for other flags.
+func (env *environment) registerFlags(clause *cli.CommandClause, shorthands bool) {
+	envarFlag := clause.Flag("envar", "Source an environment variable from a secret at a given path with `NAME=<path>`")
+	if shorthands {
+		envarFlag.Short('e')
+	}
+	envarFlag.StringMapVar(&env.envar)
+	clause.Flag("env", "Set an environment variable with `NAME=value`, e.g. --env DB_PASSWORD=secrethub://company/app/db/password. Values of the form secrethub://<path> are replaced with the secret at the path.").SetValue(envFlagValue{envDir: &env.secretsEnvDir, envVars: env.envVars})
+	clause.Flag("env-file", "The path to a file with environment variable mappings of the form `NAME=value`, or a .json or .yaml file with an object of environment variables. Template syntax can be used to inject secrets. Can be repeated, in which case later files take precedence over earlier ones.").StringsVar(&env.envFiles)
+	clause.Flag("template", "").Hidden().StringsVar(&env.envFiles)
+	clause.Flag("env-file-separator", "The separator that joins the keys of nested objects in a .json or .yaml env file into the name of an environment variable.").Default(defaultEnvFileSeparator).StringVar(&env.envFileSeparator)
+	varFlag := clause.Flag("var", "Define the value for a template variable with `VAR=VALUE`, e.g. --var env=prod")
+	if shorthands {
+		varFlag.Short('v')
+	}
+	varFlag.StringMapVar(&env.templateVars)
+	clause.Flag("template-version", "The template syntax version to be used. The options are v1, v2, v3, latest or auto to automatically detect the version.").Default("auto").StringVar(&env.templateVersion)
 	clause.Flag("no-prompt", "Do not prompt when a template variable is missing and return an error instead.").BoolVar(&env.dontPromptMissingTemplateVar)
 	clause.Flag("secrets-dir", "Recursively include all secrets from a directory. Environment variable names are derived from the path of the secret: `/` are replaced with `_` and the name is uppercased.").StringVar(&env.secretsDir)
-	clause.Flag("env", "The name of the environment prepared by the set command (default is `default`)").Default("default").Hidden().StringVar(&env.secretsEnvDir)
+	clause.Flag("env-passthrough", "Resolve the values of the form `secrethub://<path>` of environment variables that are passed on from the current environment to the secrets at these paths. This is enabled by default, use --no-env-passthrough to pass on these values as they are.").SetValue(invertedBoolValue{target: &env.noEnvPassthrough})
 }
 
+// envFlagValue is the value of the repeatable --env flag, which sets an environment variable with `NAME=value`.
+// For backwards compatibility, a value without = sets the name of the environment prepared by the set command.
+type envFlagValue struct {
+	envDir  *string
+	envVars map[string]string
+}
+
+// Set implements the flag.Value interface.
+func (v envFlagValue) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) == 1 {
+		*v.envDir = value
+		return nil
+	}
+
+	err := validation.ValidateEnvarName(parts[0])
+	if err != nil {
+		return err
+	}
+	v.envVars[parts[0]] = parts[1]
+	return nil
+}
+
+// String implements the flag.Value interface.
+func (v envFlagValue) String() string {
+	return ""
+}
+
+// IsCumulative makes the flag repeatable.
+func (v envFlagValue) IsCumulative() bool {
+	return true
+}
+
+// sourcedEnv is the environment read from a single source.
+type sourcedEnv struct {
+	// source describes where the environment is read from, e.g. --env-file secrethub.env.
+	source string
+	env    map[string]value
+}
+
+// env returns the environment read from all sources.
 func (env *environment) env() (map[string]value, error) {
+	envs, err := env.sourcedEnvs()
+	if err != nil {
+		return nil, err
+	}
+
+	maps := make([]map[string]value, len(envs))
+	for i, e := range envs {
+		maps[i] = e.env
+	}
+	return mergeEnvs(maps...), nil
+}
+
+// sourcedEnvs reads the environment from all sources. The environments are returned in order of
+// increasing precedence: the current environment, the .secretsenv directory, --secrets-dir,
+// the env files in the order they are given, secret references in the current environment,
+// --envar and --env.
+func (env *environment) sourcedEnvs() ([]sourcedEnv, error) {
 	osEnvMap, _ := parseKeyValueStringsToMap(env.osEnv)
-	var sources []EnvSource
+	type namedSource struct {
+		name   string
+		source EnvSource
+	}
+	var sources []namedSource
 
-	sources = append(sources, &osEnv{
-		osEnv: osEnvMap,
-	})
+	passedOsEnv, err := env.filter.filter(osEnvMap)
+	if err != nil {
+		return nil, err
+	}
+	sources = append(sources, namedSource{"environment", &osEnv{
+		osEnv: passedOsEnv,
+	}})
 
 	// .secretsenv dir (for backwards compatibility)
 	envDir := filepath.Join(secretspec.SecretEnvPath, env.secretsEnvDir)
-	_, err := os.Stat(envDir)
+	_, err = os.Stat(envDir)
 	if err == nil {
 		dirSource, err := NewEnvDir(envDir)
 		if err != nil {
 			return nil, err
 		}
-		sources = append(sources, dirSource)
+		sources = append(sources, namedSource{envDir, dirSource})
 	}
 
 	// --secrets-dir flag
 	if env.secretsDir != "" {
 		secretsDirEnv := newSecretsDirEnv(env.newClient, env.secretsDir)
-		sources = append(sources, secretsDirEnv)
+		sources = append(sources, namedSource{"--secrets-dir " + env.secretsDir, secretsDirEnv})
 	}
 
 	//secrethub.env file
-	if env.envFile == "" {
+	envFiles := env.envFiles
+	if len(envFiles) == 0 {
 		_, err := env.osStat(defaultEnvFile)
 		if err == nil {
-			env.envFile = defaultEnvFile
+			envFiles = []string{defaultEnvFile}
 		} else if !os.IsNotExist(err) {
 			return nil, ErrReadDefaultEnvFile(defaultEnvFile, err)
 		}
 	}
 
-	if env.envFile != "" {
-		templateVariableReader, err := newVariableReader(osEnvMap, env.templateVars)
+	if len(envFiles) > 0 {
+		templateVariableReader, err := newVariableReader(passedOsEnv, env.templateVars)
 		if err != nil {
 			return nil, err
 		}
@@ -116,26 +208,20 @@ func (env *environment) env() (map[string]value, error) {
 			templateVariableReader = newPromptMissingVariableReader(templateVariableReader, env.io)
 		}
 
-		raw, err := env.readFile(env.envFile)
-		if err != nil {
-			return nil, ErrCannotReadFile(env.envFile, err)
-		}
-
-		parser, err := getTemplateParser(raw, env.templateVersion)
-		if err != nil {
-			return nil, err
-		}
-
-		envFile, err := ReadEnvFile(env.envFile, bytes.NewReader(raw), templateVariableReader, parser)
-		if err != nil {
-			return nil, err
+		for _, path := range envFiles {
+			envFile, err := env.readEnvFile(path, templateVariableReader)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, namedSource{"--env-file " + path, envFile})
 		}
-		sources = append(sources, envFile)
 	}
 
 	// secret references (secrethub://)
-	referenceEnv := newReferenceEnv(osEnvMap)
-	sources = append(sources, referenceEnv)
+	if !env.noEnvPassthrough {
+		referenceEnv := newReferenceEnv(passedOsEnv)
+		sources = append(sources, namedSource{"environment (" + secretReferencePrefix + ")", referenceEnv})
+	}
 
 	// --envar flag
 	// TODO: Validate the flags when parsing by implementing the Flag interface for EnvFlags.
@@ -143,18 +229,72 @@ func (env *environment) env() (map[string]value, error) {
 	if err != nil {
 		return nil, err
 	}
-	sources = append(sources, flagEnv)
+	sources = append(sources, namedSource{"--envar", flagEnv})
 
-	envs := make([]map[string]value, len(sources))
+	// --env flag
+	sources = append(sources, namedSource{"--env", newInlineEnv(env.envVars)})
+
+	envs := make([]sourcedEnv, 0, len(sources))
 	for _, source := range sources {
-		env, err := source.env()
+		env, err := source.source.env()
 		if err != nil {
 			return nil, err
 		}
-		envs = append(envs, env)
+		envs = append(envs, sourcedEnv{
+			source: source.name,
+			env:    env,
+		})
+	}
+	return envs, nil
+}
+
+// readEnvFile reads the env file at the given path.
+func (env *environment) readEnvFile(path string, varReader tpl.VariableReader) (EnvFile, error) {
+	raw, err := env.readFile(path)
+	if err != nil {
+		return EnvFile{}, ErrCannotReadFile(path, err)
+	}
+
+	parser, err := getTemplateParser(raw, env.templateVersion)
+	if err != nil {
+		return EnvFile{}, err
 	}
 
-	return mergeEnvs(envs...), nil
+	format := envFileFormat(path)
+	if format != "" {
+		return readStructuredEnvFile(path, raw, format, env.envFileSeparator, varReader, parser)
+	}
+	return ReadEnvFile(path, bytes.NewReader(raw), varReader, parser)
+}
+
+// printResolution prints for every environment variable the source it is read from
+// and the sources with a lower precedence that also define it.
+func (env *environment) printResolution(w io.Writer) error {
+	envs, err := env.sourcedEnvs()
+	if err != nil {
+		return err
+	}
+
+	sources := make(map[string][]string)
+	for _, e := range envs {
+		for name := range e.env {
+			sources[name] = append(sources[name], e.source)
+		}
+	}
+
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSOURCE\tOVERRIDES")
+	for _, name := range names {
+		s := sources[name]
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", name, s[len(s)-1], strings.Join(s[:len(s)-1], ", "))
+	}
+	return tw.Flush()
 }
 
 func mergeEnvs(envs ...map[string]value) map[string]value {
@@ -213,6 +353,7 @@ func (s *secretsDirEnv) env() (map[string]value, error) {
 	if err != nil {
 		return nil, err
 	}
+	excludeTrash(tree, s.dirPath)
 
 	paths := make(map[string]string, tree.SecretCount())
 	for id := range tree.Secrets {
@@ -287,6 +428,32 @@ func (ef EnvFlags) env() (map[string]value, error) {
 	return result, nil
 }
 
+// inlineEnv defines environment variables set with the --env flag.
+// Values of the form secrethub://<path> are sourced from the secret at the path.
+type inlineEnv map[string]string
+
+func newInlineEnv(envVars map[string]string) inlineEnv {
+	return envVars
+}
+
+// env returns a map of the environment variables set with the --env flag.
+func (e inlineEnv) env() (map[string]value, error) {
+	result := make(map[string]value, len(e))
+	for name, v := range e {
+		if strings.HasPrefix(v, secretReferencePrefix) {
+			path := strings.TrimPrefix(v, secretReferencePrefix)
+			err := api.ValidateSecretPath(path)
+			if err != nil {
+				return nil, err
+			}
+			result[name] = newSecretValue(path)
+		} else {
+			result[name] = newPlaintextValue(v)
+		}
+	}
+	return result, nil
+}
+
 // referenceEnv is an environment with secrets configured with the
 // secrethub:// syntax in the os environment variables.
 type referenceEnv struct {
@@ -467,6 +634,11 @@ func NewEnv(filepath string, r io.Reader, varReader tpl.VariableReader, parser t
 		return nil, err
 	}
 
+	return newEnvTemplate(filepath, env, varReader, parser)
+}
+
+// newEnvTemplate parses the keys and values of the environment variables as templates.
+func newEnvTemplate(filepath string, env []envvar, varReader tpl.VariableReader, parser tpl.Parser) (EnvSource, error) {
 	secretTemplates := make([]envvarTpls, len(env))
 	for i, envvar := range env {
 		keyTpl, err := parser.Parse(envvar.key, envvar.lineNumber, envvar.columnNumberKey)
@@ -1,6 +1,8 @@
 package secrethub
 
 import (
+	"bytes"
+	"os"
 	"testing"
 
 	"github.com/secrethub/secrethub-go/internals/api"
@@ -140,3 +142,51 @@ func TestSecretsDirEnv(t *testing.T) {
 		})
 	}
 }
+
+func TestEnvFlagValue(t *testing.T) {
+	envDir := "default"
+	envVars := make(map[string]string)
+	value := envFlagValue{envDir: &envDir, envVars: envVars}
+
+	err := value.Set("DB_PASSWORD=secrethub://company/app/db/password")
+	assert.OK(t, err)
+	err = value.Set("URL=https://example.com/?a=b")
+	assert.OK(t, err)
+	err = value.Set("test")
+	assert.OK(t, err)
+
+	assert.Equal(t, envVars, map[string]string{
+		"DB_PASSWORD": "secrethub://company/app/db/password",
+		"URL":         "https://example.com/?a=b",
+	})
+	assert.Equal(t, envDir, "test")
+
+	err = value.Set("1NVALID=value")
+	assert.Equal(t, err == nil, false)
+}
+
+func TestEnvironment_printResolution(t *testing.T) {
+	env := &environment{
+		osEnv:  []string{"HOME=/root", "LOG_LEVEL=debug"},
+		osStat: osStatFunc("secrethub.env", os.ErrNotExist),
+		readFile: func(filename string) ([]byte, error) {
+			if filename == "app.env" {
+				return []byte("LOG_LEVEL=info\nPORT=8080"), nil
+			}
+			return nil, os.ErrNotExist
+		},
+		envFiles:        []string{"app.env"},
+		templateVersion: "2",
+		envVars:         map[string]string{"PORT": "80"},
+	}
+
+	var buffer bytes.Buffer
+	err := env.printResolution(&buffer)
+	assert.OK(t, err)
+
+	assert.Equal(t, buffer.String(), ""+
+		"NAME       SOURCE              OVERRIDES\n"+
+		"HOME       environment         \n"+
+		"LOG_LEVEL  --env-file app.env  environment\n"+
+		"PORT       --env               --env-file app.env\n")
+}
@@ -0,0 +1,240 @@
+package secrethub
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/errio"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+
+	units "github.com/docker/go-units"
+)
+
+// Errors
+var (
+	errExpiry          = errio.Namespace("expiry")
+	ErrInvalidDuration = errExpiry.Code("invalid_duration").ErrorPref("invalid duration %s: use a number followed by d (days), w (weeks) or a Go duration like 36h")
+	ErrInvalidExpiry   = errExpiry.Code("invalid_expiry").ErrorPref("the expiry date %s of %s is invalid: %s")
+)
+
+const (
+	// expiresLabel is the label that holds the expiry date of a secret, formatted as RFC3339.
+	expiresLabel = "expires"
+)
+
+// ExpiringCommand lists the secrets that expire soon.
+type ExpiringCommand struct {
+	io            ui.IO
+	path          api.Path
+	within        dayDuration
+	useTimestamps bool
+	now           func() time.Time
+	newClient     newClientFunc
+}
+
+// NewExpiringCommand creates a new ExpiringCommand.
+func NewExpiringCommand(io ui.IO, newClient newClientFunc) *ExpiringCommand {
+	return &ExpiringCommand{
+		io:        io,
+		within:    dayDuration(14 * 24 * time.Hour),
+		now:       time.Now,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *ExpiringCommand) Register(r command.Registerer) {
+	clause := r.Command("expiring", "List the secrets that expire soon.")
+	clause.HelpLong("Lists the secrets with an expiry date that has passed or is within the given period, " +
+		"so they can be renewed in time. Set the expiry date of a secret with `" + ApplicationName + " write --expires-in 90d` " +
+		"or `" + ApplicationName + " meta set <path> " + expiresLabel + "=<RFC3339 date>`.\n" +
+		"\n" +
+		"Without a path, the secrets in all namespaces you have access to are listed.")
+	clause.Arg("path", "Only list the secrets in this namespace, repository or directory.").PlaceHolder("<namespace>[/<repo>[/<dir> ...]]").SetValue(&cmd.path)
+	clause.Flag("within", "List the secrets that expire within this period, e.g. 14d or 2w.").Default(cmd.within.String()).SetValue(&cmd.within)
+	registerTimestampFlag(clause).BoolVar(&cmd.useTimestamps)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run lists the secrets that expire soon.
+func (cmd *ExpiringCommand) Run() error {
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	var namespaces []string
+	if cmd.path != "" {
+		namespaces = []string{strings.SplitN(cmd.path.Value(), "/", 2)[0]}
+	} else {
+		namespaces, err = myNamespaces(client)
+		if err != nil {
+			return err
+		}
+	}
+
+	now := cmd.now()
+	deadline := now.Add(time.Duration(cmd.within))
+	var expiring []expiringSecret
+	for _, namespace := range namespaces {
+		secrets, err := listExpiries(client, namespace)
+		if err != nil {
+			return err
+		}
+		for _, secret := range secrets {
+			if cmd.path != "" && !isSubPath(secret.path, cmd.path.Value()) {
+				continue
+			}
+			if !secret.expires.After(deadline) {
+				expiring = append(expiring, secret)
+			}
+		}
+	}
+
+	if len(expiring) == 0 {
+		fmt.Fprintf(cmd.io.Output(), "No secrets expire within %s.\n", cmd.within)
+		return nil
+	}
+
+	sort.Slice(expiring, func(i, j int) bool {
+		return expiring[i].expires.Before(expiring[j].expires)
+	})
+	return printExpiring(cmd.io.Output(), expiring, now, cmd.useTimestamps)
+}
+
+// expiringSecret is a secret with an expiry date.
+type expiringSecret struct {
+	path    string
+	expires time.Time
+}
+
+// myNamespaces returns the namespaces of the repositories the current account has access to.
+func myNamespaces(client secrethub.ClientInterface) ([]string, error) {
+	repos, err := client.Repos().ListMine()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var namespaces []string
+	for _, repo := range repos {
+		namespace := strings.SplitN(repo.Path().Value(), "/", 2)[0]
+		if !seen[namespace] {
+			seen[namespace] = true
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// listExpiries returns the secrets with an expiry date in all repositories of a namespace.
+func listExpiries(client secrethub.ClientInterface, namespace string) ([]expiringSecret, error) {
+	tree, err := client.Dirs().GetTree(api.JoinPaths(namespace, metadataRepoName), 1, false)
+	if api.IsErrNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var secrets []expiringSecret
+	for _, secret := range tree.RootDir.Secrets {
+		metadata, err := readRepoMetadata(client, api.JoinPaths(namespace, secret.Name))
+		if err != nil {
+			return nil, err
+		}
+
+		for path, labels := range metadata.Labels {
+			value, ok := labels[expiresLabel]
+			if !ok {
+				continue
+			}
+			expires, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, ErrInvalidExpiry(value, path, err)
+			}
+			secrets = append(secrets, expiringSecret{path: path, expires: expires})
+		}
+	}
+	return secrets, nil
+}
+
+// printExpiring prints a table with the expiry dates of the secrets.
+func printExpiring(w io.Writer, secrets []expiringSecret, now time.Time, timestamps bool) error {
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\t%s\n", "PATH", "EXPIRES")
+	for _, secret := range secrets {
+		fmt.Fprintf(tw, "%s\t%s\n", secret.path, formatExpiry(secret.expires, now, timestamps))
+	}
+	return tw.Flush()
+}
+
+// formatExpiry returns a human readable representation of an expiry date, e.g. in 3 days or expired 2 hours ago.
+func formatExpiry(expires time.Time, now time.Time, timestamps bool) string {
+	if timestamps {
+		return expires.Format(time.RFC3339)
+	}
+	if expires.After(now) {
+		return "in " + units.HumanDuration(expires.Sub(now))
+	}
+	return "expired " + units.HumanDuration(now.Sub(expires)) + " ago"
+}
+
+// dayDuration is a duration flag that also accepts days and weeks, e.g. 90d or 2w.
+type dayDuration time.Duration
+
+// Set implements the flag.Value interface.
+func (d *dayDuration) Set(value string) error {
+	duration, err := parseDayDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = dayDuration(duration)
+	return nil
+}
+
+// String implements the flag.Value interface.
+func (d dayDuration) String() string {
+	duration := time.Duration(d)
+	day := 24 * time.Hour
+	if duration > 0 && duration%(7*day) == 0 {
+		return strconv.FormatInt(int64(duration/(7*day)), 10) + "w"
+	}
+	if duration > 0 && duration%day == 0 {
+		return strconv.FormatInt(int64(duration/day), 10) + "d"
+	}
+	return duration.String()
+}
+
+// parseDayDuration parses a number of days (90d) or weeks (2w), or a duration as accepted by time.ParseDuration.
+func parseDayDuration(value string) (time.Duration, error) {
+	suffixes := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+	for suffix, unit := range suffixes {
+		if strings.HasSuffix(value, suffix) {
+			n, err := strconv.Atoi(strings.TrimSuffix(value, suffix))
+			if err != nil || n <= 0 {
+				return 0, ErrInvalidDuration(value)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, ErrInvalidDuration(value)
+	}
+	return duration, nil
+}
@@ -0,0 +1,148 @@
+package secrethub
+
+import (
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestParseDayDuration(t *testing.T) {
+	cases := map[string]struct {
+		value    string
+		expected time.Duration
+		err      error
+	}{
+		"days": {
+			value:    "90d",
+			expected: 90 * 24 * time.Hour,
+		},
+		"weeks": {
+			value:    "2w",
+			expected: 14 * 24 * time.Hour,
+		},
+		"go duration": {
+			value:    "36h",
+			expected: 36 * time.Hour,
+		},
+		"zero": {
+			value: "0d",
+			err:   ErrInvalidDuration("0d"),
+		},
+		"negative": {
+			value: "-1h",
+			err:   ErrInvalidDuration("-1h"),
+		},
+		"invalid": {
+			value: "soon",
+			err:   ErrInvalidDuration("soon"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := parseDayDuration(tc.value)
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
+
+func TestDayDuration_String(t *testing.T) {
+	assert.Equal(t, dayDuration(14*24*time.Hour).String(), "2w")
+	assert.Equal(t, dayDuration(90*24*time.Hour).String(), "90d")
+	assert.Equal(t, dayDuration(36*time.Hour).String(), "36h0m0s")
+}
+
+func TestExpiringCommand_Run(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	metadata := map[string]string{
+		"company/secrethub-metadata/app": `{"labels":{
+			"company/app/tls/cert": {"expires": "2020-06-05T12:00:00Z"},
+			"company/app/api-key": {"expires": "2020-05-30T12:00:00Z", "owner": "team-a"},
+			"company/app/db/password": {"expires": "2020-09-01T12:00:00Z"},
+			"company/app/db/user": {"owner": "team-a"}
+		}}`,
+		"company/secrethub-metadata/other": `{"labels":{
+			"company/other/token": {"expires": "2020-06-02T12:00:00Z"}
+		}}`,
+	}
+
+	cases := map[string]struct {
+		path     api.Path
+		within   time.Duration
+		expected string
+	}{
+		"namespace": {
+			path:   "company",
+			within: 14 * 24 * time.Hour,
+			expected: "PATH                  EXPIRES\n" +
+				"company/app/api-key   expired 2 days ago\n" +
+				"company/other/token   in 24 hours\n" +
+				"company/app/tls/cert  in 4 days\n",
+		},
+		"repository": {
+			path:   "company/app",
+			within: 14 * 24 * time.Hour,
+			expected: "PATH                  EXPIRES\n" +
+				"company/app/api-key   expired 2 days ago\n" +
+				"company/app/tls/cert  in 4 days\n",
+		},
+		"nothing expiring": {
+			path:     "company/app/db",
+			within:   14 * 24 * time.Hour,
+			expected: "No secrets expire within 2w.\n",
+		},
+		"longer period": {
+			path:   "company/app/db",
+			within: 100 * 24 * time.Hour,
+			expected: "PATH                     EXPIRES\n" +
+				"company/app/db/password  in 3 months\n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			io := fakeui.NewIO(t)
+			cmd := ExpiringCommand{
+				io:     io,
+				path:   tc.path,
+				within: dayDuration(tc.within),
+				now: func() time.Time {
+					return now
+				},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						DirService: &fakeclient.DirService{
+							GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+								assert.Equal(t, path, "company/secrethub-metadata")
+								return &api.Tree{
+									RootDir: &api.Dir{
+										Name:    "secrethub-metadata",
+										Secrets: []*api.Secret{{Name: "app"}, {Name: "other"}},
+									},
+								}, nil
+							},
+						},
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+									return &api.SecretVersion{Data: []byte(metadata[path])}, nil
+								},
+							},
+						},
+					}, nil
+				},
+			}
+
+			err := cmd.Run()
+			assert.OK(t, err)
+			assert.Equal(t, io.Out.String(), tc.expected)
+		})
+	}
+}
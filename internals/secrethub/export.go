@@ -0,0 +1,372 @@
+package secrethub
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/filemode"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/errio"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Errors
+var (
+	errExport                 = errio.Namespace("export")
+	ErrInvalidExportFormat    = errExport.Code("invalid_format").ErrorPref("invalid format %s: the options are dotenv, json and yaml")
+	ErrInvalidKeyTemplate     = errExport.Code("invalid_key_template").ErrorPref("invalid key template: %s")
+	ErrDuplicateExportKey     = errExport.Code("duplicate_key").ErrorPref("the secrets %s and %s both result in the key %s: use --key-template to make the keys unique")
+	ErrEmptyExportKey         = errExport.Code("empty_key").ErrorPref("the key template results in an empty key for %s")
+	ErrInvalidDotEnvExportKey = errExport.Code("invalid_dotenv_key").ErrorPref("%s is not a valid key for the dotenv format: keys cannot contain whitespace or =")
+)
+
+const (
+	exportFormatDotEnv = "dotenv"
+	exportFormatJSON   = "json"
+	exportFormatYAML   = "yaml"
+)
+
+var (
+	envKeyIllegalChars = regexp.MustCompile(`[^A-Z0-9_]+`)
+	dotEnvSafeValue    = regexp.MustCompile(`^[A-Za-z0-9_./:@+,%-]*$`)
+)
+
+// ExportCommand handles exporting secrets to files that can be used by other tools.
+type ExportCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+}
+
+// NewExportCommand creates a new ExportCommand.
+func NewExportCommand(io ui.IO, newClient newClientFunc) *ExportCommand {
+	return &ExportCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command and its sub-commands on the provided Registerer.
+func (cmd *ExportCommand) Register(r command.Registerer) {
+	clause := r.Command("export", "Export the secrets in a directory to a dotenv, JSON or YAML file, a Kubernetes Secret manifest or Terraform variables.")
+	clause.HelpLong("Without a sub-command, the secrets are exported to a dotenv, JSON or YAML file, e.g. secrethub export company/repo/dir --format json.")
+	NewExportFileCommand(cmd.io, cmd.newClient).Register(clause)
+	NewExportK8sCommand(cmd.io, cmd.newClient).Register(clause)
+	NewExportTerraformCommand(cmd.io, cmd.newClient).Register(clause)
+}
+
+// exportOptions are the flags shared by all export commands.
+type exportOptions struct {
+	recursive   bool
+	keyTemplate string
+	outFile     string
+	fileMode    filemode.FileMode
+	force       bool
+}
+
+// register registers the shared flags of the export commands.
+func (o *exportOptions) register(r FlagRegisterer) {
+	r.Flag("recursive", "Also export the secrets in all subdirectories.").Short('r').BoolVar(&o.recursive)
+	r.Flag("key-template", "A Go template that generates the key of every secret.").StringVar(&o.keyTemplate)
+	r.Flag("out-file", "Write the exported secrets to a file instead of stdout.").Short('o').StringVar(&o.outFile)
+	r.Flag("file-mode", "Set filemode for the output file if it does not yet exist. Defaults to 0600 (read and write for current user) and is ignored without the --out-file flag.").Default("0600").SetValue(&o.fileMode)
+	r.Flag("force", "Overwrite the output file if it already exists, without prompting for confirmation. This flag is ignored if no --out-file is supplied.").Short('f').BoolVar(&o.force)
+}
+
+// confirmOverwrite asks whether an existing output file may be overwritten.
+// It returns true when there is nothing to overwrite or when force is set.
+func (o *exportOptions) confirmOverwrite(io ui.IO) (bool, error) {
+	if o.outFile == "" {
+		return true, nil
+	}
+	return o.confirmOverwriteFile(io, o.outFile)
+}
+
+// confirmOverwriteFile asks whether the given file may be overwritten when it exists and force is not set.
+func (o *exportOptions) confirmOverwriteFile(io ui.IO, path string) (bool, error) {
+	if o.force {
+		return true, nil
+	}
+
+	_, err := os.Stat(path)
+	if err != nil {
+		return true, nil
+	}
+
+	confirmed, err := ui.AskYesNo(
+		io,
+		fmt.Sprintf("File %s already exists, overwrite it?", path),
+		ui.DefaultNo,
+	)
+	if err == ui.ErrCannotAsk {
+		return false, ErrFileAlreadyExists
+	} else if err != nil {
+		return false, err
+	}
+
+	if !confirmed {
+		fmt.Fprintln(io.Output(), "Aborting.")
+	}
+	return confirmed, nil
+}
+
+// fetchSecrets returns the secrets in the directory, with their paths relative to the directory.
+func (o *exportOptions) fetchSecrets(newClient newClientFunc, dirPath api.DirPath) ([]exportSecret, error) {
+	client, err := newClient()
+	if err != nil {
+		return nil, err
+	}
+
+	depth := 1
+	if o.recursive {
+		depth = -1
+	}
+
+	tree, err := client.Dirs().GetTree(dirPath.Value(), depth, false)
+	if err != nil {
+		return nil, err
+	}
+	excludeTrash(tree, dirPath.Value())
+
+	var secrets []exportSecret
+	for _, secretPath := range secretPathsInDir(tree.RootDir, dirPath.Value()) {
+		secret, err := client.Secrets().Versions().GetWithData(secretPath)
+		if err != nil {
+			return nil, err
+		}
+
+		secrets = append(secrets, exportSecret{
+			path:    strings.TrimPrefix(secretPath, dirPath.Value()+"/"),
+			version: secret.Version,
+			data:    secret.Data,
+		})
+	}
+	return secrets, nil
+}
+
+// write writes the exported secrets to the output file or, when no output file is set, to stdout.
+func (o *exportOptions) write(io ui.IO, out []byte, n int) error {
+	if o.outFile == "" {
+		_, err := io.Output().Write(out)
+		return err
+	}
+
+	err := ioutil.WriteFile(o.outFile, out, o.fileMode.FileMode())
+	if err != nil {
+		return ErrCannotWrite(o.outFile, err)
+	}
+
+	fmt.Fprintf(io.Output(), "Exported %s to %s.\n", pluralize("secret", "secrets", n), o.outFile)
+	return nil
+}
+
+// ExportFileCommand writes the secrets in a directory to a dotenv, JSON or YAML file.
+type ExportFileCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+	path      api.DirPath
+	format    string
+	options   exportOptions
+}
+
+// NewExportFileCommand creates a new ExportFileCommand.
+func NewExportFileCommand(io ui.IO, newClient newClientFunc) *ExportFileCommand {
+	return &ExportFileCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *ExportFileCommand) Register(r command.Registerer) {
+	clause := r.Command("file", "Export the secrets in a directory to a dotenv, JSON or YAML file. This is the default export command.")
+	clause.Default()
+	clause.HelpLong("The key of every secret is generated with a Go template, configured with --key-template. " +
+		"The template has access to .Path, the path of the secret relative to the exported directory, .Name, the name of the secret, and .Dir, the relative directory of the secret. " +
+		"The functions upper, lower, env and replace can be used to transform these values. " +
+		"For example, --key-template '{{ .Name | upper }}' uses the uppercased name of the secret as key and " +
+		"--key-template '{{ .Path | replace \"/\" \".\" }}' results in keys like db.password.\n\n" +
+		"By default, dotenv keys are the relative paths converted to environment variable names ({{ .Path | env }}) " +
+		"and JSON and YAML keys are the relative paths ({{ .Path }}).")
+	clause.Arg("dir-path", "The path to the directory to export.").Required().PlaceHolder(optionalDirPathPlaceHolder).SetValue(&cmd.path)
+	clause.Flag("format", "The format to export the secrets in. Options are: dotenv, json and yaml.").HintOptions(exportFormatDotEnv, exportFormatJSON, exportFormatYAML).Default(exportFormatDotEnv).StringVar(&cmd.format)
+	cmd.options.register(clause)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run exports the secrets.
+func (cmd *ExportFileCommand) Run() error {
+	keyTemplate, err := newExportKeyTemplate(cmd.format, cmd.options.keyTemplate)
+	if err != nil {
+		return err
+	}
+
+	confirmed, err := cmd.options.confirmOverwrite(cmd.io)
+	if err != nil || !confirmed {
+		return err
+	}
+
+	secrets, err := cmd.options.fetchSecrets(cmd.newClient, cmd.path)
+	if err != nil {
+		return err
+	}
+
+	out, err := formatExport(cmd.format, keyTemplate, secrets)
+	if err != nil {
+		return err
+	}
+
+	return cmd.options.write(cmd.io, out, len(secrets))
+}
+
+// exportSecret is a secret to export. Its path is relative to the exported directory.
+type exportSecret struct {
+	path    string
+	version int
+	data    []byte
+}
+
+// exportKeyData is passed to the key template.
+type exportKeyData struct {
+	Path string
+	Name string
+	Dir  string
+}
+
+// newExportKeyTemplate parses the key template, or returns the default template for the format.
+func newExportKeyTemplate(format string, text string) (*template.Template, error) {
+	if text == "" {
+		switch format {
+		case exportFormatDotEnv:
+			text = "{{ .Path | env }}"
+		case exportFormatJSON, exportFormatYAML:
+			text = "{{ .Path }}"
+		default:
+			return nil, ErrInvalidExportFormat(format)
+		}
+	}
+	return parseExportKeyTemplate(text)
+}
+
+// parseExportKeyTemplate parses a key template and adds the functions available in it.
+func parseExportKeyTemplate(text string) (*template.Template, error) {
+	tpl, err := template.New("key").Option("missingkey=error").Funcs(template.FuncMap{
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"env":   envVarName,
+		"replace": func(old, new, s string) string {
+			return strings.Replace(s, old, new, -1)
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, ErrInvalidKeyTemplate(err)
+	}
+	return tpl, nil
+}
+
+// envVarName converts a path to a name that can be used as an environment variable.
+func envVarName(s string) string {
+	return strings.Trim(envKeyIllegalChars.ReplaceAllString(strings.ToUpper(s), "_"), "_")
+}
+
+// exportKeys generates the key of every secret with the template.
+func exportKeys(keyTemplate *template.Template, secrets []exportSecret) ([]string, error) {
+	keys := make([]string, len(secrets))
+	paths := map[string]string{}
+	for i, secret := range secrets {
+		dir := path.Dir(secret.path)
+		if dir == "." {
+			dir = ""
+		}
+
+		var buf bytes.Buffer
+		err := keyTemplate.Execute(&buf, exportKeyData{
+			Path: secret.path,
+			Name: path.Base(secret.path),
+			Dir:  dir,
+		})
+		if err != nil {
+			return nil, ErrInvalidKeyTemplate(err)
+		}
+
+		key := buf.String()
+		if key == "" {
+			return nil, ErrEmptyExportKey(secret.path)
+		}
+		if other, exists := paths[key]; exists {
+			return nil, ErrDuplicateExportKey(other, secret.path, key)
+		}
+		paths[key] = secret.path
+		keys[i] = key
+	}
+	return keys, nil
+}
+
+// formatExport encodes the secrets in the given format.
+func formatExport(format string, keyTemplate *template.Template, secrets []exportSecret) ([]byte, error) {
+	keys, err := exportKeys(keyTemplate, secrets)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(secrets))
+	for i, secret := range secrets {
+		values[keys[i]] = string(secret.data)
+	}
+	sort.Strings(keys)
+
+	switch format {
+	case exportFormatDotEnv:
+		var buf bytes.Buffer
+		for _, key := range keys {
+			if strings.ContainsAny(key, "= \t\r\n") {
+				return nil, ErrInvalidDotEnvExportKey(key)
+			}
+			fmt.Fprintf(&buf, "%s=%s\n", key, dotEnvQuote(values[key]))
+		}
+		return buf.Bytes(), nil
+	case exportFormatJSON:
+		out, err := json.MarshalIndent(values, "", "    ")
+		if err != nil {
+			return nil, err
+		}
+		return append(out, '\n'), nil
+	case exportFormatYAML:
+		doc := make(yaml.MapSlice, len(keys))
+		for i, key := range keys {
+			doc[i] = yaml.MapItem{Key: key, Value: values[key]}
+		}
+		return yaml.Marshal(doc)
+	default:
+		return nil, ErrInvalidExportFormat(format)
+	}
+}
+
+// dotEnvQuote returns the value as is when it is safe to use unquoted in a
+// dotenv file. Otherwise, it is double quoted with quotes, backslashes and
+// newlines escaped.
+func dotEnvQuote(value string) string {
+	if dotEnvSafeValue.MatchString(value) {
+		return value
+	}
+
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"\r", `\r`,
+	)
+	return `"` + replacer.Replace(value) + `"`
+}
@@ -0,0 +1,352 @@
+package secrethub
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Errors
+var (
+	ErrInvalidK8sName               = errExport.Code("invalid_k8s_name").ErrorPref("invalid Kubernetes %s %q: it must consist of lowercase alphanumeric characters, '-' and '.' and start and end with an alphanumeric character")
+	ErrInvalidK8sSecretKey          = errExport.Code("invalid_k8s_key").ErrorPref("%s is not a valid key for a Kubernetes Secret: keys can only contain alphanumeric characters, '-', '_' and '.'")
+	ErrSealedSecretNamespaceMissing = errExport.Code("sealed_secret_namespace_missing").Error("a namespace is required to create a SealedSecret: use --namespace to set it")
+	ErrInvalidSealingCert           = errExport.Code("invalid_sealing_cert").ErrorPref("invalid sealing certificate %s: %s")
+)
+
+const (
+	k8sAnnotationSource   = "secrethub.io/source"
+	k8sAnnotationVersions = "secrethub.io/versions"
+)
+
+var (
+	k8sSecretKeyPattern = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+	k8sNamePattern      = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	k8sNamespacePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+)
+
+// ExportK8sCommand renders a Kubernetes Secret or SealedSecret manifest from the secrets in a directory.
+type ExportK8sCommand struct {
+	io         ui.IO
+	newClient  newClientFunc
+	path       api.DirPath
+	name       string
+	namespace  string
+	secretType string
+	sealCert   string
+	options    exportOptions
+}
+
+// NewExportK8sCommand creates a new ExportK8sCommand.
+func NewExportK8sCommand(io ui.IO, newClient newClientFunc) *ExportK8sCommand {
+	return &ExportK8sCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *ExportK8sCommand) Register(r command.Registerer) {
+	clause := r.Command("k8s", "Export the secrets in a directory to a Kubernetes Secret manifest.")
+	clause.HelpLong("The manifest can be applied with kubectl apply -f. " +
+		"The values are base64 encoded and the annotations " + k8sAnnotationSource + " and " + k8sAnnotationVersions + " record the exported directory and the path and version of the secret of every key.\n\n" +
+		"The key of every secret is generated with a Go template, configured with --key-template. See `secrethub export file --help` for the template syntax. " +
+		"By default, the keys are the relative paths with slashes replaced by underscores ({{ .Path | replace \"/\" \"_\" }}).\n\n" +
+		"When --seal-cert is set to the certificate of a Sealed Secrets controller (kubeseal --fetch-cert), a SealedSecret is rendered instead. " +
+		"Its values are encrypted for the namespace and name of the secret, so the manifest can safely be committed to a repository.")
+	clause.Arg("dir-path", "The path to the directory to export.").Required().PlaceHolder(optionalDirPathPlaceHolder).SetValue(&cmd.path)
+	clause.Flag("name", "The name of the Kubernetes Secret.").Required().StringVar(&cmd.name)
+	clause.Flag("namespace", "The namespace of the Kubernetes Secret. When omitted, the namespace is set by kubectl.").StringVar(&cmd.namespace)
+	clause.Flag("type", "The type of the Kubernetes Secret.").Default("Opaque").StringVar(&cmd.secretType)
+	clause.Flag("seal-cert", "Render a SealedSecret encrypted with the public key in this certificate file of a Sealed Secrets controller.").ExistingFileVar(&cmd.sealCert)
+	cmd.options.register(clause)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run exports the secrets.
+func (cmd *ExportK8sCommand) Run() error {
+	if len(cmd.name) > 253 || !k8sNamePattern.MatchString(cmd.name) {
+		return ErrInvalidK8sName("name", cmd.name)
+	}
+	if cmd.namespace != "" && (len(cmd.namespace) > 63 || !k8sNamespacePattern.MatchString(cmd.namespace)) {
+		return ErrInvalidK8sName("namespace", cmd.namespace)
+	}
+
+	var sealKey *rsa.PublicKey
+	if cmd.sealCert != "" {
+		if cmd.namespace == "" {
+			return ErrSealedSecretNamespaceMissing
+		}
+
+		var err error
+		sealKey, err = readSealingKey(cmd.sealCert)
+		if err != nil {
+			return err
+		}
+	}
+
+	text := cmd.options.keyTemplate
+	if text == "" {
+		text = `{{ .Path | replace "/" "_" }}`
+	}
+	keyTemplate, err := parseExportKeyTemplate(text)
+	if err != nil {
+		return err
+	}
+
+	confirmed, err := cmd.options.confirmOverwrite(cmd.io)
+	if err != nil || !confirmed {
+		return err
+	}
+
+	secrets, err := cmd.options.fetchSecrets(cmd.newClient, cmd.path)
+	if err != nil {
+		return err
+	}
+
+	keys, err := exportKeys(keyTemplate, secrets)
+	if err != nil {
+		return err
+	}
+
+	manifest := newK8sSecret(cmd.name, cmd.namespace, cmd.secretType, cmd.path.Value(), keys, secrets)
+
+	var out []byte
+	if sealKey != nil {
+		out, err = manifest.sealed(sealKey, rand.Reader)
+	} else {
+		out, err = manifest.secret()
+	}
+	if err != nil {
+		return err
+	}
+
+	return cmd.options.write(cmd.io, out, len(secrets))
+}
+
+// k8sSecret is a Kubernetes Secret to render.
+type k8sSecret struct {
+	name       string
+	namespace  string
+	secretType string
+	source     string
+	entries    []k8sSecretEntry
+}
+
+// k8sSecretEntry is a key of a Kubernetes Secret and the secret its value comes from.
+type k8sSecretEntry struct {
+	key    string
+	path   string
+	secret exportSecret
+}
+
+// newK8sSecret creates a Kubernetes Secret from the secrets exported from the source
+// directory, with the given keys. The entries are sorted by key.
+func newK8sSecret(name, namespace, secretType, source string, keys []string, secrets []exportSecret) *k8sSecret {
+	entries := make([]k8sSecretEntry, len(secrets))
+	for i, secret := range secrets {
+		entries[i] = k8sSecretEntry{
+			key:    keys[i],
+			path:   source + "/" + secret.path,
+			secret: secret,
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+
+	return &k8sSecret{
+		name:       name,
+		namespace:  namespace,
+		secretType: secretType,
+		source:     source,
+		entries:    entries,
+	}
+}
+
+// validate checks whether all keys can be used in a Kubernetes Secret.
+func (s *k8sSecret) validate() error {
+	for _, entry := range s.entries {
+		if len(entry.key) > 253 || !k8sSecretKeyPattern.MatchString(entry.key) {
+			return ErrInvalidK8sSecretKey(entry.key)
+		}
+	}
+	return nil
+}
+
+// annotations returns the annotations that record where the values of the Secret come from.
+func (s *k8sSecret) annotations() (map[string]string, error) {
+	versions := make(map[string]string, len(s.entries))
+	for _, entry := range s.entries {
+		versions[entry.key] = fmt.Sprintf("%s:%d", entry.path, entry.secret.version)
+	}
+	encoded, err := json.Marshal(versions)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{
+		k8sAnnotationSource:   s.source,
+		k8sAnnotationVersions: string(encoded),
+	}, nil
+}
+
+// metadata returns the metadata of the Secret, including the annotations that record where the values come from.
+func (s *k8sSecret) metadata() (yaml.MapSlice, error) {
+	annotations, err := s.annotations()
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := yaml.MapSlice{{Key: "name", Value: s.name}}
+	if s.namespace != "" {
+		metadata = append(metadata, yaml.MapItem{Key: "namespace", Value: s.namespace})
+	}
+	metadata = append(metadata, yaml.MapItem{Key: "annotations", Value: yaml.MapSlice{
+		{Key: k8sAnnotationSource, Value: annotations[k8sAnnotationSource]},
+		{Key: k8sAnnotationVersions, Value: annotations[k8sAnnotationVersions]},
+	}})
+	return metadata, nil
+}
+
+// secret renders a Secret manifest with base64 encoded values.
+func (s *k8sSecret) secret() ([]byte, error) {
+	err := s.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := s.metadata()
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(yaml.MapSlice, len(s.entries))
+	for i, entry := range s.entries {
+		data[i] = yaml.MapItem{Key: entry.key, Value: base64.StdEncoding.EncodeToString(entry.secret.data)}
+	}
+
+	return yaml.Marshal(yaml.MapSlice{
+		{Key: "apiVersion", Value: "v1"},
+		{Key: "kind", Value: "Secret"},
+		{Key: "metadata", Value: metadata},
+		{Key: "type", Value: s.secretType},
+		{Key: "data", Value: data},
+	})
+}
+
+// sealed renders a SealedSecret manifest with the values encrypted with the key
+// of a Sealed Secrets controller. The values are encrypted for the namespace and
+// name of the secret (strict scope), so they cannot be used in another secret.
+func (s *k8sSecret) sealed(key *rsa.PublicKey, random io.Reader) ([]byte, error) {
+	err := s.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := s.metadata()
+	if err != nil {
+		return nil, err
+	}
+
+	label := []byte(s.namespace + "/" + s.name)
+	data := make(yaml.MapSlice, len(s.entries))
+	for i, entry := range s.entries {
+		ciphertext, err := sealValue(key, random, entry.secret.data, label)
+		if err != nil {
+			return nil, err
+		}
+		data[i] = yaml.MapItem{Key: entry.key, Value: base64.StdEncoding.EncodeToString(ciphertext)}
+	}
+
+	return yaml.Marshal(yaml.MapSlice{
+		{Key: "apiVersion", Value: "bitnami.com/v1alpha1"},
+		{Key: "kind", Value: "SealedSecret"},
+		{Key: "metadata", Value: metadata},
+		{Key: "spec", Value: yaml.MapSlice{
+			{Key: "encryptedData", Value: data},
+			{Key: "template", Value: yaml.MapSlice{
+				{Key: "metadata", Value: metadata},
+				{Key: "type", Value: s.secretType},
+			}},
+		}},
+	})
+}
+
+// sealValue encrypts a value the way the Sealed Secrets controller expects it:
+// a random session key is encrypted with RSA-OAEP and the value is encrypted with
+// AES-GCM under the session key. The output is the length of the encrypted session
+// key as two bytes, followed by the encrypted session key and the encrypted value.
+func sealValue(key *rsa.PublicKey, random io.Reader, plaintext, label []byte) ([]byte, error) {
+	sessionKey := make([]byte, 32)
+	_, err := io.ReadFull(random, sessionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedKey, err := rsa.EncryptOAEP(sha256.New(), random, key, sessionKey, label)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 2, 2+len(encryptedKey)+len(plaintext)+gcm.Overhead())
+	binary.BigEndian.PutUint16(out, uint16(len(encryptedKey)))
+	out = append(out, encryptedKey...)
+
+	// The session key is only used once, so a zero nonce is safe.
+	nonce := make([]byte, gcm.NonceSize())
+	return gcm.Seal(out, nonce, plaintext, nil), nil
+}
+
+// readSealingKey reads the RSA public key from the PEM encoded certificate of a Sealed Secrets controller.
+func readSealingKey(path string) (*rsa.PublicKey, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, ErrCannotReadFile(path, err)
+	}
+
+	block, _ := pem.Decode(contents)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, ErrInvalidSealingCert(path, "no PEM encoded certificate found")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, ErrInvalidSealingCert(path, err)
+	}
+
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, ErrInvalidSealingCert(path, "the certificate does not contain an RSA public key")
+	}
+	return key, nil
+}
@@ -0,0 +1,104 @@
+package secrethub
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestK8sSecret(t *testing.T) {
+	secrets := []exportSecret{
+		{path: "db/password", version: 3, data: []byte("p@ssword")},
+		{path: "api-key", version: 1, data: []byte("abc123")},
+	}
+
+	cases := map[string]struct {
+		namespace string
+		keys      []string
+		expected  string
+		err       error
+	}{
+		"secret": {
+			namespace: "production",
+			keys:      []string{"db_password", "api-key"},
+			expected: "apiVersion: v1\n" +
+				"kind: Secret\n" +
+				"metadata:\n" +
+				"  name: my-secret\n" +
+				"  namespace: production\n" +
+				"  annotations:\n" +
+				"    secrethub.io/source: company/repo/app\n" +
+				"    secrethub.io/versions: '{\"api-key\":\"company/repo/app/api-key:1\",\"db_password\":\"company/repo/app/db/password:3\"}'\n" +
+				"type: Opaque\n" +
+				"data:\n" +
+				"  api-key: YWJjMTIz\n" +
+				"  db_password: cEBzc3dvcmQ=\n",
+		},
+		"without namespace": {
+			keys: []string{"db_password", "api-key"},
+			expected: "apiVersion: v1\n" +
+				"kind: Secret\n" +
+				"metadata:\n" +
+				"  name: my-secret\n" +
+				"  annotations:\n" +
+				"    secrethub.io/source: company/repo/app\n" +
+				"    secrethub.io/versions: '{\"api-key\":\"company/repo/app/api-key:1\",\"db_password\":\"company/repo/app/db/password:3\"}'\n" +
+				"type: Opaque\n" +
+				"data:\n" +
+				"  api-key: YWJjMTIz\n" +
+				"  db_password: cEBzc3dvcmQ=\n",
+		},
+		"invalid key": {
+			keys: []string{"db/password", "api-key"},
+			err:  ErrInvalidK8sSecretKey("db/password"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			secret := newK8sSecret("my-secret", tc.namespace, "Opaque", "company/repo/app", tc.keys, secrets)
+
+			actual, err := secret.secret()
+
+			assert.Equal(t, err, tc.err)
+			if tc.err == nil {
+				assert.Equal(t, string(actual), tc.expected)
+			}
+		})
+	}
+}
+
+func TestSealValue(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.OK(t, err)
+
+	label := []byte("production/my-secret")
+	ciphertext, err := sealValue(&key.PublicKey, rand.Reader, []byte("p@ssword"), label)
+	assert.OK(t, err)
+
+	// Decrypt the value the way the Sealed Secrets controller does.
+	keyLen := int(binary.BigEndian.Uint16(ciphertext))
+	sessionKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, key, ciphertext[2:2+keyLen], label)
+	assert.OK(t, err)
+
+	block, err := aes.NewCipher(sessionKey)
+	assert.OK(t, err)
+	gcm, err := cipher.NewGCM(block)
+	assert.OK(t, err)
+
+	plaintext, err := gcm.Open(nil, make([]byte, gcm.NonceSize()), ciphertext[2+keyLen:], nil)
+	assert.OK(t, err)
+	assert.Equal(t, plaintext, []byte("p@ssword"))
+
+	// A value sealed for another secret cannot be decrypted.
+	_, err = rsa.DecryptOAEP(sha256.New(), rand.Reader, key, ciphertext[2:2+keyLen], []byte("production/other-secret"))
+	if err == nil {
+		t.Error("expected decryption with another label to fail")
+	}
+}
@@ -0,0 +1,216 @@
+package secrethub
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"text/template"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// Errors
+var (
+	ErrInvalidTerraformName = errExport.Code("invalid_terraform_name").ErrorPref("%s is not a valid Terraform variable name: use --mangle-names to convert the names to valid identifiers")
+)
+
+const terraformVariablesFileName = "secrethub_variables.tf.json"
+
+var (
+	terraformIdentifier        = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+	terraformIllegalIdentChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+	// terraformReservedNames cannot be used as variable names.
+	terraformReservedNames = map[string]bool{
+		"source":     true,
+		"version":    true,
+		"providers":  true,
+		"count":      true,
+		"for_each":   true,
+		"lifecycle":  true,
+		"depends_on": true,
+		"locals":     true,
+	}
+)
+
+// ExportTerraformCommand writes the secrets in a directory to a Terraform .tfvars.json file.
+type ExportTerraformCommand struct {
+	io            ui.IO
+	newClient     newClientFunc
+	path          api.DirPath
+	sensitive     bool
+	variablesFile string
+	mangleNames   bool
+	options       exportOptions
+}
+
+// NewExportTerraformCommand creates a new ExportTerraformCommand.
+func NewExportTerraformCommand(io ui.IO, newClient newClientFunc) *ExportTerraformCommand {
+	return &ExportTerraformCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *ExportTerraformCommand) Register(r command.Registerer) {
+	clause := r.Command("terraform", "Export the secrets in a directory to a Terraform .tfvars.json file.")
+	clause.HelpLong("Every secret becomes a Terraform variable. Load the file with terraform apply -var-file=<file>.tfvars.json.\n\n" +
+		"The name of every variable is generated with a Go template, configured with --key-template. See `secrethub export file --help` for the template syntax. " +
+		"By default, the names are the relative paths with slashes replaced by underscores ({{ .Path | replace \"/\" \"_\" }}). " +
+		"Names must be valid Terraform identifiers: use --mangle-names to replace invalid characters with underscores.\n\n" +
+		"Terraform variables can only be marked as sensitive in their declaration. " +
+		"With --sensitive, the declarations of all variables are written to a separate file and marked as sensitive, so that Terraform hides their values in its output.")
+	clause.Arg("dir-path", "The path to the directory to export.").Required().PlaceHolder(optionalDirPathPlaceHolder).SetValue(&cmd.path)
+	clause.Flag("sensitive", "Write declarations that mark all variables as sensitive to the file set with --variables-file.").BoolVar(&cmd.sensitive)
+	clause.Flag("variables-file", "The file to write the variable declarations to when --sensitive is set. Defaults to "+terraformVariablesFileName+" in the directory of the output file.").StringVar(&cmd.variablesFile)
+	clause.Flag("mangle-names", "Convert the names of the variables to valid Terraform identifiers.").BoolVar(&cmd.mangleNames)
+	cmd.options.register(clause)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run exports the secrets.
+func (cmd *ExportTerraformCommand) Run() error {
+	text := cmd.options.keyTemplate
+	if text == "" {
+		text = `{{ .Path | replace "/" "_" }}`
+	}
+	keyTemplate, err := parseExportKeyTemplate(text)
+	if err != nil {
+		return err
+	}
+
+	confirmed, err := cmd.options.confirmOverwrite(cmd.io)
+	if err != nil || !confirmed {
+		return err
+	}
+
+	variablesFile := cmd.variablesFile
+	if cmd.sensitive {
+		if variablesFile == "" {
+			variablesFile = filepath.Join(filepath.Dir(cmd.options.outFile), terraformVariablesFileName)
+		}
+
+		confirmed, err := cmd.options.confirmOverwriteFile(cmd.io, variablesFile)
+		if err != nil || !confirmed {
+			return err
+		}
+	}
+
+	secrets, err := cmd.options.fetchSecrets(cmd.newClient, cmd.path)
+	if err != nil {
+		return err
+	}
+
+	vars, err := terraformVariables(keyTemplate, secrets, cmd.mangleNames)
+	if err != nil {
+		return err
+	}
+
+	out, err := formatTerraformVariables(vars)
+	if err != nil {
+		return err
+	}
+
+	if cmd.sensitive {
+		declarations, err := formatTerraformDeclarations(vars)
+		if err != nil {
+			return err
+		}
+
+		err = ioutil.WriteFile(variablesFile, declarations, 0644)
+		if err != nil {
+			return ErrCannotWrite(variablesFile, err)
+		}
+
+		fmt.Fprintf(cmd.io.Output(), "Wrote the variable declarations to %s.\n", variablesFile)
+	}
+
+	return cmd.options.write(cmd.io, out, len(secrets))
+}
+
+// terraformVariables returns the variables generated from the secrets by name. When mangle
+// is set, the names are converted to valid identifiers. Otherwise, invalid names are an error.
+func terraformVariables(keyTemplate *template.Template, secrets []exportSecret, mangle bool) (map[string]string, error) {
+	names, err := exportKeys(keyTemplate, secrets)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string]string, len(secrets))
+	paths := make(map[string]string, len(secrets))
+	for i, name := range names {
+		if mangle {
+			name = mangleTerraformName(name)
+		} else if !isValidTerraformName(name) {
+			return nil, ErrInvalidTerraformName(name)
+		}
+
+		// Mangling can map different names to the same identifier.
+		if other, exists := paths[name]; exists {
+			return nil, ErrDuplicateExportKey(other, secrets[i].path, name)
+		}
+		paths[name] = secrets[i].path
+		vars[name] = string(secrets[i].data)
+	}
+	return vars, nil
+}
+
+// isValidTerraformName returns whether the name can be used as a Terraform variable name.
+func isValidTerraformName(name string) bool {
+	return terraformIdentifier.MatchString(name) && !terraformReservedNames[name]
+}
+
+// mangleTerraformName converts a name into a valid Terraform identifier by replacing
+// invalid characters with underscores. Names that start with a digit get an underscore
+// prefix and reserved names get an underscore suffix.
+func mangleTerraformName(name string) string {
+	name = terraformIllegalIdentChars.ReplaceAllString(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') || name[0] == '-' {
+		name = "_" + name
+	}
+	if terraformReservedNames[name] {
+		name += "_"
+	}
+	return name
+}
+
+// formatTerraformVariables encodes the variables as a .tfvars.json file.
+func formatTerraformVariables(vars map[string]string) ([]byte, error) {
+	out, err := json.MarshalIndent(vars, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// terraformDeclaration is the JSON representation of a variable block.
+type terraformDeclaration struct {
+	Type      string `json:"type"`
+	Sensitive bool   `json:"sensitive"`
+}
+
+// formatTerraformDeclarations encodes declarations that mark all variables as sensitive as a .tf.json file.
+func formatTerraformDeclarations(vars map[string]string) ([]byte, error) {
+	declarations := make(map[string]terraformDeclaration, len(vars))
+	for name := range vars {
+		declarations[name] = terraformDeclaration{
+			Type:      "string",
+			Sensitive: true,
+		}
+	}
+
+	out, err := json.MarshalIndent(map[string]interface{}{
+		"variable": declarations,
+	}, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
@@ -0,0 +1,90 @@
+package secrethub
+
+import (
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestTerraformVariables(t *testing.T) {
+	cases := map[string]struct {
+		secrets  []exportSecret
+		mangle   bool
+		expected map[string]string
+		err      error
+	}{
+		"valid names": {
+			secrets: []exportSecret{
+				{path: "db/password", data: []byte("p@ssword")},
+				{path: "api-key", data: []byte("abc123")},
+			},
+			expected: map[string]string{
+				"db_password": "p@ssword",
+				"api-key":     "abc123",
+			},
+		},
+		"invalid name": {
+			secrets: []exportSecret{
+				{path: "db.password", data: []byte("p@ssword")},
+			},
+			err: ErrInvalidTerraformName("db.password"),
+		},
+		"reserved name": {
+			secrets: []exportSecret{
+				{path: "count", data: []byte("3")},
+			},
+			err: ErrInvalidTerraformName("count"),
+		},
+		"mangled names": {
+			secrets: []exportSecret{
+				{path: "db.password", data: []byte("p@ssword")},
+				{path: "1st-key", data: []byte("abc123")},
+				{path: "count", data: []byte("3")},
+			},
+			mangle: true,
+			expected: map[string]string{
+				"db_password": "p@ssword",
+				"_1st-key":    "abc123",
+				"count_":      "3",
+			},
+		},
+		"mangled duplicates": {
+			secrets: []exportSecret{
+				{path: "db.password", data: []byte("p@ssword")},
+				{path: "db_password", data: []byte("p@ssword")},
+			},
+			mangle: true,
+			err:    ErrDuplicateExportKey("db.password", "db_password", "db_password"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			keyTemplate, err := parseExportKeyTemplate(`{{ .Path | replace "/" "_" }}`)
+			assert.OK(t, err)
+
+			actual, err := terraformVariables(keyTemplate, tc.secrets, tc.mangle)
+
+			assert.Equal(t, err, tc.err)
+			if tc.err == nil {
+				assert.Equal(t, actual, tc.expected)
+			}
+		})
+	}
+}
+
+func TestFormatTerraformDeclarations(t *testing.T) {
+	out, err := formatTerraformDeclarations(map[string]string{
+		"db_password": "p@ssword",
+	})
+
+	assert.OK(t, err)
+	assert.Equal(t, string(out), "{\n"+
+		"    \"variable\": {\n"+
+		"        \"db_password\": {\n"+
+		"            \"type\": \"string\",\n"+
+		"            \"sensitive\": true\n"+
+		"        }\n"+
+		"    }\n"+
+		"}\n")
+}
@@ -0,0 +1,99 @@
+package secrethub
+
+import (
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestFormatExport(t *testing.T) {
+	secrets := []exportSecret{
+		{path: "db/password", data: []byte("p@ss \"word\"")},
+		{path: "db/cert", data: []byte("line 1\nline 2\n")},
+		{path: "db/user", data: []byte("postgres")},
+		{path: "api-key", data: []byte("abc123")},
+	}
+
+	cases := map[string]struct {
+		secrets     []exportSecret
+		format      string
+		keyTemplate string
+		expected    string
+		err         error
+	}{
+		"dotenv": {
+			format: exportFormatDotEnv,
+			expected: "API_KEY=abc123\n" +
+				"DB_CERT=\"line 1\\nline 2\\n\"\n" +
+				"DB_PASSWORD=\"p@ss \\\"word\\\"\"\n" +
+				"DB_USER=postgres\n",
+		},
+		"json": {
+			format: exportFormatJSON,
+			expected: "{\n" +
+				"    \"api-key\": \"abc123\",\n" +
+				"    \"db/cert\": \"line 1\\nline 2\\n\",\n" +
+				"    \"db/password\": \"p@ss \\\"word\\\"\",\n" +
+				"    \"db/user\": \"postgres\"\n" +
+				"}\n",
+		},
+		"yaml": {
+			secrets: []exportSecret{
+				{path: "db/password", data: []byte("p@ss \"word\"")},
+				{path: "api-key", data: []byte("abc123")},
+			},
+			format: exportFormatYAML,
+			expected: "api-key: abc123\n" +
+				"db/password: p@ss \"word\"\n",
+		},
+		"key template": {
+			format:      exportFormatDotEnv,
+			keyTemplate: `APP_{{ .Path | replace "/" "__" | replace "-" "_" | upper }}`,
+			expected: "APP_API_KEY=abc123\n" +
+				"APP_DB__CERT=\"line 1\\nline 2\\n\"\n" +
+				"APP_DB__PASSWORD=\"p@ss \\\"word\\\"\"\n" +
+				"APP_DB__USER=postgres\n",
+		},
+		"duplicate keys": {
+			secrets:     secrets[:3],
+			format:      exportFormatJSON,
+			keyTemplate: "{{ .Dir }}",
+			err:         ErrDuplicateExportKey("db/password", "db/cert", "db"),
+		},
+		"empty key": {
+			secrets:     secrets[3:],
+			format:      exportFormatJSON,
+			keyTemplate: "{{ .Dir }}",
+			err:         ErrEmptyExportKey("api-key"),
+		},
+		"invalid dotenv key": {
+			format:      exportFormatDotEnv,
+			keyTemplate: "{{ .Name }} key",
+			err:         ErrInvalidDotEnvExportKey("api-key key"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			input := tc.secrets
+			if input == nil {
+				input = secrets
+			}
+
+			keyTemplate, err := newExportKeyTemplate(tc.format, tc.keyTemplate)
+			assert.OK(t, err)
+
+			actual, err := formatExport(tc.format, keyTemplate, input)
+
+			assert.Equal(t, err, tc.err)
+			if tc.err == nil {
+				assert.Equal(t, string(actual), tc.expected)
+			}
+		})
+	}
+}
+
+func TestNewExportKeyTemplate_InvalidFormat(t *testing.T) {
+	_, err := newExportKeyTemplate("xml", "")
+	assert.Equal(t, err, ErrInvalidExportFormat("xml"))
+}
@@ -0,0 +1,18 @@
+// +build !windows
+
+package secrethub
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileOwner returns the uid and gid of the owner of the file.
+// It returns false when the owner cannot be determined.
+func fileOwner(info os.FileInfo) (int, int, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return int(stat.Uid), int(stat.Gid), true
+}
@@ -0,0 +1,212 @@
+package secrethub
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/errio"
+)
+
+// Errors
+var (
+	errFind               = errio.Namespace("find")
+	ErrInvalidNamePattern = errFind.Code("invalid_name_pattern").ErrorPref("invalid name pattern %s: %s")
+)
+
+// FindCommand searches a directory tree for secrets that match the given criteria.
+type FindCommand struct {
+	io            ui.IO
+	path          api.DirPath
+	name          string
+	regex         string
+	updatedSince  dayDuration
+	filters       []string
+	quiet         bool
+	useTimestamps bool
+	now           func() time.Time
+	newClient     newClientFunc
+}
+
+// NewFindCommand creates a new FindCommand.
+func NewFindCommand(io ui.IO, newClient newClientFunc) *FindCommand {
+	return &FindCommand{
+		io:        io,
+		now:       time.Now,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *FindCommand) Register(r command.Registerer) {
+	clause := r.Command("find", "Find secrets in a repository or directory.")
+	clause.HelpLong("Searches a repository or directory, including all its subdirectories, for secrets that match all given criteria, " +
+		"e.g. `" + ApplicationName + " find company/app --name \"*db*\" --updated-since 30d`. " +
+		"Names are matched case-insensitively.")
+	clause.Arg("dir-path", "The path to the repository or directory to search.").Required().PlaceHolder(optionalDirPathPlaceHolder).SetValue(&cmd.path)
+	clause.Flag("name", "Only find secrets with a name that matches this glob pattern, e.g. \"*db*\".").StringVar(&cmd.name)
+	clause.Flag("regex", "Only find secrets with a name that matches this regular expression, e.g. \"^db_(user|password)$\".").StringVar(&cmd.regex)
+	clause.Flag("updated-since", "Only find secrets that got a new version within this period, e.g. 30d.").SetValue(&cmd.updatedSince)
+	clause.Flag("filter", "Only find secrets that have a label, e.g. --filter label=env=prod. Can be repeated to match multiple labels.").PlaceHolder("label=<key>=<value>").StringsVar(&cmd.filters)
+	clause.Flag("quiet", "Only print paths.").Short('q').BoolVar(&cmd.quiet)
+	registerTimestampFlag(clause).BoolVar(&cmd.useTimestamps)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run searches the tree and prints the secrets that match.
+func (cmd *FindCommand) Run() error {
+	matcher, err := newSecretMatcher(cmd.name, cmd.regex)
+	if err != nil {
+		return err
+	}
+
+	filter, err := parseLabelFilters(cmd.filters)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	tree, err := client.Dirs().GetTree(cmd.path.Value(), -1, false)
+	if err != nil {
+		return err
+	}
+	excludeTrash(tree, cmd.path.Value())
+
+	var metadata *repoMetadata
+	if len(filter) > 0 {
+		metadata, err = readRepoMetadata(client, cmd.path.Value())
+		if err != nil {
+			return err
+		}
+	}
+
+	var found []foundSecret
+	for _, secret := range secretsInDir(tree.RootDir, cmd.path.Value()) {
+		if !matcher.matches(secret.secret.Name) {
+			continue
+		}
+		if metadata != nil && !filter.matches(metadata.get(secret.path)) {
+			continue
+		}
+		found = append(found, secret)
+	}
+
+	// Fetching the latest version takes a request for every secret, so only do it when needed.
+	if cmd.updatedSince > 0 {
+		since := cmd.now().Add(-time.Duration(cmd.updatedSince))
+		updated := found[:0]
+		for _, secret := range found {
+			version, err := client.Secrets().Versions().GetWithoutData(secret.path)
+			if err != nil {
+				return err
+			}
+			if version.CreatedAt.After(since) {
+				secret.updatedAt = version.CreatedAt
+				updated = append(updated, secret)
+			}
+		}
+		found = updated
+	}
+
+	return cmd.print(cmd.io.Output(), found)
+}
+
+// print prints the paths of the secrets, with their details unless quiet is set.
+func (cmd *FindCommand) print(w io.Writer, secrets []foundSecret) error {
+	if cmd.quiet {
+		for _, secret := range secrets {
+			fmt.Fprintln(w, secret.path)
+		}
+		return nil
+	}
+
+	timeFormatter := NewTimeFormatter(cmd.useTimestamps)
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	if cmd.updatedSince > 0 {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", "PATH", "VERSIONS", "CREATED", "UPDATED")
+	} else {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", "PATH", "VERSIONS", "CREATED")
+	}
+	for _, secret := range secrets {
+		created := timeFormatter.Format(secret.secret.CreatedAt.Local())
+		if cmd.updatedSince > 0 {
+			fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", secret.path, secret.secret.VersionCount, created, timeFormatter.Format(secret.updatedAt.Local()))
+		} else {
+			fmt.Fprintf(tw, "%s\t%d\t%s\n", secret.path, secret.secret.VersionCount, created)
+		}
+	}
+	return tw.Flush()
+}
+
+// foundSecret is a secret with its full path.
+type foundSecret struct {
+	path      string
+	secret    *api.Secret
+	updatedAt time.Time
+}
+
+// secretsInDir returns all secrets in the directory and its subdirectories, sorted by path.
+func secretsInDir(dir *api.Dir, dirPath string) []foundSecret {
+	var secrets []foundSecret
+	for _, secret := range dir.Secrets {
+		secrets = append(secrets, foundSecret{path: dirPath + "/" + secret.Name, secret: secret})
+	}
+	for _, subDir := range dir.SubDirs {
+		secrets = append(secrets, secretsInDir(subDir, dirPath+"/"+subDir.Name)...)
+	}
+	sort.Slice(secrets, func(i, j int) bool {
+		return secrets[i].path < secrets[j].path
+	})
+	return secrets
+}
+
+// secretMatcher matches the names of secrets against a glob pattern and a regular expression.
+type secretMatcher struct {
+	glob  string
+	regex *regexp.Regexp
+}
+
+// newSecretMatcher creates a matcher for the given glob pattern and regular expression. Empty patterns match every name.
+func newSecretMatcher(glob, regex string) (*secretMatcher, error) {
+	matcher := &secretMatcher{glob: strings.ToLower(glob)}
+	if glob != "" {
+		_, err := path.Match(matcher.glob, "")
+		if err != nil {
+			return nil, ErrInvalidNamePattern(glob, err)
+		}
+	}
+
+	if regex != "" {
+		compiled, err := regexp.Compile("(?i)" + regex)
+		if err != nil {
+			return nil, ErrInvalidNamePattern(regex, err)
+		}
+		matcher.regex = compiled
+	}
+	return matcher, nil
+}
+
+// matches returns whether the name matches both patterns.
+func (m *secretMatcher) matches(name string) bool {
+	if m.glob != "" {
+		match, _ := path.Match(m.glob, strings.ToLower(name))
+		if !match {
+			return false
+		}
+	}
+	return m.regex == nil || m.regex.MatchString(name)
+}
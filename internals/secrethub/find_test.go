@@ -0,0 +1,156 @@
+package secrethub
+
+import (
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestSecretMatcher(t *testing.T) {
+	cases := map[string]struct {
+		glob     string
+		regex    string
+		name     string
+		expected bool
+	}{
+		"no patterns": {
+			name:     "db_password",
+			expected: true,
+		},
+		"glob": {
+			glob:     "*db*",
+			name:     "app_db_password",
+			expected: true,
+		},
+		"glob case insensitive": {
+			glob:     "*DB*",
+			name:     "app_db_password",
+			expected: true,
+		},
+		"glob no match": {
+			glob:     "db*",
+			name:     "app_db_password",
+			expected: false,
+		},
+		"regex": {
+			regex:    "^db_(user|password)$",
+			name:     "db_user",
+			expected: true,
+		},
+		"regex no match": {
+			regex:    "^db_(user|password)$",
+			name:     "db_host",
+			expected: false,
+		},
+		"glob and regex": {
+			glob:     "db_*",
+			regex:    "password$",
+			name:     "db_user",
+			expected: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			matcher, err := newSecretMatcher(tc.glob, tc.regex)
+			assert.OK(t, err)
+			assert.Equal(t, matcher.matches(tc.name), tc.expected)
+		})
+	}
+}
+
+func TestNewSecretMatcher_Invalid(t *testing.T) {
+	_, err := newSecretMatcher("[db", "")
+	assert.Equal(t, err != nil, true)
+
+	_, err = newSecretMatcher("", "(db")
+	assert.Equal(t, err != nil, true)
+}
+
+func TestFindCommand_Run(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	updated := map[string]time.Time{
+		"company/app/db_password":      now.Add(-24 * time.Hour),
+		"company/app/prod/db_user":     now.Add(-60 * 24 * time.Hour),
+		"company/app/prod/db_password": now.Add(-10 * 24 * time.Hour),
+	}
+
+	cases := map[string]struct {
+		name         string
+		updatedSince time.Duration
+		expected     string
+	}{
+		"name": {
+			name:     "db_*",
+			expected: "company/app/db_password\ncompany/app/prod/db_password\ncompany/app/prod/db_user\n",
+		},
+		"updated since": {
+			name:         "db_*",
+			updatedSince: 30 * 24 * time.Hour,
+			expected:     "company/app/db_password\ncompany/app/prod/db_password\n",
+		},
+		"no match": {
+			name:     "*token*",
+			expected: "",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			io := fakeui.NewIO(t)
+			cmd := FindCommand{
+				io:           io,
+				path:         "company/app",
+				name:         tc.name,
+				updatedSince: dayDuration(tc.updatedSince),
+				quiet:        true,
+				now: func() time.Time {
+					return now
+				},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						DirService: &fakeclient.DirService{
+							GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+								return &api.Tree{
+									RootDir: &api.Dir{
+										Name: "app",
+										Secrets: []*api.Secret{
+											{Name: "db_password"},
+											{Name: "api_key"},
+										},
+										SubDirs: []*api.Dir{
+											{
+												Name: "prod",
+												Secrets: []*api.Secret{
+													{Name: "db_user"},
+													{Name: "db_password"},
+												},
+											},
+										},
+									},
+								}, nil
+							},
+						},
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithoutDataFunc: func(path string) (*api.SecretVersion, error) {
+									return &api.SecretVersion{CreatedAt: updated[path]}, nil
+								},
+							},
+						},
+					}, nil
+				},
+			}
+
+			err := cmd.Run()
+			assert.OK(t, err)
+			assert.Equal(t, io.Out.String(), tc.expected)
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package secrethub
 
 import (
+	"strconv"
+
 	"github.com/alecthomas/kingpin"
 	"github.com/secrethub/secrethub-cli/internals/cli"
 )
@@ -17,3 +19,38 @@ func registerTimestampFlag(r FlagRegisterer) *kingpin.FlagClause {
 func registerForceFlag(r FlagRegisterer) *kingpin.FlagClause {
 	return r.Flag("force", "Ignore confirmation and fail instead of prompt for missing arguments.").Short('f')
 }
+
+func registerClearAfterFlag(r FlagRegisterer) *kingpin.FlagClause {
+	return r.Flag("clear-after", "When using --clip, clear the clipboard after this duration, e.g. 30s. The clipboard is only cleared when it still contains the copied value.").Default(defaultClearClipboardAfter.String())
+}
+
+func registerCountdownFlag(r FlagRegisterer) *cli.Flag {
+	return r.Flag("countdown", "When using --clip, show a countdown until the clipboard is cleared and wait for it. Press [ENTER] to clear the clipboard right away.")
+}
+
+// invertedBoolValue is a boolean flag value that sets its target to the inverse of the flag.
+// This allows a flag that is enabled by default, e.g. --mask-output, to be disabled with its
+// --no- form, while the zero value of the target keeps the default.
+type invertedBoolValue struct {
+	target *bool
+}
+
+// Set implements the flag.Value interface.
+func (v invertedBoolValue) Set(value string) error {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	*v.target = !b
+	return nil
+}
+
+// String implements the flag.Value interface.
+func (v invertedBoolValue) String() string {
+	return strconv.FormatBool(!*v.target)
+}
+
+// IsBoolFlag makes the flag usable without a value.
+func (v invertedBoolValue) IsBoolFlag() bool {
+	return true
+}
@@ -0,0 +1,25 @@
+package secrethub
+
+import (
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestInvertedBoolValue(t *testing.T) {
+	var disabled bool
+	value := invertedBoolValue{target: &disabled}
+	assert.Equal(t, value.String(), "true")
+
+	err := value.Set("false")
+	assert.OK(t, err)
+	assert.Equal(t, disabled, true)
+	assert.Equal(t, value.String(), "false")
+
+	err = value.Set("true")
+	assert.OK(t, err)
+	assert.Equal(t, disabled, false)
+
+	err = value.Set("maybe")
+	assert.Equal(t, err != nil, true)
+}
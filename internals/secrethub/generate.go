@@ -42,8 +42,14 @@ type GenerateSecretCommand struct {
 	lengthArg           intValue
 	charsetFlag         charsetValue
 	mins                minRuleValue
+	minDigits           int
+	minSymbols          int
+	excludeAmbiguous    bool
+	policyName          string
+	policy              *generatePolicy
 	copyToClipboard     bool
 	clearClipboardAfter time.Duration
+	countdown           bool
 	clipper             clip.Clipper
 	newClient           newClientFunc
 }
@@ -60,12 +66,23 @@ func NewGenerateSecretCommand(io ui.IO, newClient newClientFunc) *GenerateSecret
 
 // Register registers the command, arguments and flags on the provided Registerer.
 func (cmd *GenerateSecretCommand) Register(r command.Registerer) {
-	clause := r.Command("generate", "Generate a random secret.")
+	generateClause := r.Command("generate", "Generate a random secret, SSH key pair or TLS certificate.")
+	NewGenerateSSHKeyCommand(cmd.io, cmd.newClient).Register(generateClause)
+	NewGenerateCertCommand(cmd.io, cmd.newClient).Register(generateClause)
+
+	clause := generateClause.Command("secret", "Generate a random secret. This is the default generate command.")
+	clause.Default()
 	clause.Arg("secret-path", "The path to write the generated secret to").Required().PlaceHolder(secretPathPlaceHolder).StringVar(&cmd.firstArg)
 	clause.Flag("length", "The length of the generated secret. Defaults to "+strconv.Itoa(defaultLength)).PlaceHolder(strconv.Itoa(defaultLength)).Short('l').SetValue(&cmd.lengthFlag)
 	clause.Flag("min", "<charset>:<n> Ensure that the resulting password contains at least n characters from the given character set. Note that adding constraints reduces the strength of the secret. When possible, avoid any constraints.").SetValue(&cmd.mins)
-	clause.Flag("clip", "Copy the generated value to the clipboard. The clipboard is automatically cleared after "+units.HumanDuration(cmd.clearClipboardAfter)+".").Short('c').BoolVar(&cmd.copyToClipboard)
-	clause.Flag("charset", "Define the set of characters to randomly generate a password from. Options are all, alphanumeric, numeric, lowercase, uppercase, letters, symbols and human-readable. Multiple character sets can be combined by supplying them in a comma separated list. Defaults to alphanumeric.").Default("alphanumeric").HintOptions("all", "alphanumeric", "numeric", "lowercase", "uppercase", "letters", "symbols", "human-readable").SetValue(&cmd.charsetFlag)
+	clause.Flag("clip", "Copy the generated value to the clipboard. The clipboard is automatically cleared after "+units.HumanDuration(cmd.clearClipboardAfter)+", or the duration set with --clear-after.").Short('c').BoolVar(&cmd.copyToClipboard)
+	registerClearAfterFlag(clause).DurationVar(&cmd.clearClipboardAfter)
+	registerCountdownFlag(clause).BoolVar(&cmd.countdown)
+	clause.Flag("charset", "Define the set of characters to randomly generate a password from. Options are all, alphanumeric, numeric, lowercase, uppercase, letters, symbols and human-readable. Multiple character sets can be combined by supplying them in a comma separated list. Defaults to alphanumeric.").HintOptions("all", "alphanumeric", "numeric", "lowercase", "uppercase", "letters", "symbols", "human-readable").SetValue(&cmd.charsetFlag)
+	clause.Flag("min-digits", "Ensure that the resulting password contains at least this many digits. Shorthand for --min numeric:<n>.").PlaceHolder("0").IntVar(&cmd.minDigits)
+	clause.Flag("min-symbols", "Ensure that the resulting password contains at least this many symbols. Symbols are added to the character set. Shorthand for --min symbols:<n>.").PlaceHolder("0").IntVar(&cmd.minSymbols)
+	clause.Flag("exclude-ambiguous", "Exclude characters that are easily confused with each other: "+ambiguousChars).BoolVar(&cmd.excludeAmbiguous)
+	clause.Flag("policy", "Generate a password that complies with the password rules of a target system. Options are "+strings.Join(generatePolicyNames(), ", ")+". The other flags override or add to the rules of the policy.").HintOptions(generatePolicyNames()...).StringVar(&cmd.policyName)
 	clause.Flag("symbols", "Include symbols in secret.").Short('s').Hidden().SetValue(&cmd.symbolsFlag)
 	clause.Arg("rand-command", "").Hidden().StringVar(&cmd.secondArg)
 	clause.Arg("length", "").Hidden().SetValue(&cmd.lengthArg)
@@ -80,12 +97,45 @@ func (cmd *GenerateSecretCommand) before() error {
 		return err
 	}
 
-	charset := cmd.charsetFlag.v
-	if useSymbols {
-		charset = charset.Add(randchar.Symbols)
+	policy := generatePolicy{charsets: []string{"alphanumeric"}}
+	if cmd.policyName != "" {
+		policy, err = getGeneratePolicy(cmd.policyName)
+		if err != nil {
+			return err
+		}
 	}
 
-	cmd.generator, err = randchar.NewRand(charset, cmd.mins.v...)
+	if len(cmd.charsetFlag.names) > 0 {
+		policy.charsets = cmd.charsetFlag.names
+	}
+	if useSymbols || cmd.minSymbols > 0 {
+		policy.addCharset("symbols")
+	}
+	if cmd.excludeAmbiguous {
+		policy.excludeAmbiguous = true
+	}
+
+	policy.mins = append(policy.mins, cmd.mins.rules...)
+	if cmd.minDigits < 0 {
+		return ErrNegativeMinimum("min-digits")
+	}
+	if cmd.minSymbols < 0 {
+		return ErrNegativeMinimum("min-symbols")
+	}
+	if cmd.minDigits > 0 {
+		policy.mins = append(policy.mins, newMinRule("numeric", cmd.minDigits))
+	}
+	if cmd.minSymbols > 0 {
+		policy.mins = append(policy.mins, newMinRule("symbols", cmd.minSymbols))
+	}
+
+	cmd.policy = &policy
+	policy.length, err = cmd.length()
+	if err != nil {
+		return err
+	}
+
+	cmd.generator, err = policy.generator()
 	if err != nil {
 		return err
 	}
@@ -134,6 +184,9 @@ func (cmd *GenerateSecretCommand) run() error {
 	}
 
 	fmt.Fprintf(cmd.io.Output(), "A randomly generated secret has been written to %s:%d.\n", path, version.Version)
+	if cmd.policy != nil {
+		fmt.Fprintf(cmd.io.Output(), "Policy: %s\n", cmd.policy)
+	}
 
 	if cmd.copyToClipboard {
 		err = WriteClipboardAutoClear(data, cmd.clearClipboardAfter, cmd.clipper)
@@ -146,6 +199,13 @@ func (cmd *GenerateSecretCommand) run() error {
 			"The generated value has been copied to the clipboard. It will be cleared after %s.\n",
 			units.HumanDuration(cmd.clearClipboardAfter),
 		)
+
+		if cmd.countdown {
+			err = waitForClipboardClear(cmd.io, data, cmd.clearClipboardAfter, cmd.clipper)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -161,6 +221,9 @@ func (cmd *GenerateSecretCommand) length() (int, error) {
 	if cmd.lengthArg.IsSet() {
 		return cmd.lengthArg.Get(), nil
 	}
+	if cmd.policy != nil && cmd.policy.length > 0 {
+		return cmd.policy.length, nil
+	}
 	return defaultLength, nil
 }
 
@@ -195,7 +258,8 @@ func (cmd *GenerateSecretCommand) useSymbols() (bool, error) {
 }
 
 type minRuleValue struct {
-	v []randchar.Option
+	v     []randchar.Option
+	rules []minRule
 }
 
 func (ov *minRuleValue) String() string {
@@ -219,6 +283,7 @@ func (ov *minRuleValue) Set(flagValue string) error {
 	}
 
 	ov.v = append(ov.v, randchar.Min(count, charset))
+	ov.rules = append(ov.rules, minRule{charsetName: elements[0], charset: charset, count: count})
 	return nil
 }
 
@@ -227,7 +292,8 @@ func (ov *minRuleValue) IsCumulative() bool {
 }
 
 type charsetValue struct {
-	v randchar.Charset
+	v     randchar.Charset
+	names []string
 }
 
 func (cv *charsetValue) String() string {
@@ -242,6 +308,7 @@ func (cv *charsetValue) Set(flagValue string) error {
 			return ErrCouldNotFindCharSet(charsetName)
 		}
 		cv.v = cv.v.Add(charset)
+		cv.names = append(cv.names, charsetName)
 	}
 	return nil
 }
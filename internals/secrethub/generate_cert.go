@@ -0,0 +1,412 @@
+package secrethub
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+)
+
+// Errors
+var (
+	ErrCSRWithCA          = errGenerate.Code("csr_with_ca").Error("--csr cannot be combined with --ca: a certificate signing request is signed by the CA that receives it")
+	ErrInvalidValidity    = errGenerate.Code("invalid_validity").Error("the validity of the certificate must be positive")
+	ErrInvalidCAKey       = errGenerate.Code("invalid_ca_key").ErrorPref("cannot parse the private key of the CA at %s: it must be a PEM encoded PKCS#8, PKCS#1 or EC private key")
+	ErrInvalidCACert      = errGenerate.Code("invalid_ca_cert").ErrorPref("cannot parse the certificate of the CA at %s: it must be a PEM encoded certificate")
+	ErrCACannotSign       = errGenerate.Code("ca_cannot_sign").ErrorPref("the certificate at %s is not a CA certificate and cannot sign other certificates")
+	ErrCAKeyDoesNotMatch  = errGenerate.Code("ca_key_mismatch").ErrorPref("the private key at %s does not belong to the certificate of the CA")
+	ErrUnknownCertKeyType = errGenerate.Code("unknown_cert_key_type").ErrorPref("unknown key type %s: the options are ecdsa and rsa")
+)
+
+const (
+	certKeySecretName   = "key"
+	certSecretName      = "cert"
+	certChainSecretName = "chain"
+	certCSRSecretName   = "csr"
+
+	certKeyTypeECDSA = "ecdsa"
+	certKeyTypeRSA   = "rsa"
+
+	defaultCertValidity = 365 * 24 * time.Hour
+	certRSABits         = 2048
+)
+
+// GenerateCertCommand generates a private key with a certificate or certificate signing request
+// and writes them as secrets to a directory.
+type GenerateCertCommand struct {
+	io         ui.IO
+	path       api.DirPath
+	commonName string
+	sans       []string
+	validity   time.Duration
+	keyType    string
+	csr        bool
+	isCA       bool
+	caPath     string
+	newClient  newClientFunc
+}
+
+// NewGenerateCertCommand creates a new GenerateCertCommand.
+func NewGenerateCertCommand(io ui.IO, newClient newClientFunc) *GenerateCertCommand {
+	return &GenerateCertCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *GenerateCertCommand) Register(r command.Registerer) {
+	clause := r.Command("cert", "Generate a private key with a TLS certificate or certificate signing request.")
+	clause.HelpLong("Generates a private key locally and writes it to the secret " + certKeySecretName + " in the given directory, " +
+		"together with a certificate in the secret " + certSecretName + " and the certificate chain, from the certificate up to the CA, in the secret " + certChainSecretName + ". " +
+		"With --csr, a certificate signing request is written to the secret " + certCSRSecretName + " instead, to be signed by an external CA.\n" +
+		"\n" +
+		"The certificate is self-signed, unless --ca is set to a directory that was created with this command and --is-ca. " +
+		"A certificate signed by a CA never outlives the certificate of the CA.\n" +
+		"\n" +
+		"Every --san that is an IP address is added as an IP address, every --san containing an @ as an email address and every other --san as a DNS name. " +
+		"When no --san is given, the common name is used as DNS name.\n" +
+		"\n" +
+		"All values are PEM encoded, so they can be passed to most TLS servers as they are, e.g. with secrethub inject.")
+	clause.Arg("dir-path", "The path of the directory to write the key and certificate to.").Required().PlaceHolder(dirPathPlaceHolder).SetValue(&cmd.path)
+	clause.Flag("cn", "The common name of the certificate. Defaults to the name of the directory.").StringVar(&cmd.commonName)
+	clause.Flag("san", "A subject alternative name of the certificate. Can be repeated.").StringsVar(&cmd.sans)
+	clause.Flag("validity", "How long the certificate is valid, e.g. 720h.").Default(defaultCertValidity.String()).DurationVar(&cmd.validity)
+	clause.Flag("key-type", "The type of key to generate: ecdsa (P-256) or rsa (2048 bits).").Default(certKeyTypeECDSA).EnumVar(&cmd.keyType, certKeyTypeECDSA, certKeyTypeRSA)
+	clause.Flag("csr", "Generate a certificate signing request instead of a certificate.").BoolVar(&cmd.csr)
+	clause.Flag("is-ca", "Generate a CA certificate that can sign other certificates.").BoolVar(&cmd.isCA)
+	clause.Flag("ca", "The directory containing the key, cert and chain of the CA to sign the certificate with.").PlaceHolder(dirPathPlaceHolder).StringVar(&cmd.caPath)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run generates the key and certificate and writes them to SecretHub.
+func (cmd *GenerateCertCommand) Run() error {
+	if cmd.csr && cmd.caPath != "" {
+		return ErrCSRWithCA
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	var ca *certAuthority
+	if cmd.caPath != "" {
+		ca, err = readCertAuthority(client, cmd.caPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	commonName := cmd.commonName
+	if commonName == "" {
+		commonName = cmd.path.GetDirName()
+	}
+
+	options := certOptions{
+		commonName: commonName,
+		sans:       cmd.sans,
+		validity:   cmd.validity,
+		keyType:    cmd.keyType,
+		isCA:       cmd.isCA,
+	}
+
+	var bundle *certBundle
+	if cmd.csr {
+		bundle, err = generateCSR(options)
+	} else {
+		bundle, err = generateCert(options, ca, time.Now())
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, secret := range bundle.secrets() {
+		secretPath := api.JoinPaths(cmd.path.Value(), secret.name)
+		version, err := client.Secrets().Write(secretPath, secret.data)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.io.Output(), "The generated %s has been written to %s:%d.\n", secret.name, secretPath, version.Version)
+	}
+
+	if cmd.csr {
+		fmt.Fprintf(cmd.io.Output(), "\n%s", bundle.csr)
+	}
+	return nil
+}
+
+// certOptions configures a generated certificate or certificate signing request.
+type certOptions struct {
+	commonName string
+	sans       []string
+	validity   time.Duration
+	keyType    string
+	isCA       bool
+}
+
+// certAuthority is the CA that signs a certificate.
+type certAuthority struct {
+	key   crypto.Signer
+	cert  *x509.Certificate
+	chain []byte
+}
+
+// certBundle contains the PEM encoded values of a generated certificate or certificate signing request.
+type certBundle struct {
+	key   []byte
+	cert  []byte
+	chain []byte
+	csr   []byte
+}
+
+type certSecret struct {
+	name string
+	data []byte
+}
+
+// secrets returns the values of the bundle that are set, with the names of the secrets to write them to.
+func (b *certBundle) secrets() []certSecret {
+	secrets := []certSecret{{name: certKeySecretName, data: b.key}}
+	if b.csr != nil {
+		return append(secrets, certSecret{name: certCSRSecretName, data: b.csr})
+	}
+	return append(secrets,
+		certSecret{name: certSecretName, data: b.cert},
+		certSecret{name: certChainSecretName, data: b.chain},
+	)
+}
+
+// readCertAuthority reads the key, certificate and chain of a CA from the secrets in a directory.
+// When the directory has no chain, the certificate of the CA is the chain.
+func readCertAuthority(client secrethub.ClientInterface, dirPath string) (*certAuthority, error) {
+	keyPath := api.JoinPaths(dirPath, certKeySecretName)
+	key, err := client.Secrets().Versions().GetWithData(keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	certPath := api.JoinPaths(dirPath, certSecretName)
+	cert, err := client.Secrets().Versions().GetWithData(certPath)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := cert.Data
+	chainSecret, err := client.Secrets().Versions().GetWithData(api.JoinPaths(dirPath, certChainSecretName))
+	if err == nil {
+		chain = chainSecret.Data
+	} else if err != api.ErrSecretNotFound {
+		return nil, err
+	}
+
+	return parseCertAuthority(key.Data, keyPath, cert.Data, certPath, chain)
+}
+
+// parseCertAuthority parses the PEM encoded key and certificate of a CA and checks that they can sign certificates.
+func parseCertAuthority(key []byte, keyPath string, cert []byte, certPath string, chain []byte) (*certAuthority, error) {
+	signer, err := parsePEMPrivateKey(key)
+	if err != nil {
+		return nil, ErrInvalidCAKey(keyPath)
+	}
+
+	block, _ := pem.Decode(cert)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, ErrInvalidCACert(certPath)
+	}
+	parsed, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, ErrInvalidCACert(certPath)
+	}
+
+	if !parsed.IsCA {
+		return nil, ErrCACannotSign(certPath)
+	}
+
+	public, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	if string(public) != string(parsed.RawSubjectPublicKeyInfo) {
+		return nil, ErrCAKeyDoesNotMatch(keyPath)
+	}
+
+	return &certAuthority{
+		key:   signer,
+		cert:  parsed,
+		chain: chain,
+	}, nil
+}
+
+// parsePEMPrivateKey parses a PEM encoded PKCS#8, PKCS#1 or EC private key.
+func parsePEMPrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	}
+
+	rsaKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err == nil {
+		return rsaKey, nil
+	}
+
+	return x509.ParseECPrivateKey(block.Bytes)
+}
+
+// generateCertKey generates a private key of the given type and returns it with its PKCS#8 PEM encoding.
+func generateCertKey(keyType string) (crypto.Signer, []byte, error) {
+	var key crypto.Signer
+	var err error
+	switch keyType {
+	case certKeyTypeECDSA:
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case certKeyTypeRSA:
+		key, err = rsa.GenerateKey(rand.Reader, certRSABits)
+	default:
+		return nil, nil, ErrUnknownCertKeyType(keyType)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// certSubject returns the subject and subject alternative names of a certificate.
+func certSubject(options certOptions) (pkix.Name, []string, []net.IP, []string) {
+	sans := options.sans
+	if len(sans) == 0 {
+		sans = []string{options.commonName}
+	}
+
+	var dnsNames, emails []string
+	var ips []net.IP
+	for _, san := range sans {
+		if ip := net.ParseIP(san); ip != nil {
+			ips = append(ips, ip)
+		} else if strings.Contains(san, "@") {
+			emails = append(emails, san)
+		} else {
+			dnsNames = append(dnsNames, san)
+		}
+	}
+	return pkix.Name{CommonName: options.commonName}, dnsNames, ips, emails
+}
+
+// generateCert generates a key and a certificate, signed by the CA or self-signed when the CA is nil.
+func generateCert(options certOptions, ca *certAuthority, now time.Time) (*certBundle, error) {
+	if options.validity <= 0 {
+		return nil, ErrInvalidValidity
+	}
+
+	key, keyPEM, err := generateCertKey(options.keyType)
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	subject, dnsNames, ips, emails := certSubject(options)
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               subject,
+		DNSNames:              dnsNames,
+		IPAddresses:           ips,
+		EmailAddresses:        emails,
+		NotBefore:             now.Add(-5 * time.Minute),
+		NotAfter:              now.Add(options.validity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+	if _, ok := key.(*rsa.PrivateKey); ok {
+		template.KeyUsage |= x509.KeyUsageKeyEncipherment
+	}
+	if options.isCA {
+		template.IsCA = true
+		template.KeyUsage |= x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	}
+
+	parent, signer := template, key
+	if ca != nil {
+		parent, signer = ca.cert, ca.key
+		if template.NotAfter.After(ca.cert.NotAfter) {
+			template.NotAfter = ca.cert.NotAfter
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), signer)
+	if err != nil {
+		return nil, err
+	}
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	chain := cert
+	if ca != nil {
+		chain = append(append([]byte{}, cert...), ca.chain...)
+	}
+
+	return &certBundle{
+		key:   keyPEM,
+		cert:  cert,
+		chain: chain,
+	}, nil
+}
+
+// generateCSR generates a key and a certificate signing request.
+func generateCSR(options certOptions) (*certBundle, error) {
+	key, keyPEM, err := generateCertKey(options.keyType)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, dnsNames, ips, emails := certSubject(options)
+	template := &x509.CertificateRequest{
+		Subject:        subject,
+		DNSNames:       dnsNames,
+		IPAddresses:    ips,
+		EmailAddresses: emails,
+	}
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &certBundle{
+		key: keyPEM,
+		csr: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}),
+	}, nil
+}
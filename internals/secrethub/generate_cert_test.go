@@ -0,0 +1,90 @@
+package secrethub
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func parsePEMCert(t *testing.T, data []byte) *x509.Certificate {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		t.Fatal("no PEM data found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	assert.OK(t, err)
+	return cert
+}
+
+func TestGenerateCert(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	caBundle, err := generateCert(certOptions{
+		commonName: "Internal CA",
+		validity:   48 * time.Hour,
+		keyType:    certKeyTypeECDSA,
+		isCA:       true,
+	}, nil, now)
+	assert.OK(t, err)
+	assert.Equal(t, caBundle.chain, caBundle.cert)
+
+	ca, err := parseCertAuthority(caBundle.key, "ca/key", caBundle.cert, "ca/cert", caBundle.chain)
+	assert.OK(t, err)
+
+	bundle, err := generateCert(certOptions{
+		commonName: "api",
+		sans:       []string{"api.internal", "10.0.0.1", "ops@example.com"},
+		validity:   72 * time.Hour,
+		keyType:    certKeyTypeRSA,
+	}, ca, now)
+	assert.OK(t, err)
+
+	cert := parsePEMCert(t, bundle.cert)
+	assert.Equal(t, cert.Subject.CommonName, "api")
+	assert.Equal(t, cert.DNSNames, []string{"api.internal"})
+	assert.Equal(t, cert.IPAddresses[0].Equal(net.ParseIP("10.0.0.1")), true)
+	assert.Equal(t, cert.EmailAddresses, []string{"ops@example.com"})
+	assert.Equal(t, cert.IsCA, false)
+	// The certificate does not outlive the CA.
+	assert.Equal(t, cert.NotAfter, ca.cert.NotAfter)
+	assert.Equal(t, string(bundle.chain), string(bundle.cert)+string(caBundle.cert))
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	_, err = cert.Verify(x509.VerifyOptions{DNSName: "api.internal", Roots: roots, CurrentTime: now.Add(time.Hour)})
+	assert.OK(t, err)
+
+	_, err = parseCertAuthority(caBundle.key, "ca/key", bundle.cert, "ca/cert", nil)
+	assert.Equal(t, err, ErrCACannotSign("ca/cert"))
+
+	_, err = parseCertAuthority(bundle.key, "ca/key", caBundle.cert, "ca/cert", nil)
+	assert.Equal(t, err, ErrCAKeyDoesNotMatch("ca/key"))
+
+	_, err = generateCert(certOptions{commonName: "api", keyType: certKeyTypeECDSA}, nil, now)
+	assert.Equal(t, err, ErrInvalidValidity)
+}
+
+func TestGenerateCSR(t *testing.T) {
+	bundle, err := generateCSR(certOptions{
+		commonName: "db.internal",
+		keyType:    certKeyTypeECDSA,
+	})
+	assert.OK(t, err)
+
+	block, _ := pem.Decode(bundle.csr)
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	assert.OK(t, err)
+	assert.OK(t, csr.CheckSignature())
+	assert.Equal(t, csr.Subject.CommonName, "db.internal")
+	assert.Equal(t, csr.DNSNames, []string{"db.internal"})
+
+	names := []string{}
+	for _, secret := range bundle.secrets() {
+		names = append(names, secret.name)
+	}
+	assert.Equal(t, names, []string{certKeySecretName, certCSRSecretName})
+}
@@ -0,0 +1,186 @@
+package secrethub
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/secrethub/secrethub-go/pkg/randchar"
+)
+
+// Errors
+var (
+	ErrUnknownGeneratePolicy = errGenerate.Code("unknown_policy").ErrorPref("unknown policy %s, choose one of: %s")
+	ErrNegativeMinimum       = errGenerate.Code("negative_min").ErrorPref("--%s cannot be negative")
+	ErrMinimumsExceedLength  = errGenerate.Code("min_exceeds_length").ErrorPref("the policy requires at least %d characters, which is more than the length %d")
+	ErrEmptyCharset          = errGenerate.Code("empty_charset").Error("no characters are left to generate a secret from after excluding the ambiguous characters")
+)
+
+// ambiguousChars are characters that are easily confused with each other
+// when a secret is read or typed by a human.
+const ambiguousChars = "0O1Il|`'\""
+
+// generatePolicy is a set of rules a generated secret complies with.
+type generatePolicy struct {
+	name             string
+	length           int
+	charsets         []string
+	mins             []minRule
+	excludeAmbiguous bool
+}
+
+// minRule requires a minimum number of characters of a charset.
+type minRule struct {
+	charsetName string
+	charset     randchar.Charset
+	count       int
+}
+
+// generatePolicies are presets that comply with the password rules of common target systems.
+var generatePolicies = map[string]generatePolicy{
+	"pin": {
+		length:   6,
+		charsets: []string{"numeric"},
+	},
+	"url-safe": {
+		length:   32,
+		charsets: []string{"alphanumeric"},
+	},
+	"human-readable": {
+		length:           16,
+		charsets:         []string{"alphanumeric"},
+		excludeAmbiguous: true,
+	},
+	// aws-iam complies with an IAM password policy that requires all character types.
+	"aws-iam": {
+		length:   32,
+		charsets: []string{"alphanumeric", "symbols"},
+		mins:     requireAll("lowercase", "uppercase", "numeric", "symbols"),
+	},
+	// active-directory complies with the Active Directory complexity requirements.
+	"active-directory": {
+		length:   24,
+		charsets: []string{"alphanumeric", "symbols"},
+		mins:     requireAll("lowercase", "uppercase", "numeric", "symbols"),
+	},
+	// mysql complies with the MEDIUM and STRONG password validation policies of MySQL.
+	"mysql": {
+		length:   24,
+		charsets: []string{"alphanumeric", "symbols"},
+		mins:     requireAll("lowercase", "uppercase", "numeric", "symbols"),
+	},
+}
+
+// newMinRule returns a rule that requires at least count characters of the charset with the given name.
+func newMinRule(charsetName string, count int) minRule {
+	charset, _ := randchar.CharsetByName(charsetName)
+	return minRule{charsetName: charsetName, charset: charset, count: count}
+}
+
+// requireAll returns rules that require at least one character of each of the given charsets.
+func requireAll(charsetNames ...string) []minRule {
+	rules := make([]minRule, len(charsetNames))
+	for i, name := range charsetNames {
+		rules[i] = newMinRule(name, 1)
+	}
+	return rules
+}
+
+// generatePolicyNames returns the names of all policy presets in alphabetical order.
+func generatePolicyNames() []string {
+	names := make([]string, 0, len(generatePolicies))
+	for name := range generatePolicies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// getGeneratePolicy returns a copy of the policy preset with the given name.
+func getGeneratePolicy(name string) (generatePolicy, error) {
+	policy, ok := generatePolicies[name]
+	if !ok {
+		return generatePolicy{}, ErrUnknownGeneratePolicy(name, strings.Join(generatePolicyNames(), ", "))
+	}
+	policy.name = name
+	policy.mins = append([]minRule{}, policy.mins...)
+	return policy, nil
+}
+
+// addCharset adds the charset with the given name to the policy, unless it is already included.
+func (p *generatePolicy) addCharset(name string) {
+	for _, charset := range p.charsets {
+		if charset == name || charset == "all" {
+			return
+		}
+	}
+	p.charsets = append(p.charsets, name)
+}
+
+// generator returns a generator for secrets that comply with the policy.
+func (p generatePolicy) generator() (randchar.Generator, error) {
+	var charset randchar.Charset
+	for _, name := range p.charsets {
+		set, ok := randchar.CharsetByName(name)
+		if !ok {
+			return nil, ErrCouldNotFindCharSet(name)
+		}
+		charset = charset.Add(set)
+	}
+
+	ambiguous := randchar.NewCharset(ambiguousChars)
+	if p.excludeAmbiguous {
+		charset = charset.Subtract(ambiguous)
+		if len(charset) == 0 {
+			return nil, ErrEmptyCharset
+		}
+	}
+
+	total := 0
+	options := make([]randchar.Option, len(p.mins))
+	for i, min := range p.mins {
+		set := min.charset
+		if p.excludeAmbiguous {
+			set = set.Subtract(ambiguous)
+		}
+		options[i] = randchar.Min(min.count, set)
+		total += min.count
+	}
+
+	if p.length > 0 && total > p.length {
+		return nil, ErrMinimumsExceedLength(total, p.length)
+	}
+
+	return randchar.NewRand(charset, options...)
+}
+
+// String returns a description of the policy, e.g.
+// aws-iam (length 32, alphanumeric,symbols, at least 1 lowercase and 1 uppercase).
+func (p generatePolicy) String() string {
+	rules := []string{
+		"length " + strconv.Itoa(p.length),
+		strings.Join(p.charsets, ","),
+	}
+
+	if len(p.mins) > 0 {
+		mins := make([]string, len(p.mins))
+		for i, min := range p.mins {
+			mins[i] = strconv.Itoa(min.count) + " " + min.charsetName
+		}
+		last := len(mins) - 1
+		if last > 0 {
+			mins = append(mins[:last-1], mins[last-1]+" and "+mins[last])
+		}
+		rules = append(rules, "at least "+strings.Join(mins, ", "))
+	}
+
+	if p.excludeAmbiguous {
+		rules = append(rules, "no ambiguous characters")
+	}
+
+	description := strings.Join(rules, ", ")
+	if p.name == "" {
+		return description
+	}
+	return p.name + " (" + description + ")"
+}
@@ -0,0 +1,111 @@
+package secrethub
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestGeneratePolicy_String(t *testing.T) {
+	cases := map[string]struct {
+		policy   generatePolicy
+		expected string
+	}{
+		"default": {
+			policy:   generatePolicy{length: 22, charsets: []string{"alphanumeric"}},
+			expected: "length 22, alphanumeric",
+		},
+		"one min": {
+			policy:   generatePolicy{length: 22, charsets: []string{"alphanumeric"}, mins: []minRule{newMinRule("numeric", 3)}},
+			expected: "length 22, alphanumeric, at least 3 numeric",
+		},
+		"preset": {
+			policy: func() generatePolicy {
+				policy, _ := getGeneratePolicy("aws-iam")
+				return policy
+			}(),
+			expected: "aws-iam (length 32, alphanumeric,symbols, at least 1 lowercase, 1 uppercase, 1 numeric and 1 symbols)",
+		},
+		"exclude ambiguous": {
+			policy:   generatePolicy{length: 16, charsets: []string{"alphanumeric"}, excludeAmbiguous: true},
+			expected: "length 16, alphanumeric, no ambiguous characters",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.policy.String(), tc.expected)
+		})
+	}
+}
+
+func TestGetGeneratePolicy(t *testing.T) {
+	_, err := getGeneratePolicy("unknown")
+	assert.Equal(t, err, ErrUnknownGeneratePolicy("unknown", "active-directory, aws-iam, human-readable, mysql, pin, url-safe"))
+
+	// Changing the returned policy does not change the preset.
+	policy, err := getGeneratePolicy("mysql")
+	assert.OK(t, err)
+	policy.mins[0].count = 10
+	assert.Equal(t, generatePolicies["mysql"].mins[0].count, 1)
+}
+
+func TestGenerateSecretCommand_before(t *testing.T) {
+	cases := map[string]struct {
+		cmd      GenerateSecretCommand
+		expected string
+		err      error
+	}{
+		"default": {
+			cmd:      GenerateSecretCommand{},
+			expected: "length 22, alphanumeric",
+		},
+		"policy": {
+			cmd:      GenerateSecretCommand{policyName: "pin"},
+			expected: "pin (length 6, numeric)",
+		},
+		"policy with overrides": {
+			cmd:      GenerateSecretCommand{policyName: "pin", lengthFlag: newIntValue(8), minSymbols: 2},
+			expected: "pin (length 8, numeric,symbols, at least 2 symbols)",
+		},
+		"min digits": {
+			cmd:      GenerateSecretCommand{minDigits: 4, excludeAmbiguous: true},
+			expected: "length 22, alphanumeric, at least 4 numeric, no ambiguous characters",
+		},
+		"negative min": {
+			cmd: GenerateSecretCommand{minDigits: -1},
+			err: ErrNegativeMinimum("min-digits"),
+		},
+		"mins exceed length": {
+			cmd: GenerateSecretCommand{policyName: "mysql", lengthFlag: newIntValue(3)},
+			err: ErrMinimumsExceedLength(4, 3),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.cmd.before()
+
+			assert.Equal(t, err, tc.err)
+			if tc.err == nil {
+				assert.Equal(t, tc.cmd.policy.String(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestGeneratePolicy_generator(t *testing.T) {
+	policy, err := getGeneratePolicy("human-readable")
+	assert.OK(t, err)
+	policy.mins = append(policy.mins, newMinRule("numeric", 8))
+
+	generator, err := policy.generator()
+	assert.OK(t, err)
+
+	for i := 0; i < 20; i++ {
+		secret, err := generator.Generate(policy.length)
+		assert.OK(t, err)
+		assert.Equal(t, strings.ContainsAny(string(secret), ambiguousChars), false)
+	}
+}
@@ -0,0 +1,226 @@
+package secrethub
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Errors
+var (
+	ErrInvalidSSHKeyBits = errGenerate.Code("invalid_ssh_key_bits").ErrorPref("%d bits are not supported for %s keys, choose one of: %s")
+)
+
+const (
+	sshKeyTypeEd25519 = "ed25519"
+	sshKeyTypeRSA     = "rsa"
+	sshKeyTypeECDSA   = "ecdsa"
+
+	defaultRSABits   = 4096
+	defaultECDSABits = 256
+
+	// sshPublicKeySuffix is appended to the path of the private key to get the path of the public key.
+	sshPublicKeySuffix = ".pub"
+)
+
+// GenerateSSHKeyCommand generates an SSH key pair and stores the private key as a secret.
+type GenerateSSHKeyCommand struct {
+	io           ui.IO
+	path         api.SecretPath
+	keyType      string
+	bits         int
+	comment      string
+	publicKeyOut string
+	newClient    newClientFunc
+}
+
+// NewGenerateSSHKeyCommand creates a new GenerateSSHKeyCommand.
+func NewGenerateSSHKeyCommand(io ui.IO, newClient newClientFunc) *GenerateSSHKeyCommand {
+	return &GenerateSSHKeyCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *GenerateSSHKeyCommand) Register(r command.Registerer) {
+	clause := r.Command("ssh-key", "Generate an SSH key pair and write the private key to a secret.")
+	clause.HelpLong("The key pair is generated locally and the private key is only written to SecretHub, so it never touches the disk unencrypted. " +
+		"The public key is written to the secret <secret-path>" + sshPublicKeySuffix + " and printed, so that it can be added as e.g. a deploy key.")
+	clause.Arg("secret-path", "The path to write the private key to.").Required().PlaceHolder(secretPathPlaceHolder).SetValue(&cmd.path)
+	clause.Flag("type", "The type of key to generate: ed25519, rsa or ecdsa.").Default(sshKeyTypeEd25519).EnumVar(&cmd.keyType, sshKeyTypeEd25519, sshKeyTypeRSA, sshKeyTypeECDSA)
+	clause.Flag("bits", "The size of rsa keys (2048, 3072 or 4096) or ecdsa keys (256, 384 or 521). Defaults to 4096 for rsa and 256 for ecdsa.").IntVar(&cmd.bits)
+	clause.Flag("comment", "The comment of the public key. Defaults to the secret path.").StringVar(&cmd.comment)
+	clause.Flag("public-key-out", "Also write the public key to this file.").StringVar(&cmd.publicKeyOut)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run generates the key pair and writes it to SecretHub.
+func (cmd *GenerateSSHKeyCommand) Run() error {
+	if cmd.path.HasVersion() {
+		return errCannotWriteToVersion
+	}
+
+	comment := cmd.comment
+	if comment == "" {
+		comment = cmd.path.Value()
+	}
+
+	privateKey, publicKey, err := generateSSHKey(cmd.keyType, cmd.bits, comment)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	privateVersion, err := client.Secrets().Write(cmd.path.Value(), privateKey)
+	if err != nil {
+		return err
+	}
+
+	publicPath := cmd.path.Value() + sshPublicKeySuffix
+	publicVersion, err := client.Secrets().Write(publicPath, publicKey)
+	if err != nil {
+		return err
+	}
+
+	if cmd.publicKeyOut != "" {
+		err = ioutil.WriteFile(cmd.publicKeyOut, publicKey, 0644)
+		if err != nil {
+			return ErrCannotWrite(cmd.publicKeyOut, err)
+		}
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "An %s key pair has been generated. The private key has been written to %s:%d and the public key to %s:%d:\n\n%s",
+		cmd.keyType, cmd.path, privateVersion.Version, publicPath, publicVersion.Version, publicKey)
+	return nil
+}
+
+// generateSSHKey generates a key pair and returns the private key in PEM format,
+// as read by OpenSSH, and the public key in the authorized_keys format.
+func generateSSHKey(keyType string, bits int, comment string) ([]byte, []byte, error) {
+	var private crypto.Signer
+	var privatePEM *pem.Block
+
+	switch keyType {
+	case sshKeyTypeRSA:
+		if bits == 0 {
+			bits = defaultRSABits
+		}
+		if bits != 2048 && bits != 3072 && bits != 4096 {
+			return nil, nil, ErrInvalidSSHKeyBits(bits, keyType, "2048, 3072, 4096")
+		}
+		key, err := rsa.GenerateKey(rand.Reader, bits)
+		if err != nil {
+			return nil, nil, err
+		}
+		private = key
+		privatePEM = &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	case sshKeyTypeECDSA:
+		if bits == 0 {
+			bits = defaultECDSABits
+		}
+		curves := map[int]elliptic.Curve{256: elliptic.P256(), 384: elliptic.P384(), 521: elliptic.P521()}
+		curve, ok := curves[bits]
+		if !ok {
+			return nil, nil, ErrInvalidSSHKeyBits(bits, keyType, "256, 384, 521")
+		}
+		key, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		der, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		private = key
+		privatePEM = &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}
+	default:
+		if bits != 0 {
+			return nil, nil, ErrInvalidSSHKeyBits(bits, keyType, "none, ed25519 keys have a fixed size")
+		}
+		public, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		private = key
+		privatePEM, err = marshalOpenSSHEd25519Key(public, key, comment)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	public, err := ssh.NewPublicKey(private.Public())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	authorizedKey := strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(public)), "\n")
+	if comment != "" {
+		authorizedKey += " " + comment
+	}
+
+	return pem.EncodeToMemory(privatePEM), []byte(authorizedKey + "\n"), nil
+}
+
+// marshalOpenSSHEd25519Key encodes an ed25519 private key in the unencrypted openssh-key-v1 format,
+// which is the only format OpenSSH reads ed25519 keys from.
+func marshalOpenSSHEd25519Key(public ed25519.PublicKey, private ed25519.PrivateKey, comment string) (*pem.Block, error) {
+	publicKey := sshString(nil, []byte(ssh.KeyAlgoED25519))
+	publicKey = sshString(publicKey, public)
+
+	// The check integers are used to verify the decryption of encrypted keys.
+	check := make([]byte, 4)
+	_, err := rand.Read(check)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKeys := append(append([]byte{}, check...), check...)
+	privateKeys = sshString(privateKeys, []byte(ssh.KeyAlgoED25519))
+	privateKeys = sshString(privateKeys, public)
+	privateKeys = sshString(privateKeys, private)
+	privateKeys = sshString(privateKeys, []byte(comment))
+	for i := byte(1); len(privateKeys)%8 != 0; i++ {
+		privateKeys = append(privateKeys, i)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("openssh-key-v1\x00")
+	buf.Write(sshString(nil, []byte("none")))
+	buf.Write(sshString(nil, []byte("none")))
+	buf.Write(sshString(nil, nil))
+	_ = binary.Write(&buf, binary.BigEndian, uint32(1))
+	buf.Write(sshString(nil, publicKey))
+	buf.Write(sshString(nil, privateKeys))
+
+	return &pem.Block{Type: "OPENSSH PRIVATE KEY", Bytes: buf.Bytes()}, nil
+}
+
+// sshString appends the value to b as an SSH wire format string, prefixed with its length.
+func sshString(b []byte, value []byte) []byte {
+	length := make([]byte, 4)
+	binary.BigEndian.PutUint32(length, uint32(len(value)))
+	return append(append(b, length...), value...)
+}
@@ -0,0 +1,220 @@
+package secrethub
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/errio"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+)
+
+// Errors
+var (
+	errGitCredential        = errio.Namespace("git_credential")
+	ErrInvalidGitCredential = errGitCredential.Code("invalid_input").ErrorPref("cannot parse the credential description on line %d: expected key=value")
+	ErrAmbiguousGitUsername = errGitCredential.Code("ambiguous_username").ErrorPref("%s contains the credentials of multiple users: set the username in the URL of the git remote or with git config credential.username")
+)
+
+const (
+	gitCredentialGet   = "get"
+	gitCredentialStore = "store"
+	gitCredentialErase = "erase"
+)
+
+// GitCredentialCommand implements the git credential helper protocol,
+// serving the tokens of git hosts from secrets in a directory.
+type GitCredentialCommand struct {
+	io        ui.IO
+	action    string
+	path      api.DirPath
+	newClient newClientFunc
+}
+
+// NewGitCredentialCommand creates a new GitCredentialCommand.
+func NewGitCredentialCommand(io ui.IO, newClient newClientFunc) *GitCredentialCommand {
+	return &GitCredentialCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *GitCredentialCommand) Register(r command.Registerer) {
+	clause := r.Command("git-credential", "Serve the credentials of git hosts from SecretHub.")
+	clause.HelpLong("This command is meant to be invoked by git. Configure it as a credential helper instead of storing " +
+		"tokens in plaintext in ~/.git-credentials:\n\n" +
+		"  git config --global credential.helper '!secrethub git-credential --path my-org/my-repo/git'\n\n" +
+		"The password or token of a user on a host is read from the secret <path>/<host>/<username>, " +
+		"e.g. my-org/my-repo/git/github.com/dev1. A colon in the host is replaced by an underscore. " +
+		"Characters that cannot be used in secret names are encoded in the username as an underscore followed by their hexadecimal value, " +
+		"e.g. dev1@example.com is stored as dev1_40example.com and an underscore itself as _5f. " +
+		"When the username is not known, the host directory must contain a single secret, which is then used as the username. " +
+		"Credentials are only served over https.\n\n" +
+		"When git stores credentials you entered, they are written to SecretHub. " +
+		"Rejected credentials are never deleted, as a failed login can be temporary: use secrethub rm to remove them.")
+	clause.Arg("action", "The action git requests: get, store or erase.").Required().HintOptions(gitCredentialGet, gitCredentialStore, gitCredentialErase).EnumVar(&cmd.action, gitCredentialGet, gitCredentialStore, gitCredentialErase)
+	clause.Flag("path", "The directory containing a directory with the credentials of every host.").Required().PlaceHolder(dirPathPlaceHolder).SetValue(&cmd.path)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run performs the requested action with the credential description provided by git.
+func (cmd *GitCredentialCommand) Run() error {
+	credential, err := readGitCredential(cmd.io.Input())
+	if err != nil {
+		return err
+	}
+
+	// Git also asks helpers for other protocols, e.g. for certificate passwords.
+	// These are not served and tokens are never sent in plaintext.
+	if credential["protocol"] != "https" || credential["host"] == "" {
+		return nil
+	}
+
+	switch cmd.action {
+	case gitCredentialGet:
+		client, err := cmd.newClient()
+		if err != nil {
+			return err
+		}
+
+		username, password, err := getGitCredential(client, cmd.path, credential["host"], credential["username"])
+		if err != nil || password == "" {
+			return err
+		}
+		fmt.Fprintf(cmd.io.Output(), "username=%s\npassword=%s\n", username, password)
+	case gitCredentialStore:
+		if credential["username"] == "" || credential["password"] == "" {
+			return nil
+		}
+
+		client, err := cmd.newClient()
+		if err != nil {
+			return err
+		}
+		return storeGitCredential(client, cmd.path, credential["host"], credential["username"], credential["password"])
+	}
+	return nil
+}
+
+// readGitCredential parses a credential description of key=value lines,
+// terminated by an empty line or the end of the input.
+func readGitCredential(r io.Reader) (map[string]string, error) {
+	credential := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if text == "" {
+			break
+		}
+		parts := strings.SplitN(text, "=", 2)
+		if len(parts) != 2 {
+			return nil, ErrInvalidGitCredential(line)
+		}
+		credential[parts[0]] = parts[1]
+	}
+	return credential, scanner.Err()
+}
+
+// gitHostDir returns the directory containing the credentials of the users of a host.
+func gitHostDir(dir api.DirPath, host string) string {
+	return api.JoinPaths(dir.Value(), strings.Replace(host, ":", "_", -1))
+}
+
+// gitSecretName returns the name of the secret holding the password of the user.
+// Git usernames are often email addresses, so all characters other than letters,
+// digits, dashes and dots are encoded as an underscore followed by their hexadecimal value.
+func gitSecretName(username string) string {
+	var name strings.Builder
+	for _, b := range []byte(username) {
+		if (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9') || b == '-' || b == '.' {
+			name.WriteByte(b)
+		} else {
+			name.WriteByte('_')
+			name.WriteString(hex.EncodeToString([]byte{b}))
+		}
+	}
+	return name.String()
+}
+
+// gitUsername decodes the username from the name of a secret written by gitSecretName.
+// Names that are not encoded are returned unchanged.
+func gitUsername(name string) string {
+	username := make([]byte, 0, len(name))
+	for i := 0; i < len(name); i++ {
+		if name[i] != '_' {
+			username = append(username, name[i])
+			continue
+		}
+		if i+2 >= len(name) {
+			return name
+		}
+		b, err := hex.DecodeString(name[i+1 : i+3])
+		if err != nil {
+			return name
+		}
+		username = append(username, b...)
+		i += 2
+	}
+	return string(username)
+}
+
+// getGitCredential returns the username and password for the host. When no username is
+// given, the only secret in the directory of the host is used. An empty password is
+// returned when there are no credentials for the host.
+func getGitCredential(client secrethub.ClientInterface, dir api.DirPath, host, username string) (string, string, error) {
+	hostDir := gitHostDir(dir, host)
+
+	if username == "" {
+		tree, err := client.Dirs().GetTree(hostDir, 1, false)
+		if api.IsErrNotFound(err) {
+			return "", "", nil
+		} else if err != nil {
+			return "", "", err
+		}
+
+		switch len(tree.RootDir.Secrets) {
+		case 0:
+			return "", "", nil
+		case 1:
+			username = gitUsername(tree.RootDir.Secrets[0].Name)
+		default:
+			return "", "", ErrAmbiguousGitUsername(hostDir)
+		}
+	}
+
+	secret, err := client.Secrets().Versions().GetWithData(api.JoinPaths(hostDir, gitSecretName(username)))
+	if api.IsErrNotFound(err) {
+		return "", "", nil
+	} else if err != nil {
+		return "", "", err
+	}
+	return username, strings.TrimSpace(string(secret.Data)), nil
+}
+
+// storeGitCredential writes the password of the user on the host, unless it is already stored.
+func storeGitCredential(client secrethub.ClientInterface, dir api.DirPath, host, username, password string) error {
+	_, current, err := getGitCredential(client, dir, host, username)
+	if err != nil {
+		return err
+	}
+	if current == password {
+		return nil
+	}
+
+	hostDir := gitHostDir(dir, host)
+	err = client.Dirs().CreateAll(hostDir)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Secrets().Write(api.JoinPaths(hostDir, gitSecretName(username)), []byte(password))
+	return err
+}
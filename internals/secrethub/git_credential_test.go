@@ -0,0 +1,239 @@
+package secrethub
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestReadGitCredential(t *testing.T) {
+	cases := map[string]struct {
+		in       string
+		expected map[string]string
+		err      error
+	}{
+		"description": {
+			in: "protocol=https\nhost=github.com\nusername=dev1\n\nignored\n",
+			expected: map[string]string{
+				"protocol": "https",
+				"host":     "github.com",
+				"username": "dev1",
+			},
+		},
+		"value with equals sign": {
+			in:       "password=abc=def",
+			expected: map[string]string{"password": "abc=def"},
+		},
+		"invalid": {
+			in:  "protocol=https\nhost\n",
+			err: ErrInvalidGitCredential(2),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			actual, err := readGitCredential(strings.NewReader(tc.in))
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
+
+func TestGitSecretName(t *testing.T) {
+	cases := map[string]struct {
+		username string
+		expected string
+	}{
+		"plain": {
+			username: "dev1",
+			expected: "dev1",
+		},
+		"email": {
+			username: "dev1@example.com",
+			expected: "dev1_40example.com",
+		},
+		"space and underscore": {
+			username: "Jane Doe_2",
+			expected: "Jane_20Doe_5f2",
+		},
+		"non-ascii": {
+			username: "zoë",
+			expected: "zo_c3_ab",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			actual := gitSecretName(tc.username)
+
+			// Assert
+			assert.Equal(t, actual, tc.expected)
+			assert.Equal(t, gitUsername(actual), tc.username)
+		})
+	}
+}
+
+func TestGitCredentialCommand_Run(t *testing.T) {
+	cases := map[string]struct {
+		action   string
+		in       string
+		secrets  map[string]string
+		expected map[string]string
+		out      string
+		err      error
+	}{
+		"get": {
+			action: "get",
+			in:     "protocol=https\nhost=github.com\nusername=dev1\n",
+			secrets: map[string]string{
+				"namespace/repo/git/github.com/dev1": "token1\n",
+				"namespace/repo/git/github.com/dev2": "token2\n",
+			},
+			out: "username=dev1\npassword=token1\n",
+		},
+		"get without username": {
+			action: "get",
+			in:     "protocol=https\nhost=git.example.com:8443\n",
+			secrets: map[string]string{
+				"namespace/repo/git/git.example.com_8443/dev1": "token1",
+			},
+			out: "username=dev1\npassword=token1\n",
+		},
+		"get encoded username": {
+			action: "get",
+			in:     "protocol=https\nhost=dev.azure.com\n",
+			secrets: map[string]string{
+				"namespace/repo/git/dev.azure.com/jane_20doe_40example.com": "token1",
+			},
+			out: "username=jane doe@example.com\npassword=token1\n",
+		},
+		"get ambiguous username": {
+			action: "get",
+			in:     "protocol=https\nhost=github.com\n",
+			secrets: map[string]string{
+				"namespace/repo/git/github.com/dev1": "token1",
+				"namespace/repo/git/github.com/dev2": "token2",
+			},
+			err: ErrAmbiguousGitUsername("namespace/repo/git/github.com"),
+		},
+		"get not found": {
+			action:  "get",
+			in:      "protocol=https\nhost=github.com\nusername=dev1\n",
+			secrets: map[string]string{},
+		},
+		"get over http": {
+			action: "get",
+			in:     "protocol=http\nhost=github.com\nusername=dev1\n",
+			secrets: map[string]string{
+				"namespace/repo/git/github.com/dev1": "token1",
+			},
+		},
+		"store": {
+			action:  "store",
+			in:      "protocol=https\nhost=github.com\nusername=dev1\npassword=token1\n",
+			secrets: map[string]string{},
+			expected: map[string]string{
+				"namespace/repo/git/github.com/dev1": "token1",
+			},
+		},
+		"store email": {
+			action:  "store",
+			in:      "protocol=https\nhost=dev.azure.com\nusername=jane_doe@example.com\npassword=token1\n",
+			secrets: map[string]string{},
+			expected: map[string]string{
+				"namespace/repo/git/dev.azure.com/jane_5fdoe_40example.com": "token1",
+			},
+		},
+		"store unchanged": {
+			action: "store",
+			in:     "protocol=https\nhost=github.com\nusername=dev1\npassword=token1\n",
+			secrets: map[string]string{
+				"namespace/repo/git/github.com/dev1": "token1\n",
+			},
+			expected: map[string]string{
+				"namespace/repo/git/github.com/dev1": "token1\n",
+			},
+		},
+		"erase": {
+			action: "erase",
+			in:     "protocol=https\nhost=github.com\nusername=dev1\npassword=token1\n",
+			secrets: map[string]string{
+				"namespace/repo/git/github.com/dev1": "token1",
+			},
+			expected: map[string]string{
+				"namespace/repo/git/github.com/dev1": "token1",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			io := fakeui.NewIO(t)
+			io.In.Buffer = bytes.NewBufferString(tc.in)
+
+			cmd := GitCredentialCommand{
+				io:     io,
+				action: tc.action,
+				path:   "namespace/repo/git",
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						DirService: &fakeclient.DirService{
+							GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+								dir := &api.Dir{}
+								for secretPath := range tc.secrets {
+									if strings.HasPrefix(secretPath, path+"/") {
+										dir.Secrets = append(dir.Secrets, &api.Secret{Name: secretPath[len(path)+1:]})
+									}
+								}
+								if len(dir.Secrets) == 0 {
+									return nil, api.ErrDirNotFound
+								}
+								return &api.Tree{RootDir: dir}, nil
+							},
+							CreateAllFunc: func(path string) error {
+								return nil
+							},
+						},
+						SecretService: &fakeclient.SecretService{
+							WriteFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+								tc.secrets[path] = string(data)
+								return &api.SecretVersion{}, nil
+							},
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+									data, ok := tc.secrets[path]
+									if !ok {
+										return nil, api.ErrSecretNotFound
+									}
+									return &api.SecretVersion{Data: []byte(data)}, nil
+								},
+							},
+						},
+					}, nil
+				},
+			}
+
+			// Act
+			err := cmd.Run()
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.Out.String(), tc.out)
+			if tc.expected != nil {
+				assert.Equal(t, tc.secrets, tc.expected)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package secrethub
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/fatih/color"
 	"github.com/secrethub/secrethub-go/internals/api"
@@ -28,3 +29,17 @@ func colorizeByStatus(status string, msg interface{}) interface{} {
 		return msg
 	}
 }
+
+// secretPathsInDir returns the paths of all secrets in the given directory and
+// its subdirectories, sorted alphabetically. The dirPath is used as the path of dir.
+func secretPathsInDir(dir *api.Dir, dirPath string) []string {
+	var paths []string
+	for _, secret := range dir.Secrets {
+		paths = append(paths, dirPath+"/"+secret.Name)
+	}
+	for _, subDir := range dir.SubDirs {
+		paths = append(paths, secretPathsInDir(subDir, dirPath+"/"+subDir.Name)...)
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,391 @@
+package secrethub
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/errio"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+)
+
+// Errors
+var (
+	errImport = errio.Namespace("import")
+
+	ErrNothingToImport = errImport.Code("nothing_to_import").Error("no secrets found to import")
+)
+
+// ImportCommand handles importing secrets from other secret managers.
+type ImportCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+}
+
+// NewImportCommand creates a new ImportCommand.
+func NewImportCommand(io ui.IO, newClient newClientFunc) *ImportCommand {
+	return &ImportCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command and its sub-commands on the provided Registerer.
+func (cmd *ImportCommand) Register(r command.Registerer) {
+	clause := r.Command("import", "Import secrets from other secret managers.")
+	NewImportKeePassCommand(cmd.io, cmd.newClient).Register(clause)
+	NewImportConjurCommand(cmd.io, cmd.newClient).Register(clause)
+	NewImportAnsibleVaultCommand(cmd.io, cmd.newClient).Register(clause)
+	NewImportComposeCommand(cmd.io, cmd.newClient).Register(clause)
+}
+
+// importSecret is a secret found by an importer. Its path is relative to the destination directory.
+type importSecret struct {
+	path string
+	data []byte
+}
+
+// importOptions are the flags shared by all import commands.
+type importOptions struct {
+	mappingFile string
+	dryRun      bool
+	diff        bool
+	force       bool
+}
+
+// register registers the shared flags of the import commands.
+func (o *importOptions) register(r FlagRegisterer) {
+	r.Flag("mapping", "Change the default layout of the imported secrets with a mapping file. "+
+		"A mapping file can be created with the save command of the interactive mapping editor.").ExistingFileVar(&o.mappingFile)
+	r.Flag("dry-run", "Print the directories that will be created and the secrets that will be written, overwritten or skipped, without importing anything.").BoolVar(&o.dryRun)
+	r.Flag("diff", "Compare the secrets to import with the existing secrets and print which are new, changed or unchanged. Unchanged secrets are not written again.").BoolVar(&o.diff)
+	registerForceFlag(r).BoolVar(&o.force)
+}
+
+// importSecrets writes the secrets into the destination directory. Before anything is written,
+// the layout can be changed with a mapping file or the interactive mapping editor. Missing
+// directories are created and existing secrets are only overwritten after confirmation
+// or when force is set.
+func importSecrets(io ui.IO, client secrethub.ClientInterface, dest api.DirPath, secrets []importSecret, opts importOptions) error {
+	_, err := importSecretsWithDestinations(io, client, dest, secrets, opts)
+	return err
+}
+
+// importSecretsWithDestinations imports the secrets like importSecrets and returns the full
+// paths the secrets have been imported to by their relative path. Secrets that are skipped
+// or merged with other secrets by the mapping are not included. When nothing has been
+// imported, because of a dry run or because the user aborted, it returns nil.
+func importSecretsWithDestinations(io ui.IO, client secrethub.ClientInterface, dest api.DirPath, secrets []importSecret, opts importOptions) (map[string]string, error) {
+	if len(secrets) == 0 {
+		return nil, ErrNothingToImport
+	}
+
+	mapping := &importMapping{}
+	if opts.mappingFile != "" {
+		var err error
+		mapping, err = readImportMapping(opts.mappingFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !opts.force {
+		proceed, err := editImportMapping(io, mapping, secrets)
+		if err != nil && err != ui.ErrCannotAsk {
+			return nil, err
+		}
+		if err == nil && !proceed {
+			fmt.Fprintln(io.Output(), "Aborting.")
+			return nil, nil
+		}
+	}
+
+	items, skipped := mapping.plan(secrets)
+	if len(items) == 0 {
+		return nil, ErrNothingToImport
+	}
+
+	plan, err := newImportPlan(client, dest, items, skipped)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.diff {
+		err = plan.diff(client)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.dryRun || opts.diff {
+		err = plan.print(io.Output())
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintln(io.Output())
+	}
+
+	if opts.dryRun {
+		fmt.Fprintln(io.Output(), "Dry run complete! Nothing has been imported.")
+		return nil, nil
+	}
+
+	overwrites := plan.overwrites()
+	if overwrites > 0 && !opts.force {
+		confirmed, err := ui.AskYesNo(
+			io,
+			fmt.Sprintf(
+				"%d of the %d secrets to import already exist in %s and will be overwritten. Do you want to continue?",
+				overwrites,
+				len(items),
+				dest,
+			),
+			ui.DefaultNo,
+		)
+		if err == ui.ErrCannotAsk {
+			return nil, ErrCannotDoWithoutForce
+		} else if err != nil {
+			return nil, err
+		}
+
+		if !confirmed {
+			fmt.Fprintln(io.Output(), "Aborting.")
+			return nil, nil
+		}
+	}
+
+	created := map[string]bool{}
+	imported := 0
+	for _, item := range items {
+		secretPath := api.JoinPaths(dest.Value(), item.path)
+		if plan.unchanged[strings.ToLower(secretPath)] {
+			continue
+		}
+
+		dir := path.Dir(secretPath)
+		if !created[dir] {
+			dirPath, err := api.NewDirPath(dir)
+			if err != nil {
+				return nil, err
+			}
+			if !dirPath.IsRepoPath() {
+				err = client.Dirs().CreateAll(dir)
+				if err != nil {
+					return nil, err
+				}
+			}
+			created[dir] = true
+		}
+
+		_, err = client.Secrets().Write(secretPath, item.data())
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(io.Output(), "Imported %s\n", secretPath)
+		imported++
+	}
+
+	fmt.Fprintf(io.Output(), "Import complete! %s imported into %s", pluralize("secret", "secrets", imported), dest)
+	if len(plan.unchanged) > 0 {
+		fmt.Fprintf(io.Output(), ", %d unchanged", len(plan.unchanged))
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(io.Output(), ", %d skipped", len(skipped))
+	}
+	fmt.Fprintln(io.Output(), ".")
+
+	destinations := make(map[string]string, len(items))
+	for _, item := range items {
+		if len(item.sources) == 1 {
+			destinations[item.sources[0].path] = api.JoinPaths(dest.Value(), item.path)
+		}
+	}
+	return destinations, nil
+}
+
+// importPlan describes the changes an import makes to the destination directory.
+type importPlan struct {
+	dest    api.DirPath
+	items   []importPlanItem
+	skipped []string
+	// dirs are the directories that have to be created.
+	dirs []string
+	// existing and unchanged contain the lowercased paths of the
+	// secrets that already exist and of those with the same value.
+	existing  map[string]bool
+	unchanged map[string]bool
+	diffed    bool
+}
+
+// newImportPlan compares the secrets to import with the contents of the destination directory.
+func newImportPlan(client secrethub.ClientInterface, dest api.DirPath, items []importPlanItem, skipped []string) (*importPlan, error) {
+	plan := &importPlan{
+		dest:      dest,
+		items:     items,
+		skipped:   skipped,
+		existing:  map[string]bool{},
+		unchanged: map[string]bool{},
+	}
+
+	// All paths are validated before anything is written, so an invalid name cannot leave a partial import.
+	for _, item := range items {
+		err := api.ValidateSecretPath(api.JoinPaths(dest.Value(), item.path))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	existingDirs := map[string]bool{}
+	tree, err := client.Dirs().GetTree(dest.Value(), -1, false)
+	if err == nil {
+		for _, p := range secretPathsInDir(tree.RootDir, dest.Value()) {
+			plan.existing[strings.ToLower(p)] = true
+		}
+		for _, p := range dirPathsInDir(tree.RootDir, dest.Value()) {
+			existingDirs[strings.ToLower(p)] = true
+		}
+	} else if !api.IsErrNotFound(err) {
+		return nil, err
+	}
+
+	for _, item := range items {
+		dir := path.Dir(api.JoinPaths(dest.Value(), item.path))
+		for !existingDirs[strings.ToLower(dir)] {
+			dirPath, err := api.NewDirPath(dir)
+			if err != nil {
+				return nil, err
+			}
+			if dirPath.IsRepoPath() {
+				break
+			}
+			existingDirs[strings.ToLower(dir)] = true
+			plan.dirs = append(plan.dirs, dir)
+			dir = path.Dir(dir)
+		}
+	}
+	sort.Strings(plan.dirs)
+
+	return plan, nil
+}
+
+// diff reads the existing secrets that will be overwritten to find out which of them are unchanged.
+func (p *importPlan) diff(client secrethub.ClientInterface) error {
+	for _, item := range p.items {
+		secretPath := api.JoinPaths(p.dest.Value(), item.path)
+		if !p.existing[strings.ToLower(secretPath)] {
+			continue
+		}
+
+		secret, err := client.Secrets().Versions().GetWithData(secretPath)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(secret.Data, item.data()) {
+			p.unchanged[strings.ToLower(secretPath)] = true
+		}
+	}
+	p.diffed = true
+	return nil
+}
+
+// overwrites returns the number of existing secrets that will be overwritten.
+func (p *importPlan) overwrites() int {
+	n := 0
+	for _, item := range p.items {
+		secretPath := strings.ToLower(api.JoinPaths(p.dest.Value(), item.path))
+		if p.existing[secretPath] && !p.unchanged[secretPath] {
+			n++
+		}
+	}
+	return n
+}
+
+// print writes a table with the actions the import takes. When the plan
+// has been diffed, it also shows how each secret compares to the existing secret.
+func (p *importPlan) print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	if p.diffed {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", "ACTION", "PATH", "DIFF")
+	} else {
+		fmt.Fprintf(tw, "%s\t%s\n", "ACTION", "PATH")
+	}
+
+	row := func(action, path, diff string) {
+		if p.diffed {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", action, path, diff)
+		} else {
+			fmt.Fprintf(tw, "%s\t%s\n", action, path)
+		}
+	}
+
+	for _, dir := range p.dirs {
+		row("create", dir+"/", "")
+	}
+
+	for _, item := range p.items {
+		secretPath := api.JoinPaths(p.dest.Value(), item.path)
+		key := strings.ToLower(secretPath)
+		switch {
+		case p.unchanged[key]:
+			row("keep", secretPath, "unchanged")
+		case p.existing[key]:
+			row("overwrite", secretPath, "changed")
+		default:
+			row("write", secretPath, "new")
+		}
+	}
+
+	for _, source := range p.skipped {
+		row("skip", source, "")
+	}
+
+	return tw.Flush()
+}
+
+// dirPathsInDir returns the paths of the directory and all its subdirectories.
+func dirPathsInDir(dir *api.Dir, dirPath string) []string {
+	paths := []string{dirPath}
+	for _, subDir := range dir.SubDirs {
+		paths = append(paths, dirPathsInDir(subDir, dirPath+"/"+subDir.Name)...)
+	}
+	return paths
+}
+
+// importName converts a name from another secret manager into a valid
+// secret or directory name. Unsupported characters are replaced by dashes.
+func importName(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '.' {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+	return strings.Trim(b.String(), "-.")
+}
+
+// uniqueNames makes names unique within a directory by appending a number to duplicates.
+type uniqueNames map[string]bool
+
+// add returns the given name, or the name with a numbered suffix when it is already taken.
+func (u uniqueNames) add(name string) string {
+	unique := name
+	for i := 2; u[strings.ToLower(unique)]; i++ {
+		unique = name + "-" + strconv.Itoa(i)
+	}
+	u[strings.ToLower(unique)] = true
+	return unique
+}
@@ -0,0 +1,183 @@
+package secrethub
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/secrethub/secrethub-cli/internals/ansiblevault"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"gopkg.in/yaml.v2"
+)
+
+// Errors
+var (
+	ErrAnsibleVaultInvalidYAML = errImport.Code("ansible_vault_invalid_yaml").ErrorPref("%s does not contain valid YAML: %s")
+	ErrAnsibleVaultNotMapping  = errImport.Code("ansible_vault_not_mapping").ErrorPref("%s must contain a mapping of variable names to values")
+	ErrAnsibleVaultVariable    = errImport.Code("ansible_vault_variable").ErrorPref("cannot decrypt the variable %s: %s")
+)
+
+// ImportAnsibleVaultCommand imports the variables of an Ansible Vault encrypted YAML file.
+type ImportAnsibleVaultCommand struct {
+	io           ui.IO
+	file         string
+	path         api.DirPath
+	passwordFile string
+	options      importOptions
+	newClient    newClientFunc
+}
+
+// NewImportAnsibleVaultCommand creates a new ImportAnsibleVaultCommand.
+func NewImportAnsibleVaultCommand(io ui.IO, newClient newClientFunc) *ImportAnsibleVaultCommand {
+	return &ImportAnsibleVaultCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *ImportAnsibleVaultCommand) Register(r command.Registerer) {
+	clause := r.Command("ansible-vault", "Import the variables of an Ansible Vault encrypted YAML file.")
+	clause.HelpLong("Decrypts a YAML variables file that is encrypted with ansible-vault encrypt, or that contains variables " +
+		"encrypted with ansible-vault encrypt_string (!vault), and imports the variables into the given directory. " +
+		"Nested mappings become directories and the items of a list are named by their index, " +
+		"so the variable db: {users: [{password: x}]} is imported to <dir-path>/db/users/0/password. " +
+		"Empty values are skipped.\n" +
+		"\n" +
+		"The vault password is asked for, unless --vault-password-file is set.")
+	clause.Arg("vars-file", "The path to the YAML variables file.").Required().ExistingFileVar(&cmd.file)
+	clause.Arg("dir-path", "The path of the directory to import the variables into.").Required().PlaceHolder(dirPathPlaceHolder).SetValue(&cmd.path)
+	clause.Flag("vault-password-file", "Read the vault password from this file instead of asking for it.").Envar("ANSIBLE_VAULT_PASSWORD_FILE").ExistingFileVar(&cmd.passwordFile)
+	cmd.options.register(clause)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run decrypts the variables file and imports its variables.
+func (cmd *ImportAnsibleVaultCommand) Run() error {
+	content, err := ioutil.ReadFile(cmd.file)
+	if err != nil {
+		return ErrCannotReadFile(cmd.file, err)
+	}
+
+	password, err := cmd.password()
+	if err != nil {
+		return err
+	}
+
+	secrets, err := ansibleVaultSecrets(cmd.file, content, password)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	return importSecrets(cmd.io, client, cmd.path, secrets, cmd.options)
+}
+
+// password reads the vault password from the password file or asks for it.
+func (cmd *ImportAnsibleVaultCommand) password() ([]byte, error) {
+	if cmd.passwordFile != "" {
+		content, err := ioutil.ReadFile(cmd.passwordFile)
+		if err != nil {
+			return nil, ErrCannotReadFile(cmd.passwordFile, err)
+		}
+		// Ansible strips surrounding whitespace from password files too.
+		return bytes.TrimSpace(content), nil
+	}
+
+	password, err := ui.AskSecret(cmd.io, fmt.Sprintf("What is the vault password of %s?", cmd.file))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(password), nil
+}
+
+// ansibleVaultSecrets decrypts the content of a variables file and returns the secrets to import.
+// Both fully encrypted files and files with encrypted variables are supported.
+func ansibleVaultSecrets(file string, content []byte, password []byte) ([]importSecret, error) {
+	if ansiblevault.IsEncrypted(content) {
+		var err error
+		content, err = ansiblevault.Decrypt(content, password)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// A MapSlice keeps the variables in the order of the file.
+	var vars yaml.MapSlice
+	err := yaml.Unmarshal(content, &vars)
+	if _, ok := err.(*yaml.TypeError); ok {
+		return nil, ErrAnsibleVaultNotMapping(file)
+	} else if err != nil {
+		return nil, ErrAnsibleVaultInvalidYAML(file, err)
+	}
+
+	return ansibleVariableSecrets(vars, "", "", password)
+}
+
+// ansibleVariableSecrets flattens a variable into the secrets to import. The name is
+// the dotted name of the variable, used in errors, and dir is its relative path.
+func ansibleVariableSecrets(value interface{}, name string, dir string, password []byte) ([]importSecret, error) {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		var secrets []importSecret
+		names := uniqueNames{}
+		for _, item := range v {
+			key := fmt.Sprint(item.Key)
+			secretName := importName(key)
+			if secretName == "" {
+				secretName = "unnamed"
+			}
+			sub, err := ansibleVariableSecrets(item.Value, joinAnsibleName(name, key), joinImportPath(dir, names.add(secretName)), password)
+			if err != nil {
+				return nil, err
+			}
+			secrets = append(secrets, sub...)
+		}
+		return secrets, nil
+	case []interface{}:
+		var secrets []importSecret
+		for i, item := range v {
+			index := strconv.Itoa(i)
+			sub, err := ansibleVariableSecrets(item, joinAnsibleName(name, index), joinImportPath(dir, index), password)
+			if err != nil {
+				return nil, err
+			}
+			secrets = append(secrets, sub...)
+		}
+		return secrets, nil
+	case nil:
+		return nil, nil
+	case string:
+		data := []byte(v)
+		if ansiblevault.IsEncrypted(data) {
+			var err error
+			data, err = ansiblevault.Decrypt(data, password)
+			if err != nil {
+				return nil, ErrAnsibleVaultVariable(name, err)
+			}
+		}
+		if len(bytes.TrimSpace(data)) == 0 {
+			return nil, nil
+		}
+		return []importSecret{{path: dir, data: data}}, nil
+	default:
+		return []importSecret{{path: dir, data: []byte(fmt.Sprint(v))}}, nil
+	}
+}
+
+// joinAnsibleName joins the name of a variable with the key of a nested value.
+func joinAnsibleName(name, key string) string {
+	if name == "" {
+		return key
+	}
+	return name + "." + key
+}
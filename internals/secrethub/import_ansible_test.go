@@ -0,0 +1,82 @@
+package secrethub
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/ansiblevault"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+// testAnsibleVault is "db_password: s3cr3t\n" encrypted with the password "correct horse battery staple".
+const testAnsibleVault = `$ANSIBLE_VAULT;1.1;AES256
+30303031303230333034303530363037303830393061306230633064306530663130313131323133
+3134313531363137313831393161316231633164316531660a636463643461653235326661383432
+35643062346236636335306166303864376431396562316664313564373861386532656238326335
+6139353234303132630a316333376266333333626231326530633431303535383266646534336634
+36643932636330643036646461363866646539663734316635323334656531643531
+`
+
+func TestAnsibleVaultSecrets(t *testing.T) {
+	password := []byte("correct horse battery staple")
+
+	cases := map[string]struct {
+		content  string
+		password []byte
+		expected []importSecret
+		err      error
+	}{
+		"encrypted file": {
+			content:  testAnsibleVault,
+			password: password,
+			expected: []importSecret{
+				{path: "db_password", data: []byte("s3cr3t")},
+			},
+		},
+		"encrypted variables": {
+			content: "app_user: admin\n" +
+				"app_password: !vault |\n" +
+				"  " + strings.Replace(strings.TrimSpace(testAnsibleVault), "\n", "\n  ", -1) + "\n" +
+				"db:\n" +
+				"  port: 5432\n" +
+				"  users:\n" +
+				"    - name: alice\n" +
+				"      password: ''\n" +
+				"  \"host name\": db.example.com\n" +
+				"empty:\n",
+			password: password,
+			expected: []importSecret{
+				{path: "app_user", data: []byte("admin")},
+				{path: "app_password", data: []byte("db_password: s3cr3t\n")},
+				{path: "db/port", data: []byte("5432")},
+				{path: "db/users/0/name", data: []byte("alice")},
+				{path: "db/host-name", data: []byte("db.example.com")},
+			},
+		},
+		"wrong password": {
+			content:  testAnsibleVault,
+			password: []byte("wrong"),
+			err:      ansiblevault.ErrInvalidPassword,
+		},
+		"wrong password for variable": {
+			content:  "app_password: !vault |\n  " + strings.Replace(strings.TrimSpace(testAnsibleVault), "\n", "\n  ", -1) + "\n",
+			password: []byte("wrong"),
+			err:      ErrAnsibleVaultVariable("app_password", ansiblevault.ErrInvalidPassword),
+		},
+		"not a mapping": {
+			content:  "- a\n- b\n",
+			password: password,
+			err:      ErrAnsibleVaultNotMapping("vars.yml"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := ansibleVaultSecrets("vars.yml", []byte(tc.content), tc.password)
+
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package secrethub
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"gopkg.in/yaml.v2"
+)
+
+// Errors
+var (
+	ErrComposeInvalidYAML = errImport.Code("compose_invalid_yaml").ErrorPref("%s is not a valid Docker Compose file: %s")
+	ErrComposeInvalidKey  = errImport.Code("compose_invalid_key").ErrorPref("%s is not a valid Docker Compose file: %s must be a mapping")
+)
+
+const (
+	composeOutFileName  = "docker-compose.secrethub.yml"
+	composeEnvVarPrefix = "SECRET_"
+)
+
+var composeIllegalEnvVarChars = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// ImportComposeCommand imports the environment variables and file-based secrets of a Docker Compose file.
+type ImportComposeCommand struct {
+	io         ui.IO
+	file       string
+	path       api.DirPath
+	composeOut string
+	envOut     string
+	options    importOptions
+	newClient  newClientFunc
+}
+
+// NewImportComposeCommand creates a new ImportComposeCommand.
+func NewImportComposeCommand(io ui.IO, newClient newClientFunc) *ImportComposeCommand {
+	return &ImportComposeCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *ImportComposeCommand) Register(r command.Registerer) {
+	clause := r.Command("compose", "Import the environment variables and secrets of a Docker Compose file.")
+	clause.HelpLong("Scans the environment sections of all services and the top-level secrets section of a Docker Compose file. " +
+		"Every environment variable with a value is imported to <dir-path>/<service>/<name> and every secret that is read from a file " +
+		"is imported to <dir-path>/secrets/<name>. Values that use variable substitution ($VAR) and values that are skipped or merged by a mapping are left as they are.\n" +
+		"\n" +
+		"After the import, a rewritten Compose file is written that reads the imported values from environment variables, " +
+		"together with an env-file that maps these environment variables to the imported secrets. " +
+		"Start the services with secrethub run --env-file <env-out> -- docker compose -f <compose-out> up. " +
+		"Secrets that are read from environment variables require Docker Compose 2.6 or newer. " +
+		"Comments are not preserved in the rewritten Compose file.")
+	clause.Arg("compose-file", "The path to the Docker Compose file.").Required().ExistingFileVar(&cmd.file)
+	clause.Arg("dir-path", "The path of the directory to import the secrets into.").Required().PlaceHolder(dirPathPlaceHolder).SetValue(&cmd.path)
+	clause.Flag("compose-out", "The file to write the rewritten Compose file to. Defaults to "+composeOutFileName+" in the directory of the Compose file.").StringVar(&cmd.composeOut)
+	clause.Flag("env-out", "The file to write the env-file for secrethub run to. Defaults to "+defaultEnvFile+" in the directory of the Compose file.").StringVar(&cmd.envOut)
+	cmd.options.register(clause)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run imports the secrets of the Compose file and writes the rewritten Compose file and env-file.
+func (cmd *ImportComposeCommand) Run() error {
+	if cmd.composeOut == "" {
+		cmd.composeOut = filepath.Join(filepath.Dir(cmd.file), composeOutFileName)
+	}
+	if cmd.envOut == "" {
+		cmd.envOut = filepath.Join(filepath.Dir(cmd.file), defaultEnvFile)
+	}
+
+	content, err := ioutil.ReadFile(cmd.file)
+	if err != nil {
+		return ErrCannotReadFile(cmd.file, err)
+	}
+
+	compose, err := parseComposeFile(cmd.file, content)
+	if err != nil {
+		return err
+	}
+
+	if !cmd.options.dryRun {
+		for _, file := range []string{cmd.composeOut, cmd.envOut} {
+			confirmed, err := cmd.confirmOverwrite(file)
+			if err != nil || !confirmed {
+				return err
+			}
+		}
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	destinations, err := importSecretsWithDestinations(cmd.io, client, cmd.path, compose.secrets, cmd.options)
+	if err != nil || destinations == nil {
+		return err
+	}
+
+	envFile := compose.rewrite(destinations)
+
+	out, err := yaml.Marshal(compose.doc)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(cmd.composeOut, out, 0644)
+	if err != nil {
+		return ErrCannotWrite(cmd.composeOut, err)
+	}
+
+	err = ioutil.WriteFile(cmd.envOut, envFile, 0644)
+	if err != nil {
+		return ErrCannotWrite(cmd.envOut, err)
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Wrote the rewritten Compose file to %s and the env-file to %s.\n", cmd.composeOut, cmd.envOut)
+	fmt.Fprintf(cmd.io.Output(), "Start the services with: secrethub run --env-file %s -- docker compose -f %s up\n", cmd.envOut, cmd.composeOut)
+	return nil
+}
+
+// confirmOverwrite asks whether an existing output file may be overwritten.
+func (cmd *ImportComposeCommand) confirmOverwrite(path string) (bool, error) {
+	if cmd.options.force {
+		return true, nil
+	}
+
+	_, err := os.Stat(path)
+	if err != nil {
+		return true, nil
+	}
+
+	confirmed, err := ui.AskYesNo(cmd.io, fmt.Sprintf("File %s already exists, overwrite it?", path), ui.DefaultNo)
+	if err == ui.ErrCannotAsk {
+		return false, ErrFileAlreadyExists
+	} else if err != nil {
+		return false, err
+	}
+
+	if !confirmed {
+		fmt.Fprintln(cmd.io.Output(), "Aborting.")
+	}
+	return confirmed, nil
+}
+
+// composeFile is a parsed Docker Compose file with the secrets found in it.
+type composeFile struct {
+	doc     yaml.MapSlice
+	secrets []importSecret
+	refs    []composeRef
+}
+
+// composeRef is a value in the Compose file that is replaced
+// by an environment variable when its secret has been imported.
+type composeRef struct {
+	// source is the relative path of the secret the value is imported to.
+	source string
+	envVar string
+	// replace rewrites the value in the Compose file to use the environment variable.
+	replace func()
+}
+
+// parseComposeFile finds the environment variables and file-based secrets in a Compose file.
+// Secret files are read relative to the directory of the Compose file.
+func parseComposeFile(file string, content []byte) (*composeFile, error) {
+	compose := &composeFile{}
+	err := yaml.Unmarshal(content, &compose.doc)
+	if err != nil {
+		return nil, ErrComposeInvalidYAML(file, err)
+	}
+
+	envVars := map[string]bool{}
+	uniqueEnvVar := func(name string) string {
+		name = strings.ToUpper(composeIllegalEnvVarChars.ReplaceAllString(name, "_"))
+		unique := name
+		for i := 2; envVars[unique]; i++ {
+			unique = name + "_" + strconv.Itoa(i)
+		}
+		envVars[unique] = true
+		return unique
+	}
+
+	for _, item := range compose.doc {
+		switch item.Key {
+		case "services":
+			services, ok := item.Value.(yaml.MapSlice)
+			if !ok {
+				return nil, ErrComposeInvalidKey(file, "services")
+			}
+			serviceNames := uniqueNames{}
+			for _, service := range services {
+				serviceName := fmt.Sprint(service.Key)
+				definition, ok := service.Value.(yaml.MapSlice)
+				if !ok {
+					return nil, ErrComposeInvalidKey(file, "services."+serviceName)
+				}
+				dir := serviceNames.add(importName(serviceName))
+				for i := range definition {
+					if definition[i].Key == "environment" {
+						compose.addEnvironment(&definition[i], serviceName, dir, uniqueEnvVar)
+					}
+				}
+			}
+		case "secrets":
+			secrets, ok := item.Value.(yaml.MapSlice)
+			if !ok {
+				return nil, ErrComposeInvalidKey(file, "secrets")
+			}
+			names := uniqueNames{}
+			for i := range secrets {
+				secret := &secrets[i]
+				name := fmt.Sprint(secret.Key)
+				definition, ok := secret.Value.(yaml.MapSlice)
+				if !ok {
+					return nil, ErrComposeInvalidKey(file, "secrets."+name)
+				}
+				secretFile, ok := composeValue(definition, "file").(string)
+				if !ok {
+					// External secrets and secrets from environment variables are not stored in the file.
+					continue
+				}
+				if !filepath.IsAbs(secretFile) {
+					secretFile = filepath.Join(filepath.Dir(file), secretFile)
+				}
+				data, err := ioutil.ReadFile(secretFile)
+				if err != nil {
+					return nil, ErrCannotReadFile(secretFile, err)
+				}
+
+				source := joinImportPath("secrets", names.add(importName(name)))
+				compose.secrets = append(compose.secrets, importSecret{path: source, data: data})
+
+				envVar := uniqueEnvVar(composeEnvVarPrefix + name)
+				compose.refs = append(compose.refs, composeRef{
+					source: source,
+					envVar: envVar,
+					replace: func() {
+						secret.Value = yaml.MapSlice{{Key: "environment", Value: envVar}}
+					},
+				})
+			}
+		}
+	}
+
+	return compose, nil
+}
+
+// addEnvironment adds the variables of the environment section of a service. Both the
+// mapping syntax and the list syntax (NAME=value) are supported.
+func (c *composeFile) addEnvironment(environment *yaml.MapItem, service string, dir string, uniqueEnvVar func(string) string) {
+	names := uniqueNames{}
+	add := func(name string, value string, replace func(string)) {
+		// Values without a value are passed from the host and values with
+		// substitutions are resolved by Docker Compose, so they are kept.
+		if value == "" || strings.Contains(value, "$") {
+			return
+		}
+		source := joinImportPath(dir, names.add(importName(name)))
+		c.secrets = append(c.secrets, importSecret{path: source, data: []byte(value)})
+
+		envVar := uniqueEnvVar(service + "_" + name)
+		c.refs = append(c.refs, composeRef{
+			source: source,
+			envVar: envVar,
+			replace: func() {
+				replace("${" + envVar + "}")
+			},
+		})
+	}
+
+	switch vars := environment.Value.(type) {
+	case yaml.MapSlice:
+		for i := range vars {
+			item := &vars[i]
+			if item.Value == nil {
+				continue
+			}
+			name := fmt.Sprint(item.Key)
+			add(name, fmt.Sprint(item.Value), func(value string) {
+				item.Value = value
+			})
+		}
+	case []interface{}:
+		for i := range vars {
+			i := i
+			entry, ok := vars[i].(string)
+			if !ok {
+				continue
+			}
+			parts := strings.SplitN(entry, "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			name := parts[0]
+			add(name, parts[1], func(value string) {
+				vars[i] = name + "=" + value
+			})
+		}
+	}
+}
+
+// rewrite replaces the values of the imported secrets in the Compose file with
+// environment variables and returns an env-file that maps these environment
+// variables to the given destinations of the secrets.
+func (c *composeFile) rewrite(destinations map[string]string) []byte {
+	var envFile bytes.Buffer
+	for _, ref := range c.refs {
+		dest, ok := destinations[ref.source]
+		if !ok {
+			continue
+		}
+		ref.replace()
+		fmt.Fprintf(&envFile, "%s={{ %s }}\n", ref.envVar, dest)
+	}
+	return envFile.Bytes()
+}
+
+// composeValue returns the value of the given key in a mapping or nil when the key is not set.
+func composeValue(m yaml.MapSlice, key string) interface{} {
+	for _, item := range m {
+		if item.Key == key {
+			return item.Value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package secrethub
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestComposeFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrethub-compose")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "db_password.txt"), []byte("s3cr3t"), 0600)
+	assert.OK(t, err)
+
+	file := filepath.Join(dir, "docker-compose.yml")
+	content := "version: \"3.8\"\n" +
+		"services:\n" +
+		"  web:\n" +
+		"    image: example/web\n" +
+		"    environment:\n" +
+		"      API_KEY: abc123\n" +
+		"      PORT: 8080\n" +
+		"      HOME_DIR: $HOME\n" +
+		"      PASSTHROUGH:\n" +
+		"  db-worker:\n" +
+		"    environment:\n" +
+		"      - API_KEY=def456\n" +
+		"      - DEBUG\n" +
+		"    secrets:\n" +
+		"      - db_password\n" +
+		"secrets:\n" +
+		"  db_password:\n" +
+		"    file: ./db_password.txt\n" +
+		"  external_token:\n" +
+		"    external: true\n"
+
+	compose, err := parseComposeFile(file, []byte(content))
+	assert.OK(t, err)
+
+	assert.Equal(t, compose.secrets, []importSecret{
+		{path: "web/API_KEY", data: []byte("abc123")},
+		{path: "web/PORT", data: []byte("8080")},
+		{path: "db-worker/API_KEY", data: []byte("def456")},
+		{path: "secrets/db_password", data: []byte("s3cr3t")},
+	})
+
+	// web/PORT is not imported, e.g. because the mapping skips it.
+	envFile := compose.rewrite(map[string]string{
+		"web/API_KEY":         "company/app/web/API_KEY",
+		"db-worker/API_KEY":   "company/app/db-worker/API_KEY",
+		"secrets/db_password": "company/app/secrets/db_password",
+	})
+
+	assert.Equal(t, string(envFile), ""+
+		"WEB_API_KEY={{ company/app/web/API_KEY }}\n"+
+		"DB_WORKER_API_KEY={{ company/app/db-worker/API_KEY }}\n"+
+		"SECRET_DB_PASSWORD={{ company/app/secrets/db_password }}\n")
+
+	out, err := yaml.Marshal(compose.doc)
+	assert.OK(t, err)
+	assert.Equal(t, string(out), ""+
+		"version: \"3.8\"\n"+
+		"services:\n"+
+		"  web:\n"+
+		"    image: example/web\n"+
+		"    environment:\n"+
+		"      API_KEY: ${WEB_API_KEY}\n"+
+		"      PORT: 8080\n"+
+		"      HOME_DIR: $HOME\n"+
+		"      PASSTHROUGH: null\n"+
+		"  db-worker:\n"+
+		"    environment:\n"+
+		"    - API_KEY=${DB_WORKER_API_KEY}\n"+
+		"    - DEBUG\n"+
+		"    secrets:\n"+
+		"    - db_password\n"+
+		"secrets:\n"+
+		"  db_password:\n"+
+		"    environment: SECRET_DB_PASSWORD\n"+
+		"  external_token:\n"+
+		"    external: true\n")
+}
+
+func TestParseComposeFile_Invalid(t *testing.T) {
+	_, err := parseComposeFile("docker-compose.yml", []byte("services: [web]\n"))
+
+	assert.Equal(t, err, ErrComposeInvalidKey("docker-compose.yml", "services"))
+}
@@ -0,0 +1,309 @@
+package secrethub
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// Errors
+var (
+	ErrConjurMissingFlag       = errImport.Code("conjur_missing_flag").ErrorPref("--%s is required to connect to Conjur")
+	ErrConjurInvalidCACert     = errImport.Code("conjur_invalid_ca_cert").ErrorPref("no valid certificates found in %s")
+	ErrConjurAuthFailed        = errImport.Code("conjur_auth_failed").Error("could not authenticate to Conjur: the login or API key is incorrect")
+	ErrConjurUnexpectedStatus  = errImport.Code("conjur_unexpected_status").ErrorPref("unexpected response from Conjur for %s: %s")
+	ErrConjurInvalidResourceID = errImport.Code("conjur_invalid_resource_id").ErrorPref("Conjur returned an invalid resource id: %s")
+)
+
+// conjurPageSize is the number of variables requested from Conjur at once.
+const conjurPageSize = 100
+
+// ImportConjurCommand imports the variables of a Conjur policy branch.
+type ImportConjurCommand struct {
+	io        ui.IO
+	branch    string
+	path      api.DirPath
+	url       string
+	account   string
+	login     string
+	apiKey    string
+	caCert    string
+	options   importOptions
+	newClient newClientFunc
+}
+
+// NewImportConjurCommand creates a new ImportConjurCommand.
+func NewImportConjurCommand(io ui.IO, newClient newClientFunc) *ImportConjurCommand {
+	return &ImportConjurCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *ImportConjurCommand) Register(r command.Registerer) {
+	clause := r.Command("conjur", "Import the variables of a CyberArk Conjur policy branch.")
+	clause.HelpLong("Authenticates to a Conjur (or CyberArk DAP) server and imports all variables in the given policy branch. " +
+		"The slash-separated variable IDs are preserved as paths, so the variable prod/db/password is imported to <dir-path>/prod/db/password. " +
+		"Variables without a value are skipped.\n" +
+		"\n" +
+		"The connection is configured with the same environment variables as the Conjur CLI.")
+	clause.Arg("policy-branch", "The policy branch to import the variables of, e.g. prod/db. Use root to import all variables.").Required().StringVar(&cmd.branch)
+	clause.Arg("dir-path", "The path of the directory to import the variables into.").Required().PlaceHolder(dirPathPlaceHolder).SetValue(&cmd.path)
+	clause.Flag("url", "The URL of the Conjur server.").Envar("CONJUR_APPLIANCE_URL").StringVar(&cmd.url)
+	clause.Flag("account", "The Conjur organization account.").Envar("CONJUR_ACCOUNT").StringVar(&cmd.account)
+	clause.Flag("login", "The user or host to authenticate as, e.g. host/secrethub-import.").Envar("CONJUR_AUTHN_LOGIN").StringVar(&cmd.login)
+	clause.Flag("api-key", "The API key of the login. Is asked if not supplied.").Envar("CONJUR_AUTHN_API_KEY").StringVar(&cmd.apiKey)
+	clause.Flag("ca-cert", "The path to the PEM encoded CA certificate of the Conjur server, for servers with a self-signed certificate.").Envar("CONJUR_CERT_FILE").ExistingFileVar(&cmd.caCert)
+	cmd.options.register(clause)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run fetches the variables from Conjur and imports them.
+func (cmd *ImportConjurCommand) Run() error {
+	if cmd.url == "" {
+		return ErrConjurMissingFlag("url")
+	}
+	if cmd.account == "" {
+		return ErrConjurMissingFlag("account")
+	}
+	if cmd.login == "" {
+		return ErrConjurMissingFlag("login")
+	}
+
+	if cmd.apiKey == "" {
+		apiKey, err := ui.AskSecret(cmd.io, fmt.Sprintf("What is the Conjur API key of %s?", cmd.login))
+		if err != nil {
+			return err
+		}
+		cmd.apiKey = apiKey
+	}
+
+	httpClient, err := cmd.httpClient()
+	if err != nil {
+		return err
+	}
+
+	conjur := &conjurClient{
+		url:     strings.TrimRight(cmd.url, "/"),
+		account: cmd.account,
+		client:  httpClient,
+	}
+
+	err = conjur.authenticate(cmd.login, cmd.apiKey)
+	if err != nil {
+		return err
+	}
+
+	secrets, err := conjurSecrets(conjur, cmd.branch)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	return importSecrets(cmd.io, client, cmd.path, secrets, cmd.options)
+}
+
+// httpClient returns the HTTP client to connect to Conjur, trusting the configured CA certificate.
+func (cmd *ImportConjurCommand) httpClient() (*http.Client, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	if cmd.caCert == "" {
+		return client, nil
+	}
+
+	pem, err := ioutil.ReadFile(cmd.caCert)
+	if err != nil {
+		return nil, ErrCannotReadFile(cmd.caCert, err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, ErrConjurInvalidCACert(cmd.caCert)
+	}
+
+	client.Transport = &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{RootCAs: pool},
+	}
+	return client, nil
+}
+
+// conjurSecrets returns the secrets to import for all variables with a value in the policy branch.
+func conjurSecrets(conjur *conjurClient, branch string) ([]importSecret, error) {
+	ids, err := conjur.listVariables(branch)
+	if err != nil {
+		return nil, err
+	}
+
+	var secrets []importSecret
+	for _, id := range ids {
+		value, ok, err := conjur.getSecret(id)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		var segments []string
+		for _, segment := range strings.Split(id, "/") {
+			if name := importName(segment); name != "" {
+				segments = append(segments, name)
+			}
+		}
+
+		secrets = append(secrets, importSecret{
+			path: strings.Join(segments, "/"),
+			data: value,
+		})
+	}
+	return secrets, nil
+}
+
+// conjurClient is a minimal client for the Conjur REST API.
+type conjurClient struct {
+	url     string
+	account string
+	client  *http.Client
+	token   string
+}
+
+// authenticate exchanges the API key for an access token.
+func (c *conjurClient) authenticate(login, apiKey string) error {
+	endpoint := fmt.Sprintf("%s/authn/%s/%s/authenticate", c.url, url.PathEscape(c.account), url.PathEscape(login))
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(apiKey))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept-Encoding", "base64")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return ErrConjurAuthFailed
+	} else if resp.StatusCode != http.StatusOK {
+		return ErrConjurUnexpectedStatus("authentication", resp.Status)
+	}
+
+	token, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	// Older servers ignore the Accept-Encoding header and return the raw JSON token.
+	if strings.HasPrefix(strings.TrimSpace(string(token)), "{") {
+		token = []byte(base64.StdEncoding.EncodeToString(token))
+	}
+	c.token = string(token)
+	return nil
+}
+
+// listVariables returns the IDs of all variables in the policy branch.
+func (c *conjurClient) listVariables(branch string) ([]string, error) {
+	branch = strings.Trim(branch, "/")
+	prefix := ""
+	if branch != "" && branch != "root" {
+		prefix = branch + "/"
+	}
+
+	var ids []string
+	for offset := 0; ; offset += conjurPageSize {
+		query := url.Values{}
+		query.Set("kind", "variable")
+		query.Set("limit", strconv.Itoa(conjurPageSize))
+		query.Set("offset", strconv.Itoa(offset))
+
+		var resources []struct {
+			ID string `json:"id"`
+		}
+		err := c.get(fmt.Sprintf("%s/resources/%s?%s", c.url, url.PathEscape(c.account), query.Encode()), &resources)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, resource := range resources {
+			// Resource IDs have the format account:kind:id.
+			parts := strings.SplitN(resource.ID, ":", 3)
+			if len(parts) != 3 {
+				return nil, ErrConjurInvalidResourceID(resource.ID)
+			}
+			if strings.HasPrefix(parts[2], prefix) {
+				ids = append(ids, parts[2])
+			}
+		}
+
+		if len(resources) < conjurPageSize {
+			return ids, nil
+		}
+	}
+}
+
+// getSecret returns the value of the variable. When the variable has no value, ok is false.
+func (c *conjurClient) getSecret(id string) ([]byte, bool, error) {
+	endpoint := fmt.Sprintf("%s/secrets/%s/variable/%s", c.url, url.PathEscape(c.account), url.PathEscape(id))
+
+	resp, err := c.do(endpoint)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, false, nil
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, false, ErrConjurUnexpectedStatus(id, resp.Status)
+	}
+
+	value, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// get performs an authenticated GET request and decodes the JSON response into v.
+func (c *conjurClient) get(endpoint string, v interface{}) error {
+	resp, err := c.do(endpoint)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return ErrConjurUnexpectedStatus(endpoint, resp.Status)
+	}
+
+	return json.NewDecoder(io.LimitReader(resp.Body, 64<<20)).Decode(v)
+}
+
+func (c *conjurClient) do(endpoint string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Token token=\"%s\"", c.token))
+	return c.client.Do(req)
+}
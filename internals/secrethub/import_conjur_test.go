@@ -0,0 +1,106 @@
+package secrethub
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func newFakeConjur(t *testing.T, variables map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/authn/myorg/host/importer/authenticate":
+			body, _ := ioutil.ReadAll(r.Body)
+			if string(body) != "api-key" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, "dG9rZW4=")
+		case r.Header.Get("Authorization") != `Token token="dG9rZW4="`:
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/resources/myorg":
+			assert.Equal(t, r.URL.Query().Get("kind"), "variable")
+			var resources []map[string]string
+			if r.URL.Query().Get("offset") == "0" {
+				for _, id := range []string{"prod/db/password", "prod/db/empty", "prod/api key", "dev/db/password"} {
+					resources = append(resources, map[string]string{"id": "myorg:variable:" + id})
+				}
+			}
+			_ = json.NewEncoder(w).Encode(resources)
+		case strings.HasPrefix(r.URL.Path, "/secrets/myorg/variable/"):
+			value, ok := variables[strings.TrimPrefix(r.URL.Path, "/secrets/myorg/variable/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			fmt.Fprint(w, value)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestConjurSecrets(t *testing.T) {
+	server := newFakeConjur(t, map[string]string{
+		"prod/db/password": "s3cr3t",
+		"prod/api key":     "abc",
+		"dev/db/password":  "dev",
+	})
+	defer server.Close()
+
+	cases := map[string]struct {
+		branch   string
+		apiKey   string
+		expected []importSecret
+		err      error
+	}{
+		"branch": {
+			branch: "prod",
+			apiKey: "api-key",
+			expected: []importSecret{
+				{path: "prod/db/password", data: []byte("s3cr3t")},
+				{path: "prod/api-key", data: []byte("abc")},
+			},
+		},
+		"root": {
+			branch: "root",
+			apiKey: "api-key",
+			expected: []importSecret{
+				{path: "prod/db/password", data: []byte("s3cr3t")},
+				{path: "prod/api-key", data: []byte("abc")},
+				{path: "dev/db/password", data: []byte("dev")},
+			},
+		},
+		"wrong api key": {
+			branch: "prod",
+			apiKey: "wrong",
+			err:    ErrConjurAuthFailed,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			conjur := &conjurClient{
+				url:     server.URL,
+				account: "myorg",
+				client:  server.Client(),
+			}
+
+			err := conjur.authenticate("host/importer", tc.apiKey)
+			assert.Equal(t, err, tc.err)
+			if err != nil {
+				return
+			}
+
+			actual, err := conjurSecrets(conjur, tc.branch)
+			assert.OK(t, err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package secrethub
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/kdbx"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// Errors
+var (
+	ErrKeePassPasswordFlags = errImport.Code("keepass_password_flags").Error("--password-file and --no-password cannot be used together")
+	ErrKeePassNoKey         = errImport.Code("keepass_no_key").Error("--no-password requires a --keyfile to unlock the database")
+)
+
+// keepassFieldNames maps the standard fields of a KeePass entry to secret names.
+var keepassFieldNames = map[string]string{
+	"UserName": "username",
+	"Password": "password",
+	"URL":      "url",
+	"Notes":    "notes",
+}
+
+// ImportKeePassCommand imports the entries of a KeePass database.
+type ImportKeePassCommand struct {
+	io           ui.IO
+	file         string
+	path         api.DirPath
+	keyFile      string
+	passwordFile string
+	noPassword   bool
+	options      importOptions
+	newClient    newClientFunc
+}
+
+// NewImportKeePassCommand creates a new ImportKeePassCommand.
+func NewImportKeePassCommand(io ui.IO, newClient newClientFunc) *ImportKeePassCommand {
+	return &ImportKeePassCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *ImportKeePassCommand) Register(r command.Registerer) {
+	clause := r.Command("keepass", "Import the groups and entries of a KeePass database.")
+	clause.HelpLong("Decrypts a KeePass database in the KDBX 3.1 or KDBX 4 format and imports it into the given directory. " +
+		"Every group becomes a directory and every entry becomes a directory containing a secret for each field " +
+		"(username, password, url, notes and custom fields) and for each attachment. Empty fields and the recycle bin are skipped.\n" +
+		"\n" +
+		"The master password is asked for, unless --password-file or --no-password is set.")
+	clause.Arg("kdbx-file", "The path to the KeePass database.").Required().ExistingFileVar(&cmd.file)
+	clause.Arg("dir-path", "The path of the directory to import the database into.").Required().PlaceHolder(dirPathPlaceHolder).SetValue(&cmd.path)
+	clause.Flag("keyfile", "The key file to unlock the database with.").ExistingFileVar(&cmd.keyFile)
+	clause.Flag("password-file", "Read the master password from this file instead of asking for it.").ExistingFileVar(&cmd.passwordFile)
+	clause.Flag("no-password", "The database is only unlocked with a key file and has no master password.").BoolVar(&cmd.noPassword)
+	cmd.options.register(clause)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run decrypts the database and imports its entries.
+func (cmd *ImportKeePassCommand) Run() error {
+	key, err := cmd.key()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(cmd.file)
+	if err != nil {
+		return ErrCannotReadFile(cmd.file, err)
+	}
+	defer f.Close()
+
+	db, err := kdbx.Open(f, key)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	return importSecrets(cmd.io, client, cmd.path, keepassSecrets(db.Root, ""), cmd.options)
+}
+
+// key reads the master password and key file into a composite key.
+func (cmd *ImportKeePassCommand) key() (*kdbx.Key, error) {
+	if cmd.noPassword && cmd.passwordFile != "" {
+		return nil, ErrKeePassPasswordFlags
+	}
+	if cmd.noPassword && cmd.keyFile == "" {
+		return nil, ErrKeePassNoKey
+	}
+
+	var password []byte
+	if cmd.passwordFile != "" {
+		content, err := ioutil.ReadFile(cmd.passwordFile)
+		if err != nil {
+			return nil, ErrCannotReadFile(cmd.passwordFile, err)
+		}
+		password = bytes.TrimRight(content, "\r\n")
+	} else if !cmd.noPassword {
+		str, err := ui.AskSecret(cmd.io, fmt.Sprintf("What is the master password of %s?", cmd.file))
+		if err != nil {
+			return nil, err
+		}
+		password = []byte(str)
+	}
+
+	var keyFile []byte
+	if cmd.keyFile != "" {
+		content, err := ioutil.ReadFile(cmd.keyFile)
+		if err != nil {
+			return nil, ErrCannotReadFile(cmd.keyFile, err)
+		}
+		keyFile = content
+	}
+
+	return kdbx.NewKey(password, keyFile)
+}
+
+// keepassSecrets returns the secrets to import for the entries in the group and its sub-groups.
+// The root group itself does not become a directory.
+func keepassSecrets(group *kdbx.Group, dir string) []importSecret {
+	var secrets []importSecret
+	names := uniqueNames{}
+
+	for _, entry := range group.Entries {
+		name := importName(entry.Title())
+		if name == "" {
+			name = "untitled"
+		}
+		entryDir := joinImportPath(dir, names.add(name))
+
+		fields := uniqueNames{}
+		for _, field := range entry.Fields {
+			if field.Key == "Title" || strings.TrimSpace(field.Value) == "" {
+				continue
+			}
+			fieldName, ok := keepassFieldNames[field.Key]
+			if !ok {
+				fieldName = importName(field.Key)
+			}
+			if fieldName == "" {
+				continue
+			}
+			secrets = append(secrets, importSecret{
+				path: joinImportPath(entryDir, fields.add(fieldName)),
+				data: []byte(field.Value),
+			})
+		}
+
+		for _, attachment := range entry.Attachments {
+			attachmentName := importName(attachment.Name)
+			if attachmentName == "" {
+				attachmentName = "attachment"
+			}
+			secrets = append(secrets, importSecret{
+				path: joinImportPath(entryDir, fields.add(attachmentName)),
+				data: attachment.Data,
+			})
+		}
+	}
+
+	for _, sub := range group.Groups {
+		if sub.RecycleBin {
+			continue
+		}
+		name := importName(sub.Name)
+		if name == "" {
+			name = "untitled"
+		}
+		secrets = append(secrets, keepassSecrets(sub, joinImportPath(dir, names.add(name)))...)
+	}
+
+	return secrets
+}
+
+// joinImportPath joins a relative import path with a name.
+func joinImportPath(dir, name string) string {
+	if dir == "" {
+		return name
+	}
+	return dir + "/" + name
+}
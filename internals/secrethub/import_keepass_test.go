@@ -0,0 +1,91 @@
+package secrethub
+
+import (
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/kdbx"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestKeepassSecrets(t *testing.T) {
+	root := &kdbx.Group{
+		Name: "Database",
+		Entries: []*kdbx.Entry{
+			{
+				Fields: []kdbx.Field{
+					{Key: "Title", Value: "My Mail"},
+					{Key: "UserName", Value: "alice"},
+					{Key: "Password", Value: "p4ssw0rd", Protected: true},
+					{Key: "URL", Value: ""},
+					{Key: "API Key", Value: "abc"},
+				},
+				Attachments: []kdbx.Attachment{
+					{Name: "id_rsa (old).pem", Data: []byte{0x00, 0x01}},
+				},
+			},
+			{
+				Fields: []kdbx.Field{
+					{Key: "Title", Value: "my mail"},
+					{Key: "Password", Value: "other"},
+				},
+			},
+		},
+		Groups: []*kdbx.Group{
+			{
+				Name: "Servers",
+				Entries: []*kdbx.Entry{
+					{
+						Fields: []kdbx.Field{
+							{Key: "Password", Value: "s3cr3t"},
+						},
+					},
+				},
+			},
+			{
+				Name:       "Recycle Bin",
+				RecycleBin: true,
+				Entries: []*kdbx.Entry{
+					{
+						Fields: []kdbx.Field{
+							{Key: "Title", Value: "deleted"},
+							{Key: "Password", Value: "gone"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expected := []importSecret{
+		{path: "My-Mail/username", data: []byte("alice")},
+		{path: "My-Mail/password", data: []byte("p4ssw0rd")},
+		{path: "My-Mail/API-Key", data: []byte("abc")},
+		{path: "My-Mail/id_rsa-old-.pem", data: []byte{0x00, 0x01}},
+		{path: "my-mail-2/password", data: []byte("other")},
+		{path: "Servers/untitled/password", data: []byte("s3cr3t")},
+	}
+
+	actual := keepassSecrets(root, "")
+
+	assert.Equal(t, actual, expected)
+}
+
+func TestImportName(t *testing.T) {
+	cases := map[string]string{
+		"simple":          "simple",
+		"with spaces":     "with-spaces",
+		"  trimmed  ":     "trimmed",
+		"a/b\\c":          "a-b-c",
+		"dots.and_under":  "dots.and_under",
+		"multiple   ---x": "multiple-x",
+		"ünïcödé":         "n-c-d",
+		"...":             "",
+	}
+
+	for name, expected := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, importName(name), expected)
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package secrethub
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Errors
+var (
+	ErrInvalidImportMapping = errImport.Code("invalid_mapping").ErrorPref("invalid mapping file %s: %v")
+	ErrInvalidMappingRule   = errImport.Code("invalid_mapping_rule").ErrorPref("invalid mapping rule for %s: %s")
+	ErrUnknownImportSource  = errImport.Code("unknown_import_source").ErrorPref("%s does not match any of the secrets to import")
+)
+
+// importMapping changes the default layout proposed by an importer. It is stored as YAML,
+// so that it can be reused with the --mapping flag. For example:
+//
+//	rules:
+//	  - source: My-Mail
+//	    destination: mail
+//	  - source: My-Mail/notes
+//	    skip: true
+//	  - source: Servers/db/username
+//	    destination: servers/db/credentials
+//	  - source: Servers/db/password
+//	    destination: servers/db/credentials
+//
+// A rule applies to the secret with the source path, or to all secrets in the directory
+// with the source path. When multiple rules apply, the rule with the longest source wins.
+// Secrets mapped to the same destination are merged into a single JSON object.
+type importMapping struct {
+	Rules []importMappingRule `yaml:"rules"`
+}
+
+// importMappingRule renames or skips a secret or directory.
+type importMappingRule struct {
+	Source      string `yaml:"source"`
+	Destination string `yaml:"destination,omitempty"`
+	Skip        bool   `yaml:"skip,omitempty"`
+}
+
+// readImportMapping reads a mapping from a YAML file.
+func readImportMapping(filename string) (*importMapping, error) {
+	raw, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, ErrCannotReadFile(filename, err)
+	}
+
+	mapping := &importMapping{}
+	err = yaml.UnmarshalStrict(raw, mapping)
+	if err != nil {
+		return nil, ErrInvalidImportMapping(filename, err)
+	}
+
+	for _, rule := range mapping.Rules {
+		err = rule.validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return mapping, nil
+}
+
+// write stores the mapping as YAML in the given file.
+func (m *importMapping) write(filename string) error {
+	raw, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(filename, raw, 0644)
+	if err != nil {
+		return ErrCannotWrite(filename, err)
+	}
+	return nil
+}
+
+func (r importMappingRule) validate() error {
+	if r.Source == "" {
+		return ErrInvalidMappingRule("rule", "source is required")
+	}
+	if r.Skip && r.Destination != "" {
+		return ErrInvalidMappingRule(r.Source, "a skipped source cannot have a destination")
+	}
+	if !r.Skip && r.Destination == "" {
+		return ErrInvalidMappingRule(r.Source, "destination is required unless skip is set")
+	}
+	return nil
+}
+
+// set adds the rule to the mapping, replacing any existing rule for the same source.
+func (m *importMapping) set(rule importMappingRule) {
+	rule.Source = strings.Trim(rule.Source, "/")
+	rule.Destination = strings.Trim(rule.Destination, "/")
+	for i, existing := range m.Rules {
+		if existing.Source == rule.Source {
+			m.Rules[i] = rule
+			return
+		}
+	}
+	m.Rules = append(m.Rules, rule)
+}
+
+// destination returns the path a secret is mapped to and whether it should be imported.
+func (m *importMapping) destination(source string) (string, bool) {
+	var match *importMappingRule
+	for i, rule := range m.Rules {
+		if rule.Source == source || strings.HasPrefix(source, rule.Source+"/") {
+			if match == nil || len(rule.Source) > len(match.Source) {
+				match = &m.Rules[i]
+			}
+		}
+	}
+
+	if match == nil {
+		return source, true
+	}
+	if match.Skip {
+		return "", false
+	}
+	return match.Destination + strings.TrimPrefix(source, match.Source), true
+}
+
+// importPlanItem is a secret that will be written, together with the secrets it is imported from.
+type importPlanItem struct {
+	path    string
+	sources []importSecret
+}
+
+// data returns the value of the secret. Merged secrets are encoded as a JSON object
+// with the names of the sources as keys.
+func (i importPlanItem) data() []byte {
+	if len(i.sources) == 1 {
+		return i.sources[0].data
+	}
+
+	merged := map[string]string{}
+	for _, source := range i.sources {
+		key := path.Base(source.path)
+		if _, exists := merged[key]; exists {
+			key = source.path
+		}
+		merged[key] = string(source.data)
+	}
+	// Marshalling a map of strings cannot fail.
+	data, _ := json.MarshalIndent(merged, "", "  ")
+	return data
+}
+
+// plan applies the mapping to the secrets found by an importer.
+// It returns the secrets to write and the paths of the skipped secrets.
+func (m *importMapping) plan(secrets []importSecret) ([]importPlanItem, []string) {
+	var items []importPlanItem
+	var skipped []string
+	index := map[string]int{}
+	for _, secret := range secrets {
+		dest, ok := m.destination(secret.path)
+		if !ok {
+			skipped = append(skipped, secret.path)
+			continue
+		}
+
+		key := strings.ToLower(dest)
+		i, exists := index[key]
+		if !exists {
+			i = len(items)
+			index[key] = i
+			items = append(items, importPlanItem{path: dest})
+		}
+		items[i].sources = append(items[i].sources, secret)
+	}
+	return items, skipped
+}
+
+// editImportMapping lets the user interactively change the mapping until they continue
+// with the import. It returns false when the user aborts the import.
+func editImportMapping(io ui.IO, mapping *importMapping, secrets []importSecret) (bool, error) {
+	_, promptOut, err := io.Prompts()
+	if err != nil {
+		return false, err
+	}
+
+	for {
+		err = printImportMapping(promptOut, mapping, secrets)
+		if err != nil {
+			return false, err
+		}
+
+		fmt.Fprint(promptOut, "\n"+
+			"Press [ENTER] to continue with the import or change the mapping with one of the following commands:\n"+
+			"  rename <source> <destination>       Rename a secret or directory.\n"+
+			"  skip <source>                       Do not import a secret or directory.\n"+
+			"  merge <destination> <source>...     Merge secrets into a single secret containing a JSON object.\n"+
+			"  reset <source>                      Remove the mapping of a secret or directory.\n"+
+			"  save <file>                         Save the mapping to a file to reuse it with --mapping.\n"+
+			"  abort                               Abort the import.\n"+
+			"Sources can be referred to by their path or number.\n")
+
+		line, err := ui.Ask(io, "> ")
+		if err != nil {
+			return false, err
+		}
+
+		args := strings.Fields(line)
+		if len(args) == 0 {
+			return true, nil
+		}
+
+		err = applyMappingCommand(mapping, secrets, args)
+		if err == errAbortImport {
+			return false, nil
+		} else if err != nil {
+			fmt.Fprintf(promptOut, "\n%s\n", err)
+		}
+		fmt.Fprintln(promptOut)
+	}
+}
+
+var errAbortImport = errImport.Code("aborted").Error("import aborted")
+
+// applyMappingCommand executes a single command of the interactive mapping editor.
+func applyMappingCommand(mapping *importMapping, secrets []importSecret, args []string) error {
+	usage := func(format string) error {
+		return fmt.Errorf("usage: %s", format)
+	}
+
+	switch args[0] {
+	case "rename":
+		if len(args) != 3 {
+			return usage("rename <source> <destination>")
+		}
+		source, err := resolveImportSource(secrets, args[1])
+		if err != nil {
+			return err
+		}
+		mapping.set(importMappingRule{Source: source, Destination: args[2]})
+	case "skip":
+		if len(args) != 2 {
+			return usage("skip <source>")
+		}
+		source, err := resolveImportSource(secrets, args[1])
+		if err != nil {
+			return err
+		}
+		mapping.set(importMappingRule{Source: source, Skip: true})
+	case "merge":
+		if len(args) < 3 {
+			return usage("merge <destination> <source>...")
+		}
+		var sources []string
+		for _, arg := range args[2:] {
+			source, err := resolveImportSource(secrets, arg)
+			if err != nil {
+				return err
+			}
+			sources = append(sources, source)
+		}
+		for _, source := range sources {
+			mapping.set(importMappingRule{Source: source, Destination: args[1]})
+		}
+	case "reset":
+		if len(args) != 2 {
+			return usage("reset <source>")
+		}
+		source, err := resolveImportSource(secrets, args[1])
+		if err != nil {
+			return err
+		}
+		for i, rule := range mapping.Rules {
+			if rule.Source == source {
+				mapping.Rules = append(mapping.Rules[:i], mapping.Rules[i+1:]...)
+				break
+			}
+		}
+	case "save":
+		if len(args) != 2 {
+			return usage("save <file>")
+		}
+		return mapping.write(args[1])
+	case "abort", "quit", "exit":
+		return errAbortImport
+	default:
+		return fmt.Errorf("unknown command %s", args[0])
+	}
+	return nil
+}
+
+// resolveImportSource returns the source path referred to by a number or a path.
+// A path can refer to a secret or to a directory containing secrets.
+func resolveImportSource(secrets []importSecret, arg string) (string, error) {
+	n, err := strconv.Atoi(arg)
+	if err == nil {
+		if n < 1 || n > len(secrets) {
+			return "", ErrUnknownImportSource(arg)
+		}
+		return secrets[n-1].path, nil
+	}
+
+	source := strings.Trim(arg, "/")
+	for _, secret := range secrets {
+		if secret.path == source || strings.HasPrefix(secret.path, source+"/") {
+			return source, nil
+		}
+	}
+	return "", ErrUnknownImportSource(arg)
+}
+
+// printImportMapping prints every source with its number and destination.
+func printImportMapping(w io.Writer, mapping *importMapping, secrets []importSecret) error {
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	fmt.Fprintf(tw, "\t%s\t\t%s\n", "SOURCE", "DESTINATION")
+
+	items, _ := mapping.plan(secrets)
+	merged := map[string]bool{}
+	for _, item := range items {
+		if len(item.sources) > 1 {
+			merged[strings.ToLower(item.path)] = true
+		}
+	}
+
+	for i, secret := range secrets {
+		dest, ok := mapping.destination(secret.path)
+		switch {
+		case !ok:
+			dest = "(skipped)"
+		case merged[strings.ToLower(dest)]:
+			dest += " (merged)"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t=>\t%s\n", i+1, secret.path, dest)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,167 @@
+package secrethub
+
+import (
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestImportMappingPlan(t *testing.T) {
+	secrets := []importSecret{
+		{path: "My-Mail/username", data: []byte("alice")},
+		{path: "My-Mail/password", data: []byte("p4ssw0rd")},
+		{path: "My-Mail/notes", data: []byte("note")},
+		{path: "Servers/db/username", data: []byte("root")},
+		{path: "Servers/db/password", data: []byte("s3cr3t")},
+		{path: "Old/key", data: []byte("old")},
+	}
+
+	cases := map[string]struct {
+		mapping         importMapping
+		expectedPaths   []string
+		expectedData    map[string]string
+		expectedSkipped []string
+	}{
+		"no rules": {
+			mapping: importMapping{},
+			expectedPaths: []string{
+				"My-Mail/username",
+				"My-Mail/password",
+				"My-Mail/notes",
+				"Servers/db/username",
+				"Servers/db/password",
+				"Old/key",
+			},
+		},
+		"rename, skip and merge": {
+			mapping: importMapping{
+				Rules: []importMappingRule{
+					{Source: "My-Mail", Destination: "mail"},
+					{Source: "My-Mail/notes", Skip: true},
+					{Source: "Old", Skip: true},
+					{Source: "Servers/db/username", Destination: "db"},
+					{Source: "Servers/db/password", Destination: "db"},
+				},
+			},
+			expectedPaths: []string{
+				"mail/username",
+				"mail/password",
+				"db",
+			},
+			expectedData: map[string]string{
+				"mail/username": "alice",
+				"db":            "{\n  \"password\": \"s3cr3t\",\n  \"username\": \"root\"\n}",
+			},
+			expectedSkipped: []string{
+				"My-Mail/notes",
+				"Old/key",
+			},
+		},
+		"longest source wins": {
+			mapping: importMapping{
+				Rules: []importMappingRule{
+					{Source: "Servers/db/password", Destination: "db/pass"},
+					{Source: "Servers", Skip: true},
+					{Source: "My-Mail", Skip: true},
+					{Source: "Old", Skip: true},
+				},
+			},
+			expectedPaths: []string{
+				"db/pass",
+			},
+			expectedSkipped: []string{
+				"My-Mail/username",
+				"My-Mail/password",
+				"My-Mail/notes",
+				"Servers/db/username",
+				"Old/key",
+			},
+		},
+		"source prefix is not a directory": {
+			mapping: importMapping{
+				Rules: []importMappingRule{
+					{Source: "My", Skip: true},
+				},
+			},
+			expectedPaths: []string{
+				"My-Mail/username",
+				"My-Mail/password",
+				"My-Mail/notes",
+				"Servers/db/username",
+				"Servers/db/password",
+				"Old/key",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			items, skipped := tc.mapping.plan(secrets)
+
+			paths := make([]string, len(items))
+			for i, item := range items {
+				paths[i] = item.path
+				if expected, ok := tc.expectedData[item.path]; ok {
+					assert.Equal(t, string(item.data()), expected)
+				}
+			}
+
+			assert.Equal(t, paths, tc.expectedPaths)
+			assert.Equal(t, skipped, tc.expectedSkipped)
+		})
+	}
+}
+
+func TestApplyMappingCommand(t *testing.T) {
+	secrets := []importSecret{
+		{path: "My-Mail/username", data: []byte("alice")},
+		{path: "My-Mail/password", data: []byte("p4ssw0rd")},
+	}
+
+	cases := map[string]struct {
+		args     []string
+		expected []importMappingRule
+		err      error
+	}{
+		"rename by path": {
+			args:     []string{"rename", "My-Mail/", "mail"},
+			expected: []importMappingRule{{Source: "My-Mail", Destination: "mail"}},
+		},
+		"skip by number": {
+			args:     []string{"skip", "2"},
+			expected: []importMappingRule{{Source: "My-Mail/password", Skip: true}},
+		},
+		"merge": {
+			args: []string{"merge", "mail", "1", "2"},
+			expected: []importMappingRule{
+				{Source: "My-Mail/username", Destination: "mail"},
+				{Source: "My-Mail/password", Destination: "mail"},
+			},
+		},
+		"unknown number": {
+			args: []string{"skip", "3"},
+			err:  ErrUnknownImportSource("3"),
+		},
+		"unknown path": {
+			args: []string{"rename", "My", "mail"},
+			err:  ErrUnknownImportSource("My"),
+		},
+		"abort": {
+			args: []string{"abort"},
+			err:  errAbortImport,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mapping := &importMapping{}
+
+			err := applyMappingCommand(mapping, secrets, tc.args)
+
+			assert.Equal(t, err, tc.err)
+			if tc.err == nil {
+				assert.Equal(t, mapping.Rules, tc.expected)
+			}
+		})
+	}
+}
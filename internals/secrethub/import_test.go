@@ -0,0 +1,122 @@
+package secrethub
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestImportPlanPrint(t *testing.T) {
+	plan := &importPlan{
+		dest: api.DirPath("company/repo/imported"),
+		items: []importPlanItem{
+			{path: "mail/username"},
+			{path: "mail/password"},
+			{path: "db"},
+		},
+		skipped: []string{"My-Mail/notes"},
+		dirs: []string{
+			"company/repo/imported",
+			"company/repo/imported/mail",
+		},
+		existing: map[string]bool{
+			"company/repo/imported/mail/password": true,
+			"company/repo/imported/db":            true,
+		},
+		unchanged: map[string]bool{
+			"company/repo/imported/db": true,
+		},
+	}
+
+	var buf bytes.Buffer
+	err := plan.print(&buf)
+	assert.OK(t, err)
+	assert.Equal(t, buf.String(), ""+
+		"ACTION     PATH\n"+
+		"create     company/repo/imported/\n"+
+		"create     company/repo/imported/mail/\n"+
+		"write      company/repo/imported/mail/username\n"+
+		"overwrite  company/repo/imported/mail/password\n"+
+		"keep       company/repo/imported/db\n"+
+		"skip       My-Mail/notes\n",
+	)
+
+	buf.Reset()
+	plan.diffed = true
+	err = plan.print(&buf)
+	assert.OK(t, err)
+	assert.Equal(t, buf.String(), ""+
+		"ACTION     PATH                                 DIFF\n"+
+		"create     company/repo/imported/               \n"+
+		"create     company/repo/imported/mail/          \n"+
+		"write      company/repo/imported/mail/username  new\n"+
+		"overwrite  company/repo/imported/mail/password  changed\n"+
+		"keep       company/repo/imported/db             unchanged\n"+
+		"skip       My-Mail/notes                        \n",
+	)
+}
+
+func TestNewImportPlan_InvalidPath(t *testing.T) {
+	// Setup
+	client := fakeclient.Client{
+		DirService: &fakeclient.DirService{
+			GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+				t.Fatal("the destination is read before all paths are validated")
+				return nil, nil
+			},
+		},
+	}
+	items := []importPlanItem{
+		{path: "mail/username"},
+		{path: "mail/pa$$word"},
+	}
+
+	// Act
+	_, err := newImportPlan(client, api.DirPath("company/repo/imported"), items, nil)
+
+	// Assert
+	assert.Equal(t, err, api.ValidateSecretPath("company/repo/imported/mail/pa$$word"))
+}
+
+func TestImportSecrets_Summary(t *testing.T) {
+	// Setup
+	io := fakeui.NewIO(t)
+	var written []string
+	client := fakeclient.Client{
+		DirService: &fakeclient.DirService{
+			GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+				return nil, api.ErrDirNotFound
+			},
+			CreateAllFunc: func(path string) error {
+				return nil
+			},
+		},
+		SecretService: &fakeclient.SecretService{
+			WriteFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+				written = append(written, path)
+				return &api.SecretVersion{}, nil
+			},
+		},
+	}
+	secrets := []importSecret{
+		{path: "mail/username", data: []byte("user")},
+		{path: "mail/password", data: []byte("pass")},
+	}
+
+	// Act
+	err := importSecrets(io, client, api.DirPath("company/repo/imported"), secrets, importOptions{force: true})
+
+	// Assert
+	assert.OK(t, err)
+	assert.Equal(t, written, []string{"company/repo/imported/mail/username", "company/repo/imported/mail/password"})
+	assert.Equal(t, io.Out.String(), ""+
+		"Imported company/repo/imported/mail/username\n"+
+		"Imported company/repo/imported/mail/password\n"+
+		"Import complete! 2 secrets imported into company/repo/imported.\n",
+	)
+}
@@ -18,7 +18,9 @@ import (
 type InitCommand struct {
 	backupCode               string
 	setupCode                string
+	mnemonic                 bool
 	force                    bool
+	skipOnboarding           bool
 	io                       ui.IO
 	newUnauthenticatedClient newClientFunc
 	newClientWithCredentials func(credentials.Provider) (secrethub.ClientInterface, error)
@@ -41,7 +43,9 @@ func NewInitCommand(io ui.IO, newUnauthenticatedClient newClientFunc, newClientW
 func (cmd *InitCommand) Register(r command.Registerer) {
 	clause := r.Command("init", "Initialize the SecretHub client for first use on this device.")
 	clause.Flag("backup-code", "The backup code used to restore an existing account to this device.").StringVar(&cmd.backupCode)
+	clause.Flag("mnemonic", "Restore an existing account to this device from the recovery words created with `"+ApplicationName+" credential backup --mnemonic`. You are asked for the words.").BoolVar(&cmd.mnemonic)
 	clause.Flag("setup-code", "The setup code used to configure the CLI to use an account created on the website.").StringVar(&cmd.setupCode)
+	clause.Flag("skip-onboarding", "Do not guide me through the configuration of the CLI after setting up my account.").BoolVar(&cmd.skipOnboarding)
 	registerForceFlag(clause).BoolVar(&cmd.force)
 
 	command.BindAction(clause, cmd.Run)
@@ -61,13 +65,17 @@ func (cmd *InitCommand) Run() error {
 	if cmd.setupCode != "" && cmd.backupCode != "" {
 		return ErrFlagsConflict("--backup-code and --setup-code")
 	}
+	if cmd.mnemonic && (cmd.setupCode != "" || cmd.backupCode != "") {
+		return ErrFlagsConflict("--mnemonic and --backup-code or --setup-code")
+	}
 
 	credentialPath := cmd.credentialStore.ConfigDir().Credential().Path()
+	existing := storedCredentialBackend(cmd.credentialStore.ConfigDir())
 
-	if cmd.credentialStore.ConfigDir().Credential().Exists() && !cmd.force {
+	if existing.Exists() && !cmd.force {
 		confirmed, err := ui.AskYesNo(
 			cmd.io,
-			fmt.Sprintf("Already found a credential at %s, do you wish the re-initialize SecretHub on this device? (this will overwrite the credential)", credentialPath),
+			fmt.Sprintf("Already found a credential in %s, do you wish the re-initialize SecretHub on this device? (this will overwrite the credential)", existing),
 			ui.DefaultNo,
 		)
 		if err == ui.ErrCannotAsk {
@@ -85,7 +93,7 @@ func (cmd *InitCommand) Run() error {
 	var mode InitMode
 	if cmd.setupCode != "" {
 		mode = InitModeSetupCode
-	} else if cmd.backupCode != "" {
+	} else if cmd.backupCode != "" || cmd.mnemonic {
 		mode = InitModeBackupCode
 	}
 
@@ -97,6 +105,7 @@ func (cmd *InitCommand) Run() error {
 			[]string{
 				"Sign up for a new account",
 				"Use a backup code to recover an existing account",
+				"Use a setup code to link an account created on the website",
 			}, 3)
 		if err != nil {
 			return err
@@ -109,6 +118,12 @@ func (cmd *InitCommand) Run() error {
 			return nil
 		case 1:
 			mode = InitModeBackupCode
+		case 2:
+			mode = InitModeSetupCode
+			cmd.setupCode, err = ui.Ask(cmd.io, "What is your setup code?\n")
+			if err != nil {
+				return err
+			}
 		}
 	}
 
@@ -191,12 +206,26 @@ func (cmd *InitCommand) Run() error {
 			return err
 		}
 
+		err = cmd.onboard(client, passphrase != "")
+		if err != nil {
+			return err
+		}
+
 		fmt.Fprintf(cmd.io.Output(), "Setup complete. To read your first secret, run:\n\n    secrethub read %s\n\n", secretPath)
 		return nil
 	case InitModeBackupCode:
 		backupCode := cmd.backupCode
 
-		if backupCode == "" {
+		if backupCode == "" && cmd.mnemonic {
+			words, err := ui.AskAndValidate(cmd.io, "What are your recovery words? Enter them in order, separated by spaces.\n", 3, validateMnemonic)
+			if err != nil {
+				return err
+			}
+			backupCode, err = mnemonicToBackupCode(words)
+			if err != nil {
+				return err
+			}
+		} else if backupCode == "" {
 			var err error
 			backupCode, err = ui.AskAndValidate(cmd.io, "What is your backup code?\n", 3, credentials.ValidateBootstrapCode)
 			if err != nil {
@@ -264,12 +293,25 @@ func (cmd *InitCommand) Run() error {
 		if err != nil {
 			return err
 		}
-		return nil
+
+		client, err = cmd.newClientWithCredentials(credential)
+		if err != nil {
+			return err
+		}
+		return cmd.onboard(client, passphrase != "")
 	default:
 		return errors.New("invalid option")
 	}
 }
 
+// onboard guides the user through the configuration of the CLI, unless it is skipped.
+func (cmd *InitCommand) onboard(client secrethub.ClientInterface, passphrase bool) error {
+	if cmd.force || cmd.skipOnboarding {
+		return nil
+	}
+	return newOnboarding(cmd.io, client, passphrase || cmd.credentialStore.IsPassphraseSet()).Run()
+}
+
 func promptForDeviceName(io ui.IO) (string, error) {
 	deviceName := ""
 	question := "What is the name of this device?"
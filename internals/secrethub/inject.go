@@ -20,35 +20,45 @@ import (
 
 // Errors
 var (
-	ErrUnknownTemplateVersion = errMain.Code("unknown_template_version").ErrorPref("unknown template version: '%s' supported versions are 1, 2 and latest")
+	ErrUnknownTemplateVersion = errMain.Code("unknown_template_version").ErrorPref("unknown template version: '%s' supported versions are 1, 2, 3 and latest")
 	ErrReadFile               = errMain.Code("in_file_read_error").ErrorPref("could not read the input file %s: %s")
+	ErrInDirWithoutOutDir     = errMain.Code("in_dir_without_out_dir").Error("--in-dir and --out-dir must be used together")
+	ErrReadDir                = errMain.Code("in_dir_read_error").ErrorPref("could not read the input directory %s: %s")
+	ErrInjectFile             = errMain.Code("inject_file_error").ErrorPref("could not inject %s: %s")
 )
 
 // InjectCommand is a command to read a secret.
 type InjectCommand struct {
 	outFile                       string
 	inFile                        string
+	inDir                         string
+	outDir                        string
 	fileMode                      filemode.FileMode
 	force                         bool
 	io                            ui.IO
 	useClipboard                  bool
 	clearClipboardAfter           time.Duration
+	countdown                     bool
 	clipper                       clip.Clipper
 	osEnv                         []string
 	newClient                     newClientFunc
+	secretCache                   SecretCache
 	templateVars                  map[string]string
 	templateVersion               string
+	templateDirs                  []string
+	lenient                       bool
 	dontPromptMissingTemplateVars bool
 }
 
 // NewInjectCommand creates a new InjectCommand.
-func NewInjectCommand(io ui.IO, newClient newClientFunc) *InjectCommand {
+func NewInjectCommand(io ui.IO, newClient newClientFunc, secretCache SecretCache) *InjectCommand {
 	return &InjectCommand{
 		clipper:             clip.NewClipboard(),
 		osEnv:               os.Environ(),
 		clearClipboardAfter: defaultClearClipboardAfter,
 		io:                  io,
 		newClient:           newClient,
+		secretCache:         secretCache,
 		templateVars:        make(map[string]string),
 	}
 }
@@ -60,24 +70,35 @@ func (cmd *InjectCommand) Register(r command.Registerer) {
 	clause.Flag(
 		"clip",
 		fmt.Sprintf(
-			"Copy the injected template to the clipboard instead of stdout. The clipboard is automatically cleared after %s.",
+			"Copy the injected template to the clipboard instead of stdout. The clipboard is automatically cleared after %s, or the duration set with --clear-after.",
 			units.HumanDuration(cmd.clearClipboardAfter),
 		),
 	).Short('c').BoolVar(&cmd.useClipboard)
+	registerClearAfterFlag(clause).DurationVar(&cmd.clearClipboardAfter)
+	registerCountdownFlag(clause).BoolVar(&cmd.countdown)
 	clause.Flag("in-file", "The filename of a template file to inject.").Short('i').StringVar(&cmd.inFile)
 	clause.Flag("out-file", "Write the injected template to a file instead of stdout.").Short('o').StringVar(&cmd.outFile)
 	clause.Flag("file", "").Hidden().StringVar(&cmd.outFile) // Alias of --out-file (for backwards compatibility)
+	clause.Flag("in-dir", "A directory of template files to inject. All files in the directory and its subdirectories are injected into --out-dir.").StringVar(&cmd.inDir)
+	clause.Flag("out-dir", "Write the injected templates of --in-dir to this directory, with the same relative paths and file modes as the templates.").StringVar(&cmd.outDir)
 	clause.Flag("file-mode", "Set filemode for the output file if it does not yet exist. Defaults to 0600 (read and write for current user) and is ignored without the --out-file flag.").Default("0600").SetValue(&cmd.fileMode)
 	clause.Flag("var", "Define the value for a template variable with `VAR=VALUE`, e.g. --var env=prod").Short('v').StringMapVar(&cmd.templateVars)
-	clause.Flag("template-version", "The template syntax version to be used. The options are v1, v2, latest or auto to automatically detect the version.").Default("auto").StringVar(&cmd.templateVersion)
+	clause.Flag("template-version", "The template syntax version to be used. The options are v1, v2, v3, latest or auto to automatically detect the version.").Default("auto").StringVar(&cmd.templateVersion)
+	clause.Flag("template-dir", "A directory to look up the templates included with {{ include \"name\" }} in. Can be repeated to search multiple directories in order. Defaults to the directory of the --in-file or the current directory when reading from stdin.").StringsVar(&cmd.templateDirs)
+	clause.Flag("strict", "Return an error when a secret in the template does not exist, unless the secret is given a default value with {{ path/to/secret | default \"fallback\" }}. This is enabled by default.").SetValue(invertedBoolValue{target: &cmd.lenient})
+	clause.Flag("lenient", "Use an empty value for secrets in the template that do not exist, instead of returning an error.").BoolVar(&cmd.lenient)
 	clause.Flag("no-prompt", "Do not prompt when a template variable is missing and return an error instead.").BoolVar(&cmd.dontPromptMissingTemplateVars)
-	clause.Flag("force", "Overwrite the output file if it already exists, without prompting for confirmation. This flag is ignored if no --out-file is supplied.").Short('f').BoolVar(&cmd.force)
+	clause.Flag("force", "Overwrite the output file if it already exists, without prompting for confirmation. This flag is ignored if no --out-file or --out-dir is supplied.").Short('f').BoolVar(&cmd.force)
 
 	command.BindAction(clause, cmd.Run)
 }
 
 // Run handles the command with the options as specified in the command.
 func (cmd *InjectCommand) Run() error {
+	if cmd.inDir != "" || cmd.outDir != "" {
+		return cmd.injectDir()
+	}
+
 	if cmd.useClipboard && cmd.outFile != "" {
 		return ErrFlagsConflict("--clip and --file")
 	}
@@ -101,29 +122,12 @@ func (cmd *InjectCommand) Run() error {
 		}
 	}
 
-	osEnv, _ := parseKeyValueStringsToMap(cmd.osEnv)
-
-	var templateVariableReader tpl.VariableReader
-	templateVariableReader, err = newVariableReader(osEnv, cmd.templateVars)
+	varReader, secretReader, err := cmd.readers()
 	if err != nil {
 		return err
 	}
 
-	if !cmd.dontPromptMissingTemplateVars {
-		templateVariableReader = newPromptMissingVariableReader(templateVariableReader, cmd.io)
-	}
-
-	parser, err := getTemplateParser(raw, cmd.templateVersion)
-	if err != nil {
-		return err
-	}
-
-	template, err := parser.Parse(string(raw), 1, 1)
-	if err != nil {
-		return err
-	}
-
-	injected, err := template.Evaluate(templateVariableReader, newSecretReader(cmd.newClient))
+	injected, err := cmd.inject(raw, filepath.Dir(cmd.inFile), varReader, secretReader)
 	if err != nil {
 		return err
 	}
@@ -136,6 +140,13 @@ func (cmd *InjectCommand) Run() error {
 		}
 
 		fmt.Fprintln(cmd.io.Output(), fmt.Sprintf("Copied injected template to clipboard. It will be cleared after %s.", units.HumanDuration(cmd.clearClipboardAfter)))
+
+		if cmd.countdown {
+			err = waitForClipboardClear(cmd.io, out, cmd.clearClipboardAfter, cmd.clipper)
+			if err != nil {
+				return err
+			}
+		}
 	} else if cmd.outFile != "" {
 		_, err := os.Stat(cmd.outFile)
 		if err == nil && !cmd.force {
@@ -178,3 +189,210 @@ func (cmd *InjectCommand) Run() error {
 
 	return nil
 }
+
+// readers returns the readers for the template variables and the secrets in the templates.
+func (cmd *InjectCommand) readers() (tpl.VariableReader, tpl.SecretReader, error) {
+	osEnv, _ := parseKeyValueStringsToMap(cmd.osEnv)
+
+	var varReader tpl.VariableReader
+	varReader, err := newVariableReader(osEnv, cmd.templateVars)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !cmd.dontPromptMissingTemplateVars {
+		varReader = newPromptMissingVariableReader(varReader, cmd.io)
+	}
+
+	var secretReader tpl.SecretReader = newCachedSecretReader(newSecretReader(cmd.newClient), cmd.secretCache)
+	if cmd.lenient {
+		secretReader = newIgnoreMissingSecretReader(secretReader)
+	}
+
+	return varReader, secretReader, nil
+}
+
+// inject parses the raw template and injects the variables and secrets into it.
+// Included templates are looked up in the --template-dir directories, or in the
+// given directory of the template when none are set.
+func (cmd *InjectCommand) inject(raw []byte, dir string, varReader tpl.VariableReader, secretReader tpl.SecretReader) (string, error) {
+	templateDirs := cmd.templateDirs
+	if len(templateDirs) == 0 {
+		templateDirs = []string{dir}
+	}
+
+	parser, err := getTemplateParser(raw, cmd.templateVersion, templateDirs...)
+	if err != nil {
+		return "", err
+	}
+
+	template, err := parser.Parse(string(raw), 1, 1)
+	if err != nil {
+		return "", err
+	}
+
+	return template.Evaluate(varReader, secretReader)
+}
+
+// injectedFile is a template file of --in-dir that has been injected.
+type injectedFile struct {
+	path string
+	mode os.FileMode
+	data []byte
+}
+
+// injectDir injects all template files in --in-dir and writes them to --out-dir.
+// All templates are injected before any file is written, so no files are written
+// when one of the templates cannot be injected.
+func (cmd *InjectCommand) injectDir() error {
+	if cmd.inDir == "" || cmd.outDir == "" {
+		return ErrInDirWithoutOutDir
+	}
+	if cmd.inFile != "" {
+		return ErrFlagsConflict("--in-dir and --in-file")
+	}
+	if cmd.outFile != "" {
+		return ErrFlagsConflict("--out-dir and --out-file")
+	}
+	if cmd.useClipboard {
+		return ErrFlagsConflict("--out-dir and --clip")
+	}
+
+	outDir, err := filepath.Abs(cmd.outDir)
+	if err != nil {
+		return ErrCannotWrite(cmd.outDir, err)
+	}
+
+	varReader, secretReader, err := cmd.readers()
+	if err != nil {
+		return err
+	}
+
+	var files []injectedFile
+	dirModes := map[string]os.FileMode{}
+	err = filepath.Walk(cmd.inDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return ErrReadDir(cmd.inDir, err)
+		}
+
+		rel, err := filepath.Rel(cmd.inDir, path)
+		if err != nil {
+			return ErrReadDir(cmd.inDir, err)
+		}
+		target := filepath.Join(outDir, rel)
+
+		if info.IsDir() {
+			abs, err := filepath.Abs(path)
+			if err == nil && abs == outDir {
+				// Skip the output directory when it is within the input directory,
+				// so the injected templates are not injected again.
+				return filepath.SkipDir
+			}
+			dirModes[target] = info.Mode().Perm()
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			return ErrReadFile(path, err)
+		}
+
+		injected, err := cmd.inject(raw, filepath.Dir(path), varReader, secretReader)
+		if err != nil {
+			return ErrInjectFile(path, err)
+		}
+
+		files = append(files, injectedFile{
+			path: target,
+			mode: info.Mode().Perm(),
+			data: posix.AddNewLine([]byte(injected)),
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !cmd.force {
+		var existing int
+		for _, file := range files {
+			_, err := os.Stat(file.path)
+			if err == nil {
+				existing++
+			}
+		}
+
+		if existing > 0 {
+			if cmd.io.IsOutputPiped() {
+				return ErrFileAlreadyExists
+			}
+
+			confirmed, err := ui.AskYesNo(
+				cmd.io,
+				fmt.Sprintf(
+					"%s already exist in %s, overwrite them?",
+					pluralize("file", "files", existing),
+					cmd.outDir,
+				),
+				ui.DefaultNo,
+			)
+			if err != nil {
+				return err
+			}
+
+			if !confirmed {
+				fmt.Fprintln(cmd.io.Output(), "Aborting.")
+				return nil
+			}
+		}
+	}
+
+	for _, file := range files {
+		err = mkdirAllWithModes(filepath.Dir(file.path), dirModes)
+		if err != nil {
+			return ErrCannotWrite(file.path, err)
+		}
+
+		err = ioutil.WriteFile(file.path, file.data, file.mode)
+		if err != nil {
+			return ErrCannotWrite(file.path, err)
+		}
+
+		// The mode of existing files is not changed by WriteFile, so it is set explicitly.
+		err = os.Chmod(file.path, file.mode)
+		if err != nil {
+			return ErrCannotWrite(file.path, err)
+		}
+
+		fmt.Fprintf(cmd.io.Output(), "%s\n", file.path)
+	}
+
+	return nil
+}
+
+// mkdirAllWithModes creates the directory and its missing parents. Directories that
+// have a mode in the given map are created with that mode, others with mode 0755.
+func mkdirAllWithModes(dir string, modes map[string]os.FileMode) error {
+	_, err := os.Stat(dir)
+	if err == nil {
+		return nil
+	}
+
+	err = mkdirAllWithModes(filepath.Dir(dir), modes)
+	if err != nil {
+		return err
+	}
+
+	mode, ok := modes[dir]
+	if !ok {
+		mode = 0755
+	}
+	err = os.Mkdir(dir, mode)
+	if err != nil && !os.IsExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package secrethub
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestInjectCommand_injectDir(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "secrethub-inject-test")
+	assert.OK(t, err)
+	defer os.RemoveAll(tempDir)
+
+	inDir := filepath.Join(tempDir, "templates")
+	err = os.MkdirAll(filepath.Join(inDir, "db"), 0700)
+	assert.OK(t, err)
+
+	templates := map[string]struct {
+		content string
+		mode    os.FileMode
+	}{
+		"app.conf": {
+			content: `env={{ var "env" }}`,
+			mode:    0644,
+		},
+		"db/db.conf": {
+			content: `{{ if eq (var "env") "prod" }}host=db.example.com{{ else }}host=localhost{{ end }}`,
+			mode:    0600,
+		},
+	}
+	for name, template := range templates {
+		err = ioutil.WriteFile(filepath.Join(inDir, name), []byte(template.content), template.mode)
+		assert.OK(t, err)
+	}
+
+	newCommand := func(outDir string) (*InjectCommand, *fakeui.FakeIO) {
+		io := fakeui.NewIO(t)
+		return &InjectCommand{
+			io:                            io,
+			inDir:                         inDir,
+			outDir:                        outDir,
+			templateVars:                  map[string]string{"env": "prod"},
+			templateVersion:               "auto",
+			dontPromptMissingTemplateVars: true,
+		}, io
+	}
+
+	t.Run("success", func(t *testing.T) {
+		outDir := filepath.Join(tempDir, "rendered")
+		cmd, io := newCommand(outDir)
+
+		err := cmd.Run()
+		assert.OK(t, err)
+
+		expected := map[string]struct {
+			content string
+			mode    os.FileMode
+		}{
+			"app.conf": {
+				content: "env=prod\n",
+				mode:    0644,
+			},
+			"db/db.conf": {
+				content: "host=db.example.com\n",
+				mode:    0600,
+			},
+		}
+		for name, file := range expected {
+			path := filepath.Join(outDir, name)
+			content, err := ioutil.ReadFile(path)
+			assert.OK(t, err)
+			assert.Equal(t, string(content), file.content)
+
+			info, err := os.Stat(path)
+			assert.OK(t, err)
+			assert.Equal(t, info.Mode().Perm(), file.mode)
+		}
+
+		info, err := os.Stat(filepath.Join(outDir, "db"))
+		assert.OK(t, err)
+		assert.Equal(t, info.Mode().Perm(), os.FileMode(0700))
+
+		assert.Equal(t, io.Out.String(), filepath.Join(outDir, "app.conf")+"\n"+filepath.Join(outDir, "db", "db.conf")+"\n")
+	})
+
+	t.Run("existing files", func(t *testing.T) {
+		outDir := filepath.Join(tempDir, "existing")
+		err := os.MkdirAll(outDir, 0755)
+		assert.OK(t, err)
+		err = ioutil.WriteFile(filepath.Join(outDir, "app.conf"), []byte("old"), 0644)
+		assert.OK(t, err)
+
+		cmd, io := newCommand(outDir)
+		io.Out.Piped = true
+
+		err = cmd.Run()
+		assert.Equal(t, err, ErrFileAlreadyExists)
+
+		cmd, _ = newCommand(outDir)
+		cmd.force = true
+
+		err = cmd.Run()
+		assert.OK(t, err)
+
+		content, err := ioutil.ReadFile(filepath.Join(outDir, "app.conf"))
+		assert.OK(t, err)
+		assert.Equal(t, string(content), "env=prod\n")
+	})
+
+	t.Run("out dir within in dir", func(t *testing.T) {
+		outDir := filepath.Join(inDir, "rendered")
+		cmd, _ := newCommand(outDir)
+
+		err := cmd.Run()
+		assert.OK(t, err)
+
+		cmd, _ = newCommand(outDir)
+		cmd.force = true
+
+		err = cmd.Run()
+		assert.OK(t, err)
+
+		_, err = os.Stat(filepath.Join(outDir, "rendered"))
+		assert.Equal(t, os.IsNotExist(err), true)
+	})
+
+	t.Run("in dir without out dir", func(t *testing.T) {
+		cmd, _ := newCommand("")
+
+		err := cmd.Run()
+		assert.Equal(t, err, ErrInDirWithoutOutDir)
+	})
+}
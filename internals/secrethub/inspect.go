@@ -11,9 +11,13 @@ import (
 // a path as argument that is not a repository- or secret-path.
 var ErrInspectResourceNotSupported = errMain.Code("inspect_resource_not_supported").Error("currently only inspecting repositories or secrets is supported")
 
+// ErrChecksumRepo is returned when the --checksum flag is used to inspect a repository.
+var ErrChecksumRepo = errMain.Code("checksum_repo").Error("checksums can only be printed when inspecting a secret")
+
 // InspectCommand prints information about a repository or a secret.
 type InspectCommand struct {
 	path          api.Path
+	checksum      bool
 	io            ui.IO
 	newClient     newClientFunc
 	timeFormatter TimeFormatter
@@ -31,7 +35,10 @@ func NewInspectCommand(io ui.IO, newClient newClientFunc) *InspectCommand {
 // Register registers the command, arguments and flags on the provided Registerer.
 func (cmd *InspectCommand) Register(r command.Registerer) {
 	clause := r.Command("inspect", "Print details of a resource.")
+	clause.HelpLong("When inspecting a secret, --checksum adds the SHA-256 checksum of the content of every version to the output. " +
+		"Comparing checksums shows whether two environments hold identical secrets, without revealing their values.")
 	clause.Arg("repo or secret-path", "Path to the repository or the secret to inspect "+repoPathPlaceHolder+" or "+secretPathOptionalVersionPlaceHolder).Required().SetValue(&cmd.path)
+	clause.Flag("checksum", "Print the SHA-256 checksum of the secret content for every version. This requires read access to the secret.").BoolVar(&cmd.checksum)
 
 	command.BindAction(clause, cmd.Run)
 }
@@ -40,6 +47,9 @@ func (cmd *InspectCommand) Register(r command.Registerer) {
 func (cmd *InspectCommand) Run() error {
 	repoPath, err := cmd.path.ToRepoPath()
 	if err == nil {
+		if cmd.checksum {
+			return ErrChecksumRepo
+		}
 		repoInspectCmd := NewRepoInspectCommand(
 			cmd.io,
 			cmd.newClient,
@@ -51,18 +61,22 @@ func (cmd *InspectCommand) Run() error {
 	secretPath, err := cmd.path.ToSecretPath()
 	if err == nil {
 		if secretPath.HasVersion() {
-			return NewInspectSecretVersionCommand(
+			inspectSecretVersionCmd := NewInspectSecretVersionCommand(
 				secretPath,
 				cmd.io,
 				cmd.newClient,
-			).Run()
+			)
+			inspectSecretVersionCmd.checksum = cmd.checksum
+			return inspectSecretVersionCmd.Run()
 		}
 
-		return NewInspectSecretCommand(
+		inspectSecretCmd := NewInspectSecretCommand(
 			secretPath,
 			cmd.io,
 			cmd.newClient,
-		).Run()
+		)
+		inspectSecretCmd.checksum = cmd.checksum
+		return inspectSecretCmd.Run()
 	}
 
 	return ErrInspectResourceNotSupported
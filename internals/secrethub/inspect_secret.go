@@ -12,6 +12,7 @@ import (
 // InspectSecretCommand prints out a secret's details.
 type InspectSecretCommand struct {
 	path          api.SecretPath
+	checksum      bool
 	io            ui.IO
 	newClient     newClientFunc
 	timeFormatter TimeFormatter
@@ -39,12 +40,24 @@ func (cmd *InspectSecretCommand) Run() error {
 		return err
 	}
 
-	versions, err := client.Secrets().Versions().ListWithoutData(cmd.path.Value())
+	var versions []*api.SecretVersion
+	if cmd.checksum {
+		versions, err = client.Secrets().Versions().ListWithData(cmd.path.Value())
+	} else {
+		versions, err = client.Secrets().Versions().ListWithoutData(cmd.path.Value())
+	}
 	if err != nil {
 		return err
 	}
 
-	output, err := cli.PrettyJSON(newSecretOutput(secret.Secret, versions, cmd.timeFormatter))
+	out := newSecretOutput(secret.Secret, versions, cmd.timeFormatter)
+	if cmd.checksum {
+		for i, version := range versions {
+			out.Versions[i].SHA256 = checksum(version.Data)
+		}
+	}
+
+	output, err := cli.PrettyJSON(out)
 	if err != nil {
 		return err
 	}
@@ -73,6 +73,65 @@ func TestInspectSecret_Run(t *testing.T) {
 				"    ]\n" +
 				"}\n",
 		},
+		"success with checksums": {
+			cmd: InspectSecretCommand{
+				path:     "foo/bar/secret",
+				checksum: true,
+				timeFormatter: &fakes.TimeFormatter{
+					Response: "2018-01-01T01:01:01+01:00",
+				},
+			},
+			secretVersionService: fakeclient.SecretVersionService{
+				GetWithoutDataFunc: func(path string) (*api.SecretVersion, error) {
+					return &api.SecretVersion{
+						Secret: &api.Secret{
+							Name:         "secret",
+							CreatedAt:    time.Date(2018, 1, 1, 1, 1, 1, 1, time.UTC),
+							VersionCount: 2,
+						},
+						Version:   2,
+						CreatedAt: time.Date(2018, 1, 1, 1, 1, 1, 1, time.UTC),
+						Status:    api.StatusOK,
+					}, nil
+				},
+				ListWithDataFunc: func(path string) ([]*api.SecretVersion, error) {
+					return []*api.SecretVersion{
+						{
+							Version:   1,
+							Data:      []byte("secret"),
+							CreatedAt: time.Date(2018, 1, 1, 1, 1, 1, 1, time.UTC),
+							Status:    api.StatusOK,
+						},
+						{
+							Version:   2,
+							Data:      []byte(""),
+							CreatedAt: time.Date(2018, 1, 1, 1, 1, 1, 1, time.UTC),
+							Status:    api.StatusOK,
+						},
+					}, nil
+				},
+			},
+			out: "" +
+				"{\n" +
+				"    \"Name\": \"secret\",\n" +
+				"    \"CreatedAt\": \"2018-01-01T01:01:01+01:00\",\n" +
+				"    \"VersionCount\": 2,\n" +
+				"    \"Versions\": [\n" +
+				"        {\n" +
+				"            \"Version\": 1,\n" +
+				"            \"CreatedAt\": \"2018-01-01T01:01:01+01:00\",\n" +
+				"            \"Status\": \"ok\",\n" +
+				"            \"SHA256\": \"2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b\"\n" +
+				"        },\n" +
+				"        {\n" +
+				"            \"Version\": 2,\n" +
+				"            \"CreatedAt\": \"2018-01-01T01:01:01+01:00\",\n" +
+				"            \"Status\": \"ok\",\n" +
+				"            \"SHA256\": \"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855\"\n" +
+				"        }\n" +
+				"    ]\n" +
+				"}\n",
+		},
 		"no secret": {
 			cmd: InspectSecretCommand{
 				path: "foo/bar/secret",
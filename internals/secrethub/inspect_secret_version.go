@@ -1,6 +1,8 @@
 package secrethub
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 
 	"github.com/secrethub/secrethub-cli/internals/cli"
@@ -12,6 +14,7 @@ import (
 // InspectSecretVersionCommand prints out the details of a secret version in JSON format.
 type InspectSecretVersionCommand struct {
 	path          api.SecretPath
+	checksum      bool
 	io            ui.IO
 	newClient     newClientFunc
 	timeFormatter TimeFormatter
@@ -34,12 +37,22 @@ func (cmd *InspectSecretVersionCommand) Run() error {
 		return err
 	}
 
-	version, err := client.Secrets().Versions().GetWithoutData(cmd.path.Value())
+	var version *api.SecretVersion
+	if cmd.checksum {
+		version, err = client.Secrets().Versions().GetWithData(cmd.path.Value())
+	} else {
+		version, err = client.Secrets().Versions().GetWithoutData(cmd.path.Value())
+	}
 	if err != nil {
 		return err
 	}
 
-	output, err := cli.PrettyJSON(newSecretVersionOutput(version, cmd.timeFormatter))
+	out := newSecretVersionOutput(version, cmd.timeFormatter)
+	if cmd.checksum {
+		out.SHA256 = checksum(version.Data)
+	}
+
+	output, err := cli.PrettyJSON(out)
 	if err != nil {
 		return err
 	}
@@ -62,4 +75,11 @@ type secretVersionOutput struct {
 	Version   int
 	CreatedAt string
 	Status    string
+	SHA256    string `json:",omitempty"`
+}
+
+// checksum returns the hex encoded SHA-256 checksum of the data.
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
 }
@@ -47,6 +47,32 @@ func TestInspectSecretVersion_Run(t *testing.T) {
 				"    \"Status\": \"ok\"\n" +
 				"}\n",
 		},
+		"success with checksum": {
+			cmd: InspectSecretVersionCommand{
+				path:     "foo/bar/secret:1",
+				checksum: true,
+				timeFormatter: &fakes.TimeFormatter{
+					Response: "2018-01-01T01:01:01+01:00",
+				},
+			},
+			secretVersionService: fakeclient.SecretVersionService{
+				GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+					return &api.SecretVersion{
+						Version:   1,
+						Data:      []byte("secret"),
+						CreatedAt: time.Date(2018, 1, 1, 1, 1, 1, 1, time.UTC),
+						Status:    api.StatusOK,
+					}, nil
+				},
+			},
+			out: "" +
+				"{\n" +
+				"    \"Version\": 1,\n" +
+				"    \"CreatedAt\": \"2018-01-01T01:01:01+01:00\",\n" +
+				"    \"Status\": \"ok\",\n" +
+				"    \"SHA256\": \"2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b\"\n" +
+				"}\n",
+		},
 		"client not fount": {
 			newClientErr: testErr,
 			err:          testErr,
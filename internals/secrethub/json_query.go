@@ -0,0 +1,204 @@
+package secrethub
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/secrethub/secrethub-go/internals/errio"
+)
+
+// Errors
+var (
+	errQuery                = errio.Namespace("query")
+	ErrInvalidQuery         = errQuery.Code("invalid_query").ErrorPref("invalid query %s: %s")
+	ErrSecretNotJSON        = errQuery.Code("secret_not_json").ErrorPref("cannot query the secret, because it does not contain valid JSON: %s")
+	ErrQueryFieldNotFound   = errQuery.Code("field_not_found").ErrorPref("the secret does not contain the field %s")
+	ErrQueryIndexOutOfRange = errQuery.Code("index_out_of_range").ErrorPref("the index of %s is out of range: the array has %d elements")
+	ErrQueryWrongType       = errQuery.Code("wrong_type").ErrorPref("cannot get %s from a value of type %s")
+)
+
+// queryJSON returns the field of a JSON document that is selected by a jq-like query,
+// e.g. .database.password or .hosts[0]. Strings are returned without quotes, so they can be used directly.
+// Other values are returned as JSON.
+func queryJSON(data []byte, query string) ([]byte, error) {
+	selectors, err := parseJSONQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// Keep numbers as they are, instead of converting them to floats.
+	decoder.UseNumber()
+
+	var value interface{}
+	err = decoder.Decode(&value)
+	if err != nil {
+		return nil, ErrSecretNotJSON(err)
+	}
+
+	path := ""
+	for _, selector := range selectors {
+		path += selector.String()
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if selector.isIndex {
+				return nil, ErrQueryWrongType(path, "object")
+			}
+			field, ok := v[selector.key]
+			if !ok {
+				return nil, ErrQueryFieldNotFound(path)
+			}
+			value = field
+		case []interface{}:
+			if !selector.isIndex {
+				return nil, ErrQueryWrongType(path, "array")
+			}
+			index := selector.index
+			if index < 0 {
+				index += len(v)
+			}
+			if index < 0 || index >= len(v) {
+				return nil, ErrQueryIndexOutOfRange(path, len(v))
+			}
+			value = v[index]
+		default:
+			return nil, ErrQueryWrongType(path, jsonTypeName(value))
+		}
+	}
+
+	if s, ok := value.(string); ok {
+		return []byte(s), nil
+	}
+	return json.Marshal(value)
+}
+
+// jsonSelector selects a field of an object by its key or an element of an array by its index.
+type jsonSelector struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// String returns the selector in the query syntax.
+func (s jsonSelector) String() string {
+	if s.isIndex {
+		return "[" + strconv.Itoa(s.index) + "]"
+	}
+	if isJSONQueryIdentifier(s.key) {
+		return "." + s.key
+	}
+	return "." + strconv.Quote(s.key)
+}
+
+// parseJSONQuery parses a query like .database.password, .hosts[0], ."key.with.dots" or .["key"].
+// The query . selects the whole document.
+func parseJSONQuery(query string) ([]jsonSelector, error) {
+	if !strings.HasPrefix(query, ".") && !strings.HasPrefix(query, "[") {
+		return nil, ErrInvalidQuery(query, "a query must start with a . or [")
+	}
+
+	if query == "." {
+		return nil, nil
+	}
+
+	var selectors []jsonSelector
+	rest := query
+
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, `."`) || strings.HasPrefix(rest, `["`):
+			quoted, ok := quotedPrefix(rest[1:])
+			if !ok {
+				return nil, ErrInvalidQuery(query, "unterminated quoted key")
+			}
+			key, err := strconv.Unquote(quoted)
+			if err != nil {
+				return nil, ErrInvalidQuery(query, err)
+			}
+			selectors = append(selectors, jsonSelector{key: key})
+
+			bracket := rest[0] == '['
+			rest = rest[1+len(quoted):]
+			if bracket {
+				if !strings.HasPrefix(rest, "]") {
+					return nil, ErrInvalidQuery(query, "missing ]")
+				}
+				rest = rest[1:]
+			}
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, ErrInvalidQuery(query, "missing ]")
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil {
+				return nil, ErrInvalidQuery(query, "an array index must be a number")
+			}
+			selectors = append(selectors, jsonSelector{index: index, isIndex: true})
+			rest = rest[end+1:]
+		case strings.HasPrefix(rest, ".["):
+			// Like jq, .[0] is the same as [0].
+			rest = rest[1:]
+		case strings.HasPrefix(rest, "."):
+			end := 1
+			for end < len(rest) && isJSONQueryIdentifierChar(rune(rest[end])) {
+				end++
+			}
+			if end == 1 {
+				return nil, ErrInvalidQuery(query, "expected a field name after .")
+			}
+			selectors = append(selectors, jsonSelector{key: rest[1:end]})
+			rest = rest[end:]
+		default:
+			return nil, ErrInvalidQuery(query, "expected . or [ before "+rest)
+		}
+	}
+	return selectors, nil
+}
+
+// quotedPrefix returns the double quoted string at the start of s, including its quotes.
+func quotedPrefix(s string) (string, bool) {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return s[:i+1], true
+		}
+	}
+	return "", false
+}
+
+// isJSONQueryIdentifier returns whether the key can be used in a query without quotes.
+func isJSONQueryIdentifier(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if !isJSONQueryIdentifierChar(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isJSONQueryIdentifierChar(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '-'
+}
+
+// jsonTypeName returns the name of the JSON type of a decoded value.
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return "unknown"
+}
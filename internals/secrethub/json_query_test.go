@@ -0,0 +1,104 @@
+package secrethub
+
+import (
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestQueryJSON(t *testing.T) {
+	data := []byte(`{
+		"database": {"host": "db.local", "port": 5432, "password": "s3cr3t"},
+		"hosts": ["a.local", "b.local"],
+		"key.with.dots": true,
+		"empty": null
+	}`)
+
+	cases := map[string]struct {
+		query    string
+		expected string
+		err      error
+	}{
+		"nested string": {
+			query:    ".database.password",
+			expected: "s3cr3t",
+		},
+		"number": {
+			query:    ".database.port",
+			expected: "5432",
+		},
+		"object": {
+			query:    ".database",
+			expected: `{"host":"db.local","password":"s3cr3t","port":5432}`,
+		},
+		"array index": {
+			query:    ".hosts[1]",
+			expected: "b.local",
+		},
+		"negative array index": {
+			query:    ".hosts[-1]",
+			expected: "b.local",
+		},
+		"quoted key": {
+			query:    `."key.with.dots"`,
+			expected: "true",
+		},
+		"bracket key": {
+			query:    `.["database"].host`,
+			expected: "db.local",
+		},
+		"null": {
+			query:    ".empty",
+			expected: "null",
+		},
+		"whole document": {
+			query:    ".",
+			expected: `{"database":{"host":"db.local","password":"s3cr3t","port":5432},"empty":null,"hosts":["a.local","b.local"],"key.with.dots":true}`,
+		},
+		"field not found": {
+			query: ".database.user",
+			err:   ErrQueryFieldNotFound(".database.user"),
+		},
+		"index out of range": {
+			query: ".hosts[2]",
+			err:   ErrQueryIndexOutOfRange(".hosts[2]", 2),
+		},
+		"field of string": {
+			query: ".database.host.name",
+			err:   ErrQueryWrongType(".database.host.name", "string"),
+		},
+		"index of object": {
+			query: ".database[0]",
+			err:   ErrQueryWrongType(".database[0]", "object"),
+		},
+		"invalid query": {
+			query: "database",
+			err:   ErrInvalidQuery("database", "a query must start with a . or ["),
+		},
+		"unterminated quote": {
+			query: `."database`,
+			err:   ErrInvalidQuery(`."database`, "unterminated quoted key"),
+		},
+		"invalid index": {
+			query: ".hosts[first]",
+			err:   ErrInvalidQuery(".hosts[first]", "an array index must be a number"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := queryJSON(data, tc.query)
+			assert.Equal(t, err, tc.err)
+			if tc.err == nil {
+				assert.Equal(t, string(actual), tc.expected)
+			}
+		})
+	}
+}
+
+func TestQueryJSON_NotJSON(t *testing.T) {
+	_, err := queryJSON([]byte("not json"), ".field")
+	if err == nil {
+		t.Fatal("expected an error for a secret that is not JSON")
+	}
+}
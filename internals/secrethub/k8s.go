@@ -0,0 +1,147 @@
+package secrethub
+
+import (
+	"fmt"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/errio"
+)
+
+// Errors
+var (
+	errK8s = errio.Namespace("k8s")
+)
+
+// K8sCommand handles running SecretHub inside a Kubernetes cluster.
+type K8sCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+	logger    cli.Logger
+}
+
+// NewK8sCommand creates a new K8sCommand.
+func NewK8sCommand(io ui.IO, newClient newClientFunc, logger cli.Logger) *K8sCommand {
+	return &K8sCommand{
+		io:        io,
+		newClient: newClient,
+		logger:    logger,
+	}
+}
+
+// Register registers the command and its sub-commands on the provided Registerer.
+func (cmd *K8sCommand) Register(r command.Registerer) {
+	clause := r.Command("k8s", "Run SecretHub inside a Kubernetes cluster.")
+	NewK8sOperatorCommand(cmd.io, cmd.newClient, cmd.logger).Register(clause)
+	NewK8sInjectWebhookCommand(cmd.io, cmd.logger).Register(clause)
+	NewK8sCRDCommand(cmd.io).Register(clause)
+}
+
+// K8sCRDCommand prints the CustomResourceDefinition of the SecretHubSecret resource.
+type K8sCRDCommand struct {
+	io ui.IO
+}
+
+// NewK8sCRDCommand creates a new K8sCRDCommand.
+func NewK8sCRDCommand(io ui.IO) *K8sCRDCommand {
+	return &K8sCRDCommand{
+		io: io,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *K8sCRDCommand) Register(r command.Registerer) {
+	clause := r.Command("crd", "Print the CustomResourceDefinition of the "+k8sCRDKind+" resource, to install it with kubectl apply -f -.")
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run prints the CustomResourceDefinition.
+func (cmd *K8sCRDCommand) Run() error {
+	_, err := fmt.Fprint(cmd.io.Output(), k8sCRDManifest)
+	return err
+}
+
+// k8sCRDManifest is the CustomResourceDefinition of the SecretHubSecret resource.
+const k8sCRDManifest = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: ` + k8sCRDPlural + `.` + k8sCRDGroup + `
+spec:
+  group: ` + k8sCRDGroup + `
+  names:
+    kind: ` + k8sCRDKind + `
+    listKind: ` + k8sCRDKind + `List
+    plural: ` + k8sCRDPlural + `
+    singular: secrethubsecret
+  scope: Namespaced
+  versions:
+    - name: ` + k8sCRDVersion + `
+      served: true
+      storage: true
+      subresources:
+        status: {}
+      additionalPrinterColumns:
+        - name: Directory
+          type: string
+          jsonPath: .spec.dir
+        - name: Ready
+          type: string
+          jsonPath: .status.conditions[?(@.type=="Ready")].status
+        - name: Last Sync
+          type: date
+          jsonPath: .status.lastSyncTime
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            spec:
+              type: object
+              required: [dir]
+              properties:
+                dir:
+                  type: string
+                  description: The SecretHub directory to sync the secrets of.
+                recursive:
+                  type: boolean
+                  description: Also sync the secrets in all subdirectories.
+                keyTemplate:
+                  type: string
+                  description: A Go template that generates the key of every secret, as in secrethub export k8s.
+                secretName:
+                  type: string
+                  description: The name of the Secret to sync to. Defaults to the name of this resource.
+                type:
+                  type: string
+                  description: The type of the Secret. Defaults to Opaque.
+                refreshInterval:
+                  type: string
+                  description: How often to sync the Secret, e.g. 10m. Defaults to the --refresh-interval of the operator.
+            status:
+              type: object
+              properties:
+                observedGeneration:
+                  type: integer
+                lastSyncTime:
+                  type: string
+                  format: date-time
+                conditions:
+                  type: array
+                  items:
+                    type: object
+                    required: [type, status]
+                    properties:
+                      type:
+                        type: string
+                      status:
+                        type: string
+                      reason:
+                        type: string
+                      message:
+                        type: string
+                      lastTransitionTime:
+                        type: string
+                        format: date-time
+`
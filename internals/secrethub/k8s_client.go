@@ -0,0 +1,150 @@
+package secrethub
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Errors
+var (
+	ErrK8sNotInCluster       = errK8s.Code("not_in_cluster").Error("not running in a Kubernetes cluster: KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT are not set")
+	ErrK8sInvalidCACert      = errK8s.Code("invalid_ca_cert").ErrorPref("no certificates found in %s")
+	ErrK8sUnexpectedResponse = errK8s.Code("unexpected_response").ErrorPref("%s %s: %s: %s")
+)
+
+const (
+	k8sServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+)
+
+// k8sClient is a minimal client for the Kubernetes API.
+type k8sClient struct {
+	url string
+	// tokenFile is read on every request, because the token of a service account is rotated.
+	tokenFile string
+	client    *http.Client
+}
+
+// newInClusterK8sClient returns a client that connects to the API server of the
+// cluster it runs in, authenticated with the token of the service account of the pod.
+func newInClusterK8sClient() (*k8sClient, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, ErrK8sNotInCluster
+	}
+
+	caFile := k8sServiceAccountDir + "/ca.crt"
+	pem, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, ErrCannotReadFile(caFile, err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, ErrK8sInvalidCACert(caFile)
+	}
+
+	return &k8sClient{
+		url:       "https://" + net.JoinHostPort(host, port),
+		tokenFile: k8sServiceAccountDir + "/token",
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{RootCAs: pool},
+			},
+		},
+	}, nil
+}
+
+// k8sAPIError is an error response of the Kubernetes API.
+type k8sAPIError struct {
+	method     string
+	path       string
+	statusCode int
+	status     string
+	message    string
+}
+
+// Error implements the error interface.
+func (e *k8sAPIError) Error() string {
+	return ErrK8sUnexpectedResponse(e.method, e.path, e.status, e.message).Error()
+}
+
+// isK8sNotFound returns whether the error is a response of the Kubernetes API that the object does not exist.
+func isK8sNotFound(err error) bool {
+	apiErr, ok := err.(*k8sAPIError)
+	return ok && apiErr.statusCode == http.StatusNotFound
+}
+
+// do sends a request with the JSON encoded body to the API and decodes the response into out.
+// The body and out are ignored when nil.
+func (c *k8sClient) do(method, path string, body, out interface{}) error {
+	var reqBody []byte
+	if body != nil {
+		var err error
+		reqBody, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, c.url+path, bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.tokenFile != "" {
+		token, err := ioutil.ReadFile(c.tokenFile)
+		if err != nil {
+			return ErrCannotReadFile(c.tokenFile, err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// Errors are returned as a Status object with a message.
+		var status struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(respBody, &status)
+		return &k8sAPIError{
+			method:     method,
+			path:       path,
+			statusCode: resp.StatusCode,
+			status:     resp.Status,
+			message:    status.Message,
+		}
+	}
+
+	if out == nil {
+		return nil
+	}
+	err = json.Unmarshal(respBody, out)
+	if err != nil {
+		return fmt.Errorf("cannot decode the response of %s %s: %s", method, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package secrethub
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+const (
+	k8sAnnotationInject           = "secrethub.io/inject"
+	k8sAnnotationInjected         = "secrethub.io/injected"
+	k8sAnnotationInjectContainers = "secrethub.io/containers"
+	k8sAnnotationCredentialSecret = "secrethub.io/credential-secret"
+
+	k8sInjectVolumeName    = "secrethub-bin"
+	k8sInjectMountPath     = "/secrethub"
+	k8sInjectInitContainer = "secrethub-init"
+	// k8sCredentialSecretKey is the key of the credential in the Secret set with the credential-secret annotation.
+	k8sCredentialSecretKey = "credential"
+)
+
+// K8sInjectWebhookCommand serves a mutating admission webhook that wraps the containers of annotated pods in `secrethub run`.
+type K8sInjectWebhookCommand struct {
+	io         ui.IO
+	logger     cli.Logger
+	listenAddr string
+	tlsCert    string
+	tlsKey     string
+	image      string
+	binaryPath string
+}
+
+// NewK8sInjectWebhookCommand creates a new K8sInjectWebhookCommand.
+func NewK8sInjectWebhookCommand(io ui.IO, logger cli.Logger) *K8sInjectWebhookCommand {
+	return &K8sInjectWebhookCommand{
+		io:     io,
+		logger: logger,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *K8sInjectWebhookCommand) Register(r command.Registerer) {
+	clause := r.Command("inject-webhook", "Serve a mutating admission webhook that injects secrets into annotated pods.")
+	clause.HelpLong("The webhook changes pods with the annotation " + k8sAnnotationInject + ": \"true\" when they are created. " +
+		"An init container copies the " + ApplicationName + " binary from --image into a shared volume, and the command of every container is wrapped in `" + ApplicationName + " run`. " +
+		"Environment variables with a value of the form secrethub://<path> then get the value of the secret at the path, as with `" + ApplicationName + " run`.\n\n" +
+		"Pods can set these annotations:\n" +
+		"  - " + k8sAnnotationInject + ": \"true\" to inject secrets into the pod.\n" +
+		"  - " + k8sAnnotationInjectContainers + ": a comma-separated list of the containers to inject secrets into. Defaults to all containers.\n" +
+		"  - " + k8sAnnotationCredentialSecret + ": the name of a Secret with the credential to use in its " + k8sCredentialSecretKey + " key.\n\n" +
+		"Only containers that set their command in the pod spec can be wrapped, because the entrypoint of an image is not known to the webhook. " +
+		"Other containers are left as they are and reported in a warning.\n\n" +
+		"Register the webhook for pod CREATE operations with a MutatingWebhookConfiguration that points to the /mutate path of a Service in front of this command.")
+	clause.Flag("listen-address", "The address to serve the webhook on.").Default(":8443").StringVar(&cmd.listenAddr)
+	clause.Flag("tls-cert", "The file with the TLS certificate of the webhook.").Required().ExistingFileVar(&cmd.tlsCert)
+	clause.Flag("tls-key", "The file with the private key of the TLS certificate.").Required().ExistingFileVar(&cmd.tlsKey)
+	clause.Flag("image", "The image to copy the "+ApplicationName+" binary from.").Default("secrethub/cli").StringVar(&cmd.image)
+	clause.Flag("binary-path", "The path of the "+ApplicationName+" binary in the image.").Default("/usr/bin/secrethub").StringVar(&cmd.binaryPath)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run serves the webhook until the process is stopped.
+func (cmd *K8sInjectWebhookCommand) Run() error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mutate", cmd.handleMutate)
+
+	cmd.logger.Infof("Serving the injection webhook on %s", cmd.listenAddr)
+	return http.ListenAndServeTLS(cmd.listenAddr, cmd.tlsCert, cmd.tlsKey, mux)
+}
+
+// k8sAdmissionReview is a request of the API server to the webhook and the response to it.
+type k8sAdmissionReview struct {
+	APIVersion string                `json:"apiVersion"`
+	Kind       string                `json:"kind"`
+	Request    *k8sAdmissionRequest  `json:"request,omitempty"`
+	Response   *k8sAdmissionResponse `json:"response,omitempty"`
+}
+
+// k8sAdmissionRequest is the object to admit.
+type k8sAdmissionRequest struct {
+	UID       string          `json:"uid"`
+	Namespace string          `json:"namespace"`
+	Object    json.RawMessage `json:"object"`
+}
+
+// k8sAdmissionResponse admits an object, with a JSON patch that changes it.
+type k8sAdmissionResponse struct {
+	UID       string           `json:"uid"`
+	Allowed   bool             `json:"allowed"`
+	PatchType string           `json:"patchType,omitempty"`
+	Patch     []byte           `json:"patch,omitempty"`
+	Warnings  []string         `json:"warnings,omitempty"`
+	Status    *k8sAdmissionMsg `json:"status,omitempty"`
+}
+
+// k8sAdmissionMsg explains why an object is not admitted.
+type k8sAdmissionMsg struct {
+	Message string `json:"message"`
+}
+
+// k8sPod is the part of a pod that the webhook changes.
+type k8sPod struct {
+	Metadata k8sObjectMeta `json:"metadata"`
+	Spec     struct {
+		InitContainers []k8sContainer    `json:"initContainers"`
+		Containers     []k8sContainer    `json:"containers"`
+		Volumes        []json.RawMessage `json:"volumes"`
+	} `json:"spec"`
+}
+
+// k8sContainer is the part of a container that the webhook changes.
+type k8sContainer struct {
+	Name         string            `json:"name"`
+	Command      []string          `json:"command"`
+	Env          []json.RawMessage `json:"env"`
+	VolumeMounts []json.RawMessage `json:"volumeMounts"`
+}
+
+// jsonPatchOperation is an operation of a JSON patch (RFC 6902).
+type jsonPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// handleMutate responds to an admission review with the patch that injects secrets into the pod.
+func (cmd *K8sInjectWebhookCommand) handleMutate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var review k8sAdmissionReview
+	err := json.NewDecoder(r.Body).Decode(&review)
+	if err != nil || review.Request == nil {
+		http.Error(w, "the body must be an AdmissionReview with a request", http.StatusBadRequest)
+		return
+	}
+
+	response := &k8sAdmissionResponse{
+		UID:     review.Request.UID,
+		Allowed: true,
+	}
+
+	var pod k8sPod
+	err = json.Unmarshal(review.Request.Object, &pod)
+	if err != nil {
+		response.Allowed = false
+		response.Status = &k8sAdmissionMsg{Message: fmt.Sprintf("cannot decode the pod: %s", err)}
+	} else {
+		patch, warnings := cmd.injectPod(&pod)
+		response.Warnings = warnings
+		if len(patch) > 0 {
+			response.PatchType = "JSONPatch"
+			response.Patch, err = json.Marshal(patch)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			cmd.logger.With("namespace", review.Request.Namespace, "pod", pod.Metadata.Name).Infof("Injected secrets into pod")
+		}
+	}
+
+	review.Request = nil
+	review.Response = response
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(review)
+}
+
+// injectPod returns the JSON patch that injects secrets into the pod when it is annotated to
+// do so, and warnings about the containers that cannot be injected.
+func (cmd *K8sInjectWebhookCommand) injectPod(pod *k8sPod) ([]jsonPatchOperation, []string) {
+	annotations := pod.Metadata.Annotations
+	if annotations[k8sAnnotationInject] != "true" || annotations[k8sAnnotationInjected] == "true" {
+		return nil, nil
+	}
+
+	selected := map[string]bool{}
+	if value := annotations[k8sAnnotationInjectContainers]; value != "" {
+		for _, name := range strings.Split(value, ",") {
+			selected[strings.TrimSpace(name)] = true
+		}
+	}
+
+	var warnings []string
+	var patch []jsonPatchOperation
+	for i, container := range pod.Spec.Containers {
+		if len(selected) > 0 && !selected[container.Name] {
+			continue
+		}
+		delete(selected, container.Name)
+
+		if len(container.Command) == 0 {
+			warnings = append(warnings, fmt.Sprintf("secrets are not injected into the container %s, because it does not set its command", container.Name))
+			continue
+		}
+
+		path := "/spec/containers/" + strconv.Itoa(i)
+		patch = append(patch, jsonPatchOperation{
+			Op:    "replace",
+			Path:  path + "/command",
+			Value: append([]string{k8sInjectMountPath + "/" + ApplicationName, "run", "--"}, container.Command...),
+		})
+		patch = append(patch, addToJSONArray(path+"/volumeMounts", len(container.VolumeMounts), map[string]interface{}{
+			"name":      k8sInjectVolumeName,
+			"mountPath": k8sInjectMountPath,
+			"readOnly":  true,
+		}))
+		if secret := annotations[k8sAnnotationCredentialSecret]; secret != "" {
+			patch = append(patch, addToJSONArray(path+"/env", len(container.Env), map[string]interface{}{
+				"name": "SECRETHUB_CREDENTIAL",
+				"valueFrom": map[string]interface{}{
+					"secretKeyRef": map[string]string{
+						"name": secret,
+						"key":  k8sCredentialSecretKey,
+					},
+				},
+			}))
+		}
+	}
+
+	missing := make([]string, 0, len(selected))
+	for name := range selected {
+		missing = append(missing, name)
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		warnings = append(warnings, fmt.Sprintf("the pod has no container %s to inject secrets into", name))
+	}
+
+	if len(patch) == 0 {
+		return nil, warnings
+	}
+
+	initContainer := map[string]interface{}{
+		"name":    k8sInjectInitContainer,
+		"image":   cmd.image,
+		"command": []string{"cp", cmd.binaryPath, k8sInjectMountPath + "/" + ApplicationName},
+		"volumeMounts": []map[string]string{{
+			"name":      k8sInjectVolumeName,
+			"mountPath": k8sInjectMountPath,
+		}},
+	}
+	// The init container is added before the other init containers, so that it runs first.
+	if len(pod.Spec.InitContainers) == 0 {
+		patch = append(patch, jsonPatchOperation{Op: "add", Path: "/spec/initContainers", Value: []interface{}{initContainer}})
+	} else {
+		patch = append(patch, jsonPatchOperation{Op: "add", Path: "/spec/initContainers/0", Value: initContainer})
+	}
+
+	patch = append(patch, addToJSONArray("/spec/volumes", len(pod.Spec.Volumes), map[string]interface{}{
+		"name":     k8sInjectVolumeName,
+		"emptyDir": map[string]string{"medium": "Memory"},
+	}))
+
+	// The annotations exist, because the pod has the inject annotation.
+	patch = append(patch, jsonPatchOperation{
+		Op:    "add",
+		Path:  "/metadata/annotations/" + strings.Replace(k8sAnnotationInjected, "/", "~1", -1),
+		Value: "true",
+	})
+
+	return patch, warnings
+}
+
+// addToJSONArray returns the patch operation that appends the value to the array at the path,
+// creating the array when it is empty or does not exist.
+func addToJSONArray(path string, length int, value interface{}) jsonPatchOperation {
+	if length == 0 {
+		return jsonPatchOperation{Op: "add", Path: path, Value: []interface{}{value}}
+	}
+	return jsonPatchOperation{Op: "add", Path: path + "/-", Value: value}
+}
@@ -0,0 +1,111 @@
+package secrethub
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestK8sInjectWebhookCommand_injectPod(t *testing.T) {
+	cases := map[string]struct {
+		pod      string
+		patch    string
+		warnings []string
+	}{
+		"not annotated": {
+			pod: `{"metadata":{"name":"app"},"spec":{"containers":[{"name":"app","command":["app"]}]}}`,
+		},
+		"already injected": {
+			pod: `{"metadata":{"annotations":{"secrethub.io/inject":"true","secrethub.io/injected":"true"}},` +
+				`"spec":{"containers":[{"name":"app","command":["app"]}]}}`,
+		},
+		"inject": {
+			pod: `{"metadata":{"annotations":{"secrethub.io/inject":"true","secrethub.io/credential-secret":"secrethub"}},` +
+				`"spec":{"containers":[{"name":"app","command":["app","serve"],"env":[{"name":"DB_PASSWORD","value":"secrethub://company/app/db/password"}]}],` +
+				`"volumes":[{"name":"data","emptyDir":{}}]}}`,
+			patch: `[` +
+				`{"op":"replace","path":"/spec/containers/0/command","value":["/secrethub/secrethub","run","--","app","serve"]},` +
+				`{"op":"add","path":"/spec/containers/0/volumeMounts","value":[{"mountPath":"/secrethub","name":"secrethub-bin","readOnly":true}]},` +
+				`{"op":"add","path":"/spec/containers/0/env/-","value":{"name":"SECRETHUB_CREDENTIAL","valueFrom":{"secretKeyRef":{"key":"credential","name":"secrethub"}}}},` +
+				`{"op":"add","path":"/spec/initContainers","value":[{"command":["cp","/usr/bin/secrethub","/secrethub/secrethub"],"image":"secrethub/cli","name":"secrethub-init","volumeMounts":[{"mountPath":"/secrethub","name":"secrethub-bin"}]}]},` +
+				`{"op":"add","path":"/spec/volumes/-","value":{"emptyDir":{"medium":"Memory"},"name":"secrethub-bin"}},` +
+				`{"op":"add","path":"/metadata/annotations/secrethub.io~1injected","value":"true"}` +
+				`]`,
+		},
+		"selected containers": {
+			pod: `{"metadata":{"annotations":{"secrethub.io/inject":"true","secrethub.io/containers":"app,sidecar,missing"}},` +
+				`"spec":{"initContainers":[{"name":"migrate","command":["migrate"]}],` +
+				`"containers":[{"name":"proxy","command":["proxy"]},{"name":"app","command":["app"]},{"name":"sidecar"}]}}`,
+			patch: `[` +
+				`{"op":"replace","path":"/spec/containers/1/command","value":["/secrethub/secrethub","run","--","app"]},` +
+				`{"op":"add","path":"/spec/containers/1/volumeMounts","value":[{"mountPath":"/secrethub","name":"secrethub-bin","readOnly":true}]},` +
+				`{"op":"add","path":"/spec/initContainers/0","value":{"command":["cp","/usr/bin/secrethub","/secrethub/secrethub"],"image":"secrethub/cli","name":"secrethub-init","volumeMounts":[{"mountPath":"/secrethub","name":"secrethub-bin"}]}},` +
+				`{"op":"add","path":"/spec/volumes","value":[{"emptyDir":{"medium":"Memory"},"name":"secrethub-bin"}]},` +
+				`{"op":"add","path":"/metadata/annotations/secrethub.io~1injected","value":"true"}` +
+				`]`,
+			warnings: []string{
+				"secrets are not injected into the container sidecar, because it does not set its command",
+				"the pod has no container missing to inject secrets into",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			cmd := K8sInjectWebhookCommand{
+				image:      "secrethub/cli",
+				binaryPath: "/usr/bin/secrethub",
+			}
+			var pod k8sPod
+			err := json.Unmarshal([]byte(tc.pod), &pod)
+			assert.OK(t, err)
+
+			// Act
+			patch, warnings := cmd.injectPod(&pod)
+
+			// Assert
+			assert.Equal(t, warnings, tc.warnings)
+			if tc.patch == "" {
+				assert.Equal(t, len(patch), 0)
+			} else {
+				actual, err := json.Marshal(patch)
+				assert.OK(t, err)
+				assert.Equal(t, string(actual), tc.patch)
+			}
+		})
+	}
+}
+
+func TestK8sInjectWebhookCommand_handleMutate(t *testing.T) {
+	// Setup
+	cmd := K8sInjectWebhookCommand{
+		logger:     cli.NewLogger(),
+		image:      "secrethub/cli",
+		binaryPath: "/usr/bin/secrethub",
+	}
+	body := `{"apiVersion":"admission.k8s.io/v1","kind":"AdmissionReview","request":{"uid":"1234","namespace":"default",` +
+		`"object":{"metadata":{"annotations":{"secrethub.io/inject":"true"}},"spec":{"containers":[{"name":"app","command":["app"]}]}}}}`
+	req := httptest.NewRequest(http.MethodPost, "/mutate", bytes.NewBufferString(body))
+	rec := httptest.NewRecorder()
+
+	// Act
+	cmd.handleMutate(rec, req)
+
+	// Assert
+	assert.Equal(t, rec.Code, http.StatusOK)
+	var review k8sAdmissionReview
+	err := json.Unmarshal(rec.Body.Bytes(), &review)
+	assert.OK(t, err)
+	assert.Equal(t, review.APIVersion, "admission.k8s.io/v1")
+	assert.Equal(t, review.Response.UID, "1234")
+	assert.Equal(t, review.Response.Allowed, true)
+	assert.Equal(t, review.Response.PatchType, "JSONPatch")
+	assert.Equal(t, len(review.Response.Patch) > 0, true)
+}
@@ -0,0 +1,468 @@
+package secrethub
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// Errors
+var (
+	ErrK8sSecretNotOwned       = errK8s.Code("secret_not_owned").ErrorPref("the Secret %s already exists and is not managed by this " + k8sCRDKind)
+	ErrK8sInvalidRefresh       = errK8s.Code("invalid_refresh_interval").ErrorPref("invalid refresh interval %q: %s")
+	ErrK8sInvalidOperatorFlags = errK8s.Code("invalid_operator_flags").Error("--poll-interval and --refresh-interval must be positive")
+	ErrK8sNoAllowedDirs        = errK8s.Code("no_allowed_dirs").Error("set the SecretHub directories the " + k8sCRDKind + " resources of each namespace can sync with --allow-dir, e.g. --allow-dir default=company/app")
+	ErrK8sInvalidAllowedDir    = errK8s.Code("invalid_allowed_dir").ErrorPref("invalid --allow-dir %s: use NAMESPACE=DIR, e.g. default=company/app")
+	ErrK8sDirNotAllowed        = errK8s.Code("dir_not_allowed").ErrorPref("%s resources in the namespace %s cannot sync %s: the operator only allows this for the directories set with --allow-dir")
+)
+
+const (
+	k8sCRDGroup   = "secrethub.io"
+	k8sCRDVersion = "v1alpha1"
+	k8sCRDKind    = "SecretHubSecret"
+	k8sCRDPlural  = "secrethubsecrets"
+
+	k8sConditionReady = "Ready"
+
+	k8sReasonSynced          = "Synced"
+	k8sReasonInvalidSpec     = "InvalidSpec"
+	k8sReasonSecretHubError  = "SecretHubError"
+	k8sReasonKubernetesError = "KubernetesError"
+	k8sReasonSecretNotOwned  = "SecretNotOwned"
+	k8sReasonDirNotAllowed   = "DirNotAllowed"
+)
+
+// secretHubSecret is a resource that declares a Kubernetes Secret with the secrets in a SecretHub directory.
+type secretHubSecret struct {
+	APIVersion string                `json:"apiVersion"`
+	Kind       string                `json:"kind"`
+	Metadata   k8sObjectMeta         `json:"metadata"`
+	Spec       secretHubSecretSpec   `json:"spec"`
+	Status     secretHubSecretStatus `json:"status"`
+}
+
+// secretHubSecretSpec is the desired state of a SecretHubSecret.
+type secretHubSecretSpec struct {
+	Dir             string `json:"dir"`
+	Recursive       bool   `json:"recursive,omitempty"`
+	KeyTemplate     string `json:"keyTemplate,omitempty"`
+	SecretName      string `json:"secretName,omitempty"`
+	Type            string `json:"type,omitempty"`
+	RefreshInterval string `json:"refreshInterval,omitempty"`
+}
+
+// secretHubSecretStatus is the state of the Secret of a SecretHubSecret.
+type secretHubSecretStatus struct {
+	ObservedGeneration int64          `json:"observedGeneration,omitempty"`
+	LastSyncTime       *time.Time     `json:"lastSyncTime,omitempty"`
+	Conditions         []k8sCondition `json:"conditions,omitempty"`
+}
+
+// k8sCondition is a condition in the status of a Kubernetes object.
+type k8sCondition struct {
+	Type               string    `json:"type"`
+	Status             string    `json:"status"`
+	Reason             string    `json:"reason,omitempty"`
+	Message            string    `json:"message,omitempty"`
+	LastTransitionTime time.Time `json:"lastTransitionTime"`
+}
+
+// k8sObjectMeta is the metadata of a Kubernetes object.
+type k8sObjectMeta struct {
+	Name            string              `json:"name"`
+	Namespace       string              `json:"namespace,omitempty"`
+	UID             string              `json:"uid,omitempty"`
+	ResourceVersion string              `json:"resourceVersion,omitempty"`
+	Generation      int64               `json:"generation,omitempty"`
+	Annotations     map[string]string   `json:"annotations,omitempty"`
+	OwnerReferences []k8sOwnerReference `json:"ownerReferences,omitempty"`
+}
+
+// k8sOwnerReference refers to the object that manages a Kubernetes object.
+type k8sOwnerReference struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	UID        string `json:"uid"`
+	Controller bool   `json:"controller"`
+}
+
+// k8sSecretObject is a Kubernetes Secret as sent to and received from the API.
+type k8sSecretObject struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   k8sObjectMeta     `json:"metadata"`
+	Type       string            `json:"type,omitempty"`
+	Data       map[string]string `json:"data"`
+}
+
+// K8sOperatorCommand runs a controller that syncs SecretHubSecret resources to Kubernetes Secrets.
+type K8sOperatorCommand struct {
+	io              ui.IO
+	newClient       newClientFunc
+	newK8sClient    func() (*k8sClient, error)
+	logger          cli.Logger
+	namespace       string
+	allowDirs       []string
+	allowedDirs     map[string][]api.DirPath
+	pollInterval    time.Duration
+	refreshInterval time.Duration
+	now             func() time.Time
+}
+
+// NewK8sOperatorCommand creates a new K8sOperatorCommand.
+func NewK8sOperatorCommand(io ui.IO, newClient newClientFunc, logger cli.Logger) *K8sOperatorCommand {
+	return &K8sOperatorCommand{
+		io:           io,
+		newClient:    newClient,
+		newK8sClient: newInClusterK8sClient,
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *K8sOperatorCommand) Register(r command.Registerer) {
+	clause := r.Command("operator", "Run a controller in the cluster that syncs "+k8sCRDKind+" resources to Kubernetes Secrets.")
+	clause.HelpLong("A " + k8sCRDKind + " declares a Secret with the secrets in a SecretHub directory, e.g.:\n\n" +
+		"    apiVersion: " + k8sCRDGroup + "/" + k8sCRDVersion + "\n" +
+		"    kind: " + k8sCRDKind + "\n" +
+		"    metadata:\n" +
+		"      name: app\n" +
+		"    spec:\n" +
+		"      dir: company/app/prod\n" +
+		"      recursive: true\n" +
+		"      refreshInterval: 10m\n\n" +
+		"The Secret has the same keys and annotations as the manifests of `" + ApplicationName + " export k8s`. " +
+		"It is synced when the " + k8sCRDKind + " is created or changed and again after every refresh interval. " +
+		"Its status has a Ready condition that reports whether the last sync succeeded and why it failed.\n\n" +
+		"The operator runs in the cluster with the credential set in the SECRETHUB_CREDENTIAL environment variable. " +
+		"Anyone who can create a " + k8sCRDKind + " in a namespace could read every secret that credential can read through the Secret, " +
+		"so set the directories each namespace can sync with --allow-dir, e.g.:\n\n" +
+		"    " + ApplicationName + " k8s operator --allow-dir default=company/app/prod --allow-dir staging=company/app/staging\n\n" +
+		"A " + k8sCRDKind + " can sync an allowed directory and its subdirectories. Other directories are not synced and reported in its Ready condition. " +
+		"Install the " + k8sCRDKind + " resource with `" + ApplicationName + " k8s crd | kubectl apply -f -`. " +
+		"The service account of the operator needs permission to list " + k8sCRDPlural + ", update " + k8sCRDPlural + "/status " +
+		"and get, create and update secrets.")
+	clause.Flag("namespace", "Only sync the "+k8sCRDKind+" resources in this namespace. Defaults to all namespaces.").StringVar(&cmd.namespace)
+	clause.Flag("allow-dir", "Allow the "+k8sCRDKind+" resources in a namespace to sync the directory and its subdirectories, e.g. default=company/app. Can be repeated.").Required().PlaceHolder("NAMESPACE=DIR").StringsVar(&cmd.allowDirs)
+	clause.Flag("poll-interval", "How often to check the "+k8sCRDKind+" resources for changes.").Default("30s").DurationVar(&cmd.pollInterval)
+	clause.Flag("refresh-interval", "How often to sync a Secret when its "+k8sCRDKind+" sets no refresh interval.").Default("5m").DurationVar(&cmd.refreshInterval)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run syncs the resources until the process is stopped.
+func (cmd *K8sOperatorCommand) Run() error {
+	if cmd.pollInterval <= 0 || cmd.refreshInterval <= 0 {
+		return ErrK8sInvalidOperatorFlags
+	}
+
+	allowedDirs, err := parseK8sAllowedDirs(cmd.allowDirs)
+	if err != nil {
+		return err
+	}
+	cmd.allowedDirs = allowedDirs
+
+	k8s, err := cmd.newK8sClient()
+	if err != nil {
+		return err
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	ticker := time.NewTicker(cmd.pollInterval)
+	defer ticker.Stop()
+
+	cmd.logger.Infof("Syncing %s resources", k8sCRDKind)
+	cmd.reconcileAll(k8s)
+	for {
+		select {
+		case <-stop:
+			cmd.logger.Infof("Stopped syncing %s resources", k8sCRDKind)
+			return nil
+		case <-ticker.C:
+			cmd.reconcileAll(k8s)
+		}
+	}
+}
+
+// parseK8sAllowedDirs parses the NAMESPACE=DIR values of --allow-dir into the directories allowed per namespace.
+func parseK8sAllowedDirs(values []string) (map[string][]api.DirPath, error) {
+	if len(values) == 0 {
+		return nil, ErrK8sNoAllowedDirs
+	}
+
+	allowed := make(map[string][]api.DirPath, len(values))
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, ErrK8sInvalidAllowedDir(value)
+		}
+
+		var dir api.DirPath
+		err := dir.Set(parts[1])
+		if err != nil {
+			return nil, ErrK8sInvalidAllowedDir(value)
+		}
+		allowed[parts[0]] = append(allowed[parts[0]], dir)
+	}
+	return allowed, nil
+}
+
+// isDirAllowed returns whether the resources in the namespace are allowed to sync the directory.
+func (cmd *K8sOperatorCommand) isDirAllowed(namespace string, dir api.DirPath) bool {
+	for _, allowed := range cmd.allowedDirs[namespace] {
+		if isParentDir(allowed, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcileAll syncs the Secrets of all resources that are due.
+func (cmd *K8sOperatorCommand) reconcileAll(k8s *k8sClient) {
+	path := "/apis/" + k8sCRDGroup + "/" + k8sCRDVersion + "/" + k8sCRDPlural
+	if cmd.namespace != "" {
+		path = "/apis/" + k8sCRDGroup + "/" + k8sCRDVersion + "/namespaces/" + url.PathEscape(cmd.namespace) + "/" + k8sCRDPlural
+	}
+
+	var list struct {
+		Items []*secretHubSecret `json:"items"`
+	}
+	err := k8s.do(http.MethodGet, path, nil, &list)
+	if err != nil {
+		cmd.logger.With("error", err).Errorf("Listing %s resources failed", k8sCRDKind)
+		return
+	}
+
+	for _, resource := range list.Items {
+		if !cmd.due(resource) {
+			continue
+		}
+
+		logger := cmd.logger.With("namespace", resource.Metadata.Namespace, "name", resource.Metadata.Name, "dir", resource.Spec.Dir)
+		err := cmd.reconcile(k8s, resource)
+		if err != nil {
+			logger.With("error", err).Errorf("Syncing the Secret failed")
+		} else {
+			logger.Debugf("Synced the Secret")
+		}
+	}
+}
+
+// due returns whether the Secret of the resource must be synced: when the resource
+// changed, the last sync failed or the refresh interval passed since the last sync.
+func (cmd *K8sOperatorCommand) due(resource *secretHubSecret) bool {
+	status := resource.Status
+	if status.ObservedGeneration != resource.Metadata.Generation || status.LastSyncTime == nil {
+		return true
+	}
+
+	ready := findK8sCondition(status.Conditions, k8sConditionReady)
+	if ready == nil || ready.Status != "True" {
+		return true
+	}
+
+	interval, err := cmd.interval(resource.Spec)
+	if err != nil {
+		return true
+	}
+	return !cmd.now().Before(status.LastSyncTime.Add(interval))
+}
+
+// interval returns the refresh interval of the resource.
+func (cmd *K8sOperatorCommand) interval(spec secretHubSecretSpec) (time.Duration, error) {
+	if spec.RefreshInterval == "" {
+		return cmd.refreshInterval, nil
+	}
+	interval, err := time.ParseDuration(spec.RefreshInterval)
+	if err != nil {
+		return 0, ErrK8sInvalidRefresh(spec.RefreshInterval, err)
+	}
+	if interval <= 0 {
+		return 0, ErrK8sInvalidRefresh(spec.RefreshInterval, "it must be positive")
+	}
+	return interval, nil
+}
+
+// reconcile syncs the Secret of the resource and records the result in its status.
+func (cmd *K8sOperatorCommand) reconcile(k8s *k8sClient, resource *secretHubSecret) error {
+	now := cmd.now().UTC().Truncate(time.Second)
+
+	message, reason, syncErr := cmd.syncSecret(k8s, resource)
+	condition := k8sCondition{
+		Type:               k8sConditionReady,
+		Status:             "True",
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: now,
+	}
+	if syncErr != nil {
+		condition.Status = "False"
+		condition.Message = syncErr.Error()
+	} else {
+		resource.Status.LastSyncTime = &now
+	}
+	resource.Status.Conditions = setK8sCondition(resource.Status.Conditions, condition)
+	resource.Status.ObservedGeneration = resource.Metadata.Generation
+
+	path := fmt.Sprintf("/apis/%s/%s/namespaces/%s/%s/%s/status",
+		k8sCRDGroup, k8sCRDVersion, url.PathEscape(resource.Metadata.Namespace), k8sCRDPlural, url.PathEscape(resource.Metadata.Name))
+	err := k8s.do(http.MethodPut, path, resource, nil)
+	if err != nil {
+		return err
+	}
+	return syncErr
+}
+
+// syncSecret creates or updates the Secret of the resource with the secrets in its directory.
+// It returns a message and a reason for the Ready condition of the resource.
+func (cmd *K8sOperatorCommand) syncSecret(k8s *k8sClient, resource *secretHubSecret) (string, string, error) {
+	spec := resource.Spec
+
+	var dirPath api.DirPath
+	err := dirPath.Set(spec.Dir)
+	if err != nil {
+		return "", k8sReasonInvalidSpec, err
+	}
+	if !cmd.isDirAllowed(resource.Metadata.Namespace, dirPath) {
+		return "", k8sReasonDirNotAllowed, ErrK8sDirNotAllowed(k8sCRDKind, resource.Metadata.Namespace, dirPath)
+	}
+
+	name := spec.SecretName
+	if name == "" {
+		name = resource.Metadata.Name
+	}
+	if len(name) > 253 || !k8sNamePattern.MatchString(name) {
+		return "", k8sReasonInvalidSpec, ErrInvalidK8sName("name", name)
+	}
+
+	secretType := spec.Type
+	if secretType == "" {
+		secretType = "Opaque"
+	}
+
+	_, err = cmd.interval(spec)
+	if err != nil {
+		return "", k8sReasonInvalidSpec, err
+	}
+
+	text := spec.KeyTemplate
+	if text == "" {
+		text = `{{ .Path | replace "/" "_" }}`
+	}
+	keyTemplate, err := parseExportKeyTemplate(text)
+	if err != nil {
+		return "", k8sReasonInvalidSpec, err
+	}
+
+	options := exportOptions{recursive: spec.Recursive}
+	secrets, err := options.fetchSecrets(cmd.newClient, dirPath)
+	if err != nil {
+		return "", k8sReasonSecretHubError, err
+	}
+
+	keys, err := exportKeys(keyTemplate, secrets)
+	if err != nil {
+		return "", k8sReasonInvalidSpec, err
+	}
+
+	secret := newK8sSecret(name, resource.Metadata.Namespace, secretType, dirPath.Value(), keys, secrets)
+	err = secret.validate()
+	if err != nil {
+		return "", k8sReasonInvalidSpec, err
+	}
+
+	annotations, err := secret.annotations()
+	if err != nil {
+		return "", k8sReasonInvalidSpec, err
+	}
+
+	object := k8sSecretObject{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata: k8sObjectMeta{
+			Name:        name,
+			Namespace:   resource.Metadata.Namespace,
+			Annotations: annotations,
+			OwnerReferences: []k8sOwnerReference{{
+				APIVersion: k8sCRDGroup + "/" + k8sCRDVersion,
+				Kind:       k8sCRDKind,
+				Name:       resource.Metadata.Name,
+				UID:        resource.Metadata.UID,
+				Controller: true,
+			}},
+		},
+		Type: secretType,
+		Data: make(map[string]string, len(secret.entries)),
+	}
+	for _, entry := range secret.entries {
+		object.Data[entry.key] = base64.StdEncoding.EncodeToString(entry.secret.data)
+	}
+
+	secretsPath := "/api/v1/namespaces/" + url.PathEscape(resource.Metadata.Namespace) + "/secrets"
+	var existing k8sSecretObject
+	err = k8s.do(http.MethodGet, secretsPath+"/"+url.PathEscape(name), nil, &existing)
+	if isK8sNotFound(err) {
+		err = k8s.do(http.MethodPost, secretsPath, object, nil)
+	} else if err == nil {
+		if !isControlledBy(existing.Metadata, resource.Metadata.UID) {
+			return "", k8sReasonSecretNotOwned, ErrK8sSecretNotOwned(name)
+		}
+		object.Metadata.ResourceVersion = existing.Metadata.ResourceVersion
+		err = k8s.do(http.MethodPut, secretsPath+"/"+url.PathEscape(name), object, nil)
+	}
+	if err != nil {
+		return "", k8sReasonKubernetesError, err
+	}
+
+	return fmt.Sprintf("Synced %s from %s to the Secret %s", pluralize("secret", "secrets", len(secrets)), dirPath, name), k8sReasonSynced, nil
+}
+
+// isControlledBy returns whether the object is managed by the object with the given UID.
+func isControlledBy(metadata k8sObjectMeta, uid string) bool {
+	for _, owner := range metadata.OwnerReferences {
+		if owner.Controller && owner.UID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// findK8sCondition returns the condition of the given type, or nil when there is none.
+func findK8sCondition(conditions []k8sCondition, conditionType string) *k8sCondition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// setK8sCondition replaces the condition of the same type, keeping its transition time when the status did not change.
+func setK8sCondition(conditions []k8sCondition, condition k8sCondition) []k8sCondition {
+	existing := findK8sCondition(conditions, condition.Type)
+	if existing == nil {
+		return append(conditions, condition)
+	}
+	if existing.Status == condition.Status {
+		condition.LastTransitionTime = existing.LastTransitionTime
+	}
+	*existing = condition
+	return conditions
+}
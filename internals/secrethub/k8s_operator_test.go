@@ -0,0 +1,260 @@
+package secrethub
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestK8sOperatorCommand_due(t *testing.T) {
+	now := time.Date(2018, 1, 1, 1, 0, 0, 0, time.UTC)
+	synced := now.Add(-10 * time.Minute)
+
+	cases := map[string]struct {
+		resource secretHubSecret
+		expected bool
+	}{
+		"never synced": {
+			resource: secretHubSecret{},
+			expected: true,
+		},
+		"up to date": {
+			resource: secretHubSecret{
+				Metadata: k8sObjectMeta{Generation: 2},
+				Status: secretHubSecretStatus{
+					ObservedGeneration: 2,
+					LastSyncTime:       &synced,
+					Conditions:         []k8sCondition{{Type: k8sConditionReady, Status: "True"}},
+				},
+			},
+			expected: false,
+		},
+		"refresh interval passed": {
+			resource: secretHubSecret{
+				Metadata: k8sObjectMeta{Generation: 2},
+				Spec:     secretHubSecretSpec{RefreshInterval: "10m"},
+				Status: secretHubSecretStatus{
+					ObservedGeneration: 2,
+					LastSyncTime:       &synced,
+					Conditions:         []k8sCondition{{Type: k8sConditionReady, Status: "True"}},
+				},
+			},
+			expected: true,
+		},
+		"spec changed": {
+			resource: secretHubSecret{
+				Metadata: k8sObjectMeta{Generation: 3},
+				Status: secretHubSecretStatus{
+					ObservedGeneration: 2,
+					LastSyncTime:       &synced,
+					Conditions:         []k8sCondition{{Type: k8sConditionReady, Status: "True"}},
+				},
+			},
+			expected: true,
+		},
+		"last sync failed": {
+			resource: secretHubSecret{
+				Metadata: k8sObjectMeta{Generation: 2},
+				Status: secretHubSecretStatus{
+					ObservedGeneration: 2,
+					LastSyncTime:       &synced,
+					Conditions:         []k8sCondition{{Type: k8sConditionReady, Status: "False"}},
+				},
+			},
+			expected: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			cmd := K8sOperatorCommand{
+				refreshInterval: time.Hour,
+				now: func() time.Time {
+					return now
+				},
+			}
+
+			// Act
+			actual := cmd.due(&tc.resource)
+
+			// Assert
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
+
+func TestK8sOperatorCommand_reconcileAll(t *testing.T) {
+	now := time.Date(2018, 1, 1, 1, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		allowedDirs map[string][]api.DirPath
+		existing    *k8sSecretObject
+		created     bool
+		condition   k8sCondition
+	}{
+		"create": {
+			allowedDirs: map[string][]api.DirPath{"default": {"company/app"}},
+			created:     true,
+			condition: k8sCondition{
+				Type:               k8sConditionReady,
+				Status:             "True",
+				Reason:             k8sReasonSynced,
+				Message:            "Synced 2 secrets from company/app/prod to the Secret app",
+				LastTransitionTime: now,
+			},
+		},
+		"not owned": {
+			allowedDirs: map[string][]api.DirPath{"default": {"company/app/prod"}},
+			existing:    &k8sSecretObject{Metadata: k8sObjectMeta{Name: "app", ResourceVersion: "7"}},
+			condition: k8sCondition{
+				Type:               k8sConditionReady,
+				Status:             "False",
+				Reason:             k8sReasonSecretNotOwned,
+				Message:            ErrK8sSecretNotOwned("app").Error(),
+				LastTransitionTime: now,
+			},
+		},
+		"dir not allowed": {
+			allowedDirs: map[string][]api.DirPath{
+				"default": {"company/app/staging"},
+				"prod":    {"company/app/prod"},
+			},
+			condition: k8sCondition{
+				Type:               k8sConditionReady,
+				Status:             "False",
+				Reason:             k8sReasonDirNotAllowed,
+				Message:            ErrK8sDirNotAllowed(k8sCRDKind, "default", api.DirPath("company/app/prod")).Error(),
+				LastTransitionTime: now,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			var created *k8sSecretObject
+			var status *secretHubSecret
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/apis/secrethub.io/v1alpha1/namespaces/default/secrethubsecrets":
+					_ = json.NewEncoder(w).Encode(map[string]interface{}{
+						"items": []secretHubSecret{{
+							Metadata: k8sObjectMeta{Name: "app", Namespace: "default", UID: "1234", Generation: 1},
+							Spec:     secretHubSecretSpec{Dir: "company/app/prod", Recursive: true},
+						}},
+					})
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v1/namespaces/default/secrets/app":
+					if tc.existing == nil {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					_ = json.NewEncoder(w).Encode(tc.existing)
+				case r.Method == http.MethodPost && r.URL.Path == "/api/v1/namespaces/default/secrets":
+					created = &k8sSecretObject{}
+					assert.OK(t, json.NewDecoder(r.Body).Decode(created))
+				case r.Method == http.MethodPut && r.URL.Path == "/apis/secrethub.io/v1alpha1/namespaces/default/secrethubsecrets/app/status":
+					status = &secretHubSecret{}
+					assert.OK(t, json.NewDecoder(r.Body).Decode(status))
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			cmd := K8sOperatorCommand{
+				namespace:   "default",
+				allowedDirs: tc.allowedDirs,
+				logger:      cli.NewLogger(),
+				now: func() time.Time {
+					return now
+				},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						DirService: &fakeclient.DirService{
+							GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+								return &api.Tree{
+									RootDir: &api.Dir{
+										Name:    "prod",
+										Secrets: []*api.Secret{{Name: "password"}},
+										SubDirs: []*api.Dir{{Name: "api", Secrets: []*api.Secret{{Name: "key"}}}},
+									},
+								}, nil
+							},
+						},
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+									return &api.SecretVersion{Version: 1, Data: []byte(path)}, nil
+								},
+							},
+						},
+					}, nil
+				},
+			}
+
+			// Act
+			cmd.reconcileAll(&k8sClient{url: server.URL, client: server.Client()})
+
+			// Assert
+			assert.Equal(t, created != nil, tc.created)
+			if tc.created {
+				assert.Equal(t, created.Metadata.OwnerReferences[0].UID, "1234")
+				assert.Equal(t, created.Data, map[string]string{
+					"api_key":  "Y29tcGFueS9hcHAvcHJvZC9hcGkva2V5",
+					"password": "Y29tcGFueS9hcHAvcHJvZC9wYXNzd29yZA==",
+				})
+			}
+			assert.Equal(t, status.Status.ObservedGeneration, int64(1))
+			assert.Equal(t, status.Status.Conditions, []k8sCondition{tc.condition})
+		})
+	}
+}
+
+func TestParseK8sAllowedDirs(t *testing.T) {
+	cases := map[string]struct {
+		values   []string
+		expected map[string][]api.DirPath
+		err      error
+	}{
+		"multiple dirs": {
+			values: []string{"default=company/app", "prod=company/app/prod", "default=company/shared"},
+			expected: map[string][]api.DirPath{
+				"default": {"company/app", "company/shared"},
+				"prod":    {"company/app/prod"},
+			},
+		},
+		"none": {
+			err: ErrK8sNoAllowedDirs,
+		},
+		"no namespace": {
+			values: []string{"company/app"},
+			err:    ErrK8sInvalidAllowedDir("company/app"),
+		},
+		"invalid dir": {
+			values: []string{"default=company"},
+			err:    ErrK8sInvalidAllowedDir("default=company"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			actual, err := parseK8sAllowedDirs(tc.values)
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
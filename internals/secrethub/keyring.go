@@ -10,6 +10,7 @@ import (
 
 	"github.com/secrethub/secrethub-cli/internals/cli/cloneproc"
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/configdir"
 	"github.com/secrethub/secrethub-go/pkg/secrethub/credentials"
 )
 
@@ -32,7 +33,9 @@ var (
 
 const (
 	keyringServiceLabel = "secrethub"
-	keyringKey          = "secrethub-passphrase"
+	// keyringKeyPrefix is followed by the path of the configuration directory in the key of the cached passphrase,
+	// so the passphrase of one profile or configuration directory cannot unlock the credential of another.
+	keyringKeyPrefix = "passphrase:"
 )
 
 // PassphraseReader can retrieve a password and be instructed if the password is incorrect.
@@ -67,10 +70,11 @@ func (pr *passphraseReader) Read() ([]byte, error) {
 }
 
 // NewPassphraseReader constructs a new PassphraseReader using values in the CLI.
-func NewPassphraseReader(io ui.IO, credentialPassphrase string, credentialPassphraseTTL time.Duration) credentials.Reader {
+// The passphrase is cached for the credential in the given configuration directory.
+func NewPassphraseReader(io ui.IO, credentialPassphrase string, credentialPassphraseTTL time.Duration, configDir func() configdir.Dir) credentials.Reader {
 	ttl := credentialPassphraseTTL
-	cleaner := NewKeyringCleaner()
-	keyring := NewKeyring()
+	cleaner := NewKeyringCleaner(configDir)
+	keyring := NewKeyring(configDir)
 
 	return &passphraseReader{
 		io:        io,
@@ -165,7 +169,7 @@ func (c PassphraseCache) Set(passphrase string) error {
 		}
 	}
 
-	item.ExpiresAt = c.ExpiresAt()
+	item.ExpiresAt = c.expiresAt(item)
 
 	return c.keyring.Set(item)
 }
@@ -193,7 +197,7 @@ func (c PassphraseCache) Get() (string, error) {
 		}
 	}
 
-	item.ExpiresAt = c.ExpiresAt()
+	item.ExpiresAt = c.expiresAt(item)
 
 	err = c.keyring.Set(item)
 	if err != nil {
@@ -213,11 +217,22 @@ func (c PassphraseCache) ExpiresAt() time.Time {
 	return time.Now().UTC().Add(c.ttl)
 }
 
+// expiresAt returns a timestamp to expire the keyring item at. Items of a session
+// started with login expire after the idle timeout of the session instead of the ttl.
+func (c PassphraseCache) expiresAt(item *KeyringItem) time.Time {
+	if item.IdleTimeout > 0 {
+		return time.Now().UTC().Add(item.IdleTimeout)
+	}
+	return c.ExpiresAt()
+}
+
 // KeyringItem wraps a passphrase with metadata to be stored the keyring.
 type KeyringItem struct {
 	RunningCleanupProcess bool      `json:"running_cleanup_process,omitempty"`
 	ExpiresAt             time.Time `json:"expires_at"`
-	Passphrase            []byte    `json:"passphrase"`
+	// IdleTimeout is set when the passphrase is cached for a session started with login.
+	IdleTimeout time.Duration `json:"idle_timeout,omitempty"`
+	Passphrase  []byte        `json:"passphrase"`
 }
 
 // IsExpired returns true when the item has expired.
@@ -238,19 +253,27 @@ type Keyring interface {
 type keyring struct {
 	usernameMaxLen int
 	label          string
+	configDir      func() configdir.Dir
 }
 
-// NewKeyring returns a new Keyring
+// NewKeyring returns a new Keyring for the passphrase of the credential in the configuration directory.
+// The directory is only resolved when the keyring is used, so it can be created before the flags are parsed.
 // KeyRing only supports usernames up to 20 characters to ensure the maximum input for the macOS keyring is not achieved.
 // There is also a limited on the maximum length of password about 900 characters, but this is ridiculously long.
 // It is very unlikely that it is hit, and hard to fix for a system up for replacement.
-func NewKeyring() Keyring {
+func NewKeyring(configDir func() configdir.Dir) Keyring {
 	return &keyring{
 		usernameMaxLen: 20,
 		label:          keyringServiceLabel,
+		configDir:      configDir,
 	}
 }
 
+// key returns the key of the cached passphrase of the credential in the configuration directory.
+func (kr keyring) key() string {
+	return keyringKeyPrefix + kr.configDir().Path()
+}
+
 // IsAvailable returns true when the OS keyring is available.
 // On some operating systems it may not be installed.
 func (kr keyring) IsAvailable() bool {
@@ -261,7 +284,7 @@ func (kr keyring) IsAvailable() bool {
 // Get gets an item from the keyring for the given username.
 // This should not be used outside this file!
 func (kr keyring) Get() (*KeyringItem, error) {
-	stored, err := libkeyring.Get(kr.label, keyringKey)
+	stored, err := libkeyring.Get(kr.label, kr.key())
 	if err == libkeyring.ErrNotFound {
 		return nil, ErrKeyringItemNotFound
 	} else if err != nil {
@@ -285,7 +308,7 @@ func (kr keyring) Set(item *KeyringItem) error {
 		return ErrCannotSetKeyringItem(err)
 	}
 
-	err = libkeyring.Set(kr.label, keyringKey, string(bytes))
+	err = libkeyring.Set(kr.label, kr.key(), string(bytes))
 	if err != nil {
 		return ErrCannotSetKeyringItem(err)
 	}
@@ -295,7 +318,7 @@ func (kr keyring) Set(item *KeyringItem) error {
 
 // Delete deletes an item in the keyring for a given username.
 func (kr keyring) Delete() error {
-	err := libkeyring.Delete(kr.label, keyringKey)
+	err := libkeyring.Delete(kr.label, kr.key())
 	if err == libkeyring.ErrNotFound {
 		return ErrKeyringItemNotFound
 	} else if err != nil {
@@ -312,16 +335,22 @@ type KeyringCleaner interface {
 }
 
 // keyringCleaner cleans up the credential by spawning a new CLI process that will take care of cleaning up the credential.
-type keyringCleaner struct{}
+type keyringCleaner struct {
+	configDir func() configdir.Dir
+}
 
-// NewKeyringCleaner returns a new KeyringCleaner.
-func NewKeyringCleaner() KeyringCleaner {
-	return &keyringCleaner{}
+// NewKeyringCleaner returns a new KeyringCleaner for the passphrase of the credential in the configuration directory.
+func NewKeyringCleaner(configDir func() configdir.Dir) KeyringCleaner {
+	return &keyringCleaner{
+		configDir: configDir,
+	}
 }
 
 // Cleanup starts a Cleanup process to clean up the cached passphrase when it expires.
+// The configuration directory is passed with the default profile, so the process
+// clears the passphrase of the same directory, regardless of the active profile.
 func (kc keyringCleaner) Cleanup() error {
-	err := cloneproc.Spawn("keyring-clear")
+	err := cloneproc.Spawn("keyring-clear", "--config-dir", kc.configDir().Path(), "--profile", defaultProfileName)
 	if err != nil {
 		return err
 	}
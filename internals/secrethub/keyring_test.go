@@ -1,10 +1,12 @@
 package secrethub
 
 import (
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/configdir"
 
 	libkeyring "github.com/zalando/go-keyring"
 )
@@ -21,7 +23,9 @@ var (
 
 func newTestKeyring() Keyring {
 	libkeyring.MockInit()
-	return NewKeyring()
+	return NewKeyring(func() configdir.Dir {
+		return configdir.New(filepath.Join("home", ".secrethub"))
+	})
 }
 
 type TestKeyringCleaner struct {
@@ -130,6 +134,33 @@ func TestPassphraseCacheGet_UpdatedAfterRead(t *testing.T) {
 	}
 }
 
+func TestPassphraseCacheGet_IdleTimeout(t *testing.T) {
+	// Arrange
+	keyring := newTestKeyring()
+	cache := NewPassphraseCache(testTTL, &TestKeyringCleaner{}, keyring)
+
+	item := &KeyringItem{
+		RunningCleanupProcess: true,
+		ExpiresAt:             time.Now().UTC().Add(testTTL),
+		IdleTimeout:           time.Hour,
+		Passphrase:            []byte(password),
+	}
+	err := keyring.Set(item)
+	assert.OK(t, err)
+
+	// Act
+	_, err = cache.Get()
+	assert.OK(t, err)
+
+	// Assert
+	actual, err := keyring.Get()
+	assert.OK(t, err)
+	assert.Equal(t, actual.IdleTimeout, time.Hour)
+	if !actual.ExpiresAt.After(time.Now().Add(time.Hour - time.Minute)) {
+		t.Errorf("expiry not extended with the idle timeout")
+	}
+}
+
 func TestPassphraseCacheGet_NonExisting(t *testing.T) {
 	// Arrange
 	cache := NewPassphraseCache(testTTL, &TestKeyringCleaner{}, newTestKeyring())
@@ -241,3 +272,24 @@ func TestKeyring_Delete_NonExisting(t *testing.T) {
 	// Assert
 	assert.Equal(t, err, ErrKeyringItemNotFound)
 }
+
+func TestKeyring_ProfilesDoNotShareItems(t *testing.T) {
+	// Arrange
+	libkeyring.MockInit()
+	base := profiles{base: configdir.New(filepath.Join("home", ".secrethub"))}
+	work := NewKeyring(func() configdir.Dir { return base.dir("work") })
+	personal := NewKeyring(func() configdir.Dir { return base.dir(defaultProfileName) })
+
+	err := work.Set(testKeyringItem)
+	assert.OK(t, err)
+
+	// Act
+	_, err = personal.Get()
+
+	// Assert
+	assert.Equal(t, err, ErrKeyringItemNotFound)
+
+	item, err := work.Get()
+	assert.OK(t, err)
+	assert.Equal(t, item.Passphrase, testKeyringItem.Passphrase)
+}
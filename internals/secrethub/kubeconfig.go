@@ -0,0 +1,26 @@
+package secrethub
+
+import (
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// KubeconfigCommand handles integrations with kubeconfig files.
+type KubeconfigCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+}
+
+// NewKubeconfigCommand creates a new KubeconfigCommand.
+func NewKubeconfigCommand(io ui.IO, newClient newClientFunc) *KubeconfigCommand {
+	return &KubeconfigCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command and its sub-commands on the provided Registerer.
+func (cmd *KubeconfigCommand) Register(r command.Registerer) {
+	clause := r.Command("kubeconfig", "Serve Kubernetes credentials stored in SecretHub.")
+	NewKubeconfigExecCredentialCommand(cmd.io, cmd.newClient).Register(clause)
+}
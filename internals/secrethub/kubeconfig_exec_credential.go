@@ -0,0 +1,184 @@
+package secrethub
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/errio"
+)
+
+// Errors
+var (
+	errKubeconfig                   = errio.Namespace("kubeconfig")
+	ErrNoKubernetesCredential       = errKubeconfig.Code("no_credential").Error("provide the path to a token, a --client-certificate and --client-key, or both")
+	ErrIncompleteClientCertificate  = errKubeconfig.Code("incomplete_client_certificate").Error("--client-certificate and --client-key must be used together")
+	ErrInvalidKubernetesExecInfo    = errKubeconfig.Code("invalid_exec_info").ErrorPref("cannot parse the " + kubernetesExecInfoEnvVar + " environment variable: %s")
+	ErrUnsupportedExecCredentialAPI = errKubeconfig.Code("unsupported_api_version").ErrorPref("unsupported ExecCredential apiVersion %s")
+)
+
+const (
+	// kubernetesExecInfoEnvVar is set by client-go when it invokes a credential plugin.
+	kubernetesExecInfoEnvVar = "KUBERNETES_EXEC_INFO"
+
+	execCredentialKind              = "ExecCredential"
+	defaultExecCredentialAPIVersion = "client.authentication.k8s.io/v1beta1"
+)
+
+var supportedExecCredentialAPIVersions = map[string]bool{
+	"client.authentication.k8s.io/v1alpha1": true,
+	"client.authentication.k8s.io/v1beta1":  true,
+	"client.authentication.k8s.io/v1":       true,
+}
+
+// KubeconfigExecCredentialCommand implements the client-go credential plugin protocol,
+// so that kubectl can read its credentials from SecretHub on demand.
+type KubeconfigExecCredentialCommand struct {
+	io                    ui.IO
+	tokenPath             api.SecretPath
+	clientCertificatePath api.SecretPath
+	clientKeyPath         api.SecretPath
+	expiresIn             time.Duration
+	getenv                func(key string) string
+	now                   func() time.Time
+	newClient             newClientFunc
+}
+
+// NewKubeconfigExecCredentialCommand creates a new KubeconfigExecCredentialCommand.
+func NewKubeconfigExecCredentialCommand(io ui.IO, newClient newClientFunc) *KubeconfigExecCredentialCommand {
+	return &KubeconfigExecCredentialCommand{
+		io:        io,
+		getenv:    os.Getenv,
+		now:       time.Now,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *KubeconfigExecCredentialCommand) Register(r command.Registerer) {
+	clause := r.Command("exec-credential", "Print an ExecCredential for kubectl with a token or client certificate stored in SecretHub.")
+	clause.HelpLong("This command is meant to be invoked by kubectl and other client-go based tools. " +
+		"Configure it in the users section of your kubeconfig file instead of embedding credentials in it:\n\n" +
+		"  users:\n" +
+		"  - name: my-cluster\n" +
+		"    user:\n" +
+		"      exec:\n" +
+		"        apiVersion: client.authentication.k8s.io/v1beta1\n" +
+		"        command: secrethub\n" +
+		"        args: [\"kubeconfig\", \"exec-credential\", \"my-org/my-repo/k8s/token\"]\n\n" +
+		"The secrets are only read when kubectl needs them and are never written to disk.")
+	clause.Arg("token-path", "The path to the secret containing a bearer token").PlaceHolder(secretPathOptionalVersionPlaceHolder).SetValue(&cmd.tokenPath)
+	clause.Flag("client-certificate", "The path to the secret containing a PEM encoded client certificate.").PlaceHolder(secretPathOptionalVersionPlaceHolder).SetValue(&cmd.clientCertificatePath)
+	clause.Flag("client-key", "The path to the secret containing the PEM encoded private key of the client certificate.").PlaceHolder(secretPathOptionalVersionPlaceHolder).SetValue(&cmd.clientKeyPath)
+	clause.Flag("expires-in", "Let kubectl cache the credential for this duration, e.g. 10m. By default kubectl invokes the command for every request.").DurationVar(&cmd.expiresIn)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// execCredential is the object exchanged with client-go credential plugins.
+// See https://kubernetes.io/docs/reference/access-authn-authz/authentication/#client-go-credential-plugins
+type execCredential struct {
+	APIVersion string                `json:"apiVersion"`
+	Kind       string                `json:"kind"`
+	Status     *execCredentialStatus `json:"status,omitempty"`
+}
+
+type execCredentialStatus struct {
+	ExpirationTimestamp   string `json:"expirationTimestamp,omitempty"`
+	Token                 string `json:"token,omitempty"`
+	ClientCertificateData string `json:"clientCertificateData,omitempty"`
+	ClientKeyData         string `json:"clientKeyData,omitempty"`
+}
+
+// Run reads the configured secrets and prints them as an ExecCredential.
+func (cmd *KubeconfigExecCredentialCommand) Run() error {
+	if cmd.tokenPath == "" && cmd.clientCertificatePath == "" && cmd.clientKeyPath == "" {
+		return ErrNoKubernetesCredential
+	}
+
+	if (cmd.clientCertificatePath == "") != (cmd.clientKeyPath == "") {
+		return ErrIncompleteClientCertificate
+	}
+
+	apiVersion, err := cmd.apiVersion()
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	read := func(path api.SecretPath) (string, error) {
+		if path == "" {
+			return "", nil
+		}
+		secret, err := client.Secrets().Versions().GetWithData(path.Value())
+		if err != nil {
+			return "", err
+		}
+		return string(secret.Data), nil
+	}
+
+	status := &execCredentialStatus{}
+
+	token, err := read(cmd.tokenPath)
+	if err != nil {
+		return err
+	}
+	status.Token = strings.TrimSpace(token)
+
+	status.ClientCertificateData, err = read(cmd.clientCertificatePath)
+	if err != nil {
+		return err
+	}
+
+	status.ClientKeyData, err = read(cmd.clientKeyPath)
+	if err != nil {
+		return err
+	}
+
+	if cmd.expiresIn > 0 {
+		status.ExpirationTimestamp = cmd.now().Add(cmd.expiresIn).UTC().Format(time.RFC3339)
+	}
+
+	out, err := json.Marshal(execCredential{
+		APIVersion: apiVersion,
+		Kind:       execCredentialKind,
+		Status:     status,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(cmd.io.Output(), string(out))
+	return nil
+}
+
+// apiVersion returns the ExecCredential API version requested by client-go.
+// Older versions of client-go do not pass any exec info, in which case v1beta1 is used.
+func (cmd *KubeconfigExecCredentialCommand) apiVersion() (string, error) {
+	info := cmd.getenv(kubernetesExecInfoEnvVar)
+	if info == "" {
+		return defaultExecCredentialAPIVersion, nil
+	}
+
+	var req execCredential
+	err := json.Unmarshal([]byte(info), &req)
+	if err != nil {
+		return "", ErrInvalidKubernetesExecInfo(err)
+	}
+
+	if !supportedExecCredentialAPIVersions[req.APIVersion] {
+		return "", ErrUnsupportedExecCredentialAPI(req.APIVersion)
+	}
+
+	return req.APIVersion, nil
+}
@@ -0,0 +1,90 @@
+package secrethub
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// Errors
+var (
+	ErrInvalidLineRange = errMain.Code("invalid_line_range").ErrorPref("invalid line range %q: use <first>:<last> with line numbers starting at 1, e.g. 3:10, 3: or :10")
+	ErrLinesOutOfRange  = errMain.Code("lines_out_of_range").ErrorPref("cannot read from line %d, the secret has %s")
+	ErrInvalidLineCount = errMain.Code("invalid_line_count").Error("the number of lines to read must be positive")
+)
+
+// lineRange selects the lines first to last, counting from 1. A last line of 0 selects up to the end.
+type lineRange struct {
+	first int
+	last  int
+}
+
+// Set implements the flag.Value interface.
+func (r *lineRange) Set(value string) error {
+	parsed, err := parseLineRange(value)
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// String implements the flag.Value interface.
+func (r lineRange) String() string {
+	if r.first == 0 {
+		return ""
+	}
+	if r.last == 0 {
+		return strconv.Itoa(r.first) + ":"
+	}
+	return strconv.Itoa(r.first) + ":" + strconv.Itoa(r.last)
+}
+
+// parseLineRange parses a line range in the format <first>:<last>, in which both line numbers are optional.
+func parseLineRange(value string) (lineRange, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 {
+		return lineRange{}, ErrInvalidLineRange(value)
+	}
+
+	r := lineRange{first: 1}
+	var err error
+	if parts[0] != "" {
+		r.first, err = strconv.Atoi(parts[0])
+		if err != nil || r.first < 1 {
+			return lineRange{}, ErrInvalidLineRange(value)
+		}
+	}
+	if parts[1] != "" {
+		r.last, err = strconv.Atoi(parts[1])
+		if err != nil || r.last < r.first {
+			return lineRange{}, ErrInvalidLineRange(value)
+		}
+	}
+	return r, nil
+}
+
+// selectLines returns the lines of the data in the range, without a newline after the last selected line.
+func selectLines(data []byte, r lineRange) ([]byte, error) {
+	lines := splitLines(data)
+	if r.first > len(lines) {
+		return nil, ErrLinesOutOfRange(r.first, pluralize("line", "lines", len(lines)))
+	}
+
+	last := r.last
+	if last == 0 || last > len(lines) {
+		last = len(lines)
+	}
+	selected := strings.Join(lines[r.first-1:last], "")
+	return bytes.TrimSuffix([]byte(selected), []byte("\n")), nil
+}
+
+// tailLines returns the last n lines of the data, without a newline after the last line.
+func tailLines(data []byte, n int) []byte {
+	lines := splitLines(data)
+	if n > len(lines) {
+		n = len(lines)
+	}
+	selected := strings.Join(lines[len(lines)-n:], "")
+	return bytes.TrimSuffix([]byte(selected), []byte("\n"))
+}
@@ -0,0 +1,101 @@
+package secrethub
+
+import (
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestParseLineRange(t *testing.T) {
+	cases := map[string]struct {
+		value    string
+		expected lineRange
+		err      error
+	}{
+		"range": {
+			value:    "3:10",
+			expected: lineRange{first: 3, last: 10},
+		},
+		"open end": {
+			value:    "3:",
+			expected: lineRange{first: 3},
+		},
+		"open start": {
+			value:    ":10",
+			expected: lineRange{first: 1, last: 10},
+		},
+		"single line": {
+			value:    "2:2",
+			expected: lineRange{first: 2, last: 2},
+		},
+		"no colon": {
+			value: "3",
+			err:   ErrInvalidLineRange("3"),
+		},
+		"zero": {
+			value: "0:3",
+			err:   ErrInvalidLineRange("0:3"),
+		},
+		"last before first": {
+			value: "10:3",
+			err:   ErrInvalidLineRange("10:3"),
+		},
+		"not a number": {
+			value: "a:b",
+			err:   ErrInvalidLineRange("a:b"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := parseLineRange(tc.value)
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
+
+func TestSelectLines(t *testing.T) {
+	data := []byte("one\ntwo\nthree\nfour\n")
+
+	cases := map[string]struct {
+		r        lineRange
+		expected string
+		err      error
+	}{
+		"first line": {
+			r:        lineRange{first: 1, last: 1},
+			expected: "one",
+		},
+		"middle": {
+			r:        lineRange{first: 2, last: 3},
+			expected: "two\nthree",
+		},
+		"to the end": {
+			r:        lineRange{first: 3},
+			expected: "three\nfour",
+		},
+		"past the end": {
+			r:        lineRange{first: 3, last: 10},
+			expected: "three\nfour",
+		},
+		"out of range": {
+			r:   lineRange{first: 5},
+			err: ErrLinesOutOfRange(5, "4 lines"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := selectLines(data, tc.r)
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, string(actual), tc.expected)
+		})
+	}
+}
+
+func TestTailLines(t *testing.T) {
+	assert.Equal(t, string(tailLines([]byte("one\ntwo\nthree"), 2)), "two\nthree")
+	assert.Equal(t, string(tailLines([]byte("one\ntwo\nthree\n"), 1)), "three")
+	assert.Equal(t, string(tailLines([]byte("one\n"), 5)), "one")
+}
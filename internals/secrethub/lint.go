@@ -0,0 +1,535 @@
+package secrethub
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/errio"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Errors
+var (
+	errLint               = errio.Namespace("lint")
+	ErrLintIssuesFound    = errLint.Code("issues_found").ErrorPref("found %s")
+	ErrInvalidLintConfig  = errLint.Code("invalid_config").ErrorPref("invalid lint configuration %s: %v")
+	ErrInvalidLintPattern = errLint.Code("invalid_pattern").ErrorPref("invalid regular expression %q in lint configuration: %v")
+	ErrUnknownLintCheck   = errLint.Code("unknown_check").ErrorPref("unknown lint check %s: the options are naming, duplicate, placeholder and weak-password")
+)
+
+const (
+	lintCheckNaming       = "naming"
+	lintCheckDuplicate    = "duplicate"
+	lintCheckPlaceholder  = "placeholder"
+	lintCheckWeakPassword = "weak-password"
+
+	defaultLintMinEntropy = 64
+	// Short values such as ports or booleans are commonly shared, so they are
+	// not reported as duplicates.
+	lintMinDuplicateLength = 8
+)
+
+// defaultPlaceholders are regular expressions matching values that are typically
+// left behind as placeholders or defaults. They are matched case insensitively.
+var defaultPlaceholders = []string{
+	`change[-_ ]?me`,
+	`passw(o|0)rd1?`,
+	`secret`,
+	`todo|tbd|fixme`,
+	`placeholder|example|default|dummy|sample`,
+	`test(ing)?`,
+	`admin|root|guest|letmein|qwerty`,
+	`none|null|nil|undefined|empty`,
+	`1234(5|56|5678|56789)?`,
+	`<[^>]*>`,
+	`\$\{[^}]*\}`,
+	`\{\{[^}]*\}\}`,
+}
+
+// LintCommand checks the secrets in a directory or a template or env file for common mistakes.
+type LintCommand struct {
+	io              ui.IO
+	newClient       newClientFunc
+	target          string
+	path            api.DirPath
+	configFile      string
+	format          string
+	fileType        string
+	templateVars    map[string]string
+	templateVersion string
+	osEnv           []string
+}
+
+// NewLintCommand creates a new LintCommand.
+func NewLintCommand(io ui.IO, newClient newClientFunc) *LintCommand {
+	return &LintCommand{
+		io:           io,
+		newClient:    newClient,
+		templateVars: make(map[string]string),
+		osEnv:        os.Environ(),
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *LintCommand) Register(r command.Registerer) {
+	clause := r.Command("lint", "Check the secrets in a repository for naming, duplicate, placeholder and weak values, or check a template or env file.")
+	clause.HelpLong("The following checks are run on every secret in the repository or directory:\n\n" +
+		"  naming         The secret name does not match a naming rule from the configuration file.\n" +
+		"  duplicate      The value is also stored at another path.\n" +
+		"  placeholder    The value looks like a default or placeholder value, such as `changeme`.\n" +
+		"  weak-password  The value looks like a generated password but has an estimated strength below the minimum entropy.\n\n" +
+		"Checks are configured with a YAML file, for example:\n\n" +
+		"  naming:\n" +
+		"    - pattern: ^[a-z0-9_]+$\n" +
+		"      message: secret names must be snake_case\n" +
+		"      paths: ^company/repo/prod/\n" +
+		"  placeholders:\n" +
+		"    - ^my-.*$\n" +
+		"  min_entropy: 80\n" +
+		"  ignore:\n" +
+		"    - ^company/repo/legacy/\n" +
+		"  disable:\n" +
+		"    - duplicate\n\n" +
+		"When the path is a local file, the file is parsed as a template or env file instead and the following checks are run:\n\n" +
+		"  syntax            The file cannot be parsed or rendered.\n" +
+		"  missing-secret    A referenced secret or directory does not exist.\n" +
+		"  secret-access     A referenced secret cannot be read with the current credential.\n" +
+		"  missing-variable  A template variable is used but not set.\n" +
+		"  unused-variable   A template variable set with --var is not used.\n\n" +
+		"Files with the .env extension are parsed as env files and other files as templates, unless --type is set. " +
+		"Secrets and variables in blocks of v3 templates that are not rendered with the given variables are not checked.\n\n" +
+		"The command exits with a non-zero status code when issues are found, so it can be used in CI.")
+	clause.Arg("path", "The path to the repository or directory to lint, or to a local template or env file.").Required().PlaceHolder(optionalDirPathPlaceHolder + "|<file>").StringVar(&cmd.target)
+	clause.Flag("config", "The path to a YAML file configuring the checks of secrets in a directory.").ExistingFileVar(&cmd.configFile)
+	clause.Flag("output-format", "Specify the format in which to output the issues. Options are: table and json.").HintOptions(formatTable, formatJSON).Default(formatTable).StringVar(&cmd.format)
+	clause.Flag("type", "The type of the file to lint. Options are: auto, template and env. With auto, files with the .env extension are linted as env files.").HintOptions(lintFileTypeAuto, lintFileTypeTemplate, lintFileTypeEnv).Default(lintFileTypeAuto).StringVar(&cmd.fileType)
+	clause.Flag("var", "Define the value for a template variable with `VAR=VALUE`, e.g. --var env=prod").Short('v').StringMapVar(&cmd.templateVars)
+	clause.Flag("template-version", "The template syntax version to be used. The options are v1, v2, v3, latest or auto to automatically detect the version.").Default("auto").StringVar(&cmd.templateVersion)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run lints the secrets and prints the issues that are found.
+func (cmd *LintCommand) Run() error {
+	if cmd.format != formatTable && cmd.format != formatJSON {
+		return errNoSuchFormat(cmd.format)
+	}
+
+	info, err := os.Stat(cmd.target)
+	if err == nil && !info.IsDir() {
+		return cmd.runFile()
+	}
+
+	err = cmd.path.Set(cmd.target)
+	if err != nil {
+		return err
+	}
+
+	config := &lintConfig{}
+	if cmd.configFile != "" {
+		config, err = readLintConfig(cmd.configFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	linter, err := newLinter(config)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	tree, err := client.Dirs().GetTree(cmd.path.Value(), -1, false)
+	if err != nil {
+		return err
+	}
+	excludeTrash(tree, cmd.path.Value())
+
+	var secrets []lintSecret
+	for _, secretPath := range secretPathsInDir(tree.RootDir, cmd.path.Value()) {
+		if linter.ignored(secretPath) {
+			continue
+		}
+
+		secret, err := client.Secrets().Versions().GetWithData(secretPath)
+		if err != nil {
+			return err
+		}
+		secrets = append(secrets, lintSecret{path: secretPath, data: secret.Data})
+	}
+
+	issues := linter.lint(secrets)
+	err = cmd.printIssues(issues)
+	if err != nil {
+		return err
+	}
+
+	if len(issues) > 0 {
+		return ErrLintIssuesFound(pluralize("issue", "issues", len(issues)))
+	}
+
+	if cmd.format == formatTable {
+		fmt.Fprintf(cmd.io.Output(), "No issues found in %s.\n", pluralize("secret", "secrets", len(secrets)))
+	}
+	return nil
+}
+
+// printIssues writes the issues in the configured format.
+func (cmd *LintCommand) printIssues(issues []lintIssue) error {
+	if cmd.format == formatJSON {
+		formatter := newJSONFormatter(cmd.io.Output(), []string{"path", "check", "message"})
+		for _, issue := range issues {
+			err := formatter.Write([]string{issue.path, issue.check, issue.message})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(issues) == 0 {
+		return nil
+	}
+
+	w := tabwriter.NewWriter(cmd.io.Output(), 0, 4, 4, ' ', 0)
+	fmt.Fprintf(w, "%s\t%s\t%s\n", "PATH", "CHECK", "MESSAGE")
+	for _, issue := range issues {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", issue.path, issue.check, issue.message)
+	}
+	return w.Flush()
+}
+
+// lintConfig configures the checks of the lint command.
+type lintConfig struct {
+	Naming       []lintNamingRule `yaml:"naming"`
+	Placeholders []string         `yaml:"placeholders"`
+	MinEntropy   float64          `yaml:"min_entropy"`
+	Ignore       []string         `yaml:"ignore"`
+	Disable      []string         `yaml:"disable"`
+}
+
+// lintNamingRule requires the names of secrets to match a pattern.
+// When paths is set, the rule only applies to secrets with a matching path.
+type lintNamingRule struct {
+	Pattern string `yaml:"pattern"`
+	Paths   string `yaml:"paths"`
+	Message string `yaml:"message"`
+}
+
+// readLintConfig reads the lint configuration from a YAML file.
+func readLintConfig(filename string) (*lintConfig, error) {
+	raw, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, ErrCannotReadFile(filename, err)
+	}
+
+	config := &lintConfig{}
+	err = yaml.UnmarshalStrict(raw, config)
+	if err != nil {
+		return nil, ErrInvalidLintConfig(filename, err)
+	}
+	return config, nil
+}
+
+type lintSecret struct {
+	path string
+	data []byte
+}
+
+type lintIssue struct {
+	path    string
+	check   string
+	message string
+}
+
+type compiledNamingRule struct {
+	pattern *regexp.Regexp
+	paths   *regexp.Regexp
+	message string
+}
+
+// linter runs the checks configured by a lintConfig.
+type linter struct {
+	naming       []compiledNamingRule
+	placeholders []*regexp.Regexp
+	ignore       []*regexp.Regexp
+	disabled     map[string]bool
+	minEntropy   float64
+}
+
+// newLinter compiles the regular expressions in the configuration.
+func newLinter(config *lintConfig) (*linter, error) {
+	l := &linter{
+		disabled:   map[string]bool{},
+		minEntropy: config.MinEntropy,
+	}
+	if l.minEntropy == 0 {
+		l.minEntropy = defaultLintMinEntropy
+	}
+
+	for _, check := range config.Disable {
+		switch check {
+		case lintCheckNaming, lintCheckDuplicate, lintCheckPlaceholder, lintCheckWeakPassword:
+			l.disabled[check] = true
+		default:
+			return nil, ErrUnknownLintCheck(check)
+		}
+	}
+
+	for _, rule := range config.Naming {
+		pattern, err := compileLintPattern(rule.Pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		compiled := compiledNamingRule{
+			pattern: pattern,
+			message: rule.Message,
+		}
+		if rule.Paths != "" {
+			compiled.paths, err = compileLintPattern(rule.Paths)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if compiled.message == "" {
+			compiled.message = fmt.Sprintf("name does not match %s", rule.Pattern)
+		}
+		l.naming = append(l.naming, compiled)
+	}
+
+	for _, placeholder := range append(defaultPlaceholders, config.Placeholders...) {
+		// Placeholders must match the complete value.
+		pattern, err := regexp.Compile("(?i)^(" + placeholder + ")$")
+		if err != nil {
+			return nil, ErrInvalidLintPattern(placeholder, err)
+		}
+		l.placeholders = append(l.placeholders, pattern)
+	}
+
+	for _, ignore := range config.Ignore {
+		pattern, err := compileLintPattern(ignore)
+		if err != nil {
+			return nil, err
+		}
+		l.ignore = append(l.ignore, pattern)
+	}
+
+	return l, nil
+}
+
+func compileLintPattern(pattern string) (*regexp.Regexp, error) {
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, ErrInvalidLintPattern(pattern, err)
+	}
+	return compiled, nil
+}
+
+// ignored returns whether the secret at the given path is excluded from linting.
+func (l *linter) ignored(secretPath string) bool {
+	for _, pattern := range l.ignore {
+		if pattern.MatchString(secretPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// lint runs all enabled checks on the secrets and returns the issues sorted by path.
+func (l *linter) lint(secrets []lintSecret) []lintIssue {
+	var issues []lintIssue
+	for _, secret := range secrets {
+		if !l.disabled[lintCheckNaming] {
+			issues = append(issues, l.checkNaming(secret)...)
+		}
+		var placeholderIssues []lintIssue
+		if !l.disabled[lintCheckPlaceholder] {
+			placeholderIssues = l.checkPlaceholder(secret)
+			issues = append(issues, placeholderIssues...)
+		}
+		// A placeholder is not a generated password, so it is not also reported as weak.
+		if !l.disabled[lintCheckWeakPassword] && len(placeholderIssues) == 0 {
+			issues = append(issues, l.checkWeakPassword(secret)...)
+		}
+	}
+	if !l.disabled[lintCheckDuplicate] {
+		issues = append(issues, checkDuplicates(secrets)...)
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].path < issues[j].path
+	})
+	return issues
+}
+
+func (l *linter) checkNaming(secret lintSecret) []lintIssue {
+	var issues []lintIssue
+	name := path.Base(secret.path)
+	for _, rule := range l.naming {
+		if rule.paths != nil && !rule.paths.MatchString(secret.path) {
+			continue
+		}
+		if !rule.pattern.MatchString(name) {
+			issues = append(issues, lintIssue{path: secret.path, check: lintCheckNaming, message: rule.message})
+		}
+	}
+	return issues
+}
+
+func (l *linter) checkPlaceholder(secret lintSecret) []lintIssue {
+	value := strings.TrimSpace(string(secret.data))
+	if value == "" {
+		return []lintIssue{{path: secret.path, check: lintCheckPlaceholder, message: "value is empty"}}
+	}
+
+	if isRepeatedCharacter(value) {
+		return []lintIssue{{path: secret.path, check: lintCheckPlaceholder, message: "value looks like a placeholder or default value"}}
+	}
+
+	for _, pattern := range l.placeholders {
+		if pattern.MatchString(value) {
+			return []lintIssue{{path: secret.path, check: lintCheckPlaceholder, message: "value looks like a placeholder or default value"}}
+		}
+	}
+	return nil
+}
+
+func (l *linter) checkWeakPassword(secret lintSecret) []lintIssue {
+	if !looksLikePassword(secret.data) {
+		return nil
+	}
+
+	entropy := estimateEntropy(string(secret.data))
+	if entropy >= l.minEntropy {
+		return nil
+	}
+
+	return []lintIssue{{
+		path:    secret.path,
+		check:   lintCheckWeakPassword,
+		message: fmt.Sprintf("estimated strength of %.0f bits is below the minimum of %.0f bits", entropy, l.minEntropy),
+	}}
+}
+
+// isRepeatedCharacter returns whether the value is a single character repeated, e.g. xxxx or ****.
+func isRepeatedCharacter(value string) bool {
+	runes := []rune(value)
+	if len(runes) < 4 {
+		return false
+	}
+	for _, r := range runes[1:] {
+		if r != runes[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// checkDuplicates reports every secret that has the same value as a secret at another path.
+func checkDuplicates(secrets []lintSecret) []lintIssue {
+	paths := map[[sha256.Size]byte][]string{}
+	var order [][sha256.Size]byte
+	for _, secret := range secrets {
+		if len(secret.data) < lintMinDuplicateLength {
+			continue
+		}
+
+		sum := sha256.Sum256(secret.data)
+		if _, ok := paths[sum]; !ok {
+			order = append(order, sum)
+		}
+		paths[sum] = append(paths[sum], secret.path)
+	}
+
+	var issues []lintIssue
+	for _, sum := range order {
+		duplicates := paths[sum]
+		if len(duplicates) < 2 {
+			continue
+		}
+
+		for i, secretPath := range duplicates {
+			others := make([]string, 0, len(duplicates)-1)
+			others = append(others, duplicates[:i]...)
+			others = append(others, duplicates[i+1:]...)
+			issues = append(issues, lintIssue{
+				path:    secretPath,
+				check:   lintCheckDuplicate,
+				message: "same value as " + strings.Join(others, ", "),
+			})
+		}
+	}
+	return issues
+}
+
+// looksLikePassword returns whether the value looks like a randomly generated
+// password: a single word mixing at least two character classes, that does not
+// look like a URL, hostname, e-mail address or path.
+func looksLikePassword(data []byte) bool {
+	value := string(data)
+	if len(value) == 0 || len(value) > 64 || strings.ContainsAny(value, "/:@. \t\r\n") {
+		return false
+	}
+
+	classes := 0
+	for _, has := range characterClasses(value) {
+		if has {
+			classes++
+		}
+	}
+	return classes >= 2
+}
+
+// estimateEntropy estimates the strength of a randomly generated value in bits,
+// based on its length and the character classes it uses.
+func estimateEntropy(value string) float64 {
+	sizes := [4]int{26, 26, 10, 32}
+	pool := 0
+	for i, has := range characterClasses(value) {
+		if has {
+			pool += sizes[i]
+		}
+	}
+	if pool == 0 {
+		return 0
+	}
+	return float64(len([]rune(value))) * math.Log2(float64(pool))
+}
+
+// characterClasses returns whether the value contains lowercase letters,
+// uppercase letters, digits and symbols respectively.
+func characterClasses(value string) [4]bool {
+	var classes [4]bool
+	for _, r := range value {
+		switch {
+		case unicode.IsLower(r):
+			classes[0] = true
+		case unicode.IsUpper(r):
+			classes[1] = true
+		case unicode.IsDigit(r):
+			classes[2] = true
+		default:
+			classes[3] = true
+		}
+	}
+	return classes
+}
@@ -0,0 +1,374 @@
+package secrethub
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/secrethub/tpl"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/errio"
+)
+
+// Errors
+var (
+	ErrUnknownLintFileType = errLint.Code("unknown_file_type").ErrorPref("unknown file type %s: the options are auto, template and env")
+)
+
+const (
+	lintFileTypeAuto     = "auto"
+	lintFileTypeTemplate = "template"
+	lintFileTypeEnv      = "env"
+
+	lintCheckSyntax          = "syntax"
+	lintCheckMissingSecret   = "missing-secret"
+	lintCheckSecretAccess    = "secret-access"
+	lintCheckMissingVariable = "missing-variable"
+	lintCheckUnusedVariable  = "unused-variable"
+)
+
+// runFile lints the template or env file and prints the issues that are found.
+func (cmd *LintCommand) runFile() error {
+	issues, err := cmd.lintFile()
+	if err != nil {
+		return err
+	}
+
+	err = cmd.printIssues(issues)
+	if err != nil {
+		return err
+	}
+
+	if len(issues) > 0 {
+		return ErrLintIssuesFound(pluralize("issue", "issues", len(issues)))
+	}
+
+	if cmd.format == formatTable {
+		fmt.Fprintf(cmd.io.Output(), "No issues found in %s.\n", cmd.target)
+	}
+	return nil
+}
+
+// lintFile parses and renders the template or env file with readers that keep track of
+// the secrets and variables that are used, and returns the issues found while doing so.
+func (cmd *LintCommand) lintFile() ([]lintIssue, error) {
+	isEnvFile, err := cmd.isEnvFile()
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := ioutil.ReadFile(cmd.target)
+	if err != nil {
+		return nil, ErrCannotReadFile(cmd.target, err)
+	}
+
+	osEnv, _ := parseKeyValueStringsToMap(cmd.osEnv)
+	varReader, err := newVariableReader(osEnv, cmd.templateVars)
+	if err != nil {
+		return nil, err
+	}
+	vars := newLintVariableReader(varReader)
+	secrets := newLintSecretReader(newSecretReader(cmd.newClient))
+
+	var syntaxErrs []error
+	if isEnvFile {
+		syntaxErrs, err = cmd.lintEnvFile(vars, secrets)
+	} else {
+		syntaxErrs, err = cmd.lintTemplate(raw, vars, secrets)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []lintIssue
+	for _, err := range syntaxErrs {
+		issues = append(issues, cmd.fileIssue(lintCheckSyntax, err.Error()))
+	}
+	for _, path := range secrets.unreadablePaths() {
+		switch err := secrets.errs[path]; {
+		case secrets.invalid[path]:
+			issues = append(issues, cmd.fileIssue(lintCheckSyntax, fmt.Sprintf("%s is not a valid path: %s", path, err)))
+		case api.IsErrNotFound(err):
+			issues = append(issues, cmd.fileIssue(lintCheckMissingSecret, fmt.Sprintf("%s does not exist", path)))
+		default:
+			issues = append(issues, cmd.fileIssue(lintCheckSecretAccess, fmt.Sprintf("%s cannot be read: %s", path, err)))
+		}
+	}
+	for _, name := range vars.missingVariables() {
+		issues = append(issues, cmd.fileIssue(lintCheckMissingVariable, fmt.Sprintf("variable %s is not set", name)))
+	}
+	for _, name := range vars.unusedVariables(cmd.templateVars) {
+		issues = append(issues, cmd.fileIssue(lintCheckUnusedVariable, fmt.Sprintf("variable %s is set but not used", name)))
+	}
+	return issues, nil
+}
+
+// isEnvFile returns whether the file should be linted as an env file instead of a template.
+func (cmd *LintCommand) isEnvFile() (bool, error) {
+	switch cmd.fileType {
+	case lintFileTypeAuto:
+		return filepath.Ext(cmd.target) == ".env", nil
+	case lintFileTypeEnv:
+		return true, nil
+	case lintFileTypeTemplate:
+		return false, nil
+	default:
+		return false, ErrUnknownLintFileType(cmd.fileType)
+	}
+}
+
+// lintTemplate renders the template and returns the errors that make it invalid.
+func (cmd *LintCommand) lintTemplate(raw []byte, vars tpl.VariableReader, secrets *lintSecretReader) ([]error, error) {
+	parser, err := getTemplateParser(raw, cmd.templateVersion, filepath.Dir(cmd.target))
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := parser.Parse(string(raw), 1, 1)
+	if err != nil {
+		return []error{err}, nil
+	}
+
+	renderErr, err := secrets.evaluate(func() error {
+		_, err := template.Evaluate(vars, secrets)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if renderErr != nil {
+		return []error{renderErr}, nil
+	}
+	return nil, nil
+}
+
+// lintEnvFile resolves all values of the env file and returns the errors that make it invalid.
+func (cmd *LintCommand) lintEnvFile(vars tpl.VariableReader, secrets *lintSecretReader) ([]error, error) {
+	env := &environment{
+		readFile:         ioutil.ReadFile,
+		templateVersion:  cmd.templateVersion,
+		envFileSeparator: defaultEnvFileSeparator,
+	}
+	envFile, err := env.readEnvFile(cmd.target, vars)
+	if err != nil {
+		return []error{err}, nil
+	}
+
+	values, err := envFile.env()
+	if err != nil {
+		return []error{err}, nil
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var syntaxErrs []error
+	for _, name := range names {
+		renderErr, err := secrets.evaluate(func() error {
+			_, err := values[name].resolve(secrets)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		if renderErr != nil {
+			syntaxErrs = append(syntaxErrs, renderErr)
+		}
+	}
+	return syntaxErrs, nil
+}
+
+// fileIssue returns an issue with the linted file.
+func (cmd *LintCommand) fileIssue(check string, message string) lintIssue {
+	return lintIssue{path: cmd.target, check: check, message: message}
+}
+
+// lintSecretReader reads secrets and keeps track of the secrets that cannot be read.
+type lintSecretReader struct {
+	secretReader tpl.SecretReader
+	values       map[string]string
+	dirs         map[string][]string
+	errs         map[string]error
+	// invalid contains the paths that are not valid, e.g. because a variable in it is missing.
+	invalid map[string]bool
+	// unreadable contains the paths that failed a rendering and are read as empty since.
+	unreadable map[string]bool
+	// lastErrPath is the path of the last read when it failed.
+	lastErrPath string
+}
+
+func newLintSecretReader(sr tpl.SecretReader) *lintSecretReader {
+	return &lintSecretReader{
+		secretReader: sr,
+		values:       make(map[string]string),
+		dirs:         make(map[string][]string),
+		errs:         make(map[string]error),
+		invalid:      make(map[string]bool),
+		unreadable:   make(map[string]bool),
+	}
+}
+
+// ReadSecret reads the secret, once for every path.
+func (sr *lintSecretReader) ReadSecret(path string) (string, error) {
+	if sr.unreadable[path] {
+		sr.lastErrPath = ""
+		return "", nil
+	}
+	if err, ok := sr.errs[path]; ok {
+		sr.lastErrPath = path
+		return "", err
+	}
+	if value, ok := sr.values[path]; ok {
+		sr.lastErrPath = ""
+		return value, nil
+	}
+
+	err := api.ValidateSecretPath(path)
+	if err != nil {
+		sr.invalid[path] = true
+		sr.errs[path] = err
+		sr.lastErrPath = path
+		return "", err
+	}
+
+	value, err := sr.secretReader.ReadSecret(path)
+	if err != nil {
+		sr.errs[path] = err
+		sr.lastErrPath = path
+		return "", err
+	}
+	sr.values[path] = value
+	sr.lastErrPath = ""
+	return value, nil
+}
+
+// ListSecrets lists the secrets in the directory, once for every path.
+func (sr *lintSecretReader) ListSecrets(dirPath string) ([]string, error) {
+	if sr.unreadable[dirPath] {
+		sr.lastErrPath = ""
+		return nil, nil
+	}
+	if err, ok := sr.errs[dirPath]; ok {
+		sr.lastErrPath = dirPath
+		return nil, err
+	}
+	if paths, ok := sr.dirs[dirPath]; ok {
+		sr.lastErrPath = ""
+		return paths, nil
+	}
+
+	err := api.ValidateDirPath(dirPath)
+	if err != nil {
+		sr.invalid[dirPath] = true
+		sr.errs[dirPath] = err
+		sr.lastErrPath = dirPath
+		return nil, err
+	}
+
+	paths, err := tpl.ListSecrets(sr.secretReader, dirPath)
+	if err != nil {
+		sr.errs[dirPath] = err
+		sr.lastErrPath = dirPath
+		return nil, err
+	}
+	sr.dirs[dirPath] = paths
+	sr.lastErrPath = ""
+	return paths, nil
+}
+
+// evaluate calls render until it no longer fails because of a secret that does not exist or cannot be
+// accessed. Every secret that makes it fail is read as empty afterwards, so all of these secrets are found.
+// Secrets that do not make it fail, e.g. because they have a default value, are not reported.
+// The error of the last rendering is returned as renderErr when it is not caused by a secret.
+// An error is returned when a secret cannot be read for another reason, e.g. because the API cannot be reached.
+func (sr *lintSecretReader) evaluate(render func() error) (renderErr error, err error) {
+	for {
+		sr.lastErrPath = ""
+		renderErr := render()
+		if renderErr == nil || sr.lastErrPath == "" {
+			return renderErr, nil
+		}
+
+		readErr := sr.errs[sr.lastErrPath]
+		if !strings.Contains(renderErr.Error(), readErr.Error()) {
+			return renderErr, nil
+		}
+		if !sr.invalid[sr.lastErrPath] && !api.IsErrNotFound(readErr) && !isAccessErr(readErr) {
+			return nil, readErr
+		}
+		sr.unreadable[sr.lastErrPath] = true
+	}
+}
+
+// unreadablePaths returns the paths of the secrets that cannot be read, sorted.
+func (sr *lintSecretReader) unreadablePaths() []string {
+	paths := make([]string, 0, len(sr.unreadable))
+	for path := range sr.unreadable {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// isAccessErr returns whether the error is returned because the
+// credential is not allowed to perform the operation.
+func isAccessErr(err error) bool {
+	statusErr, ok := err.(errio.PublicStatusError)
+	return ok && (statusErr.StatusCode == http.StatusForbidden || statusErr.StatusCode == http.StatusUnauthorized)
+}
+
+// lintVariableReader reads template variables and keeps track of the variables that are used.
+// Missing variables are read as empty, so all missing variables are found.
+type lintVariableReader struct {
+	reader  tpl.VariableReader
+	used    map[string]bool
+	missing map[string]bool
+}
+
+func newLintVariableReader(reader tpl.VariableReader) *lintVariableReader {
+	return &lintVariableReader{
+		reader:  reader,
+		used:    make(map[string]bool),
+		missing: make(map[string]bool),
+	}
+}
+
+// ReadVariable reads the variable and records that it is used.
+func (r *lintVariableReader) ReadVariable(name string) (string, error) {
+	r.used[name] = true
+	value, err := r.reader.ReadVariable(name)
+	if err == tpl.ErrTemplateVarNotFound(name) {
+		r.missing[name] = true
+		return "", nil
+	}
+	return value, err
+}
+
+// missingVariables returns the names of the variables that are used but not set, sorted.
+func (r *lintVariableReader) missingVariables() []string {
+	names := make([]string, 0, len(r.missing))
+	for name := range r.missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// unusedVariables returns the names of the given variables that are not used, sorted.
+func (r *lintVariableReader) unusedVariables(vars map[string]string) []string {
+	var names []string
+	for name := range vars {
+		if !r.used[strings.ToLower(name)] {
+			names = append(names, strings.ToLower(name))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,125 @@
+package secrethub
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/internals/errio"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestLintCommand_lintFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrethub-lint-test")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	errForbidden := errio.PublicStatusError{
+		PublicError: errio.PublicError{Code: "forbidden", Message: "access denied"},
+		StatusCode:  http.StatusForbidden,
+	}
+
+	newClient := func() (secrethub.ClientInterface, error) {
+		return fakeclient.Client{
+			SecretService: &fakeclient.SecretService{
+				VersionService: &fakeclient.SecretVersionService{
+					GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+						switch path {
+						case "company/app/db/password", "company/app/prod/api_key":
+							return &api.SecretVersion{Data: []byte("secret")}, nil
+						case "company/app/admin/password":
+							return nil, errForbidden
+						default:
+							return nil, api.ErrSecretNotFound
+						}
+					},
+				},
+			},
+		}, nil
+	}
+
+	cases := map[string]struct {
+		file         string
+		content      string
+		fileType     string
+		templateVars map[string]string
+		expected     func(file string) []lintIssue
+	}{
+		"valid template": {
+			file: "app.conf",
+			content: `password={{ secret "company/app/db/password" }}` + "\n" +
+				`key={{ secret (printf "company/app/%s/api_key" (var "env")) }}` + "\n" +
+				`token={{ secret "company/app/token" | default "none" }}`,
+			templateVars: map[string]string{"env": "prod"},
+			expected: func(file string) []lintIssue {
+				return nil
+			},
+		},
+		"template with issues": {
+			file: "app.conf",
+			content: `password={{ secret "company/app/db/missing" }}` + "\n" +
+				`admin={{ secret "company/app/admin/password" }}` + "\n" +
+				`env={{ var "env" }}` + "\n" +
+				`other={{ secret "company/app/other" }}`,
+			templateVars: map[string]string{"unused": "value"},
+			expected: func(file string) []lintIssue {
+				return []lintIssue{
+					{path: file, check: lintCheckSecretAccess, message: fmt.Sprintf("company/app/admin/password cannot be read: %s", errForbidden)},
+					{path: file, check: lintCheckMissingSecret, message: "company/app/db/missing does not exist"},
+					{path: file, check: lintCheckMissingSecret, message: "company/app/other does not exist"},
+					{path: file, check: lintCheckMissingVariable, message: "variable env is not set"},
+					{path: file, check: lintCheckUnusedVariable, message: "variable unused is set but not used"},
+				}
+			},
+		},
+		"env file": {
+			file:    "app.env",
+			content: "DB_PASSWORD={{ company/app/db/password }}\nDB_USER={{ company/app/db/user }}\n",
+			expected: func(file string) []lintIssue {
+				return []lintIssue{
+					{path: file, check: lintCheckMissingSecret, message: "company/app/db/user does not exist"},
+				}
+			},
+		},
+		"env file as template": {
+			file:     "app.env",
+			content:  "DB_PASSWORD={{ company/app/db/password }}\n",
+			fileType: lintFileTypeTemplate,
+			expected: func(file string) []lintIssue {
+				return nil
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			file := filepath.Join(dir, tc.file)
+			err := ioutil.WriteFile(file, []byte(tc.content), 0600)
+			assert.OK(t, err)
+
+			cmd := NewLintCommand(fakeui.NewIO(t), newClient)
+			cmd.target = file
+			cmd.fileType = lintFileTypeAuto
+			if tc.fileType != "" {
+				cmd.fileType = tc.fileType
+			}
+			cmd.templateVersion = "auto"
+			cmd.osEnv = nil
+			if tc.templateVars != nil {
+				cmd.templateVars = tc.templateVars
+			}
+
+			issues, err := cmd.lintFile()
+			assert.OK(t, err)
+			assert.Equal(t, issues, tc.expected(file))
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package secrethub
+
+import (
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestLinter(t *testing.T) {
+	secrets := []lintSecret{
+		{path: "company/repo/db_password", data: []byte("x8Kq2mZp9LwR4tYv7NcB1s")},
+		{path: "company/repo/db-user", data: []byte("postgres")},
+		{path: "company/repo/api_key", data: []byte("changeme")},
+		{path: "company/repo/empty", data: []byte("  \n")},
+		{path: "company/repo/masked", data: []byte("********")},
+		{path: "company/repo/short_pass", data: []byte("abc123XY")},
+		{path: "company/repo/prod/db_password", data: []byte("x8Kq2mZp9LwR4tYv7NcB1s")},
+		{path: "company/repo/host", data: []byte("db.example.com")},
+		{path: "company/repo/template", data: []byte("${DB_PASSWORD}")},
+	}
+
+	cases := map[string]struct {
+		config   lintConfig
+		expected []lintIssue
+	}{
+		"defaults": {
+			expected: []lintIssue{
+				{path: "company/repo/api_key", check: lintCheckPlaceholder, message: "value looks like a placeholder or default value"},
+				{path: "company/repo/db_password", check: lintCheckDuplicate, message: "same value as company/repo/prod/db_password"},
+				{path: "company/repo/empty", check: lintCheckPlaceholder, message: "value is empty"},
+				{path: "company/repo/masked", check: lintCheckPlaceholder, message: "value looks like a placeholder or default value"},
+				{path: "company/repo/prod/db_password", check: lintCheckDuplicate, message: "same value as company/repo/db_password"},
+				{path: "company/repo/short_pass", check: lintCheckWeakPassword, message: "estimated strength of 48 bits is below the minimum of 64 bits"},
+				{path: "company/repo/template", check: lintCheckPlaceholder, message: "value looks like a placeholder or default value"},
+			},
+		},
+		"naming rules and disabled checks": {
+			config: lintConfig{
+				Naming: []lintNamingRule{
+					{Pattern: "^[a-z0-9_]+$", Message: "use snake_case"},
+					{Pattern: "^db_", Paths: "^company/repo/prod/"},
+				},
+				Disable: []string{lintCheckPlaceholder, lintCheckDuplicate},
+				Ignore:  []string{"short_pass$"},
+			},
+			expected: []lintIssue{
+				{path: "company/repo/db-user", check: lintCheckNaming, message: "use snake_case"},
+			},
+		},
+		"custom placeholders and entropy": {
+			config: lintConfig{
+				Placeholders: []string{"postgres"},
+				MinEntropy:   200,
+				Disable:      []string{lintCheckDuplicate},
+			},
+			expected: []lintIssue{
+				{path: "company/repo/api_key", check: lintCheckPlaceholder, message: "value looks like a placeholder or default value"},
+				{path: "company/repo/db-user", check: lintCheckPlaceholder, message: "value looks like a placeholder or default value"},
+				{path: "company/repo/db_password", check: lintCheckWeakPassword, message: "estimated strength of 131 bits is below the minimum of 200 bits"},
+				{path: "company/repo/empty", check: lintCheckPlaceholder, message: "value is empty"},
+				{path: "company/repo/masked", check: lintCheckPlaceholder, message: "value looks like a placeholder or default value"},
+				{path: "company/repo/prod/db_password", check: lintCheckWeakPassword, message: "estimated strength of 131 bits is below the minimum of 200 bits"},
+				{path: "company/repo/short_pass", check: lintCheckWeakPassword, message: "estimated strength of 48 bits is below the minimum of 200 bits"},
+				{path: "company/repo/template", check: lintCheckPlaceholder, message: "value looks like a placeholder or default value"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			l, err := newLinter(&tc.config)
+			assert.OK(t, err)
+
+			var filtered []lintSecret
+			for _, secret := range secrets {
+				if !l.ignored(secret.path) {
+					filtered = append(filtered, secret)
+				}
+			}
+
+			assert.Equal(t, l.lint(filtered), tc.expected)
+		})
+	}
+}
+
+func TestNewLinter_Errors(t *testing.T) {
+	_, err := newLinter(&lintConfig{Disable: []string{"unknown"}})
+	assert.Equal(t, err, ErrUnknownLintCheck("unknown"))
+
+	_, err = newLinter(&lintConfig{Naming: []lintNamingRule{{Pattern: "("}}})
+	assert.Equal(t, err == nil, false)
+}
@@ -12,6 +12,8 @@ import (
 
 	"github.com/secrethub/secrethub-go/internals/api"
 	"github.com/secrethub/secrethub-go/internals/errio"
+
+	"github.com/docker/go-units"
 )
 
 // LsCommand lists a repo, secret or namespace.
@@ -19,6 +21,8 @@ type LsCommand struct {
 	path          api.Path
 	quiet         bool
 	useTimestamps bool
+	showSize      bool
+	filters       []string
 	io            ui.IO
 	newClient     newClientFunc
 }
@@ -38,6 +42,8 @@ func (cmd *LsCommand) Register(r command.Registerer) {
 	clause.Arg("path", "The path to list contents of").SetValue(&cmd.path)
 	clause.Flag("quiet", "Only print paths.").Short('q').BoolVar(&cmd.quiet)
 	registerTimestampFlag(clause).BoolVar(&cmd.useTimestamps)
+	clause.Flag("size", "Show the size of secrets. This reads, and therefore decrypts, every listed secret version.").BoolVar(&cmd.showSize)
+	clause.Flag("filter", "When listing a directory, only list the secrets and directories that have a label, e.g. --filter label=env=prod. Can be repeated to match multiple labels.").PlaceHolder("label=<key>=<value>").StringsVar(&cmd.filters)
 
 	command.BindAction(clause, cmd.Run)
 }
@@ -46,6 +52,11 @@ func (cmd *LsCommand) Register(r command.Registerer) {
 func (cmd *LsCommand) Run() error {
 	timeFormatter := NewTimeFormatter(cmd.useTimestamps)
 
+	filter, err := parseLabelFilters(cmd.filters)
+	if err != nil {
+		return err
+	}
+
 	if cmd.path == "" {
 		repoLSCommand := NewRepoLSCommand(cmd.io, cmd.newClient)
 		repoLSCommand.quiet = cmd.quiet
@@ -66,12 +77,17 @@ func (cmd *LsCommand) Run() error {
 			return err
 		}
 
-		version, err := client.Secrets().Versions().GetWithoutData(secretPath.Value())
+		var version *api.SecretVersion
+		if cmd.showSize {
+			version, err = client.Secrets().Versions().GetWithData(secretPath.Value())
+		} else {
+			version, err = client.Secrets().Versions().GetWithoutData(secretPath.Value())
+		}
 		if err != nil {
 			return err
 		}
 
-		err = printVersions(cmd.io.Output(), cmd.quiet, timeFormatter, version)
+		err = printVersions(cmd.io.Output(), cmd.quiet, cmd.showSize, timeFormatter, version)
 		if err != nil {
 			return err
 		}
@@ -88,7 +104,27 @@ func (cmd *LsCommand) Run() error {
 		} else if err != nil && !api.IsErrNotFound(err) {
 			return err
 		} else if err == nil {
-			err = printDir(cmd.io.Output(), cmd.quiet, dirFS.RootDir, timeFormatter)
+			if len(filter) > 0 {
+				metadata, err := readRepoMetadata(client, dirPath.Value())
+				if err != nil {
+					return err
+				}
+				filterDir(dirFS.RootDir, dirPath.Value(), metadata, filter)
+			}
+
+			var sizes map[string]int
+			if cmd.showSize && !cmd.quiet {
+				sizes = make(map[string]int, len(dirFS.RootDir.Secrets))
+				for _, secret := range dirFS.RootDir.Secrets {
+					version, err := client.Secrets().Versions().GetWithData(api.JoinPaths(dirPath.Value(), secret.Name))
+					if err != nil {
+						return err
+					}
+					sizes[secret.Name] = len(version.Data)
+				}
+			}
+
+			err = printDir(cmd.io.Output(), cmd.quiet, dirFS.RootDir, timeFormatter, sizes)
 			if err != nil {
 				return err
 			}
@@ -99,14 +135,19 @@ func (cmd *LsCommand) Run() error {
 	// Try SecretPath
 	secretPath, err := cmd.path.ToSecretPath()
 	if err == nil {
-		versions, err := client.Secrets().Versions().ListWithoutData(secretPath.Value())
+		var versions []*api.SecretVersion
+		if cmd.showSize {
+			versions, err = client.Secrets().Versions().ListWithData(secretPath.Value())
+		} else {
+			versions, err = client.Secrets().Versions().ListWithoutData(secretPath.Value())
+		}
 		if api.IsErrNotFound(err) {
 			return ErrResourceNotFound(cmd.path)
 		} else if err != nil {
 			return err
 		}
 
-		err = printVersions(cmd.io.Output(), cmd.quiet, timeFormatter, versions...)
+		err = printVersions(cmd.io.Output(), cmd.quiet, cmd.showSize, timeFormatter, versions...)
 		if err != nil {
 			return err
 		}
@@ -132,17 +173,45 @@ func (cmd *LsCommand) Run() error {
 	return errio.UnexpectedError(errors.New("invalid path argument"))
 }
 
+// filterDir removes the subdirectories and secrets from the directory that do not match the filter.
+func filterDir(dir *api.Dir, dirPath string, metadata *repoMetadata, filter labelFilter) {
+	subDirs := dir.SubDirs[:0]
+	for _, subDir := range dir.SubDirs {
+		if filter.matches(metadata.get(api.JoinPaths(dirPath, subDir.Name))) {
+			subDirs = append(subDirs, subDir)
+		}
+	}
+	dir.SubDirs = subDirs
+
+	secrets := dir.Secrets[:0]
+	for _, secret := range dir.Secrets {
+		if filter.matches(metadata.get(api.JoinPaths(dirPath, secret.Name))) {
+			secrets = append(secrets, secret)
+		}
+	}
+	dir.Secrets = secrets
+}
+
 // printVersions prints out secret versions in long or short format.
-func printVersions(w io.Writer, quiet bool, timeFormatter TimeFormatter, versions ...*api.SecretVersion) error {
+// When showSize is set, the versions must contain their data.
+func printVersions(w io.Writer, quiet bool, showSize bool, timeFormatter TimeFormatter, versions ...*api.SecretVersion) error {
 	if quiet {
 		for _, version := range versions {
 			fmt.Fprintf(w, "%s\n", version.Name())
 		}
 	} else {
 		w := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
-		fmt.Fprintf(w, "%s\t%s\t%s\n", "NAME", "STATUS", "CREATED")
+		if showSize {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", "NAME", "STATUS", "SIZE", "CREATED")
+		} else {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", "NAME", "STATUS", "CREATED")
+		}
 		for _, version := range versions {
-			fmt.Fprintf(w, "%s\t%s\t%s\n", version.Name(), version.Status, timeFormatter.Format(version.CreatedAt.Local()))
+			if showSize {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", version.Name(), version.Status, formatSize(len(version.Data)), timeFormatter.Format(version.CreatedAt.Local()))
+			} else {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", version.Name(), version.Status, timeFormatter.Format(version.CreatedAt.Local()))
+			}
 		}
 		err := w.Flush()
 		if err != nil {
@@ -153,7 +222,8 @@ func printVersions(w io.Writer, quiet bool, timeFormatter TimeFormatter, version
 }
 
 // printDir prints out directory contents in long or short format.
-func printDir(w io.Writer, quiet bool, dir *api.Dir, timeFormatter TimeFormatter) error {
+// When sizes is set, it contains the size of every secret in the directory by name.
+func printDir(w io.Writer, quiet bool, dir *api.Dir, timeFormatter TimeFormatter, sizes map[string]int) error {
 	sort.Sort(api.SortDirByName(dir.SubDirs))
 	sort.Sort(api.SortSecretByName(dir.Secrets))
 
@@ -166,12 +236,24 @@ func printDir(w io.Writer, quiet bool, dir *api.Dir, timeFormatter TimeFormatter
 		}
 	} else {
 		tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
-		fmt.Fprintf(tw, "%s\t%s\t%s\n", "NAME", "STATUS", "CREATED")
+		if sizes != nil {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", "NAME", "STATUS", "SIZE", "CREATED")
+		} else {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", "NAME", "STATUS", "CREATED")
+		}
 		for _, dir := range dir.SubDirs {
-			fmt.Fprintf(tw, "%s/\t%s\t%s\n", dir.Name, dir.Status, timeFormatter.Format(dir.CreatedAt.Local()))
+			if sizes != nil {
+				fmt.Fprintf(tw, "%s/\t%s\t%s\t%s\n", dir.Name, dir.Status, "-", timeFormatter.Format(dir.CreatedAt.Local()))
+			} else {
+				fmt.Fprintf(tw, "%s/\t%s\t%s\n", dir.Name, dir.Status, timeFormatter.Format(dir.CreatedAt.Local()))
+			}
 		}
 		for _, secret := range dir.Secrets {
-			fmt.Fprintf(tw, "%s\t%s\t%s\n", secret.Name, secret.Status, timeFormatter.Format(secret.CreatedAt.Local()))
+			if sizes != nil {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", secret.Name, secret.Status, formatSize(sizes[secret.Name]), timeFormatter.Format(secret.CreatedAt.Local()))
+			} else {
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", secret.Name, secret.Status, timeFormatter.Format(secret.CreatedAt.Local()))
+			}
 		}
 		err := tw.Flush()
 		if err != nil {
@@ -180,3 +262,8 @@ func printDir(w io.Writer, quiet bool, dir *api.Dir, timeFormatter TimeFormatter
 	}
 	return nil
 }
+
+// formatSize returns a human readable representation of a number of bytes, e.g. 1.5KiB.
+func formatSize(n int) string {
+	return units.BytesSize(float64(n))
+}
@@ -0,0 +1,59 @@
+package secrethub
+
+import (
+	"os"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+)
+
+// RegisterLogFlags registers the flags that configure the format and destination of the log messages of the given logger.
+func RegisterLogFlags(r FlagRegisterer, logger cli.Logger) {
+	r.Flag("log-format", "The format of log messages: "+strings.Join(cli.LogFormats, " or ")+". Use json for logs that are parsed by machines.").
+		Default(cli.LogFormatText).SetValue(&logFormatFlag{logger: logger, format: cli.LogFormatText})
+	r.Flag("log-file", "Append log messages to this file instead of writing them to stderr.").SetValue(&logFileFlag{logger: logger})
+}
+
+// logFormatFlag configures the format of a logger.
+type logFormatFlag struct {
+	format string
+	logger cli.Logger
+}
+
+// String implements the flag.Value interface.
+func (f logFormatFlag) String() string {
+	return f.format
+}
+
+// Set changes the format of the logger.
+func (f *logFormatFlag) Set(value string) error {
+	err := f.logger.SetFormat(value)
+	if err != nil {
+		return err
+	}
+	f.format = value
+	return nil
+}
+
+// logFileFlag configures a logger to write to a file.
+type logFileFlag struct {
+	path   string
+	logger cli.Logger
+}
+
+// String implements the flag.Value interface.
+func (f logFileFlag) String() string {
+	return f.path
+}
+
+// Set opens the file for appending and directs the log messages to it.
+// The file stays open until the process exits.
+func (f *logFileFlag) Set(value string) error {
+	file, err := os.OpenFile(value, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return ErrCannotWrite(value, err)
+	}
+	f.path = value
+	f.logger.SetOutput(file)
+	return nil
+}
@@ -0,0 +1,83 @@
+package secrethub
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/errio"
+)
+
+// Errors
+var (
+	errSession             = errio.Namespace("session")
+	ErrKeyringNotAvailable = errSession.Code("keyring_not_available").Error("cannot start a session: the OS keyring is not available on this system")
+	ErrInvalidIdleTimeout  = errSession.Code("invalid_idle_timeout").Error("the idle timeout must be longer than 0")
+	ErrNoSessionStarted    = errSession.Code("not_started").Error(
+		"no session was started: the credential is not protected by a passphrase, " +
+			"the passphrase is set with --credential-passphrase or passphrase caching is turned off with --credential-passphrase-cache-ttl=0",
+	)
+)
+
+// LoginCommand unlocks the credential and keeps it unlocked in a session until it is idle for too long.
+type LoginCommand struct {
+	idleTimeout     time.Duration
+	io              ui.IO
+	credentialStore CredentialConfig
+	keyring         Keyring
+}
+
+// NewLoginCommand creates a new LoginCommand.
+func NewLoginCommand(io ui.IO, credentialStore CredentialConfig) *LoginCommand {
+	return &LoginCommand{
+		io:              io,
+		credentialStore: credentialStore,
+		keyring:         NewKeyring(credentialStore.ConfigDir),
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *LoginCommand) Register(r command.Registerer) {
+	clause := r.Command("login", "Unlock your credential for a session.")
+	clause.HelpLong("Asks for the passphrase of your credential once and keeps it in the OS keyring, " +
+		"so following commands do not ask for it again. The session ends when no command uses the credential for the idle timeout " +
+		"or when you run `" + ApplicationName + " logout`. Use `" + ApplicationName + " session status` to see when it ends.")
+	clause.Flag("idle-timeout", "End the session after the credential is not used for this duration, e.g. 30m or 8h.").Default("1h").DurationVar(&cmd.idleTimeout)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run unlocks the credential and starts the session.
+func (cmd *LoginCommand) Run() error {
+	if cmd.idleTimeout <= 0 {
+		return ErrInvalidIdleTimeout
+	}
+	if !cmd.keyring.IsAvailable() {
+		return ErrKeyringNotAvailable
+	}
+
+	// Importing the credential reads the passphrase and caches it in the keyring.
+	_, err := cmd.credentialStore.Import()
+	if err != nil {
+		return err
+	}
+
+	item, err := cmd.keyring.Get()
+	if err == ErrKeyringItemNotFound {
+		return ErrNoSessionStarted
+	} else if err != nil {
+		return err
+	}
+
+	item.IdleTimeout = cmd.idleTimeout
+	item.ExpiresAt = time.Now().UTC().Add(cmd.idleTimeout)
+	err = cmd.keyring.Set(item)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Logged in. The session ends when the credential is not used for %s or when you run `%s logout`.\n", cmd.idleTimeout, ApplicationName)
+	return nil
+}
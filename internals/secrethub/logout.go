@@ -0,0 +1,58 @@
+package secrethub
+
+import (
+	"fmt"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// LogoutCommand ends the session started with login.
+type LogoutCommand struct {
+	io          ui.IO
+	keyring     Keyring
+	agentSocket func() string
+}
+
+// NewLogoutCommand creates a new LogoutCommand.
+func NewLogoutCommand(io ui.IO, credentialStore CredentialConfig) *LogoutCommand {
+	return &LogoutCommand{
+		io:          io,
+		keyring:     NewKeyring(credentialStore.ConfigDir),
+		agentSocket: credentialStore.AgentSocket,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *LogoutCommand) Register(r command.Registerer) {
+	clause := r.Command("logout", "End the session and remove the passphrase of your credential from the OS keyring.")
+	clause.HelpLong("Removes the passphrase of your credential from the OS keyring " +
+		"and stops the `" + ApplicationName + " agent`, when it is running, so it no longer uses your credential.")
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run removes the cached passphrase from the keyring and stops the agent.
+func (cmd *LogoutCommand) Run() error {
+	socket := cmd.agentSocket()
+	stopped, err := stopAgent(socket)
+	if err != nil {
+		return err
+	}
+	if stopped {
+		fmt.Fprintf(cmd.io.Output(), "Stopped the agent listening on %s.\n", socket)
+	}
+
+	err = cmd.keyring.Delete()
+	if err == ErrKeyringItemNotFound {
+		if !stopped {
+			fmt.Fprintln(cmd.io.Output(), "You are not logged in.")
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(cmd.io.Output(), "Logged out.")
+	return nil
+}
@@ -0,0 +1,278 @@
+package secrethub
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/errio"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/iterator"
+)
+
+// Errors
+var (
+	errMeta              = errio.Namespace("meta")
+	ErrInvalidMetadata   = errMeta.Code("invalid").ErrorPref("the metadata of %s is invalid: %s")
+	ErrInvalidLabelKey   = errMeta.Code("invalid_label_key").ErrorPref("invalid label key %q: keys can only contain letters, digits, dashes, underscores and dots")
+	ErrInvalidMetaFilter = errMeta.Code("invalid_filter").ErrorPref("invalid filter %q: filters must have the format label=<key>=<value>")
+	ErrLabelNotFound     = errMeta.Code("label_not_found").ErrorPref("%s has no label %s")
+	ErrMetadataChanged   = errMeta.Code("changed").ErrorPref("the metadata of %s changed while it was checked: try again")
+	ErrMetadataNoAuthor  = errMeta.Code("no_author").ErrorPref("cannot find who wrote version %d of %s in the audit log")
+)
+
+const (
+	// metadataRepoName is the repository in a namespace that holds the metadata of the secrets and directories in the namespace.
+	// It contains a secret for every repository that has metadata, with the same name as the repository.
+	metadataRepoName = "secrethub-metadata"
+)
+
+// MetaCommand handles the metadata of secrets and directories.
+type MetaCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+}
+
+// NewMetaCommand creates a new MetaCommand.
+func NewMetaCommand(io ui.IO, newClient newClientFunc) *MetaCommand {
+	return &MetaCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command and its sub-commands on the provided Registerer.
+func (cmd *MetaCommand) Register(r command.Registerer) {
+	clause := r.Command("meta", "Manage the labels of secrets and directories.")
+	clause.HelpLong("Labels are key-value pairs that describe a secret or directory, " +
+		"such as its owner, environment, rotation period, a link to a ticket or a description. " +
+		"They can be used to filter the output of `" + ApplicationName + " ls` with --filter label=<key>=<value>.\n" +
+		"\n" +
+		"Labels are stored as secrets in the " + metadataRepoName + " repository of the namespace, " +
+		"so they are encrypted end-to-end like any other secret. " +
+		"That repository must be readable by everyone who reads the labels, " +
+		"so everyone who can read the labels of one directory can read the labels of the whole namespace.")
+	NewMetaSetCommand(cmd.io, cmd.newClient).Register(clause)
+	NewMetaGetCommand(cmd.io, cmd.newClient).Register(clause)
+}
+
+// repoMetadata holds the labels of the secrets, directories and service accounts in a repository.
+type repoMetadata struct {
+	// Labels maps the lowercase paths of secrets and directories to their labels.
+	Labels map[string]map[string]string `json:"labels"`
+	// Services maps the IDs of the service accounts of the repository to their labels.
+	Services map[string]map[string]string `json:"services,omitempty"`
+	// AccessRequests are the requests for access to directories of the repository, in the order they were made.
+	AccessRequests []*accessRequest `json:"access_requests,omitempty"`
+}
+
+// get returns the labels of the secret or directory at the path.
+func (m *repoMetadata) get(path string) map[string]string {
+	return m.Labels[strings.ToLower(path)]
+}
+
+// set sets the labels of the secret or directory at the path. Labels with an empty value are removed.
+func (m *repoMetadata) set(path string, labels map[string]string) {
+	updateLabels(m.Labels, strings.ToLower(path), labels)
+}
+
+// setService sets the labels of the service account with the given ID. Labels with an empty value are removed.
+func (m *repoMetadata) setService(serviceID string, labels map[string]string) {
+	if m.Services == nil {
+		m.Services = map[string]map[string]string{}
+	}
+	updateLabels(m.Services, serviceID, labels)
+}
+
+// serviceExpiry returns the expiry date of the service account with the given ID, if it has one.
+func (m *repoMetadata) serviceExpiry(serviceID string) (time.Time, bool, error) {
+	value, ok := m.Services[serviceID][expiresLabel]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	expires, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, ErrInvalidExpiry(value, serviceID, err)
+	}
+	return expires, true, nil
+}
+
+// updateLabels updates the labels stored under the key. Labels with an empty value are removed.
+func updateLabels(all map[string]map[string]string, key string, labels map[string]string) {
+	current := all[key]
+	if current == nil {
+		current = map[string]string{}
+	}
+	for label, value := range labels {
+		if value == "" {
+			delete(current, label)
+		} else {
+			current[label] = value
+		}
+	}
+
+	if len(current) == 0 {
+		delete(all, key)
+	} else {
+		all[key] = current
+	}
+}
+
+// metadataPath returns the path of the secret that holds the metadata of the repository of the given path.
+func metadataPath(path string) string {
+	elements := strings.SplitN(path, "/", 3)
+	return api.JoinPaths(elements[0], metadataRepoName, elements[1])
+}
+
+// readRepoMetadata reads the metadata of the repository of the given path.
+// It returns empty metadata when the repository has none.
+func readRepoMetadata(client secrethub.ClientInterface, path string) (*repoMetadata, error) {
+	metadata := &repoMetadata{Labels: map[string]map[string]string{}}
+	secret, err := client.Secrets().Versions().GetWithData(metadataPath(path))
+	if api.IsErrNotFound(err) {
+		return metadata, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(secret.Data, metadata)
+	if err != nil {
+		return nil, ErrInvalidMetadata(metadataPath(path), err)
+	}
+	if metadata.Labels == nil {
+		metadata.Labels = map[string]map[string]string{}
+	}
+	return metadata, nil
+}
+
+// metadataAuthor returns the account that wrote the metadata of the repository of the given path
+// in the state for which matches returns true. That is the author of the oldest version of the
+// metadata from which on every version matches.
+//
+// Anyone with write access to the metadata repository can change the metadata, so commands that
+// act on it, e.g. by setting access rules, use this to check who actually wrote it.
+func metadataAuthor(client secrethub.ClientInterface, path string, matches func(*repoMetadata) bool) (string, error) {
+	secretPath := metadataPath(path)
+	versions, err := client.Secrets().Versions().ListWithData(secretPath)
+	if err != nil {
+		return "", err
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version > versions[j].Version
+	})
+
+	version := 0
+	for _, v := range versions {
+		metadata := &repoMetadata{}
+		err = json.Unmarshal(v.Data, metadata)
+		if err != nil || !matches(metadata) {
+			break
+		}
+		version = v.Version
+	}
+	if version == 0 {
+		return "", ErrMetadataChanged(secretPath)
+	}
+
+	iter := client.Secrets().EventIterator(secretPath, &secrethub.AuditEventIteratorParams{})
+	for {
+		event, err := iter.Next()
+		if err == iterator.Done {
+			return "", ErrMetadataNoAuthor(version, secretPath)
+		} else if err != nil {
+			return "", err
+		}
+
+		if event.Action == api.AuditActionCreate && event.Subject.Type == api.AuditSubjectSecretVersion &&
+			event.Subject.SecretVersion != nil && event.Subject.SecretVersion.Version == version {
+			return getAuditActor(event)
+		}
+	}
+}
+
+// writeRepoMetadata writes the metadata of the repository of the given path, creating the metadata repository when needed.
+func writeRepoMetadata(client secrethub.ClientInterface, path string, metadata *repoMetadata) error {
+	return writeMetadataSecret(client, metadataPath(path), metadata)
+}
+
+// writeMetadataSecret writes the value as JSON to the secret at the given path in the
+// metadata repository of a namespace, creating the metadata repository when needed.
+func writeMetadataSecret(client secrethub.ClientInterface, path string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Repos().Create(api.JoinPaths(strings.SplitN(path, "/", 2)[0], metadataRepoName))
+	if err != nil && err != api.ErrRepoAlreadyExists {
+		return err
+	}
+
+	_, err = client.Secrets().Write(path, data)
+	return err
+}
+
+// setSecretLabels sets the labels of the secret or directory at the path. Labels with an empty value are removed.
+func setSecretLabels(client secrethub.ClientInterface, path string, labels map[string]string) error {
+	metadata, err := readRepoMetadata(client, path)
+	if err != nil {
+		return err
+	}
+
+	metadata.set(path, labels)
+	return writeRepoMetadata(client, path, metadata)
+}
+
+// validateLabelKey checks that a label key can be used in a filter.
+func validateLabelKey(key string) error {
+	if key == "" {
+		return ErrInvalidLabelKey(key)
+	}
+	for _, r := range key {
+		if !isJSONQueryIdentifierChar(r) && r != '.' {
+			return ErrInvalidLabelKey(key)
+		}
+	}
+	return nil
+}
+
+// sortedLabelKeys returns the keys of the labels in alphabetical order.
+func sortedLabelKeys(labels map[string]string) []string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// labelFilter matches secrets and directories that have all the given labels.
+type labelFilter map[string]string
+
+// parseLabelFilters parses filters in the format label=<key>=<value>.
+func parseLabelFilters(filters []string) (labelFilter, error) {
+	result := labelFilter{}
+	for _, filter := range filters {
+		parts := strings.SplitN(filter, "=", 3)
+		if len(parts) != 3 || parts[0] != "label" || parts[1] == "" {
+			return nil, ErrInvalidMetaFilter(filter)
+		}
+		result[parts[1]] = parts[2]
+	}
+	return result, nil
+}
+
+// matches returns whether the labels contain all the labels of the filter.
+func (f labelFilter) matches(labels map[string]string) bool {
+	for key, value := range f {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package secrethub
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// MetaGetCommand prints the labels of a secret or directory.
+type MetaGetCommand struct {
+	io        ui.IO
+	path      api.Path
+	key       string
+	newClient newClientFunc
+}
+
+// NewMetaGetCommand creates a new MetaGetCommand.
+func NewMetaGetCommand(io ui.IO, newClient newClientFunc) *MetaGetCommand {
+	return &MetaGetCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *MetaGetCommand) Register(r command.Registerer) {
+	clause := r.Command("get", "Print the labels of a secret or directory.")
+	clause.Arg("path", "The path to the secret or directory.").Required().PlaceHolder(repoPathPlaceHolder + "[/<path>]").SetValue(&cmd.path)
+	clause.Arg("key", "Only print the value of the label with this key.").StringVar(&cmd.key)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run prints the labels.
+func (cmd *MetaGetCommand) Run() error {
+	// Metadata is stored per repository, so the path must be in a repository.
+	_, err := cmd.path.ToDirPath()
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	metadata, err := readRepoMetadata(client, cmd.path.Value())
+	if err != nil {
+		return err
+	}
+
+	labels := metadata.get(cmd.path.Value())
+	if cmd.key != "" {
+		value, ok := labels[cmd.key]
+		if !ok {
+			return ErrLabelNotFound(cmd.path, cmd.key)
+		}
+		fmt.Fprintln(cmd.io.Output(), value)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(cmd.io.Output(), 0, 2, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\t%s\n", "KEY", "VALUE")
+	for _, key := range sortedLabelKeys(labels) {
+		fmt.Fprintf(w, "%s\t%s\n", key, labels[key])
+	}
+	return w.Flush()
+}
@@ -0,0 +1,96 @@
+package secrethub
+
+import (
+	"fmt"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+)
+
+// MetaSetCommand sets the labels of a secret or directory.
+type MetaSetCommand struct {
+	io        ui.IO
+	path      api.Path
+	labels    map[string]string
+	newClient newClientFunc
+}
+
+// NewMetaSetCommand creates a new MetaSetCommand.
+func NewMetaSetCommand(io ui.IO, newClient newClientFunc) *MetaSetCommand {
+	return &MetaSetCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *MetaSetCommand) Register(r command.Registerer) {
+	clause := r.Command("set", "Set labels on a secret or directory.")
+	clause.HelpLong("Sets labels on a secret or directory, e.g. `" + ApplicationName + " meta set company/app/db owner=team-a env=prod`. " +
+		"Existing labels with other keys are kept. Use an empty value to remove a label, e.g. `env=`.")
+	clause.Arg("path", "The path to the secret or directory.").Required().PlaceHolder(repoPathPlaceHolder + "[/<path>]").SetValue(&cmd.path)
+	clause.Arg("labels", "The labels to set as <key>=<value>.").Required().StringMapVar(&cmd.labels)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run sets the labels.
+func (cmd *MetaSetCommand) Run() error {
+	if cmd.path.HasVersion() {
+		return errCannotWriteToVersion
+	}
+
+	for key := range cmd.labels {
+		err := validateLabelKey(key)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Metadata is stored per repository, so the path must be in a repository.
+	_, err := cmd.path.ToDirPath()
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	err = checkResourceExists(client, cmd.path)
+	if err != nil {
+		return err
+	}
+
+	err = setSecretLabels(client, cmd.path.Value(), cmd.labels)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "The labels of %s have been updated.\n", cmd.path)
+	return nil
+}
+
+// checkResourceExists returns ErrResourceNotFound when there is no directory or secret at the path.
+func checkResourceExists(client secrethub.ClientInterface, path api.Path) error {
+	isDir, err := client.Dirs().Exists(path.Value())
+	if err != nil && !api.IsErrNotFound(err) {
+		return err
+	}
+	if isDir {
+		return nil
+	}
+
+	isSecret, err := client.Secrets().Exists(path.Value())
+	if err != nil && !api.IsErrNotFound(err) {
+		return err
+	}
+	if !isSecret {
+		return ErrResourceNotFound(path)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package secrethub
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestRepoMetadata_Set(t *testing.T) {
+	metadata := &repoMetadata{Labels: map[string]map[string]string{}}
+
+	metadata.set("company/app/DB", map[string]string{"owner": "team-a", "env": "prod"})
+	assert.Equal(t, metadata.get("company/app/db"), map[string]string{"owner": "team-a", "env": "prod"})
+
+	metadata.set("company/app/db", map[string]string{"env": "", "ticket": "OPS-1"})
+	assert.Equal(t, metadata.get("company/app/db"), map[string]string{"owner": "team-a", "ticket": "OPS-1"})
+
+	metadata.set("company/app/db", map[string]string{"owner": "", "ticket": ""})
+	_, ok := metadata.Labels["company/app/db"]
+	assert.Equal(t, ok, false)
+}
+
+func TestMetadataPath(t *testing.T) {
+	assert.Equal(t, metadataPath("company/app"), "company/secrethub-metadata/app")
+	assert.Equal(t, metadataPath("company/app/db/password"), "company/secrethub-metadata/app")
+}
+
+func TestParseLabelFilters(t *testing.T) {
+	cases := map[string]struct {
+		filters  []string
+		expected labelFilter
+		err      error
+	}{
+		"single": {
+			filters:  []string{"label=env=prod"},
+			expected: labelFilter{"env": "prod"},
+		},
+		"multiple": {
+			filters:  []string{"label=env=prod", "label=owner=team-a"},
+			expected: labelFilter{"env": "prod", "owner": "team-a"},
+		},
+		"value with equals sign": {
+			filters:  []string{"label=query=a=b"},
+			expected: labelFilter{"query": "a=b"},
+		},
+		"none": {
+			expected: labelFilter{},
+		},
+		"no label prefix": {
+			filters: []string{"env=prod"},
+			err:     ErrInvalidMetaFilter("env=prod"),
+		},
+		"no value": {
+			filters: []string{"label=env"},
+			err:     ErrInvalidMetaFilter("label=env"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := parseLabelFilters(tc.filters)
+			assert.Equal(t, err, tc.err)
+			if tc.err == nil {
+				assert.Equal(t, actual, tc.expected)
+			}
+		})
+	}
+}
+
+func TestFilterDir(t *testing.T) {
+	metadata := &repoMetadata{Labels: map[string]map[string]string{
+		"company/app/prod":      {"env": "prod"},
+		"company/app/db-prod":   {"env": "prod", "owner": "team-a"},
+		"company/app/db-dev":    {"env": "dev", "owner": "team-a"},
+		"company/app/unrelated": {"owner": "team-b"},
+	}}
+
+	dir := &api.Dir{
+		SubDirs: []*api.Dir{{Name: "prod"}, {Name: "dev"}},
+		Secrets: []*api.Secret{{Name: "db-prod"}, {Name: "db-dev"}, {Name: "unrelated"}},
+	}
+
+	filterDir(dir, "company/app", metadata, labelFilter{"env": "prod"})
+
+	assert.Equal(t, dir.SubDirs, []*api.Dir{{Name: "prod"}})
+	assert.Equal(t, dir.Secrets, []*api.Secret{{Name: "db-prod"}})
+}
+
+func TestMetaSetCommand_Run(t *testing.T) {
+	cases := map[string]struct {
+		path     api.Path
+		labels   map[string]string
+		existing string
+		expected map[string]map[string]string
+		err      error
+	}{
+		"new metadata": {
+			path:   "company/app/db/password",
+			labels: map[string]string{"owner": "team-a"},
+			expected: map[string]map[string]string{
+				"company/app/db/password": {"owner": "team-a"},
+			},
+		},
+		"existing metadata": {
+			path:     "company/app/db/password",
+			labels:   map[string]string{"env": "prod"},
+			existing: `{"labels":{"company/app/db/password":{"owner":"team-a"},"company/app/db":{"owner":"team-b"}}}`,
+			expected: map[string]map[string]string{
+				"company/app/db/password": {"owner": "team-a", "env": "prod"},
+				"company/app/db":          {"owner": "team-b"},
+			},
+		},
+		"not found": {
+			path:   "company/app/db/missing",
+			labels: map[string]string{"owner": "team-a"},
+			err:    ErrResourceNotFound("company/app/db/missing"),
+		},
+		"invalid key": {
+			path:   "company/app/db/password",
+			labels: map[string]string{"owner name": "team-a"},
+			err:    ErrInvalidLabelKey("owner name"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var written []byte
+			io := fakeui.NewIO(t)
+			cmd := MetaSetCommand{
+				io:     io,
+				path:   tc.path,
+				labels: tc.labels,
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						DirService: &fakeclient.DirService{
+							ExistsFunc: func(path string) (bool, error) {
+								return false, nil
+							},
+						},
+						RepoService: &fakeclient.RepoService{
+							CreateFunc: func(path string) (*api.Repo, error) {
+								assert.Equal(t, path, "company/secrethub-metadata")
+								return nil, api.ErrRepoAlreadyExists
+							},
+						},
+						SecretService: &fakeclient.SecretService{
+							ExistsFunc: func(path string) (bool, error) {
+								return path == "company/app/db/password", nil
+							},
+							WriteFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+								assert.Equal(t, path, "company/secrethub-metadata/app")
+								written = data
+								return &api.SecretVersion{}, nil
+							},
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+									if tc.existing == "" {
+										return nil, api.ErrSecretNotFound
+									}
+									return &api.SecretVersion{Data: []byte(tc.existing)}, nil
+								},
+							},
+						},
+					}, nil
+				},
+			}
+
+			err := cmd.Run()
+			assert.Equal(t, err, tc.err)
+			if tc.err == nil {
+				var metadata repoMetadata
+				err = json.Unmarshal(written, &metadata)
+				assert.OK(t, err)
+				assert.Equal(t, metadata.Labels, tc.expected)
+			}
+		})
+	}
+}
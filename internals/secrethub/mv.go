@@ -0,0 +1,100 @@
+package secrethub
+
+import (
+	"fmt"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// Errors
+var (
+	ErrCannotMoveVersion = errMain.Code("cannot_move_version").Error("cannot move a single secret version. Use the cp command to copy it.")
+	ErrCannotMoveRootDir = errMain.Code("cannot_move_root_dir").Error("cannot move the root directory of a repository. Use the cp command with the -r flag to copy its contents.")
+)
+
+// MvCommand moves secrets, including all their versions, to another location.
+type MvCommand struct {
+	io          ui.IO
+	source      api.Path
+	destination api.Path
+	recursive   bool
+	force       bool
+	newClient   newClientFunc
+}
+
+// NewMvCommand creates a new MvCommand.
+func NewMvCommand(io ui.IO, newClient newClientFunc) *MvCommand {
+	return &MvCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *MvCommand) Register(r command.Registerer) {
+	clause := r.Command("mv", "Move a secret or directory, including all versions of the secrets.")
+	clause.Alias("move")
+	clause.HelpLong("Moves a secret or a directory to another location, which can be in another repository or namespace. " +
+		"The secrets are copied with all their versions in order, after which the source is removed. " +
+		"The source is only removed when all secrets have been copied.\n" +
+		"\n" +
+		"Moving to a secret that already exists requires --force, in which case the moved versions are added as new versions.")
+	clause.Arg("source", "The path to the secret or directory to move.").Required().PlaceHolder(secretPathPlaceHolder).SetValue(&cmd.source)
+	clause.Arg("destination", "The path to move to.").Required().PlaceHolder(secretPathPlaceHolder).SetValue(&cmd.destination)
+	clause.Flag("recursive", "Move directories and their contents recursively.").Short('r').BoolVar(&cmd.recursive)
+	registerForceFlag(clause).BoolVar(&cmd.force)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run moves the secrets and prints a summary of what was moved.
+func (cmd *MvCommand) Run() error {
+	if cmd.source.HasVersion() {
+		return ErrCannotMoveVersion
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	plan, err := planCopy(client, cmd.source, cmd.destination, cmd.recursive, cmd.force)
+	if err != nil {
+		return err
+	}
+
+	if plan.sourceDir != "" {
+		dirPath, err := api.NewDirPath(plan.sourceDir)
+		if err != nil {
+			return err
+		}
+		if dirPath.IsRepoPath() {
+			return ErrCannotMoveRootDir
+		}
+	}
+
+	transfers, err := plan.execute(client, false)
+	if err != nil {
+		return err
+	}
+
+	if plan.sourceDir != "" {
+		err = client.Dirs().Delete(plan.sourceDir)
+	} else {
+		err = client.Secrets().Delete(transfers[0].source)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = printTransfers(cmd.io.Output(), transfers)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "\nMoved %s.\n", pluralize("secret", "secrets", len(transfers)))
+	return nil
+}
@@ -0,0 +1,272 @@
+package secrethub
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/errio"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+)
+
+// Errors
+var (
+	errConvention = errio.Namespace("convention")
+
+	ErrConventionViolation          = errConvention.Code("violation").ErrorPref("the name %s in %s does not match the naming convention of %s: names must match %s")
+	ErrConventionTooDeep            = errConvention.Code("too_deep").ErrorPref("%s is nested too deep: the naming convention of %s allows at most %d levels below the repository")
+	ErrConventionOverrideNotAllowed = errConvention.Code("override_not_allowed").ErrorPref("only admins of %s can ignore its naming convention")
+	ErrCannotFetchConvention        = errConvention.Code("cannot_fetch").ErrorPref("cannot fetch the naming convention of %s: %s")
+	ErrInvalidConvention            = errConvention.Code("invalid").ErrorPref("invalid naming convention: %s")
+)
+
+const (
+	// conventionRepoName is the repository in a namespace that holds the naming convention.
+	// It must be readable by everyone who writes to the namespace.
+	conventionRepoName   = "secrethub-conventions"
+	conventionSecretName = "naming"
+)
+
+// namingConvention restricts the names of the directories and secrets in a namespace.
+// It is stored as JSON in a secret in the namespace, so it applies to every client that writes to the namespace.
+type namingConvention struct {
+	// Pattern is a regular expression that the name of every directory and secret below a repository must match.
+	Pattern string `json:"pattern"`
+	// MaxDepth is the maximum number of levels below a repository, where 1 only allows secrets directly in the repository.
+	// Zero means there is no maximum.
+	MaxDepth int `json:"max_depth,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// conventionPath returns the path of the secret that holds the naming convention of a namespace.
+func conventionPath(namespace string) string {
+	return namespace + "/" + conventionRepoName + "/" + conventionSecretName
+}
+
+// newNamingConvention validates and compiles a naming convention.
+func newNamingConvention(pattern string, maxDepth int) (*namingConvention, error) {
+	if maxDepth < 0 {
+		return nil, ErrInvalidConvention("the maximum depth cannot be negative")
+	}
+
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, ErrInvalidConvention(err)
+	}
+
+	return &namingConvention{
+		Pattern:  pattern,
+		MaxDepth: maxDepth,
+		pattern:  compiled,
+	}, nil
+}
+
+// parseNamingConvention decodes a naming convention stored in a secret.
+func parseNamingConvention(data []byte) (*namingConvention, error) {
+	convention := &namingConvention{}
+	err := json.Unmarshal(data, convention)
+	if err != nil {
+		return nil, ErrInvalidConvention(err)
+	}
+	return newNamingConvention(convention.Pattern, convention.MaxDepth)
+}
+
+// check returns an error when the directory or secret at the given path does not comply with the convention.
+// The names of the namespace and the repository are not checked.
+func (c *namingConvention) check(path string) error {
+	elements := strings.Split(strings.SplitN(path, ":", 2)[0], "/")
+	if len(elements) <= 2 {
+		return nil
+	}
+	namespace, names := elements[0], elements[2:]
+
+	if c.MaxDepth > 0 && len(names) > c.MaxDepth {
+		return ErrConventionTooDeep(path, namespace, c.MaxDepth)
+	}
+
+	for _, name := range names {
+		if !c.pattern.MatchString(name) {
+			return ErrConventionViolation(name, path, namespace, c.Pattern)
+		}
+	}
+	return nil
+}
+
+// String describes the convention, e.g. names match ^[a-z0-9-]+$, at most 5 levels below the repository.
+func (c *namingConvention) String() string {
+	description := "names match " + c.Pattern
+	if c.MaxDepth > 0 {
+		description += fmt.Sprintf(", at most %s below the repository", pluralize("level", "levels", c.MaxDepth))
+	}
+	return description
+}
+
+// getNamingConvention fetches the naming convention of a namespace. It returns nil when the namespace has none.
+func getNamingConvention(client secrethub.ClientInterface, namespace string) (*namingConvention, error) {
+	secret, err := client.Secrets().Versions().GetWithData(conventionPath(namespace))
+	if err == api.ErrSecretNotFound || err == api.ErrRepoNotFound || api.IsErrNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, ErrCannotFetchConvention(namespace, err)
+	}
+	return parseNamingConvention(secret.Data)
+}
+
+// namingConventions enforces the naming conventions of the namespaces that are written to.
+// The conventions are fetched once per namespace.
+type namingConventions struct {
+	client secrethub.ClientInterface
+	// ignore skips the conventions of namespaces that the account is an admin of.
+	ignore bool
+	logger cli.Logger
+
+	mutex       sync.Mutex
+	conventions map[string]*namingConvention
+}
+
+// newNamingConventions creates a namingConventions that fetches the conventions with the given client.
+func newNamingConventions(client secrethub.ClientInterface, ignore bool, logger cli.Logger) *namingConventions {
+	return &namingConventions{
+		client:      client,
+		ignore:      ignore,
+		logger:      logger,
+		conventions: map[string]*namingConvention{},
+	}
+}
+
+// check returns an error when the directory or secret at the given path does not comply
+// with the naming convention of its namespace.
+func (n *namingConventions) check(path string) error {
+	elements := strings.SplitN(path, "/", 3)
+	if len(elements) < 3 || strings.EqualFold(elements[1], conventionRepoName) {
+		return nil
+	}
+	namespace := strings.ToLower(elements[0])
+
+	convention, err := n.get(namespace)
+	if err != nil || convention == nil {
+		return err
+	}
+
+	err = convention.check(path)
+	if err == nil || !n.ignore {
+		return err
+	}
+
+	admin, adminErr := n.isAdmin(namespace)
+	if adminErr != nil {
+		return adminErr
+	}
+	if !admin {
+		return ErrConventionOverrideNotAllowed(namespace)
+	}
+
+	n.logger.With("namespace", namespace, "error", err).Warningf("Ignoring the naming convention")
+	return nil
+}
+
+func (n *namingConventions) get(namespace string) (*namingConvention, error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	convention, ok := n.conventions[namespace]
+	if ok {
+		return convention, nil
+	}
+
+	convention, err := getNamingConvention(n.client, namespace)
+	if err != nil {
+		return nil, err
+	}
+	n.conventions[namespace] = convention
+	return convention, nil
+}
+
+// isAdmin returns whether the account owns the namespace or is an admin of the organization.
+func (n *namingConventions) isAdmin(namespace string) (bool, error) {
+	me, err := n.client.Me().GetUser()
+	if err != nil {
+		return false, err
+	}
+	if strings.EqualFold(me.Username, namespace) {
+		return true, nil
+	}
+
+	members, err := n.client.Orgs().Members().List(namespace)
+	if err != nil {
+		return false, err
+	}
+	for _, member := range members {
+		if member.User != nil && strings.EqualFold(member.User.Username, me.Username) {
+			return member.Role == api.OrgRoleAdmin, nil
+		}
+	}
+	return false, nil
+}
+
+// conventionClient enforces the naming conventions on all writes of secrets and creations of directories.
+type conventionClient struct {
+	secrethub.ClientInterface
+	conventions *namingConventions
+}
+
+// Secrets returns a SecretService that enforces the naming conventions.
+func (c conventionClient) Secrets() secrethub.SecretService {
+	return conventionSecretService{
+		SecretService: c.ClientInterface.Secrets(),
+		conventions:   c.conventions,
+	}
+}
+
+// Dirs returns a DirService that enforces the naming conventions.
+func (c conventionClient) Dirs() secrethub.DirService {
+	return conventionDirService{
+		DirService:  c.ClientInterface.Dirs(),
+		conventions: c.conventions,
+	}
+}
+
+// conventionSecretService checks the naming conventions before secrets are written.
+type conventionSecretService struct {
+	secrethub.SecretService
+	conventions *namingConventions
+}
+
+// Write writes a secret when its path complies with the naming convention.
+func (s conventionSecretService) Write(path string, data []byte) (*api.SecretVersion, error) {
+	err := s.conventions.check(path)
+	if err != nil {
+		return nil, err
+	}
+	return s.SecretService.Write(path, data)
+}
+
+// conventionDirService checks the naming conventions before directories are created.
+type conventionDirService struct {
+	secrethub.DirService
+	conventions *namingConventions
+}
+
+// Create creates a directory when its path complies with the naming convention.
+func (s conventionDirService) Create(path string) (*api.Dir, error) {
+	err := s.conventions.check(path)
+	if err != nil {
+		return nil, err
+	}
+	return s.DirService.Create(path)
+}
+
+// CreateAll creates a directory and its parents when its path complies with the naming convention.
+func (s conventionDirService) CreateAll(path string) error {
+	err := s.conventions.check(path)
+	if err != nil {
+		return err
+	}
+	return s.DirService.CreateAll(path)
+}
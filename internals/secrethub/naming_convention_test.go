@@ -0,0 +1,104 @@
+package secrethub
+
+import (
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestNamingConvention_Check(t *testing.T) {
+	convention, err := newNamingConvention("^[a-z0-9-]+$", 3)
+	assert.OK(t, err)
+
+	cases := map[string]struct {
+		path string
+		err  error
+	}{
+		"repo is not checked": {
+			path: "company/App",
+		},
+		"valid": {
+			path: "company/App/prod/db/password",
+		},
+		"version": {
+			path: "company/app/prod/db/password:3",
+		},
+		"invalid name": {
+			path: "company/app/prod/DB/password",
+			err:  ErrConventionViolation("DB", "company/app/prod/DB/password", "company", "^[a-z0-9-]+$"),
+		},
+		"too deep": {
+			path: "company/app/prod/db/primary/password",
+			err:  ErrConventionTooDeep("company/app/prod/db/primary/password", "company", 3),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, convention.check(tc.path), tc.err)
+		})
+	}
+}
+
+func TestParseNamingConvention(t *testing.T) {
+	convention, err := parseNamingConvention([]byte(`{"pattern":"^[a-z]+$","max_depth":5}`))
+	assert.OK(t, err)
+	assert.Equal(t, convention.String(), "names match ^[a-z]+$, at most 5 levels below the repository")
+
+	_, err = parseNamingConvention([]byte(`{"pattern":"["}`))
+	assert.Equal(t, err == nil, false)
+
+	_, err = newNamingConvention("^[a-z]+$", -1)
+	assert.Equal(t, err == nil, false)
+}
+
+func TestConventionClient(t *testing.T) {
+	fetched := 0
+	var written []string
+	client := conventionClient{
+		ClientInterface: fakeclient.Client{
+			SecretService: &fakeclient.SecretService{
+				WriteFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+					written = append(written, path)
+					return &api.SecretVersion{Version: 1}, nil
+				},
+				VersionService: &fakeclient.SecretVersionService{
+					GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+						fetched++
+						if path == conventionPath("company") {
+							return &api.SecretVersion{Data: []byte(`{"pattern":"^[a-z0-9-]+$"}`)}, nil
+						}
+						return nil, api.ErrSecretNotFound
+					},
+				},
+			},
+			DirService: &fakeclient.DirService{
+				CreateAllFunc: func(path string) error {
+					written = append(written, path)
+					return nil
+				},
+			},
+		},
+	}
+	client.conventions = newNamingConventions(client.ClientInterface, false, cli.NewLogger())
+
+	_, err := client.Secrets().Write("company/app/db_password", []byte("secret"))
+	assert.Equal(t, err, ErrConventionViolation("db_password", "company/app/db_password", "company", "^[a-z0-9-]+$"))
+
+	err = client.Dirs().CreateAll("Company/app/Prod")
+	assert.Equal(t, err, ErrConventionViolation("Prod", "Company/app/Prod", "Company", "^[a-z0-9-]+$"))
+
+	_, err = client.Secrets().Write("company/app/db-password", []byte("secret"))
+	assert.OK(t, err)
+
+	// Namespaces without a convention accept any name.
+	_, err = client.Secrets().Write("other/app/DB_PASSWORD", []byte("secret"))
+	assert.OK(t, err)
+
+	assert.Equal(t, written, []string{"company/app/db-password", "other/app/DB_PASSWORD"})
+	assert.Equal(t, fetched, 2)
+}
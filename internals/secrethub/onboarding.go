@@ -0,0 +1,279 @@
+package secrethub
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/errio"
+	"github.com/secrethub/secrethub-go/pkg/randchar"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+)
+
+// Errors
+var (
+	errOnboarding            = errio.Namespace("onboarding")
+	ErrConnectivityCheckFail = errOnboarding.Code("connectivity_check_failed").ErrorPref("the connectivity check failed: %s")
+)
+
+const (
+	// onboardingSandboxRepo is the repository in the namespace of the user
+	// that is used to check that secrets can be written and read.
+	onboardingSandboxRepo   = "sandbox"
+	onboardingCheckSecret   = "connectivity-check"
+	shellProfileBlockStart  = "# >>> secrethub >>>"
+	shellProfileBlockEnd    = "# <<< secrethub <<<"
+	shellProfileBlockNotice = "# Added by secrethub init. Run secrethub init again to change these settings."
+)
+
+// onboarding guides new users through the configuration of the CLI
+// after their account has been set up on this device.
+type onboarding struct {
+	io         ui.IO
+	client     secrethub.ClientInterface
+	passphrase bool
+	shell      string
+	home       string
+}
+
+// newOnboarding creates an onboarding for the shell and home directory of the current user.
+// The passphrase indicates whether the credential is protected by a passphrase.
+func newOnboarding(io ui.IO, client secrethub.ClientInterface, passphrase bool) *onboarding {
+	home, _ := os.UserHomeDir()
+	return &onboarding{
+		io:         io,
+		client:     client,
+		passphrase: passphrase,
+		shell:      os.Getenv("SHELL"),
+		home:       home,
+	}
+}
+
+// Run asks for the preferences of the user, writes them to the profile of their shell
+// and checks that secrets can be written and read.
+func (o *onboarding) Run() error {
+	fmt.Fprint(o.io.Output(), "Let's configure the CLI for this device.\n\n")
+
+	var settings []string
+	steps := []func() ([]string, error){
+		o.askPassphraseCache,
+		o.askCompletion,
+		o.askPager,
+		o.askClipboard,
+	}
+	for _, step := range steps {
+		lines, err := step()
+		if err != nil {
+			return err
+		}
+		settings = append(settings, lines...)
+		fmt.Fprintln(o.io.Output())
+	}
+
+	err := o.saveSettings(settings)
+	if err != nil {
+		return err
+	}
+
+	me, err := o.client.Me().GetUser()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(o.io.Output(), "Checking that secrets can be written and read...")
+	err = checkConnectivity(o.client, me.Username)
+	if err != nil {
+		fmt.Fprintln(o.io.Output(), " failed.")
+		return err
+	}
+	fmt.Fprintf(o.io.Output(), " done.\nYou can use %s to try out the CLI.\n\n", api.JoinPaths(me.Username, onboardingSandboxRepo))
+	return nil
+}
+
+// askPassphraseCache asks how long the passphrase of the credential is cached in the OS keyring.
+func (o *onboarding) askPassphraseCache() ([]string, error) {
+	if !o.passphrase {
+		return nil, nil
+	}
+
+	ttls := []string{"5m", "1h", "0"}
+	option, err := ui.Choose(o.io, "How long do you want to cache your passphrase in the keyring of your OS?",
+		[]string{
+			"5 minutes (default)",
+			"1 hour",
+			"Do not cache my passphrase",
+		}, 3)
+	if err != nil {
+		return nil, err
+	}
+	if option == 0 {
+		return nil, nil
+	}
+	return []string{shellExport("SECRETHUB_CREDENTIAL_PASSPHRASE_CACHE_TTL", ttls[option])}, nil
+}
+
+// askCompletion asks whether command completion is enabled in the shell of the user.
+func (o *onboarding) askCompletion() ([]string, error) {
+	shell := filepath.Base(o.shell)
+	if shell != "bash" && shell != "zsh" {
+		fmt.Fprintln(o.io.Output(), "Command completion is only available for bash and zsh.")
+		return nil, nil
+	}
+
+	enable, err := ui.AskYesNo(o.io, fmt.Sprintf("Do you want to enable command completion for %s?", shell), ui.DefaultYes)
+	if err != nil || !enable {
+		return nil, err
+	}
+	return []string{fmt.Sprintf(`eval "$(%s --completion-script-%s)"`, ApplicationName, shell)}, nil
+}
+
+// askPager asks for the terminal pager that is used for long output, such as the audit log.
+func (o *onboarding) askPager() ([]string, error) {
+	current := os.Getenv("SECRETHUB_PAGER")
+	if current == "" {
+		current = os.Getenv("PAGER")
+	}
+	if current == "" {
+		current = "less"
+	}
+
+	pager, err := ui.AskWithDefault(o.io, "Which terminal pager do you want to use for long output, such as the audit log?", current)
+	if err != nil || pager == current {
+		return nil, err
+	}
+	return []string{shellExport("SECRETHUB_PAGER", pager)}, nil
+}
+
+// askClipboard asks whether secrethub read copies secrets to the clipboard by default.
+func (o *onboarding) askClipboard() ([]string, error) {
+	clip, err := ui.AskYesNo(o.io, "Do you want `secrethub read` to copy secrets to your clipboard instead of printing them?", ui.DefaultNo)
+	if err != nil || !clip {
+		return nil, err
+	}
+	return []string{shellExport("SECRETHUB_READ_CLIP", "true")}, nil
+}
+
+// saveSettings adds the settings to the profile of the shell of the user when they confirm.
+// Otherwise, the settings are printed, so they can be added manually.
+func (o *onboarding) saveSettings(settings []string) error {
+	if len(settings) == 0 {
+		return nil
+	}
+
+	block := shellProfileBlock(settings)
+	profile := shellProfile(o.shell, o.home)
+	if profile != "" {
+		save, err := ui.AskYesNo(o.io, fmt.Sprintf("Do you want to save your settings to %s?", profile), ui.DefaultYes)
+		if err != nil {
+			return err
+		}
+		if save {
+			content, err := ioutil.ReadFile(profile)
+			if err != nil && !os.IsNotExist(err) {
+				return ErrCannotReadFile(profile, err)
+			}
+
+			err = ioutil.WriteFile(profile, setShellProfileBlock(content, block), 0644)
+			if err != nil {
+				return ErrCannotWrite(profile, err)
+			}
+
+			fmt.Fprintf(o.io.Output(), "Your settings have been saved. They apply to every new shell, or run `source %s` to apply them now.\n\n", profile)
+			return nil
+		}
+	}
+
+	fmt.Fprintf(o.io.Output(), "Add the following lines to the profile of your shell to apply your settings:\n\n%s\n", block)
+	return nil
+}
+
+// checkConnectivity writes a random value to the sandbox repository of the user, reads it back and removes it.
+// The sandbox repository is created when it does not exist yet.
+func checkConnectivity(client secrethub.ClientInterface, username string) error {
+	repoPath := api.JoinPaths(username, onboardingSandboxRepo)
+	_, err := client.Repos().Create(repoPath)
+	if err != nil && err != api.ErrRepoAlreadyExists {
+		return ErrConnectivityCheckFail(err)
+	}
+
+	generator, err := randchar.NewRand(randchar.Alphanumeric)
+	if err != nil {
+		return err
+	}
+	value, err := generator.Generate(32)
+	if err != nil {
+		return err
+	}
+
+	secretPath := api.JoinPaths(repoPath, onboardingCheckSecret)
+	_, err = client.Secrets().Write(secretPath, value)
+	if err != nil {
+		return ErrConnectivityCheckFail(err)
+	}
+
+	secret, err := client.Secrets().Versions().GetWithData(secretPath)
+	if err != nil {
+		return ErrConnectivityCheckFail(err)
+	}
+	if !bytes.Equal(secret.Data, value) {
+		return ErrConnectivityCheckFail("the value that was read is not the value that was written")
+	}
+
+	err = client.Secrets().Delete(secretPath)
+	if err != nil {
+		return ErrConnectivityCheckFail(err)
+	}
+	return nil
+}
+
+// shellProfile returns the profile file of a bash or zsh shell, or an empty string for other shells.
+func shellProfile(shell string, home string) string {
+	if home == "" {
+		return ""
+	}
+	switch filepath.Base(shell) {
+	case "bash":
+		return filepath.Join(home, ".bashrc")
+	case "zsh":
+		return filepath.Join(home, ".zshrc")
+	}
+	return ""
+}
+
+// shellExport returns a shell command that exports an environment variable.
+func shellExport(name, value string) string {
+	return fmt.Sprintf("export %s='%s'", name, strings.Replace(value, "'", `'\''`, -1))
+}
+
+// shellProfileBlock returns the lines surrounded by markers, so they can be replaced later.
+func shellProfileBlock(lines []string) string {
+	return strings.Join(append(append([]string{shellProfileBlockStart, shellProfileBlockNotice}, lines...), shellProfileBlockEnd), "\n") + "\n"
+}
+
+// setShellProfileBlock replaces the block in the content of a profile, or appends it when the profile has no block yet.
+func setShellProfileBlock(content []byte, block string) []byte {
+	profile := string(content)
+	start := strings.Index(profile, shellProfileBlockStart)
+	end := strings.Index(profile, shellProfileBlockEnd)
+	if start >= 0 && end > start {
+		end += len(shellProfileBlockEnd)
+		if end < len(profile) && profile[end] == '\n' {
+			end++
+		}
+		return []byte(profile[:start] + block + profile[end:])
+	}
+
+	if profile != "" && !strings.HasSuffix(profile, "\n") {
+		profile += "\n"
+	}
+	if profile != "" {
+		profile += "\n"
+	}
+	return []byte(profile + block)
+}
@@ -0,0 +1,49 @@
+package secrethub
+
+import (
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestSetShellProfileBlock(t *testing.T) {
+	block := shellProfileBlock([]string{shellExport("SECRETHUB_PAGER", "most")})
+
+	cases := map[string]struct {
+		profile  string
+		expected string
+	}{
+		"empty profile": {
+			profile:  "",
+			expected: block,
+		},
+		"append": {
+			profile:  "alias ll='ls -l'",
+			expected: "alias ll='ls -l'\n\n" + block,
+		},
+		"replace": {
+			profile: "alias ll='ls -l'\n\n" +
+				shellProfileBlockStart + "\nexport SECRETHUB_PAGER='less'\n" + shellProfileBlockEnd + "\n" +
+				"export EDITOR=vim\n",
+			expected: "alias ll='ls -l'\n\n" + block + "export EDITOR=vim\n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, string(setShellProfileBlock([]byte(tc.profile), block)), tc.expected)
+		})
+	}
+}
+
+func TestShellProfile(t *testing.T) {
+	assert.Equal(t, shellProfile("/bin/bash", "/home/dev"), "/home/dev/.bashrc")
+	assert.Equal(t, shellProfile("/usr/bin/zsh", "/home/dev"), "/home/dev/.zshrc")
+	assert.Equal(t, shellProfile("/usr/bin/fish", "/home/dev"), "")
+	assert.Equal(t, shellProfile("/bin/bash", ""), "")
+}
+
+func TestShellExport(t *testing.T) {
+	assert.Equal(t, shellExport("SECRETHUB_PAGER", "less -R"), "export SECRETHUB_PAGER='less -R'")
+	assert.Equal(t, shellExport("SECRETHUB_PAGER", "it's"), `export SECRETHUB_PAGER='it'\''s'`)
+}
@@ -27,6 +27,7 @@ func (cmd *OrgCommand) Register(r command.Registerer) {
 	clause.Alias("orgs")
 	clause.Alias("organizations")
 	clause.Alias("organisations")
+	NewOrgGroupCommand(cmd.io, cmd.newClient).Register(clause)
 	NewOrgInitCommand(cmd.io, cmd.newClient).Register(clause)
 	NewOrgInspectCommand(cmd.io, cmd.newClient).Register(clause)
 	NewOrgInviteCommand(cmd.io, cmd.newClient).Register(clause)
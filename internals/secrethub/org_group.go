@@ -0,0 +1,226 @@
+package secrethub
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/errio"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+)
+
+// Errors
+var (
+	errOrgGroup            = errio.Namespace("org_group")
+	ErrInvalidGroupName    = errOrgGroup.Code("invalid_name").ErrorPref("invalid group name %q: group names can only contain letters, digits, dashes and underscores")
+	ErrGroupAlreadyExists  = errOrgGroup.Code("already_exists").ErrorPref("the organization %s already has a group %s")
+	ErrGroupNotFound       = errOrgGroup.Code("not_found").ErrorPref("the organization %s has no group %s")
+	ErrAlreadyGroupMember  = errOrgGroup.Code("already_member").ErrorPref("%s is already a member of the group %s")
+	ErrNotGroupMember      = errOrgGroup.Code("not_member").ErrorPref("%s is not a member of the group %s")
+	ErrGroupRuleNotFound   = errOrgGroup.Code("rule_not_found").ErrorPref("the group %s has no access rule on %s")
+	ErrInvalidGroupsConfig = errOrgGroup.Code("invalid_groups").ErrorPref("the groups of %s are invalid: %s")
+	ErrUntrustedGroups     = errOrgGroup.Code("untrusted_groups").ErrorPref("the groups of %s cannot be trusted: %s can write to the %s repository, but is not an admin of the organization: remove its access rule with `" + ApplicationName + " acl rm %s %s`")
+)
+
+const (
+	// orgGroupsRepoName is the repository of an organization that holds its groups.
+	// It is separate from the metadata repository, so that only admins of the organization can write to it.
+	orgGroupsRepoName = "secrethub-groups"
+	// orgGroupsSecretName is the secret in the groups repository that holds the groups.
+	orgGroupsSecretName = "groups"
+)
+
+// OrgGroupCommand handles the groups of an organization.
+type OrgGroupCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+}
+
+// NewOrgGroupCommand creates a new OrgGroupCommand.
+func NewOrgGroupCommand(io ui.IO, newClient newClientFunc) *OrgGroupCommand {
+	return &OrgGroupCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command and its sub-commands on the provided Registerer.
+func (cmd *OrgGroupCommand) Register(r command.Registerer) {
+	clause := r.Command("group", "Manage groups of users in an organization, e.g. per team.")
+	clause.Alias("groups")
+	clause.HelpLong("A group gives all its members the same access rules. " +
+		"Access rules are set for a group with `" + ApplicationName + " acl set --group`, " +
+		"which sets the access rule for every member of the group. " +
+		"Members that are added to the group later get the access rules of the group, " +
+		"and the access rules of the group are removed for members that are removed from it.\n" +
+		"\n" +
+		"Groups are stored in the " + orgGroupsRepoName + " repository of the organization. " +
+		"Only admins of the organization may be able to write to it: " +
+		"groups are not used when anyone else has write or admin access to it, because they could give themselves access through a group.\n" +
+		"\n" +
+		"Groups that were created by earlier versions were stored in the " + metadataRepoName + " repository and are ignored. " +
+		"The access rules that were set through them stay in place, but are no longer managed by a group. " +
+		"Recreate the groups with `" + ApplicationName + " org group create` and `" + ApplicationName + " org group add-member`, " +
+		"or remove the access rules with `" + ApplicationName + " acl rm`.")
+	NewOrgGroupCreateCommand(cmd.io, cmd.newClient).Register(clause)
+	NewOrgGroupListCommand(cmd.io, cmd.newClient).Register(clause)
+	NewOrgGroupAddMemberCommand(cmd.io, cmd.newClient).Register(clause)
+	NewOrgGroupRemoveMemberCommand(cmd.io, cmd.newClient).Register(clause)
+}
+
+// orgGroups holds the groups of an organization.
+type orgGroups struct {
+	// Groups maps the names of the groups to the groups.
+	Groups map[string]*orgGroup `json:"groups"`
+}
+
+// orgGroup is a group of users in an organization.
+type orgGroup struct {
+	Members []string `json:"members"`
+	// Rules are the access rules that are set for every member of the group.
+	Rules []orgGroupRule `json:"rules,omitempty"`
+}
+
+// orgGroupRule is an access rule of a group.
+type orgGroupRule struct {
+	Path       string `json:"path"`
+	Permission string `json:"permission"`
+}
+
+// orgGroupsPath returns the path of the secret that holds the groups of the organization.
+func orgGroupsPath(org string) string {
+	return api.JoinPaths(org, orgGroupsRepoName, orgGroupsSecretName)
+}
+
+// readOrgGroups reads the groups of the organization. It returns no groups when the organization has none.
+// An error is returned when accounts other than the admins of the organization can write the groups.
+func readOrgGroups(client secrethub.ClientInterface, org string) (*orgGroups, error) {
+	groups := &orgGroups{Groups: map[string]*orgGroup{}}
+
+	// The writers are checked before the groups are read, so that
+	// groups that cannot be trusted are never used.
+	err := checkOrgGroupsWriters(client, org)
+	if api.IsErrNotFound(err) {
+		return groups, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	secret, err := client.Secrets().Versions().GetWithData(orgGroupsPath(org))
+	if api.IsErrNotFound(err) {
+		return groups, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(secret.Data, groups)
+	if err != nil {
+		return nil, ErrInvalidGroupsConfig(org, err)
+	}
+	if groups.Groups == nil {
+		groups.Groups = map[string]*orgGroup{}
+	}
+	return groups, nil
+}
+
+// writeOrgGroups writes the groups of the organization, creating the groups repository when it does not exist yet.
+func writeOrgGroups(client secrethub.ClientInterface, org string, groups *orgGroups) error {
+	data, err := json.MarshalIndent(groups, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Repos().Create(api.JoinPaths(org, orgGroupsRepoName))
+	if err != nil && err != api.ErrRepoAlreadyExists {
+		return err
+	}
+
+	_, err = client.Secrets().Write(orgGroupsPath(org), data)
+	return err
+}
+
+// checkOrgGroupsWriters returns an error when an account that is not an admin of the organization
+// has write or admin access to the groups repository or any directory in it.
+func checkOrgGroupsWriters(client secrethub.ClientInterface, org string) error {
+	repo := api.JoinPaths(org, orgGroupsRepoName)
+	rules, err := client.AccessRules().List(repo, -1, false)
+	if err != nil {
+		return err
+	}
+
+	members, err := client.Orgs().Members().List(org)
+	if err != nil {
+		return err
+	}
+	admins := map[string]bool{}
+	for _, member := range members {
+		if member.User != nil && member.Role == api.OrgRoleAdmin {
+			admins[strings.ToLower(member.User.Username)] = true
+		}
+	}
+
+	for _, rule := range rules {
+		account := rule.Account.Name.Value()
+		if rule.Permission > api.PermissionRead && !admins[strings.ToLower(account)] {
+			return ErrUntrustedGroups(org, account, repo, repo, account)
+		}
+	}
+	return nil
+}
+
+// get returns the group with the given name.
+func (g *orgGroups) get(org, name string) (*orgGroup, error) {
+	group, ok := g.Groups[name]
+	if !ok {
+		return nil, ErrGroupNotFound(org, name)
+	}
+	return group, nil
+}
+
+// validateGroupName checks that the name can be used for a group.
+func validateGroupName(name string) error {
+	if name == "" || len(name) > 32 {
+		return ErrInvalidGroupName(name)
+	}
+	for _, r := range name {
+		if !isJSONQueryIdentifierChar(r) {
+			return ErrInvalidGroupName(name)
+		}
+	}
+	return nil
+}
+
+// memberIndex returns the index of the user in the members of the group, or -1 when the user is not a member.
+func (g *orgGroup) memberIndex(username string) int {
+	for i, member := range g.Members {
+		if strings.EqualFold(member, username) {
+			return i
+		}
+	}
+	return -1
+}
+
+// setRule sets the permission of the group on the directory, replacing an existing rule on the directory.
+func (g *orgGroup) setRule(path, permission string) {
+	for i, rule := range g.Rules {
+		if strings.EqualFold(rule.Path, path) {
+			g.Rules[i].Permission = permission
+			return
+		}
+	}
+	g.Rules = append(g.Rules, orgGroupRule{Path: path, Permission: permission})
+}
+
+// removeRule removes the rule of the group on the directory and returns whether the group had one.
+func (g *orgGroup) removeRule(path string) bool {
+	for i, rule := range g.Rules {
+		if strings.EqualFold(rule.Path, path) {
+			g.Rules = append(g.Rules[:i], g.Rules[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package secrethub
+
+import (
+	"fmt"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// OrgGroupAddMemberCommand adds a user to a group and gives them the access rules of the group.
+type OrgGroupAddMemberCommand struct {
+	orgName   api.OrgName
+	group     string
+	username  string
+	io        ui.IO
+	newClient newClientFunc
+}
+
+// NewOrgGroupAddMemberCommand creates a new OrgGroupAddMemberCommand.
+func NewOrgGroupAddMemberCommand(io ui.IO, newClient newClientFunc) *OrgGroupAddMemberCommand {
+	return &OrgGroupAddMemberCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *OrgGroupAddMemberCommand) Register(r command.Registerer) {
+	clause := r.Command("add-member", "Add a member of an organization to a group and set the access rules of the group for them.")
+	clause.Arg("org-name", "The organization name").Required().SetValue(&cmd.orgName)
+	clause.Arg("group-name", "The name of the group").Required().StringVar(&cmd.group)
+	clause.Arg("username", "The username of the user to add").Required().StringVar(&cmd.username)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run adds the user to the group.
+func (cmd *OrgGroupAddMemberCommand) Run() error {
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	groups, err := readOrgGroups(client, cmd.orgName.Value())
+	if err != nil {
+		return err
+	}
+
+	group, err := groups.get(cmd.orgName.Value(), cmd.group)
+	if err != nil {
+		return err
+	}
+
+	if group.memberIndex(cmd.username) != -1 {
+		return ErrAlreadyGroupMember(cmd.username, cmd.group)
+	}
+
+	for _, rule := range group.Rules {
+		_, err = client.AccessRules().Set(rule.Path, rule.Permission, cmd.username)
+		if err != nil {
+			return err
+		}
+	}
+
+	group.Members = append(group.Members, cmd.username)
+	err = writeOrgGroups(client, cmd.orgName.Value(), groups)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Added %s to the group %s and set %s for them.\n",
+		cmd.username, cmd.group, pluralize("access rule", "access rules", len(group.Rules)))
+	return nil
+}
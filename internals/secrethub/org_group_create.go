@@ -0,0 +1,67 @@
+package secrethub
+
+import (
+	"fmt"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// OrgGroupCreateCommand creates a group in an organization.
+type OrgGroupCreateCommand struct {
+	orgName   api.OrgName
+	name      string
+	io        ui.IO
+	newClient newClientFunc
+}
+
+// NewOrgGroupCreateCommand creates a new OrgGroupCreateCommand.
+func NewOrgGroupCreateCommand(io ui.IO, newClient newClientFunc) *OrgGroupCreateCommand {
+	return &OrgGroupCreateCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *OrgGroupCreateCommand) Register(r command.Registerer) {
+	clause := r.Command("create", "Create a group in an organization.")
+	clause.Arg("org-name", "The organization name").Required().SetValue(&cmd.orgName)
+	clause.Arg("group-name", "The name of the group").Required().StringVar(&cmd.name)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run creates the group.
+func (cmd *OrgGroupCreateCommand) Run() error {
+	err := validateGroupName(cmd.name)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	groups, err := readOrgGroups(client, cmd.orgName.Value())
+	if err != nil {
+		return err
+	}
+
+	if _, exists := groups.Groups[cmd.name]; exists {
+		return ErrGroupAlreadyExists(cmd.orgName, cmd.name)
+	}
+	groups.Groups[cmd.name] = &orgGroup{Members: []string{}}
+
+	err = writeOrgGroups(client, cmd.orgName.Value(), groups)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Created group %s in %s.\n", cmd.name, cmd.orgName)
+	fmt.Fprintf(cmd.io.Output(), "Add members with `%s org group add-member %s %s <username>`.\n", ApplicationName, cmd.orgName, cmd.name)
+	return nil
+}
@@ -0,0 +1,74 @@
+package secrethub
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// OrgGroupListCommand lists the groups of an organization.
+type OrgGroupListCommand struct {
+	orgName   api.OrgName
+	io        ui.IO
+	newClient newClientFunc
+}
+
+// NewOrgGroupListCommand creates a new OrgGroupListCommand.
+func NewOrgGroupListCommand(io ui.IO, newClient newClientFunc) *OrgGroupListCommand {
+	return &OrgGroupListCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *OrgGroupListCommand) Register(r command.Registerer) {
+	clause := r.Command("ls", "List the groups of an organization with their members and access rules.")
+	clause.Alias("list")
+	clause.Arg("org-name", "The organization name").Required().SetValue(&cmd.orgName)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run prints the groups.
+func (cmd *OrgGroupListCommand) Run() error {
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	groups, err := readOrgGroups(client, cmd.orgName.Value())
+	if err != nil {
+		return err
+	}
+
+	return printOrgGroups(cmd.io.Output(), groups)
+}
+
+// printOrgGroups prints a table of the groups, sorted by name.
+func printOrgGroups(w io.Writer, groups *orgGroups) error {
+	names := make([]string, 0, len(groups.Groups))
+	for name := range groups.Groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(tw, "GROUP\tMEMBERS\tACCESS RULES")
+	for _, name := range names {
+		group := groups.Groups[name]
+		rules := make([]string, len(group.Rules))
+		for i, rule := range group.Rules {
+			rules[i] = rule.Permission + " on " + rule.Path
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", name, strings.Join(group.Members, ", "), strings.Join(rules, ", "))
+	}
+	return tw.Flush()
+}
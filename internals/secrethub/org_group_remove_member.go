@@ -0,0 +1,98 @@
+package secrethub
+
+import (
+	"fmt"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// OrgGroupRemoveMemberCommand removes a user from a group and removes the access rules of the group for them.
+type OrgGroupRemoveMemberCommand struct {
+	orgName   api.OrgName
+	group     string
+	username  string
+	force     bool
+	io        ui.IO
+	newClient newClientFunc
+}
+
+// NewOrgGroupRemoveMemberCommand creates a new OrgGroupRemoveMemberCommand.
+func NewOrgGroupRemoveMemberCommand(io ui.IO, newClient newClientFunc) *OrgGroupRemoveMemberCommand {
+	return &OrgGroupRemoveMemberCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *OrgGroupRemoveMemberCommand) Register(r command.Registerer) {
+	clause := r.Command("remove-member", "Remove a user from a group and remove the access rules of the group for them.")
+	clause.HelpLong("The access rules of the group are removed for the user, " +
+		"including access rules on the same directories that were set for the user directly. " +
+		"Note that this does not trigger secret rotation.")
+	clause.Arg("org-name", "The organization name").Required().SetValue(&cmd.orgName)
+	clause.Arg("group-name", "The name of the group").Required().StringVar(&cmd.group)
+	clause.Arg("username", "The username of the user to remove").Required().StringVar(&cmd.username)
+	registerForceFlag(clause).BoolVar(&cmd.force)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run removes the user from the group.
+func (cmd *OrgGroupRemoveMemberCommand) Run() error {
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	groups, err := readOrgGroups(client, cmd.orgName.Value())
+	if err != nil {
+		return err
+	}
+
+	group, err := groups.get(cmd.orgName.Value(), cmd.group)
+	if err != nil {
+		return err
+	}
+
+	i := group.memberIndex(cmd.username)
+	if i == -1 {
+		return ErrNotGroupMember(cmd.username, cmd.group)
+	}
+
+	if !cmd.force && len(group.Rules) > 0 {
+		msg := fmt.Sprintf("[WARNING] This removes %s of %s. Are you sure you want to remove %s from %s?",
+			pluralize("access rule", "access rules", len(group.Rules)), cmd.username, cmd.username, cmd.group)
+		confirmed, err := ui.AskYesNo(cmd.io, msg, ui.DefaultNo)
+		if err == ui.ErrCannotAsk {
+			return ErrCannotDoWithoutForce
+		} else if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			fmt.Fprintln(cmd.io.Output(), "Aborting.")
+			return nil
+		}
+	}
+
+	for _, rule := range group.Rules {
+		err = client.AccessRules().Delete(rule.Path, cmd.username)
+		if err != nil && err != api.ErrAccessRuleNotFound {
+			return err
+		}
+	}
+
+	group.Members = append(group.Members[:i], group.Members[i+1:]...)
+	err = writeOrgGroups(client, cmd.orgName.Value(), groups)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Removed %s from the group %s and removed %s of them.\n",
+		cmd.username, cmd.group, pluralize("access rule", "access rules", len(group.Rules)))
+	return nil
+}
@@ -0,0 +1,205 @@
+package secrethub
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestValidateGroupName(t *testing.T) {
+	cases := map[string]struct {
+		name string
+		err  error
+	}{
+		"valid": {
+			name: "backend-team_1",
+		},
+		"empty": {
+			name: "",
+			err:  ErrInvalidGroupName(""),
+		},
+		"space": {
+			name: "backend team",
+			err:  ErrInvalidGroupName("backend team"),
+		},
+		"too long": {
+			name: "a-group-name-that-is-way-too-long",
+			err:  ErrInvalidGroupName("a-group-name-that-is-way-too-long"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			err := validateGroupName(tc.name)
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+		})
+	}
+}
+
+func TestOrgGroup_Rules(t *testing.T) {
+	// Setup
+	group := &orgGroup{}
+
+	// Act
+	group.setRule("company/app", "read")
+	group.setRule("company/app/prod", "read")
+	group.setRule("company/App", "write")
+	removed := group.removeRule("company/app/prod")
+	removedMissing := group.removeRule("company/app/staging")
+
+	// Assert
+	assert.Equal(t, group.Rules, []orgGroupRule{{Path: "company/app", Permission: "write"}})
+	assert.Equal(t, removed, true)
+	assert.Equal(t, removedMissing, false)
+}
+
+func TestPrintOrgGroups(t *testing.T) {
+	// Arrange
+	groups := &orgGroups{
+		Groups: map[string]*orgGroup{
+			"ops": {Members: []string{}},
+			"backend": {
+				Members: []string{"dev1", "dev2"},
+				Rules: []orgGroupRule{
+					{Path: "company/app", Permission: "read"},
+					{Path: "company/app/prod", Permission: "write"},
+				},
+			},
+		},
+	}
+	buf := bytes.Buffer{}
+
+	// Act
+	err := printOrgGroups(&buf, groups)
+
+	// Assert
+	assert.OK(t, err)
+	assert.Equal(t, buf.String(), ""+
+		"GROUP    MEMBERS     ACCESS RULES\n"+
+		"backend  dev1, dev2  read on company/app, write on company/app/prod\n"+
+		"ops                  \n",
+	)
+}
+
+func TestOrgGroupAddMemberCommand_Run(t *testing.T) {
+	existing := `{"groups":{"backend":{"members":["dev1"],"rules":[{"path":"company/app","permission":"read"}]}}}`
+
+	cases := map[string]struct {
+		username string
+		rules    []*api.AccessRule
+		set      []string
+		members  []string
+		out      string
+		read     bool
+		err      error
+	}{
+		"success": {
+			username: "dev2",
+			rules: []*api.AccessRule{
+				{Account: &api.Account{Name: "admin1"}, Permission: api.PermissionAdmin},
+				{Account: &api.Account{Name: "dev1"}, Permission: api.PermissionRead},
+			},
+			set:     []string{"company/app dev2 read"},
+			members: []string{"dev1", "dev2"},
+			out:     "Added dev2 to the group backend and set 1 access rule for them.\n",
+			read:    true,
+		},
+		"already member": {
+			username: "Dev1",
+			read:     true,
+			err:      ErrAlreadyGroupMember("Dev1", "backend"),
+		},
+		"writable by member": {
+			username: "dev2",
+			rules: []*api.AccessRule{
+				{Account: &api.Account{Name: "admin1"}, Permission: api.PermissionAdmin},
+				{Account: &api.Account{Name: "dev1"}, Permission: api.PermissionWrite},
+			},
+			err: ErrUntrustedGroups("company", "dev1", "company/secrethub-groups", "company/secrethub-groups", "dev1"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			var set []string
+			var written []byte
+			var read bool
+			io := fakeui.NewIO(t)
+			cmd := OrgGroupAddMemberCommand{
+				io:       io,
+				orgName:  "company",
+				group:    "backend",
+				username: tc.username,
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						AccessRuleService: &fakeclient.AccessRuleService{
+							ListFunc: func(path string, depth int, ancestors bool) ([]*api.AccessRule, error) {
+								assert.Equal(t, path, "company/secrethub-groups")
+								assert.Equal(t, depth, -1)
+								return tc.rules, nil
+							},
+							SetFunc: func(path string, permission string, accountName string) (*api.AccessRule, error) {
+								set = append(set, path+" "+accountName+" "+permission)
+								return nil, nil
+							},
+						},
+						OrgService: &fakeclient.OrgService{
+							MembersService: &fakeclient.OrgMemberService{
+								ListFunc: func(org string) ([]*api.OrgMember, error) {
+									return []*api.OrgMember{
+										{Role: api.OrgRoleAdmin, User: &api.User{Username: "Admin1"}},
+										{Role: api.OrgRoleMember, User: &api.User{Username: "dev1"}},
+									}, nil
+								},
+							},
+						},
+						RepoService: &fakeclient.RepoService{
+							CreateFunc: func(path string) (*api.Repo, error) {
+								return nil, api.ErrRepoAlreadyExists
+							},
+						},
+						SecretService: &fakeclient.SecretService{
+							WriteFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+								assert.Equal(t, path, "company/secrethub-groups/groups")
+								written = data
+								return &api.SecretVersion{}, nil
+							},
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+									read = true
+									return &api.SecretVersion{Data: []byte(existing)}, nil
+								},
+							},
+						},
+					}, nil
+				},
+			}
+
+			// Act
+			err := cmd.Run()
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.Out.String(), tc.out)
+			assert.Equal(t, set, tc.set)
+			assert.Equal(t, read, tc.read)
+			if tc.err == nil {
+				var groups orgGroups
+				err = json.Unmarshal(written, &groups)
+				assert.OK(t, err)
+				assert.Equal(t, groups.Groups["backend"].Members, tc.members)
+			}
+		})
+	}
+}
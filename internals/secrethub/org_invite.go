@@ -11,12 +11,15 @@ import (
 
 // OrgInviteCommand handles inviting a user to an organization.
 type OrgInviteCommand struct {
-	orgName   api.OrgName
-	username  string
-	role      string
-	force     bool
-	io        ui.IO
-	newClient newClientFunc
+	orgName      api.OrgName
+	username     string
+	role         string
+	csvFile      string
+	progressFile string
+	permission   api.Permission
+	force        bool
+	io           ui.IO
+	newClient    newClientFunc
 }
 
 // NewOrgInviteCommand creates a new OrgInviteCommand.
@@ -31,8 +34,20 @@ func NewOrgInviteCommand(io ui.IO, newClient newClientFunc) *OrgInviteCommand {
 func (cmd *OrgInviteCommand) Register(r command.Registerer) {
 	clause := r.Command("invite", "Invite a user to join an organization.")
 	clause.Arg("org-name", "The organization name").Required().SetValue(&cmd.orgName)
-	clause.Arg("username", "The username of the user to invite").Required().StringVar(&cmd.username)
+	clause.HelpLong("To invite many users at once, pass a CSV file with --from-csv instead of a username. " +
+		"The file has a header row with a username column and optional role, repos and permission columns, e.g.:\n\n" +
+		"    username,role,repos,permission\n" +
+		"    alice,admin,,\n" +
+		"    bob,member,app;website,write\n\n" +
+		"Users are given the permission on the root directory of each of their repos, which are separated by semicolons. " +
+		"An empty role or permission defaults to --role or --permission.\n\n" +
+		"When some invites fail, the others are still made and the steps that succeeded are saved in a progress file. " +
+		"Running the same command again retries the failed invites only.")
+	clause.Arg("username", "The username of the user to invite").StringVar(&cmd.username)
 	clause.Flag("role", "Assign a role to the invited member. This can be either `admin` or `member`. It defaults to `member`.").Default("member").StringVar(&cmd.role)
+	clause.Flag("from-csv", "Invite the users in a CSV file.").ExistingFileVar(&cmd.csvFile)
+	clause.Flag("permission", "The default permission of users from the CSV file on their repos. Options are: read, write and admin.").Default("read").HintOptions("read", "write", "admin").SetValue(&cmd.permission)
+	clause.Flag("progress-file", "The file to save the progress of a CSV invite in. Defaults to the path of the CSV file with .progress appended.").StringVar(&cmd.progressFile)
 	registerForceFlag(clause).BoolVar(&cmd.force)
 
 	command.BindAction(clause, cmd.Run)
@@ -40,6 +55,13 @@ func (cmd *OrgInviteCommand) Register(r command.Registerer) {
 
 // Run invites a user to an organization and gives them a certain role.
 func (cmd *OrgInviteCommand) Run() error {
+	if (cmd.username == "") == (cmd.csvFile == "") {
+		return ErrInviteUsernameOrCSV
+	}
+	if cmd.csvFile != "" {
+		return cmd.runCSV()
+	}
+
 	if !cmd.force {
 		msg := fmt.Sprintf("Are you sure you want to invite %s to the %s organization?",
 			cmd.username,
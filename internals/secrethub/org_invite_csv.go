@@ -0,0 +1,271 @@
+package secrethub
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+)
+
+// Errors
+var (
+	ErrInviteUsernameOrCSV = errMain.Code("invite_username_or_csv").Error("provide either a username or --from-csv")
+	ErrInvalidInviteCSV    = errMain.Code("invalid_invite_csv").ErrorPref("invalid members file %s: %s")
+	ErrInvitesFailed       = errMain.Code("invites_failed").ErrorPref("%s failed: run the same command again to retry, invites that succeeded are skipped")
+)
+
+// inviteRow is a user to invite, read from a members file.
+type inviteRow struct {
+	username   string
+	role       string
+	repos      []string
+	permission api.Permission
+}
+
+// readInviteCSV reads the users to invite from a CSV file with a header row.
+// The username column is required. The role, repos and permission columns are
+// optional and default to the given role and permission. Repos are separated by semicolons.
+func readInviteCSV(r io.Reader, defaultRole string, defaultPermission api.Permission) ([]inviteRow, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("the file is empty")
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "username", "role", "repos", "permission":
+			columns[name] = i
+		default:
+			return nil, fmt.Errorf("unknown column %q: the columns are username, role, repos and permission", name)
+		}
+	}
+	if _, ok := columns["username"]; !ok {
+		return nil, fmt.Errorf("the username column is missing")
+	}
+
+	get := func(record []string, column string) string {
+		i, ok := columns[column]
+		if !ok {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	rows := make([]inviteRow, 0, len(records)-1)
+	for i, record := range records[1:] {
+		line := i + 2
+		row := inviteRow{
+			username:   get(record, "username"),
+			role:       defaultRole,
+			permission: defaultPermission,
+		}
+		if row.username == "" {
+			return nil, fmt.Errorf("line %d has no username", line)
+		}
+
+		if role := get(record, "role"); role != "" {
+			row.role = role
+		}
+		if row.role != api.OrgRoleAdmin && row.role != api.OrgRoleMember {
+			return nil, fmt.Errorf("line %d has an invalid role %q: the roles are admin and member", line, row.role)
+		}
+
+		if permission := get(record, "permission"); permission != "" {
+			err = row.permission.Set(permission)
+			if err != nil {
+				return nil, fmt.Errorf("line %d has an invalid permission %q: %s", line, permission, err)
+			}
+		}
+
+		for _, repo := range strings.Split(get(record, "repos"), ";") {
+			repo = strings.TrimSpace(repo)
+			if repo != "" {
+				row.repos = append(row.repos, repo)
+			}
+		}
+
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// inviteProgress keeps track of the steps of a bulk invite that succeeded, so
+// that a run with failures can be retried without repeating them.
+type inviteProgress struct {
+	file string
+	done map[string]bool
+}
+
+// loadInviteProgress reads the steps that succeeded in previous runs from the file.
+func loadInviteProgress(file string) (*inviteProgress, error) {
+	progress := &inviteProgress{file: file, done: map[string]bool{}}
+
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return progress, nil
+	} else if err != nil {
+		return nil, ErrCannotReadFile(file, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		progress.done[scanner.Text()] = true
+	}
+	err = scanner.Err()
+	if err != nil {
+		return nil, ErrCannotReadFile(file, err)
+	}
+	return progress, nil
+}
+
+// has returns whether the step succeeded before.
+func (p *inviteProgress) has(step string) bool {
+	return p.done[step]
+}
+
+// inviteStep returns the progress step of inviting the user to the organization.
+// The organization is part of the step, so a progress file cannot skip invites to another organization.
+func inviteStep(org string, username string) string {
+	return "invite " + org + " " + username
+}
+
+// accessStep returns the progress step of giving the user of the organization access to the path.
+func accessStep(org string, username string, path string) string {
+	return "access " + org + " " + username + " " + path
+}
+
+// record appends the step to the progress file.
+func (p *inviteProgress) record(step string) error {
+	f, err := os.OpenFile(p.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return ErrCannotWrite(p.file, err)
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintln(f, step)
+	if err != nil {
+		return ErrCannotWrite(p.file, err)
+	}
+	p.done[step] = true
+	return nil
+}
+
+// runCSV invites the users in the members file and gives them access to their repositories.
+func (cmd *OrgInviteCommand) runCSV() error {
+	f, err := os.Open(cmd.csvFile)
+	if err != nil {
+		return ErrCannotReadFile(cmd.csvFile, err)
+	}
+	defer f.Close()
+
+	rows, err := readInviteCSV(f, cmd.role, cmd.permission)
+	if err != nil {
+		return ErrInvalidInviteCSV(cmd.csvFile, err)
+	}
+
+	progressFile := cmd.progressFile
+	if progressFile == "" {
+		progressFile = cmd.csvFile + ".progress"
+	}
+	progress, err := loadInviteProgress(progressFile)
+	if err != nil {
+		return err
+	}
+
+	if !cmd.force {
+		msg := fmt.Sprintf("Are you sure you want to invite %s to the %s organization?",
+			pluralize("user", "users", len(rows)),
+			cmd.orgName)
+
+		confirmed, err := ui.AskYesNo(cmd.io, msg, ui.DefaultNo)
+		if err == ui.ErrCannotAsk {
+			return ErrCannotDoWithoutForce
+		} else if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			fmt.Fprintln(cmd.io.Output(), "Aborting.")
+			return nil
+		}
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, row := range rows {
+		err = cmd.inviteRow(client, row, progress)
+		if err != nil {
+			fmt.Fprintf(cmd.io.Output(), "Failed to invite %s: %s\n", row.username, err)
+			failed++
+		}
+	}
+
+	fmt.Fprintln(cmd.io.Output())
+	if failed > 0 {
+		fmt.Fprintf(cmd.io.Output(), "Invited %d of %s. The progress is saved in %s.\n", len(rows)-failed, pluralize("user", "users", len(rows)), progressFile)
+		return ErrInvitesFailed(pluralize("invite", "invites", failed))
+	}
+
+	err = os.Remove(progressFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Invite complete! Invited %s to the %s organization.\n", pluralize("user", "users", len(rows)), cmd.orgName)
+	return nil
+}
+
+// inviteRow invites the user of the row and gives them access to the repositories,
+// skipping the steps that succeeded before.
+func (cmd *OrgInviteCommand) inviteRow(client secrethub.ClientInterface, row inviteRow, progress *inviteProgress) error {
+	step := inviteStep(cmd.orgName.Value(), row.username)
+	if progress.has(step) {
+		fmt.Fprintf(cmd.io.Output(), "Skipped inviting %s: invited in a previous run.\n", row.username)
+	} else {
+		_, err := client.Orgs().Members().Invite(cmd.orgName.Value(), row.username, row.role)
+		if err != nil {
+			return err
+		}
+		err = progress.record(step)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.io.Output(), "Invited %s as %s.\n", row.username, row.role)
+	}
+
+	for _, repo := range row.repos {
+		path := api.JoinPaths(cmd.orgName.Value(), repo)
+		step := accessStep(cmd.orgName.Value(), row.username, path)
+		if progress.has(step) {
+			continue
+		}
+
+		_, err := client.AccessRules().Set(path, row.permission.String(), row.username)
+		if err != nil {
+			return err
+		}
+		err = progress.record(step)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.io.Output(), "Gave %s %s access to %s.\n", row.username, row.permission, path)
+	}
+	return nil
+}
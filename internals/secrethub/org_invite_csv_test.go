@@ -0,0 +1,188 @@
+package secrethub
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestReadInviteCSV(t *testing.T) {
+	cases := map[string]struct {
+		in       string
+		expected []inviteRow
+		err      error
+	}{
+		"usernames only": {
+			in: "username\ndev1\ndev2\n",
+			expected: []inviteRow{
+				{username: "dev1", role: "member", permission: api.PermissionRead},
+				{username: "dev2", role: "member", permission: api.PermissionRead},
+			},
+		},
+		"all columns": {
+			in: "" +
+				"username,role,repos,permission\n" +
+				"dev1,admin,,\n" +
+				"dev2,,app; website,write\n",
+			expected: []inviteRow{
+				{username: "dev1", role: "admin", permission: api.PermissionRead},
+				{username: "dev2", role: "member", repos: []string{"app", "website"}, permission: api.PermissionWrite},
+			},
+		},
+		"missing username column": {
+			in:  "role\nadmin\n",
+			err: errors.New("the username column is missing"),
+		},
+		"unknown column": {
+			in:  "username,email\ndev1,dev1@example.com\n",
+			err: errors.New("unknown column \"email\": the columns are username, role, repos and permission"),
+		},
+		"invalid role": {
+			in:  "username,role\ndev1,owner\n",
+			err: errors.New("line 2 has an invalid role \"owner\": the roles are admin and member"),
+		},
+		"empty username": {
+			in:  "username,role\ndev1,admin\n,member\n",
+			err: errors.New("line 3 has no username"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			actual, err := readInviteCSV(strings.NewReader(tc.in), "member", api.PermissionRead)
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
+
+func TestOrgInviteCommand_RunCSV(t *testing.T) {
+	// Setup
+	dir, cleanup := testdata.tempDir(t)
+	defer cleanup()
+	csvFile := filepath.Join(dir, "members.csv")
+	err := ioutil.WriteFile(csvFile, []byte("username,repos\ndev1,app\ndev2,app\n"), 0644)
+	assert.OK(t, err)
+
+	failDev2 := true
+	var calls []string
+	newCmd := func() (*OrgInviteCommand, *fakeui.FakeIO) {
+		io := fakeui.NewIO(t)
+		return &OrgInviteCommand{
+			io:         io,
+			orgName:    "company",
+			csvFile:    csvFile,
+			role:       "member",
+			permission: api.PermissionRead,
+			force:      true,
+			newClient: func() (secrethub.ClientInterface, error) {
+				return fakeclient.Client{
+					OrgService: &fakeclient.OrgService{
+						MembersService: &fakeclient.OrgMemberService{
+							InviteFunc: func(org string, username string, role string) (*api.OrgMember, error) {
+								calls = append(calls, "invite "+username)
+								return &api.OrgMember{}, nil
+							},
+						},
+					},
+					AccessRuleService: &fakeclient.AccessRuleService{
+						SetFunc: func(path string, permission string, accountName string) (*api.AccessRule, error) {
+							calls = append(calls, "set "+path+" "+accountName)
+							if accountName == "dev2" && failDev2 {
+								return nil, api.ErrAccessRuleNotFound
+							}
+							return nil, nil
+						},
+					},
+				}, nil
+			},
+		}, io
+	}
+
+	// Act
+	cmd, io := newCmd()
+	err = cmd.Run()
+
+	// Assert
+	assert.Equal(t, err, ErrInvitesFailed("1 invite"))
+	assert.Equal(t, io.Out.String(), ""+
+		"Invited dev1 as member.\n"+
+		"Gave dev1 read access to company/app.\n"+
+		"Invited dev2 as member.\n"+
+		"Failed to invite dev2: "+api.ErrAccessRuleNotFound.Error()+"\n"+
+		"\n"+
+		"Invited 1 of 2 users. The progress is saved in "+csvFile+".progress.\n",
+	)
+
+	// Act
+	failDev2 = false
+	calls = nil
+	cmd, io = newCmd()
+	err = cmd.Run()
+
+	// Assert
+	assert.OK(t, err)
+	assert.Equal(t, calls, []string{"set company/app dev2"})
+	assert.Equal(t, io.Out.String(), ""+
+		"Skipped inviting dev1: invited in a previous run.\n"+
+		"Skipped inviting dev2: invited in a previous run.\n"+
+		"Gave dev2 read access to company/app.\n"+
+		"\n"+
+		"Invite complete! Invited 2 users to the company organization.\n",
+	)
+	_, err = ioutil.ReadFile(csvFile + ".progress")
+	assert.Equal(t, err != nil, true)
+}
+
+func TestOrgInviteCommand_RunCSV_ProgressOfOtherOrg(t *testing.T) {
+	// Setup
+	dir, cleanup := testdata.tempDir(t)
+	defer cleanup()
+	csvFile := filepath.Join(dir, "members.csv")
+	err := ioutil.WriteFile(csvFile, []byte("username\ndev1\n"), 0644)
+	assert.OK(t, err)
+	err = ioutil.WriteFile(csvFile+".progress", []byte(inviteStep("company", "dev1")+"\n"), 0600)
+	assert.OK(t, err)
+
+	var invited []string
+	io := fakeui.NewIO(t)
+	cmd := &OrgInviteCommand{
+		io:         io,
+		orgName:    "other",
+		csvFile:    csvFile,
+		role:       "member",
+		permission: api.PermissionRead,
+		force:      true,
+		newClient: func() (secrethub.ClientInterface, error) {
+			return fakeclient.Client{
+				OrgService: &fakeclient.OrgService{
+					MembersService: &fakeclient.OrgMemberService{
+						InviteFunc: func(org string, username string, role string) (*api.OrgMember, error) {
+							invited = append(invited, org+" "+username)
+							return &api.OrgMember{}, nil
+						},
+					},
+				},
+			}, nil
+		},
+	}
+
+	// Act
+	err = cmd.Run()
+
+	// Assert
+	assert.OK(t, err)
+	assert.Equal(t, invited, []string{"other dev1"})
+}
@@ -1,32 +1,63 @@
 package secrethub
 
 import (
+	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/secrethub/secrethub-cli/internals/cli"
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/cli/validation"
 	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
 )
 
+// Errors
+var (
+	errShellWithoutExport = errMain.Code("shell_without_export").Error("--shell can only be used together with --export")
+	ErrInvalidExportName  = errMain.Code("invalid_export_name").ErrorPref("cannot export %q: environment variable names can only contain letters, digits and underscores and cannot start with a digit")
+)
+
+// Shells for which environment variables can be exported.
+const (
+	shellBash       = "bash"
+	shellFish       = "fish"
+	shellPowerShell = "powershell"
+)
+
 // PrintEnvCommand prints out debug statements about all environment variables.
 type PrintEnvCommand struct {
-	app     *cli.App
-	io      ui.IO
-	osEnv   func() []string
-	verbose bool
+	app         *cli.App
+	io          ui.IO
+	newClient   newClientFunc
+	osEnv       func() []string
+	verbose     bool
+	export      bool
+	shell       string
+	environment *environment
 }
 
 // NewPrintEnvCommand creates a new PrintEnvCommand.
-func NewPrintEnvCommand(app *cli.App, io ui.IO) *PrintEnvCommand {
+func NewPrintEnvCommand(app *cli.App, io ui.IO, newClient newClientFunc) *PrintEnvCommand {
 	return &PrintEnvCommand{
-		app:   app,
-		io:    io,
-		osEnv: os.Environ,
+		app:         app,
+		io:          io,
+		newClient:   newClient,
+		osEnv:       os.Environ,
+		environment: newEnvironment(io, newClient),
 	}
 }
 
 // Run prints out debug statements about all environment variables.
 func (cmd *PrintEnvCommand) Run() error {
+	if cmd.export {
+		return cmd.exportEnv()
+	}
+	if cmd.shell != "" {
+		return errShellWithoutExport
+	}
+
 	err := cmd.app.PrintEnv(cmd.io.Output(), cmd.verbose, cmd.osEnv)
 	if err != nil {
 		return err
@@ -34,10 +65,85 @@ func (cmd *PrintEnvCommand) Run() error {
 	return nil
 }
 
+// exportEnv prints the commands to export the environment variables of the env file or
+// template in the shell, so they can be loaded with `eval "$(secrethub printenv --export)"`.
+// Variables that are already set to the same value in the current environment are left out.
+func (cmd *PrintEnvCommand) exportEnv() error {
+	env, err := cmd.environment.env()
+	if err != nil {
+		return err
+	}
+
+	osEnv, _ := parseKeyValueStringsToMap(cmd.osEnv())
+	secretReader := newSecretReader(cmd.newClient)
+
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := make(map[string]string, len(names))
+	for _, name := range names {
+		value, err := env[name].resolve(secretReader)
+		if err != nil {
+			return err
+		}
+		current, ok := osEnv[name]
+		if ok && current == value {
+			continue
+		}
+		// Names are printed unquoted, so only POSIX names are exported to prevent them from injecting commands.
+		if !validation.IsEnvarNamePosix(name) {
+			return ErrInvalidExportName(name)
+		}
+		values[name] = value
+	}
+
+	shell := cmd.shell
+	if shell == "" {
+		shell = shellBash
+	}
+
+	for _, name := range names {
+		value, ok := values[name]
+		if !ok {
+			continue
+		}
+		printExport(cmd.io.Output(), shell, name, value)
+	}
+	return nil
+}
+
+// printExport prints the command to export the environment variable in the given shell.
+// The name must be a POSIX environment variable name.
+func printExport(w io.Writer, shell string, name string, value string) {
+	switch shell {
+	case shellFish:
+		// In fish, backslashes and single quotes have to be escaped within single quotes.
+		escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+		fmt.Fprintf(w, "set -gx %s '%s';\n", name, escaped)
+	case shellPowerShell:
+		// In PowerShell, a single quote is escaped by doubling it.
+		fmt.Fprintf(w, "$env:%s = '%s'\n", name, strings.Replace(value, `'`, `''`, -1))
+	default:
+		// In POSIX shells, a single quote cannot be escaped within single quotes,
+		// so the quoted string is closed, an escaped quote is added and the string is reopened.
+		fmt.Fprintf(w, "export %s='%s'\n", name, strings.Replace(value, `'`, `'\''`, -1))
+	}
+}
+
 // Register registers the command, arguments and flags on the provided Registerer.
 func (cmd *PrintEnvCommand) Register(r command.Registerer) {
 	clause := r.Command("printenv", "Print environment variables.")
+	clause.HelpLong("By default, the environment variables that configure secrethub are printed. " +
+		"With --export, the environment variables of an env file or template are printed as commands for the given shell instead, with the secrets they reference filled in. " +
+		"This makes them available in the current shell session, e.g. with `eval \"$(secrethub printenv --export)\"` in bash or `secrethub printenv --export --shell powershell | Invoke-Expression` in PowerShell.")
 	clause.Flag("verbose", "Show all possible environment variables.").Short('v').BoolVar(&cmd.verbose)
+	clause.Flag("export", "Print the environment variables of an env file or template as export commands.").BoolVar(&cmd.export)
+	clause.Flag("shell", "The shell to print the export commands for: bash, fish or powershell. Commands for bash also work in sh and zsh. Defaults to bash.").EnumVar(&cmd.shell, shellBash, shellFish, shellPowerShell)
+
+	cmd.environment.registerFlags(clause, false)
 
 	command.BindAction(clause, cmd.Run)
 }
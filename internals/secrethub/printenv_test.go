@@ -0,0 +1,143 @@
+package secrethub
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestPrintExport(t *testing.T) {
+	cases := map[string]struct {
+		shell    string
+		value    string
+		expected string
+	}{
+		"bash": {
+			shell:    shellBash,
+			value:    "it's a $ecret",
+			expected: "export DB_PASSWORD='it'\\''s a $ecret'\n",
+		},
+		"fish": {
+			shell:    shellFish,
+			value:    `it's a \secret`,
+			expected: `set -gx DB_PASSWORD 'it\'s a \\secret';` + "\n",
+		},
+		"powershell": {
+			shell:    shellPowerShell,
+			value:    "it's a $ecret",
+			expected: "$env:DB_PASSWORD = 'it''s a $ecret'\n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			printExport(&buf, tc.shell, "DB_PASSWORD", tc.value)
+			assert.Equal(t, buf.String(), tc.expected)
+		})
+	}
+}
+
+func TestPrintEnvCommand_Run_Export(t *testing.T) {
+	io := fakeui.NewIO(t)
+	newClient := func() (secrethub.ClientInterface, error) {
+		return fakeclient.Client{
+			SecretService: &fakeclient.SecretService{
+				VersionService: &fakeclient.SecretVersionService{
+					GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+						return &api.SecretVersion{Data: []byte("secret")}, nil
+					},
+				},
+			},
+		}, nil
+	}
+
+	cmd := PrintEnvCommand{
+		io:        io,
+		newClient: newClient,
+		export:    true,
+		osEnv: func() []string {
+			return []string{"HOME=/home/user"}
+		},
+		environment: &environment{
+			osEnv: []string{"HOME=/home/user"},
+			envar: map[string]string{
+				"DB_PASSWORD": "company/app/db/password",
+			},
+			osStat: func(string) (os.FileInfo, error) {
+				return nil, os.ErrNotExist
+			},
+		},
+	}
+
+	err := cmd.Run()
+	assert.OK(t, err)
+	assert.Equal(t, io.Out.String(), "export DB_PASSWORD='secret'\n")
+}
+
+func TestPrintEnvCommand_Run_ExportInvalidName(t *testing.T) {
+	cases := map[string]string{
+		"command substitution": "$(touch pwned)",
+		"statement separator":  "A;touch pwned;B",
+		"leading digit":        "1PASSWORD",
+		"space":                "DB PASSWORD",
+	}
+
+	for name, envar := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			io := fakeui.NewIO(t)
+			newClient := func() (secrethub.ClientInterface, error) {
+				return fakeclient.Client{
+					SecretService: &fakeclient.SecretService{
+						VersionService: &fakeclient.SecretVersionService{
+							GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+								return &api.SecretVersion{Data: []byte("secret")}, nil
+							},
+						},
+					},
+				}, nil
+			}
+
+			cmd := PrintEnvCommand{
+				io:        io,
+				newClient: newClient,
+				export:    true,
+				osEnv: func() []string {
+					return nil
+				},
+				environment: &environment{
+					envar: map[string]string{
+						envar: "company/app/db/password",
+					},
+					osStat: func(string) (os.FileInfo, error) {
+						return nil, os.ErrNotExist
+					},
+				},
+			}
+
+			// Act
+			err := cmd.Run()
+
+			// Assert
+			assert.Equal(t, err, ErrInvalidExportName(envar))
+			assert.Equal(t, io.Out.String(), "")
+		})
+	}
+}
+
+func TestPrintEnvCommand_Run_ShellWithoutExport(t *testing.T) {
+	cmd := PrintEnvCommand{
+		shell: shellFish,
+	}
+
+	err := cmd.Run()
+	assert.Equal(t, err, errShellWithoutExport)
+}
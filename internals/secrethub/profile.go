@@ -0,0 +1,141 @@
+package secrethub
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/secrethub/secrethub-go/pkg/secrethub/configdir"
+)
+
+// Errors
+var (
+	ErrInvalidProfileName   = errMain.Code("invalid_profile_name").ErrorPref("invalid profile name %q: names can only contain letters, digits, dashes and underscores")
+	ErrProfileNotFound      = errMain.Code("profile_not_found").ErrorPref("profile %s does not exist: create it with `" + ApplicationName + " config profile add %s`")
+	ErrProfileAlreadyExists = errMain.Code("profile_already_exists").ErrorPref("profile %s already exists")
+	ErrCannotReadProfile    = errMain.Code("cannot_read_profile").ErrorPref("cannot read the active profile: %s")
+)
+
+const (
+	// defaultProfileName is the name of the profile that uses the configuration directory itself.
+	defaultProfileName = "default"
+	// profilesDirName is the directory in the configuration directory that holds the other profiles.
+	profilesDirName = "profiles"
+	// activeProfileFilename is the file in the configuration directory that holds the name of the active profile.
+	activeProfileFilename = "profile"
+)
+
+// profiles manages the named profiles in a configuration directory. Every profile is a configuration
+// directory of its own, with its own credential, so you can switch between accounts.
+type profiles struct {
+	base configdir.Dir
+}
+
+// dir returns the configuration directory of the profile.
+func (p profiles) dir(name string) configdir.Dir {
+	if name == "" || name == defaultProfileName {
+		return p.base
+	}
+	return configdir.New(filepath.Join(p.base.Path(), profilesDirName, name))
+}
+
+// exists returns whether the profile exists.
+func (p profiles) exists(name string) bool {
+	if name == defaultProfileName {
+		return true
+	}
+	info, err := os.Stat(p.dir(name).Path())
+	return err == nil && info.IsDir()
+}
+
+// add creates the configuration directory of the profile.
+func (p profiles) add(name string) error {
+	if p.exists(name) {
+		return ErrProfileAlreadyExists(name)
+	}
+	return os.MkdirAll(p.dir(name).Path(), defaultProfileDirFileMode)
+}
+
+// list returns the names of all profiles, sorted, starting with the default profile.
+func (p profiles) list() ([]string, error) {
+	infos, err := ioutil.ReadDir(filepath.Join(p.base.Path(), profilesDirName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var names []string
+	for _, info := range infos {
+		if info.IsDir() && validateProfileName(info.Name()) == nil {
+			names = append(names, info.Name())
+		}
+	}
+	sort.Strings(names)
+	return append([]string{defaultProfileName}, names...), nil
+}
+
+// active returns the name of the profile that is used when none is selected with --profile.
+func (p profiles) active() (string, error) {
+	data, err := ioutil.ReadFile(filepath.Join(p.base.Path(), activeProfileFilename))
+	if os.IsNotExist(err) {
+		return defaultProfileName, nil
+	} else if err != nil {
+		return "", ErrCannotReadProfile(err)
+	}
+
+	name := strings.TrimSpace(string(data))
+	err = validateProfileName(name)
+	if err != nil {
+		return "", ErrCannotReadProfile(err)
+	}
+	return name, nil
+}
+
+// setActive sets the profile that is used when none is selected with --profile.
+func (p profiles) setActive(name string) error {
+	if !p.exists(name) {
+		return ErrProfileNotFound(name, name)
+	}
+	err := os.MkdirAll(p.base.Path(), defaultProfileDirFileMode)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(p.base.Path(), activeProfileFilename), []byte(name+"\n"), defaultCredentialFileMode)
+}
+
+// validateProfileName checks that the name can be used as the name of a profile directory.
+func validateProfileName(name string) error {
+	if name == "" {
+		return ErrInvalidProfileName(name)
+	}
+	for _, r := range name {
+		if !isProfileNameChar(r) {
+			return ErrInvalidProfileName(name)
+		}
+	}
+	return nil
+}
+
+// isProfileNameChar returns whether the character can be used in the name of a profile.
+// Profile names are used as directory names, so they are restricted to characters that are
+// safe in file names on all platforms.
+func isProfileNameChar(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '-'
+}
+
+// profileName is a flag value for the name of a profile.
+type profileName string
+
+func (n *profileName) Set(value string) error {
+	err := validateProfileName(value)
+	if err != nil {
+		return err
+	}
+	*n = profileName(value)
+	return nil
+}
+
+func (n profileName) String() string {
+	return string(n)
+}
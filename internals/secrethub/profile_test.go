@@ -0,0 +1,69 @@
+package secrethub
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/configdir"
+)
+
+func TestCredentialConfig_Profile(t *testing.T) {
+	dir, cleanup := testdata.tempDir(t)
+	defer cleanup()
+
+	store := &credentialConfig{configDir: ConfigDir{Dir: configdir.New(dir)}}
+	profiles := store.Profiles()
+
+	// Without profiles, the configuration directory itself is used.
+	assert.Equal(t, store.Profile(), defaultProfileName)
+	assert.Equal(t, store.ConfigDir().Path(), dir)
+
+	err := profiles.add("work")
+	assert.OK(t, err)
+	err = profiles.add("work")
+	assert.Equal(t, err, ErrProfileAlreadyExists("work"))
+
+	names, err := profiles.list()
+	assert.OK(t, err)
+	assert.Equal(t, names, []string{defaultProfileName, "work"})
+
+	// The active profile is used by default.
+	err = profiles.setActive("work")
+	assert.OK(t, err)
+	assert.Equal(t, store.Profile(), "work")
+	assert.Equal(t, store.ConfigDir().Path(), filepath.Join(dir, profilesDirName, "work"))
+
+	// The --profile flag takes precedence.
+	err = store.profile.Set(defaultProfileName)
+	assert.OK(t, err)
+	assert.Equal(t, store.ConfigDir().Path(), dir)
+
+	err = profiles.setActive("other")
+	assert.Equal(t, err, ErrProfileNotFound("other", "other"))
+}
+
+func TestValidateProfileName(t *testing.T) {
+	cases := map[string]struct {
+		name string
+		err  error
+	}{
+		"valid": {
+			name: "break-glass_2",
+		},
+		"empty": {
+			name: "",
+			err:  ErrInvalidProfileName(""),
+		},
+		"path": {
+			name: "../work",
+			err:  ErrInvalidProfileName("../work"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, validateProfileName(tc.name), tc.err)
+		})
+	}
+}
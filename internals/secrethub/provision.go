@@ -0,0 +1,28 @@
+package secrethub
+
+import (
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// ProvisionCommand handles provisioning secrets, services and access rules from a manifest.
+type ProvisionCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+}
+
+// NewProvisionCommand creates a new ProvisionCommand.
+func NewProvisionCommand(io ui.IO, newClient newClientFunc) *ProvisionCommand {
+	return &ProvisionCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command and its sub-commands on the provided Registerer.
+func (cmd *ProvisionCommand) Register(r command.Registerer) {
+	clause := r.Command("provision", "Provision secrets, service accounts and access rules from a manifest.")
+	clause.HelpLong("A manifest declares the secrets to generate, the service accounts to create and the access rules to give them, " +
+		"so that an environment can be bootstrapped from a single reviewed file.")
+	NewProvisionApplyCommand(cmd.io, cmd.newClient).Register(clause)
+}
@@ -0,0 +1,507 @@
+package secrethub
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/posix"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/errio"
+	"github.com/secrethub/secrethub-go/pkg/randchar"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/credentials"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Errors
+var (
+	errProvision                 = errio.Namespace("provision")
+	ErrInvalidProvisionManifest  = errProvision.Code("invalid_manifest").ErrorPref("invalid manifest %s: %v")
+	ErrInvalidProvisionSecret    = errProvision.Code("invalid_secret").ErrorPref("invalid secret %s: %v")
+	ErrInvalidProvisionService   = errProvision.Code("invalid_service").ErrorPref("invalid service %s: %v")
+	ErrDuplicateProvisionSecret  = errProvision.Code("duplicate_secret").ErrorPref("the secret %s is declared more than once")
+	ErrDuplicateProvisionService = errProvision.Code("duplicate_service").ErrorPref("the service %s in %s is declared more than once")
+	ErrCredentialFileExists      = errProvision.Code("credential_file_exists").ErrorPref("cannot create service %s: its credential file %s already exists")
+)
+
+const (
+	provisionCredentialFileMode = 0400
+
+	provisionActionCreate = "create"
+	provisionActionKeep   = "keep"
+	provisionActionUpdate = "update"
+)
+
+// provisionManifest declares the secrets, service accounts and access rules of an environment. For example:
+//
+//	secrets:
+//	  - path: company/app/prod/db/password
+//	    length: 32
+//	    charset: alphanumeric,symbols
+//	    min: ["numeric:2"]
+//	services:
+//	  - repo: company/app
+//	    description: app-prod
+//	    credential_file: app-prod.credential
+//	    permissions:
+//	      - path: company/app/prod
+//	        permission: read
+//
+// Secrets that already exist are never regenerated and service accounts are identified by
+// their repository and description, so applying a manifest again only creates what is missing.
+type provisionManifest struct {
+	Secrets  []provisionSecret  `yaml:"secrets"`
+	Services []provisionService `yaml:"services"`
+}
+
+// provisionSecret is a secret to generate.
+type provisionSecret struct {
+	Path    string   `yaml:"path"`
+	Length  int      `yaml:"length,omitempty"`
+	Charset string   `yaml:"charset,omitempty"`
+	Min     []string `yaml:"min,omitempty"`
+}
+
+// provisionService is a service account to create, with the permissions to give it.
+type provisionService struct {
+	Repo           string                `yaml:"repo"`
+	Description    string                `yaml:"description"`
+	CredentialFile string                `yaml:"credential_file,omitempty"`
+	Permissions    []provisionPermission `yaml:"permissions,omitempty"`
+}
+
+// provisionPermission is an access rule to give a service account on a directory.
+type provisionPermission struct {
+	Path       string `yaml:"path"`
+	Permission string `yaml:"permission"`
+}
+
+// readProvisionManifest reads and validates a manifest from a YAML file.
+func readProvisionManifest(filename string) (*provisionManifest, error) {
+	raw, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, ErrCannotReadFile(filename, err)
+	}
+
+	manifest := &provisionManifest{}
+	err = yaml.UnmarshalStrict(raw, manifest)
+	if err != nil {
+		return nil, ErrInvalidProvisionManifest(filename, err)
+	}
+
+	err = manifest.validate()
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// validate checks the manifest before anything is provisioned.
+func (m *provisionManifest) validate() error {
+	secrets := map[string]bool{}
+	for _, secret := range m.Secrets {
+		var secretPath api.SecretPath
+		err := secretPath.Set(secret.Path)
+		if err != nil {
+			return ErrInvalidProvisionSecret(secret.Path, err)
+		}
+
+		key := strings.ToLower(secret.Path)
+		if secrets[key] {
+			return ErrDuplicateProvisionSecret(secret.Path)
+		}
+		secrets[key] = true
+
+		if secret.Length < 0 {
+			return ErrInvalidProvisionSecret(secret.Path, ErrInvalidRandLength)
+		}
+
+		_, err = secret.generator()
+		if err != nil {
+			return ErrInvalidProvisionSecret(secret.Path, err)
+		}
+	}
+
+	services := map[string]bool{}
+	for _, service := range m.Services {
+		var repoPath api.RepoPath
+		err := repoPath.Set(service.Repo)
+		if err != nil {
+			return ErrInvalidProvisionService(service.Description, err)
+		}
+
+		if service.Description == "" {
+			return ErrInvalidProvisionService(service.Repo, "a description is required to identify the service")
+		}
+
+		key := strings.ToLower(service.Repo + "\x00" + service.Description)
+		if services[key] {
+			return ErrDuplicateProvisionService(service.Description, service.Repo)
+		}
+		services[key] = true
+
+		for _, rule := range service.Permissions {
+			dirPath, err := api.NewDirPath(rule.Path)
+			if err != nil {
+				return ErrInvalidProvisionService(service.Description, err)
+			}
+
+			dir := strings.ToLower(dirPath.Value())
+			repo := strings.ToLower(repoPath.Value())
+			if dir != repo && !strings.HasPrefix(dir, repo+"/") {
+				return ErrInvalidProvisionService(service.Description, fmt.Sprintf("%s is not in the repository %s", rule.Path, service.Repo))
+			}
+
+			var permission api.Permission
+			err = permission.Set(rule.Permission)
+			if err != nil {
+				return ErrInvalidProvisionService(service.Description, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// length returns the length of the secret to generate.
+func (s provisionSecret) length() int {
+	if s.Length == 0 {
+		return defaultLength
+	}
+	return s.Length
+}
+
+// generator returns the generator for the secret, configured with its charset and minimum rules.
+func (s provisionSecret) generator() (randchar.Generator, error) {
+	charset := s.Charset
+	if charset == "" {
+		charset = "alphanumeric"
+	}
+
+	var charsetFlag charsetValue
+	err := charsetFlag.Set(charset)
+	if err != nil {
+		return nil, err
+	}
+
+	var mins minRuleValue
+	for _, min := range s.Min {
+		err = mins.Set(min)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return randchar.NewRand(charsetFlag.v, mins.v...)
+}
+
+// ProvisionApplyCommand creates everything declared in a manifest that does not exist yet.
+type ProvisionApplyCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+	file      string
+	dryRun    bool
+}
+
+// NewProvisionApplyCommand creates a new ProvisionApplyCommand.
+func NewProvisionApplyCommand(io ui.IO, newClient newClientFunc) *ProvisionApplyCommand {
+	return &ProvisionApplyCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *ProvisionApplyCommand) Register(r command.Registerer) {
+	clause := r.Command("apply", "Generate the secrets, create the service accounts and set the access rules declared in a manifest.")
+	clause.HelpLong("The manifest is a YAML file, e.g.:\n\n" +
+		"    secrets:\n" +
+		"      - path: company/app/prod/db/password\n" +
+		"        length: 32\n" +
+		"        charset: alphanumeric,symbols\n" +
+		"        min: [\"numeric:2\"]\n" +
+		"    services:\n" +
+		"      - repo: company/app\n" +
+		"        description: app-prod\n" +
+		"        credential_file: app-prod.credential\n" +
+		"        permissions:\n" +
+		"          - path: company/app/prod\n" +
+		"            permission: read\n\n" +
+		"Applying a manifest is idempotent: existing secrets are never regenerated, service accounts are identified by their repository and description " +
+		"and access rules are only set when they differ. The credential of a new service account is written to its credential_file.")
+	clause.Arg("manifest", "The path to the manifest file.").Required().ExistingFileVar(&cmd.file)
+	clause.Flag("dry-run", "Print what would be created or updated, without changing anything.").BoolVar(&cmd.dryRun)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run applies the manifest.
+func (cmd *ProvisionApplyCommand) Run() error {
+	manifest, err := readProvisionManifest(cmd.file)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	plan, err := newProvisionPlan(client, manifest)
+	if err != nil {
+		return err
+	}
+
+	err = plan.print(cmd.io.Output())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.io.Output())
+
+	changes := plan.changes()
+	if changes == 0 {
+		fmt.Fprintln(cmd.io.Output(), "Everything is up to date.")
+		return nil
+	}
+
+	if cmd.dryRun {
+		fmt.Fprintln(cmd.io.Output(), "Dry run complete! Nothing has been changed.")
+		return nil
+	}
+
+	err = plan.apply()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Applied %s.\n", pluralize("change", "changes", changes))
+	return nil
+}
+
+// provisionStep is a single change, or a resource that is kept as is.
+type provisionStep struct {
+	action string
+	kind   string
+	target string
+	apply  func() error
+}
+
+// provisionPlan contains the steps to converge to the manifest, in the order of the manifest.
+type provisionPlan struct {
+	steps []provisionStep
+}
+
+// newProvisionPlan compares the manifest with the existing secrets, services and
+// access rules and returns the steps needed to create what is missing.
+func newProvisionPlan(client secrethub.ClientInterface, manifest *provisionManifest) (*provisionPlan, error) {
+	plan := &provisionPlan{}
+
+	for _, secret := range manifest.Secrets {
+		exists, err := client.Secrets().Exists(secret.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		if exists {
+			plan.add(provisionActionKeep, "secret", secret.Path, nil)
+			continue
+		}
+
+		secret := secret
+		plan.add(provisionActionCreate, "secret", secret.Path, func() error {
+			return provisionGenerateSecret(client, secret)
+		})
+	}
+
+	servicesByRepo := map[string][]*api.Service{}
+	for _, manifestService := range manifest.Services {
+		existing, ok := servicesByRepo[manifestService.Repo]
+		if !ok {
+			var err error
+			existing, err = client.Services().List(manifestService.Repo)
+			if err != nil {
+				return nil, err
+			}
+			servicesByRepo[manifestService.Repo] = existing
+		}
+
+		// service is set when the service exists or once it has been created.
+		var service *api.Service
+		for _, s := range existing {
+			if s.Description == manifestService.Description {
+				service = s
+				break
+			}
+		}
+
+		target := manifestService.Repo + " (" + manifestService.Description + ")"
+		if service != nil {
+			plan.add(provisionActionKeep, "service", target+" "+service.ServiceID, nil)
+		} else {
+			if manifestService.CredentialFile == "" {
+				return nil, ErrInvalidProvisionService(manifestService.Description, "a credential_file is required to create the service")
+			}
+			if _, err := os.Stat(manifestService.CredentialFile); err == nil {
+				return nil, ErrCredentialFileExists(manifestService.Description, manifestService.CredentialFile)
+			}
+
+			manifestService := manifestService
+			plan.add(provisionActionCreate, "service", target, func() error {
+				var err error
+				service, err = provisionCreateService(client, manifestService)
+				return err
+			})
+		}
+
+		for _, rule := range manifestService.Permissions {
+			var permission api.Permission
+			err := permission.Set(rule.Permission)
+			if err != nil {
+				return nil, err
+			}
+
+			action := provisionActionCreate
+			if service != nil {
+				rules, err := client.AccessRules().List(rule.Path, 0, false)
+				if err != nil {
+					return nil, err
+				}
+
+				for _, existingRule := range rules {
+					if existingRule.Account.Name.Value() != service.ServiceID {
+						continue
+					}
+
+					action = provisionActionUpdate
+					if existingRule.Permission == permission {
+						action = provisionActionKeep
+					}
+				}
+			}
+
+			ruleTarget := fmt.Sprintf("%s %s for %s", rule.Path, permission, target)
+			if action == provisionActionKeep {
+				plan.add(action, "access rule", ruleTarget, nil)
+				continue
+			}
+
+			rulePath := rule.Path
+			plan.add(action, "access rule", ruleTarget, func() error {
+				_, err := client.AccessRules().Set(rulePath, permission.String(), service.ServiceID)
+				return err
+			})
+		}
+	}
+
+	return plan, nil
+}
+
+// add adds a step to the plan. Steps without an apply function are kept as is.
+func (p *provisionPlan) add(action, kind, target string, apply func() error) {
+	p.steps = append(p.steps, provisionStep{
+		action: action,
+		kind:   kind,
+		target: target,
+		apply:  apply,
+	})
+}
+
+// changes returns the number of steps that change something.
+func (p *provisionPlan) changes() int {
+	n := 0
+	for _, step := range p.steps {
+		if step.apply != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// apply executes the steps in order.
+func (p *provisionPlan) apply() error {
+	for _, step := range p.steps {
+		if step.apply == nil {
+			continue
+		}
+
+		err := step.apply()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// print writes the steps of the plan as a table.
+func (p *provisionPlan) print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\t%s\t%s\n", "ACTION", "KIND", "TARGET")
+	for _, step := range p.steps {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", step.action, step.kind, step.target)
+	}
+	return tw.Flush()
+}
+
+// provisionGenerateSecret generates the secret and writes it, creating its directory when needed.
+func provisionGenerateSecret(client secrethub.ClientInterface, secret provisionSecret) error {
+	dir := path.Dir(secret.Path)
+	dirPath, err := api.NewDirPath(dir)
+	if err != nil {
+		return err
+	}
+
+	if !dirPath.IsRepoPath() {
+		exists, err := client.Dirs().Exists(dir)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			err = client.Dirs().CreateAll(dir)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	generator, err := secret.generator()
+	if err != nil {
+		return err
+	}
+
+	data, err := generator.Generate(secret.length())
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Secrets().Write(secret.Path, data)
+	return err
+}
+
+// provisionCreateService creates the service account and writes its credential to the credential file.
+func provisionCreateService(client secrethub.ClientInterface, manifestService provisionService) (*api.Service, error) {
+	credential := credentials.CreateKey()
+	service, err := client.Services().Create(manifestService.Repo, manifestService.Description, credential)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := credential.Export()
+	if err != nil {
+		return nil, err
+	}
+
+	err = ioutil.WriteFile(manifestService.CredentialFile, posix.AddNewLine(out), provisionCredentialFileMode)
+	if err != nil {
+		return nil, ErrCannotWrite(manifestService.CredentialFile, err)
+	}
+	return service, nil
+}
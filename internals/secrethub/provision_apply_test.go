@@ -0,0 +1,118 @@
+package secrethub
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestProvisionManifest_Validate(t *testing.T) {
+	cases := map[string]struct {
+		manifest provisionManifest
+		err      error
+	}{
+		"valid": {
+			manifest: provisionManifest{
+				Secrets: []provisionSecret{
+					{Path: "company/app/prod/db/password", Length: 32, Charset: "alphanumeric,symbols", Min: []string{"numeric:2"}},
+					{Path: "company/app/prod/api-key"},
+				},
+				Services: []provisionService{
+					{
+						Repo:           "company/app",
+						Description:    "app-prod",
+						CredentialFile: "app-prod.credential",
+						Permissions: []provisionPermission{
+							{Path: "company/app/prod", Permission: "read"},
+						},
+					},
+				},
+			},
+		},
+		"duplicate secret": {
+			manifest: provisionManifest{
+				Secrets: []provisionSecret{
+					{Path: "company/app/prod/api-key"},
+					{Path: "company/app/prod/API-KEY"},
+				},
+			},
+			err: ErrDuplicateProvisionSecret("company/app/prod/API-KEY"),
+		},
+		"invalid charset": {
+			manifest: provisionManifest{
+				Secrets: []provisionSecret{
+					{Path: "company/app/prod/api-key", Charset: "emoji"},
+				},
+			},
+			err: ErrInvalidProvisionSecret("company/app/prod/api-key", ErrCouldNotFindCharSet("emoji")),
+		},
+		"negative length": {
+			manifest: provisionManifest{
+				Secrets: []provisionSecret{
+					{Path: "company/app/prod/api-key", Length: -1},
+				},
+			},
+			err: ErrInvalidProvisionSecret("company/app/prod/api-key", ErrInvalidRandLength),
+		},
+		"service without description": {
+			manifest: provisionManifest{
+				Services: []provisionService{
+					{Repo: "company/app"},
+				},
+			},
+			err: ErrInvalidProvisionService("company/app", "a description is required to identify the service"),
+		},
+		"duplicate service": {
+			manifest: provisionManifest{
+				Services: []provisionService{
+					{Repo: "company/app", Description: "app-prod"},
+					{Repo: "company/app", Description: "app-prod"},
+				},
+			},
+			err: ErrDuplicateProvisionService("app-prod", "company/app"),
+		},
+		"permission outside repo": {
+			manifest: provisionManifest{
+				Services: []provisionService{
+					{
+						Repo:        "company/app",
+						Description: "app-prod",
+						Permissions: []provisionPermission{
+							{Path: "company/other/prod", Permission: "read"},
+						},
+					},
+				},
+			},
+			err: ErrInvalidProvisionService("app-prod", "company/other/prod is not in the repository company/app"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.manifest.validate()
+
+			assert.Equal(t, err, tc.err)
+		})
+	}
+}
+
+func TestProvisionPlan(t *testing.T) {
+	plan := &provisionPlan{}
+	plan.add(provisionActionKeep, "secret", "company/app/prod/db/password", nil)
+	plan.add(provisionActionCreate, "secret", "company/app/prod/api-key", func() error { return nil })
+	plan.add(provisionActionCreate, "service", "company/app (app-prod)", func() error { return nil })
+	plan.add(provisionActionCreate, "access rule", "company/app/prod read for company/app (app-prod)", func() error { return nil })
+
+	assert.Equal(t, plan.changes(), 3)
+
+	var buf bytes.Buffer
+	err := plan.print(&buf)
+	assert.OK(t, err)
+	assert.Equal(t, buf.String(), ""+
+		"ACTION  KIND         TARGET\n"+
+		"keep    secret       company/app/prod/db/password\n"+
+		"create  secret       company/app/prod/api-key\n"+
+		"create  service      company/app (app-prod)\n"+
+		"create  access rule  company/app/prod read for company/app (app-prod)\n")
+}
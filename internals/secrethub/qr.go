@@ -0,0 +1,68 @@
+package secrethub
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+
+	"github.com/docker/go-units"
+	"github.com/skip2/go-qrcode"
+)
+
+// Errors
+var (
+	ErrQRCodeNotInteractive = errMain.Code("qr_not_interactive").Error("a QR code can only be shown in an interactive terminal")
+	ErrQRCodeTooLarge       = errMain.Code("qr_too_large").ErrorPref("the value is too large to show as a QR code: %s")
+)
+
+const (
+	defaultClearQRAfter = 1 * time.Minute
+
+	// clearScreen moves the cursor to the top left and clears the screen and the scrollback buffer.
+	clearScreen = "\033[H\033[2J\033[3J"
+)
+
+// showQRCode shows the data as a QR code on the terminal after the user confirms that nobody else can see
+// the screen. The screen is cleared when the user presses [ENTER] or after the timeout, whichever comes first.
+func showQRCode(io ui.IO, name string, data []byte, timeout time.Duration) error {
+	code, err := qrcode.New(string(data), qrcode.Medium)
+	if err != nil {
+		return ErrQRCodeTooLarge(err)
+	}
+
+	question := fmt.Sprintf("The value of %s will be shown on the screen. Make sure nobody else can see your screen. Do you want to continue?", name)
+	confirmed, err := ui.AskYesNo(io, question, ui.DefaultNo)
+	if err == ui.ErrCannotAsk {
+		return ErrQRCodeNotInteractive
+	} else if err != nil {
+		return err
+	}
+
+	if !confirmed {
+		fmt.Fprintln(io.Output(), "Aborting.")
+		return nil
+	}
+
+	in, out, err := io.Prompts()
+	if err != nil {
+		return ErrQRCodeNotInteractive
+	}
+
+	fmt.Fprint(out, code.ToSmallString(false))
+	fmt.Fprintf(out, "Press [ENTER] to clear the screen. It is cleared automatically after %s.", units.HumanDuration(timeout))
+
+	entered := make(chan struct{})
+	go func() {
+		_, _ = ui.Readln(in)
+		close(entered)
+	}()
+
+	select {
+	case <-entered:
+	case <-time.After(timeout):
+	}
+
+	fmt.Fprint(out, clearScreen)
+	return nil
+}
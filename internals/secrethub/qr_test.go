@@ -0,0 +1,62 @@
+package secrethub
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestShowQRCode(t *testing.T) {
+	cases := map[string]struct {
+		promptIn  string
+		promptErr error
+		err       error
+		shown     bool
+		out       string
+	}{
+		"confirmed": {
+			promptIn: "y\n\n",
+			shown:    true,
+		},
+		"declined": {
+			promptIn: "n\n",
+			out:      "Aborting.\n",
+		},
+		"not interactive": {
+			promptErr: ui.ErrCannotAsk,
+			err:       ErrQRCodeNotInteractive,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			io := fakeui.NewIO(t)
+			io.PromptIn.Buffer = bytes.NewBufferString(tc.promptIn)
+			io.PromptErr = tc.promptErr
+
+			err := showQRCode(io, "company/app/totp-seed", []byte("otpauth://totp/company?secret=JBSWY3DPEHPK3PXP"), time.Minute)
+
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.Out.String(), tc.out)
+			assert.Equal(t, strings.Contains(io.PromptOut.String(), "▄"), tc.shown)
+			assert.Equal(t, strings.HasSuffix(io.PromptOut.String(), clearScreen), tc.shown)
+		})
+	}
+}
+
+func TestReadCommand_QRWithOtherOutput(t *testing.T) {
+	cmd := ReadCommand{
+		showQR:       true,
+		useClipboard: true,
+	}
+
+	err := cmd.Run()
+
+	assert.Equal(t, err, errQRWithOtherOutput)
+}
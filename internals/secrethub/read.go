@@ -2,9 +2,9 @@ package secrethub
 
 import (
 	"fmt"
-	"io/ioutil"
 	"time"
 
+	"github.com/secrethub/secrethub-cli/internals/cli/atomicfile"
 	"github.com/secrethub/secrethub-cli/internals/cli/clip"
 	"github.com/secrethub/secrethub-cli/internals/cli/filemode"
 	"github.com/secrethub/secrethub-cli/internals/cli/posix"
@@ -16,16 +16,30 @@ import (
 	"github.com/docker/go-units"
 )
 
+// Errors
+var (
+	errQRWithOtherOutput = errMain.Code("qr_with_other_output").Error("--qr cannot be used together with --clip or --out-file")
+	errBinaryToTerminal  = errMain.Code("binary_to_terminal").Error("the secret contains binary data, which is not printed to the terminal. Use --out-file or redirect the output to a file.")
+)
+
 // ReadCommand is a command to read a secret.
 type ReadCommand struct {
 	io                  ui.IO
 	path                api.SecretPath
 	useClipboard        bool
 	clearClipboardAfter time.Duration
+	countdown           bool
 	clipper             clip.Clipper
 	outFile             string
 	fileMode            filemode.FileMode
 	noNewLine           bool
+	binary              bool
+	showQR              bool
+	query               string
+	lines               int
+	tail                int
+	lineRange           lineRange
+	clearQRAfter        time.Duration
 	newClient           newClientFunc
 }
 
@@ -34,6 +48,7 @@ func NewReadCommand(io ui.IO, newClient newClientFunc) *ReadCommand {
 	return &ReadCommand{
 		clipper:             clip.NewClipboard(),
 		clearClipboardAfter: defaultClearClipboardAfter,
+		clearQRAfter:        defaultClearQRAfter,
 		io:                  io,
 		newClient:           newClient,
 	}
@@ -46,19 +61,59 @@ func (cmd *ReadCommand) Register(r command.Registerer) {
 	clause.Flag(
 		"clip",
 		fmt.Sprintf(
-			"Copy the secret value to the clipboard. The clipboard is automatically cleared after %s.",
+			"Copy the secret value to the clipboard. The clipboard is automatically cleared after %s, or the duration set with --clear-after.",
 			units.HumanDuration(cmd.clearClipboardAfter),
 		),
 	).Short('c').BoolVar(&cmd.useClipboard)
-	clause.Flag("out-file", "Write the secret value to this file.").Short('o').StringVar(&cmd.outFile)
-	clause.Flag("file-mode", "Set filemode for the output file. Defaults to 0600 (read and write for current user) and is ignored without the --out-file flag.").Default("0600").SetValue(&cmd.fileMode)
+	registerClearAfterFlag(clause).DurationVar(&cmd.clearClipboardAfter)
+	registerCountdownFlag(clause).BoolVar(&cmd.countdown)
+	clause.Flag("out-file", "Write the secret value to this file instead of stdout. An existing file is replaced atomically.").Short('o').StringVar(&cmd.outFile)
+	clause.Flag("file-mode", "Set filemode for the output file, also when it already exists. Defaults to 0600 (read and write for current user) and is ignored without the --out-file flag.").Default("0600").SetValue(&cmd.fileMode)
 	clause.Flag("no-newline", "Do not print a new line after the secret.").Short('n').BoolVar(&cmd.noNewLine)
+	clause.Flag("binary", "Read the secret exactly as it is stored, e.g. a keystore or license file. No new line is added and the secret is never printed to the terminal, so use it together with --out-file or redirect the output.").BoolVar(&cmd.binary)
+	clause.Flag(
+		"qr",
+		fmt.Sprintf(
+			"Show the secret value as a QR code in the terminal, e.g. to scan a TOTP seed or WiFi password with a mobile device. "+
+				"You are asked to confirm that nobody else can see your screen first. The screen is cleared after %s or when you press [ENTER].",
+			units.HumanDuration(cmd.clearQRAfter),
+		),
+	).BoolVar(&cmd.showQR)
+	clause.Flag("query", "Only read the field of a JSON secret that is selected by this jq-like query, e.g. .database.password or .hosts[0]. "+
+		"String values are returned without quotes, other values as JSON.").PlaceHolder(".<field>").StringVar(&cmd.query)
+	clause.Flag("lines", "Only read the first n lines of the secret, e.g. the first key of a list of keys.").PlaceHolder("n").IntVar(&cmd.lines)
+	clause.Flag("tail", "Only read the last n lines of the secret.").PlaceHolder("n").IntVar(&cmd.tail)
+	clause.Flag("line-range", "Only read the lines in this range, counting from 1, e.g. 3:10, 3: or :10.").PlaceHolder("<first>:<last>").SetValue(&cmd.lineRange)
 
 	command.BindAction(clause, cmd.Run)
 }
 
 // Run handles the command with the options as specified in the command.
 func (cmd *ReadCommand) Run() error {
+	if cmd.showQR && (cmd.useClipboard || cmd.outFile != "") {
+		return errQRWithOtherOutput
+	}
+
+	if cmd.binary && (cmd.useClipboard || cmd.showQR || cmd.query != "") {
+		return ErrFlagsConflict("--binary and --clip, --qr or --query")
+	}
+
+	selectors := 0
+	for _, set := range []bool{cmd.lines != 0, cmd.tail != 0, cmd.lineRange.first != 0} {
+		if set {
+			selectors++
+		}
+	}
+	if selectors > 1 {
+		return ErrFlagsConflict("--lines, --tail and --line-range")
+	}
+	if selectors > 0 && cmd.binary {
+		return ErrFlagsConflict("--binary and --lines, --tail or --line-range")
+	}
+	if cmd.lines < 0 || cmd.tail < 0 {
+		return ErrInvalidLineCount
+	}
+
 	client, err := cmd.newClient()
 	if err != nil {
 		return err
@@ -69,6 +124,28 @@ func (cmd *ReadCommand) Run() error {
 		return err
 	}
 
+	if cmd.query != "" {
+		secret.Data, err = queryJSON(secret.Data, cmd.query)
+		if err != nil {
+			return err
+		}
+	}
+
+	if cmd.lines > 0 {
+		secret.Data, err = selectLines(secret.Data, lineRange{first: 1, last: cmd.lines})
+	} else if cmd.lineRange.first > 0 {
+		secret.Data, err = selectLines(secret.Data, cmd.lineRange)
+	} else if cmd.tail > 0 {
+		secret.Data = tailLines(secret.Data, cmd.tail)
+	}
+	if err != nil {
+		return err
+	}
+
+	if cmd.showQR {
+		return showQRCode(cmd.io, cmd.path.String(), secret.Data, cmd.clearQRAfter)
+	}
+
 	if cmd.useClipboard {
 		err = WriteClipboardAutoClear(secret.Data, cmd.clearClipboardAfter, cmd.clipper)
 		if err != nil {
@@ -81,15 +158,29 @@ func (cmd *ReadCommand) Run() error {
 			cmd.path,
 			units.HumanDuration(cmd.clearClipboardAfter),
 		)
+
+		if cmd.countdown {
+			err = waitForClipboardClear(cmd.io, secret.Data, cmd.clearClipboardAfter, cmd.clipper)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Binary data would mess up the terminal, so it is only written to files and pipes.
+	printToTerminal := cmd.outFile == "" && !cmd.useClipboard && !cmd.io.IsOutputPiped()
+	if printToTerminal && (cmd.binary || isBinary(secret.Data)) {
+		return errBinaryToTerminal
 	}
 
 	secretData := secret.Data
-	if !cmd.noNewLine {
+	if !cmd.noNewLine && !cmd.binary {
 		secretData = posix.AddNewLine(secretData)
 	}
 
 	if cmd.outFile != "" {
-		err = ioutil.WriteFile(cmd.outFile, secretData, cmd.fileMode.FileMode())
+		// The file is written atomically, so that it never exists with other permissions than the given file mode.
+		err = atomicfile.WriteFile(cmd.outFile, secretData, cmd.fileMode.FileMode())
 		if err != nil {
 			return ErrCannotWrite(cmd.outFile, err)
 		}
@@ -1,9 +1,153 @@
 package secrethub
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/filemode"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
 )
 
 func TestReadCommand_Run(t *testing.T) {
 	// TODO SHDEV-1029 Test ReadCommand.
 }
+
+func TestReadCommand_Run_OutFile(t *testing.T) {
+	cases := map[string]struct {
+		existing     bool
+		fileMode     os.FileMode
+		noNewLine    bool
+		expectedData string
+	}{
+		"new file": {
+			fileMode:     0600,
+			expectedData: "secret\n",
+		},
+		"existing file is replaced with file mode": {
+			existing:     true,
+			fileMode:     0640,
+			expectedData: "secret\n",
+		},
+		"no newline": {
+			fileMode:     0600,
+			noNewLine:    true,
+			expectedData: "secret",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "secrethub-read-test")
+			assert.OK(t, err)
+			defer os.RemoveAll(dir)
+
+			outFile := filepath.Join(dir, "secret")
+			if tc.existing {
+				err = ioutil.WriteFile(outFile, []byte("old value"), 0644)
+				assert.OK(t, err)
+				// Make sure the file is world readable regardless of the umask.
+				err = os.Chmod(outFile, 0644)
+				assert.OK(t, err)
+			}
+
+			io := fakeui.NewIO(t)
+			cmd := ReadCommand{
+				io:        io,
+				path:      "company/app/secret",
+				outFile:   outFile,
+				fileMode:  filemode.New(tc.fileMode),
+				noNewLine: tc.noNewLine,
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+									return &api.SecretVersion{Data: []byte("secret")}, nil
+								},
+							},
+						},
+					}, nil
+				},
+			}
+
+			err = cmd.Run()
+			assert.OK(t, err)
+			assert.Equal(t, io.Out.String(), "")
+
+			data, err := ioutil.ReadFile(outFile)
+			assert.OK(t, err)
+			assert.Equal(t, string(data), tc.expectedData)
+
+			if runtime.GOOS != "windows" {
+				info, err := os.Stat(outFile)
+				assert.OK(t, err)
+				assert.Equal(t, info.Mode().Perm(), tc.fileMode)
+			}
+		})
+	}
+}
+
+func TestReadCommand_Run_Binary(t *testing.T) {
+	cases := map[string]struct {
+		binary bool
+		data   string
+		piped  bool
+		out    string
+		err    error
+	}{
+		"binary to pipe": {
+			binary: true,
+			data:   "\x00\x01binary",
+			piped:  true,
+			out:    "\x00\x01binary",
+		},
+		"binary to terminal": {
+			binary: true,
+			data:   "\x00\x01binary",
+			err:    errBinaryToTerminal,
+		},
+		"binary data to terminal without flag": {
+			data: "\xff\xfe",
+			err:  errBinaryToTerminal,
+		},
+		"text to terminal": {
+			data: "secret",
+			out:  "secret\n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			io := fakeui.NewIO(t)
+			io.Out.Piped = tc.piped
+			cmd := ReadCommand{
+				io:     io,
+				path:   "company/app/keystore",
+				binary: tc.binary,
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+									return &api.SecretVersion{Data: []byte(tc.data)}, nil
+								},
+							},
+						},
+					}, nil
+				},
+			}
+
+			err := cmd.Run()
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.Out.String(), tc.out)
+		})
+	}
+}
@@ -84,6 +84,7 @@ func (cmd *RepoExportCommand) Run() error {
 	if err != nil {
 		return err
 	}
+	excludeTrash(rootDir, cmd.path.Value())
 
 	zipFile, err := os.Create(cmd.zipName)
 	if err != nil {
@@ -0,0 +1,148 @@
+package secrethub
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// Errors
+var (
+	ErrNotInTrash           = errMain.Code("not_in_trash").ErrorPref("%s is not in the trash. Use the trash ls command to list the secrets that can be restored.")
+	ErrCannotRestoreRepo    = errMain.Code("cannot_restore_repo").Error("cannot restore a repository. Use the path of a removed secret or directory.")
+	ErrCannotRestoreTrash   = errMain.Code("cannot_restore_trash").Error("cannot restore a path in the trash. Use the path the secret or directory had before it was removed.")
+	ErrCannotRestoreVersion = errMain.Code("cannot_restore_version").Error("cannot restore a secret version. Removing a version is permanent.")
+)
+
+// RestoreCommand restores a removed secret or directory from the trash.
+type RestoreCommand struct {
+	io        ui.IO
+	path      api.Path
+	force     bool
+	newClient newClientFunc
+}
+
+// NewRestoreCommand creates a new RestoreCommand.
+func NewRestoreCommand(io ui.IO, newClient newClientFunc) *RestoreCommand {
+	return &RestoreCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *RestoreCommand) Register(r command.Registerer) {
+	clause := r.Command("restore", "Restore a removed secret or directory from the trash.")
+	clause.HelpLong("Restores a secret or directory that was removed with `" + ApplicationName + " rm` to its original path, " +
+		"including all versions of the secrets and the access rules on the directories. " +
+		"When it was removed more than once, the most recent removal is restored.\n" +
+		"\n" +
+		"Restoring to a secret that exists again requires --force, in which case the restored versions are added as new versions.")
+	clause.Arg("path", "The path the secret or directory had before it was removed.").Required().PlaceHolder(optionalDirPathPlaceHolder).SetValue(&cmd.path)
+	registerForceFlag(clause).BoolVar(&cmd.force)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run restores the most recent removal of the path.
+func (cmd *RestoreCommand) Run() error {
+	if cmd.path.HasVersion() {
+		return ErrCannotRestoreVersion
+	}
+
+	dirPath, err := cmd.path.ToDirPath()
+	if err != nil {
+		return err
+	}
+	if dirPath.IsRepoPath() {
+		return ErrCannotRestoreRepo
+	}
+	if isInTrash(dirPath.Value()) {
+		return ErrCannotRestoreTrash
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	repoPath, rel := splitRepoPath(dirPath.Value())
+	removals, err := listTrash(client, repoPath)
+	if err != nil {
+		return err
+	}
+
+	plan, removal := planRestore(removals, dirPath.Value())
+	if plan == nil {
+		return ErrNotInTrash(cmd.path)
+	}
+
+	if !cmd.force {
+		err = plan.checkDestinations(client)
+		if err != nil {
+			return err
+		}
+	}
+
+	transfers, err := plan.execute(client, false)
+	if err != nil {
+		return err
+	}
+
+	restoredSecret := len(transfers) == 1 && strings.EqualFold(transfers[0].destination, dirPath.Value())
+	if !restoredSecret {
+		err = copyACLRules(client, api.JoinPaths(removal.path, rel), dirPath.Value())
+		if err != nil {
+			return err
+		}
+	}
+
+	// Remove the removal altogether when nothing else was removed with it.
+	if len(transfers) == len(removal.secrets) {
+		err = client.Dirs().Delete(removal.path)
+	} else if restoredSecret {
+		err = client.Secrets().Delete(transfers[0].source)
+	} else {
+		err = client.Dirs().Delete(api.JoinPaths(removal.path, rel))
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Restored %s to %s.\n", pluralize("secret", "secrets", len(transfers)), cmd.path)
+	return nil
+}
+
+// planRestore plans restoring the secrets at or under the path from the most recent removal that contains them.
+// It returns nil when none of the removals contain the path.
+func planRestore(removals []trashRemoval, target string) (*copyPlan, *trashRemoval) {
+	for i, removal := range removals {
+		plan := &copyPlan{}
+		dirs := map[string]bool{}
+		for _, secret := range removal.secrets {
+			if !isSubPath(secret.path, target) {
+				continue
+			}
+
+			dir := path.Dir(secret.path)
+			if _, rel := splitRepoPath(dir); rel != "" && !dirs[dir] {
+				dirs[dir] = true
+				plan.dirs = append(plan.dirs, dir)
+			}
+			plan.transfers = append(plan.transfers, secretTransfer{
+				source:      secret.trashPath,
+				destination: secret.path,
+			})
+		}
+
+		if len(plan.transfers) > 0 {
+			return plan, &removals[i]
+		}
+	}
+	return nil, nil
+}
@@ -2,11 +2,14 @@ package secrethub
 
 import (
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
 
 	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/errio"
 	"github.com/secrethub/secrethub-go/pkg/secrethub"
 )
 
@@ -16,14 +19,20 @@ var (
 	ErrCannotRemoveRootDir = errMain.Code("cannot_remove_root_dir").Errorf(
 		"cannot remove root directory. Use the repo rm command to remove a repository",
 	)
+	ErrCannotMoveToTrash = errMain.Code("cannot_move_to_trash").ErrorPref(
+		"cannot move %s to the trash: this requires write access to the root directory of %s, " +
+			"and admin access when the directory has access rules. Use --permanent to remove it permanently",
+	)
 )
 
 // RmCommand handles removing a resource.
 type RmCommand struct {
 	path      api.Path
 	recursive bool
+	permanent bool
 	force     bool
 	io        ui.IO
+	now       func() time.Time
 	newClient newClientFunc
 }
 
@@ -31,6 +40,7 @@ type RmCommand struct {
 func NewRmCommand(io ui.IO, newClient newClientFunc) *RmCommand {
 	return &RmCommand{
 		io:        io,
+		now:       time.Now,
 		newClient: newClient,
 	}
 }
@@ -39,8 +49,16 @@ func NewRmCommand(io ui.IO, newClient newClientFunc) *RmCommand {
 func (cmd *RmCommand) Register(r command.Registerer) {
 	clause := r.Command("rm", "Remove a directory, secret or version.")
 	clause.Alias("remove")
+	clause.HelpLong("Secrets and directories are moved to the " + trashDirName + " directory of their repository, " +
+		"from where they can be restored with `" + ApplicationName + " restore` until the trash is emptied with `" + ApplicationName + " trash empty`. " +
+		"The access rules on removed directories are kept in the trash and restored with them. " +
+		"Moving to the trash requires write access to the root directory of the repository, and admin access for directories with access rules. " +
+		"Use --permanent to remove them right away.\n" +
+		"\n" +
+		"Removing a secret version, or anything that is already in the trash, is always permanent.")
 	clause.Arg("path", "The path to the resource to remove (<namespace>/<repo>[/<path>])").Required().SetValue(&cmd.path)
 	clause.Flag("recursive", "Remove directories and their contents recursively.").Short('r').BoolVar(&cmd.recursive)
+	clause.Flag("permanent", "Permanently remove the secret or directory instead of moving it to the trash.").BoolVar(&cmd.permanent)
 	registerForceFlag(clause).BoolVar(&cmd.force)
 
 	command.BindAction(clause, cmd.Run)
@@ -49,6 +67,7 @@ func (cmd *RmCommand) Register(r command.Registerer) {
 // Run removes the resource at the given path.
 // Removes a secret, secret-version or directory.
 // To remove a directory the -r flag must be set.
+// Secrets and directories are moved to the trash, unless permanent is set.
 func (cmd *RmCommand) Run() error {
 	client, err := cmd.newClient()
 	if err != nil {
@@ -65,11 +84,14 @@ func (cmd *RmCommand) Run() error {
 			return ErrCannotRemoveRootDir
 		}
 
-		_, err = client.Dirs().GetTree(dirPath.Value(), -1, false)
+		tree, err := client.Dirs().GetTree(dirPath.Value(), -1, false)
 		if err == nil {
 			if !cmd.recursive {
 				return ErrCannotRemoveDir
 			}
+			if !cmd.permanent && !isInTrash(dirPath.Value()) {
+				return cmd.moveToTrash(client, dirPath.Value(), dirPath.GetDirName(), tree.RootDir, "directory")
+			}
 			return rmDir(client, dirPath, cmd.force, cmd.io)
 		} else if !api.IsErrNotFound(err) {
 			return err
@@ -91,9 +113,48 @@ func (cmd *RmCommand) Run() error {
 		return ErrResourceNotFound(cmd.path)
 	}
 
+	if !cmd.permanent && !isInTrash(secretPath.Value()) {
+		return cmd.moveToTrash(client, secretPath.Value(), secretPath.GetSecret(), nil, "secret")
+	}
 	return rmSecret(client, secretPath, cmd.force, cmd.io)
 }
 
+// moveToTrash moves the secret, or the directory when dir is set, to the trash of its repository.
+func (cmd *RmCommand) moveToTrash(client secrethub.ClientInterface, path string, name string, dir *api.Dir, kind string) error {
+	ok, err := confirmRemoval(
+		cmd.io,
+		fmt.Sprintf("This will move the %s %s to the trash. Please type in the name of the %s to confirm", kind, path, kind),
+		cmd.force,
+		name,
+		path,
+	)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	err = moveToTrash(client, path, dir, cmd.now())
+	if statusErr, ok := err.(errio.PublicStatusError); ok && statusErr.StatusCode == http.StatusForbidden {
+		repo, _ := splitRepoPath(path)
+		return ErrCannotMoveToTrash(path, repo)
+	} else if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(
+		cmd.io.Output(),
+		"Moved the %s %s to the trash. Use `%s restore %s` to restore it.\n",
+		kind,
+		path,
+		ApplicationName,
+		path,
+	)
+
+	return nil
+}
+
 func rmSecretVersion(client secrethub.ClientInterface, secretPath api.SecretPath, force bool, io ui.IO) error {
 	version, err := secretPath.GetVersion()
 	if err != nil {
@@ -190,18 +251,16 @@ func rmDir(client secrethub.ClientInterface, dirPath api.DirPath, force bool, io
 }
 
 func askRmConfirmation(io ui.IO, confirmationText string, force bool, expected ...string) (bool, error) {
+	return confirmRemoval(io, fmt.Sprintf("[WARNING] This action cannot be undone. %s", confirmationText), force, expected...)
+}
+
+// confirmRemoval asks the user to confirm a removal by typing in one of the expected values, unless force is set.
+func confirmRemoval(io ui.IO, confirmationText string, force bool, expected ...string) (bool, error) {
 	if force {
 		return true, nil
 	}
 
-	confirmed, err := ui.ConfirmCaseInsensitive(
-		io,
-		fmt.Sprintf(
-			"[WARNING] This action cannot be undone. %s",
-			confirmationText,
-		),
-		expected...,
-	)
+	confirmed, err := ui.ConfirmCaseInsensitive(io, confirmationText, expected...)
 
 	if err == ui.ErrCannotAsk {
 		return false, ErrCannotDoWithoutForce
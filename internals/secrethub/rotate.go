@@ -0,0 +1,416 @@
+package secrethub
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/errio"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/iterator"
+)
+
+// Errors
+var (
+	errRotate                  = errio.Namespace("rotate")
+	ErrRotateNothingSelected   = errRotate.Code("nothing_selected").Error("provide the path of a secret to rotate or the namespace of which to rotate the secrets that are due with --due")
+	ErrRotatePathAndDue        = errRotate.Code("path_and_due").Error("a secret path cannot be combined with --due: --due rotates all secrets that are due in a namespace")
+	ErrRotatorFailed           = errRotate.Code("rotator_failed").ErrorPref("rotator %s failed: %s")
+	ErrRotatorEmptyValue       = errRotate.Code("rotator_empty_value").ErrorPref("rotator %s returned an empty value")
+	ErrInvalidRotationSchedule = errRotate.Code("invalid_schedule").ErrorPref("invalid rotation schedule: %s")
+	ErrRotationsFailed         = errRotate.Code("rotations_failed").ErrorPref("%s could not be rotated")
+)
+
+const (
+	// rotationRepoName is the repository in a namespace that holds the rotation schedules.
+	rotationRepoName       = "secrethub-rotation"
+	rotationSchedulesName  = "schedules"
+	rotatorGenerate        = "generate"
+	rotatorEnvPath         = "SECRETHUB_ROTATE_PATH"
+	maxRotateConsumerScans = 1000
+)
+
+// RotateCommand writes a new value to a secret, generated by a rotator.
+type RotateCommand struct {
+	io         ui.IO
+	path       api.SecretPath
+	due        api.Namespace
+	rotator    string
+	policyName string
+	length     int
+	schedule   time.Duration
+	unschedule bool
+	newClient  newClientFunc
+	now        func() time.Time
+}
+
+// NewRotateCommand creates a new RotateCommand.
+func NewRotateCommand(io ui.IO, newClient newClientFunc) *RotateCommand {
+	return &RotateCommand{
+		io:        io,
+		newClient: newClient,
+		now:       time.Now,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *RotateCommand) Register(r command.Registerer) {
+	clause := r.Command("rotate", "Write a new value to a secret and report the accounts that may need to be restarted.")
+	clause.HelpLong("A rotator provides the new value of the secret. By default, a random value is generated. " +
+		"For secrets that must also be changed elsewhere, such as database passwords or AWS access keys, " +
+		"--rotator can be set to an executable. The executable receives the current value on stdin and " +
+		"the path of the secret in the " + rotatorEnvPath + " environment variable, applies the change " +
+		"and prints the new value to stdout. The secret is only written when the executable succeeds.\n" +
+		"\n" +
+		"After the rotation, the accounts that read the previous value are listed, so that the applications " +
+		"using them can be restarted or reloaded.\n" +
+		"\n" +
+		"With --schedule, the secret is rotated periodically by running `secrethub rotate --due <namespace>`, e.g. from cron. " +
+		"The schedules are stored in the " + rotationRepoName + " repository of the namespace, so any device can run the due rotations. " +
+		"Executable rotators must be available at the same path on that device.")
+	clause.Arg("secret-path", "The path of the secret to rotate.").PlaceHolder(secretPathPlaceHolder).SetValue(&cmd.path)
+	clause.Flag("rotator", "The executable that rotates the secret, or generate to generate a random value.").Default(rotatorGenerate).StringVar(&cmd.rotator)
+	clause.Flag("policy", "The policy of the generated value. Options are: "+strings.Join(generatePolicyNames(), ", ")+". Defaults to an alphanumeric value.").EnumVar(&cmd.policyName, generatePolicyNames()...)
+	clause.Flag("length", "The length of the generated value. Defaults to "+fmt.Sprint(defaultLength)+" or the length of the policy.").IntVar(&cmd.length)
+	clause.Flag("schedule", "Rotate the secret periodically with this interval, e.g. 720h.").DurationVar(&cmd.schedule)
+	clause.Flag("unschedule", "Stop rotating the secret periodically.").BoolVar(&cmd.unschedule)
+	clause.Flag("due", "Rotate all secrets in the namespace of which the scheduled rotation is due.").PlaceHolder("<namespace>").SetValue(&cmd.due)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run rotates the secret or the secrets that are due.
+func (cmd *RotateCommand) Run() error {
+	if cmd.path != "" && cmd.due != "" {
+		return ErrRotatePathAndDue
+	}
+	if cmd.path == "" && cmd.due == "" {
+		return ErrRotateNothingSelected
+	}
+	if cmd.path.HasVersion() {
+		return errCannotWriteToVersion
+	}
+	if cmd.schedule < 0 {
+		return ErrInvalidRotationSchedule("the interval cannot be negative")
+	}
+	if cmd.schedule > 0 && cmd.unschedule {
+		return ErrFlagsConflict("--schedule and --unschedule")
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	if cmd.due != "" {
+		return cmd.rotateDue(client)
+	}
+
+	path := cmd.path.Value()
+	namespace := strings.SplitN(path, "/", 2)[0]
+	schedules, err := readRotationSchedules(client, namespace)
+	if err != nil {
+		return err
+	}
+
+	rotator, err := cmd.newRotator(cmd.rotator)
+	if err != nil {
+		return err
+	}
+
+	err = cmd.rotate(client, path, rotator)
+	if err != nil {
+		return err
+	}
+
+	now := cmd.now()
+	schedule, scheduled := schedules.Secrets[path]
+	switch {
+	case cmd.unschedule:
+		if !scheduled {
+			return nil
+		}
+		delete(schedules.Secrets, path)
+		fmt.Fprintf(cmd.io.Output(), "%s is no longer rotated periodically.\n", path)
+	case cmd.schedule > 0:
+		schedule = rotationSchedule{Interval: cmd.schedule.String(), Rotator: cmd.rotator, LastRotated: now}
+		schedules.Secrets[path] = schedule
+		fmt.Fprintf(cmd.io.Output(), "%s is rotated every %s. The next rotation is due at %s.\n", path, cmd.schedule, now.Add(cmd.schedule).Format(time.RFC3339))
+	case scheduled:
+		schedule.LastRotated = now
+		schedules.Secrets[path] = schedule
+	default:
+		return nil
+	}
+
+	return writeRotationSchedules(client, namespace, schedules)
+}
+
+// rotateDue rotates all secrets in the namespace of which the scheduled rotation is due.
+// The rotation of the other secrets continues when a rotation fails.
+func (cmd *RotateCommand) rotateDue(client secrethub.ClientInterface) error {
+	namespace := cmd.due.String()
+	schedules, err := readRotationSchedules(client, namespace)
+	if err != nil {
+		return err
+	}
+
+	now := cmd.now()
+	due, err := schedules.due(now)
+	if err != nil {
+		return err
+	}
+	if len(due) == 0 {
+		fmt.Fprintf(cmd.io.Output(), "No rotations are due in %s.\n", namespace)
+		return nil
+	}
+
+	failed := 0
+	for _, path := range due {
+		schedule := schedules.Secrets[path]
+		rotator, err := cmd.newRotator(schedule.Rotator)
+		if err == nil {
+			err = cmd.rotate(client, path, rotator)
+		}
+		if err != nil {
+			failed++
+			fmt.Fprintf(cmd.io.Output(), "Could not rotate %s: %s\n", path, err)
+			continue
+		}
+
+		schedule.LastRotated = now
+		schedules.Secrets[path] = schedule
+	}
+
+	err = writeRotationSchedules(client, namespace, schedules)
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return ErrRotationsFailed(pluralize("secret", "secrets", failed))
+	}
+	return nil
+}
+
+// rotate writes a new value from the rotator to the secret and prints the accounts that read the previous value.
+func (cmd *RotateCommand) rotate(client secrethub.ClientInterface, path string, rotator rotator) error {
+	var current []byte
+	var since time.Time
+	previous, err := client.Secrets().Versions().GetWithData(path)
+	if err == nil {
+		current = previous.Data
+		since = previous.CreatedAt
+	} else if err != api.ErrSecretNotFound {
+		return err
+	}
+
+	value, err := rotator.Rotate(path, current)
+	if err != nil {
+		return err
+	}
+
+	version, err := client.Secrets().Write(path, value)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.io.Output(), "%s has been rotated to version %d.\n", path, version.Version)
+
+	if previous == nil {
+		return nil
+	}
+
+	consumers, err := rotateConsumers(client, path, since)
+	if err != nil {
+		fmt.Fprintf(cmd.io.Output(), "Could not determine the accounts that read the previous value: %s\n", err)
+		return nil
+	}
+	if len(consumers) > 0 {
+		fmt.Fprintf(cmd.io.Output(), "The following accounts read the previous value and may need to be restarted or reloaded:\n  %s\n", strings.Join(consumers, "\n  "))
+	}
+	return nil
+}
+
+// newRotator returns the rotator with the given name, which is either generate or the path to an executable.
+func (cmd *RotateCommand) newRotator(name string) (rotator, error) {
+	if name != "" && name != rotatorGenerate {
+		return execRotator{command: name}, nil
+	}
+
+	policy := generatePolicy{charsets: []string{"alphanumeric"}}
+	if cmd.policyName != "" {
+		var err error
+		policy, err = getGeneratePolicy(cmd.policyName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cmd.length > 0 {
+		policy.length = cmd.length
+	}
+	if policy.length == 0 {
+		policy.length = defaultLength
+	}
+	if policy.length <= 0 {
+		return nil, ErrInvalidRandLength
+	}
+	return generateRotator{policy: policy}, nil
+}
+
+// rotateConsumers returns the accounts that read the secret since the given time, in alphabetical order.
+// Only the most recent audit events are checked.
+func rotateConsumers(client secrethub.ClientInterface, path string, since time.Time) ([]string, error) {
+	iter := client.Secrets().EventIterator(path, &secrethub.AuditEventIteratorParams{})
+	accounts := map[string]bool{}
+	for i := 0; i < maxRotateConsumerScans; i++ {
+		event, err := iter.Next()
+		if err == iterator.Done {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		if event.LoggedAt.Before(since) {
+			break
+		}
+		if event.Action != api.AuditActionRead {
+			continue
+		}
+
+		actor, err := getAuditActor(event)
+		if err != nil {
+			return nil, err
+		}
+		accounts[actor] = true
+	}
+
+	consumers := make([]string, 0, len(accounts))
+	for account := range accounts {
+		consumers = append(consumers, account)
+	}
+	sort.Strings(consumers)
+	return consumers, nil
+}
+
+// rotator provides the new value of a secret.
+type rotator interface {
+	Rotate(path string, current []byte) ([]byte, error)
+}
+
+// generateRotator generates a random value that complies with a policy.
+type generateRotator struct {
+	policy generatePolicy
+}
+
+// Rotate generates a new value.
+func (r generateRotator) Rotate(path string, current []byte) ([]byte, error) {
+	generator, err := r.policy.generator()
+	if err != nil {
+		return nil, err
+	}
+	return generator.Generate(r.policy.length)
+}
+
+// execRotator runs an executable that rotates the secret.
+type execRotator struct {
+	command string
+}
+
+// Rotate runs the executable with the current value on stdin and returns its output without the trailing newline.
+func (r execRotator) Rotate(path string, current []byte) ([]byte, error) {
+	var stdout bytes.Buffer
+	rotator := exec.Command(r.command)
+	rotator.Env = append(os.Environ(), rotatorEnvPath+"="+path)
+	rotator.Stdin = bytes.NewReader(current)
+	rotator.Stdout = &stdout
+	rotator.Stderr = os.Stderr
+
+	err := rotator.Run()
+	if err != nil {
+		return nil, ErrRotatorFailed(r.command, err)
+	}
+
+	value := bytes.TrimSuffix(bytes.TrimSuffix(stdout.Bytes(), []byte("\n")), []byte("\r"))
+	if len(value) == 0 {
+		return nil, ErrRotatorEmptyValue(r.command)
+	}
+	return value, nil
+}
+
+// rotationSchedules are the periodic rotations of the secrets in a namespace.
+type rotationSchedules struct {
+	Secrets map[string]rotationSchedule `json:"secrets"`
+}
+
+// rotationSchedule configures the periodic rotation of a secret.
+type rotationSchedule struct {
+	Interval    string    `json:"interval"`
+	Rotator     string    `json:"rotator"`
+	LastRotated time.Time `json:"last_rotated"`
+}
+
+// due returns the paths of the secrets of which the rotation is due, in alphabetical order.
+func (s *rotationSchedules) due(now time.Time) ([]string, error) {
+	var due []string
+	for path, schedule := range s.Secrets {
+		interval, err := time.ParseDuration(schedule.Interval)
+		if err != nil {
+			return nil, ErrInvalidRotationSchedule(fmt.Sprintf("%s: %s", path, err))
+		}
+		if !now.Before(schedule.LastRotated.Add(interval)) {
+			due = append(due, path)
+		}
+	}
+	sort.Strings(due)
+	return due, nil
+}
+
+// rotationSchedulesPath returns the path of the secret that holds the rotation schedules of a namespace.
+func rotationSchedulesPath(namespace string) string {
+	return api.JoinPaths(namespace, rotationRepoName, rotationSchedulesName)
+}
+
+// readRotationSchedules reads the rotation schedules of a namespace. It returns empty schedules when there are none.
+func readRotationSchedules(client secrethub.ClientInterface, namespace string) (*rotationSchedules, error) {
+	schedules := &rotationSchedules{Secrets: map[string]rotationSchedule{}}
+	secret, err := client.Secrets().Versions().GetWithData(rotationSchedulesPath(namespace))
+	if err == api.ErrSecretNotFound || err == api.ErrRepoNotFound || api.IsErrNotFound(err) {
+		return schedules, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(secret.Data, schedules)
+	if err != nil {
+		return nil, ErrInvalidRotationSchedule(err)
+	}
+	if schedules.Secrets == nil {
+		schedules.Secrets = map[string]rotationSchedule{}
+	}
+	return schedules, nil
+}
+
+// writeRotationSchedules writes the rotation schedules of a namespace, creating the repository when needed.
+func writeRotationSchedules(client secrethub.ClientInterface, namespace string, schedules *rotationSchedules) error {
+	data, err := json.MarshalIndent(schedules, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Repos().Create(api.JoinPaths(namespace, rotationRepoName))
+	if err != nil && err != api.ErrRepoAlreadyExists {
+		return err
+	}
+
+	_, err = client.Secrets().Write(rotationSchedulesPath(namespace), data)
+	return err
+}
@@ -0,0 +1,93 @@
+package secrethub
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestRotationSchedules_Due(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	schedules := rotationSchedules{
+		Secrets: map[string]rotationSchedule{
+			"company/app/db/password": {Interval: "720h", LastRotated: now.Add(-721 * time.Hour)},
+			"company/app/api_key":     {Interval: "24h", LastRotated: now.Add(-24 * time.Hour)},
+			"company/app/token":       {Interval: "24h", LastRotated: now.Add(-time.Hour)},
+		},
+	}
+
+	due, err := schedules.due(now)
+	assert.OK(t, err)
+	assert.Equal(t, due, []string{"company/app/api_key", "company/app/db/password"})
+
+	schedules.Secrets["company/app/token"] = rotationSchedule{Interval: "monthly"}
+	_, err = schedules.due(now)
+	assert.Equal(t, err == nil, false)
+}
+
+func TestRotateConsumers(t *testing.T) {
+	since := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	event := func(action string, username string, loggedAt time.Time) api.Audit {
+		return api.Audit{
+			Action:   action,
+			Actor:    api.AuditActor{Type: "user", User: &api.User{Username: username}},
+			LoggedAt: loggedAt,
+		}
+	}
+
+	client := fakeclient.Client{
+		SecretService: &fakeclient.SecretService{
+			AuditEventIterator: &fakeclient.AuditEventIterator{
+				Events: []api.Audit{
+					event(api.AuditActionRead, "web", since.Add(3*time.Hour)),
+					event(api.AuditActionRead, "api", since.Add(2*time.Hour)),
+					event(api.AuditActionRead, "web", since.Add(time.Hour)),
+					event(api.AuditActionCreate, "dev", since),
+					event(api.AuditActionRead, "old-worker", since.Add(-time.Hour)),
+				},
+			},
+		},
+	}
+
+	consumers, err := rotateConsumers(client, "company/app/db/password", since)
+	assert.OK(t, err)
+	assert.Equal(t, consumers, []string{"api", "web"})
+}
+
+func TestGenerateRotator(t *testing.T) {
+	value, err := generateRotator{policy: generatePolicy{charsets: []string{"alphanumeric"}, length: 30}}.Rotate("company/app/token", []byte("old"))
+	assert.OK(t, err)
+	assert.Equal(t, len(value), 30)
+}
+
+func TestExecRotator(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the rotator is a shell script")
+	}
+
+	dir, err := ioutil.TempDir("", "secrethub-rotate")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	script := filepath.Join(dir, "rotator.sh")
+	err = ioutil.WriteFile(script, []byte("#!/bin/sh\necho \"$(cat)-rotated-$"+rotatorEnvPath+"\"\n"), 0700)
+	assert.OK(t, err)
+
+	value, err := execRotator{command: script}.Rotate("company/app/token", []byte("old"))
+	assert.OK(t, err)
+	assert.Equal(t, string(value), "old-rotated-company/app/token")
+
+	empty := filepath.Join(dir, "empty.sh")
+	err = ioutil.WriteFile(empty, []byte("#!/bin/sh\necho\n"), 0700)
+	assert.OK(t, err)
+
+	_, err = execRotator{command: empty}.Rotate("company/app/token", nil)
+	assert.Equal(t, err, ErrRotatorEmptyValue(empty))
+}
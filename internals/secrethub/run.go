@@ -1,15 +1,16 @@
 package secrethub
 
 import (
-	"fmt"
 	"os"
 	"os/exec"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
+	"github.com/secrethub/secrethub-cli/internals/cli"
 	"github.com/secrethub/secrethub-cli/internals/cli/masker"
-	"github.com/secrethub/secrethub-cli/internals/secrethub/tpl"
 
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 
@@ -17,6 +18,7 @@ import (
 
 	"github.com/secrethub/secrethub-cli/internals/cli/validation"
 	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/tpl"
 )
 
 // Errors
@@ -35,7 +37,7 @@ var (
 
 const (
 	defaultEnvFile = "secrethub.env"
-	maskString     = "<redacted by SecretHub>"
+	maskString     = masker.DefaultMaskString
 	// templateVarEnvVarPrefix is used to prefix environment variables
 	// that should be used as template variables.
 	templateVarEnvVarPrefix = "SECRETHUB_VAR_"
@@ -55,42 +57,101 @@ type RunCommand struct {
 	noMasking            bool
 	maskerOptions        masker.Options
 	newClient            newClientFunc
+	secretCache          SecretCache
 	ignoreMissingSecrets bool
+	watch                bool
+	watchInterval        time.Duration
+	reloadSignal         string
+	secretFiles          map[string]string
+	tempDir              string
+	killTimeout          time.Duration
+	startupRetries       int
+	startupTimeout       time.Duration
+	maxCacheAge          time.Duration
+	printResolution      bool
+	reaper               *reaper
+	logger               cli.Logger
 }
 
 // NewRunCommand creates a new RunCommand.
-func NewRunCommand(io ui.IO, newClient newClientFunc) *RunCommand {
+func NewRunCommand(io ui.IO, newClient newClientFunc, secretCache SecretCache, logger cli.Logger) *RunCommand {
 	return &RunCommand{
 		io:          io,
 		osEnv:       os.Environ(),
 		environment: newEnvironment(io, newClient),
 		newClient:   newClient,
+		secretCache: secretCache,
+		secretFiles: make(map[string]string),
+		tempDir:     secureTempDir(),
+		logger:      logger,
 	}
 }
 
 // Register registers the command, arguments and flags on the provided Registerer.
 func (cmd *RunCommand) Register(r command.Registerer) {
 	const helpShort = "Pass secrets as environment variables to a process."
-	const helpLong = "To protect against secrets leaking via stdout and stderr, those output streams are monitored for secrets. Detected secrets are automatically masked by replacing them with \"" + maskString + "\". " +
+	const helpLong = "To protect against secrets leaking via stdout and stderr, those output streams are monitored for secrets. Detected secrets are automatically masked by replacing them with \"" + maskString + "\" or the text set with --mask-string. " +
+		"Secrets that are split across multiple writes are also masked, as long as the writes happen within the masking buffer period. " +
 		"The output is buffered to scan for secrets and can be adjusted using the masking-buffer-period flag. " +
-		"You should regard the masking as a best effort attempt and should always prevent secrets ending up on stdout and stderr in the first place."
+		"You should regard the masking as a best effort attempt and should always prevent secrets ending up on stdout and stderr in the first place.\n\n" +
+		"Environment variables of the current environment with a value of the form secrethub://<path>, e.g. DB_PASSWORD=secrethub://company/app/db/password, are passed on with the secret at the path as their value. " +
+		"This allows orchestration tools to pass references to secrets without an env file. Use --no-env-passthrough to pass on these values as they are.\n\n" +
+		"The environment of the command is merged from multiple sources. In order of increasing precedence, these are: the current environment, --secrets-dir, the env files in the order they are given with --env-file, " +
+		"secret references in the current environment, --envar and --env. Use --print-resolution to show where each variable is sourced from.\n\n" +
+		"By default, all environment variables of the current environment are passed on to the command. " +
+		"Use --allow to only pass on the given variables and --deny-pattern to leave out the variables that match a pattern, e.g. --allow PATH,HOME --deny-pattern 'AWS_*'. " +
+		"Variables that are sourced by SecretHub, e.g. from an env file, are always passed on.\n\n" +
+		"With --watch, the secrets are read again every watch interval and the command is restarted when any of the values changed. " +
+		"Use --reload-signal to send a signal to the command instead of restarting it. " +
+		"The environment of a running command cannot be changed, so this is only useful for commands that reload their secrets themselves, e.g. from secret files.\n\n" +
+		"For commands that can only read credentials from files, --secret-file writes a secret to a file for as long as the command runs and sets an environment variable to the path of the file. " +
+		"Without a file path, the secret is written to a temporary directory, which is kept in memory (" + tmpfsDir + ") when available. " +
+		"The files are overwritten and removed when the command exits.\n\n" +
+		"When the secrets cannot be read because the SecretHub API cannot be reached or returns a server error, the command is not started. " +
+		"Use --startup-retries and --startup-timeout to retry reading the secrets with exponential backoff instead, e.g. to prevent a container from crash-looping during a brief network outage. " +
+		"With --max-cache-age, the secrets that are read are also stored in the encrypted local cache and the command is started with their last-known values when the API cannot be reached. " +
+		"A warning is logged for every secret that is read from the cache and cached values that are older than the maximum age are never used.\n\n" +
+		"All signals are passed on to the command. When the command does not exit within the kill timeout after an interrupt or termination signal, it is killed. " +
+		"The exit status of the command is returned, or 128 plus the signal number when the command was stopped by a signal. " +
+		"When running as PID 1, e.g. as the entrypoint of a container, exited orphan processes are also reaped."
 
 	clause := r.Command("run", helpShort)
 	clause.HelpLong(helpLong)
 	clause.Alias("exec")
 	clause.Arg("command", "The command to execute").Required().StringsVar(&cmd.command)
-	clause.Flag("no-masking", "Disable masking of secrets on stdout and stderr").BoolVar(&cmd.noMasking)
+	clause.Flag("mask-output", "Mask secrets on stdout and stderr. This is enabled by default, use --no-mask-output to disable it.").SetValue(invertedBoolValue{target: &cmd.noMasking})
+	clause.Flag("no-masking", "Disable masking of secrets on stdout and stderr").Hidden().BoolVar(&cmd.noMasking) // Alias of --no-mask-output (for backwards compatibility)
+	clause.Flag("mask-string", "The text that replaces masked secrets, e.g. ***.").Default(maskString).StringVar(&cmd.maskerOptions.MaskString)
 	clause.Flag("no-output-buffering", "Disable output buffering. This increases output responsiveness, but decreases the probability that secrets get masked.").BoolVar(&cmd.maskerOptions.DisableBuffer)
 	clause.Flag("masking-buffer-period", "The time period for which output is buffered. A higher value increases the probability that secrets get masked but decreases output responsiveness.").Default("50ms").DurationVar(&cmd.maskerOptions.BufferDelay)
 	clause.Flag("ignore-missing-secrets", "Do not return an error when a secret does not exist and use an empty value instead.").BoolVar(&cmd.ignoreMissingSecrets)
+	clause.Flag("secret-file", "Write a secret to a file and set an environment variable to its path with `NAME=<path>[:<file>]`, e.g. --secret-file DB_CERT=company/app/db/cert:/run/secrets/db.pem").StringMapVar(&cmd.secretFiles)
+	clause.Flag("kill-timeout", "The time the command gets to exit after an interrupt or termination signal before it is killed. Set to 0 to never kill the command.").Default("10s").DurationVar(&cmd.killTimeout)
+	clause.Flag("startup-retries", "The maximum number of times reading a secret is retried when it fails because of a network or server error before the command is started. Defaults to no retries, or as many retries as fit in the --startup-timeout when it is set.").PlaceHolder("0").IntVar(&cmd.startupRetries)
+	clause.Flag("startup-timeout", "The time after which reading the secrets before the command is started is no longer retried, e.g. 2m. Defaults to no timeout.").PlaceHolder("0").DurationVar(&cmd.startupTimeout)
+	clause.Flag("max-cache-age", "Start the command with the cached values of the secrets when the SecretHub API cannot be reached, as long as they were cached less than this duration ago, e.g. 24h. Turned off by default.").PlaceHolder("0").DurationVar(&cmd.maxCacheAge)
+	clause.Flag("watch", "Poll the secrets for changes and restart the command when a value changes.").BoolVar(&cmd.watch)
+	clause.Flag("watch-interval", "The time between two polls for changed secrets when using --watch.").Default("1m").DurationVar(&cmd.watchInterval)
+	clause.Flag("reload-signal", "Send this signal, e.g. SIGHUP, to the command instead of restarting it when a secret changes. Can only be used together with --watch.").StringVar(&cmd.reloadSignal)
 	cmd.environment.register(clause)
+	cmd.environment.filter.registerFlags(clause)
+	clause.Flag("print-resolution", "Print for every environment variable the source it is read from and the sources it overrides, instead of running the command.").BoolVar(&cmd.printResolution)
 	command.BindAction(clause, cmd.Run)
 }
 
 // Run reads files from the .secretsenv/<env-name> directory, sets them as environment variables and runs the given command.
 // Note that the environment variables are only passed to the child process and not exported globally, which is nice.
 func (cmd *RunCommand) Run() error {
-	environment, secrets, err := cmd.sourceEnvironment()
+	if cmd.reloadSignal != "" && !cmd.watch {
+		return ErrReloadSignalWithoutWatch
+	}
+
+	if cmd.printResolution {
+		return cmd.environment.printResolution(cmd.io.Output())
+	}
+
+	sr := cmd.startupSecretReader()
+	environment, secrets, err := cmd.resolveEnvironment(sr)
 	if err != nil {
 		return err
 	}
@@ -100,30 +161,23 @@ func (cmd *RunCommand) Run() error {
 		cmd.command = strings.Split(cmd.command[0], " ")
 	}
 
-	sequences := make([][]byte, 0, len(secrets))
-	for _, val := range secrets {
-		if val != "" {
-			sequences = append(sequences, []byte(val))
-		}
+	files, err := cmd.writeSecretFiles(sr)
+	if err != nil {
+		return err
 	}
-	m := masker.New(sequences, &cmd.maskerOptions)
+	defer files.remove()
+	environment = append(environment, files.env()...)
+	secrets = append(secrets, files.values()...)
 
-	command := exec.Command(cmd.command[0], cmd.command[1:]...)
-	command.Env = environment
-	command.Stdin = os.Stdin
-	if cmd.noMasking {
-		command.Stdout = cmd.io.Stdout()
-		command.Stderr = os.Stderr
-	} else {
-		command.Stdout = m.AddStream(cmd.io.Stdout())
-		command.Stderr = m.AddStream(os.Stderr)
+	cmd.reaper = newReaper()
 
-		go m.Start()
+	if cmd.watch {
+		return cmd.runWatch(environment, secrets, files)
 	}
 
-	err = command.Start()
+	process, err := cmd.start(environment, secrets)
 	if err != nil {
-		return ErrStartFailed(err)
+		return err
 	}
 
 	done := make(chan bool, 1)
@@ -133,39 +187,149 @@ func (cmd *RunCommand) Run() error {
 	signal.Notify(signals)
 
 	go func() {
-		select {
-		case s := <-signals:
-			err := command.Process.Signal(s)
-			if err != nil && !strings.Contains(err.Error(), "process already finished") {
-				fmt.Fprintln(os.Stderr, ErrSignalFailed(err))
+		for {
+			select {
+			case s := <-signals:
+				process.forward(s, cmd.killTimeout)
+			case <-done:
+				signal.Stop(signals)
+				return
 			}
-		case <-done:
-			signal.Stop(signals)
-			return
 		}
 	}()
 
-	commandErr := command.Wait()
+	err = process.wait()
 	done <- true
 
-	if !cmd.noMasking {
-		err := m.Stop()
+	// The files are removed before exiting, as deferred functions do not run on an exit.
+	files.remove()
+	return exitWithStatus(err)
+}
+
+// childProcess is a started command of which the output is masked.
+type childProcess struct {
+	command *exec.Cmd
+	masker  *masker.Masker
+	// reaped receives the wait status of the process when it is reaped by the reaper instead of by the command.
+	reaped <-chan syscall.WaitStatus
+	// done is closed when the process has exited.
+	done   chan struct{}
+	logger cli.Logger
+}
+
+// start starts the command with the given environment and masks the secrets in its output.
+func (cmd *RunCommand) start(environment []string, secrets []string) (*childProcess, error) {
+	command := exec.Command(cmd.command[0], cmd.command[1:]...)
+	command.Env = environment
+	command.Stdin = os.Stdin
+
+	process := &childProcess{
+		command: command,
+		done:    make(chan struct{}),
+		logger:  cmd.logger,
+	}
+
+	if cmd.noMasking {
+		command.Stdout = cmd.io.Stdout()
+		command.Stderr = os.Stderr
+	} else {
+		sequences := make([][]byte, 0, len(secrets))
+		for _, val := range secrets {
+			if val != "" {
+				sequences = append(sequences, []byte(val))
+			}
+		}
+		process.masker = masker.New(sequences, &cmd.maskerOptions)
+
+		command.Stdout = process.masker.AddStream(cmd.io.Stdout())
+		command.Stderr = process.masker.AddStream(os.Stderr)
+
+		go process.masker.Start()
+	}
+
+	reaped, err := cmd.reaper.start(command)
+	if err != nil {
+		return nil, ErrStartFailed(err)
+	}
+	process.reaped = reaped
+	return process, nil
+}
+
+// wait waits for the process to exit and flushes its masked output.
+// An error of the masker takes precedence over the error of the command.
+func (p *childProcess) wait() error {
+	commandErr := p.command.Wait()
+	if p.reaped != nil && isNoChildProcessErr(commandErr) {
+		// The reaper has waited for the process, so it knows the exit status.
+		status := <-p.reaped
+		commandErr = nil
+		if status.ExitStatus() != 0 {
+			commandErr = reapedExitError{status: status}
+		}
+	}
+	close(p.done)
+
+	if p.masker != nil {
+		err := p.masker.Stop()
 		if err != nil {
 			return err
 		}
 	}
 
+	return commandErr
+}
+
+// forward passes the signal to the process. After an interrupt or termination
+// signal, the process is killed when it has not exited within the kill timeout.
+func (p *childProcess) forward(s os.Signal, killTimeout time.Duration) {
+	if !isForwardedSignal(s) {
+		return
+	}
+
+	p.signal(s)
+
+	if killTimeout > 0 && (s == os.Interrupt || s == syscall.SIGTERM) {
+		go func() {
+			select {
+			case <-p.done:
+			case <-time.After(killTimeout):
+				p.logger.With("timeout", killTimeout).Warningf("The command did not exit within the kill timeout. Killing it.")
+				_ = p.command.Process.Kill()
+			}
+		}()
+	}
+}
+
+// signal passes the signal to the process.
+func (p *childProcess) signal(s os.Signal) {
+	err := p.command.Process.Signal(s)
+	if err != nil && !strings.Contains(err.Error(), "process already finished") {
+		p.logger.With("signal", s, "error", err).Errorf("Could not pass the signal to the command")
+	}
+}
+
+// exitWithStatus exits with the status code of the command when it exited with an error.
+// When the command was stopped by a signal, it exits with 128 plus the number of the signal, like a shell does.
+func exitWithStatus(commandErr error) error {
 	if commandErr != nil {
+		var waitStatus syscall.WaitStatus
+		var ok bool
+
 		// Check if the program exited with an error
-		exitErr, ok := commandErr.(*exec.ExitError)
+		switch err := commandErr.(type) {
+		case *exec.ExitError:
+			waitStatus, ok = err.Sys().(syscall.WaitStatus)
+		case reapedExitError:
+			waitStatus, ok = err.status, true
+		}
+
 		if ok {
-			waitStatus, ok := exitErr.Sys().(syscall.WaitStatus)
-			if ok {
-				// Return the status code returned by the process
-				os.Exit(waitStatus.ExitStatus())
-				return nil
+			// Return the status code returned by the process
+			if waitStatus.Signaled() {
+				os.Exit(128 + int(waitStatus.Signal()))
 			}
-
+			os.Exit(waitStatus.ExitStatus())
+			return nil
 		}
 		return commandErr
 	}
@@ -173,21 +337,59 @@ func (cmd *RunCommand) Run() error {
 	return nil
 }
 
-// sourceEnvironment returns the environment of the subcommand, with all the secrets sourced
-// and the secret values that need to be masked.
-func (cmd *RunCommand) sourceEnvironment() ([]string, []string, error) {
-	_, passthroughEnv := parseKeyValueStringsToMap(cmd.osEnv)
-	newEnv := map[string]string{}
+// reapedExitError is the error of a command that exited with an error and was reaped by the reaper.
+type reapedExitError struct {
+	status syscall.WaitStatus
+}
 
-	envValues, err := cmd.environment.env()
-	if err != nil {
-		return nil, nil, err
+// Error implements the error interface.
+func (e reapedExitError) Error() string {
+	if e.status.Signaled() {
+		return "signal: " + e.status.Signal().String()
 	}
+	return "exit status " + strconv.Itoa(e.status.ExitStatus())
+}
 
+// isNoChildProcessErr returns whether the error is returned because the process has already been waited for.
+func isNoChildProcessErr(err error) bool {
+	sysErr, ok := err.(*os.SyscallError)
+	return ok && sysErr.Err == syscall.ECHILD
+}
+
+// secretReader returns the secret reader for the secrets of the command.
+// Polling for changed secrets does not use the cache, as it would return the same values every time.
+func (cmd *RunCommand) secretReader(useCache bool) tpl.SecretReader {
 	var sr tpl.SecretReader = newSecretReader(cmd.newClient)
+	if useCache {
+		sr = newCachedSecretReader(sr, cmd.secretCache)
+	}
 	if cmd.ignoreMissingSecrets {
 		sr = newIgnoreMissingSecretReader(sr)
 	}
+	return sr
+}
+
+// resolveEnvironment returns the environment of the subcommand with the secrets read
+// by the given secret reader and the secret values that need to be masked.
+func (cmd *RunCommand) resolveEnvironment(sr tpl.SecretReader) ([]string, []string, error) {
+	_, unparsableEnv := parseKeyValueStringsToMap(cmd.osEnv)
+	var passthroughEnv []string
+	for _, line := range unparsableEnv {
+		ok, err := cmd.environment.filter.passes(strings.SplitN(line, "=", 2)[0])
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			passthroughEnv = append(passthroughEnv, line)
+		}
+	}
+	newEnv := map[string]string{}
+
+	envValues, err := cmd.environment.env()
+	if err != nil {
+		return nil, nil, err
+	}
+
 	secretReader := newBufferedSecretReader(sr)
 
 	for name, value := range envValues {
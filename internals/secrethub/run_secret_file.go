@@ -0,0 +1,250 @@
+package secrethub
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/atomicfile"
+	"github.com/secrethub/secrethub-cli/internals/cli/validation"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/tpl"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// Errors
+var (
+	ErrInvalidSecretFile = errRun.Code("invalid_secret_file").ErrorPref("invalid secret file for %s: %s")
+	ErrSecretFileExists  = errRun.Code("secret_file_exists").ErrorPref("cannot write the secret of %s to %s: the file already exists")
+	ErrWriteSecretFile   = errRun.Code("secret_file_write_failed").ErrorPref("could not write the secret of %s to %s: %s")
+)
+
+// secretFile is a secret that is written to a file for the lifetime of the command.
+// The path of the file is passed to the command in an environment variable.
+type secretFile struct {
+	envVar     string
+	secretPath string
+	// target is the file to write the secret to. When it is empty, the secret is written to a temporary directory.
+	target string
+}
+
+// parseSecretFile parses a secret file in the format <path>[:<version>][:<file>].
+func parseSecretFile(envVar string, value string) (secretFile, error) {
+	err := validation.ValidateEnvarName(envVar)
+	if err != nil {
+		return secretFile{}, err
+	}
+
+	file := secretFile{
+		envVar: envVar,
+	}
+
+	// Split at most twice, so a file path can contain a colon, e.g. C:\secrets\db.pem.
+	parts := strings.SplitN(value, ":", 3)
+	file.secretPath = parts[0]
+	rest := parts[1:]
+	if len(rest) > 0 && isSecretVersion(rest[0]) {
+		file.secretPath += ":" + rest[0]
+		rest = rest[1:]
+	}
+	if len(rest) > 0 {
+		file.target = strings.Join(rest, ":")
+		if file.target == "" {
+			return secretFile{}, ErrInvalidSecretFile(envVar, "the file path is empty")
+		}
+	}
+
+	err = api.ValidateSecretPath(file.secretPath)
+	if err != nil {
+		return secretFile{}, ErrInvalidSecretFile(envVar, err)
+	}
+
+	return file, nil
+}
+
+// isSecretVersion returns whether the value is a version of a secret, e.g. 3 or latest.
+func isSecretVersion(value string) bool {
+	if value == "latest" {
+		return true
+	}
+	_, err := strconv.Atoi(value)
+	return err == nil
+}
+
+// secretFileSet is the set of secret files that have been written for a command.
+type secretFileSet struct {
+	files []secretFile
+	// paths are the paths of the written files, by environment variable.
+	paths map[string]string
+	// data is the content of the written files, by environment variable.
+	data map[string][]byte
+	// tempDir is the temporary directory created for secret files without a target.
+	tempDir string
+	// dirs are the directories created for the targets of secret files, in the order they were created.
+	dirs []string
+}
+
+// writeSecretFiles reads the secrets of the --secret-file flags and writes them to their files.
+// Secret files without a target are written to a new directory in the given temporary directory.
+func (cmd *RunCommand) writeSecretFiles(sr tpl.SecretReader) (*secretFileSet, error) {
+	set := &secretFileSet{
+		paths: make(map[string]string, len(cmd.secretFiles)),
+		data:  make(map[string][]byte, len(cmd.secretFiles)),
+	}
+
+	for envVar, value := range cmd.secretFiles {
+		file, err := parseSecretFile(envVar, value)
+		if err != nil {
+			return nil, err
+		}
+		set.files = append(set.files, file)
+	}
+	sort.Slice(set.files, func(i, j int) bool {
+		return set.files[i].envVar < set.files[j].envVar
+	})
+
+	for _, file := range set.files {
+		path := file.target
+		if path == "" {
+			if set.tempDir == "" {
+				dir, err := ioutil.TempDir(cmd.tempDir, "secrethub-run-")
+				if err != nil {
+					set.remove()
+					return nil, ErrWriteSecretFile(file.envVar, cmd.tempDir, err)
+				}
+				set.tempDir = dir
+			}
+			// Every file gets its own directory, so the file can have the name of the secret.
+			path = filepath.Join(set.tempDir, file.envVar, api.SecretPath(file.secretPath).GetSecret())
+		}
+
+		path, err := filepath.Abs(path)
+		if err != nil {
+			set.remove()
+			return nil, ErrWriteSecretFile(file.envVar, path, err)
+		}
+
+		_, err = os.Lstat(path)
+		if err == nil {
+			set.remove()
+			return nil, ErrSecretFileExists(file.envVar, path)
+		}
+
+		secret, err := sr.ReadSecret(file.secretPath)
+		if err != nil {
+			set.remove()
+			return nil, err
+		}
+
+		err = set.write(file, path, []byte(secret))
+		if err != nil {
+			set.remove()
+			return nil, err
+		}
+	}
+
+	return set, nil
+}
+
+// write writes the secret of the file to the given path.
+// The file is replaced atomically, so a command reading the file never sees a partially written secret.
+func (s *secretFileSet) write(file secretFile, path string, data []byte) error {
+	err := s.mkdirAll(filepath.Dir(path))
+	if err != nil {
+		return ErrWriteSecretFile(file.envVar, path, err)
+	}
+
+	// The path is registered before writing, so the file is also removed when writing fails after it was moved into place.
+	s.paths[file.envVar] = path
+	err = atomicfile.WriteFile(path, data, 0600)
+	if err != nil {
+		return ErrWriteSecretFile(file.envVar, path, err)
+	}
+	s.data[file.envVar] = data
+	return nil
+}
+
+// mkdirAll creates the directory and its missing parents and registers them, so they are removed again.
+func (s *secretFileSet) mkdirAll(dir string) error {
+	var missing []string
+	for parent := dir; ; parent = filepath.Dir(parent) {
+		_, err := os.Lstat(parent)
+		if !os.IsNotExist(err) || parent == filepath.Dir(parent) {
+			break
+		}
+		missing = append(missing, parent)
+	}
+
+	err := os.MkdirAll(dir, 0700)
+	// Directories that were created before an error are also registered.
+	for i := len(missing) - 1; i >= 0; i-- {
+		_, statErr := os.Lstat(missing[i])
+		if statErr == nil {
+			s.dirs = append(s.dirs, missing[i])
+		}
+	}
+	return err
+}
+
+// update reads the secrets again and rewrites the files of which the secret changed.
+// It returns whether any of the files changed.
+func (s *secretFileSet) update(sr tpl.SecretReader) (bool, error) {
+	changed := false
+	for _, file := range s.files {
+		secret, err := sr.ReadSecret(file.secretPath)
+		if err != nil {
+			return changed, err
+		}
+		if bytes.Equal([]byte(secret), s.data[file.envVar]) {
+			continue
+		}
+
+		err = s.write(file, s.paths[file.envVar], []byte(secret))
+		if err != nil {
+			return changed, err
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// env returns the environment variables that contain the paths of the secret files.
+func (s *secretFileSet) env() []string {
+	env := make([]string, 0, len(s.files))
+	for _, file := range s.files {
+		env = append(env, file.envVar+"="+s.paths[file.envVar])
+	}
+	return env
+}
+
+// values returns the contents of the secret files, so they can be masked.
+func (s *secretFileSet) values() []string {
+	values := make([]string, 0, len(s.files))
+	for _, file := range s.files {
+		values = append(values, string(s.data[file.envVar]))
+	}
+	return values
+}
+
+// remove overwrites the secret files and removes them. It is safe to call remove more than once.
+func (s *secretFileSet) remove() {
+	for envVar, path := range s.paths {
+		overwriteFile(path)
+		_ = os.Remove(path)
+		delete(s.paths, envVar)
+	}
+	if s.tempDir != "" {
+		_ = os.RemoveAll(s.tempDir)
+		s.tempDir = ""
+	}
+	// The directories are removed in the reverse order of their creation, so children are removed before their parents.
+	// Directories that are not empty, e.g. because the command wrote other files to them, are kept.
+	for i := len(s.dirs) - 1; i >= 0; i-- {
+		_ = os.Remove(s.dirs[i])
+	}
+	s.dirs = nil
+}
@@ -0,0 +1,158 @@
+package secrethub
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/secrethub/tpl"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestParseSecretFile(t *testing.T) {
+	cases := map[string]struct {
+		value    string
+		expected secretFile
+		err      error
+	}{
+		"path": {
+			value: "company/app/db/cert",
+			expected: secretFile{
+				envVar:     "DB_CERT",
+				secretPath: "company/app/db/cert",
+			},
+		},
+		"path and file": {
+			value: "company/app/db/cert:/run/secrets/db.pem",
+			expected: secretFile{
+				envVar:     "DB_CERT",
+				secretPath: "company/app/db/cert",
+				target:     "/run/secrets/db.pem",
+			},
+		},
+		"version and file": {
+			value: "company/app/db/cert:3:/run/secrets/db.pem",
+			expected: secretFile{
+				envVar:     "DB_CERT",
+				secretPath: "company/app/db/cert:3",
+				target:     "/run/secrets/db.pem",
+			},
+		},
+		"latest version": {
+			value: "company/app/db/cert:latest",
+			expected: secretFile{
+				envVar:     "DB_CERT",
+				secretPath: "company/app/db/cert:latest",
+			},
+		},
+		"windows file": {
+			value: `company/app/db/cert:C:\secrets\db.pem`,
+			expected: secretFile{
+				envVar:     "DB_CERT",
+				secretPath: "company/app/db/cert",
+				target:     `C:\secrets\db.pem`,
+			},
+		},
+		"empty file": {
+			value: "company/app/db/cert:",
+			err:   ErrInvalidSecretFile("DB_CERT", "the file path is empty"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := parseSecretFile("DB_CERT", tc.value)
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
+
+type fakeSecretReader map[string]string
+
+func (sr fakeSecretReader) ReadSecret(path string) (string, error) {
+	secret, ok := sr[path]
+	if !ok {
+		return "", api.ErrSecretNotFound
+	}
+	return secret, nil
+}
+
+func TestRunCommand_writeSecretFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrethub-test-")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	target := filepath.Join(dir, "secrets", "db", "db.pem")
+	cmd := RunCommand{
+		tempDir: dir,
+		secretFiles: map[string]string{
+			"DB_CERT":     "company/app/db/cert:" + target,
+			"DB_PASSWORD": "company/app/db/password",
+		},
+	}
+	var sr tpl.SecretReader = fakeSecretReader{
+		"company/app/db/cert":     "certificate",
+		"company/app/db/password": "password",
+	}
+
+	files, err := cmd.writeSecretFiles(sr)
+	assert.OK(t, err)
+
+	env := files.env()
+	assert.Equal(t, len(env), 2)
+	assert.Equal(t, env[0], "DB_CERT="+target)
+	assert.Equal(t, files.values(), []string{"certificate", "password"})
+
+	data, err := ioutil.ReadFile(target)
+	assert.OK(t, err)
+	assert.Equal(t, string(data), "certificate")
+
+	passwordFile := files.paths["DB_PASSWORD"]
+	assert.Equal(t, filepath.Base(passwordFile), "password")
+	data, err = ioutil.ReadFile(passwordFile)
+	assert.OK(t, err)
+	assert.Equal(t, string(data), "password")
+
+	changed, err := files.update(fakeSecretReader{
+		"company/app/db/cert":     "certificate",
+		"company/app/db/password": "rotated",
+	})
+	assert.OK(t, err)
+	assert.Equal(t, changed, true)
+	data, err = ioutil.ReadFile(passwordFile)
+	assert.OK(t, err)
+	assert.Equal(t, string(data), "rotated")
+	infos, err := ioutil.ReadDir(filepath.Dir(passwordFile))
+	assert.OK(t, err)
+	assert.Equal(t, len(infos), 1)
+
+	files.remove()
+	_, err = os.Stat(target)
+	assert.Equal(t, os.IsNotExist(err), true)
+	_, err = os.Stat(passwordFile)
+	assert.Equal(t, os.IsNotExist(err), true)
+	_, err = os.Stat(filepath.Join(dir, "secrets"))
+	assert.Equal(t, os.IsNotExist(err), true)
+	_, err = os.Stat(dir)
+	assert.OK(t, err)
+}
+
+func TestRunCommand_writeSecretFiles_Exists(t *testing.T) {
+	file, err := ioutil.TempFile("", "secrethub-test-")
+	assert.OK(t, err)
+	defer os.Remove(file.Name())
+	_ = file.Close()
+
+	cmd := RunCommand{
+		secretFiles: map[string]string{
+			"DB_CERT": "company/app/db/cert:" + file.Name(),
+		},
+	}
+
+	_, err = cmd.writeSecretFiles(fakeSecretReader{"company/app/db/cert": "certificate"})
+	assert.Equal(t, err, ErrSecretFileExists("DB_CERT", file.Name()))
+}
@@ -0,0 +1,198 @@
+package secrethub
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/tpl"
+
+	"github.com/secrethub/secrethub-go/internals/errio"
+)
+
+const (
+	// initialStartupBackoff is the time waited before the first retry, which doubles with every retry.
+	initialStartupBackoff = time.Second
+	// maxStartupBackoff is the maximum time waited between two retries.
+	maxStartupBackoff = 30 * time.Second
+)
+
+// startupSecretReader returns the secret reader for the secrets that are read before the command starts.
+// It retries reading secrets that failed because of a transient error, as configured with
+// --startup-retries and --startup-timeout. When retrying does not help, it falls back to the
+// cached values of the secrets, as configured with --max-cache-age.
+func (cmd *RunCommand) startupSecretReader() tpl.SecretReader {
+	var sr tpl.SecretReader = newSecretReader(cmd.newClient)
+	if cmd.startupRetries > 0 || cmd.startupTimeout > 0 {
+		sr = newRetryingSecretReader(sr, cmd.startupRetries, cmd.startupTimeout, cmd.logger)
+	}
+	sr = newCachedSecretReader(sr, cmd.secretCache)
+	// The cached values are used as a fallback after the cache, so that falling back
+	// does not add the cached values to the cache again as if they were just read.
+	if cmd.maxCacheAge > 0 && cmd.secretCache != nil {
+		sr = newOfflineSecretReader(sr, cmd.secretCache, cmd.maxCacheAge, cmd.logger)
+	}
+	if cmd.ignoreMissingSecrets {
+		sr = newIgnoreMissingSecretReader(sr)
+	}
+	return sr
+}
+
+type retryingSecretReader struct {
+	secretReader tpl.SecretReader
+	// retries is the maximum number of retries, or 0 for no maximum.
+	retries int
+	// deadline is the time after which no more retries are done, or the zero time for no deadline.
+	deadline time.Time
+	logger   cli.Logger
+	now      func() time.Time
+	sleep    func(time.Duration)
+}
+
+// newRetryingSecretReader wraps a secret reader to retry reads that fail because of a transient error,
+// e.g. an unreachable or overloaded API, with exponential backoff. It gives up when the maximum number
+// of retries is reached or when the next retry would start after the timeout has passed.
+func newRetryingSecretReader(sr tpl.SecretReader, retries int, timeout time.Duration, logger cli.Logger) *retryingSecretReader {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	return &retryingSecretReader{
+		secretReader: sr,
+		retries:      retries,
+		deadline:     deadline,
+		logger:       logger,
+		now:          time.Now,
+		sleep:        time.Sleep,
+	}
+}
+
+// ReadSecret uses the underlying secret reader to read the secret and retries
+// when it fails because of a transient error.
+func (sr *retryingSecretReader) ReadSecret(path string) (string, error) {
+	var secret string
+	err := sr.retry(path, func() error {
+		var err error
+		secret, err = sr.secretReader.ReadSecret(path)
+		return err
+	})
+	return secret, err
+}
+
+// ListSecrets uses the underlying secret reader to list the secrets and retries
+// when it fails because of a transient error.
+func (sr *retryingSecretReader) ListSecrets(dirPath string) ([]string, error) {
+	var paths []string
+	err := sr.retry(dirPath, func() error {
+		var err error
+		paths, err = tpl.ListSecrets(sr.secretReader, dirPath)
+		return err
+	})
+	return paths, err
+}
+
+// retry calls fn until it succeeds, it returns an error that is not transient or no more retries are left.
+func (sr *retryingSecretReader) retry(path string, fn func() error) error {
+	backoff := initialStartupBackoff
+	for retry := 1; ; retry++ {
+		err := fn()
+		if err == nil || !isTransientErr(err) {
+			return err
+		}
+		if sr.retries > 0 && retry > sr.retries {
+			return err
+		}
+		if !sr.deadline.IsZero() && sr.now().Add(backoff).After(sr.deadline) {
+			return err
+		}
+
+		sr.logger.With("path", path, "error", err, "backoff", backoff).Warningf("Could not read from SecretHub. Retrying.")
+		sr.sleep(backoff)
+
+		backoff *= 2
+		if backoff > maxStartupBackoff {
+			backoff = maxStartupBackoff
+		}
+	}
+}
+
+type offlineSecretReader struct {
+	secretReader tpl.SecretReader
+	cache        SecretCache
+	maxAge       time.Duration
+	logger       cli.Logger
+	now          func() time.Time
+}
+
+// newOfflineSecretReader wraps a secret reader to add the secrets it reads to the local cache
+// and to fall back to their cached values when the API cannot be reached. Cached values that
+// are older than maxAge are never used.
+func newOfflineSecretReader(sr tpl.SecretReader, cache SecretCache, maxAge time.Duration, logger cli.Logger) *offlineSecretReader {
+	return &offlineSecretReader{
+		secretReader: sr,
+		cache:        cache,
+		maxAge:       maxAge,
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+// ReadSecret uses the underlying secret reader to read the secret and stores it in the cache.
+// When reading fails because of a transient error, the cached value is returned with a warning.
+func (sr *offlineSecretReader) ReadSecret(path string) (string, error) {
+	secret, err := sr.secretReader.ReadSecret(path)
+	if err == nil {
+		// An enabled cache already stores the secrets it reads.
+		if !sr.cache.Enabled() {
+			cache, err := sr.cache.Open()
+			if err == nil {
+				// The cache is only a fallback, so failing to
+				// update it, e.g. on a read-only filesystem, is ignored.
+				_ = cache.Set(path, []byte(secret))
+			}
+		}
+		return secret, nil
+	}
+	if !isTransientErr(err) {
+		return "", err
+	}
+
+	cache, openErr := sr.cache.Open()
+	if openErr != nil {
+		return "", err
+	}
+	data, cachedAt, ok := cache.Lookup(path)
+	if !ok {
+		return "", err
+	}
+	age := sr.now().Sub(cachedAt)
+	if age >= sr.maxAge {
+		return "", err
+	}
+
+	sr.logger.With("path", path, "error", err, "age", age.Round(time.Second)).
+		Warningf("Could not read the secret from SecretHub. Using its cached value instead, which may be outdated.")
+	return string(data), nil
+}
+
+// ListSecrets uses the underlying secret reader to list the secrets.
+// Listings are not cached, so they cannot be used offline.
+func (sr *offlineSecretReader) ListSecrets(dirPath string) ([]string, error) {
+	return tpl.ListSecrets(sr.secretReader, dirPath)
+}
+
+// isTransientErr returns whether the error may not occur again when retrying,
+// i.e. when the API could not be reached or returned a server error.
+func isTransientErr(err error) bool {
+	switch e := err.(type) {
+	case errio.PublicStatusError:
+		return e.StatusCode >= http.StatusInternalServerError || e.StatusCode == http.StatusTooManyRequests
+	case errio.PublicError:
+		// The client returns failed requests, e.g. because of a lost connection, as unexpected errors.
+		return e.Code == "unexpected"
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,249 @@
+package secrethub
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/secretcache"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/internals/errio"
+)
+
+// failingSecretReader returns the errors in order before returning the secret.
+type failingSecretReader struct {
+	errs  []error
+	reads int
+}
+
+func (sr *failingSecretReader) ReadSecret(path string) (string, error) {
+	sr.reads++
+	if sr.reads <= len(sr.errs) {
+		return "", sr.errs[sr.reads-1]
+	}
+	return "secret", nil
+}
+
+// recordingLogger records the warnings that are logged to it.
+type recordingLogger struct {
+	warnings []string
+}
+
+func (l *recordingLogger) Debugf(format string, args ...interface{}) {}
+func (l *recordingLogger) Infof(format string, args ...interface{})  {}
+func (l *recordingLogger) Errorf(format string, args ...interface{}) {}
+func (l *recordingLogger) With(keyvals ...interface{}) cli.Logger    { return l }
+func (l *recordingLogger) EnableDebug()                              {}
+func (l *recordingLogger) SetFormat(format string) error             { return nil }
+func (l *recordingLogger) SetOutput(w io.Writer)                     {}
+func (l *recordingLogger) Warningf(format string, args ...interface{}) {
+	l.warnings = append(l.warnings, fmt.Sprintf(format, args...))
+}
+
+func TestRetryingSecretReader(t *testing.T) {
+	serverErr := errio.PublicStatusError{
+		PublicError: errio.PublicError{Code: "server_error"},
+		StatusCode:  http.StatusServiceUnavailable,
+	}
+
+	cases := map[string]struct {
+		errs            []error
+		retries         int
+		timeout         time.Duration
+		expected        string
+		expectedErr     error
+		expectedReads   int
+		expectedBackoff []time.Duration
+	}{
+		"success": {
+			retries:       3,
+			expected:      "secret",
+			expectedReads: 1,
+		},
+		"retry transient errors": {
+			errs:            []error{serverErr, serverErr},
+			retries:         3,
+			expected:        "secret",
+			expectedReads:   3,
+			expectedBackoff: []time.Duration{time.Second, 2 * time.Second},
+		},
+		"retries exhausted": {
+			errs:            []error{serverErr, serverErr, serverErr},
+			retries:         2,
+			expectedErr:     serverErr,
+			expectedReads:   3,
+			expectedBackoff: []time.Duration{time.Second, 2 * time.Second},
+		},
+		"timeout": {
+			errs:            []error{serverErr, serverErr, serverErr},
+			timeout:         2 * time.Second,
+			expectedErr:     serverErr,
+			expectedReads:   2,
+			expectedBackoff: []time.Duration{time.Second},
+		},
+		"backoff is limited": {
+			errs:            []error{serverErr, serverErr, serverErr, serverErr, serverErr, serverErr, serverErr},
+			retries:         10,
+			expected:        "secret",
+			expectedReads:   8,
+			expectedBackoff: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 30 * time.Second, 30 * time.Second},
+		},
+		"not found is not retried": {
+			errs:          []error{api.ErrSecretNotFound},
+			retries:       3,
+			expectedErr:   api.ErrSecretNotFound,
+			expectedReads: 1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fake := &failingSecretReader{errs: tc.errs}
+			sr := newRetryingSecretReader(fake, tc.retries, 0, &recordingLogger{})
+
+			now := time.Now()
+			if tc.timeout > 0 {
+				sr.deadline = now.Add(tc.timeout)
+			}
+			sr.now = func() time.Time {
+				return now
+			}
+			var backoff []time.Duration
+			sr.sleep = func(d time.Duration) {
+				backoff = append(backoff, d)
+				now = now.Add(d)
+			}
+
+			actual, err := sr.ReadSecret("namespace/repo/secret")
+
+			assert.Equal(t, err, tc.expectedErr)
+			assert.Equal(t, actual, tc.expected)
+			assert.Equal(t, fake.reads, tc.expectedReads)
+			assert.Equal(t, backoff, tc.expectedBackoff)
+		})
+	}
+}
+
+func TestIsTransientErr(t *testing.T) {
+	cases := map[string]struct {
+		err      error
+		expected bool
+	}{
+		"server error": {
+			err:      errio.PublicStatusError{StatusCode: http.StatusBadGateway},
+			expected: true,
+		},
+		"too many requests": {
+			err:      errio.PublicStatusError{StatusCode: http.StatusTooManyRequests},
+			expected: true,
+		},
+		"client error": {
+			err:      errio.PublicStatusError{StatusCode: http.StatusForbidden},
+			expected: false,
+		},
+		"network error": {
+			err:      &net.OpError{Op: "dial", Err: errors.New("connection refused")},
+			expected: true,
+		},
+		"other error": {
+			err:      errors.New("invalid passphrase"),
+			expected: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, isTransientErr(tc.err), tc.expected)
+		})
+	}
+}
+
+// fakeSecretCache is a disabled SecretCache that opens the given cache.
+type fakeSecretCache struct {
+	SecretCache
+	cache *secretcache.Cache
+}
+
+func (c fakeSecretCache) Enabled() bool {
+	return false
+}
+
+func (c fakeSecretCache) Open() (*secretcache.Cache, error) {
+	return c.cache, nil
+}
+
+func TestOfflineSecretReader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrethub-cache-test")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	cache, err := secretcache.New(dir, secretcache.DeriveKey([]byte("credential")))
+	assert.OK(t, err)
+	secretCache := fakeSecretCache{cache: cache}
+
+	serverErr := errio.PublicStatusError{StatusCode: http.StatusServiceUnavailable}
+
+	// Reading the secret adds it to the cache.
+	sr := newOfflineSecretReader(&failingSecretReader{}, secretCache, time.Hour, &recordingLogger{})
+	actual, err := sr.ReadSecret("namespace/repo/secret")
+	assert.OK(t, err)
+	assert.Equal(t, actual, "secret")
+
+	cases := map[string]struct {
+		err         error
+		age         time.Duration
+		path        string
+		expected    string
+		expectedErr error
+	}{
+		"fall back to cached value": {
+			err:      serverErr,
+			age:      time.Minute,
+			path:     "namespace/repo/secret",
+			expected: "secret",
+		},
+		"cached value too old": {
+			err:         serverErr,
+			age:         2 * time.Hour,
+			path:        "namespace/repo/secret",
+			expectedErr: serverErr,
+		},
+		"not cached": {
+			err:         serverErr,
+			age:         time.Minute,
+			path:        "namespace/repo/other",
+			expectedErr: serverErr,
+		},
+		"not found": {
+			err:         api.ErrSecretNotFound,
+			age:         time.Minute,
+			path:        "namespace/repo/secret",
+			expectedErr: api.ErrSecretNotFound,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			logger := &recordingLogger{}
+			sr := newOfflineSecretReader(&failingSecretReader{errs: []error{tc.err}}, secretCache, time.Hour, logger)
+			sr.now = func() time.Time {
+				return time.Now().Add(tc.age)
+			}
+
+			actual, err := sr.ReadSecret(tc.path)
+
+			assert.Equal(t, err, tc.expectedErr)
+			assert.Equal(t, actual, tc.expected)
+			assert.Equal(t, len(logger.warnings) == 1, tc.expectedErr == nil)
+		})
+	}
+}
@@ -563,8 +563,8 @@ func TestRunCommand_Run(t *testing.T) {
 		"invalid template var: start with a number": {
 			command: RunCommand{
 				environment: &environment{
-					osStat:  osStatNotExist,
-					envFile: "secrethub.env",
+					osStat:   osStatNotExist,
+					envFiles: []string{"secrethub.env"},
 					templateVars: map[string]string{
 						"0foo": "value",
 					},
@@ -576,8 +576,8 @@ func TestRunCommand_Run(t *testing.T) {
 		"invalid template var: illegal character": {
 			command: RunCommand{
 				environment: &environment{
-					osStat:  osStatNotExist,
-					envFile: "secrethub.env",
+					osStat:   osStatNotExist,
+					envFiles: []string{"secrethub.env"},
 					templateVars: map[string]string{
 						"foo@bar": "value",
 					},
@@ -676,7 +676,7 @@ func TestRunCommand_environment(t *testing.T) {
 				environment: &environment{
 					osStat:          osStatFunc("secrethub.env", nil),
 					readFile:        readFileFunc("secrethub.env", "TEST={{path/to/secret}"),
-					envFile:         "secrethub.env",
+					envFiles:        []string{"secrethub.env"},
 					templateVersion: "2",
 				},
 			},
@@ -700,7 +700,7 @@ func TestRunCommand_environment(t *testing.T) {
 		"custom env file does not exist": {
 			command: RunCommand{
 				environment: &environment{
-					envFile: "foo.env",
+					envFiles: []string{"foo.env"},
 					readFile: func(filename string) ([]byte, error) {
 						if filename == "foo.env" {
 							return nil, &os.PathError{Op: "open", Path: "foo.env", Err: os.ErrNotExist}
@@ -715,20 +715,32 @@ func TestRunCommand_environment(t *testing.T) {
 			command: RunCommand{
 				environment: &environment{
 					osStat:          osStatFunc("foo.env", nil),
-					envFile:         "foo.env",
+					envFiles:        []string{"foo.env"},
 					templateVersion: "2",
 					readFile:        readFileFunc("foo.env", "TEST=test"),
 				},
 			},
 			expectedEnv: []string{"TEST=test"},
 		},
+		"yaml env file success": {
+			command: RunCommand{
+				environment: &environment{
+					osStat:           osStatFunc("foo.yml", nil),
+					envFiles:         []string{"foo.yml"},
+					envFileSeparator: "_",
+					templateVersion:  "2",
+					readFile:         readFileFunc("foo.yml", "DB:\n  USER: app\n  PORT: 5432\n"),
+				},
+			},
+			expectedEnv: []string{"DB_USER=app", "DB_PORT=5432"},
+		},
 		"env file secret does not exist": {
 			command: RunCommand{
 				command: []string{"echo", "test"},
 				environment: &environment{
 					osStat:          osStatFunc("secrethub.env", nil),
 					readFile:        readFileFunc("secrethub.env", "TEST= {{ unexistent/secret/path }}"),
-					envFile:         "secrethub.env",
+					envFiles:        []string{"secrethub.env"},
 					templateVersion: "2",
 				},
 				newClient: func() (secrethub.ClientInterface, error) {
@@ -750,7 +762,7 @@ func TestRunCommand_environment(t *testing.T) {
 				environment: &environment{
 					osStat:   osStatFunc("secrethub.env", nil),
 					readFile: readFileFunc("secrethub.env", "TEST=aaa"),
-					envFile:  "secrethub.env",
+					envFiles: []string{"secrethub.env"},
 					envar: map[string]string{
 						"TEST": "test/test/test",
 					},
@@ -948,6 +960,91 @@ func TestRunCommand_environment(t *testing.T) {
 			expectedSecrets: []string{"bbb"},
 			expectedEnv:     []string{"TEST=bbb"},
 		},
+		"secret reference without env passthrough": {
+			command: RunCommand{
+				environment: &environment{
+					osStat:                       osStatFunc("secrethub.env", os.ErrNotExist),
+					dontPromptMissingTemplateVar: true,
+					templateVersion:              "2",
+					osEnv:                        []string{"TEST=secrethub://test/test/test"},
+					noEnvPassthrough:             true,
+				},
+			},
+			expectedSecrets: []string{},
+			expectedEnv:     []string{"TEST=secrethub://test/test/test"},
+		},
+		"deny pattern": {
+			command: RunCommand{
+				environment: &environment{
+					osStat:                       osStatFunc("secrethub.env", os.ErrNotExist),
+					dontPromptMissingTemplateVar: true,
+					osEnv:                        []string{"PATH=/bin", "AWS_ACCESS_KEY_ID=key", "AWS_SECRET_ACCESS_KEY=secret"},
+					filter: envFilter{
+						denyPatterns: []string{"AWS_*"},
+					},
+				},
+			},
+			expectedSecrets: []string{},
+			expectedEnv:     []string{"PATH=/bin"},
+		},
+		"deny pattern secret reference": {
+			command: RunCommand{
+				environment: &environment{
+					osStat:                       osStatFunc("secrethub.env", os.ErrNotExist),
+					dontPromptMissingTemplateVar: true,
+					templateVersion:              "2",
+					osEnv:                        []string{"PATH=/bin", "AWS_SECRET_ACCESS_KEY=secrethub://test/test/test"},
+					filter: envFilter{
+						denyPatterns: []string{"AWS_*"},
+					},
+				},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+									return &api.SecretVersion{Data: []byte("secret")}, nil
+								},
+							},
+						},
+					}, nil
+				},
+			},
+			expectedSecrets: []string{},
+			expectedEnv:     []string{"PATH=/bin"},
+		},
+		"allow": {
+			command: RunCommand{
+				environment: &environment{
+					osStat:                       osStatFunc("secrethub.env", nil),
+					readFile:                     readFileFunc("secrethub.env", "APP_ENV=prod"),
+					dontPromptMissingTemplateVar: true,
+					templateVersion:              "2",
+					osEnv:                        []string{"PATH=/bin", "HOME=/root", "LC_ALL=C", "TOKEN=abc"},
+					filter: envFilter{
+						allow: []string{"PATH,HOME", "LC_*"},
+					},
+				},
+			},
+			expectedSecrets: []string{},
+			expectedEnv:     []string{"PATH=/bin", "HOME=/root", "LC_ALL=C", "APP_ENV=prod"},
+		},
+		"no prune": {
+			command: RunCommand{
+				environment: &environment{
+					osStat:                       osStatFunc("secrethub.env", os.ErrNotExist),
+					dontPromptMissingTemplateVar: true,
+					osEnv:                        []string{"PATH=/bin", "TOKEN=abc"},
+					filter: envFilter{
+						noPrune:      true,
+						allow:        []string{"PATH"},
+						denyPatterns: []string{"*"},
+					},
+				},
+			},
+			expectedSecrets: []string{},
+			expectedEnv:     []string{"PATH=/bin", "TOKEN=abc"},
+		},
 		".env file has precedence over other os variables": {
 			command: RunCommand{
 				environment: &environment{
@@ -990,7 +1087,7 @@ func TestRunCommand_environment(t *testing.T) {
 				ignoreMissingSecrets: true,
 				environment: &environment{
 					osStat:   osStatFunc("secrethub.env", nil),
-					envFile:  "secrethub.env",
+					envFiles: []string{"secrethub.env"},
 					readFile: readFileFunc("secrethub.env", ""),
 					envar: map[string]string{
 						"TEST": "test/test/test",
@@ -1019,7 +1116,7 @@ func TestRunCommand_environment(t *testing.T) {
 					osStat:                       osStatFunc("secrethub.env", nil),
 					readFile:                     readFileFunc("secrethub.env", "TEST = {{ test/$variable/test }}"),
 					dontPromptMissingTemplateVar: true,
-					envFile:                      "secrethub.env",
+					envFiles:                     []string{"secrethub.env"},
 					templateVersion:              "2",
 				},
 				newClient: func() (secrethub.ClientInterface, error) {
@@ -1110,6 +1207,58 @@ func TestRunCommand_environment(t *testing.T) {
 			},
 			expectedEnv: []string{"TEST=foo", "SECRETHUB_VAR_VARIABLE=bar"},
 		},
+		"multiple env files": {
+			command: RunCommand{
+				environment: &environment{
+					osStat: osStatFunc("secrethub.env", os.ErrNotExist),
+					readFile: func(filename string) ([]byte, error) {
+						files := map[string]string{
+							"base.env": "APP=app\nLOG_LEVEL=info",
+							"prod.env": "LOG_LEVEL=warn",
+						}
+						content, ok := files[filename]
+						if !ok {
+							return nil, os.ErrNotExist
+						}
+						return []byte(content), nil
+					},
+					envFiles:        []string{"base.env", "prod.env"},
+					templateVersion: "2",
+				},
+			},
+			expectedSecrets: []string{},
+			expectedEnv:     []string{"APP=app", "LOG_LEVEL=warn"},
+		},
+		"env flag": {
+			command: RunCommand{
+				environment: &environment{
+					osStat:   osStatFunc("secrethub.env", nil),
+					readFile: readFileFunc("secrethub.env", "PLAIN=file\nSECRET=file"),
+					envar:    map[string]string{"SECRET": "path/to/other"},
+					envVars: map[string]string{
+						"PLAIN":  "value",
+						"SECRET": "secrethub://path/to/secret",
+					},
+					templateVersion: "2",
+				},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+									if path == "path/to/secret" {
+										return &api.SecretVersion{Data: []byte("bbb")}, nil
+									}
+									return nil, api.ErrSecretNotFound
+								},
+							},
+						},
+					}, nil
+				},
+			},
+			expectedSecrets: []string{"bbb"},
+			expectedEnv:     []string{"PLAIN=value", "SECRET=bbb"},
+		},
 		"v1 template syntax success": {
 			command: RunCommand{
 				command: []string{"/bin/sh", "./test.sh"},
@@ -1137,7 +1286,7 @@ func TestRunCommand_environment(t *testing.T) {
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			env, secrets, err := tc.command.sourceEnvironment()
+			env, secrets, err := tc.command.resolveEnvironment(tc.command.secretReader(true))
 
 			sort.Strings(env)
 			sort.Strings(tc.expectedEnv)
@@ -1179,7 +1328,7 @@ func TestRunCommand_RunWithFile(t *testing.T) {
 				environment: &environment{
 					osStat:   osStatOnlySecretHubEnv,
 					readFile: readFileWithContent(""),
-					envFile:  "secrethub.env",
+					envFiles: []string{"secrethub.env"},
 					envar: map[string]string{
 						"TEST": "test/test/test",
 					},
@@ -1205,7 +1354,7 @@ func TestRunCommand_RunWithFile(t *testing.T) {
 				command: []string{"/bin/sh", "./test.sh"},
 				environment: &environment{
 					osStat:   osStatOnlySecretHubEnv,
-					envFile:  "secrethub.env",
+					envFiles: []string{"secrethub.env"},
 					readFile: readFileWithContent(""),
 					envar: map[string]string{
 						"TEST": "test/test/test",
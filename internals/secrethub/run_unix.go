@@ -0,0 +1,99 @@
+// +build !windows
+
+package secrethub
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// reloadSignals are the signals that can be sent to a command when its secrets change.
+var reloadSignals = map[string]os.Signal{
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGINT":  syscall.SIGINT,
+	"SIGQUIT": syscall.SIGQUIT,
+	"SIGTERM": syscall.SIGTERM,
+	"SIGUSR1": syscall.SIGUSR1,
+	"SIGUSR2": syscall.SIGUSR2,
+}
+
+// isForwardedSignal returns whether a signal received by secrethub should be passed on to the command.
+// SIGCHLD is about the children of secrethub and SIGURG is used internally by the Go runtime.
+func isForwardedSignal(s os.Signal) bool {
+	return s != syscall.SIGCHLD && s != syscall.SIGURG
+}
+
+// reaper waits for all exited child processes when secrethub runs as PID 1, e.g. as the entrypoint
+// of a container. Orphaned processes are adopted by PID 1 and would otherwise remain as zombies.
+type reaper struct {
+	mutex   sync.Mutex
+	started map[int]chan syscall.WaitStatus
+}
+
+// newReaper starts a reaper when the process runs as PID 1 and returns nil otherwise.
+func newReaper() *reaper {
+	if os.Getpid() != 1 {
+		return nil
+	}
+
+	r := &reaper{
+		started: make(map[int]chan syscall.WaitStatus),
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGCHLD)
+	go func() {
+		for range signals {
+			r.reap()
+		}
+	}()
+
+	return r
+}
+
+// start starts the command. When the reaper waits for the process before the command does,
+// the wait status of the process is sent on the returned channel.
+func (r *reaper) start(command *exec.Cmd) (<-chan syscall.WaitStatus, error) {
+	if r == nil {
+		return nil, command.Start()
+	}
+
+	// The lock makes sure the process is registered before the reaper can wait for it.
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	err := command.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	reaped := make(chan syscall.WaitStatus, 1)
+	r.started[command.Process.Pid] = reaped
+	return reaped, nil
+}
+
+// reap waits for all exited child processes.
+func (r *reaper) reap() {
+	for {
+		var status syscall.WaitStatus
+		pid, err := syscall.Wait4(-1, &status, syscall.WNOHANG, nil)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != nil || pid <= 0 {
+			return
+		}
+
+		r.mutex.Lock()
+		reaped, ok := r.started[pid]
+		delete(r.started, pid)
+		r.mutex.Unlock()
+
+		if ok {
+			reaped <- status
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// +build !windows
+
+package secrethub
+
+import (
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestReaper(t *testing.T) {
+	r := &reaper{
+		started: make(map[int]chan syscall.WaitStatus),
+	}
+
+	command := exec.Command("sh", "-c", "exit 3")
+	reaped, err := r.start(command)
+	assert.OK(t, err)
+
+	process := &childProcess{
+		command: command,
+		reaped:  reaped,
+		done:    make(chan struct{}),
+	}
+
+	// Reap until the reaper has waited for the process, like it would on a SIGCHLD.
+	for i := 0; i < 100; i++ {
+		r.reap()
+		r.mutex.Lock()
+		remaining := len(r.started)
+		r.mutex.Unlock()
+		if remaining == 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	err = process.wait()
+	exitErr, ok := err.(reapedExitError)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, exitErr.status.ExitStatus(), 3)
+	assert.Equal(t, exitErr.Error(), "exit status 3")
+}
+
+func TestIsForwardedSignal(t *testing.T) {
+	assert.Equal(t, isForwardedSignal(syscall.SIGHUP), true)
+	assert.Equal(t, isForwardedSignal(syscall.SIGWINCH), true)
+	assert.Equal(t, isForwardedSignal(syscall.SIGUSR1), true)
+	assert.Equal(t, isForwardedSignal(syscall.SIGCHLD), false)
+}
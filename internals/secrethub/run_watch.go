@@ -0,0 +1,168 @@
+package secrethub
+
+import (
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Errors
+var (
+	ErrReloadSignalWithoutWatch = errRun.Code("reload_signal_without_watch").Error("--reload-signal can only be used together with --watch")
+	ErrInvalidReloadSignal      = errRun.Code("invalid_reload_signal").ErrorPref("unsupported reload signal %s, supported signals are: %s")
+	ErrWatchIntervalTooShort    = errRun.Code("watch_interval_too_short").ErrorPref("the watch interval must be at least %s")
+	ErrReloadNotSupported       = errRun.Code("reload_not_supported").Error("sending a reload signal is not supported on this platform")
+)
+
+const (
+	// minWatchInterval prevents the secrets from being read so often that it overloads the API.
+	minWatchInterval = 5 * time.Second
+)
+
+// runWatch runs the command and polls the secrets for changes. When a secret changes, the
+// command is restarted with the new environment or the reload signal is sent to it.
+// It returns when the command exits by itself.
+func (cmd *RunCommand) runWatch(environment []string, secrets []string, files *secretFileSet) error {
+	if cmd.watchInterval < minWatchInterval {
+		return ErrWatchIntervalTooShort(minWatchInterval)
+	}
+
+	var reloadSignal os.Signal
+	if cmd.reloadSignal != "" {
+		var err error
+		reloadSignal, err = parseReloadSignal(cmd.reloadSignal)
+		if err != nil {
+			return err
+		}
+	}
+
+	process, err := cmd.start(environment, secrets)
+	if err != nil {
+		return err
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- process.wait()
+	}()
+
+	// Pass all signals to child process
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals)
+	defer signal.Stop(signals)
+
+	ticker := time.NewTicker(cmd.watchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case s := <-signals:
+			process.forward(s, cmd.killTimeout)
+		case err := <-exited:
+			// The files are removed before exiting, as deferred functions do not run on an exit.
+			files.remove()
+			return exitWithStatus(err)
+		case <-ticker.C:
+			sr := cmd.secretReader(false)
+			newEnvironment, newSecrets, err := cmd.resolveEnvironment(sr)
+			if err != nil {
+				cmd.logger.With("error", err).Errorf("Could not check the secrets for changes")
+				continue
+			}
+			filesChanged, err := files.update(sr)
+			if err != nil {
+				cmd.logger.With("error", err).Errorf("Could not check the secrets for changes")
+				continue
+			}
+			newEnvironment = append(newEnvironment, files.env()...)
+			newSecrets = append(newSecrets, files.values()...)
+
+			if !filesChanged && !environmentChanged(environment, newEnvironment) {
+				continue
+			}
+			environment, secrets = newEnvironment, newSecrets
+
+			if reloadSignal != nil {
+				cmd.logger.With("signal", reloadSignal).Infof("The secrets have changed. Sending the reload signal to the command.")
+				process.signal(reloadSignal)
+				continue
+			}
+
+			cmd.logger.Infof("The secrets have changed. Restarting the command.")
+			process.stop(cmd.killTimeout)
+			<-exited
+
+			process, err = cmd.start(environment, secrets)
+			if err != nil {
+				return err
+			}
+			go func(p *childProcess) {
+				exited <- p.wait()
+			}(process)
+		}
+	}
+}
+
+// stop terminates the process. The process is killed when it does not exit within the
+// timeout. A timeout of 0 waits for the process to exit without killing it.
+func (p *childProcess) stop(timeout time.Duration) {
+	err := p.command.Process.Signal(syscall.SIGTERM)
+	if err != nil {
+		// Not every platform supports SIGTERM, so fall back to killing the process.
+		_ = p.command.Process.Kill()
+		return
+	}
+
+	if timeout > 0 {
+		select {
+		case <-p.done:
+		case <-time.After(timeout):
+			_ = p.command.Process.Kill()
+		}
+	}
+}
+
+// environmentChanged returns whether the two environments contain different variables or values.
+func environmentChanged(current []string, updated []string) bool {
+	if len(current) != len(updated) {
+		return true
+	}
+
+	sortedCurrent := append([]string{}, current...)
+	sortedUpdated := append([]string{}, updated...)
+	sort.Strings(sortedCurrent)
+	sort.Strings(sortedUpdated)
+
+	for i := range sortedCurrent {
+		if sortedCurrent[i] != sortedUpdated[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// parseReloadSignal returns the signal with the given name. The SIG prefix is optional.
+func parseReloadSignal(name string) (os.Signal, error) {
+	if len(reloadSignals) == 0 {
+		return nil, ErrReloadNotSupported
+	}
+
+	normalized := strings.ToUpper(name)
+	if !strings.HasPrefix(normalized, "SIG") {
+		normalized = "SIG" + normalized
+	}
+
+	s, ok := reloadSignals[normalized]
+	if !ok {
+		names := make([]string, 0, len(reloadSignals))
+		for name := range reloadSignals {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, ErrInvalidReloadSignal(name, strings.Join(names, ", "))
+	}
+	return s, nil
+}
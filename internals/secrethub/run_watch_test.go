@@ -0,0 +1,90 @@
+package secrethub
+
+import (
+	"os"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestEnvironmentChanged(t *testing.T) {
+	cases := map[string]struct {
+		current  []string
+		updated  []string
+		expected bool
+	}{
+		"unchanged": {
+			current:  []string{"A=1", "B=2"},
+			updated:  []string{"A=1", "B=2"},
+			expected: false,
+		},
+		"different order": {
+			current:  []string{"A=1", "B=2"},
+			updated:  []string{"B=2", "A=1"},
+			expected: false,
+		},
+		"changed value": {
+			current:  []string{"A=1", "B=2"},
+			updated:  []string{"A=1", "B=3"},
+			expected: true,
+		},
+		"added variable": {
+			current:  []string{"A=1"},
+			updated:  []string{"A=1", "B=2"},
+			expected: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, environmentChanged(tc.current, tc.updated), tc.expected)
+		})
+	}
+}
+
+func TestRunCommand_Run_ReloadSignalWithoutWatch(t *testing.T) {
+	cmd := RunCommand{
+		command:      []string{"echo", "test"},
+		reloadSignal: "SIGHUP",
+	}
+
+	err := cmd.Run()
+	assert.Equal(t, err, ErrReloadSignalWithoutWatch)
+}
+
+func TestParseReloadSignal(t *testing.T) {
+	if len(reloadSignals) == 0 {
+		_, err := parseReloadSignal("SIGHUP")
+		assert.Equal(t, err, ErrReloadNotSupported)
+		return
+	}
+
+	cases := map[string]struct {
+		name     string
+		expected os.Signal
+	}{
+		"full name": {
+			name:     "SIGHUP",
+			expected: reloadSignals["SIGHUP"],
+		},
+		"without prefix": {
+			name:     "HUP",
+			expected: reloadSignals["SIGHUP"],
+		},
+		"lowercase": {
+			name:     "sigterm",
+			expected: reloadSignals["SIGTERM"],
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := parseReloadSignal(tc.name)
+			assert.OK(t, err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+
+	_, err := parseReloadSignal("SIGFOO")
+	assert.Equal(t, err != nil, true)
+}
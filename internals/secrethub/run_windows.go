@@ -0,0 +1,29 @@
+package secrethub
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// reloadSignals are the signals that can be sent to a command when its secrets change.
+// Windows does not support sending signals to other processes.
+var reloadSignals = map[string]os.Signal{}
+
+// isForwardedSignal returns whether a signal received by secrethub should be passed on to the command.
+func isForwardedSignal(s os.Signal) bool {
+	return true
+}
+
+// reaper is not needed on Windows, as processes do not remain as zombies.
+type reaper struct{}
+
+// newReaper returns nil, as there are no processes to reap on Windows.
+func newReaper() *reaper {
+	return nil
+}
+
+// start starts the command.
+func (r *reaper) start(command *exec.Cmd) (<-chan syscall.WaitStatus, error) {
+	return nil, command.Start()
+}
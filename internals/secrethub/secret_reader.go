@@ -31,6 +31,73 @@ func (sr secretReader) ReadSecret(path string) (string, error) {
 	return string(secret.Data), nil
 }
 
+// ListSecrets lists the secrets directly in the directory using the provided client.
+func (sr secretReader) ListSecrets(dirPath string) ([]string, error) {
+	client, err := sr.newClient()
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := client.Dirs().GetTree(dirPath, 1, false)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(tree.RootDir.Secrets))
+	for _, secret := range tree.RootDir.Secrets {
+		paths = append(paths, api.JoinPaths(dirPath, secret.Name))
+	}
+	return paths, nil
+}
+
+type cachedSecretReader struct {
+	secretReader tpl.SecretReader
+	cache        SecretCache
+}
+
+// newCachedSecretReader wraps a secret reader to read secrets from the
+// local cache when caching is enabled.
+func newCachedSecretReader(sr tpl.SecretReader, cache SecretCache) tpl.SecretReader {
+	if cache == nil || !cache.Enabled() {
+		return sr
+	}
+	return &cachedSecretReader{
+		secretReader: sr,
+		cache:        cache,
+	}
+}
+
+// ReadSecret returns the cached secret when available and otherwise uses the
+// underlying secret reader to read the secret and adds it to the cache.
+func (sr *cachedSecretReader) ReadSecret(path string) (string, error) {
+	data, ok, err := sr.cache.Read(path)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return string(data), nil
+	}
+
+	secret, err := sr.secretReader.ReadSecret(path)
+	if err != nil {
+		return "", err
+	}
+
+	cache, err := sr.cache.Open()
+	if err == nil {
+		// The cache is only an optimization, so failing to
+		// update it, e.g. on a read-only filesystem, is ignored.
+		_ = cache.Set(path, []byte(secret))
+	}
+	return secret, nil
+}
+
+// ListSecrets uses the underlying secret reader to list the secrets.
+// Listings are not cached, so newly added secrets are always included.
+func (sr *cachedSecretReader) ListSecrets(dirPath string) ([]string, error) {
+	return tpl.ListSecrets(sr.secretReader, dirPath)
+}
+
 type bufferedSecretReader struct {
 	secretReader tpl.SecretReader
 	secretsRead  []string
@@ -57,6 +124,11 @@ func (sr *bufferedSecretReader) ReadSecret(path string) (string, error) {
 	return secret, err
 }
 
+// ListSecrets uses the underlying secret reader to list the secrets.
+func (sr *bufferedSecretReader) ListSecrets(dirPath string) ([]string, error) {
+	return tpl.ListSecrets(sr.secretReader, dirPath)
+}
+
 type secretReaderNotAllowed struct{}
 
 func (sr secretReaderNotAllowed) ReadSecret(path string) (string, error) {
@@ -87,3 +159,13 @@ func (sr *ignoreMissingSecretReader) ReadSecret(path string) (string, error) {
 	}
 	return secret, err
 }
+
+// ListSecrets uses the underlying secret reader to list the secrets, but
+// ignores errors for non-existing directories. Instead, it returns no secrets.
+func (sr *ignoreMissingSecretReader) ListSecrets(dirPath string) ([]string, error) {
+	paths, err := tpl.ListSecrets(sr.secretReader, dirPath)
+	if api.IsErrNotFound(err) {
+		return nil, nil
+	}
+	return paths, err
+}
@@ -0,0 +1,28 @@
+package secrethubtest_test
+
+import (
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/secrethubtest"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestRun_Commands(t *testing.T) {
+	store := secrethubtest.NewStore()
+	store.WriteSecret("company/app/db/password", []byte("s3cr3t"))
+
+	io := fakeui.NewIO(t)
+	err := secrethubtest.Run(secrethub.NewReadCommand(io, store.NewClient), "read", "company/app/db/password")
+	assert.OK(t, err)
+	assert.Equal(t, io.Out.String(), "s3cr3t\n")
+
+	err = secrethubtest.Run(secrethub.NewMkDirCommand(io, store.NewClient), "mkdir", "company/app/api")
+	assert.OK(t, err)
+
+	exists, err := store.Client().Dirs().Exists("company/app/api")
+	assert.OK(t, err)
+	assert.Equal(t, exists, true)
+}
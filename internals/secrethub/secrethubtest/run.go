@@ -0,0 +1,32 @@
+// +build !production
+
+package secrethubtest
+
+import (
+	"io/ioutil"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// Command is a command that can be registered on an application,
+// like the commands in the secrethub package.
+type Command interface {
+	Register(r command.Registerer)
+}
+
+// Run registers the command on a new application and runs it with the given arguments,
+// in the same way as it is run from the command line. The arguments start with the
+// name of the command, e.g. Run(cmd, "read", "company/app/db/password"). Parse errors,
+// like missing arguments, and errors returned by the command are returned.
+func Run(cmd Command, args ...string) error {
+	app := cli.NewApp("secrethubtest", "")
+	app.Terminate(nil)
+	app.UsageWriter(ioutil.Discard)
+	app.ErrorWriter(ioutil.Discard)
+
+	cmd.Register(app)
+
+	_, err := app.Parse(args)
+	return err
+}
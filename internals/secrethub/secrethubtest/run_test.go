@@ -0,0 +1,44 @@
+package secrethubtest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+type echoCommand struct {
+	value string
+	err   error
+	ran   bool
+}
+
+func (cmd *echoCommand) Register(r command.Registerer) {
+	clause := r.Command("echo", "Echo a value.")
+	clause.Arg("value", "The value.").Required().StringVar(&cmd.value)
+	command.BindAction(clause, cmd.Run)
+}
+
+func (cmd *echoCommand) Run() error {
+	cmd.ran = true
+	return cmd.err
+}
+
+func TestRun(t *testing.T) {
+	cmd := &echoCommand{}
+	err := Run(cmd, "echo", "hello")
+	assert.OK(t, err)
+	assert.Equal(t, cmd.ran, true)
+	assert.Equal(t, cmd.value, "hello")
+
+	cmd = &echoCommand{err: errors.New("failed")}
+	err = Run(cmd, "echo", "hello")
+	assert.Equal(t, err, cmd.err)
+
+	cmd = &echoCommand{}
+	err = Run(cmd, "echo")
+	assert.Equal(t, err == nil, false)
+	assert.Equal(t, cmd.ran, false)
+}
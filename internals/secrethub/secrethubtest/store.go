@@ -0,0 +1,478 @@
+// +build !production
+
+// Package secrethubtest provides an in-memory SecretHub client and helpers to
+// run CLI commands against it, so that commands can be unit tested without
+// network access.
+//
+// A Store holds repositories, directories and secrets in memory. Its NewClient
+// method can be passed to the constructors of the commands in the secrethub
+// package:
+//
+//	store := secrethubtest.NewStore()
+//	store.WriteSecret("company/app/db/password", []byte("s3cr3t"))
+//
+//	io := fakeui.NewIO(t)
+//	err := secrethubtest.Run(secrethub.NewReadCommand(io, store.NewClient), "read", "company/app/db/password")
+//
+// Only the secrets and directories of the client are backed by the store. The
+// other services of the client are empty fakes that panic when they are used.
+package secrethubtest
+
+import (
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+// Store is an in-memory collection of repositories, directories and secrets.
+// It is safe for concurrent use.
+type Store struct {
+	mutex   sync.Mutex
+	dirs    map[string]*storedDir
+	secrets map[string]*storedSecret
+	// Now returns the time used for created timestamps. It defaults to time.Now.
+	Now func() time.Time
+}
+
+// storedDir is a repository or a directory. Repositories are the directories at the top of a path.
+type storedDir struct {
+	id        uuid.UUID
+	path      string
+	createdAt time.Time
+}
+
+type storedSecret struct {
+	id       uuid.UUID
+	path     string
+	versions []*api.SecretVersion
+}
+
+// NewStore creates an empty store.
+func NewStore() *Store {
+	return &Store{
+		dirs:    map[string]*storedDir{},
+		secrets: map[string]*storedSecret{},
+		Now:     time.Now,
+	}
+}
+
+// CreateRepo creates a repository, e.g. company/app.
+func (s *Store) CreateRepo(repoPath string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.createDir(repoPath)
+}
+
+// WriteSecret writes a new version of a secret, creating its repository and directories when they do not exist.
+// It is meant to set up the state before running a command.
+func (s *Store) WriteSecret(secretPath string, data []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.createDirAll(path.Dir(secretPath))
+	s.writeSecret(secretPath, data)
+}
+
+// Secret returns the value of the latest version of a secret and whether the secret exists.
+// It is meant to check the state after running a command.
+func (s *Store) Secret(secretPath string) ([]byte, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	secret, ok := s.secrets[key(secretPath)]
+	if !ok {
+		return nil, false
+	}
+	return secret.versions[len(secret.versions)-1].Data, true
+}
+
+// SecretPaths returns the paths of all secrets in the store, sorted alphabetically.
+func (s *Store) SecretPaths() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	paths := make([]string, 0, len(s.secrets))
+	for _, secret := range s.secrets {
+		paths = append(paths, secret.path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// NewClient returns a client backed by the store. Its signature matches the
+// client factory the commands in the secrethub package are constructed with.
+func (s *Store) NewClient() (secrethub.ClientInterface, error) {
+	return s.Client(), nil
+}
+
+// Client returns a client of which the secret and directory services are backed by the store.
+func (s *Store) Client() fakeclient.Client {
+	return fakeclient.Client{
+		DirService: &fakeclient.DirService{
+			CreateFunc:    s.dirCreate,
+			CreateAllFunc: s.dirCreateAll,
+			DeleteFunc:    s.dirDelete,
+			ExistsFunc:    s.dirExists,
+			GetTreeFunc:   s.getTree,
+		},
+		SecretService: &fakeclient.SecretService{
+			DeleteFunc: s.secretDelete,
+			ExistsFunc: s.secretExists,
+			GetFunc:    s.secretGet,
+			WriteFunc:  s.secretWrite,
+			VersionService: &fakeclient.SecretVersionService{
+				GetWithDataFunc:     s.versionGetWithData,
+				GetWithoutDataFunc:  s.versionGetWithoutData,
+				ListWithDataFunc:    s.versionListWithData,
+				ListWithoutDataFunc: s.versionListWithoutData,
+			},
+		},
+	}
+}
+
+func (s *Store) dirCreate(dirPath string) (*api.Dir, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.dirs[key(dirPath)]; exists {
+		return nil, api.ErrDirAlreadyExists
+	}
+	err := s.checkParent(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	d := s.createDir(dirPath)
+	return &api.Dir{
+		DirID:     d.id,
+		Name:      path.Base(d.path),
+		CreatedAt: d.createdAt,
+	}, nil
+}
+
+func (s *Store) dirCreateAll(dirPath string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.dirs[key(repoPath(dirPath))]; !exists {
+		return api.ErrRepoNotFound
+	}
+	s.createDirAll(dirPath)
+	return nil
+}
+
+func (s *Store) dirDelete(dirPath string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.dirs[key(dirPath)]; !exists {
+		return api.ErrDirNotFound
+	}
+
+	prefix := key(dirPath)
+	for k := range s.dirs {
+		if k == prefix || strings.HasPrefix(k, prefix+"/") {
+			delete(s.dirs, k)
+		}
+	}
+	for k := range s.secrets {
+		if strings.HasPrefix(k, prefix+"/") {
+			delete(s.secrets, k)
+		}
+	}
+	return nil
+}
+
+func (s *Store) dirExists(dirPath string) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, exists := s.dirs[key(dirPath)]
+	return exists, nil
+}
+
+// getTree returns the directory with its subdirectories and secrets up to the given depth.
+// A negative depth returns all levels.
+func (s *Store) getTree(dirPath string, depth int, ancestors bool) (*api.Tree, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	root, exists := s.dirs[key(dirPath)]
+	if !exists {
+		return nil, api.ErrDirNotFound
+	}
+
+	tree := &api.Tree{
+		ParentPath: api.ParentPath(path.Dir(root.path)),
+		Dirs:       map[uuid.UUID]*api.Dir{},
+		Secrets:    map[uuid.UUID]*api.Secret{},
+	}
+	tree.RootDir = s.treeDir(tree, root, nil, depth)
+	return tree, nil
+}
+
+// treeDir adds the directory and its contents to the tree.
+func (s *Store) treeDir(tree *api.Tree, d *storedDir, parentID *uuid.UUID, depth int) *api.Dir {
+	result := &api.Dir{
+		DirID:     d.id,
+		ParentID:  parentID,
+		Name:      path.Base(d.path),
+		CreatedAt: d.createdAt,
+	}
+	tree.Dirs[d.id] = result
+
+	if depth == 0 {
+		return result
+	}
+
+	for _, child := range s.sortedDirs() {
+		if parentKey(child.path) == key(d.path) {
+			result.SubDirs = append(result.SubDirs, s.treeDir(tree, child, &result.DirID, depth-1))
+		}
+	}
+	for _, secret := range s.sortedSecrets() {
+		if parentKey(secret.path) == key(d.path) {
+			apiSecret := s.apiSecret(secret)
+			apiSecret.DirID = d.id
+			result.Secrets = append(result.Secrets, apiSecret)
+			tree.Secrets[secret.id] = apiSecret
+		}
+	}
+	return result
+}
+
+func (s *Store) secretDelete(secretPath string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.secrets[key(secretPath)]; !exists {
+		return api.ErrSecretNotFound
+	}
+	delete(s.secrets, key(secretPath))
+	return nil
+}
+
+func (s *Store) secretExists(secretPath string) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, exists := s.secrets[key(secretPath)]
+	return exists, nil
+}
+
+func (s *Store) secretGet(secretPath string) (*api.Secret, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	secret, exists := s.secrets[key(secretPath)]
+	if !exists {
+		return nil, api.ErrSecretNotFound
+	}
+	return s.apiSecret(secret), nil
+}
+
+func (s *Store) secretWrite(secretPath string, data []byte) (*api.SecretVersion, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.secrets[key(secretPath)]; !exists {
+		err := s.checkParent(secretPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	version := s.writeSecret(secretPath, data)
+	return withoutData(version), nil
+}
+
+func (s *Store) versionGetWithData(secretPath string) (*api.SecretVersion, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	version, err := s.version(secretPath)
+	if err != nil {
+		return nil, err
+	}
+	return withData(version), nil
+}
+
+func (s *Store) versionGetWithoutData(secretPath string) (*api.SecretVersion, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	version, err := s.version(secretPath)
+	if err != nil {
+		return nil, err
+	}
+	return withoutData(version), nil
+}
+
+func (s *Store) versionListWithData(secretPath string) ([]*api.SecretVersion, error) {
+	return s.versionList(secretPath, withData)
+}
+
+func (s *Store) versionListWithoutData(secretPath string) ([]*api.SecretVersion, error) {
+	return s.versionList(secretPath, withoutData)
+}
+
+func (s *Store) versionList(secretPath string, copyVersion func(*api.SecretVersion) *api.SecretVersion) ([]*api.SecretVersion, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	secret, exists := s.secrets[key(secretPath)]
+	if !exists {
+		return nil, api.ErrSecretNotFound
+	}
+
+	versions := make([]*api.SecretVersion, len(secret.versions))
+	for i, version := range secret.versions {
+		versions[i] = copyVersion(version)
+	}
+	return versions, nil
+}
+
+// version returns the version of a secret path with an optional :<version> suffix.
+func (s *Store) version(secretPath string) (*api.SecretVersion, error) {
+	p, v := secretPath, ""
+	if i := strings.LastIndex(secretPath, ":"); i >= 0 {
+		p, v = secretPath[:i], secretPath[i+1:]
+	}
+
+	secret, exists := s.secrets[key(p)]
+	if !exists {
+		return nil, api.ErrSecretNotFound
+	}
+
+	if v == "" || v == "latest" {
+		return secret.versions[len(secret.versions)-1], nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > len(secret.versions) {
+		return nil, api.ErrSecretNotFound
+	}
+	return secret.versions[n-1], nil
+}
+
+// checkParent returns an error when the parent directory of the path does not exist.
+func (s *Store) checkParent(p string) error {
+	if _, exists := s.dirs[key(repoPath(p))]; !exists {
+		return api.ErrRepoNotFound
+	}
+	if _, exists := s.dirs[parentKey(p)]; !exists {
+		return api.ErrDirNotFound
+	}
+	return nil
+}
+
+func (s *Store) createDir(dirPath string) *storedDir {
+	d, exists := s.dirs[key(dirPath)]
+	if !exists {
+		d = &storedDir{
+			id:        uuid.New(),
+			path:      dirPath,
+			createdAt: s.Now(),
+		}
+		s.dirs[key(dirPath)] = d
+	}
+	return d
+}
+
+// createDirAll creates the directory and all its parents, including the repository.
+func (s *Store) createDirAll(dirPath string) {
+	elements := strings.Split(dirPath, "/")
+	for i := 2; i <= len(elements); i++ {
+		s.createDir(strings.Join(elements[:i], "/"))
+	}
+}
+
+func (s *Store) writeSecret(secretPath string, data []byte) *api.SecretVersion {
+	secret, exists := s.secrets[key(secretPath)]
+	if !exists {
+		secret = &storedSecret{
+			id:   uuid.New(),
+			path: secretPath,
+		}
+		s.secrets[key(secretPath)] = secret
+	}
+
+	version := &api.SecretVersion{
+		Version:   len(secret.versions) + 1,
+		Data:      append([]byte(nil), data...),
+		CreatedAt: s.Now(),
+		Status:    api.StatusOK,
+	}
+	secret.versions = append(secret.versions, version)
+	version.Secret = s.apiSecret(secret)
+	return version
+}
+
+func (s *Store) apiSecret(secret *storedSecret) *api.Secret {
+	return &api.Secret{
+		SecretID:     secret.id,
+		Name:         path.Base(secret.path),
+		VersionCount: len(secret.versions),
+		CreatedAt:    secret.versions[0].CreatedAt,
+		Status:       api.StatusOK,
+	}
+}
+
+func (s *Store) sortedDirs() []*storedDir {
+	dirs := make([]*storedDir, 0, len(s.dirs))
+	for _, d := range s.dirs {
+		dirs = append(dirs, d)
+	}
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].path < dirs[j].path })
+	return dirs
+}
+
+func (s *Store) sortedSecrets() []*storedSecret {
+	secrets := make([]*storedSecret, 0, len(s.secrets))
+	for _, secret := range s.secrets {
+		secrets = append(secrets, secret)
+	}
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].path < secrets[j].path })
+	return secrets
+}
+
+// withData returns a copy of the version, so that the caller cannot change the store.
+func withData(version *api.SecretVersion) *api.SecretVersion {
+	c := *version
+	c.Data = append([]byte(nil), version.Data...)
+	return &c
+}
+
+// withoutData returns a copy of the version without its value.
+func withoutData(version *api.SecretVersion) *api.SecretVersion {
+	c := *version
+	c.Data = nil
+	return &c
+}
+
+// key returns the case-insensitive key of a path.
+func key(p string) string {
+	return strings.ToLower(p)
+}
+
+// parentKey returns the key of the parent directory of a path.
+func parentKey(p string) string {
+	return key(path.Dir(p))
+}
+
+// repoPath returns the repository part of a path.
+func repoPath(p string) string {
+	elements := strings.SplitN(p, "/", 3)
+	if len(elements) < 2 {
+		return p
+	}
+	return elements[0] + "/" + elements[1]
+}
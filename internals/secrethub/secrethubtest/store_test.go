@@ -0,0 +1,63 @@
+package secrethubtest
+
+import (
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestStore(t *testing.T) {
+	store := NewStore()
+	store.WriteSecret("company/app/db/password", []byte("s3cr3t"))
+
+	client := store.Client()
+
+	// Secrets can be written in existing directories only.
+	_, err := client.Secrets().Write("company/app/api/key", []byte("abc"))
+	assert.Equal(t, err, api.ErrDirNotFound)
+	_, err = client.Secrets().Write("company/other/key", []byte("abc"))
+	assert.Equal(t, err, api.ErrRepoNotFound)
+
+	err = client.Dirs().CreateAll("company/app/api")
+	assert.OK(t, err)
+	version, err := client.Secrets().Write("company/app/api/key", []byte("abc"))
+	assert.OK(t, err)
+	assert.Equal(t, version.Version, 1)
+	assert.Equal(t, version.Data, []byte(nil))
+
+	_, err = client.Secrets().Write("company/app/db/password", []byte("n3w"))
+	assert.OK(t, err)
+
+	latest, err := client.Secrets().Versions().GetWithData("company/app/db/password")
+	assert.OK(t, err)
+	assert.Equal(t, latest.Version, 2)
+	assert.Equal(t, latest.Data, []byte("n3w"))
+
+	first, err := client.Secrets().Versions().GetWithData("Company/App/DB/password:1")
+	assert.OK(t, err)
+	assert.Equal(t, first.Data, []byte("s3cr3t"))
+
+	_, err = client.Secrets().Versions().GetWithData("company/app/db/password:3")
+	assert.Equal(t, err, api.ErrSecretNotFound)
+
+	tree, err := client.Dirs().GetTree("company/app", -1, false)
+	assert.OK(t, err)
+	assert.Equal(t, tree.SecretCount(), 2)
+	assert.Equal(t, len(tree.RootDir.SubDirs), 2)
+	assert.Equal(t, tree.RootDir.SubDirs[0].Name, "api")
+	assert.Equal(t, tree.RootDir.SubDirs[0].Secrets[0].Name, "key")
+
+	shallow, err := client.Dirs().GetTree("company/app", 1, false)
+	assert.OK(t, err)
+	assert.Equal(t, len(shallow.RootDir.SubDirs), 2)
+	assert.Equal(t, len(shallow.RootDir.SubDirs[0].Secrets), 0)
+
+	err = client.Dirs().Delete("company/app/db")
+	assert.OK(t, err)
+
+	assert.Equal(t, store.SecretPaths(), []string{"company/app/api/key"})
+	value, ok := store.Secret("company/app/api/key")
+	assert.Equal(t, ok, true)
+	assert.Equal(t, value, []byte("abc"))
+}
@@ -24,7 +24,10 @@ func (cmd *ServiceCommand) Register(r command.Registerer) {
 	clause := r.Command("service", "Manage service accounts.")
 	NewServiceAWSCommand(cmd.io, cmd.newClient).Register(clause)
 	NewServiceGCPCommand(cmd.io, cmd.newClient).Register(clause)
+	NewServiceDeactivateCommand(cmd.io, cmd.newClient).Register(clause)
 	NewServiceDeployCommand(cmd.io).Register(clause)
+	NewServiceExpireCommand(cmd.io, cmd.newClient).Register(clause)
 	NewServiceInitCommand(cmd.io, cmd.newClient).Register(clause)
 	NewServiceLsCommand(cmd.io, cmd.newClient).Register(clause)
+	NewServiceReactivateCommand(cmd.io, cmd.newClient).Register(clause)
 }
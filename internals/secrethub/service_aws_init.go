@@ -37,7 +37,7 @@ type ServiceAWSInitCommand struct {
 	kmsKeyID    string
 	role        string
 	region      string
-	permission  string
+	permission  []string
 	io          ui.IO
 	newClient   newClientFunc
 }
@@ -52,6 +52,11 @@ func NewServiceAWSInitCommand(io ui.IO, newClient newClientFunc) *ServiceAWSInit
 
 // Run initializes an AWS service.
 func (cmd *ServiceAWSInitCommand) Run() error {
+	permissions, err := parsePermissionFlags(cmd.repo, cmd.permission)
+	if err != nil {
+		return err
+	}
+
 	client, err := cmd.newClient()
 	if err != nil {
 		return err
@@ -139,17 +144,16 @@ func (cmd *ServiceAWSInitCommand) Run() error {
 		return err
 	}
 
-	if cmd.permission != "" {
-		err = givePermission(service, cmd.repo, cmd.permission, client)
-		if err != nil {
-			return err
-		}
+	err = givePermissions(service, permissions, client)
+	if err != nil {
+		return err
 	}
 
 	fmt.Fprintln(cmd.io.Output(), "Successfully created a new service account with ID: "+service.ServiceID)
 	fmt.Fprintf(cmd.io.Output(), "Any host that assumes the IAM role %s can now automatically authenticate to SecretHub and fetch the secrets the service has been given access to.\n", roleNameFromRole(cmd.role))
+	fmt.Fprintln(cmd.io.Output(), "To authenticate with the IAM role instead of a credential file, run the CLI on those hosts with --identity-provider=aws or set SECRETHUB_IDENTITY_PROVIDER=aws.")
 
-	return nil
+	return printPermissions(cmd.io.Output(), permissions)
 }
 
 // Register registers the command, arguments and flags on the provided Registerer.
@@ -162,7 +166,7 @@ func (cmd *ServiceAWSInitCommand) Register(r command.Registerer) {
 	clause.Flag("description", "A description for the service so others will recognize it. Defaults to the name of the role that is attached to the service.").StringVar(&cmd.description)
 	clause.Flag("descr", "").Hidden().StringVar(&cmd.description)
 	clause.Flag("desc", "").Hidden().StringVar(&cmd.description)
-	clause.Flag("permission", "Create an access rule giving the service account permission on a directory. Accepted permissions are `read`, `write` and `admin`. Use `--permission <permission>` to give permission on the root of the repo and `--permission <dir>[/<dir> ...]:<permission>` to give permission on a subdirectory.").StringVar(&cmd.permission)
+	registerPermissionFlag(clause).StringsVar(&cmd.permission)
 
 	clause.HelpLong("The native AWS identity provider uses a combination of AWS IAM and AWS KMS to provide access to SecretHub for any service running on AWS (e.g. EC2, Lambda or ECS). For this to work, an IAM role and a KMS key are needed.\n" +
 		"\n" +
@@ -171,7 +175,9 @@ func (cmd *ServiceAWSInitCommand) Register(r command.Registerer) {
 		"\n" +
 		"To create a new service that uses the AWS identity provider, the CLI must have encryption access to the KMS key that will be used by the service account. Therefore AWS credentials should be configured on this system. For details on how this can be done, see https://docs.aws.amazon.com/cli/latest/userguide/cli-chap-configure.html.\n" +
 		"\n" +
-		"If no system-wide default for the AWS region is provided (e.g. with $AWS_REGION), the AWS-region where the KMS key resides should be explicitly provided to this command with the --region flag.",
+		"If no system-wide default for the AWS region is provided (e.g. with $AWS_REGION), the AWS-region where the KMS key resides should be explicitly provided to this command with the --region flag.\n" +
+		"\n" +
+		"Workloads that assume the IAM role authenticate with it when the CLI is run with --identity-provider=aws or with SECRETHUB_IDENTITY_PROVIDER=aws set, so no credential file has to be copied into their image.",
 	)
 
 	command.BindAction(clause, cmd.Run)
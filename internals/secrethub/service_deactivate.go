@@ -0,0 +1,182 @@
+package secrethub
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+)
+
+// Errors
+var (
+	ErrInvalidServiceID             = errMain.Code("invalid_service_id").ErrorPref("%s is not the ID of a service account")
+	ErrServiceAlreadyDeactivated    = errMain.Code("service_already_deactivated").ErrorPref("service account %s is already deactivated: reactivate it with `" + ApplicationName + " service reactivate %s %s`")
+	ErrServiceNotDeactivated        = errMain.Code("service_not_deactivated").ErrorPref("service account %s is not deactivated")
+	ErrServiceDeactivationUntrusted = errMain.Code("service_deactivation_untrusted").ErrorPref("the access rules of service account %s were recorded by %s, who is not an admin of %s: restore them by hand")
+)
+
+const (
+	// deactivatedLabel is the label that holds the time a service account was deactivated, formatted as RFC3339.
+	deactivatedLabel = "deactivated"
+	// deactivatedRulesLabel is the label that holds the access rules a service account had before it was deactivated,
+	// in the format of the --permission flag of service init.
+	deactivatedRulesLabel = "deactivated-access-rules"
+)
+
+// ServiceDeactivateCommand removes the access rules of a service account, so that it can no longer
+// access any secrets, while keeping the service account and a record of its access rules.
+type ServiceDeactivateCommand struct {
+	repo      api.RepoPath
+	serviceID api.AccountName
+	force     bool
+	io        ui.IO
+	newClient newClientFunc
+	now       func() time.Time
+}
+
+// NewServiceDeactivateCommand creates a new ServiceDeactivateCommand.
+func NewServiceDeactivateCommand(io ui.IO, newClient newClientFunc) *ServiceDeactivateCommand {
+	return &ServiceDeactivateCommand{
+		io:        io,
+		newClient: newClient,
+		now:       time.Now,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *ServiceDeactivateCommand) Register(r command.Registerer) {
+	clause := r.Command("deactivate", "Freeze the access of a service account without deleting it.")
+	clause.HelpLong("Removes all access rules of the service account, so that it can no longer read or write any secrets, " +
+		"e.g. while investigating a possibly leaked credential. The service account and its credential are kept and " +
+		"its access rules are recorded in the metadata of the repository, so that " +
+		"`" + ApplicationName + " service reactivate` can restore them.\n" +
+		"\n" +
+		"Secrets the service account has already read are not affected: rotate them when the credential has leaked.")
+	clause.Arg("repo-path", "The repository the service account is attached to.").Required().PlaceHolder(repoPathPlaceHolder).SetValue(&cmd.repo)
+	clause.Arg("service-id", "The ID of the service account to deactivate.").Required().SetValue(&cmd.serviceID)
+	registerForceFlag(clause).BoolVar(&cmd.force)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run removes the access rules of the service account and records them in the metadata of the repository.
+func (cmd *ServiceDeactivateCommand) Run() error {
+	if !cmd.serviceID.IsService() {
+		return ErrInvalidServiceID(cmd.serviceID)
+	}
+	serviceID := cmd.serviceID.Value()
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	metadata, err := readRepoMetadata(client, cmd.repo.Value())
+	if err != nil {
+		return err
+	}
+	if metadata.Services[serviceID][deactivatedLabel] != "" {
+		return ErrServiceAlreadyDeactivated(serviceID, cmd.repo, serviceID)
+	}
+
+	permissions, err := listServicePermissions(client, cmd.repo, serviceID)
+	if err != nil {
+		return err
+	}
+
+	if !cmd.force {
+		msg := fmt.Sprintf("This removes the %d access rules of the service account %s. Do you want to continue?", len(permissions), serviceID)
+		confirmed, err := ui.AskYesNo(cmd.io, msg, ui.DefaultNo)
+		if err == ui.ErrCannotAsk {
+			return ErrCannotDoWithoutForce
+		} else if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			fmt.Fprintln(cmd.io.Output(), "Aborting.")
+			return nil
+		}
+	}
+
+	err = deactivateService(client, cmd.repo, metadata, serviceID, permissions, cmd.now())
+	if err != nil {
+		return err
+	}
+
+	if len(permissions) == 0 {
+		fmt.Fprintf(cmd.io.Output(), "Deactivated service account %s. It has no access rules.\n", serviceID)
+		return nil
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Deactivated service account %s by removing its access rules:\n", serviceID)
+	err = printPermissions(cmd.io.Output(), permissions)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.io.Output(), "Restore them with `%s service reactivate %s %s`.\n", ApplicationName, cmd.repo, serviceID)
+	return nil
+}
+
+// deactivateService records the access rules of the service account in the metadata of the repository
+// and then removes them.
+func deactivateService(client secrethub.ClientInterface, repo api.RepoPath, metadata *repoMetadata, serviceID string, permissions []servicePermission, now time.Time) error {
+	// Record the access rules before removing them, so that they can always be restored.
+	metadata.setService(serviceID, map[string]string{
+		deactivatedLabel:      now.UTC().Format(time.RFC3339),
+		deactivatedRulesLabel: formatPermissionFlags(permissions),
+	})
+	err := writeRepoMetadata(client, repo.Value(), metadata)
+	if err != nil {
+		return err
+	}
+
+	for _, permission := range permissions {
+		err = client.AccessRules().Delete(permission.path.Value(), serviceID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listServicePermissions returns the access rules of the service account in the repository.
+func listServicePermissions(client secrethub.ClientInterface, repo api.RepoPath, serviceID string) ([]servicePermission, error) {
+	rules, err := client.AccessRules().List(repo.GetDirPath().Value(), -1, false)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := client.Dirs().GetTree(repo.GetDirPath().Value(), -1, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var permissions []servicePermission
+	for _, rule := range rules {
+		if !strings.EqualFold(rule.Account.Name.Value(), serviceID) {
+			continue
+		}
+
+		path, err := tree.AbsDirPath(rule.DirID)
+		if err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, servicePermission{path: path, permission: rule.Permission})
+	}
+	return permissions, nil
+}
+
+// formatPermissionFlags formats the permissions in the <permission>:<path> format accepted by parsePermissionFlags.
+func formatPermissionFlags(permissions []servicePermission) string {
+	values := make([]string, len(permissions))
+	for i, permission := range permissions {
+		values[i] = permission.permission.String() + ":" + permission.path.Value()
+	}
+	return strings.Join(values, ",")
+}
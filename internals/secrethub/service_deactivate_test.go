@@ -0,0 +1,265 @@
+package secrethub
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestServiceDeactivateCommand_Run(t *testing.T) {
+	rootID := uuid.New()
+	dirID := uuid.New()
+
+	cases := map[string]struct {
+		serviceID api.AccountName
+		existing  string
+		deleted   []string
+		written   map[string]map[string]string
+		out       string
+		err       error
+	}{
+		"success": {
+			serviceID: "s-1234",
+			deleted:   []string{"namespace/repo", "namespace/repo/dir"},
+			written: map[string]map[string]string{
+				"s-1234": {
+					deactivatedLabel:      "2020-01-01T00:00:00Z",
+					deactivatedRulesLabel: "read:namespace/repo,write:namespace/repo/dir",
+				},
+			},
+			out: "" +
+				"Deactivated service account s-1234 by removing its access rules:\n" +
+				"PATH                PERMISSION\n" +
+				"namespace/repo      read\n" +
+				"namespace/repo/dir  write\n" +
+				"Restore them with `secrethub service reactivate namespace/repo s-1234`.\n",
+		},
+		"already deactivated": {
+			serviceID: "s-1234",
+			existing:  `{"labels":{},"services":{"s-1234":{"deactivated":"2019-01-01T00:00:00Z"}}}`,
+			err:       ErrServiceAlreadyDeactivated("s-1234", api.RepoPath("namespace/repo"), "s-1234"),
+		},
+		"user": {
+			serviceID: "developer",
+			err:       ErrInvalidServiceID(api.AccountName("developer")),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			io := fakeui.NewIO(t)
+			var deleted []string
+			var written []byte
+			cmd := ServiceDeactivateCommand{
+				repo:      "namespace/repo",
+				serviceID: tc.serviceID,
+				force:     true,
+				io:        io,
+				now: func() time.Time {
+					return time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+				},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						AccessRuleService: &fakeclient.AccessRuleService{
+							ListFunc: func(path string, depth int, ancestors bool) ([]*api.AccessRule, error) {
+								return []*api.AccessRule{
+									{Account: &api.Account{Name: "s-1234"}, DirID: rootID, Permission: api.PermissionRead},
+									{Account: &api.Account{Name: "developer"}, DirID: rootID, Permission: api.PermissionAdmin},
+									{Account: &api.Account{Name: "s-1234"}, DirID: dirID, Permission: api.PermissionWrite},
+								}, nil
+							},
+							DeleteFunc: func(path string, accountName string) error {
+								assert.Equal(t, accountName, "s-1234")
+								deleted = append(deleted, path)
+								return nil
+							},
+						},
+						DirService: &fakeclient.DirService{
+							GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+								return &api.Tree{
+									ParentPath: "namespace",
+									Dirs: map[uuid.UUID]*api.Dir{
+										rootID: {Name: "repo", DirID: rootID},
+										dirID:  {Name: "dir", DirID: dirID, ParentID: &rootID},
+									},
+									RootDir: &api.Dir{Name: "repo", DirID: rootID},
+								}, nil
+							},
+						},
+						RepoService: &fakeclient.RepoService{
+							CreateFunc: func(path string) (*api.Repo, error) {
+								return nil, api.ErrRepoAlreadyExists
+							},
+						},
+						SecretService: &fakeclient.SecretService{
+							WriteFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+								written = data
+								return &api.SecretVersion{}, nil
+							},
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+									if tc.existing == "" {
+										return nil, api.ErrSecretNotFound
+									}
+									return &api.SecretVersion{Data: []byte(tc.existing)}, nil
+								},
+							},
+						},
+					}, nil
+				},
+			}
+
+			// Act
+			err := cmd.Run()
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.Out.String(), tc.out)
+			assert.Equal(t, deleted, tc.deleted)
+			if tc.written != nil {
+				var metadata repoMetadata
+				err = json.Unmarshal(written, &metadata)
+				assert.OK(t, err)
+				assert.Equal(t, metadata.Services, tc.written)
+			}
+		})
+	}
+}
+
+func TestServiceReactivateCommand_Run(t *testing.T) {
+	cases := map[string]struct {
+		existing string
+		author   string
+		set      []string
+		written  map[string]map[string]string
+		out      string
+		err      error
+	}{
+		"success": {
+			existing: `{"labels":{},"services":{"s-1234":{` +
+				`"deactivated":"2020-01-01T00:00:00Z",` +
+				`"deactivated-access-rules":"read:namespace/repo,write:namespace/repo/dir",` +
+				`"expires":"2021-01-01T00:00:00Z"}}}`,
+			set: []string{"namespace/repo:read", "namespace/repo/dir:write"},
+			written: map[string]map[string]string{
+				"s-1234": {expiresLabel: "2021-01-01T00:00:00Z"},
+			},
+			out: "" +
+				"Reactivated service account s-1234 by restoring its access rules:\n" +
+				"PATH                PERMISSION\n" +
+				"namespace/repo      read\n" +
+				"namespace/repo/dir  write\n",
+		},
+		"recorded by non-admin": {
+			existing: `{"labels":{},"services":{"s-1234":{` +
+				`"deactivated":"2020-01-01T00:00:00Z",` +
+				`"deactivated-access-rules":"admin:namespace/repo"}}}`,
+			author: "dev2",
+			err:    ErrServiceDeactivationUntrusted("s-1234", "dev2", "namespace/repo"),
+		},
+		"not deactivated": {
+			existing: `{"labels":{},"services":{"s-1234":{"expires":"2021-01-01T00:00:00Z"}}}`,
+			err:      ErrServiceNotDeactivated("s-1234"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			io := fakeui.NewIO(t)
+			var set []string
+			var written []byte
+			author := "dev1"
+			if tc.author != "" {
+				author = tc.author
+			}
+			cmd := ServiceReactivateCommand{
+				repo:      "namespace/repo",
+				serviceID: "s-1234",
+				io:        io,
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						AccessRuleService: &fakeclient.AccessRuleService{
+							ListFunc: func(path string, depth int, ancestors bool) ([]*api.AccessRule, error) {
+								return []*api.AccessRule{
+									{Account: &api.Account{Name: "dev1"}, Permission: api.PermissionAdmin},
+									{Account: &api.Account{Name: "dev2"}, Permission: api.PermissionWrite},
+								}, nil
+							},
+							SetFunc: func(path string, permission string, accountName string) (*api.AccessRule, error) {
+								assert.Equal(t, accountName, "s-1234")
+								set = append(set, path+":"+permission)
+								return nil, nil
+							},
+						},
+						RepoService: &fakeclient.RepoService{
+							CreateFunc: func(path string) (*api.Repo, error) {
+								return nil, api.ErrRepoAlreadyExists
+							},
+						},
+						SecretService: &fakeclient.SecretService{
+							WriteFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+								written = data
+								return &api.SecretVersion{}, nil
+							},
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+									return &api.SecretVersion{Data: []byte(tc.existing)}, nil
+								},
+								ListWithDataFunc: func(path string) ([]*api.SecretVersion, error) {
+									return []*api.SecretVersion{
+										{Version: 1, Data: []byte(`{"labels":{}}`)},
+										{Version: 2, Data: []byte(tc.existing)},
+									}, nil
+								},
+							},
+							AuditEventIterator: &fakeclient.AuditEventIterator{
+								Events: []api.Audit{
+									{
+										Action: api.AuditActionCreate,
+										Actor:  api.AuditActor{Type: "user", User: &api.User{Username: author}},
+										Subject: api.AuditSubject{
+											Type:          api.AuditSubjectSecretVersion,
+											SecretVersion: &api.EncryptedSecretVersion{Version: 2},
+										},
+									},
+									{
+										Action: api.AuditActionCreate,
+										Actor:  api.AuditActor{Type: "user", User: &api.User{Username: "dev1"}},
+										Subject: api.AuditSubject{
+											Type:          api.AuditSubjectSecretVersion,
+											SecretVersion: &api.EncryptedSecretVersion{Version: 1},
+										},
+									},
+								},
+							},
+						},
+					}, nil
+				},
+			}
+
+			// Act
+			err := cmd.Run()
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.Out.String(), tc.out)
+			assert.Equal(t, set, tc.set)
+			if tc.written != nil {
+				var metadata repoMetadata
+				err = json.Unmarshal(written, &metadata)
+				assert.OK(t, err)
+				assert.Equal(t, metadata.Services, tc.written)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package secrethub
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// ServiceExpireCommand deactivates the service accounts of a repository whose expiry date has passed.
+type ServiceExpireCommand struct {
+	repo      api.RepoPath
+	force     bool
+	io        ui.IO
+	newClient newClientFunc
+	now       func() time.Time
+}
+
+// NewServiceExpireCommand creates a new ServiceExpireCommand.
+func NewServiceExpireCommand(io ui.IO, newClient newClientFunc) *ServiceExpireCommand {
+	return &ServiceExpireCommand{
+		io:        io,
+		newClient: newClient,
+		now:       time.Now,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *ServiceExpireCommand) Register(r command.Registerer) {
+	clause := r.Command("expire", "Deactivate the service accounts whose expiry date has passed.")
+	clause.HelpLong("Deactivates every service account of the repository with an expiry date that has passed, " +
+		"as set with `" + ApplicationName + " service init --expires-in`. " +
+		"Like `" + ApplicationName + " service deactivate`, this removes their access rules and records them in the metadata of the repository, " +
+		"so that `" + ApplicationName + " service reactivate` can restore them.\n" +
+		"\n" +
+		"SecretHub does not expire service accounts by itself, so run this command periodically, e.g. from cron with --force:\n" +
+		"\n" +
+		"    0 * * * * " + ApplicationName + " service expire company/app --force")
+	clause.Arg("repo-path", "The repository to deactivate the expired service accounts of.").Required().PlaceHolder(repoPathPlaceHolder).SetValue(&cmd.repo)
+	registerForceFlag(clause).BoolVar(&cmd.force)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run deactivates the service accounts whose expiry date has passed and that are not deactivated yet.
+func (cmd *ServiceExpireCommand) Run() error {
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	services, err := client.Services().List(cmd.repo.Value())
+	if err != nil {
+		return err
+	}
+
+	metadata, err := readRepoMetadata(client, cmd.repo.Value())
+	if err != nil {
+		return err
+	}
+
+	now := cmd.now()
+	var expired []string
+	expiries := map[string]time.Time{}
+	for _, service := range services {
+		expires, ok, err := metadata.serviceExpiry(service.ServiceID)
+		if err != nil {
+			return err
+		}
+		if !ok || expires.After(now) || metadata.Services[service.ServiceID][deactivatedLabel] != "" {
+			continue
+		}
+		expired = append(expired, service.ServiceID)
+		expiries[service.ServiceID] = expires
+	}
+	sort.Strings(expired)
+
+	if len(expired) == 0 {
+		fmt.Fprintln(cmd.io.Output(), "No service accounts have expired.")
+		return nil
+	}
+
+	if !cmd.force {
+		msg := fmt.Sprintf("This deactivates %s whose expiry date has passed: %s. Do you want to continue?",
+			pluralize("service account", "service accounts", len(expired)), strings.Join(expired, ", "))
+		confirmed, err := ui.AskYesNo(cmd.io, msg, ui.DefaultNo)
+		if err == ui.ErrCannotAsk {
+			return ErrCannotDoWithoutForce
+		} else if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			fmt.Fprintln(cmd.io.Output(), "Aborting.")
+			return nil
+		}
+	}
+
+	for _, serviceID := range expired {
+		permissions, err := listServicePermissions(client, cmd.repo, serviceID)
+		if err != nil {
+			return err
+		}
+
+		err = deactivateService(client, cmd.repo, metadata, serviceID, permissions, now)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.io.Output(), "Deactivated service account %s, which expired at %s, by removing %s.\n",
+			serviceID, expiries[serviceID].Format(time.RFC3339), pluralize("access rule", "access rules", len(permissions)))
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Restore the access rules of a service account with `%s service reactivate %s <service-id>`.\n", ApplicationName, cmd.repo)
+	return nil
+}
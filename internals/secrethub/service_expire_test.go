@@ -0,0 +1,144 @@
+package secrethub
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestServiceExpireCommand_Run(t *testing.T) {
+	rootID := uuid.New()
+
+	cases := map[string]struct {
+		existing string
+		deleted  []string
+		written  map[string]map[string]string
+		out      string
+		err      error
+	}{
+		"success": {
+			existing: `{"labels":{},"services":{` +
+				`"s-expired":{"expires":"2019-12-31T00:00:00Z"},` +
+				`"s-valid":{"expires":"2020-01-02T00:00:00Z"},` +
+				`"s-deactivated":{"expires":"2019-12-01T00:00:00Z","deactivated":"2019-12-02T00:00:00Z"}}}`,
+			deleted: []string{"namespace/repo"},
+			written: map[string]map[string]string{
+				"s-expired": {
+					expiresLabel:          "2019-12-31T00:00:00Z",
+					deactivatedLabel:      "2020-01-01T00:00:00Z",
+					deactivatedRulesLabel: "read:namespace/repo",
+				},
+				"s-valid": {expiresLabel: "2020-01-02T00:00:00Z"},
+				"s-deactivated": {
+					expiresLabel:     "2019-12-01T00:00:00Z",
+					deactivatedLabel: "2019-12-02T00:00:00Z",
+				},
+			},
+			out: "" +
+				"Deactivated service account s-expired, which expired at 2019-12-31T00:00:00Z, by removing 1 access rule.\n" +
+				"Restore the access rules of a service account with `secrethub service reactivate namespace/repo <service-id>`.\n",
+		},
+		"none expired": {
+			existing: `{"labels":{},"services":{"s-valid":{"expires":"2020-01-02T00:00:00Z"}}}`,
+			out:      "No service accounts have expired.\n",
+		},
+		"no metadata": {
+			out: "No service accounts have expired.\n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			io := fakeui.NewIO(t)
+			var deleted []string
+			var written []byte
+			cmd := ServiceExpireCommand{
+				repo:  "namespace/repo",
+				force: true,
+				io:    io,
+				now: func() time.Time {
+					return time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+				},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						AccessRuleService: &fakeclient.AccessRuleService{
+							ListFunc: func(path string, depth int, ancestors bool) ([]*api.AccessRule, error) {
+								return []*api.AccessRule{
+									{Account: &api.Account{Name: "s-expired"}, DirID: rootID, Permission: api.PermissionRead},
+									{Account: &api.Account{Name: "s-valid"}, DirID: rootID, Permission: api.PermissionRead},
+								}, nil
+							},
+							DeleteFunc: func(path string, accountName string) error {
+								assert.Equal(t, accountName, "s-expired")
+								deleted = append(deleted, path)
+								return nil
+							},
+						},
+						DirService: &fakeclient.DirService{
+							GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+								return &api.Tree{
+									ParentPath: "namespace",
+									Dirs: map[uuid.UUID]*api.Dir{
+										rootID: {Name: "repo", DirID: rootID},
+									},
+									RootDir: &api.Dir{Name: "repo", DirID: rootID},
+								}, nil
+							},
+						},
+						RepoService: &fakeclient.RepoService{
+							CreateFunc: func(path string) (*api.Repo, error) {
+								return nil, api.ErrRepoAlreadyExists
+							},
+						},
+						ServiceService: &fakeclient.ServiceService{
+							ListFunc: func(path string) ([]*api.Service, error) {
+								return []*api.Service{
+									{ServiceID: "s-deactivated"},
+									{ServiceID: "s-expired"},
+									{ServiceID: "s-valid"},
+								}, nil
+							},
+						},
+						SecretService: &fakeclient.SecretService{
+							WriteFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+								written = data
+								return &api.SecretVersion{}, nil
+							},
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+									if tc.existing == "" {
+										return nil, api.ErrSecretNotFound
+									}
+									return &api.SecretVersion{Data: []byte(tc.existing)}, nil
+								},
+							},
+						},
+					}, nil
+				},
+			}
+
+			// Act
+			err := cmd.Run()
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.Out.String(), tc.out)
+			assert.Equal(t, deleted, tc.deleted)
+			if tc.written != nil {
+				var metadata repoMetadata
+				err = json.Unmarshal(written, &metadata)
+				assert.OK(t, err)
+				assert.Equal(t, metadata.Services, tc.written)
+			}
+		})
+	}
+}
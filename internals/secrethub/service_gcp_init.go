@@ -30,7 +30,7 @@ type ServiceGCPInitCommand struct {
 	repo                api.RepoPath
 	kmsKeyResourceID    string
 	serviceAccountEmail string
-	permission          string
+	permission          []string
 	io                  ui.IO
 	newClient           newClientFunc
 }
@@ -45,6 +45,11 @@ func NewServiceGCPInitCommand(io ui.IO, newClient newClientFunc) *ServiceGCPInit
 
 // Run initializes an GCP service.
 func (cmd *ServiceGCPInitCommand) Run() error {
+	permissions, err := parsePermissionFlags(cmd.repo, cmd.permission)
+	if err != nil {
+		return err
+	}
+
 	client, err := cmd.newClient()
 	if err != nil {
 		return err
@@ -140,17 +145,16 @@ func (cmd *ServiceGCPInitCommand) Run() error {
 		return err
 	}
 
-	if cmd.permission != "" {
-		err = givePermission(service, cmd.repo, cmd.permission, client)
-		if err != nil {
-			return err
-		}
+	err = givePermissions(service, permissions, client)
+	if err != nil {
+		return err
 	}
 
 	fmt.Fprintln(cmd.io.Stdout(), "Successfully created a new service account with ID: "+service.ServiceID)
 	fmt.Fprintf(cmd.io.Stdout(), "Any host using the Service Account %s can now automatically authenticate to SecretHub and fetch the secrets the service has been given access to.\n", cmd.serviceAccountEmail)
+	fmt.Fprintln(cmd.io.Stdout(), "To authenticate with the Service Account instead of a credential file, run the CLI on those hosts (e.g. on GCE, GKE or Cloud Run) with --identity-provider=gcp or set SECRETHUB_IDENTITY_PROVIDER=gcp.")
 
-	return nil
+	return printPermissions(cmd.io.Stdout(), permissions)
 }
 
 // Register registers the command, arguments and flags on the provided Registerer.
@@ -162,14 +166,16 @@ func (cmd *ServiceGCPInitCommand) Register(r command.Registerer) {
 	clause.Flag("description", "A description for the service so others will recognize it. Defaults to the name of the role that is attached to the service.").StringVar(&cmd.description)
 	clause.Flag("descr", "").Hidden().StringVar(&cmd.description)
 	clause.Flag("desc", "").Hidden().StringVar(&cmd.description)
-	clause.Flag("permission", "Create an access rule giving the service account permission on a directory. Accepted permissions are `read`, `write` and `admin`. Use `--permission <permission>` to give permission on the root of the repo and `--permission <dir>[/<dir> ...]:<permission>` to give permission on a subdirectory.").StringVar(&cmd.permission)
+	registerPermissionFlag(clause).StringsVar(&cmd.permission)
 
 	clause.HelpLong("The native GCP identity provider uses a combination of GCP IAM and GCP KMS to provide access to SecretHub for any service running on GCP. For this to work, a GCP Service Account and a KMS key are needed.\n" +
 		"\n" +
 		"  - The GCP Service Account should be the service account that is assumed by the service during execution.\n" +
 		"  - The KMS key is a key that is used for encryption of the account. Decryption permission on this key must be granted to the previously described GCP Service Account.\n" +
 		"\n" +
-		"To create a new service that uses the GCP identity provider, the CLI must have encryption access to the KMS key that will be used by the service account. Therefore GCP application default credentials should be configured on this system. To achieve this, first install the Google Cloud SDK (https://cloud.google.com/sdk/docs/quickstarts) and then run `gcloud auth application-default login`.",
+		"To create a new service that uses the GCP identity provider, the CLI must have encryption access to the KMS key that will be used by the service account. Therefore GCP application default credentials should be configured on this system. To achieve this, first install the Google Cloud SDK (https://cloud.google.com/sdk/docs/quickstarts) and then run `gcloud auth application-default login`.\n" +
+		"\n" +
+		"Workloads that run as the GCP Service Account, e.g. on GCE, GKE or Cloud Run, authenticate with its identity token when the CLI is run with --identity-provider=gcp or with SECRETHUB_IDENTITY_PROVIDER=gcp set, so no SecretHub key material has to be distributed to them.",
 	)
 
 	command.BindAction(clause, cmd.Run)
@@ -1,11 +1,16 @@
 package secrethub
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"strings"
+	"text/tabwriter"
+	"time"
 
+	"github.com/secrethub/secrethub-cli/internals/cli"
 	"github.com/secrethub/secrethub-cli/internals/cli/clip"
 	"github.com/secrethub/secrethub-cli/internals/cli/filemode"
 	"github.com/secrethub/secrethub-cli/internals/cli/posix"
@@ -17,6 +22,18 @@ import (
 	"github.com/secrethub/secrethub-go/pkg/secrethub/credentials"
 )
 
+// Errors
+var (
+	ErrUnknownServiceOutFormat = errMain.Code("unknown_out_format").ErrorPref("unknown output format %s: the options are text, json, github-actions and gitlab-dotenv")
+)
+
+const (
+	serviceOutFormatText          = "text"
+	serviceOutFormatJSON          = "json"
+	serviceOutFormatGitHubActions = "github-actions"
+	serviceOutFormatGitLabDotenv  = "gitlab-dotenv"
+)
+
 // ServiceInitCommand initializes a service and writes the generated config to stdout.
 type ServiceInitCommand struct {
 	clip        bool
@@ -24,10 +41,14 @@ type ServiceInitCommand struct {
 	file        string
 	fileMode    filemode.FileMode
 	repo        api.RepoPath
-	permission  string
+	permission  []string
+	expiresIn   dayDuration
+	outFormat   string
 	clipper     clip.Clipper
 	io          ui.IO
 	newClient   newClientFunc
+	getenv      func(key string) string
+	now         func() time.Time
 }
 
 // NewServiceInitCommand creates a new ServiceInitCommand.
@@ -36,6 +57,8 @@ func NewServiceInitCommand(io ui.IO, newClient newClientFunc) *ServiceInitComman
 		clipper:   clip.NewClipboard(),
 		io:        io,
 		newClient: newClient,
+		getenv:    os.Getenv,
+		now:       time.Now,
 	}
 }
 
@@ -54,6 +77,21 @@ func (cmd *ServiceInitCommand) Run() error {
 		return ErrFlagsConflict("--clip and --file")
 	}
 
+	switch cmd.outFormat {
+	case serviceOutFormatText, serviceOutFormatJSON, serviceOutFormatGitLabDotenv:
+	case serviceOutFormatGitHubActions:
+		if cmd.clip || cmd.file != "" {
+			return ErrFlagsConflict("--out-format github-actions and --clip or --out-file")
+		}
+	default:
+		return ErrUnknownServiceOutFormat(cmd.outFormat)
+	}
+
+	permissions, err := parsePermissionFlags(cmd.repo, cmd.permission)
+	if err != nil {
+		return err
+	}
+
 	client, err := cmd.newClient()
 	if err != nil {
 		return err
@@ -65,13 +103,26 @@ func (cmd *ServiceInitCommand) Run() error {
 		return err
 	}
 
-	if cmd.permission != "" {
-		err = givePermission(service, cmd.repo, cmd.permission, client)
-		if err != nil {
-			return err
-		}
+	err = givePermissions(service, permissions, client)
+	if err != nil {
+		return err
+	}
+
+	expires, err := cmd.setExpiry(client, service)
+	if err != nil {
+		return err
+	}
+
+	exported, err := credential.Export()
+	if err != nil {
+		return err
+	}
+
+	if cmd.outFormat == serviceOutFormatGitHubActions {
+		return cmd.writeGitHubActionsOutput(service, exported)
 	}
-	out, err := credential.Export()
+
+	out, err := cmd.formatCredential(service, exported, expires)
 	if err != nil {
 		return err
 	}
@@ -83,6 +134,11 @@ func (cmd *ServiceInitCommand) Run() error {
 		}
 
 		fmt.Fprintf(cmd.io.Output(), "Copied account configuration for %s to clipboard. It will be cleared after 45 seconds.\n", service.ServiceID)
+		cmd.printExpiry(expires)
+		err = printPermissions(cmd.io.Output(), permissions)
+		if err != nil {
+			return err
+		}
 	} else if cmd.file != "" {
 		err = ioutil.WriteFile(cmd.file, posix.AddNewLine(out), cmd.fileMode.FileMode())
 		if err != nil {
@@ -95,6 +151,11 @@ func (cmd *ServiceInitCommand) Run() error {
 			service.ServiceID,
 			cmd.file,
 		)
+		cmd.printExpiry(expires)
+		err = printPermissions(cmd.io.Output(), permissions)
+		if err != nil {
+			return err
+		}
 	} else {
 		fmt.Fprintf(cmd.io.Output(), "%s", posix.AddNewLine(out))
 	}
@@ -109,36 +170,179 @@ func (cmd *ServiceInitCommand) Register(r command.Registerer) {
 	clause.Flag("description", "A description for the service so others will recognize it.").StringVar(&cmd.description)
 	clause.Flag("descr", "").Hidden().StringVar(&cmd.description)
 	clause.Flag("desc", "").Hidden().StringVar(&cmd.description)
-	clause.Flag("permission", "Create an access rule giving the service account permission on a directory. Accepted permissions are `read`, `write` and `admin`. Use `--permission <permission>` to give permission on the root of the repo and `--permission <dir>[/<dir> ...]:<permission>` to give permission on a subdirectory.").StringVar(&cmd.permission)
+	clause.Flag("expires-in", "Set the service account to expire after this period, e.g. 30d. `"+ApplicationName+" service expire <repo>` deactivates it once the period has passed, so run that command periodically. Use `"+ApplicationName+" service ls <repo> --expiring-within 7d` to list the service accounts that need to be renewed.").PlaceHolder("30d").SetValue(&cmd.expiresIn)
+	registerPermissionFlag(clause).StringsVar(&cmd.permission)
 	// TODO make 45 sec configurable
 	clause.Flag("clip", "Write the service account configuration to the clipboard instead of stdout. The clipboard is automatically cleared after 45 seconds.").Short('c').BoolVar(&cmd.clip)
 	clause.Flag("file", "Write the service account configuration to a file instead of stdout.").Hidden().StringVar(&cmd.file)
 	clause.Flag("out-file", "Write the service account configuration to a file instead of stdout.").StringVar(&cmd.file)
 	clause.Flag("file-mode", "Set filemode for the written file. Defaults to 0440 (read only) and is ignored without the --file flag.").Default("0440").SetValue(&cmd.fileMode)
+	clause.Flag("out-format", "The format of the service account configuration, so that CI pipelines can capture it: "+
+		"`text` writes the credential only, `json` writes an object with the service ID, repository, credential and expiry date, "+
+		"`gitlab-dotenv` writes SECRETHUB_CREDENTIAL and SECRETHUB_SERVICE_ID variables for a GitLab dotenv report, "+
+		"and `github-actions` masks the credential in the job log and sets the `credential` and `service-id` outputs of the step.").
+		Default(serviceOutFormatText).HintOptions(serviceOutFormatText, serviceOutFormatJSON, serviceOutFormatGitHubActions, serviceOutFormatGitLabDotenv).StringVar(&cmd.outFormat)
 
 	command.BindAction(clause, cmd.Run)
 }
 
-// givePermission gives the service permission on the repository as defined in the permission flag.
-// When the permission flag is given in the format <permission>, the permission is given on the root directory of the repository.
-// When the permission flag is given in the format <subdirectory>:<permission>, the permission is given on the given subdirectory of the
-// repo.
-func givePermission(service *api.Service, repo api.RepoPath, permissionFlagValue string, client secrethub.ClientInterface) error {
-	subdir, permissionValue := parsePermissionFlag(permissionFlagValue)
+// serviceInitJSONOutput is the service account configuration written by --out-format json.
+type serviceInitJSONOutput struct {
+	ServiceID  string `json:"service_id"`
+	Repo       string `json:"repo"`
+	Credential string `json:"credential"`
+	Expires    string `json:"expires,omitempty"`
+}
 
-	permissionPath, err := api.NewDirPath(api.JoinPaths(repo.GetDirPath().String(), subdir))
-	if err != nil {
-		return ErrInvalidPermissionPath(err)
+// formatCredential formats the exported credential of the service account in the --out-format.
+func (cmd *ServiceInitCommand) formatCredential(service *api.Service, exported []byte, expires time.Time) ([]byte, error) {
+	switch cmd.outFormat {
+	case serviceOutFormatJSON:
+		output := serviceInitJSONOutput{
+			ServiceID:  service.ServiceID,
+			Repo:       cmd.repo.String(),
+			Credential: string(exported),
+		}
+		if !expires.IsZero() {
+			output.Expires = expires.Format(time.RFC3339)
+		}
+		return json.Marshal(output)
+	case serviceOutFormatGitLabDotenv:
+		return []byte(fmt.Sprintf("SECRETHUB_CREDENTIAL=%s\nSECRETHUB_SERVICE_ID=%s\n", exported, service.ServiceID)), nil
+	default:
+		return exported, nil
 	}
+}
 
-	var permission api.Permission
-	err = permission.Set(permissionValue)
+// writeGitHubActionsOutput masks the credential in the log of the GitHub Actions job and sets it as an output of the step.
+// The outputs are written to the file in GITHUB_OUTPUT, or with the set-output workflow command on runners that do not set it.
+func (cmd *ServiceInitCommand) writeGitHubActionsOutput(service *api.Service, exported []byte) error {
+	fmt.Fprintf(cmd.io.Output(), "::add-mask::%s\n", exported)
+
+	outputs := [][2]string{
+		{"service-id", service.ServiceID},
+		{"credential", string(exported)},
+	}
+
+	outputFile := cmd.getenv("GITHUB_OUTPUT")
+	if outputFile == "" {
+		for _, output := range outputs {
+			fmt.Fprintf(cmd.io.Output(), "::set-output name=%s::%s\n", output[0], output[1])
+		}
+	} else {
+		f, err := os.OpenFile(outputFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return ErrCannotWrite(outputFile, err)
+		}
+		for _, output := range outputs {
+			fmt.Fprintf(f, "%s=%s\n", output[0], output[1])
+		}
+		err = f.Close()
+		if err != nil {
+			return ErrCannotWrite(outputFile, err)
+		}
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Created service account %s. Its credential is available in the credential output of this step.\n", service.ServiceID)
+	return nil
+}
+
+// setExpiry records the expiry date of the service account in the metadata of the repository when --expires-in is set.
+// When this fails, the service account is removed, so that it does not exist without an expiry date.
+func (cmd *ServiceInitCommand) setExpiry(client secrethub.ClientInterface, service *api.Service) (time.Time, error) {
+	if cmd.expiresIn == 0 {
+		return time.Time{}, nil
+	}
+
+	expires := cmd.now().UTC().Add(time.Duration(cmd.expiresIn)).Truncate(time.Second)
+	metadata, err := readRepoMetadata(client, cmd.repo.Value())
+	if err == nil {
+		metadata.setService(service.ServiceID, map[string]string{expiresLabel: expires.Format(time.RFC3339)})
+		err = writeRepoMetadata(client, cmd.repo.Value(), metadata)
+	}
 	if err != nil {
-		return err
+		_, delErr := client.Services().Delete(service.ServiceID)
+		if delErr != nil {
+			fmt.Fprintf(os.Stderr, "Failed to cleanup after recording the expiry date of %s failed. Be sure to manually remove the created service account %s: %s\n", service.ServiceID, service.ServiceID, err)
+			return time.Time{}, delErr
+		}
+		return time.Time{}, err
+	}
+	return expires, nil
+}
+
+// printExpiry prints when the service account expires, if it has an expiry date.
+func (cmd *ServiceInitCommand) printExpiry(expires time.Time) {
+	if !expires.IsZero() {
+		fmt.Fprintf(cmd.io.Output(), "The service account expires at %s.\n", expires.Format(time.RFC3339))
 	}
+}
 
-	if permission != 0 {
-		_, err := client.AccessRules().Set(permissionPath.Value(), permission.String(), service.ServiceID)
+// servicePermission is a permission on a directory that is given to a service account when it is created.
+type servicePermission struct {
+	path       api.DirPath
+	permission api.Permission
+}
+
+// parsePermissionFlags parses the values of the permission flag. Every value can contain multiple
+// comma-separated permissions in one of the following formats:
+//   - <permission> gives the permission on the root directory of the repository.
+//   - <subdirectory>:<permission> gives the permission on the subdirectory of the repository.
+//   - <permission>:<namespace>/<repo>[/<dir> ...] gives the permission on the directory, which must be in the repository.
+func parsePermissionFlags(repo api.RepoPath, values []string) ([]servicePermission, error) {
+	var permissions []servicePermission
+	for _, value := range values {
+		for _, flag := range strings.Split(value, ",") {
+			flag = strings.TrimSpace(flag)
+			if flag == "" {
+				continue
+			}
+
+			path, permissionValue := parsePermissionFlag(repo, flag)
+			dirPath, err := api.NewDirPath(path)
+			if err != nil {
+				return nil, ErrInvalidPermissionPath(err)
+			}
+			if !isSubPath(dirPath.Value(), repo.Value()) {
+				return nil, ErrInvalidPermissionPath(fmt.Sprintf("%s is not in the repository %s", dirPath, repo))
+			}
+
+			var permission api.Permission
+			err = permission.Set(permissionValue)
+			if err != nil {
+				return nil, err
+			}
+			if permission != 0 {
+				permissions = append(permissions, servicePermission{path: dirPath, permission: permission})
+			}
+		}
+	}
+	return permissions, nil
+}
+
+// parsePermissionFlag parses a single permission into a permission and the path of the directory to give it on.
+func parsePermissionFlag(repo api.RepoPath, value string) (path string, permission string) {
+	values := strings.SplitN(value, ":", 2)
+	if len(values) == 1 {
+		return repo.GetDirPath().String(), values[0]
+	}
+	if !isPermission(values[1]) && isPermission(values[0]) {
+		return values[1], values[0]
+	}
+	return api.JoinPaths(repo.GetDirPath().String(), values[0]), values[1]
+}
+
+// isPermission returns whether the value is the name of a permission.
+func isPermission(value string) bool {
+	var permission api.Permission
+	return permission.Set(value) == nil
+}
+
+// givePermissions creates the access rules that give the service account its permissions.
+// When this fails, the service account is removed, so that it does not exist with only part of its permissions.
+func givePermissions(service *api.Service, permissions []servicePermission, client secrethub.ClientInterface) error {
+	for _, permission := range permissions {
+		_, err := client.AccessRules().Set(permission.path.Value(), permission.permission.String(), service.ServiceID)
 		if err != nil {
 			_, delErr := client.Services().Delete(service.ServiceID)
 			if delErr != nil {
@@ -153,12 +357,24 @@ func givePermission(service *api.Service, repo api.RepoPath, permissionFlagValue
 	return nil
 }
 
-// parsePermissionFlag parses a permission flag into a permission and a subdirectory to give
-// the permission on.
-func parsePermissionFlag(value string) (subdir string, permission string) {
-	values := strings.SplitN(value, ":", 2)
-	if len(values) == 1 {
-		return "", values[0]
+// printPermissions prints a table with the access rules that were created for the service account.
+func printPermissions(w io.Writer, permissions []servicePermission) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(tw, "PATH\tPERMISSION")
+	for _, permission := range permissions {
+		fmt.Fprintf(tw, "%s\t%s\n", permission.path, permission.permission)
 	}
-	return values[0], values[1]
+	return tw.Flush()
+}
+
+// registerPermissionFlag registers the flag that gives a new service account permissions.
+func registerPermissionFlag(r FlagRegisterer) *cli.Flag {
+	return r.Flag("permission", "Create access rules giving the service account permission on directories. Accepted permissions are `read`, `write` and `admin`. "+
+		"Use `--permission <permission>` to give permission on the root of the repo, `--permission <dir>[/<dir> ...]:<permission>` to give permission on a subdirectory "+
+		"and `--permission <permission>:<namespace>/<repo>[/<dir> ...]` to give permission on a directory by its full path. "+
+		"Multiple permissions can be separated by commas or given by repeating the flag, e.g. `--permission read:company/app/dev,write:company/app/dev/logs`.")
 }
@@ -0,0 +1,196 @@
+package secrethub
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestParsePermissionFlags(t *testing.T) {
+	cases := map[string]struct {
+		values   []string
+		expected []servicePermission
+		err      error
+	}{
+		"no permission": {
+			values:   nil,
+			expected: nil,
+		},
+		"repo root": {
+			values: []string{"read"},
+			expected: []servicePermission{
+				{path: api.DirPath("namespace/repo"), permission: api.PermissionRead},
+			},
+		},
+		"subdirectory": {
+			values: []string{"dir1/dir2:write"},
+			expected: []servicePermission{
+				{path: api.DirPath("namespace/repo/dir1/dir2"), permission: api.PermissionWrite},
+			},
+		},
+		"full path": {
+			values: []string{"admin:namespace/repo/dir1"},
+			expected: []servicePermission{
+				{path: api.DirPath("namespace/repo/dir1"), permission: api.PermissionAdmin},
+			},
+		},
+		"comma separated": {
+			values: []string{"read:namespace/repo/dir1,write:namespace/repo/dir2"},
+			expected: []servicePermission{
+				{path: api.DirPath("namespace/repo/dir1"), permission: api.PermissionRead},
+				{path: api.DirPath("namespace/repo/dir2"), permission: api.PermissionWrite},
+			},
+		},
+		"repeated flag": {
+			values: []string{"read", "dir1:write"},
+			expected: []servicePermission{
+				{path: api.DirPath("namespace/repo"), permission: api.PermissionRead},
+				{path: api.DirPath("namespace/repo/dir1"), permission: api.PermissionWrite},
+			},
+		},
+		"subdirectory named after permission": {
+			values: []string{"read:write"},
+			expected: []servicePermission{
+				{path: api.DirPath("namespace/repo/read"), permission: api.PermissionWrite},
+			},
+		},
+		"other repo": {
+			values: []string{"read:namespace/other/dir1"},
+			err:    ErrInvalidPermissionPath("namespace/other/dir1 is not in the repository namespace/repo"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			actual, err := parsePermissionFlags(api.RepoPath("namespace/repo"), tc.values)
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
+
+func TestPrintPermissions(t *testing.T) {
+	// Arrange
+	permissions := []servicePermission{
+		{path: api.DirPath("namespace/repo/dir1"), permission: api.PermissionRead},
+		{path: api.DirPath("namespace/repo/dir2"), permission: api.PermissionWrite},
+	}
+	buf := bytes.Buffer{}
+
+	// Act
+	err := printPermissions(&buf, permissions)
+
+	// Assert
+	assert.OK(t, err)
+	assert.Equal(t, buf.String(), ""+
+		"PATH                 PERMISSION\n"+
+		"namespace/repo/dir1  read\n"+
+		"namespace/repo/dir2  write\n",
+	)
+}
+
+func TestServiceInitCommand_formatCredential(t *testing.T) {
+	cases := map[string]struct {
+		outFormat string
+		expires   time.Time
+		expected  string
+	}{
+		"text": {
+			outFormat: serviceOutFormatText,
+			expected:  "credential",
+		},
+		"json": {
+			outFormat: serviceOutFormatJSON,
+			expected:  `{"service_id":"s-1234","repo":"namespace/repo","credential":"credential"}`,
+		},
+		"json with expiry": {
+			outFormat: serviceOutFormatJSON,
+			expires:   time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			expected:  `{"service_id":"s-1234","repo":"namespace/repo","credential":"credential","expires":"2020-01-01T00:00:00Z"}`,
+		},
+		"gitlab dotenv": {
+			outFormat: serviceOutFormatGitLabDotenv,
+			expected:  "SECRETHUB_CREDENTIAL=credential\nSECRETHUB_SERVICE_ID=s-1234\n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			cmd := ServiceInitCommand{
+				repo:      "namespace/repo",
+				outFormat: tc.outFormat,
+			}
+
+			// Act
+			actual, err := cmd.formatCredential(&api.Service{ServiceID: "s-1234"}, []byte("credential"), tc.expires)
+
+			// Assert
+			assert.OK(t, err)
+			assert.Equal(t, string(actual), tc.expected)
+		})
+	}
+}
+
+func TestServiceInitCommand_writeGitHubActionsOutput(t *testing.T) {
+	dir, cleanup := testdata.tempDir(t)
+	defer cleanup()
+	outputFile := filepath.Join(dir, "output")
+
+	cases := map[string]struct {
+		outputFile string
+		out        string
+		written    string
+	}{
+		"output file": {
+			outputFile: outputFile,
+			out: "" +
+				"::add-mask::credential\n" +
+				"Created service account s-1234. Its credential is available in the credential output of this step.\n",
+			written: "service-id=s-1234\ncredential=credential\n",
+		},
+		"set-output": {
+			out: "" +
+				"::add-mask::credential\n" +
+				"::set-output name=service-id::s-1234\n" +
+				"::set-output name=credential::credential\n" +
+				"Created service account s-1234. Its credential is available in the credential output of this step.\n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			io := fakeui.NewIO(t)
+			cmd := ServiceInitCommand{
+				io: io,
+				getenv: func(key string) string {
+					assert.Equal(t, key, "GITHUB_OUTPUT")
+					return tc.outputFile
+				},
+			}
+
+			// Act
+			err := cmd.writeGitHubActionsOutput(&api.Service{ServiceID: "s-1234"}, []byte("credential"))
+
+			// Assert
+			assert.OK(t, err)
+			assert.Equal(t, io.Out.String(), tc.out)
+			if tc.outputFile != "" {
+				written, err := ioutil.ReadFile(tc.outputFile)
+				assert.OK(t, err)
+				assert.Equal(t, string(written), tc.written)
+			}
+		})
+	}
+}
@@ -2,19 +2,28 @@ package secrethub
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
 
 	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/iterator"
 )
 
 // ServiceLsCommand lists all service accounts in a given repository.
 type ServiceLsCommand struct {
-	repoPath api.RepoPath
-	quiet    bool
+	repoPath       api.RepoPath
+	repos          repoPathList
+	quiet          bool
+	json           bool
+	expiringWithin dayDuration
+	createdBy      string
+	lastUsedBefore dayDuration
 
 	io              ui.IO
 	useTimestamps   bool
@@ -22,6 +31,7 @@ type ServiceLsCommand struct {
 	newServiceTable func(t TimeFormatter) serviceTable
 	filters         []func(service *api.Service) bool
 	help            string
+	now             func() time.Time
 }
 
 // NewServiceLsCommand creates a new ServiceLsCommand.
@@ -31,6 +41,7 @@ func NewServiceLsCommand(io ui.IO, newClient newClientFunc) *ServiceLsCommand {
 		newClient:       newClient,
 		newServiceTable: newKeyServiceTable,
 		help:            "List all service accounts in a given repository.",
+		now:             time.Now,
 	}
 }
 
@@ -43,6 +54,7 @@ func NewServiceAWSLsCommand(io ui.IO, newClient newClientFunc) *ServiceLsCommand
 			isAWSService,
 		},
 		help: "List all AWS service accounts in a given repository.",
+		now:  time.Now,
 	}
 }
 
@@ -55,6 +67,7 @@ func NewServiceGCPLsCommand(io ui.IO, newClient newClientFunc) *ServiceLsCommand
 			isGCPService,
 		},
 		help: "List all GCP service accounts in a given repository.",
+		now:  time.Now,
 	}
 }
 
@@ -63,59 +76,267 @@ func (cmd *ServiceLsCommand) Register(r command.Registerer) {
 	clause := r.Command("ls", cmd.help)
 	clause.Alias("list")
 	clause.Arg("repo-path", "The path to the repository to list services for").Required().PlaceHolder(repoPathPlaceHolder).SetValue(&cmd.repoPath)
+	clause.Flag("repo", "Also list the service accounts in this repository. Can be repeated. When service accounts of more than one repository are listed, their repositories are shown.").PlaceHolder(repoPathPlaceHolder).SetValue(&cmd.repos)
 	clause.Flag("quiet", "Only print service IDs.").Short('q').BoolVar(&cmd.quiet)
+	clause.Flag("json", "Print the service accounts as JSON objects, one per line, with timestamps formatted to RFC3339. Use this when the output is parsed by a script.").BoolVar(&cmd.json)
+	clause.Flag("created-by", "Only list the service accounts created by this user, according to the audit log of the repository.").PlaceHolder("<username>").StringVar(&cmd.createdBy)
+	clause.Flag("last-used-before", "Only list the service accounts that have not been used within this period, e.g. 90d, and show when they were last used, according to the audit log of the repository.").PlaceHolder("90d").SetValue(&cmd.lastUsedBefore)
+	clause.Flag("expiring-within", "Only list the service accounts with an expiry date that has passed or is within this period, e.g. 7d, and show their expiry dates. Set the expiry date with `"+ApplicationName+" service init --expires-in 30d`.").PlaceHolder("7d").SetValue(&cmd.expiringWithin)
 	registerTimestampFlag(clause).BoolVar(&cmd.useTimestamps)
 
 	command.BindAction(clause, cmd.Run)
 }
 
-// Run lists all service accounts in a given repository.
 // Run lists all service accounts in a given repository.
 func (cmd *ServiceLsCommand) Run() error {
-	client, err := cmd.newClient()
-	if err != nil {
-		return err
+	if cmd.quiet && cmd.json {
+		return ErrFlagsConflict("--quiet and --json")
 	}
 
-	services, err := client.Services().List(cmd.repoPath.Value())
+	client, err := cmd.newClient()
 	if err != nil {
 		return err
 	}
 
+	repos := cmd.repoPaths()
 	included := []*api.Service{}
-outer:
-	for _, service := range services {
-		for _, filter := range cmd.filters {
-			if !filter(service) {
-				continue outer
+	serviceRepos := map[string]api.RepoPath{}
+	for _, repo := range repos {
+		services, err := client.Services().List(repo.Value())
+		if err != nil {
+			return err
+		}
+
+	outer:
+		for _, service := range services {
+			for _, filter := range cmd.filters {
+				if !filter(service) {
+					continue outer
+				}
 			}
+			included = append(included, service)
+			serviceRepos[service.ServiceID] = repo
+		}
+	}
+
+	var now time.Time
+	if cmd.expiringWithin > 0 || cmd.lastUsedBefore > 0 {
+		now = cmd.now()
+	}
+
+	var metadata *repoMetadata
+	if cmd.expiringWithin > 0 {
+		metadata, err = readServicesMetadata(client, repos)
+		if err != nil {
+			return err
+		}
+	}
+
+	var activity map[string]*serviceActivity
+	if cmd.createdBy != "" || cmd.lastUsedBefore > 0 {
+		activity, err = readServiceActivity(client, repos, included)
+		if err != nil {
+			return err
+		}
+		included = cmd.filterActivity(included, activity, now)
+	}
+
+	var expiries map[string]time.Time
+	if cmd.expiringWithin > 0 {
+		included, expiries, err = cmd.filterExpiring(metadata, included, now)
+		if err != nil {
+			return err
 		}
-		included = append(included, service)
 	}
 
 	if cmd.quiet {
 		for _, service := range included {
 			fmt.Fprintf(cmd.io.Output(), "%s\n", service.ServiceID)
 		}
-	} else {
-		w := tabwriter.NewWriter(cmd.io.Output(), 0, 2, 2, ' ', 0)
-		serviceTable := cmd.newServiceTable(NewTimeFormatter(cmd.useTimestamps))
+		return nil
+	}
 
-		fmt.Fprintln(w, strings.Join(serviceTable.header(), "\t"))
+	timestamps := cmd.useTimestamps || cmd.json
+	serviceTable := cmd.newServiceTable(NewTimeFormatter(timestamps))
+	if len(repos) > 1 {
+		serviceTable = repoServiceTable{
+			serviceTable: serviceTable,
+			repos:        serviceRepos,
+		}
+	}
+	if cmd.lastUsedBefore > 0 {
+		serviceTable = lastUsedServiceTable{
+			serviceTable:  serviceTable,
+			activity:      activity,
+			timeFormatter: NewTimeFormatter(timestamps),
+		}
+	}
+	if expiries != nil {
+		serviceTable = expiringServiceTable{
+			serviceTable: serviceTable,
+			expiries:     expiries,
+			now:          now,
+			timestamps:   timestamps,
+		}
+	}
 
+	if cmd.json {
+		formatter := newJSONFormatter(cmd.io.Output(), serviceJSONFieldNames(serviceTable.header()))
 		for _, service := range included {
-			fmt.Fprintln(w, strings.Join(serviceTable.row(service), "\t"))
+			err = formatter.Write(serviceTable.row(service))
+			if err != nil {
+				return err
+			}
 		}
+		return nil
+	}
 
-		err = w.Flush()
-		if err != nil {
-			return err
+	w := tabwriter.NewWriter(cmd.io.Output(), 0, 2, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(serviceTable.header(), "\t"))
+
+	for _, service := range included {
+		fmt.Fprintln(w, strings.Join(serviceTable.row(service), "\t"))
+	}
+
+	return w.Flush()
+}
+
+// repoPaths returns the repositories to list the service accounts of, without duplicates.
+func (cmd *ServiceLsCommand) repoPaths() []api.RepoPath {
+	repos := []api.RepoPath{cmd.repoPath}
+	seen := map[string]bool{strings.ToLower(cmd.repoPath.Value()): true}
+	for _, repo := range cmd.repos {
+		key := strings.ToLower(repo.Value())
+		if !seen[key] {
+			repos = append(repos, repo)
+			seen[key] = true
 		}
 	}
+	return repos
+}
 
+// repoPathList is a repeatable flag value holding repository paths.
+type repoPathList []api.RepoPath
+
+func (l *repoPathList) String() string {
+	return ""
+}
+
+func (l *repoPathList) Set(value string) error {
+	var path api.RepoPath
+	err := path.Set(value)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, path)
 	return nil
 }
 
+func (l *repoPathList) IsCumulative() bool {
+	return true
+}
+
+// readServicesMetadata reads the metadata of the service accounts in the repositories.
+func readServicesMetadata(client secrethub.ClientInterface, repos []api.RepoPath) (*repoMetadata, error) {
+	merged := &repoMetadata{}
+	for _, repo := range repos {
+		metadata, err := readRepoMetadata(client, repo.Value())
+		if err != nil {
+			return nil, err
+		}
+		for serviceID, labels := range metadata.Services {
+			merged.setService(serviceID, labels)
+		}
+	}
+	return merged, nil
+}
+
+// filterActivity returns the service accounts that match the --created-by and --last-used-before filters.
+func (cmd *ServiceLsCommand) filterActivity(services []*api.Service, activity map[string]*serviceActivity, now time.Time) []*api.Service {
+	deadline := now.Add(-time.Duration(cmd.lastUsedBefore))
+	filtered := []*api.Service{}
+	for _, service := range services {
+		a := activity[service.ServiceID]
+		if cmd.createdBy != "" && !strings.EqualFold(a.createdBy, cmd.createdBy) {
+			continue
+		}
+		if cmd.lastUsedBefore > 0 && a.lastUsed.After(deadline) {
+			continue
+		}
+		filtered = append(filtered, service)
+	}
+	return filtered
+}
+
+// serviceActivity is what the audit log of a repository tells about a service account.
+type serviceActivity struct {
+	// createdBy is the username of the account that created the service account.
+	createdBy string
+	// lastUsed is the time of the most recent event caused by the service account. It is zero when it has never been used.
+	lastUsed time.Time
+}
+
+// readServiceActivity reads who created the service accounts and when they were last used from the audit logs of the repositories.
+func readServiceActivity(client secrethub.ClientInterface, repos []api.RepoPath, services []*api.Service) (map[string]*serviceActivity, error) {
+	activity := make(map[string]*serviceActivity, len(services))
+	for _, service := range services {
+		activity[service.ServiceID] = &serviceActivity{}
+	}
+
+	for _, repo := range repos {
+		iter := client.Repos().EventIterator(repo.Value(), &secrethub.AuditEventIteratorParams{})
+		for {
+			event, err := iter.Next()
+			if err == iterator.Done {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+
+			if event.Actor.Type == "service" && !event.Actor.Deleted {
+				a, ok := activity[event.Actor.Service.ServiceID]
+				if ok && event.LoggedAt.After(a.lastUsed) {
+					a.lastUsed = event.LoggedAt
+				}
+			}
+
+			if event.Action == api.AuditActionCreate && event.Subject.Type == api.AuditSubjectService && !event.Subject.Deleted {
+				a, ok := activity[event.Subject.Service.ServiceID]
+				if ok {
+					a.createdBy, err = getAuditActor(event)
+					if err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+	return activity, nil
+}
+
+// filterExpiring returns the service accounts with an expiry date that has passed or is within
+// the --expiring-within period, sorted by their expiry dates, and the expiry dates of these service accounts.
+func (cmd *ServiceLsCommand) filterExpiring(metadata *repoMetadata, services []*api.Service, now time.Time) ([]*api.Service, map[string]time.Time, error) {
+	deadline := now.Add(time.Duration(cmd.expiringWithin))
+	expiring := []*api.Service{}
+	expiries := map[string]time.Time{}
+	for _, service := range services {
+		expires, ok, err := metadata.serviceExpiry(service.ServiceID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok && !expires.After(deadline) {
+			expiring = append(expiring, service)
+			expiries[service.ServiceID] = expires
+		}
+	}
+
+	sort.SliceStable(expiring, func(i, j int) bool {
+		return expiries[expiring[i].ServiceID].Before(expiries[expiring[j].ServiceID])
+	})
+	return expiring, expiries, nil
+}
+
 type serviceTable interface {
 	header() []string
 	row(service *api.Service) []string
@@ -135,6 +356,65 @@ func (sw baseServiceTable) row(service *api.Service, content ...string) []string
 	return append(res, sw.timeFormatter.Format(service.CreatedAt.Local()))
 }
 
+// expiringServiceTable adds the expiry dates of the service accounts to a table.
+type expiringServiceTable struct {
+	serviceTable
+	expiries   map[string]time.Time
+	now        time.Time
+	timestamps bool
+}
+
+func (sw expiringServiceTable) header() []string {
+	return append(sw.serviceTable.header(), "EXPIRES")
+}
+
+func (sw expiringServiceTable) row(service *api.Service) []string {
+	return append(sw.serviceTable.row(service), formatExpiry(sw.expiries[service.ServiceID], sw.now, sw.timestamps))
+}
+
+// repoServiceTable adds the repositories of the service accounts to a table.
+type repoServiceTable struct {
+	serviceTable
+	repos map[string]api.RepoPath
+}
+
+func (sw repoServiceTable) header() []string {
+	return append([]string{"REPO"}, sw.serviceTable.header()...)
+}
+
+func (sw repoServiceTable) row(service *api.Service) []string {
+	return append([]string{sw.repos[service.ServiceID].String()}, sw.serviceTable.row(service)...)
+}
+
+// lastUsedServiceTable adds the time the service accounts were last used to a table.
+type lastUsedServiceTable struct {
+	serviceTable
+	activity      map[string]*serviceActivity
+	timeFormatter TimeFormatter
+}
+
+func (sw lastUsedServiceTable) header() []string {
+	return append(sw.serviceTable.header(), "LAST-USED")
+}
+
+func (sw lastUsedServiceTable) row(service *api.Service) []string {
+	lastUsed := sw.activity[service.ServiceID].lastUsed
+	if lastUsed.IsZero() {
+		return append(sw.serviceTable.row(service), "never")
+	}
+	return append(sw.serviceTable.row(service), sw.timeFormatter.Format(lastUsed.Local()))
+}
+
+// serviceJSONFieldNames converts the column names of a service table to the field names of its JSON output,
+// e.g. KMS-KEY to KmsKey.
+func serviceJSONFieldNames(header []string) []string {
+	names := make([]string, len(header))
+	for i, column := range header {
+		names[i] = strings.ReplaceAll(strings.ToLower(column), "-", " ")
+	}
+	return names
+}
+
 func newKeyServiceTable(timeFormatter TimeFormatter) serviceTable {
 	return keyServiceTable{baseServiceTable{timeFormatter: timeFormatter}}
 }
@@ -14,9 +14,74 @@ import (
 )
 
 func TestServiceLsCommand_Run(t *testing.T) {
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.Local)
+	lastUsed := time.Date(2020, 1, 10, 0, 0, 0, 0, time.Local)
+	activity := func() fakeclient.RepoService {
+		return fakeclient.RepoService{
+			AuditEventIterator: &fakeclient.AuditEventIterator{
+				Events: []api.Audit{
+					{
+						Action:   api.AuditActionRead,
+						Actor:    api.AuditActor{Type: "service", Service: &api.Service{ServiceID: "active"}},
+						LoggedAt: time.Date(2020, 5, 30, 0, 0, 0, 0, time.UTC),
+					},
+					{
+						Action:   api.AuditActionRead,
+						Actor:    api.AuditActor{Type: "service", Service: &api.Service{ServiceID: "stale"}},
+						LoggedAt: lastUsed,
+					},
+					{
+						Action:  api.AuditActionCreate,
+						Actor:   api.AuditActor{Type: "user", User: &api.User{Username: "admin"}},
+						Subject: api.AuditSubject{Type: api.AuditSubjectService, Service: &api.Service{ServiceID: "other"}},
+					},
+					{
+						Action:  api.AuditActionCreate,
+						Actor:   api.AuditActor{Type: "user", User: &api.User{Username: "dev"}},
+						Subject: api.AuditSubject{Type: api.AuditSubjectService, Service: &api.Service{ServiceID: "active"}},
+					},
+					{
+						Action:  api.AuditActionCreate,
+						Actor:   api.AuditActor{Type: "user", User: &api.User{Username: "dev"}},
+						Subject: api.AuditSubject{Type: api.AuditSubjectService, Service: &api.Service{ServiceID: "stale"}},
+					},
+				},
+			},
+		}
+	}
+	activityServices := fakeclient.ServiceService{
+		ListFunc: func(path string) ([]*api.Service, error) {
+			return []*api.Service{
+				{
+					ServiceID:   "active",
+					Description: "active",
+					Credential:  &api.Credential{Type: api.CredentialTypeKey},
+					CreatedAt:   created,
+				},
+				{
+					ServiceID:   "stale",
+					Description: "stale",
+					Credential:  &api.Credential{Type: api.CredentialTypeKey},
+					CreatedAt:   created,
+				},
+				{
+					ServiceID:   "other",
+					Description: "other",
+					Credential:  &api.Credential{Type: api.CredentialTypeKey},
+					CreatedAt:   created,
+				},
+			}, nil
+		},
+	}
+	now := func() time.Time {
+		return time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	}
+
 	cases := map[string]struct {
 		cmd            ServiceLsCommand
 		serviceService fakeclient.ServiceService
+		versionService fakeclient.SecretVersionService
+		repoService    fakeclient.RepoService
 		newClientErr   error
 		out            string
 		err            error
@@ -197,6 +262,128 @@ func TestServiceLsCommand_Run(t *testing.T) {
 				"ID    DESCRIPTION  SERVICE-ACCOUNT-EMAIL                                              KMS-KEY                                                                                CREATED\n" +
 				"test  foobar       service-account@secrethub-test-1234567890.iam.gserviceaccount.com  projects/secrethub-test-1234567890.iam/locations/global/keyRings/test/cryptoKeys/test  About an hour ago\n",
 		},
+		"success expiring within": {
+			cmd: ServiceLsCommand{
+				newServiceTable: newKeyServiceTable,
+				expiringWithin:  dayDuration(7 * 24 * time.Hour),
+				now: func() time.Time {
+					return time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+				},
+			},
+			serviceService: fakeclient.ServiceService{
+				ListFunc: func(path string) ([]*api.Service, error) {
+					return []*api.Service{
+						{
+							ServiceID:   "later",
+							Description: "later",
+							Credential:  &api.Credential{Type: api.CredentialTypeKey},
+							CreatedAt:   time.Now().Add(-1 * time.Hour),
+						},
+						{
+							ServiceID:   "soon",
+							Description: "soon",
+							Credential:  &api.Credential{Type: api.CredentialTypeKey},
+							CreatedAt:   time.Now().Add(-1 * time.Hour),
+						},
+						{
+							ServiceID:   "expired",
+							Description: "expired",
+							Credential:  &api.Credential{Type: api.CredentialTypeKey},
+							CreatedAt:   time.Now().Add(-1 * time.Hour),
+						},
+						{
+							ServiceID:   "never",
+							Description: "never",
+							Credential:  &api.Credential{Type: api.CredentialTypeKey},
+							CreatedAt:   time.Now().Add(-1 * time.Hour),
+						},
+					}, nil
+				},
+			},
+			versionService: fakeclient.SecretVersionService{
+				GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+					return &api.SecretVersion{Data: []byte(`{"labels":{},"services":{` +
+						`"later":{"expires":"2020-02-01T00:00:00Z"},` +
+						`"soon":{"expires":"2020-01-05T00:00:00Z"},` +
+						`"expired":{"expires":"2019-12-29T00:00:00Z"}}}`)}, nil
+				},
+			},
+			out: "" +
+				"ID       DESCRIPTION  TYPE  CREATED            EXPIRES\n" +
+				"expired  expired      key   About an hour ago  expired 3 days ago\n" +
+				"soon     soon         key   About an hour ago  in 4 days\n",
+		},
+		"success json": {
+			cmd: ServiceLsCommand{
+				newServiceTable: newKeyServiceTable,
+				json:            true,
+			},
+			serviceService: fakeclient.ServiceService{
+				ListFunc: func(path string) ([]*api.Service, error) {
+					return []*api.Service{
+						{
+							ServiceID:   "test",
+							Description: "foobar",
+							Credential:  &api.Credential{Type: api.CredentialTypeKey},
+							CreatedAt:   created,
+						},
+					}, nil
+				},
+			},
+			out: `{"Created":"` + created.Format(time.RFC3339) + `","Description":"foobar","Id":"test","Type":"key"}` + "\n",
+		},
+		"success multiple repos": {
+			cmd: ServiceLsCommand{
+				newServiceTable: newKeyServiceTable,
+				repoPath:        api.RepoPath("namespace/repo1"),
+				repos:           repoPathList{api.RepoPath("namespace/repo2"), api.RepoPath("namespace/repo1")},
+			},
+			serviceService: fakeclient.ServiceService{
+				ListFunc: func(path string) ([]*api.Service, error) {
+					return []*api.Service{
+						{
+							ServiceID:   "s-" + path[len(path)-1:],
+							Description: "foobar",
+							Credential:  &api.Credential{Type: api.CredentialTypeKey},
+							CreatedAt:   time.Now().Add(-1 * time.Hour),
+						},
+					}, nil
+				},
+			},
+			out: "" +
+				"REPO             ID   DESCRIPTION  TYPE  CREATED\n" +
+				"namespace/repo1  s-1  foobar       key   About an hour ago\n" +
+				"namespace/repo2  s-2  foobar       key   About an hour ago\n",
+		},
+		"success created by": {
+			cmd: ServiceLsCommand{
+				quiet:     true,
+				createdBy: "Dev",
+			},
+			serviceService: activityServices,
+			repoService:    activity(),
+			out:            "active\nstale\n",
+		},
+		"success last used before": {
+			cmd: ServiceLsCommand{
+				newServiceTable: newKeyServiceTable,
+				json:            true,
+				lastUsedBefore:  dayDuration(90 * 24 * time.Hour),
+				now:             now,
+			},
+			serviceService: activityServices,
+			repoService:    activity(),
+			out: "" +
+				`{"Created":"` + created.Format(time.RFC3339) + `","Description":"stale","Id":"stale","LastUsed":"` + lastUsed.Format(time.RFC3339) + `","Type":"key"}` + "\n" +
+				`{"Created":"` + created.Format(time.RFC3339) + `","Description":"other","Id":"other","LastUsed":"never","Type":"key"}` + "\n",
+		},
+		"quiet and json": {
+			cmd: ServiceLsCommand{
+				quiet: true,
+				json:  true,
+			},
+			err: ErrFlagsConflict("--quiet and --json"),
+		},
 		"new client error": {
 			newClientErr: errors.New("error"),
 			err:          errors.New("error"),
@@ -225,6 +412,10 @@ func TestServiceLsCommand_Run(t *testing.T) {
 				tc.cmd.newClient = func() (secrethub.ClientInterface, error) {
 					return fakeclient.Client{
 						ServiceService: &tc.serviceService,
+						SecretService: &fakeclient.SecretService{
+							VersionService: &tc.versionService,
+						},
+						RepoService: &tc.repoService,
 					}, nil
 				}
 			}
@@ -0,0 +1,123 @@
+package secrethub
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+)
+
+// ServiceReactivateCommand restores the access rules of a service account that was deactivated.
+type ServiceReactivateCommand struct {
+	repo      api.RepoPath
+	serviceID api.AccountName
+	io        ui.IO
+	newClient newClientFunc
+}
+
+// NewServiceReactivateCommand creates a new ServiceReactivateCommand.
+func NewServiceReactivateCommand(io ui.IO, newClient newClientFunc) *ServiceReactivateCommand {
+	return &ServiceReactivateCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *ServiceReactivateCommand) Register(r command.Registerer) {
+	clause := r.Command("reactivate", "Restore the access of a deactivated service account.")
+	clause.HelpLong("Restores the access rules that a service account had when it was deactivated with " +
+		"`" + ApplicationName + " service deactivate`. " +
+		"The access rules are only restored when the audit log shows that they were recorded by an admin of the repository.")
+	clause.Arg("repo-path", "The repository the service account is attached to.").Required().PlaceHolder(repoPathPlaceHolder).SetValue(&cmd.repo)
+	clause.Arg("service-id", "The ID of the service account to reactivate.").Required().SetValue(&cmd.serviceID)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run restores the access rules of the service account that are recorded in the metadata of the repository.
+func (cmd *ServiceReactivateCommand) Run() error {
+	if !cmd.serviceID.IsService() {
+		return ErrInvalidServiceID(cmd.serviceID)
+	}
+	serviceID := cmd.serviceID.Value()
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	metadata, err := readRepoMetadata(client, cmd.repo.Value())
+	if err != nil {
+		return err
+	}
+	labels := metadata.Services[serviceID]
+	if labels[deactivatedLabel] == "" {
+		return ErrServiceNotDeactivated(serviceID)
+	}
+
+	err = verifyServiceDeactivation(client, cmd.repo, serviceID, labels)
+	if err != nil {
+		return err
+	}
+
+	var permissions []servicePermission
+	if labels[deactivatedRulesLabel] != "" {
+		permissions, err = parsePermissionFlags(cmd.repo, []string{labels[deactivatedRulesLabel]})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, permission := range permissions {
+		_, err = client.AccessRules().Set(permission.path.Value(), permission.permission.String(), serviceID)
+		if err != nil {
+			return err
+		}
+	}
+
+	metadata.setService(serviceID, map[string]string{
+		deactivatedLabel:      "",
+		deactivatedRulesLabel: "",
+	})
+	err = writeRepoMetadata(client, cmd.repo.Value(), metadata)
+	if err != nil {
+		return err
+	}
+
+	if len(permissions) == 0 {
+		fmt.Fprintf(cmd.io.Output(), "Reactivated service account %s. It had no access rules to restore.\n", serviceID)
+		return nil
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Reactivated service account %s by restoring its access rules:\n", serviceID)
+	return printPermissions(cmd.io.Output(), permissions)
+}
+
+// verifyServiceDeactivation checks that the deactivation of the service account, including the access rules
+// to restore, was recorded by an account with admin permission on the repository. Anyone with write access
+// to the metadata repository could otherwise have the access rules of their choice restored.
+func verifyServiceDeactivation(client secrethub.ClientInterface, repo api.RepoPath, serviceID string, labels map[string]string) error {
+	author, err := metadataAuthor(client, repo.Value(), func(metadata *repoMetadata) bool {
+		recorded := metadata.Services[serviceID]
+		return recorded[deactivatedLabel] == labels[deactivatedLabel] && recorded[deactivatedRulesLabel] == labels[deactivatedRulesLabel]
+	})
+	if err != nil {
+		return err
+	}
+
+	rules, err := client.AccessRules().List(repo.Value(), 0, false)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if strings.EqualFold(rule.Account.Name.Value(), author) && rule.Permission == api.PermissionAdmin {
+			return nil
+		}
+	}
+	return ErrServiceDeactivationUntrusted(serviceID, author, repo)
+}
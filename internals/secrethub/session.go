@@ -0,0 +1,26 @@
+package secrethub
+
+import (
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// SessionCommand handles the session started with login.
+type SessionCommand struct {
+	io              ui.IO
+	credentialStore CredentialConfig
+}
+
+// NewSessionCommand creates a new SessionCommand.
+func NewSessionCommand(io ui.IO, credentialStore CredentialConfig) *SessionCommand {
+	return &SessionCommand{
+		io:              io,
+		credentialStore: credentialStore,
+	}
+}
+
+// Register registers the command and its sub-commands on the provided Registerer.
+func (cmd *SessionCommand) Register(r command.Registerer) {
+	clause := r.Command("session", "Inspect the session started with `"+ApplicationName+" login`.")
+	NewSessionStatusCommand(cmd.io, cmd.credentialStore).Register(clause)
+}
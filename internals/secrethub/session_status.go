@@ -0,0 +1,65 @@
+package secrethub
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// SessionStatusCommand shows whether the credential is unlocked and when that ends.
+type SessionStatusCommand struct {
+	useTimestamps bool
+	io            ui.IO
+	keyring       Keyring
+	now           func() time.Time
+}
+
+// NewSessionStatusCommand creates a new SessionStatusCommand.
+func NewSessionStatusCommand(io ui.IO, credentialStore CredentialConfig) *SessionStatusCommand {
+	return &SessionStatusCommand{
+		io:      io,
+		keyring: NewKeyring(credentialStore.ConfigDir),
+		now:     time.Now,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *SessionStatusCommand) Register(r command.Registerer) {
+	clause := r.Command("status", "Show whether your credential is unlocked and when the session ends.")
+	registerTimestampFlag(clause).BoolVar(&cmd.useTimestamps)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run prints the status of the session. Unlike the commands that use the
+// credential, it does not extend the session.
+func (cmd *SessionStatusCommand) Run() error {
+	item, err := cmd.keyring.Get()
+	if err != nil && err != ErrKeyringItemNotFound {
+		return err
+	}
+
+	now := cmd.now()
+	if err == ErrKeyringItemNotFound || !item.ExpiresAt.After(now) {
+		fmt.Fprintf(cmd.io.Output(), "No active session. Run `%s login` to start one.\n", ApplicationName)
+		return nil
+	}
+
+	if item.IdleTimeout > 0 {
+		fmt.Fprintf(cmd.io.Output(), "Logged in with an idle timeout of %s.\n", item.IdleTimeout)
+	} else {
+		fmt.Fprintln(cmd.io.Output(), "The passphrase of your credential is cached, as configured with --credential-passphrase-cache-ttl.")
+	}
+	fmt.Fprintf(cmd.io.Output(), "The session ends %s, unless the credential is used before.\n", cmd.formatEnd(item.ExpiresAt, now))
+	return nil
+}
+
+// formatEnd returns when the session ends, e.g. in 59 minutes or at 2020-01-01T01:00:00Z.
+func (cmd *SessionStatusCommand) formatEnd(end time.Time, now time.Time) string {
+	if cmd.useTimestamps {
+		return "at " + end.UTC().Format(time.RFC3339)
+	}
+	return formatExpiry(end, now, false)
+}
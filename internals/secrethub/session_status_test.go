@@ -0,0 +1,73 @@
+package secrethub
+
+import (
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestSessionStatusCommand_Run(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		item          *KeyringItem
+		useTimestamps bool
+		out           string
+	}{
+		"no session": {
+			out: "No active session. Run `secrethub login` to start one.\n",
+		},
+		"expired": {
+			item: &KeyringItem{
+				ExpiresAt:   now.Add(-time.Minute),
+				IdleTimeout: time.Hour,
+			},
+			out: "No active session. Run `secrethub login` to start one.\n",
+		},
+		"logged in": {
+			item: &KeyringItem{
+				ExpiresAt:   now.Add(30 * time.Minute),
+				IdleTimeout: time.Hour,
+			},
+			out: "Logged in with an idle timeout of 1h0m0s.\n" +
+				"The session ends in 30 minutes, unless the credential is used before.\n",
+		},
+		"cached passphrase with timestamps": {
+			item: &KeyringItem{
+				ExpiresAt: now.Add(5 * time.Minute),
+			},
+			useTimestamps: true,
+			out: "The passphrase of your credential is cached, as configured with --credential-passphrase-cache-ttl.\n" +
+				"The session ends at 2020-01-01T00:05:00Z, unless the credential is used before.\n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			keyring := newTestKeyring()
+			_ = keyring.Delete()
+			if tc.item != nil {
+				err := keyring.Set(tc.item)
+				assert.OK(t, err)
+			}
+
+			io := fakeui.NewIO(t)
+			cmd := SessionStatusCommand{
+				useTimestamps: tc.useTimestamps,
+				io:            io,
+				keyring:       keyring,
+				now: func() time.Time {
+					return now
+				},
+			}
+
+			err := cmd.Run()
+
+			assert.OK(t, err)
+			assert.Equal(t, io.Out.String(), tc.out)
+		})
+	}
+}
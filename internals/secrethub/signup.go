@@ -67,16 +67,17 @@ func (cmd *SignUpCommand) Register(r command.Registerer) {
 // If an account was already configured, the user is prompted for confirmation to overwrite it.
 func (cmd *SignUpCommand) Run() error {
 	credentialPath := cmd.credentialStore.ConfigDir().Credential().Path()
+	existing := storedCredentialBackend(cmd.credentialStore.ConfigDir())
 
 	if cmd.force {
 		if cmd.username == "" || cmd.fullName == "" || cmd.email == "" {
 			return ErrMissingFlags
 		}
 	} else {
-		if cmd.credentialStore.ConfigDir().Credential().Exists() {
+		if existing.Exists() {
 			confirmed, err := ui.AskYesNo(
 				cmd.io,
-				fmt.Sprintf("Found account credentials at %s, do you wish to overwrite them?", credentialPath),
+				fmt.Sprintf("Found account credentials in %s, do you wish to overwrite them?", existing),
 				ui.DefaultNo,
 			)
 			if err == ui.ErrCannotAsk {
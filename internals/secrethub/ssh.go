@@ -0,0 +1,29 @@
+package secrethub
+
+import (
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// SSHCommand handles distributing SSH keys stored in SecretHub.
+type SSHCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+	logger    cli.Logger
+}
+
+// NewSSHCommand creates a new SSHCommand.
+func NewSSHCommand(io ui.IO, newClient newClientFunc, logger cli.Logger) *SSHCommand {
+	return &SSHCommand{
+		io:        io,
+		newClient: newClient,
+		logger:    logger,
+	}
+}
+
+// Register registers the command and its sub-commands on the provided Registerer.
+func (cmd *SSHCommand) Register(r command.Registerer) {
+	clause := r.Command("ssh", "Manage SSH keys and known hosts.")
+	NewSSHSyncAuthorizedKeysCommand(cmd.io, cmd.newClient, cmd.logger).Register(clause)
+}
@@ -0,0 +1,370 @@
+package secrethub
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/atomicfile"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/errio"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Errors
+var (
+	errSSH                = errio.Namespace("ssh")
+	ErrNoSSHKeyDirs       = errSSH.Code("no_key_dirs").ErrorPref("the directory %s contains neither an " + authorizedKeysDirName + " nor a " + knownHostsDirName + " directory")
+	ErrInvalidSSHKey      = errSSH.Code("invalid_key").ErrorPref("secret %s contains an invalid entry on line %d: %s")
+	ErrCannotLookupUser   = errSSH.Code("cannot_lookup_user").ErrorPref("cannot look up user %s: %s")
+	ErrCannotCreateSSHDir = errSSH.Code("cannot_create_ssh_dir").ErrorPref("cannot create SSH directory %s: %s")
+	ErrSSHPathIsSymlink   = errSSH.Code("path_is_symlink").ErrorPref("refusing to write to %s: it is a symbolic link")
+	ErrSSHDirWrongOwner   = errSSH.Code("dir_wrong_owner").ErrorPref("refusing to write to %s: it is owned by uid %d instead of uid %d")
+)
+
+const (
+	authorizedKeysDirName = "authorized_keys"
+	knownHostsDirName     = "known_hosts"
+
+	authorizedKeysFileMode = 0600
+	knownHostsFileMode     = 0644
+	sshDirFileMode         = 0700
+
+	sshManagedFileHeader = "# This file is managed by SecretHub. Manual changes will be overwritten.\n"
+)
+
+// SSHSyncAuthorizedKeysCommand renders authorized_keys and known_hosts files from
+// secrets in a directory and installs them for a user.
+type SSHSyncAuthorizedKeysCommand struct {
+	io         ui.IO
+	path       api.DirPath
+	username   string
+	sshDir     string
+	interval   time.Duration
+	lookupUser func(username string) (*user.User, error)
+	newClient  newClientFunc
+	logger     cli.Logger
+}
+
+// NewSSHSyncAuthorizedKeysCommand creates a new SSHSyncAuthorizedKeysCommand.
+func NewSSHSyncAuthorizedKeysCommand(io ui.IO, newClient newClientFunc, logger cli.Logger) *SSHSyncAuthorizedKeysCommand {
+	return &SSHSyncAuthorizedKeysCommand{
+		io:         io,
+		lookupUser: user.Lookup,
+		newClient:  newClient,
+		logger:     logger,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *SSHSyncAuthorizedKeysCommand) Register(r command.Registerer) {
+	clause := r.Command("sync-authorized-keys", "Install authorized_keys and known_hosts files from the secrets in a directory.")
+	clause.HelpLong("The public keys stored in the " + authorizedKeysDirName + " subdirectory are written to the authorized_keys file of the user " +
+		"and the entries stored in the " + knownHostsDirName + " subdirectory are written to the known_hosts file of the user. " +
+		"Every secret can contain multiple entries, one per line. " +
+		"Entries are validated before anything is written and files are replaced atomically, so sshd never reads a partially written file. " +
+		"The files are not written through symbolic links, and with --user an existing SSH directory must be owned by that user.")
+	clause.Arg("dir-path", "The path to the directory containing the keys").Required().PlaceHolder(dirPathPlaceHolder).SetValue(&cmd.path)
+	clause.Flag("user", "The user to install the files for. Defaults to the current user.").StringVar(&cmd.username)
+	clause.Flag("ssh-dir", "The directory to install the files in. Defaults to the .ssh directory in the home directory of the user.").StringVar(&cmd.sshDir)
+	clause.Flag("interval", "Keep running and synchronize the files again after every interval, e.g. 15m. By default the files are synchronized once.").DurationVar(&cmd.interval)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run synchronizes the files once or, when an interval is set, until the process is stopped.
+func (cmd *SSHSyncAuthorizedKeysCommand) Run() error {
+	err := cmd.sync()
+	if err != nil || cmd.interval <= 0 {
+		return err
+	}
+
+	logger := cmd.logger.With("dir", cmd.path.Value())
+	for range time.Tick(cmd.interval) {
+		err := cmd.sync()
+		if err != nil {
+			logger.With("error", err).Errorf("Synchronizing SSH keys failed")
+		} else {
+			logger.Debugf("Synchronized SSH keys")
+		}
+	}
+	return nil
+}
+
+// sshTarget is the account the SSH files are installed for.
+type sshTarget struct {
+	dir string
+	// uid and gid are -1 when the ownership of the files should not be changed.
+	uid int
+	gid int
+}
+
+func (cmd *SSHSyncAuthorizedKeysCommand) target() (sshTarget, error) {
+	t := sshTarget{
+		dir: cmd.sshDir,
+		uid: -1,
+		gid: -1,
+	}
+
+	var u *user.User
+	var err error
+	if cmd.username != "" {
+		u, err = cmd.lookupUser(cmd.username)
+		if err != nil {
+			return sshTarget{}, ErrCannotLookupUser(cmd.username, err)
+		}
+
+		// On Windows, the uid and gid are SIDs instead of integers. Ownership is not changed there.
+		uid, uidErr := strconv.Atoi(u.Uid)
+		gid, gidErr := strconv.Atoi(u.Gid)
+		if uidErr == nil && gidErr == nil && uid != os.Getuid() {
+			t.uid = uid
+			t.gid = gid
+		}
+	}
+
+	if t.dir == "" {
+		if u == nil {
+			u, err = user.Current()
+			if err != nil {
+				return sshTarget{}, ErrCannotLookupUser("current user", err)
+			}
+		}
+		t.dir = filepath.Join(u.HomeDir, ".ssh")
+	}
+
+	return t, nil
+}
+
+func (cmd *SSHSyncAuthorizedKeysCommand) sync() error {
+	target, err := cmd.target()
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	tree, err := client.Dirs().GetTree(cmd.path.Value(), -1, false)
+	if err != nil {
+		return err
+	}
+
+	files := []struct {
+		dirName  string
+		fileMode os.FileMode
+		validate func(line []byte) error
+	}{
+		{
+			dirName:  authorizedKeysDirName,
+			fileMode: authorizedKeysFileMode,
+			validate: validateAuthorizedKey,
+		},
+		{
+			dirName:  knownHostsDirName,
+			fileMode: knownHostsFileMode,
+			validate: validateKnownHost,
+		},
+	}
+
+	found := false
+	for _, file := range files {
+		var dir *api.Dir
+		for _, subDir := range tree.RootDir.SubDirs {
+			if subDir.Name == file.dirName {
+				dir = subDir
+			}
+		}
+		if dir == nil {
+			continue
+		}
+		found = true
+
+		paths := secretPathsInDir(dir, cmd.path.Value()+"/"+file.dirName)
+		content, count, err := renderSSHFile(client, paths, file.validate)
+		if err != nil {
+			return err
+		}
+
+		filename := filepath.Join(target.dir, file.dirName)
+		updated, err := installSSHFile(target, filename, content, file.fileMode)
+		if err != nil {
+			return err
+		}
+
+		if updated {
+			fmt.Fprintf(cmd.io.Output(), "Installed %s with %s.\n", filename, pluralize("entry", "entries", count))
+		} else {
+			fmt.Fprintf(cmd.io.Output(), "%s is up to date.\n", filename)
+		}
+	}
+
+	if !found {
+		return ErrNoSSHKeyDirs(cmd.path)
+	}
+
+	return nil
+}
+
+// renderSSHFile concatenates the entries stored in the secrets on the given paths.
+// Every entry is validated with the given function. Empty lines and comments are skipped.
+func renderSSHFile(client secrethub.ClientInterface, paths []string, validate func([]byte) error) ([]byte, int, error) {
+	var buf bytes.Buffer
+	buf.WriteString(sshManagedFileHeader)
+
+	count := 0
+	for _, path := range paths {
+		secret, err := client.Secrets().Versions().GetWithData(path)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		fmt.Fprintf(&buf, "\n# %s\n", path)
+
+		scanner := bufio.NewScanner(bytes.NewReader(secret.Data))
+		lineNo := 0
+		for scanner.Scan() {
+			lineNo++
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 || line[0] == '#' {
+				continue
+			}
+
+			err = validate(line)
+			if err != nil {
+				return nil, 0, ErrInvalidSSHKey(path, lineNo, err)
+			}
+
+			buf.Write(line)
+			buf.WriteByte('\n')
+			count++
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return buf.Bytes(), count, nil
+}
+
+// installSSHFile atomically replaces the file with the given content, unless it is already up to date.
+// Symbolic links are never followed, so the user the files are installed for cannot redirect the write elsewhere.
+// It returns whether the file was written.
+func installSSHFile(target sshTarget, filename string, content []byte, fileMode os.FileMode) (bool, error) {
+	err := prepareSSHDir(target)
+	if err != nil {
+		return false, err
+	}
+
+	info, err := os.Lstat(filename)
+	if err == nil {
+		if info.Mode()&os.ModeSymlink != 0 {
+			return false, ErrSSHPathIsSymlink(filename)
+		}
+		if isSSHFileUpToDate(target, filename, info, content, fileMode) {
+			return false, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return false, ErrCannotWrite(filename, err)
+	}
+
+	if target.uid != -1 {
+		err = atomicfile.WriteFileWithOwner(filename, content, fileMode, target.uid, target.gid)
+	} else {
+		err = atomicfile.WriteFile(filename, content, fileMode)
+	}
+	if err != nil {
+		return false, ErrCannotWrite(filename, err)
+	}
+
+	return true, nil
+}
+
+// prepareSSHDir creates the SSH directory of the target when it does not exist.
+// An existing directory must not be a symbolic link and must be owned by the target.
+func prepareSSHDir(target sshTarget) error {
+	info, err := os.Lstat(target.dir)
+	if os.IsNotExist(err) {
+		if target.uid == -1 {
+			err = os.MkdirAll(target.dir, sshDirFileMode)
+			if err != nil {
+				return ErrCannotCreateSSHDir(target.dir, err)
+			}
+			return nil
+		}
+
+		// Mkdir fails when a file or symbolic link was put in place in the meantime.
+		err = os.Mkdir(target.dir, sshDirFileMode)
+		if err != nil {
+			return ErrCannotCreateSSHDir(target.dir, err)
+		}
+		err = os.Lchown(target.dir, target.uid, target.gid)
+		if err != nil {
+			return ErrCannotCreateSSHDir(target.dir, err)
+		}
+		return nil
+	} else if err != nil {
+		return ErrCannotCreateSSHDir(target.dir, err)
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		return ErrSSHPathIsSymlink(target.dir)
+	}
+	if !info.IsDir() {
+		return ErrCannotCreateSSHDir(target.dir, "the path exists and is not a directory")
+	}
+	if target.uid != -1 {
+		uid, _, ok := fileOwner(info)
+		if ok && uid != target.uid {
+			return ErrSSHDirWrongOwner(target.dir, uid, target.uid)
+		}
+	}
+	return nil
+}
+
+// isSSHFileUpToDate returns whether the file has the given content and mode and is owned by the target.
+func isSSHFileUpToDate(target sshTarget, filename string, info os.FileInfo, content []byte, fileMode os.FileMode) bool {
+	if info.Mode().Perm() != fileMode {
+		return false
+	}
+	if target.uid != -1 {
+		uid, gid, ok := fileOwner(info)
+		if ok && (uid != target.uid || gid != target.gid) {
+			return false
+		}
+	}
+
+	current, err := ioutil.ReadFile(filename)
+	return err == nil && bytes.Equal(current, content)
+}
+
+func validateAuthorizedKey(line []byte) error {
+	_, _, _, _, err := ssh.ParseAuthorizedKey(line)
+	return err
+}
+
+func validateKnownHost(line []byte) error {
+	_, hosts, _, _, _, err := ssh.ParseKnownHosts(line)
+	if err != nil {
+		return err
+	}
+	if len(hosts) == 0 || strings.TrimSpace(hosts[0]) == "" {
+		return fmt.Errorf("missing host pattern")
+	}
+	return nil
+}
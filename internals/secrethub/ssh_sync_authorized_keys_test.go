@@ -0,0 +1,250 @@
+package secrethub
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestSSHSyncAuthorizedKeysCommand_Run(t *testing.T) {
+	const (
+		key1      = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIAY2DFil7pkKAZJsImt/1DjfNrUBCL2usVEayBbX0Y0e alice@example.com"
+		key2      = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIN6fZZEIUL53wDNktSMwCOQUOCVhGIg17OpEJfpqEHWl bob@example.com"
+		knownHost = "github.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIGSREPcAQ14YYo0M8zI/r+SXMLcQmOz835itdxHWYmgB"
+	)
+
+	tree := &api.Tree{
+		RootDir: &api.Dir{
+			Name: "ssh",
+			SubDirs: []*api.Dir{
+				{
+					Name: "authorized_keys",
+					Secrets: []*api.Secret{
+						{Name: "bob"},
+						{Name: "alice"},
+					},
+				},
+				{
+					Name: "known_hosts",
+					Secrets: []*api.Secret{
+						{Name: "github"},
+					},
+				},
+			},
+		},
+	}
+
+	cases := map[string]struct {
+		tree    *api.Tree
+		secrets map[string]string
+		files   map[string]string
+		out     string
+		err     error
+	}{
+		"success": {
+			tree: tree,
+			secrets: map[string]string{
+				"namespace/repo/ssh/authorized_keys/alice": key1 + "\n",
+				"namespace/repo/ssh/authorized_keys/bob":   "# comment\n\n" + key2,
+				"namespace/repo/ssh/known_hosts/github":    knownHost,
+			},
+			files: map[string]string{
+				"authorized_keys": sshManagedFileHeader +
+					"\n# namespace/repo/ssh/authorized_keys/alice\n" + key1 + "\n" +
+					"\n# namespace/repo/ssh/authorized_keys/bob\n" + key2 + "\n",
+				"known_hosts": sshManagedFileHeader +
+					"\n# namespace/repo/ssh/known_hosts/github\n" + knownHost + "\n",
+			},
+			out: "Installed <dir>/authorized_keys with 2 entries.\n" +
+				"Installed <dir>/known_hosts with 1 entry.\n",
+		},
+		"invalid key": {
+			tree: tree,
+			secrets: map[string]string{
+				"namespace/repo/ssh/authorized_keys/alice": "not a key",
+				"namespace/repo/ssh/authorized_keys/bob":   key2,
+				"namespace/repo/ssh/known_hosts/github":    knownHost,
+			},
+			err: ErrInvalidSSHKey("namespace/repo/ssh/authorized_keys/alice", 1, "ssh: no key found"),
+		},
+		"no key directories": {
+			tree: &api.Tree{
+				RootDir: &api.Dir{
+					Name: "ssh",
+				},
+			},
+			err: ErrNoSSHKeyDirs("namespace/repo/ssh"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir, cleanup := testdata.tempDir(t)
+			defer cleanup()
+
+			io := fakeui.NewIO(t)
+			cmd := SSHSyncAuthorizedKeysCommand{
+				io:     io,
+				path:   "namespace/repo/ssh",
+				sshDir: dir,
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						DirService: &fakeclient.DirService{
+							GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+								return tc.tree, nil
+							},
+						},
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+									return &api.SecretVersion{Data: []byte(tc.secrets[path])}, nil
+								},
+							},
+						},
+					}, nil
+				},
+			}
+
+			err := cmd.Run()
+			if tc.err != nil {
+				assert.Equal(t, err.Error(), tc.err.Error())
+				return
+			}
+			assert.OK(t, err)
+
+			for filename, expected := range tc.files {
+				actual, err := ioutil.ReadFile(filepath.Join(dir, filename))
+				assert.OK(t, err)
+				assert.Equal(t, string(actual), expected)
+			}
+			assert.Equal(t, io.Out.String(), replaceDir(tc.out, dir))
+
+			// A second run leaves the files untouched.
+			io.Out.Reset()
+			err = cmd.Run()
+			assert.OK(t, err)
+			assert.Equal(t, io.Out.String(), replaceDir(
+				"<dir>/authorized_keys is up to date.\n<dir>/known_hosts is up to date.\n", dir),
+			)
+		})
+	}
+}
+
+func TestInstallSSHFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links and file owners are not used on Windows")
+	}
+
+	cases := map[string]struct {
+		setup   func(dir string) (sshTarget, error)
+		updated bool
+		err     func(dir string) error
+	}{
+		"new directory": {
+			setup: func(dir string) (sshTarget, error) {
+				return sshTarget{dir: filepath.Join(dir, ".ssh"), uid: -1, gid: -1}, nil
+			},
+			updated: true,
+		},
+		"up to date": {
+			setup: func(dir string) (sshTarget, error) {
+				err := os.Mkdir(filepath.Join(dir, ".ssh"), 0700)
+				if err != nil {
+					return sshTarget{}, err
+				}
+				return sshTarget{dir: filepath.Join(dir, ".ssh"), uid: -1, gid: -1},
+					ioutil.WriteFile(filepath.Join(dir, ".ssh", "authorized_keys"), []byte("content"), 0600)
+			},
+			updated: false,
+		},
+		"wrong mode is repaired": {
+			setup: func(dir string) (sshTarget, error) {
+				err := os.Mkdir(filepath.Join(dir, ".ssh"), 0700)
+				if err != nil {
+					return sshTarget{}, err
+				}
+				return sshTarget{dir: filepath.Join(dir, ".ssh"), uid: -1, gid: -1},
+					ioutil.WriteFile(filepath.Join(dir, ".ssh", "authorized_keys"), []byte("content"), 0666)
+			},
+			updated: true,
+		},
+		"symlinked directory": {
+			setup: func(dir string) (sshTarget, error) {
+				err := os.Mkdir(filepath.Join(dir, "etc"), 0755)
+				if err != nil {
+					return sshTarget{}, err
+				}
+				return sshTarget{dir: filepath.Join(dir, ".ssh"), uid: -1, gid: -1},
+					os.Symlink(filepath.Join(dir, "etc"), filepath.Join(dir, ".ssh"))
+			},
+			err: func(dir string) error {
+				return ErrSSHPathIsSymlink(filepath.Join(dir, ".ssh"))
+			},
+		},
+		"symlinked file": {
+			setup: func(dir string) (sshTarget, error) {
+				err := os.Mkdir(filepath.Join(dir, ".ssh"), 0700)
+				if err != nil {
+					return sshTarget{}, err
+				}
+				return sshTarget{dir: filepath.Join(dir, ".ssh"), uid: -1, gid: -1},
+					os.Symlink(filepath.Join(dir, "shadow"), filepath.Join(dir, ".ssh", "authorized_keys"))
+			},
+			err: func(dir string) error {
+				return ErrSSHPathIsSymlink(filepath.Join(dir, ".ssh", "authorized_keys"))
+			},
+		},
+		"directory of other user": {
+			setup: func(dir string) (sshTarget, error) {
+				return sshTarget{dir: dir, uid: os.Getuid() + 1, gid: os.Getgid()}, nil
+			},
+			err: func(dir string) error {
+				return ErrSSHDirWrongOwner(dir, os.Getuid(), os.Getuid()+1)
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			dir, cleanup := testdata.tempDir(t)
+			defer cleanup()
+
+			target, err := tc.setup(dir)
+			assert.OK(t, err)
+
+			// Act
+			updated, err := installSSHFile(target, filepath.Join(target.dir, "authorized_keys"), []byte("content"), authorizedKeysFileMode)
+
+			// Assert
+			if tc.err != nil {
+				assert.Equal(t, err, tc.err(dir))
+				_, err = os.Stat(filepath.Join(dir, "etc", "authorized_keys"))
+				assert.Equal(t, os.IsNotExist(err), true)
+				_, err = os.Stat(filepath.Join(dir, "shadow"))
+				assert.Equal(t, os.IsNotExist(err), true)
+				return
+			}
+			assert.OK(t, err)
+			assert.Equal(t, updated, tc.updated)
+
+			info, err := os.Lstat(filepath.Join(target.dir, "authorized_keys"))
+			assert.OK(t, err)
+			assert.Equal(t, info.Mode().Perm(), os.FileMode(authorizedKeysFileMode))
+		})
+	}
+}
+
+func replaceDir(s string, dir string) string {
+	return strings.ReplaceAll(s, "<dir>", dir)
+}
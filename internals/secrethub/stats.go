@@ -0,0 +1,76 @@
+package secrethub
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/cloneproc"
+	"github.com/secrethub/secrethub-cli/internals/cli/stats"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// StatsCommand handles operations on the locally collected usage statistics.
+type StatsCommand struct {
+	io              ui.IO
+	credentialStore CredentialConfig
+	version         func() string
+}
+
+// NewStatsCommand creates a new StatsCommand.
+func NewStatsCommand(io ui.IO, credentialStore CredentialConfig, version func() string) *StatsCommand {
+	return &StatsCommand{
+		io:              io,
+		credentialStore: credentialStore,
+		version:         version,
+	}
+}
+
+// Register registers the command and its sub-commands on the provided Registerer.
+func (cmd *StatsCommand) Register(r command.Registerer) {
+	clause := r.Command("stats", "Show and manage local usage statistics.")
+	clause.HelpLong("The CLI keeps track of how often each command is used, how long it takes and how often the cache is hit. " +
+		"These statistics are stored in your configuration directory and never leave your machine, " +
+		"unless you explicitly opt in to share anonymized aggregates with `secrethub stats opt-in`. " +
+		"Arguments, paths and secret values are never collected.")
+	NewStatsShowCommand(cmd.io, cmd.statsFile, cmd.version).Register(clause)
+	NewStatsCollectCommand(cmd.io, cmd.statsFile, true).Register(clause)
+	NewStatsCollectCommand(cmd.io, cmd.statsFile, false).Register(clause)
+	NewStatsResetCommand(cmd.io, cmd.statsFile).Register(clause)
+	NewStatsOptInCommand(cmd.io, cmd.statsFile).Register(clause)
+	NewStatsOptOutCommand(cmd.io, cmd.statsFile).Register(clause)
+	NewStatsUploadCommand(cmd.statsFile, cmd.version).Register(clause)
+}
+
+// statsFile returns the path to the file the usage statistics are stored in.
+func (cmd *StatsCommand) statsFile() string {
+	return statsFilePath(cmd.credentialStore)
+}
+
+// statsFilePath returns the path to the usage statistics file in the configuration directory.
+func statsFilePath(credentialStore CredentialConfig) string {
+	dir := credentialStore.ConfigDir().Path()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, stats.Filename)
+}
+
+// recordStats registers an invocation of a command in the usage statistics.
+// When the user opted in to sharing and a report is due, a detached process
+// is spawned to send it, so the command itself is never slowed down.
+func (app *App) recordStats(command string, duration time.Duration, failed bool) {
+	path := statsFilePath(app.credentialStore)
+	share, err := app.stats.Save(path, command, duration, failed)
+	if err != nil {
+		app.logger.Debugf("cannot save usage statistics: %s", err)
+		return
+	}
+
+	if share {
+		err = cloneproc.Spawn("stats", "upload", "--config-dir", filepath.Dir(path))
+		if err != nil {
+			app.logger.Debugf("cannot share usage statistics: %s", err)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package secrethub
+
+import (
+	"fmt"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/stats"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// StatsCollectCommand turns the local collection of usage statistics on or off.
+type StatsCollectCommand struct {
+	io        ui.IO
+	statsFile func() string
+	enable    bool
+}
+
+// NewStatsCollectCommand creates a new StatsCollectCommand that enables
+// or disables the collection of usage statistics.
+func NewStatsCollectCommand(io ui.IO, statsFile func() string, enable bool) *StatsCollectCommand {
+	return &StatsCollectCommand{
+		io:        io,
+		statsFile: statsFile,
+		enable:    enable,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *StatsCollectCommand) Register(r command.Registerer) {
+	name, help := "enable", "Start collecting usage statistics on this machine."
+	if !cmd.enable {
+		name, help = "disable", "Stop collecting usage statistics on this machine. Already collected statistics are kept until you run `secrethub stats reset`."
+	}
+	clause := r.Command(name, help)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run enables or disables the collection of usage statistics.
+func (cmd *StatsCollectCommand) Run() error {
+	path := cmd.statsFile()
+
+	err := stats.Update(path, func(s *stats.Stats) error {
+		s.Disabled = !cmd.enable
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if cmd.enable {
+		fmt.Fprintln(cmd.io.Output(), "Usage statistics are collected on this machine.")
+	} else {
+		fmt.Fprintln(cmd.io.Output(), "Usage statistics are no longer collected on this machine.")
+	}
+	return nil
+}
+
+// StatsResetCommand removes all collected usage statistics.
+type StatsResetCommand struct {
+	io        ui.IO
+	statsFile func() string
+}
+
+// NewStatsResetCommand creates a new StatsResetCommand.
+func NewStatsResetCommand(io ui.IO, statsFile func() string) *StatsResetCommand {
+	return &StatsResetCommand{
+		io:        io,
+		statsFile: statsFile,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *StatsResetCommand) Register(r command.Registerer) {
+	clause := r.Command("reset", "Remove all usage statistics collected on this machine.")
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run clears the usage statistics.
+func (cmd *StatsResetCommand) Run() error {
+	path := cmd.statsFile()
+
+	err := stats.Update(path, func(s *stats.Stats) error {
+		s.Reset()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(cmd.io.Output(), "Usage statistics have been reset.")
+	return nil
+}
@@ -0,0 +1,135 @@
+package secrethub
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/stats"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// Errors
+var (
+	ErrInvalidStatsEndpoint = errMain.Code("invalid_stats_endpoint").ErrorPref("invalid endpoint %s: must be an absolute http(s) URL")
+)
+
+// StatsOptInCommand enables sharing of anonymized usage statistics.
+type StatsOptInCommand struct {
+	io        ui.IO
+	statsFile func() string
+	endpoint  string
+}
+
+// NewStatsOptInCommand creates a new StatsOptInCommand.
+func NewStatsOptInCommand(io ui.IO, statsFile func() string) *StatsOptInCommand {
+	return &StatsOptInCommand{
+		io:        io,
+		statsFile: statsFile,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *StatsOptInCommand) Register(r command.Registerer) {
+	clause := r.Command("opt-in", "Share anonymized aggregates of your usage statistics.")
+	clause.HelpLong("Once a day, an anonymized report is sent to the given endpoint. " +
+		"The report contains a random installation ID, the CLI version, the operating system, " +
+		"the number of invocations, failures and average duration per command and the cache hit rate. " +
+		"Use `secrethub stats show --report` to see the exact report that is shared.")
+	clause.Arg("endpoint", "The URL to send the reports to.").Required().StringVar(&cmd.endpoint)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run enables sharing of anonymized usage statistics.
+func (cmd *StatsOptInCommand) Run() error {
+	u, err := url.Parse(cmd.endpoint)
+	if err != nil || !u.IsAbs() || (u.Scheme != "https" && u.Scheme != "http") {
+		return ErrInvalidStatsEndpoint(cmd.endpoint)
+	}
+
+	path := cmd.statsFile()
+
+	err = stats.Update(path, func(s *stats.Stats) error {
+		return s.EnableSharing(cmd.endpoint)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Anonymized usage statistics will be shared with %s.\n", cmd.endpoint)
+	return nil
+}
+
+// StatsOptOutCommand disables sharing of anonymized usage statistics.
+type StatsOptOutCommand struct {
+	io        ui.IO
+	statsFile func() string
+}
+
+// NewStatsOptOutCommand creates a new StatsOptOutCommand.
+func NewStatsOptOutCommand(io ui.IO, statsFile func() string) *StatsOptOutCommand {
+	return &StatsOptOutCommand{
+		io:        io,
+		statsFile: statsFile,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *StatsOptOutCommand) Register(r command.Registerer) {
+	clause := r.Command("opt-out", "Stop sharing usage statistics. Statistics are still collected locally.")
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run disables sharing of anonymized usage statistics.
+func (cmd *StatsOptOutCommand) Run() error {
+	path := cmd.statsFile()
+
+	err := stats.Update(path, func(s *stats.Stats) error {
+		s.DisableSharing()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(cmd.io.Output(), "Usage statistics are no longer shared.")
+	return nil
+}
+
+// StatsUploadCommand sends the anonymized report to the endpoint the user opted in to.
+// It is spawned in the background by the application and therefore hidden.
+type StatsUploadCommand struct {
+	statsFile func() string
+	version   func() string
+}
+
+// NewStatsUploadCommand creates a new StatsUploadCommand.
+func NewStatsUploadCommand(statsFile func() string, version func() string) *StatsUploadCommand {
+	return &StatsUploadCommand{
+		statsFile: statsFile,
+		version:   version,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *StatsUploadCommand) Register(r command.Registerer) {
+	clause := r.Command("upload", "Share the anonymized usage statistics.").Hidden()
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run sends the report when sharing is enabled.
+func (cmd *StatsUploadCommand) Run() error {
+	s, err := stats.Load(cmd.statsFile())
+	if err != nil {
+		return err
+	}
+
+	if !s.Sharing.Enabled || s.Sharing.Endpoint == "" {
+		return nil
+	}
+
+	return s.Report(cmd.version()).Send(s.Sharing.Endpoint)
+}
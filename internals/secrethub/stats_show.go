@@ -0,0 +1,84 @@
+package secrethub
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/stats"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// StatsShowCommand prints the locally collected usage statistics.
+type StatsShowCommand struct {
+	io        ui.IO
+	statsFile func() string
+	version   func() string
+	report    bool
+}
+
+// NewStatsShowCommand creates a new StatsShowCommand.
+func NewStatsShowCommand(io ui.IO, statsFile func() string, version func() string) *StatsShowCommand {
+	return &StatsShowCommand{
+		io:        io,
+		statsFile: statsFile,
+		version:   version,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *StatsShowCommand) Register(r command.Registerer) {
+	clause := r.Command("show", "Show the usage statistics collected on this machine.")
+	clause.Default()
+	clause.Flag("report", "Print the exact anonymized report that is shared when you opted in.").BoolVar(&cmd.report)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run prints the usage statistics.
+func (cmd *StatsShowCommand) Run() error {
+	s, err := stats.Load(cmd.statsFile())
+	if err != nil {
+		return err
+	}
+
+	if cmd.report {
+		out, err := json.MarshalIndent(s.Report(cmd.version()), "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.io.Output(), string(out))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(cmd.io.Output(), 0, 2, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", "COMMAND", "COUNT", "ERRORS", "AVG DURATION")
+	for _, name := range s.CommandNames() {
+		c := s.Commands[name]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", name, c.Count, c.Errors, c.AverageDuration().Round(time.Millisecond))
+	}
+	err = w.Flush()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(cmd.io.Output(), "")
+	fmt.Fprintf(cmd.io.Output(), "Cache hit rate: %.0f%% (%d hits, %d misses)\n", s.CacheHitRate()*100, s.CacheHits, s.CacheMisses)
+	fmt.Fprintf(cmd.io.Output(), "Collected since: %s\n", s.Since.Local().Format(time.RFC3339))
+
+	if s.Disabled {
+		fmt.Fprintln(cmd.io.Output(), "Collection: disabled")
+	} else {
+		fmt.Fprintln(cmd.io.Output(), "Collection: enabled")
+	}
+
+	if s.Sharing.Enabled {
+		fmt.Fprintf(cmd.io.Output(), "Sharing: enabled (%s)\n", s.Sharing.Endpoint)
+	} else {
+		fmt.Fprintln(cmd.io.Output(), "Sharing: disabled")
+	}
+
+	return nil
+}
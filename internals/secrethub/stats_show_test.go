@@ -0,0 +1,40 @@
+package secrethub
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/stats"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestStatsShowCommand_Run(t *testing.T) {
+	dir, cleanup := testdata.tempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, stats.Filename)
+
+	s := stats.New()
+	s.RecordCommand("read", 2*time.Second, false)
+	s.RecordCommand("read", 4*time.Second, true)
+	s.RecordCommand("write", time.Second, false)
+	s.RecordCache(true)
+	s.RecordCache(false)
+	err := s.Save(path)
+	assert.OK(t, err)
+
+	io := fakeui.NewIO(t)
+	cmd := NewStatsShowCommand(io, func() string { return path }, func() string { return "0.0.0" })
+
+	err = cmd.Run()
+	assert.OK(t, err)
+
+	lines := strings.Split(io.Out.String(), "\n")
+	assert.Equal(t, strings.Fields(lines[0]), []string{"COMMAND", "COUNT", "ERRORS", "AVG", "DURATION"})
+	assert.Equal(t, strings.Fields(lines[1]), []string{"read", "2", "1", "3s"})
+	assert.Equal(t, strings.Fields(lines[2]), []string{"write", "1", "0", "1s"})
+	assert.Equal(t, lines[4], "Cache hit rate: 50% (1 hits, 1 misses)")
+}
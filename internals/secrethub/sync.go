@@ -0,0 +1,504 @@
+package secrethub
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/atomicfile"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/errio"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+)
+
+// Errors
+var (
+	errSync = errio.Namespace("sync")
+
+	ErrSyncInvalidState = errSync.Code("invalid_state").ErrorPref("the sync state file %s is invalid: %s")
+)
+
+const (
+	syncBackendAWS   = "aws-secretsmanager"
+	syncBackendLocal = "local"
+
+	syncConflictSkip      = "skip"
+	syncConflictNewest    = "newest"
+	syncConflictSecretHub = "secrethub"
+	syncConflictBackend   = "backend"
+
+	syncStateDirName = "sync"
+)
+
+// syncBackend is an external secret store that a directory is synchronized with.
+// Secrets are identified by their path relative to the synchronized directory,
+// with forward slashes as separators.
+type syncBackend interface {
+	// List returns the current version of every secret in the store.
+	List() (map[string]syncVersion, error)
+	// Read returns the value of a secret.
+	Read(name string) ([]byte, error)
+	// Write creates or updates a secret and returns its new version.
+	Write(name string, data []byte) (string, error)
+}
+
+// syncVersion identifies the current value of a secret on one side of the sync.
+type syncVersion struct {
+	// version changes whenever the value of the secret changes.
+	version  string
+	modified time.Time
+}
+
+// SyncCommand synchronizes the secrets in a directory with an external secret store in both directions.
+type SyncCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+	store     CredentialConfig
+	path      api.DirPath
+	backend   string
+	target    string
+	region    string
+	conflict  string
+	stateFile string
+	interval  time.Duration
+	dryRun    bool
+	logger    cli.Logger
+}
+
+// NewSyncCommand creates a new SyncCommand.
+func NewSyncCommand(io ui.IO, newClient newClientFunc, store CredentialConfig, logger cli.Logger) *SyncCommand {
+	return &SyncCommand{
+		io:        io,
+		newClient: newClient,
+		store:     store,
+		logger:    logger,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *SyncCommand) Register(r command.Registerer) {
+	clause := r.Command("sync", "Synchronize the secrets in a directory with an external secret store in both directions.")
+	clause.HelpLong("Every secret in the directory and its subdirectories is mirrored to the store set with --backend and --target, and every secret in the store is mirrored back. " +
+		"Supported backends are " + syncBackendAWS + ", with a prefix of secret names as target, and " + syncBackendLocal + ", with a local directory as target.\n" +
+		"\n" +
+		"The versions that were synchronized last are recorded in a state file. A secret that changed on one side since then is copied to the other side. " +
+		"A secret that changed on both sides is a conflict, which is resolved with the strategy set with --conflict: " +
+		"skip reports the conflict and leaves both sides as they are, newest keeps the most recently changed value, and secrethub or backend always keep the value on that side. " +
+		"Deleting a secret on one side is reported, but never deletes the secret on the other side.")
+	clause.Arg("dir-path", "The path to the directory to synchronize.").Required().PlaceHolder(dirPathPlaceHolder).SetValue(&cmd.path)
+	clause.Flag("backend", "The type of store to synchronize with: "+syncBackendAWS+" or "+syncBackendLocal+".").Required().EnumVar(&cmd.backend, syncBackendAWS, syncBackendLocal)
+	clause.Flag("target", "The prefix of the secret names for "+syncBackendAWS+", e.g. app/prod/, or the directory for "+syncBackendLocal+".").Required().StringVar(&cmd.target)
+	clause.Flag("region", "The AWS region of "+syncBackendAWS+". Defaults to the region in the AWS configuration.").StringVar(&cmd.region)
+	clause.Flag("conflict", "How to resolve secrets that changed on both sides: skip, newest, secrethub or backend.").Default(syncConflictSkip).EnumVar(&cmd.conflict, syncConflictSkip, syncConflictNewest, syncConflictSecretHub, syncConflictBackend)
+	clause.Flag("state-file", "The file that records the synchronized versions. Defaults to a file in the sync directory of the configuration directory.").StringVar(&cmd.stateFile)
+	clause.Flag("interval", "Keep running and synchronize again after every interval, e.g. 5m. By default the secrets are synchronized once.").DurationVar(&cmd.interval)
+	clause.Flag("dry-run", "Print what would be synchronized, without changing anything.").BoolVar(&cmd.dryRun)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run synchronizes the secrets once or, when an interval is set, until the process is stopped.
+func (cmd *SyncCommand) Run() error {
+	backend, err := cmd.openBackend()
+	if err != nil {
+		return err
+	}
+
+	err = cmd.sync(backend)
+	if err != nil || cmd.interval <= 0 || cmd.dryRun {
+		return err
+	}
+
+	logger := cmd.logger.With("dir", cmd.path.Value(), "backend", cmd.backend, "target", cmd.target)
+	for range time.Tick(cmd.interval) {
+		err := cmd.sync(backend)
+		if err != nil {
+			logger.With("error", err).Errorf("Synchronizing secrets failed")
+		} else {
+			logger.Debugf("Synchronized secrets")
+		}
+	}
+	return nil
+}
+
+// openBackend returns the configured backend.
+func (cmd *SyncCommand) openBackend() (syncBackend, error) {
+	switch cmd.backend {
+	case syncBackendAWS:
+		return newAWSSyncBackend(cmd.target, cmd.region)
+	default:
+		return newLocalSyncBackend(cmd.target)
+	}
+}
+
+// statePath returns the path of the state file. By default, every combination of
+// directory, backend and target has its own state file in the configuration directory.
+func (cmd *SyncCommand) statePath() string {
+	if cmd.stateFile != "" {
+		return cmd.stateFile
+	}
+	id := sha256.Sum256([]byte(cmd.path.Value() + "\n" + cmd.backend + "\n" + cmd.target))
+	return filepath.Join(cmd.store.ConfigDir().Path(), syncStateDirName, hex.EncodeToString(id[:8])+".json")
+}
+
+// sync runs a single synchronization.
+func (cmd *SyncCommand) sync(backend syncBackend) error {
+	state, err := readSyncState(cmd.statePath())
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	hub, err := cmd.listSecretHub(client)
+	if err != nil {
+		return err
+	}
+
+	ext, err := backend.List()
+	if err != nil {
+		return err
+	}
+
+	actions := planSync(hub, ext, state, cmd.conflict)
+
+	if cmd.dryRun {
+		return printSyncActions(cmd.io.Output(), actions)
+	}
+
+	s := &syncer{
+		client:  client,
+		backend: backend,
+		dir:     cmd.path.Value(),
+		state:   state,
+		created: map[string]bool{},
+	}
+
+	changes := 0
+	for _, action := range actions {
+		result, err := s.apply(action, hub[action.name], ext[action.name])
+		if err != nil {
+			return err
+		}
+		if result != "" {
+			fmt.Fprintf(cmd.io.Output(), "%s: %s\n", action.name, result)
+		}
+		if action.kind == syncPush || action.kind == syncPull || action.kind == syncConflict {
+			changes++
+		}
+	}
+
+	err = writeSyncState(cmd.statePath(), state)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Synchronized %s with %s %s, %s.\n", cmd.path, cmd.backend, cmd.target, pluralize("change", "changes", changes))
+	return nil
+}
+
+// listSecretHub returns the current version of every secret in the directory.
+func (cmd *SyncCommand) listSecretHub(client secrethub.ClientInterface) (map[string]syncVersion, error) {
+	tree, err := client.Dirs().GetTree(cmd.path.Value(), -1, false)
+	if err != nil {
+		return nil, err
+	}
+	excludeTrash(tree, cmd.path.Value())
+
+	result := map[string]syncVersion{}
+	for _, secretPath := range secretPathsInDir(tree.RootDir, cmd.path.Value()) {
+		version, err := client.Secrets().Versions().GetWithoutData(secretPath)
+		if err != nil {
+			return nil, err
+		}
+		name := secretPath[len(cmd.path.Value())+1:]
+		result[name] = syncVersion{
+			version:  strconv.Itoa(version.Version),
+			modified: version.CreatedAt,
+		}
+	}
+	return result, nil
+}
+
+// syncState records the versions on both sides after the last synchronization of every secret.
+type syncState struct {
+	Secrets map[string]syncStateEntry `json:"secrets"`
+}
+
+type syncStateEntry struct {
+	SecretHub string `json:"secrethub"`
+	Backend   string `json:"backend"`
+}
+
+// readSyncState reads the state file. A state file that does not exist is empty.
+func readSyncState(file string) (*syncState, error) {
+	state := &syncState{Secrets: map[string]syncStateEntry{}}
+
+	raw, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return state, nil
+	} else if err != nil {
+		return nil, ErrCannotReadFile(file, err)
+	}
+
+	err = json.Unmarshal(raw, state)
+	if err != nil {
+		return nil, ErrSyncInvalidState(file, err)
+	}
+	if state.Secrets == nil {
+		state.Secrets = map[string]syncStateEntry{}
+	}
+	return state, nil
+}
+
+// writeSyncState atomically replaces the state file.
+func writeSyncState(file string, state *syncState) error {
+	raw, err := json.MarshalIndent(state, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(file), 0700)
+	if err != nil {
+		return ErrCannotWrite(file, err)
+	}
+
+	err = atomicfile.WriteFile(file, append(raw, '\n'), 0600)
+	if err != nil {
+		return ErrCannotWrite(file, err)
+	}
+	return nil
+}
+
+// syncActionKind is what happens to a secret during a synchronization.
+type syncActionKind string
+
+const (
+	syncPush     syncActionKind = "push"
+	syncPull     syncActionKind = "pull"
+	syncConflict syncActionKind = "conflict"
+	// syncDeleted secrets have been deleted on one side and are left as they are.
+	syncDeleted syncActionKind = "deleted"
+)
+
+// syncAction is a change to a single secret.
+type syncAction struct {
+	name   string
+	kind   syncActionKind
+	reason string
+}
+
+// planSync compares the versions on both sides with the state and returns
+// the actions to take, sorted by name. Secrets that are in sync are left out.
+func planSync(hub, ext map[string]syncVersion, state *syncState, strategy string) []syncAction {
+	names := map[string]bool{}
+	for name := range hub {
+		names[name] = true
+	}
+	for name := range ext {
+		names[name] = true
+	}
+
+	var actions []syncAction
+	for name := range names {
+		h, inHub := hub[name]
+		e, inExt := ext[name]
+		s, synced := state.Secrets[name]
+
+		switch {
+		case inHub && !inExt && synced:
+			actions = append(actions, syncAction{name: name, kind: syncDeleted, reason: "deleted in the backend"})
+		case inHub && !inExt:
+			actions = append(actions, syncAction{name: name, kind: syncPush, reason: "new in SecretHub"})
+		case !inHub && inExt && synced:
+			actions = append(actions, syncAction{name: name, kind: syncDeleted, reason: "deleted in SecretHub"})
+		case !inHub && inExt:
+			actions = append(actions, syncAction{name: name, kind: syncPull, reason: "new in the backend"})
+		default:
+			hubChanged := !synced || h.version != s.SecretHub
+			extChanged := !synced || e.version != s.Backend
+			switch {
+			case hubChanged && extChanged:
+				actions = append(actions, syncAction{name: name, kind: syncConflict, reason: resolveSyncConflict(h, e, strategy)})
+			case hubChanged:
+				actions = append(actions, syncAction{name: name, kind: syncPush, reason: "changed in SecretHub"})
+			case extChanged:
+				actions = append(actions, syncAction{name: name, kind: syncPull, reason: "changed in the backend"})
+			}
+		}
+	}
+
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i].name < actions[j].name
+	})
+	return actions
+}
+
+// resolveSyncConflict returns the side whose value is kept when a secret changed on both sides,
+// or skip when the conflict is left unresolved.
+func resolveSyncConflict(h, e syncVersion, strategy string) string {
+	switch strategy {
+	case syncConflictSecretHub:
+		return syncConflictSecretHub
+	case syncConflictBackend:
+		return syncConflictBackend
+	case syncConflictNewest:
+		if e.modified.After(h.modified) {
+			return syncConflictBackend
+		}
+		return syncConflictSecretHub
+	default:
+		return syncConflictSkip
+	}
+}
+
+// printSyncActions writes a table with the actions of a dry run.
+func printSyncActions(w io.Writer, actions []syncAction) error {
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\t%s\t%s\n", "ACTION", "SECRET", "REASON")
+	for _, action := range actions {
+		reason := action.reason
+		if action.kind == syncConflict {
+			switch reason {
+			case syncConflictSecretHub:
+				reason = "changed on both sides, keep the value in SecretHub"
+			case syncConflictBackend:
+				reason = "changed on both sides, keep the value in the backend"
+			default:
+				reason = "changed on both sides, skip"
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", action.kind, action.name, reason)
+	}
+	return tw.Flush()
+}
+
+// syncer applies sync actions and records the results in the state.
+type syncer struct {
+	client  secrethub.ClientInterface
+	backend syncBackend
+	dir     string
+	state   *syncState
+	created map[string]bool
+}
+
+// apply applies the action to a secret and returns a description of the result.
+// For conflicts, the reason of the action is the side whose value is kept.
+func (s *syncer) apply(action syncAction, h, e syncVersion) (string, error) {
+	switch action.kind {
+	case syncPush:
+		return "copied to the backend (" + action.reason + ")", s.push(action.name, h)
+	case syncPull:
+		return "copied to SecretHub (" + action.reason + ")", s.pull(action.name, e)
+	case syncDeleted:
+		return action.reason + ", not synchronized", nil
+	}
+
+	hubData, err := s.readSecretHub(action.name)
+	if err != nil {
+		return "", err
+	}
+	extData, err := s.backend.Read(action.name)
+	if err != nil {
+		return "", err
+	}
+	if bytes.Equal(hubData, extData) {
+		s.record(action.name, h.version, e.version)
+		return "", nil
+	}
+
+	switch action.reason {
+	case syncConflictSecretHub:
+		return "changed on both sides, kept the value in SecretHub", s.push(action.name, h)
+	case syncConflictBackend:
+		return "changed on both sides, kept the value in the backend", s.pull(action.name, e)
+	default:
+		return "changed on both sides, skipped (use --conflict to resolve)", nil
+	}
+}
+
+// push copies the secret from SecretHub to the backend.
+func (s *syncer) push(name string, h syncVersion) error {
+	data, err := s.readSecretHub(name + ":" + h.version)
+	if err != nil {
+		return err
+	}
+
+	version, err := s.backend.Write(name, data)
+	if err != nil {
+		return err
+	}
+
+	s.record(name, h.version, version)
+	return nil
+}
+
+// pull copies the secret from the backend to SecretHub.
+func (s *syncer) pull(name string, e syncVersion) error {
+	secretPath := s.dir + "/" + name
+	err := api.ValidateSecretPath(secretPath)
+	if err != nil {
+		return err
+	}
+
+	data, err := s.backend.Read(name)
+	if err != nil {
+		return err
+	}
+
+	dir := path.Dir(secretPath)
+	if !s.created[dir] {
+		dirPath, err := api.NewDirPath(dir)
+		if err != nil {
+			return err
+		}
+		if !dirPath.IsRepoPath() {
+			err = s.client.Dirs().CreateAll(dir)
+			if err != nil {
+				return err
+			}
+		}
+		s.created[dir] = true
+	}
+
+	version, err := s.client.Secrets().Write(secretPath, data)
+	if err != nil {
+		return err
+	}
+
+	s.record(name, strconv.Itoa(version.Version), e.version)
+	return nil
+}
+
+func (s *syncer) readSecretHub(name string) ([]byte, error) {
+	secret, err := s.client.Secrets().Versions().GetWithData(s.dir + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	return secret.Data, nil
+}
+
+func (s *syncer) record(name, hubVersion, backendVersion string) {
+	s.state.Secrets[name] = syncStateEntry{
+		SecretHub: hubVersion,
+		Backend:   backendVersion,
+	}
+}
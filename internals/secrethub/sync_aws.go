@@ -0,0 +1,151 @@
+package secrethub
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+
+	shaws "github.com/secrethub/secrethub-go/internals/aws"
+	"github.com/secrethub/secrethub-go/internals/errio"
+)
+
+const awsCurrentStage = "AWSCURRENT"
+
+// awsSyncBackend stores every secret in AWS Secrets Manager, as a secret named by
+// the prefix followed by the relative path of the secret.
+type awsSyncBackend struct {
+	prefix string
+	svc    *secretsmanager.SecretsManager
+}
+
+// newAWSSyncBackend creates a backend that synchronizes with the secrets in AWS Secrets Manager
+// with the given name prefix. When region is empty, the region of the AWS configuration is used.
+func newAWSSyncBackend(prefix string, region string) (*awsSyncBackend, error) {
+	cfg := aws.NewConfig()
+	if region != "" {
+		_, ok := endpoints.AwsPartition().Regions()[region]
+		if !ok {
+			return nil, ErrInvalidAWSRegion
+		}
+		cfg = cfg.WithRegion(region)
+	}
+
+	sess, err := session.NewSession(cfg)
+	if err != nil {
+		return nil, handleAWSSyncErr(err)
+	}
+
+	return &awsSyncBackend{
+		prefix: prefix,
+		svc:    secretsmanager.New(sess),
+	}, nil
+}
+
+// List returns the current version of all secrets with the prefix. Secrets that are scheduled
+// for deletion are left out.
+func (b *awsSyncBackend) List() (map[string]syncVersion, error) {
+	result := map[string]syncVersion{}
+	err := b.svc.ListSecretsPages(&secretsmanager.ListSecretsInput{}, func(page *secretsmanager.ListSecretsOutput, _ bool) bool {
+		for _, entry := range page.SecretList {
+			name := aws.StringValue(entry.Name)
+			if !strings.HasPrefix(name, b.prefix) || entry.DeletedDate != nil {
+				continue
+			}
+
+			var version string
+			for id, stages := range entry.SecretVersionsToStages {
+				for _, stage := range stages {
+					if aws.StringValue(stage) == awsCurrentStage {
+						version = id
+					}
+				}
+			}
+			if version == "" {
+				// The secret does not have a value yet.
+				continue
+			}
+
+			result[strings.TrimPrefix(name, b.prefix)] = syncVersion{
+				version:  version,
+				modified: aws.TimeValue(entry.LastChangedDate),
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, handleAWSSyncErr(err)
+	}
+	return result, nil
+}
+
+// Read returns the current value of a secret.
+func (b *awsSyncBackend) Read(name string) ([]byte, error) {
+	out, err := b.svc.GetSecretValue(&secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(b.prefix + name),
+	})
+	if err != nil {
+		return nil, handleAWSSyncErr(err)
+	}
+	if out.SecretString != nil {
+		return []byte(*out.SecretString), nil
+	}
+	return out.SecretBinary, nil
+}
+
+// Write sets the value of a secret, creating the secret when it does not exist yet.
+// Values that are not valid UTF-8 are stored as binary secrets.
+func (b *awsSyncBackend) Write(name string, data []byte) (string, error) {
+	var secretString *string
+	var secretBinary []byte
+	if utf8.Valid(data) {
+		secretString = aws.String(string(data))
+	} else {
+		secretBinary = data
+	}
+
+	out, err := b.svc.PutSecretValue(&secretsmanager.PutSecretValueInput{
+		SecretId:     aws.String(b.prefix + name),
+		SecretString: secretString,
+		SecretBinary: secretBinary,
+	})
+	if err == nil {
+		return aws.StringValue(out.VersionId), nil
+	}
+
+	errAWS, ok := err.(awserr.Error)
+	if !ok || errAWS.Code() != secretsmanager.ErrCodeResourceNotFoundException {
+		return "", handleAWSSyncErr(err)
+	}
+
+	created, err := b.svc.CreateSecret(&secretsmanager.CreateSecretInput{
+		Name:         aws.String(b.prefix + name),
+		Description:  aws.String("Synchronized by secrethub sync at " + time.Now().UTC().Format(time.RFC3339)),
+		SecretString: secretString,
+		SecretBinary: secretBinary,
+	})
+	if err != nil {
+		return "", handleAWSSyncErr(err)
+	}
+	return aws.StringValue(created.VersionId), nil
+}
+
+// handleAWSSyncErr converts errors of the AWS SDK into errors with a code.
+func handleAWSSyncErr(err error) error {
+	errAWS, ok := err.(awserr.Error)
+	if !ok {
+		return err
+	}
+	switch errAWS.Code() {
+	case "NoCredentialProviders":
+		return shaws.ErrNoAWSCredentials
+	case "MissingRegion":
+		return ErrMissingRegion
+	}
+	return errio.Namespace("aws").Code(errAWS.Code()).Error(errAWS.Message())
+}
@@ -0,0 +1,100 @@
+package secrethub
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/atomicfile"
+)
+
+// localSyncBackend stores every secret in a file below a local directory.
+type localSyncBackend struct {
+	dir string
+}
+
+// newLocalSyncBackend creates a backend that synchronizes with the given directory.
+func newLocalSyncBackend(dir string) (*localSyncBackend, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &localSyncBackend{dir: abs}, nil
+}
+
+// List returns all files below the directory. The version of a file is the hash of its contents,
+// so that modifications that do not change the contents are not synchronized.
+func (b *localSyncBackend) List() (map[string]syncVersion, error) {
+	result := map[string]syncVersion{}
+
+	_, err := os.Stat(b.dir)
+	if os.IsNotExist(err) {
+		return result, nil
+	}
+
+	err = filepath.Walk(b.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return ErrCannotReadFile(path, err)
+		}
+
+		name, err := filepath.Rel(b.dir, path)
+		if err != nil {
+			return err
+		}
+
+		result[filepath.ToSlash(name)] = syncVersion{
+			version:  localSyncVersion(data),
+			modified: info.ModTime(),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Read returns the contents of the file of a secret.
+func (b *localSyncBackend) Read(name string) ([]byte, error) {
+	path := b.path(name)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, ErrCannotReadFile(path, err)
+	}
+	return data, nil
+}
+
+// Write atomically replaces the file of a secret. Files are only readable by the current user.
+func (b *localSyncBackend) Write(name string, data []byte) (string, error) {
+	path := b.path(name)
+
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return "", ErrCannotWrite(path, err)
+	}
+
+	err = atomicfile.WriteFile(path, data, 0600)
+	if err != nil {
+		return "", ErrCannotWrite(path, err)
+	}
+	return localSyncVersion(data), nil
+}
+
+func (b *localSyncBackend) path(name string) string {
+	return filepath.Join(b.dir, filepath.FromSlash(name))
+}
+
+func localSyncVersion(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,148 @@
+package secrethub
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestPlanSync(t *testing.T) {
+	older := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	cases := map[string]struct {
+		hub      map[string]syncVersion
+		ext      map[string]syncVersion
+		state    map[string]syncStateEntry
+		strategy string
+		expected []syncAction
+	}{
+		"in sync": {
+			hub:      map[string]syncVersion{"a": {version: "1"}},
+			ext:      map[string]syncVersion{"a": {version: "x"}},
+			state:    map[string]syncStateEntry{"a": {SecretHub: "1", Backend: "x"}},
+			expected: nil,
+		},
+		"new": {
+			hub:   map[string]syncVersion{"a": {version: "1"}},
+			ext:   map[string]syncVersion{"b": {version: "x"}},
+			state: map[string]syncStateEntry{},
+			expected: []syncAction{
+				{name: "a", kind: syncPush, reason: "new in SecretHub"},
+				{name: "b", kind: syncPull, reason: "new in the backend"},
+			},
+		},
+		"changed on one side": {
+			hub:   map[string]syncVersion{"a": {version: "2"}, "b": {version: "1"}},
+			ext:   map[string]syncVersion{"a": {version: "x"}, "b": {version: "y"}},
+			state: map[string]syncStateEntry{"a": {SecretHub: "1", Backend: "x"}, "b": {SecretHub: "1", Backend: "x"}},
+			expected: []syncAction{
+				{name: "a", kind: syncPush, reason: "changed in SecretHub"},
+				{name: "b", kind: syncPull, reason: "changed in the backend"},
+			},
+		},
+		"deleted": {
+			hub:   map[string]syncVersion{"a": {version: "1"}},
+			ext:   map[string]syncVersion{"b": {version: "x"}},
+			state: map[string]syncStateEntry{"a": {SecretHub: "1", Backend: "x"}, "b": {SecretHub: "1", Backend: "x"}},
+			expected: []syncAction{
+				{name: "a", kind: syncDeleted, reason: "deleted in the backend"},
+				{name: "b", kind: syncDeleted, reason: "deleted in SecretHub"},
+			},
+		},
+		"conflict skip": {
+			hub:      map[string]syncVersion{"a": {version: "2"}},
+			ext:      map[string]syncVersion{"a": {version: "y"}},
+			state:    map[string]syncStateEntry{"a": {SecretHub: "1", Backend: "x"}},
+			strategy: syncConflictSkip,
+			expected: []syncAction{
+				{name: "a", kind: syncConflict, reason: syncConflictSkip},
+			},
+		},
+		"conflict without state": {
+			hub:      map[string]syncVersion{"a": {version: "1"}},
+			ext:      map[string]syncVersion{"a": {version: "x"}},
+			state:    map[string]syncStateEntry{},
+			strategy: syncConflictBackend,
+			expected: []syncAction{
+				{name: "a", kind: syncConflict, reason: syncConflictBackend},
+			},
+		},
+		"conflict newest": {
+			hub:      map[string]syncVersion{"a": {version: "2", modified: older}, "b": {version: "2", modified: newer}},
+			ext:      map[string]syncVersion{"a": {version: "y", modified: newer}, "b": {version: "y", modified: older}},
+			state:    map[string]syncStateEntry{"a": {SecretHub: "1", Backend: "x"}, "b": {SecretHub: "1", Backend: "x"}},
+			strategy: syncConflictNewest,
+			expected: []syncAction{
+				{name: "a", kind: syncConflict, reason: syncConflictBackend},
+				{name: "b", kind: syncConflict, reason: syncConflictSecretHub},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual := planSync(tc.hub, tc.ext, &syncState{Secrets: tc.state}, tc.strategy)
+
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
+
+func TestSyncState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrethub-sync")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "sync", "state.json")
+
+	state, err := readSyncState(file)
+	assert.OK(t, err)
+	assert.Equal(t, state, &syncState{Secrets: map[string]syncStateEntry{}})
+
+	state.Secrets["a/b"] = syncStateEntry{SecretHub: "3", Backend: "x"}
+	err = writeSyncState(file, state)
+	assert.OK(t, err)
+
+	actual, err := readSyncState(file)
+	assert.OK(t, err)
+	assert.Equal(t, actual, state)
+}
+
+func TestLocalSyncBackend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrethub-sync")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	backend, err := newLocalSyncBackend(filepath.Join(dir, "target"))
+	assert.OK(t, err)
+
+	list, err := backend.List()
+	assert.OK(t, err)
+	assert.Equal(t, len(list), 0)
+
+	version, err := backend.Write("db/password", []byte("s3cr3t"))
+	assert.OK(t, err)
+
+	list, err = backend.List()
+	assert.OK(t, err)
+	assert.Equal(t, len(list), 1)
+	assert.Equal(t, list["db/password"].version, version)
+
+	data, err := backend.Read("db/password")
+	assert.OK(t, err)
+	assert.Equal(t, data, []byte("s3cr3t"))
+
+	// Writing the same value does not change the version.
+	same, err := backend.Write("db/password", []byte("s3cr3t"))
+	assert.OK(t, err)
+	assert.Equal(t, same, version)
+
+	changed, err := backend.Write("db/password", []byte("changed"))
+	assert.OK(t, err)
+	assert.Equal(t, changed != version, true)
+}
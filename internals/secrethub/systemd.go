@@ -0,0 +1,210 @@
+package secrethub
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/svcmgr"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/errio"
+)
+
+// Errors
+var (
+	errSystemd               = errio.Namespace("systemd")
+	ErrInvalidSystemdUnit    = errSystemd.Code("invalid_unit").ErrorPref("invalid unit name %s: only services can load credentials")
+	ErrInvalidCredentialName = errSystemd.Code("invalid_credential_name").ErrorPref("invalid credential name %s: it can only contain letters, digits, dashes, underscores and dots")
+	ErrNoSystemdCredentials  = errSystemd.Code("no_credentials").Error("no secrets have been provided: use --secret NAME=path")
+	ErrInvalidSystemdConfig  = errSystemd.Code("invalid_config").ErrorPref("cannot parse %s: %s")
+	ErrInvalidCredentialPath = errSystemd.Code("invalid_credential_path").ErrorPref("invalid path for credential %s: %s")
+)
+
+var (
+	systemdUnitNamePattern       = regexp.MustCompile(`^[a-zA-Z0-9:_.\\@-]+\.service$`)
+	systemdCredentialNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+)
+
+const (
+	// systemdGeneratorBinary is the name to link the CLI to in a systemd generator directory.
+	systemdGeneratorBinary = "secrethub-systemd-generator"
+
+	systemdDropInName         = "secrethub.conf"
+	systemdRuntimeDir         = "/run"
+	defaultSystemdConfigFile  = "/etc/secrethub/systemd.yml"
+	systemdCredentialsHeader  = "# Generated by secrethub systemd. Changes to this file are overwritten.\n"
+	systemdReaderUnitTemplate = "secrethub-credentials-%s.service"
+)
+
+// SystemdCommand handles integrations with systemd.
+type SystemdCommand struct {
+	io              ui.IO
+	credentialStore CredentialConfig
+}
+
+// NewSystemdCommand creates a new SystemdCommand.
+func NewSystemdCommand(io ui.IO, credentialStore CredentialConfig) *SystemdCommand {
+	return &SystemdCommand{
+		io:              io,
+		credentialStore: credentialStore,
+	}
+}
+
+// Register registers the command and its sub-commands on the provided Registerer.
+func (cmd *SystemdCommand) Register(r command.Registerer) {
+	clause := r.Command("systemd", "Load secrets into systemd services as credentials.")
+	clause.HelpLong("Services read their credentials from files in the directory in the $CREDENTIALS_DIRECTORY environment variable, " +
+		"instead of from environment variables that are visible to the whole process tree. " +
+		"Before a service starts, a companion unit reads the secrets with secrethub read into a private directory in /run, " +
+		"from which the service loads them with LoadCredential=. " +
+		"The secrets are read again whenever the service restarts and are removed when it stops.\n\n" +
+		"SetCredential= is not used, as it stores the value in the unit file itself. " +
+		"Credentials require systemd 247 or newer.")
+	NewSystemdInstallCommand(cmd.io, cmd.credentialStore).Register(clause)
+	NewSystemdGeneratorCommand(cmd.io, cmd.credentialStore).Register(clause)
+}
+
+// systemdCredentials are the secrets a systemd service loads as credentials.
+type systemdCredentials struct {
+	// unit is the name of the service, e.g. nginx.service.
+	unit string
+	// secrets maps the names of the credentials to the paths of the secrets.
+	secrets    map[string]string
+	executable string
+	configDir  string
+}
+
+// systemdServiceName returns the name of the unit, adding the .service suffix when it is omitted.
+func systemdServiceName(unit string) (string, error) {
+	if !strings.Contains(unit, ".") {
+		unit += ".service"
+	}
+	if !systemdUnitNamePattern.MatchString(unit) {
+		return "", ErrInvalidSystemdUnit(unit)
+	}
+	return unit, nil
+}
+
+// validate checks the names of the credentials and the paths of their secrets.
+func (c systemdCredentials) validate() error {
+	if len(c.secrets) == 0 {
+		return ErrNoSystemdCredentials
+	}
+	for name, path := range c.secrets {
+		if !systemdCredentialNamePattern.MatchString(name) {
+			return ErrInvalidCredentialName(name)
+		}
+		err := api.ValidateSecretPath(path)
+		if err != nil {
+			return ErrInvalidCredentialPath(name, err)
+		}
+	}
+	return nil
+}
+
+// readerUnit returns the name of the unit that reads the secrets of the service.
+func (c systemdCredentials) readerUnit() string {
+	return fmt.Sprintf(systemdReaderUnitTemplate, strings.Replace(strings.TrimSuffix(c.unit, ".service"), "@", "_", -1))
+}
+
+// credentialsDir returns the directory the secrets are read into,
+// relative to the runtime directory.
+func (c systemdCredentials) credentialsDir() string {
+	return filepath.Join("secrethub", c.unit)
+}
+
+// names returns the names of the credentials in alphabetical order.
+func (c systemdCredentials) names() []string {
+	names := make([]string, 0, len(c.secrets))
+	for name := range c.secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// files returns the unit files to write, by their path relative to the unit directory.
+func (c systemdCredentials) files() map[string][]byte {
+	return map[string][]byte{
+		c.readerUnit(): c.readerUnitFile(),
+		filepath.Join(c.unit+".d", systemdDropInName): c.dropInFile(),
+	}
+}
+
+// readerUnitFile returns the unit file of the oneshot service that reads the secrets.
+// It is part of the service, so it runs again whenever the service restarts
+// and its runtime directory is removed when the service stops.
+func (c systemdCredentials) readerUnitFile() []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprint(&buf, systemdCredentialsHeader)
+	fmt.Fprintln(&buf, "[Unit]")
+	fmt.Fprintf(&buf, "Description=Read the credentials of %s from SecretHub\n", c.unit)
+	fmt.Fprintln(&buf, "Wants=network-online.target")
+	fmt.Fprintln(&buf, "After=network-online.target")
+	fmt.Fprintf(&buf, "PartOf=%s\n", c.unit)
+	fmt.Fprintln(&buf)
+
+	fmt.Fprintln(&buf, "[Service]")
+	fmt.Fprintln(&buf, "Type=oneshot")
+	fmt.Fprintln(&buf, "RemainAfterExit=yes")
+	if c.configDir != "" {
+		fmt.Fprintf(&buf, "Environment=%s\n", svcmgr.SystemdQuote("SECRETHUB_CONFIG_DIR="+c.configDir))
+	}
+	fmt.Fprintf(&buf, "RuntimeDirectory=%s\n", svcmgr.SystemdQuote(c.credentialsDir()))
+	fmt.Fprintln(&buf, "RuntimeDirectoryMode=0700")
+	fmt.Fprintln(&buf, "UMask=0077")
+	for _, name := range c.names() {
+		args := []string{c.executable, "read", c.secrets[name], "--out-file", filepath.Join(systemdRuntimeDir, c.credentialsDir(), name)}
+		for i, arg := range args {
+			// Only Exec lines expand variables, so $ is escaped here.
+			args[i] = svcmgr.SystemdQuote(strings.Replace(arg, "$", "$$", -1))
+		}
+		fmt.Fprintf(&buf, "ExecStart=%s\n", strings.Join(args, " "))
+	}
+
+	return buf.Bytes()
+}
+
+// dropInFile returns the drop-in for the service that loads the secrets as credentials.
+func (c systemdCredentials) dropInFile() []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprint(&buf, systemdCredentialsHeader)
+	fmt.Fprintln(&buf, "[Unit]")
+	fmt.Fprintf(&buf, "Requires=%s\n", c.readerUnit())
+	fmt.Fprintf(&buf, "After=%s\n", c.readerUnit())
+	fmt.Fprintln(&buf)
+
+	fmt.Fprintln(&buf, "[Service]")
+	for _, name := range c.names() {
+		fmt.Fprintf(&buf, "LoadCredential=%s\n", svcmgr.SystemdQuote(name+":"+filepath.Join(systemdRuntimeDir, c.credentialsDir(), name)))
+	}
+
+	return buf.Bytes()
+}
+
+// write writes the unit files into the unit directory.
+func (c systemdCredentials) write(dir string) error {
+	for name, content := range c.files() {
+		path := filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			return ErrCannotWrite(path, err)
+		}
+
+		err = ioutil.WriteFile(path, content, 0644)
+		if err != nil {
+			return ErrCannotWrite(path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package secrethub
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"gopkg.in/yaml.v2"
+)
+
+// SystemdGeneratorCommand is a systemd generator that writes the unit files to load secrets
+// into services, as configured in a file.
+type SystemdGeneratorCommand struct {
+	io              ui.IO
+	credentialStore CredentialConfig
+	executable      func() (string, error)
+	normalDir       string
+	earlyDir        string
+	lateDir         string
+	configFile      string
+}
+
+// NewSystemdGeneratorCommand creates a new SystemdGeneratorCommand.
+func NewSystemdGeneratorCommand(io ui.IO, credentialStore CredentialConfig) *SystemdGeneratorCommand {
+	return &SystemdGeneratorCommand{
+		io:              io,
+		credentialStore: credentialStore,
+		executable:      os.Executable,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *SystemdGeneratorCommand) Register(r command.Registerer) {
+	clause := r.Command("generator", "Run as a systemd generator that loads secrets into the services configured in a file.")
+	clause.HelpLong("This command is meant to be invoked by systemd at boot and whenever its configuration is reloaded. " +
+		"Install it by linking the secrethub binary as " + systemdGeneratorBinary + " in a generator directory:\n\n" +
+		"    ln -s $(which secrethub) /etc/systemd/system-generators/" + systemdGeneratorBinary + "\n\n" +
+		"It writes the same unit files as secrethub systemd install for every service in " + defaultSystemdConfigFile + ", e.g.:\n\n" +
+		"    config_dir: /root/.secrethub\n" +
+		"    units:\n" +
+		"      nginx:\n" +
+		"        tls.key: company/web/tls/key\n" +
+		"        tls.crt: company/web/tls/cert\n\n" +
+		"The config_dir defaults to the configuration directory of the user running systemd. " +
+		"Secrets are not read by the generator, so it does not need network access. " +
+		"When the file does not exist, nothing is generated.")
+	clause.Arg("normal-dir", "The directory to write the unit files to.").Required().StringVar(&cmd.normalDir)
+	clause.Arg("early-dir", "The directory for unit files that override those of the administrator. Not used.").StringVar(&cmd.earlyDir)
+	clause.Arg("late-dir", "The directory for unit files that are overridden by all others. Not used.").StringVar(&cmd.lateDir)
+	clause.Flag("config", "The file configuring the secrets to load into services.").Default(defaultSystemdConfigFile).StringVar(&cmd.configFile)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// systemdConfig configures the credentials of systemd services.
+type systemdConfig struct {
+	ConfigDir string                       `yaml:"config_dir"`
+	Units     map[string]map[string]string `yaml:"units"`
+}
+
+// Run writes the unit files of all services in the configuration file.
+func (cmd *SystemdGeneratorCommand) Run() error {
+	config, err := readSystemdConfig(cmd.configFile)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if config.ConfigDir == "" {
+		config.ConfigDir = cmd.credentialStore.ConfigDir().Path()
+	}
+
+	executable, err := cmd.executable()
+	if err != nil {
+		return err
+	}
+	executable, err = filepath.Abs(executable)
+	if err != nil {
+		return err
+	}
+
+	units := make([]string, 0, len(config.Units))
+	for unit := range config.Units {
+		units = append(units, unit)
+	}
+	sort.Strings(units)
+
+	for _, name := range units {
+		unit, err := systemdServiceName(name)
+		if err != nil {
+			return err
+		}
+
+		credentials := systemdCredentials{
+			unit:       unit,
+			secrets:    config.Units[name],
+			executable: executable,
+			configDir:  config.ConfigDir,
+		}
+		err = credentials.validate()
+		if err != nil {
+			return err
+		}
+
+		err = credentials.write(cmd.normalDir)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readSystemdConfig reads the credentials of systemd services from a YAML file.
+// An error satisfying os.IsNotExist is returned when the file does not exist.
+func readSystemdConfig(filename string) (*systemdConfig, error) {
+	raw, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, err
+	} else if err != nil {
+		return nil, ErrCannotReadFile(filename, err)
+	}
+
+	config := &systemdConfig{}
+	err = yaml.UnmarshalStrict(raw, config)
+	if err != nil {
+		return nil, ErrInvalidSystemdConfig(filename, err)
+	}
+	return config, nil
+}
@@ -0,0 +1,93 @@
+package secrethub
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/svcmgr"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// SystemdInstallCommand writes the unit files that load secrets into a systemd service.
+type SystemdInstallCommand struct {
+	io              ui.IO
+	credentialStore CredentialConfig
+	executable      func() (string, error)
+	reload          func() error
+	unit            string
+	secrets         map[string]string
+	unitDir         string
+	noReload        bool
+}
+
+// NewSystemdInstallCommand creates a new SystemdInstallCommand.
+func NewSystemdInstallCommand(io ui.IO, credentialStore CredentialConfig) *SystemdInstallCommand {
+	return &SystemdInstallCommand{
+		io:              io,
+		credentialStore: credentialStore,
+		executable:      os.Executable,
+		reload:          svcmgr.ReloadSystemd,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *SystemdInstallCommand) Register(r command.Registerer) {
+	clause := r.Command("install", "Load secrets into a systemd service as credentials.")
+	clause.HelpLong("Writes a drop-in for the service and a companion unit that reads the secrets, e.g.:\n\n" +
+		"    secrethub systemd install nginx --secret tls.key=company/web/tls/key --secret tls.crt=company/web/tls/cert\n\n" +
+		"After restarting the service, it can read the secrets from $CREDENTIALS_DIRECTORY/tls.key and $CREDENTIALS_DIRECTORY/tls.crt. " +
+		"Running the command again replaces the credentials of the service.\n\n" +
+		"The secrets are read with the credential in the configuration directory, so make sure the credential is not protected by a passphrase. " +
+		"Writing unit files usually requires root privileges.")
+	clause.Arg("unit", "The name of the service, e.g. nginx or nginx.service.").Required().StringVar(&cmd.unit)
+	clause.Flag("secret", "Load the secret at the path as the credential with the name, e.g. --secret db-password=company/app/db/password. Can be repeated.").PlaceHolder("NAME=" + secretPathPlaceHolder).StringMapVar(&cmd.secrets)
+	clause.Flag("unit-dir", "The directory to write the unit files to.").Default(svcmgr.SystemdUnitDir).StringVar(&cmd.unitDir)
+	clause.Flag("no-reload", "Do not make systemd reload its unit files, e.g. when writing them to an image.").BoolVar(&cmd.noReload)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run writes the unit files.
+func (cmd *SystemdInstallCommand) Run() error {
+	unit, err := systemdServiceName(cmd.unit)
+	if err != nil {
+		return err
+	}
+
+	executable, err := cmd.executable()
+	if err != nil {
+		return err
+	}
+	executable, err = filepath.Abs(executable)
+	if err != nil {
+		return err
+	}
+
+	credentials := systemdCredentials{
+		unit:       unit,
+		secrets:    cmd.secrets,
+		executable: executable,
+		configDir:  cmd.credentialStore.ConfigDir().Path(),
+	}
+	err = credentials.validate()
+	if err != nil {
+		return err
+	}
+
+	err = credentials.write(cmd.unitDir)
+	if err != nil {
+		return err
+	}
+
+	if !cmd.noReload {
+		err = cmd.reload()
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Installed %s for %s. Restart it to load them:\n\n    systemctl restart %s\n", pluralize("credential", "credentials", len(cmd.secrets)), unit, unit)
+	return nil
+}
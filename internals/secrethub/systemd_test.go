@@ -0,0 +1,252 @@
+package secrethub
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/configdir"
+)
+
+func TestSystemdServiceName(t *testing.T) {
+	cases := map[string]struct {
+		unit     string
+		expected string
+		err      error
+	}{
+		"without suffix": {
+			unit:     "nginx",
+			expected: "nginx.service",
+		},
+		"with suffix": {
+			unit:     "nginx.service",
+			expected: "nginx.service",
+		},
+		"template instance": {
+			unit:     "app@blue.service",
+			expected: "app@blue.service",
+		},
+		"timer": {
+			unit: "backup.timer",
+			err:  ErrInvalidSystemdUnit("backup.timer"),
+		},
+		"path": {
+			unit: "../nginx",
+			err:  ErrInvalidSystemdUnit("../nginx"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			actual, err := systemdServiceName(tc.unit)
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
+
+func TestSystemdCredentials_validate(t *testing.T) {
+	cases := map[string]struct {
+		secrets map[string]string
+		err     error
+	}{
+		"valid": {
+			secrets: map[string]string{"tls.key": "company/web/tls/key"},
+		},
+		"no secrets": {
+			err: ErrNoSystemdCredentials,
+		},
+		"invalid name": {
+			secrets: map[string]string{"tls/key": "company/web/tls/key"},
+			err:     ErrInvalidCredentialName("tls/key"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			err := systemdCredentials{unit: "nginx.service", secrets: tc.secrets}.validate()
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+		})
+	}
+}
+
+func TestSystemdCredentials_files(t *testing.T) {
+	// Arrange
+	credentials := systemdCredentials{
+		unit: "app@blue.service",
+		secrets: map[string]string{
+			"tls.key":     "company/web/tls/key",
+			"db-password": "company/app/db password",
+		},
+		executable: "/usr/local/bin/secrethub",
+		configDir:  "/root/.secrethub",
+	}
+
+	// Act
+	actual := credentials.files()
+
+	// Assert
+	assert.Equal(t, string(actual["secrethub-credentials-app_blue.service"]), ""+
+		"# Generated by secrethub systemd. Changes to this file are overwritten.\n"+
+		"[Unit]\n"+
+		"Description=Read the credentials of app@blue.service from SecretHub\n"+
+		"Wants=network-online.target\n"+
+		"After=network-online.target\n"+
+		"PartOf=app@blue.service\n"+
+		"\n"+
+		"[Service]\n"+
+		"Type=oneshot\n"+
+		"RemainAfterExit=yes\n"+
+		"Environment=SECRETHUB_CONFIG_DIR=/root/.secrethub\n"+
+		"RuntimeDirectory=secrethub/app@blue.service\n"+
+		"RuntimeDirectoryMode=0700\n"+
+		"UMask=0077\n"+
+		`ExecStart=/usr/local/bin/secrethub read "company/app/db password" --out-file /run/secrethub/app@blue.service/db-password`+"\n"+
+		"ExecStart=/usr/local/bin/secrethub read company/web/tls/key --out-file /run/secrethub/app@blue.service/tls.key\n",
+	)
+	assert.Equal(t, string(actual[filepath.Join("app@blue.service.d", "secrethub.conf")]), ""+
+		"# Generated by secrethub systemd. Changes to this file are overwritten.\n"+
+		"[Unit]\n"+
+		"Requires=secrethub-credentials-app_blue.service\n"+
+		"After=secrethub-credentials-app_blue.service\n"+
+		"\n"+
+		"[Service]\n"+
+		"LoadCredential=db-password:/run/secrethub/app@blue.service/db-password\n"+
+		"LoadCredential=tls.key:/run/secrethub/app@blue.service/tls.key\n",
+	)
+}
+
+func TestSystemdInstallCommand_Run(t *testing.T) {
+	// Setup
+	dir, cleanup := testdata.tempDir(t)
+	defer cleanup()
+
+	io := fakeui.NewIO(t)
+	reloaded := false
+	cmd := SystemdInstallCommand{
+		io:              io,
+		credentialStore: &credentialConfig{configDir: ConfigDir{Dir: configdir.New(filepath.Join(dir, "config"))}},
+		executable: func() (string, error) {
+			return "/usr/local/bin/secrethub", nil
+		},
+		reload: func() error {
+			reloaded = true
+			return nil
+		},
+		unit:    "nginx",
+		secrets: map[string]string{"tls.key": "company/web/tls/key"},
+		unitDir: filepath.Join(dir, "system"),
+	}
+
+	// Act
+	err := cmd.Run()
+
+	// Assert
+	assert.OK(t, err)
+	assert.Equal(t, reloaded, true)
+	assert.Equal(t, io.Out.String(), "Installed 1 credential for nginx.service. Restart it to load them:\n\n    systemctl restart nginx.service\n")
+
+	expected := systemdCredentials{
+		unit:       "nginx.service",
+		secrets:    cmd.secrets,
+		executable: "/usr/local/bin/secrethub",
+		configDir:  filepath.Join(dir, "config"),
+	}
+	for name, content := range expected.files() {
+		actual, err := ioutil.ReadFile(filepath.Join(dir, "system", name))
+		assert.OK(t, err)
+		assert.Equal(t, string(actual), string(content))
+	}
+}
+
+func TestSystemdGeneratorCommand_Run(t *testing.T) {
+	cases := map[string]struct {
+		config   string
+		expected []systemdCredentials
+		err      error
+	}{
+		"units": {
+			config: "" +
+				"config_dir: /etc/secrethub\n" +
+				"units:\n" +
+				"  nginx:\n" +
+				"    tls.key: company/web/tls/key\n" +
+				"  app.service:\n" +
+				"    db-password: company/app/db/password\n",
+			expected: []systemdCredentials{
+				{
+					unit:       "nginx.service",
+					secrets:    map[string]string{"tls.key": "company/web/tls/key"},
+					executable: "/usr/local/bin/secrethub",
+					configDir:  "/etc/secrethub",
+				},
+				{
+					unit:       "app.service",
+					secrets:    map[string]string{"db-password": "company/app/db/password"},
+					executable: "/usr/local/bin/secrethub",
+					configDir:  "/etc/secrethub",
+				},
+			},
+		},
+		"no config file": {},
+		"invalid unit": {
+			config: "" +
+				"units:\n" +
+				"  backup.timer:\n" +
+				"    key: company/backup/key\n",
+			err: ErrInvalidSystemdUnit("backup.timer"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			dir, cleanup := testdata.tempDir(t)
+			defer cleanup()
+
+			configFile := filepath.Join(dir, "systemd.yml")
+			if tc.config != "" {
+				err := ioutil.WriteFile(configFile, []byte(tc.config), 0644)
+				assert.OK(t, err)
+			}
+
+			normalDir := filepath.Join(dir, "generator")
+			cmd := SystemdGeneratorCommand{
+				io:              fakeui.NewIO(t),
+				credentialStore: &credentialConfig{configDir: ConfigDir{Dir: configdir.New(filepath.Join(dir, "config"))}},
+				executable: func() (string, error) {
+					return "/usr/local/bin/secrethub", nil
+				},
+				normalDir:  normalDir,
+				configFile: configFile,
+			}
+
+			// Act
+			err := cmd.Run()
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			for _, credentials := range tc.expected {
+				for name, content := range credentials.files() {
+					actual, err := ioutil.ReadFile(filepath.Join(normalDir, name))
+					assert.OK(t, err)
+					assert.Equal(t, string(actual), string(content))
+				}
+			}
+			if tc.config == "" {
+				_, err := os.Stat(normalDir)
+				assert.Equal(t, os.IsNotExist(err), true)
+			}
+		})
+	}
+}
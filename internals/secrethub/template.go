@@ -4,17 +4,24 @@ import (
 	"github.com/secrethub/secrethub-cli/internals/secrethub/tpl"
 )
 
-func getTemplateParser(raw []byte, version string) (tpl.Parser, error) {
+// getTemplateParser returns the parser for the given template syntax version.
+// Templates in the v3 syntax can include templates from the given include directories.
+func getTemplateParser(raw []byte, version string, includeDirs ...string) (tpl.Parser, error) {
 	switch version {
 	case "auto":
 		if tpl.IsV1Template(raw) {
 			return tpl.NewV1Parser(), nil
 		}
+		if tpl.IsV3Template(raw) {
+			return tpl.NewV3Parser(includeDirs...), nil
+		}
 		return tpl.NewParser(), nil
 	case "1", "v1":
 		return tpl.NewV1Parser(), nil
 	case "2", "v2":
 		return tpl.NewV2Parser(), nil
+	case "3", "v3":
+		return tpl.NewV3Parser(includeDirs...), nil
 	case "latest":
 		return tpl.NewParser(), nil
 	default:
@@ -6,7 +6,15 @@ import (
 
 // Evaluate errors
 var (
-	ErrTemplateVarNotFound = tplError.Code("template_var_not_found").ErrorPref("no value was supplied for template variable '%s'")
+	ErrTemplateVarNotFound     = tplError.Code("template_var_not_found").ErrorPref("no value was supplied for template variable '%s'")
+	ErrFunctionFailed          = tplError.Code("function_failed").ErrorPref("cannot apply function '%s' to the secret: %s")
+	ErrFunctionArguments       = tplError.Code("wrong_number_of_arguments").ErrorPref("wrong number of arguments for function '%s': expected %d, got %d")
+	ErrListSecretsNotSupported = tplError.Code("list_secrets_not_supported").Error("iterating over the secrets in a directory is not supported here")
+	ErrInvalidTemplate         = tplError.Code("invalid_template").ErrorPref("%s")
+	ErrInvalidInclude          = tplError.Code("invalid_include").ErrorPref("cannot include %s: %s")
+	ErrIncludeNotFound         = tplError.Code("include_not_found").ErrorPref("cannot include %s: the template cannot be found in the template directories (%s)")
+	ErrIncludeNotSupported     = tplError.Code("include_not_supported").Error("including templates is not supported here")
+	ErrIncludeTooDeep          = tplError.Code("include_too_deep").ErrorPref("cannot include %s: templates cannot be nested more than %d levels deep, does a template include itself?")
 )
 
 // Parse errors
@@ -73,3 +81,33 @@ func ErrVariableTagNotClosed(lineNo, colNo int) error {
 		msg:    "expected the closing of a variable tag `}`, but reached the end of the template.",
 	}
 }
+
+// ErrIllegalFunctionCharacter is returned when a function in a secret tag contains a character that is not allowed.
+func ErrIllegalFunctionCharacter(lineNo, colNo int, char rune) error {
+	return templateSyntaxError{
+		lineNo: lineNo,
+		colNo:  colNo,
+		code:   "illegal_function_character",
+		msg:    fmt.Sprintf("illegal character '%c'. Function names can only contain letters and digits and arguments should be separated by spaces.", char),
+	}
+}
+
+// ErrUnknownFunction is returned when a secret tag contains a function that does not exist.
+func ErrUnknownFunction(lineNo, colNo int, name string) error {
+	return templateSyntaxError{
+		lineNo: lineNo,
+		colNo:  colNo,
+		code:   "unknown_function",
+		msg:    fmt.Sprintf("unknown function '%s'", name),
+	}
+}
+
+// ErrWrongNumberOfArguments is returned when a function in a secret tag is given too few or too many arguments.
+func ErrWrongNumberOfArguments(lineNo, colNo int, name string, expected, actual int) error {
+	return templateSyntaxError{
+		lineNo: lineNo,
+		colNo:  colNo,
+		code:   "wrong_number_of_arguments",
+		msg:    fmt.Sprintf("function '%s' takes %d argument(s), but %d were given", name, expected, actual),
+	}
+}
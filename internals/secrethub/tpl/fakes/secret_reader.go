@@ -1,6 +1,11 @@
 package fakes
 
-import "errors"
+import (
+	"sort"
+	"strings"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
 
 // FakeSecretReader implements tpl.SecretReader.
 type FakeSecretReader struct {
@@ -13,5 +18,18 @@ func (fsr FakeSecretReader) ReadSecret(path string) (string, error) {
 	if ok {
 		return secret, nil
 	}
-	return "", errors.New("secret not found")
+	return "", api.ErrSecretNotFound
+}
+
+// ListSecrets implements tpl.SecretLister.ListSecrets.
+func (fsr FakeSecretReader) ListSecrets(dirPath string) ([]string, error) {
+	var paths []string
+	for path := range fsr.Secrets {
+		name := strings.TrimPrefix(path, dirPath+"/")
+		if name != path && !strings.Contains(name, "/") {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
 }
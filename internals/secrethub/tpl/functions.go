@@ -0,0 +1,126 @@
+package tpl
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// function is a function that can be applied to the value of a secret tag.
+// The value is piped into the function: {{ path/to/secret | replace "a" "b" }}
+type function struct {
+	// args is the number of arguments the function takes, besides the piped value.
+	args  int
+	apply func(value string, args []string) (string, error)
+}
+
+// functions are the functions that can be used in secret tags, by name.
+var functions = map[string]function{
+	"base64enc": {
+		apply: func(value string, args []string) (string, error) {
+			return base64.StdEncoding.EncodeToString([]byte(value)), nil
+		},
+	},
+	"base64dec": {
+		apply: func(value string, args []string) (string, error) {
+			decoded, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return "", errors.New("the value is not valid base64")
+			}
+			return string(decoded), nil
+		},
+	},
+	"urlencode": {
+		apply: func(value string, args []string) (string, error) {
+			return url.QueryEscape(value), nil
+		},
+	},
+	"jsonescape": {
+		apply: jsonEscape,
+	},
+	"trim": {
+		apply: func(value string, args []string) (string, error) {
+			return strings.TrimSpace(value), nil
+		},
+	},
+	"replace": {
+		args: 2,
+		apply: func(value string, args []string) (string, error) {
+			return strings.Replace(value, args[0], args[1], -1), nil
+		},
+	},
+	"upper": {
+		apply: func(value string, args []string) (string, error) {
+			return strings.ToUpper(value), nil
+		},
+	},
+	"lower": {
+		apply: func(value string, args []string) (string, error) {
+			return strings.ToLower(value), nil
+		},
+	},
+	"default": {
+		args:  1,
+		apply: defaultValue,
+	},
+	"substr": {
+		args:  2,
+		apply: substr,
+	},
+	"sha256": {
+		apply: func(value string, args []string) (string, error) {
+			sum := sha256.Sum256([]byte(value))
+			return hex.EncodeToString(sum[:]), nil
+		},
+	},
+}
+
+// jsonEscape escapes the value so it can be used within a string in a JSON document.
+func jsonEscape(value string, args []string) (string, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(value)
+	if err != nil {
+		return "", err
+	}
+	// Strip the quotes and the newline that are added by the encoder.
+	encoded := strings.TrimSuffix(buffer.String(), "\n")
+	return encoded[1 : len(encoded)-1], nil
+}
+
+// defaultValue returns the argument when the value is empty, e.g. because the secret does not exist.
+func defaultValue(value string, args []string) (string, error) {
+	if value == "" {
+		return args[0], nil
+	}
+	return value, nil
+}
+
+// substr returns the characters of the value from the start index up to, but not including, the end index.
+// Indices past the end of the value are reduced to the length of the value.
+func substr(value string, args []string) (string, error) {
+	start, err := strconv.Atoi(args[0])
+	if err != nil || start < 0 {
+		return "", errors.New("the start index must be a non-negative number")
+	}
+	end, err := strconv.Atoi(args[1])
+	if err != nil || end < start {
+		return "", errors.New("the end index must be a number that is not smaller than the start index")
+	}
+
+	runes := []rune(value)
+	if start > len(runes) {
+		start = len(runes)
+	}
+	if end > len(runes) {
+		end = len(runes)
+	}
+	return string(runes[start:end]), nil
+}
@@ -0,0 +1,123 @@
+package tpl
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestFunctions(t *testing.T) {
+	cases := map[string]struct {
+		function string
+		value    string
+		args     []string
+		expected string
+		err      error
+	}{
+		"base64enc": {
+			function: "base64enc",
+			value:    "hello world",
+			expected: "aGVsbG8gd29ybGQ=",
+		},
+		"base64dec": {
+			function: "base64dec",
+			value:    "aGVsbG8gd29ybGQ=",
+			expected: "hello world",
+		},
+		"base64dec invalid": {
+			function: "base64dec",
+			value:    "hello world",
+			err:      errors.New("the value is not valid base64"),
+		},
+		"urlencode": {
+			function: "urlencode",
+			value:    "p@ss word/&=",
+			expected: "p%40ss+word%2F%26%3D",
+		},
+		"jsonescape": {
+			function: "jsonescape",
+			value:    "a \"quoted\" <value>\n\\",
+			expected: `a \"quoted\" <value>\n\\`,
+		},
+		"trim": {
+			function: "trim",
+			value:    " \tvalue\n",
+			expected: "value",
+		},
+		"replace": {
+			function: "replace",
+			value:    "a-b-c",
+			args:     []string{"-", "_"},
+			expected: "a_b_c",
+		},
+		"upper": {
+			function: "upper",
+			value:    "Value",
+			expected: "VALUE",
+		},
+		"lower": {
+			function: "lower",
+			value:    "Value",
+			expected: "value",
+		},
+		"default for empty value": {
+			function: "default",
+			value:    "",
+			args:     []string{"fallback"},
+			expected: "fallback",
+		},
+		"default for value": {
+			function: "default",
+			value:    "value",
+			args:     []string{"fallback"},
+			expected: "value",
+		},
+		"substr": {
+			function: "substr",
+			value:    "héllo world",
+			args:     []string{"1", "5"},
+			expected: "éllo",
+		},
+		"substr past the end": {
+			function: "substr",
+			value:    "hello",
+			args:     []string{"3", "10"},
+			expected: "lo",
+		},
+		"substr start past the end": {
+			function: "substr",
+			value:    "hello",
+			args:     []string{"10", "12"},
+			expected: "",
+		},
+		"substr negative start": {
+			function: "substr",
+			value:    "hello",
+			args:     []string{"-1", "2"},
+			err:      errors.New("the start index must be a non-negative number"),
+		},
+		"substr end before start": {
+			function: "substr",
+			value:    "hello",
+			args:     []string{"3", "2"},
+			err:      errors.New("the end index must be a number that is not smaller than the start index"),
+		},
+		"sha256": {
+			function: "sha256",
+			value:    "hello world",
+			expected: "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f, ok := functions[tc.function]
+			assert.Equal(t, ok, true)
+
+			actual, err := f.apply(tc.value, tc.args)
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
@@ -10,6 +10,12 @@ var (
 	tokens = []rune{Dollar, LBracket, RBracket, Backslash}
 )
 
+// Tokens that only have a meaning within secret tags. They do not have to be escaped elsewhere.
+var (
+	Pipe  = '|'
+	Quote = '"'
+)
+
 // IsToken returns whether the given rune is a token.
 func IsToken(ch rune) bool {
 	for _, token := range tokens {
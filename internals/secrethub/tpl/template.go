@@ -36,3 +36,11 @@ var v1SecretTag = regexp.MustCompile(`\${[\t ]*[_\-\.a-zA-Z0-9]+/[_\-\.a-zA-Z0-9
 func IsV1Template(raw []byte) bool {
 	return v1SecretTag.Match(raw)
 }
+
+var v3Action = regexp.MustCompile(`{{-?[\t ]*(?:if|range|with|secret|secrets|var|include)[\t ]+[^\t }]`)
+
+// IsV3Template returns whether v3 actions, e.g. {{ if ... }} or {{ secret "path/to/secret" }},
+// are used in the given raw bytes.
+func IsV3Template(raw []byte) bool {
+	return v3Action.Match(raw)
+}
@@ -57,3 +57,47 @@ func TestIsV1Template(t *testing.T) {
 		})
 	}
 }
+
+func TestIsV3Template(t *testing.T) {
+	cases := map[string]struct {
+		raw      string
+		expected bool
+	}{
+		"secret function": {
+			raw:      `{{ secret "path/to/secret" }}`,
+			expected: true,
+		},
+		"if": {
+			raw:      `{{ if eq (var "env") "prod" }}prod{{ end }}`,
+			expected: true,
+		},
+		"range with trimmed whitespace": {
+			raw:      `{{- range $name, $path := secrets "path/to/dir" }}{{ end }}`,
+			expected: true,
+		},
+		"include": {
+			raw:      `{{ include "database.tpl" }}`,
+			expected: true,
+		},
+		"v2 secret tag": {
+			raw:      "{{ path/to/secret }}",
+			expected: false,
+		},
+		"v2 secret tag of a secret named secret": {
+			raw:      "{{ secret }}",
+			expected: false,
+		},
+		"v2 secret tag with function": {
+			raw:      "{{ path/to/secret | upper }}",
+			expected: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual := IsV3Template([]byte(tc.raw))
+
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"unicode"
 
 	"github.com/secrethub/secrethub-cli/internals/secrethub/tpl/internal/token"
+
+	"github.com/secrethub/secrethub-go/internals/api"
 )
 
 // NewV2Parser returns a parser for the v2 template syntax.
@@ -20,6 +22,13 @@ import (
 // {{ ${app}/db/secret }}
 // Variables cannot be used outside of secret paths.
 //
+// The value of a secret can be passed through functions with a pipe:
+// {{ path/to/secret | trim | base64enc }}
+// The available functions are base64enc, base64dec, urlencode, jsonescape,
+// trim, replace <old> <new>, upper, lower, substr <start> <end>, sha256 and
+// default <value>. A secret with a default value does not have to exist:
+// {{ path/to/secret | default "fallback" }}
+//
 // Spaces directly after opening delimiters (`{{` and `${`) and directly
 // before closing delimiters (`}}`, `}`) are ignored. They are not
 // included in the secret pahts and variable names.
@@ -41,7 +50,8 @@ type node interface {
 }
 
 type secret struct {
-	path []node
+	path     []node
+	pipeline []call
 }
 
 func (s secret) evaluate(ctx context) (string, error) {
@@ -54,7 +64,48 @@ func (s secret) evaluate(ctx context) (string, error) {
 
 		buffer.WriteString(eval)
 	}
-	return ctx.secret(buffer.String())
+
+	value, err := ctx.secret(buffer.String())
+	if api.IsErrNotFound(err) && s.hasDefault() {
+		// A secret with a default value is optional.
+		value, err = "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	for _, c := range s.pipeline {
+		value, err = c.apply(value)
+		if err != nil {
+			return "", err
+		}
+	}
+	return value, nil
+}
+
+// hasDefault returns whether a default value is given for the secret.
+func (s secret) hasDefault() bool {
+	for _, c := range s.pipeline {
+		if c.name == "default" {
+			return true
+		}
+	}
+	return false
+}
+
+// call is a call of a function in a secret tag.
+type call struct {
+	name string
+	args []string
+}
+
+// apply applies the function to the given value.
+func (c call) apply(value string) (string, error) {
+	res, err := functions[c.name].apply(value, c.args)
+	if err != nil {
+		return "", ErrFunctionFailed(c.name, err)
+	}
+	return res, nil
 }
 
 type variable struct {
@@ -95,6 +146,9 @@ type parserV2 struct{}
 // - Variable tags cannot contain secret tags.
 // - Secret tags cannot contain secret tags (they cannot be nested).
 // - Variable tags cannot contain variable tags (they cannot be nested).
+// - The value of a secret tag can be piped into functions, which are applied from
+//   left to right: `{{ path/to/secret | replace "\n" "" | upper }}`. Arguments are
+//   separated by spaces and can be quoted with double quotes.
 func (p parserV2) Parse(raw string, line, column int) (Template, error) {
 	parser := newV2Parser(bytes.NewBufferString(raw), line, column)
 
@@ -360,6 +414,23 @@ func (p *v2Parser) parseSecret() (node, error) {
 				return nil, checkError(err)
 			}
 
+			if p.next == token.Pipe {
+				err = p.readRune()
+				if err != nil {
+					return nil, checkError(err)
+				}
+
+				pipeline, err := p.parsePipeline()
+				if err != nil {
+					return nil, checkError(err)
+				}
+
+				return secret{
+					path:     path,
+					pipeline: pipeline,
+				}, nil
+			}
+
 			if p.next != token.RBracket {
 				return nil, ErrUnexpectedCharacter(p.lineNo, p.columnNo+1, p.next, token.RBracket)
 			}
@@ -378,6 +449,18 @@ func (p *v2Parser) parseSecret() (node, error) {
 			}, nil
 		}
 
+		if p.current == token.Pipe {
+			pipeline, err := p.parsePipeline()
+			if err != nil {
+				return nil, checkError(err)
+			}
+
+			return secret{
+				path:     path,
+				pipeline: pipeline,
+			}, nil
+		}
+
 		if p.current == token.RBracket {
 			if p.next == token.RBracket {
 				return secret{
@@ -396,6 +479,148 @@ func (p *v2Parser) parseSecret() (node, error) {
 	}
 }
 
+// parsePipeline parses the functions that are applied to a secret, up to the closing
+// delimiter of the secret tag. The current character should be the pipe ('|') before
+// the first function when parsePipeline is called.
+//
+// When parsePipeline returns, the next character in the buffer is the last character
+// of the closing delimiter of the secret tag ('}').
+func (p *v2Parser) parsePipeline() ([]call, error) {
+	pipeline := []call{}
+
+	for {
+		err := p.skipWhiteSpace()
+		if err != nil {
+			return nil, err
+		}
+
+		lineNo, colNo := p.lineNo, p.columnNo+1
+
+		var name bytes.Buffer
+		for p.isFunctionRune(p.next) {
+			name.WriteRune(p.next)
+
+			err := p.readRune()
+			if err != nil {
+				return nil, err
+			}
+		}
+		if name.Len() == 0 {
+			return nil, ErrIllegalFunctionCharacter(p.lineNo, p.columnNo+1, p.next)
+		}
+
+		args := []string{}
+		for {
+			if !p.isAllowedWhiteSpace(p.next) && p.next != token.Pipe && p.next != token.RBracket {
+				return nil, ErrIllegalFunctionCharacter(p.lineNo, p.columnNo+1, p.next)
+			}
+
+			err := p.skipWhiteSpace()
+			if err != nil {
+				return nil, err
+			}
+
+			if p.next == token.Pipe || p.next == token.RBracket {
+				break
+			}
+
+			arg, err := p.parseArgument()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+		}
+
+		f, ok := functions[name.String()]
+		if !ok {
+			return nil, ErrUnknownFunction(lineNo, colNo, name.String())
+		}
+		if len(args) != f.args {
+			return nil, ErrWrongNumberOfArguments(lineNo, colNo, name.String(), f.args, len(args))
+		}
+
+		pipeline = append(pipeline, call{
+			name: name.String(),
+			args: args,
+		})
+
+		err = p.readRune()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.current == token.RBracket {
+			if p.next != token.RBracket {
+				return nil, ErrUnexpectedCharacter(p.lineNo, p.columnNo+1, p.next, token.RBracket)
+			}
+			return pipeline, nil
+		}
+	}
+}
+
+// parseArgument parses an argument of a function. An argument is either a word
+// without spaces or a string between double quotes. Within double quotes, \n and \t
+// are a newline and a tab and a backslash escapes any other character that follows
+// it: "a \"quoted\" string".
+//
+// When parseArgument returns, the next character in the buffer is the first
+// character after the argument.
+func (p *v2Parser) parseArgument() (string, error) {
+	var buffer bytes.Buffer
+
+	if p.next != token.Quote {
+		for !p.isAllowedWhiteSpace(p.next) && p.next != token.Pipe && p.next != token.RBracket {
+			buffer.WriteRune(p.next)
+
+			err := p.readRune()
+			if err != nil {
+				return "", err
+			}
+		}
+		return buffer.String(), nil
+	}
+
+	err := p.readRune()
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		err := p.readRune()
+		if err != nil {
+			return "", err
+		}
+
+		if p.current == token.Quote {
+			return buffer.String(), nil
+		}
+
+		if p.current == token.Backslash {
+			err := p.readRune()
+			if err != nil {
+				return "", err
+			}
+
+			switch p.current {
+			case 'n':
+				buffer.WriteRune('\n')
+			case 't':
+				buffer.WriteRune('\t')
+			default:
+				buffer.WriteRune(p.current)
+			}
+			continue
+		}
+
+		buffer.WriteRune(p.current)
+	}
+}
+
+// isFunctionRune returns whether the given rune is allowed to be used in a function name.
+func (p v2Parser) isFunctionRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
 // isSecretPathRune returns whether the given rune is allowed to be used in
 // a secret path.
 func (p v2Parser) isSecretPathRune(r rune) bool {
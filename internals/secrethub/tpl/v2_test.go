@@ -7,6 +7,7 @@ import (
 
 	"github.com/secrethub/secrethub-cli/internals/secrethub/tpl/fakes"
 
+	"github.com/secrethub/secrethub-go/internals/api"
 	"github.com/secrethub/secrethub-go/internals/assert"
 )
 
@@ -198,6 +199,59 @@ func TestParserV2_parse(t *testing.T) {
 				},
 			},
 		},
+		"secret with function": {
+			input: "{{ a | upper }}",
+			expected: []node{
+				secret{
+					path: []node{
+						character('a'),
+					},
+					pipeline: []call{
+						{name: "upper", args: []string{}},
+					},
+				},
+			},
+		},
+		"secret with functions without spaces": {
+			input: "{{a|trim|upper}}",
+			expected: []node{
+				secret{
+					path: []node{
+						character('a'),
+					},
+					pipeline: []call{
+						{name: "trim", args: []string{}},
+						{name: "upper", args: []string{}},
+					},
+				},
+			},
+		},
+		"function with arguments": {
+			input: "{{ a | replace \"x y\" z }}",
+			expected: []node{
+				secret{
+					path: []node{
+						character('a'),
+					},
+					pipeline: []call{
+						{name: "replace", args: []string{"x y", "z"}},
+					},
+				},
+			},
+		},
+		"function with escaped arguments": {
+			input: `{{ a | replace "\"\\" "\n" }}`,
+			expected: []node{
+				secret{
+					path: []node{
+						character('a'),
+					},
+					pipeline: []call{
+						{name: "replace", args: []string{"\"\\", "\n"}},
+					},
+				},
+			},
+		},
 		"secret path": {
 			input: "{{path/to/secret}}",
 			expected: []node{
@@ -552,6 +606,30 @@ func TestParserV2_parse(t *testing.T) {
 			input: "{{ path/with/${var@b} }}",
 			err:   ErrIllegalVariableCharacter(1, 19, '@'),
 		},
+		"unknown function": {
+			input: "{{ a | unknown }}",
+			err:   ErrUnknownFunction(1, 8, "unknown"),
+		},
+		"wrong number of arguments": {
+			input: "{{ a | replace \"x\" }}",
+			err:   ErrWrongNumberOfArguments(1, 8, "replace", 2, 1),
+		},
+		"missing function": {
+			input: "{{ a | }}",
+			err:   ErrIllegalFunctionCharacter(1, 8, '}'),
+		},
+		"argument without space": {
+			input: "{{ a | upper\"x\" }}",
+			err:   ErrIllegalFunctionCharacter(1, 13, '"'),
+		},
+		"secret tag with function not closed": {
+			input: "{{ a | upper }",
+			err:   ErrSecretTagNotClosed(1, 15),
+		},
+		"argument not closed": {
+			input: "{{ a | replace \"x }}",
+			err:   ErrSecretTagNotClosed(1, 21),
+		},
 		"error on new line": {
 			input: "{{ path/to/secret }}\n{{ a%b }}",
 			err:   ErrIllegalSecretCharacter(2, 5, '%'),
@@ -678,6 +756,63 @@ func TestV2(t *testing.T) {
 			},
 			evalErr: errors.New("variable not found: app"),
 		},
+		"function": {
+			raw: "hello {{ secret | upper }}",
+			secrets: map[string]string{
+				"secret": "world",
+			},
+			expected: "hello WORLD",
+		},
+		"multiple functions": {
+			raw: "token: {{ secret | trim | base64enc }}",
+			secrets: map[string]string{
+				"secret": "  hello\n",
+			},
+			expected: "token: aGVsbG8=",
+		},
+		"function on secret with template var": {
+			raw: "{{ ${app}/greeting | replace o 0 }}",
+			vars: map[string]string{
+				"app": "company/helloworld",
+			},
+			secrets: map[string]string{
+				"company/helloworld/greeting": "hello world",
+			},
+			expected: "hell0 w0rld",
+		},
+		"function fails": {
+			raw: "{{ secret | base64dec }}",
+			secrets: map[string]string{
+				"secret": "not base64",
+			},
+			evalErr: ErrFunctionFailed("base64dec", errors.New("the value is not valid base64")),
+		},
+		"missing secret": {
+			raw:     "hello {{ secret }}",
+			evalErr: api.ErrSecretNotFound,
+		},
+		"default for missing secret": {
+			raw:      "hello {{ secret | default \"world\" }}",
+			expected: "hello world",
+		},
+		"default for existing secret": {
+			raw: "hello {{ secret | default fallback }}",
+			secrets: map[string]string{
+				"secret": "world",
+			},
+			expected: "hello world",
+		},
+		"default for empty secret": {
+			raw: "hello {{ secret | trim | default world }}",
+			secrets: map[string]string{
+				"secret": " ",
+			},
+			expected: "hello world",
+		},
+		"unknown function": {
+			raw:      "{{ secret | unknown }}",
+			parseErr: ErrUnknownFunction(1, 13, "unknown"),
+		},
 	}
 
 	for name, tc := range cases {
@@ -0,0 +1,362 @@
+package tpl
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// NewV3Parser returns a parser for the v3 template syntax.
+//
+// V3 templates use the syntax of Go templates (https://golang.org/pkg/text/template),
+// in which secrets and variables are read with functions:
+// {{ secret "path/to/secret" }}
+// {{ var "app" }}
+//
+// Blocks can be rendered conditionally:
+// {{ if eq (var "env") "prod" }}...{{ else }}...{{ end }}
+//
+// The secrets function returns the paths of the secrets in a directory by their
+// names, so they can be iterated over:
+// {{ range $name, $path := secrets "path/to/dir" }}{{ $name }}={{ secret $path }}{{ end }}
+//
+// The functions that can be used in v2 secret tags are available as well:
+// {{ secret "path/to/secret" | trim | base64enc }}
+//
+// A secret that is given a default value does not have to exist. The optionalSecret
+// function returns an empty string for secrets that do not exist:
+// {{ secret "path/to/secret" | default "fallback" }}
+// {{ if optionalSecret "path/to/secret" }}...{{ end }}
+//
+// Other templates can be included with include. They are looked up in the given
+// include directories, in order:
+// {{ include "database.tpl" }}
+func NewV3Parser(includeDirs ...string) Parser {
+	return parserV3{
+		includeDirs: includeDirs,
+	}
+}
+
+// maxIncludeDepth is the maximum number of nested includes, which prevents
+// a template that includes itself from being included endlessly.
+const maxIncludeDepth = 10
+
+// SecretLister lists the secrets in a directory.
+// A SecretReader that also implements SecretLister can be used to iterate over directories.
+type SecretLister interface {
+	// ListSecrets returns the paths of the secrets directly in the given directory.
+	ListSecrets(dirPath string) ([]string, error)
+}
+
+// ListSecrets lists the secrets in the directory with the secret reader,
+// if the secret reader implements SecretLister.
+func ListSecrets(sr SecretReader, dirPath string) ([]string, error) {
+	lister, ok := sr.(SecretLister)
+	if !ok {
+		return nil, ErrListSecretsNotSupported
+	}
+	return lister.ListSecrets(dirPath)
+}
+
+type parserV3 struct {
+	includeDirs []string
+}
+
+type templateV3 struct {
+	template    *template.Template
+	includeDirs []string
+}
+
+// Parse parses a v3 secret template from a raw string.
+func (p parserV3) Parse(raw string, _, _ int) (Template, error) {
+	t, err := template.New("template").Funcs(newV3Evaluation(context{}, nil).functions()).Parse(raw)
+	if err != nil {
+		return nil, ErrInvalidTemplate(err)
+	}
+	markOptionalSecrets(t)
+
+	return templateV3{
+		template:    t,
+		includeDirs: p.includeDirs,
+	}, nil
+}
+
+// Evaluate renders a template. It replaces all variable- and secret functions in the template.
+func (t templateV3) Evaluate(varReader VariableReader, sr SecretReader) (string, error) {
+	eval := newV3Evaluation(context{
+		varReader:    varReader,
+		secretReader: sr,
+	}, t.includeDirs)
+
+	// The template is cloned, so the functions can be bound to the readers
+	// without affecting other evaluations of the same template.
+	clone, err := t.template.Clone()
+	if err != nil {
+		return "", err
+	}
+
+	var buffer bytes.Buffer
+	err = clone.Funcs(eval.functions()).Execute(&buffer, nil)
+	if eval.err != nil {
+		return "", eval.err
+	}
+	if err != nil {
+		return "", ErrInvalidTemplate(err)
+	}
+
+	return buffer.String(), nil
+}
+
+// ContainsSecrets returns whether the template reads any secrets.
+// Templates that include other templates are assumed to read secrets.
+func (t templateV3) ContainsSecrets() bool {
+	contains := false
+	for _, tmpl := range t.template.Templates() {
+		if tmpl.Tree == nil {
+			continue
+		}
+		walk(tmpl.Tree.Root, func(node parse.Node) {
+			identifier, ok := node.(*parse.IdentifierNode)
+			if ok && secretFunctions[identifier.Ident] {
+				contains = true
+			}
+		})
+	}
+	return contains
+}
+
+// secretFunctions are the functions that read secrets.
+var secretFunctions = map[string]bool{
+	"secret":         true,
+	"optionalSecret": true,
+	"secrets":        true,
+	"include":        true,
+}
+
+// markOptionalSecrets replaces the secret function by the optionalSecret function in pipelines
+// that give the secret a default value, e.g. {{ secret "path/to/secret" | default "fallback" }},
+// so that these secrets do not have to exist.
+func markOptionalSecrets(t *template.Template) {
+	for _, tmpl := range t.Templates() {
+		if tmpl.Tree == nil {
+			continue
+		}
+		walk(tmpl.Tree.Root, func(node parse.Node) {
+			pipe, ok := node.(*parse.PipeNode)
+			if !ok || len(pipe.Cmds) < 2 {
+				return
+			}
+			secret, ok := pipe.Cmds[0].Args[0].(*parse.IdentifierNode)
+			if !ok || secret.Ident != "secret" {
+				return
+			}
+			for _, cmd := range pipe.Cmds[1:] {
+				identifier, ok := cmd.Args[0].(*parse.IdentifierNode)
+				if ok && identifier.Ident == "default" {
+					secret.Ident = "optionalSecret"
+					return
+				}
+			}
+		})
+	}
+}
+
+// walk calls fn for the node and all nodes within it.
+func walk(node parse.Node, fn func(parse.Node)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walk(child, fn)
+		}
+		return
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		fn(n)
+		for _, cmd := range n.Cmds {
+			walk(cmd, fn)
+		}
+		return
+	}
+
+	fn(node)
+	switch n := node.(type) {
+	case *parse.ActionNode:
+		walk(n.Pipe, fn)
+	case *parse.IfNode:
+		walk(n.Pipe, fn)
+		walk(n.List, fn)
+		walk(n.ElseList, fn)
+	case *parse.RangeNode:
+		walk(n.Pipe, fn)
+		walk(n.List, fn)
+		walk(n.ElseList, fn)
+	case *parse.WithNode:
+		walk(n.Pipe, fn)
+		walk(n.List, fn)
+		walk(n.ElseList, fn)
+	case *parse.TemplateNode:
+		walk(n.Pipe, fn)
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walk(arg, fn)
+		}
+	case *parse.ChainNode:
+		walk(n.Node, fn)
+	}
+}
+
+// v3Evaluation keeps track of the evaluation of a v3 template.
+type v3Evaluation struct {
+	ctx         context
+	includeDirs []string
+	// depth is the number of includes the evaluated template is nested in.
+	depth int
+	// err is the first error returned by a function, which is returned
+	// as is instead of the error of the template execution wrapping it.
+	err error
+}
+
+func newV3Evaluation(ctx context, includeDirs []string) *v3Evaluation {
+	return &v3Evaluation{
+		ctx:         ctx,
+		includeDirs: includeDirs,
+	}
+}
+
+// fail records the error when it is the first error of the evaluation.
+func (e *v3Evaluation) fail(err error) error {
+	if err != nil && e.err == nil {
+		e.err = err
+	}
+	return err
+}
+
+// functions returns the functions that can be used in the template.
+func (e *v3Evaluation) functions() template.FuncMap {
+	funcs := template.FuncMap{
+		"secret": func(path string) (string, error) {
+			secret, err := e.ctx.secret(path)
+			return secret, e.fail(err)
+		},
+		"optionalSecret": func(path string) (string, error) {
+			secret, err := e.ctx.secret(path)
+			if api.IsErrNotFound(err) {
+				return "", nil
+			}
+			return secret, e.fail(err)
+		},
+		"secrets": func(dirPath string) (map[string]string, error) {
+			secrets, err := e.secrets(dirPath)
+			return secrets, e.fail(err)
+		},
+		"var": func(name string) (string, error) {
+			value, err := e.ctx.varReader.ReadVariable(strings.ToLower(name))
+			return value, e.fail(err)
+		},
+		"include": func(name string) (string, error) {
+			res, err := e.include(name)
+			return res, e.fail(err)
+		},
+	}
+
+	for name, f := range functions {
+		funcs[name] = e.pipelineFunction(name, f)
+	}
+	return funcs
+}
+
+// secrets returns the paths of the secrets in the directory by their names.
+func (e *v3Evaluation) secrets(dirPath string) (map[string]string, error) {
+	paths, err := ListSecrets(e.ctx.secretReader, dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := make(map[string]string, len(paths))
+	for _, p := range paths {
+		secrets[path.Base(p)] = p
+	}
+	return secrets, nil
+}
+
+// include evaluates the template with the given name in the first include directory containing it.
+// The included template can use the same functions, variables and secrets as the including template.
+func (e *v3Evaluation) include(name string) (string, error) {
+	if e.depth >= maxIncludeDepth {
+		return "", ErrIncludeTooDeep(name, maxIncludeDepth)
+	}
+
+	raw, err := readInclude(e.includeDirs, name)
+	if err != nil {
+		return "", err
+	}
+
+	included := &v3Evaluation{
+		ctx:         e.ctx,
+		includeDirs: e.includeDirs,
+		depth:       e.depth + 1,
+	}
+
+	t, err := template.New(name).Funcs(included.functions()).Parse(string(raw))
+	if err != nil {
+		return "", ErrInvalidInclude(name, err)
+	}
+	markOptionalSecrets(t)
+
+	var buffer bytes.Buffer
+	err = t.Execute(&buffer, nil)
+	if included.err != nil {
+		return "", included.err
+	}
+	if err != nil {
+		return "", ErrInvalidInclude(name, err)
+	}
+	return buffer.String(), nil
+}
+
+// readInclude reads the template with the given name from the first directory that contains it.
+func readInclude(dirs []string, name string) ([]byte, error) {
+	if len(dirs) == 0 {
+		return nil, ErrIncludeNotSupported
+	}
+
+	for _, dir := range dirs {
+		raw, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, ErrInvalidInclude(name, err)
+		}
+		return raw, nil
+	}
+	return nil, ErrIncludeNotFound(name, strings.Join(dirs, ", "))
+}
+
+// pipelineFunction converts a function to a template function. The value a function is
+// applied to is piped into it, so in a template function it is the last argument.
+func (e *v3Evaluation) pipelineFunction(name string, f function) func(args ...string) (string, error) {
+	return func(args ...string) (string, error) {
+		if len(args) != f.args+1 {
+			return "", e.fail(ErrFunctionArguments(name, f.args+1, len(args)))
+		}
+
+		res, err := f.apply(args[len(args)-1], args[:len(args)-1])
+		if err != nil {
+			return "", e.fail(ErrFunctionFailed(name, err))
+		}
+		return res, nil
+	}
+}
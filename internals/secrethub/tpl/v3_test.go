@@ -0,0 +1,265 @@
+package tpl
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/secrethub/tpl/fakes"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestV3(t *testing.T) {
+	cases := map[string]struct {
+		raw     string
+		vars    map[string]string
+		secrets map[string]string
+
+		expected       string
+		containsSecret bool
+		evalErr        error
+	}{
+		"no secrets": {
+			raw:      "hello world",
+			expected: "hello world",
+		},
+		"secret": {
+			raw: `hello {{ secret "company/app/greeting" }}`,
+			secrets: map[string]string{
+				"company/app/greeting": "world",
+			},
+			expected:       "hello world",
+			containsSecret: true,
+		},
+		"template var in secret path": {
+			raw: `hello {{ secret (printf "%s/greeting" (var "app")) }}`,
+			vars: map[string]string{
+				"app": "company/app",
+			},
+			secrets: map[string]string{
+				"company/app/greeting": "world",
+			},
+			expected:       "hello world",
+			containsSecret: true,
+		},
+		"if": {
+			raw: `{{ if eq (var "env") "prod" }}{{ secret "company/app/prod/db" }}{{ else }}localhost{{ end }}`,
+			vars: map[string]string{
+				"env": "prod",
+			},
+			secrets: map[string]string{
+				"company/app/prod/db": "db.example.com",
+			},
+			expected:       "db.example.com",
+			containsSecret: true,
+		},
+		"else": {
+			raw: `{{ if eq (var "env") "prod" }}{{ secret "company/app/prod/db" }}{{ else }}localhost{{ end }}`,
+			vars: map[string]string{
+				"env": "dev",
+			},
+			expected:       "localhost",
+			containsSecret: true,
+		},
+		"range over directory": {
+			raw: `{{ range $name, $path := secrets "company/app/db" }}{{ $name | upper }}={{ secret $path }}
+{{ end }}`,
+			secrets: map[string]string{
+				"company/app/db/user":        "app",
+				"company/app/db/password":    "secret",
+				"company/app/db/replica/url": "replica.example.com",
+			},
+			expected:       "PASSWORD=secret\nUSER=app\n",
+			containsSecret: true,
+		},
+		"functions": {
+			raw: `{{ secret "company/app/key" | trim | replace "a" "b" | base64enc }}`,
+			secrets: map[string]string{
+				"company/app/key": " aaa\n",
+			},
+			expected:       "YmJi",
+			containsSecret: true,
+		},
+		"missing var": {
+			raw:     `{{ var "app" }}`,
+			vars:    map[string]string{},
+			evalErr: errors.New("variable not found: app"),
+		},
+		"missing secret": {
+			raw:            `{{ secret "company/app/missing" }}`,
+			evalErr:        api.ErrSecretNotFound,
+			containsSecret: true,
+		},
+		"default for missing secret": {
+			raw:            `{{ secret "company/app/missing" | default "fallback" }}`,
+			expected:       "fallback",
+			containsSecret: true,
+		},
+		"default for existing secret": {
+			raw: `{{ secret "company/app/key" | trim | default "fallback" }}`,
+			secrets: map[string]string{
+				"company/app/key": "value\n",
+			},
+			expected:       "value",
+			containsSecret: true,
+		},
+		"default does not apply to other secrets": {
+			raw:            `{{ secret "company/app/missing" }}{{ secret "company/app/other" | default "fallback" }}`,
+			evalErr:        api.ErrSecretNotFound,
+			containsSecret: true,
+		},
+		"optional secret": {
+			raw:            `{{ if optionalSecret "company/app/missing" }}exists{{ else }}missing{{ end }}`,
+			expected:       "missing",
+			containsSecret: true,
+		},
+		"function fails": {
+			raw: `{{ secret "company/app/key" | base64dec }}`,
+			secrets: map[string]string{
+				"company/app/key": "not base64",
+			},
+			evalErr:        ErrFunctionFailed("base64dec", errors.New("the value is not valid base64")),
+			containsSecret: true,
+		},
+		"wrong number of arguments": {
+			raw: `{{ secret "company/app/key" | replace "a" }}`,
+			secrets: map[string]string{
+				"company/app/key": "value",
+			},
+			evalErr:        ErrFunctionArguments("replace", 3, 2),
+			containsSecret: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			parsed, err := NewV3Parser().Parse(tc.raw, 1, 1)
+			assert.OK(t, err)
+
+			assert.Equal(t, parsed.ContainsSecrets(), tc.containsSecret)
+
+			actual, err := parsed.Evaluate(fakes.FakeVariableReader{Variables: tc.vars}, fakes.FakeSecretReader{Secrets: tc.secrets})
+			assert.Equal(t, err, tc.evalErr)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
+
+func TestV3_ParseError(t *testing.T) {
+	_, err := NewV3Parser().Parse(`{{ if true }}unclosed`, 1, 1)
+	assert.Equal(t, err != nil, true)
+
+	_, err = NewV3Parser().Parse(`{{ unknown "x" }}`, 1, 1)
+	assert.Equal(t, err != nil, true)
+}
+
+func TestListSecrets_NotSupported(t *testing.T) {
+	parsed, err := NewV3Parser().Parse(`{{ range secrets "company/app" }}{{ end }}`, 1, 1)
+	assert.OK(t, err)
+
+	_, err = parsed.Evaluate(fakes.FakeVariableReader{}, secretReaderWithoutLister{})
+	assert.Equal(t, err, ErrListSecretsNotSupported)
+}
+
+type secretReaderWithoutLister struct{}
+
+func (secretReaderWithoutLister) ReadSecret(path string) (string, error) {
+	return "", nil
+}
+
+func TestV3_Include(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrethub-tpl-")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	shared := filepath.Join(dir, "shared")
+	err = os.Mkdir(shared, 0700)
+	assert.OK(t, err)
+
+	files := map[string]string{
+		"shared/database.tpl": `host={{ var "host" }} password={{ secret "company/app/db/password" }}`,
+		"shared/nested.tpl":   `[{{ include "database.tpl" }}]`,
+		"shared/self.tpl":     `{{ include "self.tpl" }}`,
+		"local.tpl":           `local`,
+		"shared/invalid.tpl":  `{{ if }}`,
+	}
+	for name, content := range files {
+		err = ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600)
+		assert.OK(t, err)
+	}
+
+	cases := map[string]struct {
+		raw         string
+		includeDirs []string
+		expected    string
+		err         error
+	}{
+		"include": {
+			raw:         `db: {{ include "database.tpl" }}`,
+			includeDirs: []string{shared},
+			expected:    "db: host=localhost password=secret",
+		},
+		"nested include": {
+			raw:         `{{ include "nested.tpl" }}`,
+			includeDirs: []string{shared},
+			expected:    "[host=localhost password=secret]",
+		},
+		"search multiple directories": {
+			raw:         `{{ include "local.tpl" }} {{ include "database.tpl" }}`,
+			includeDirs: []string{shared, dir},
+			expected:    "local host=localhost password=secret",
+		},
+		"include in subdirectory": {
+			raw:         `{{ include "shared/database.tpl" }}`,
+			includeDirs: []string{dir},
+			expected:    "host=localhost password=secret",
+		},
+		"include functions": {
+			raw:         `{{ include "local.tpl" | upper }}`,
+			includeDirs: []string{dir},
+			expected:    "LOCAL",
+		},
+		"not found": {
+			raw:         `{{ include "database.tpl" }}`,
+			includeDirs: []string{dir},
+			err:         ErrIncludeNotFound("database.tpl", dir),
+		},
+		"no include directories": {
+			raw: `{{ include "database.tpl" }}`,
+			err: ErrIncludeNotSupported,
+		},
+		"includes itself": {
+			raw:         `{{ include "self.tpl" }}`,
+			includeDirs: []string{shared},
+			err:         ErrIncludeTooDeep("self.tpl", maxIncludeDepth),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			parsed, err := NewV3Parser(tc.includeDirs...).Parse(tc.raw, 1, 1)
+			assert.OK(t, err)
+
+			assert.Equal(t, parsed.ContainsSecrets(), true)
+
+			actual, err := parsed.Evaluate(
+				fakes.FakeVariableReader{Variables: map[string]string{"host": "localhost"}},
+				fakes.FakeSecretReader{Secrets: map[string]string{"company/app/db/password": "secret"}},
+			)
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+
+	t.Run("invalid include", func(t *testing.T) {
+		parsed, err := NewV3Parser(shared).Parse(`{{ include "invalid.tpl" }}`, 1, 1)
+		assert.OK(t, err)
+
+		_, err = parsed.Evaluate(fakes.FakeVariableReader{}, fakes.FakeSecretReader{})
+		assert.Equal(t, err != nil, true)
+	})
+}
@@ -0,0 +1,230 @@
+package secrethub
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+)
+
+const (
+	// trashDirName is the directory in a repository that holds the secrets removed with rm.
+	// Every removal gets its own subdirectory, named after the time of removal,
+	// in which the removed secrets keep their path relative to the repository.
+	trashDirName = ".trash"
+	// trashTimeFormat is the format of the names of the removal directories in the trash.
+	trashTimeFormat = "20060102T150405Z"
+)
+
+// TrashCommand handles the secrets that are removed, but can still be restored.
+type TrashCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+}
+
+// NewTrashCommand creates a new TrashCommand.
+func NewTrashCommand(io ui.IO, newClient newClientFunc) *TrashCommand {
+	return &TrashCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command and its sub-commands on the provided Registerer.
+func (cmd *TrashCommand) Register(r command.Registerer) {
+	clause := r.Command("trash", "Manage the removed secrets of a repository.")
+	clause.HelpLong("Secrets and directories removed with `" + ApplicationName + " rm` are moved to the " + trashDirName + " directory of their repository, " +
+		"including all versions of the secrets. From there, they can be restored with `" + ApplicationName + " restore` " +
+		"until the trash is emptied.")
+	NewTrashLsCommand(cmd.io, cmd.newClient).Register(clause)
+	NewTrashEmptyCommand(cmd.io, cmd.newClient).Register(clause)
+}
+
+// trashRemoval is a removal of a secret or directory, with the secrets that were removed.
+type trashRemoval struct {
+	// path is the path of the directory in the trash that holds the removed secrets.
+	path      string
+	removedAt time.Time
+	secrets   []trashedSecret
+}
+
+// trashedSecret is a removed secret.
+type trashedSecret struct {
+	// path is the path of the secret before it was removed.
+	path string
+	// trashPath is the path of the secret in the trash.
+	trashPath string
+}
+
+// splitRepoPath splits a path into the path of its repository and the rest of the path, which is empty for a repository.
+func splitRepoPath(path string) (string, string) {
+	elements := strings.SplitN(path, "/", 3)
+	if len(elements) < 3 {
+		return path, ""
+	}
+	return elements[0] + "/" + elements[1], elements[2]
+}
+
+// trashPath returns the path in the trash for a secret or directory that is removed at the given time.
+func trashPath(path string, removedAt time.Time) string {
+	repo, rel := splitRepoPath(path)
+	return api.JoinPaths(repo, trashDirName, removedAt.UTC().Format(trashTimeFormat), rel)
+}
+
+// isInTrash returns whether the path is the trash of a repository or inside of it.
+func isInTrash(path string) bool {
+	repo, _ := splitRepoPath(path)
+	return isSubPath(path, api.JoinPaths(repo, trashDirName))
+}
+
+// excludeTrash removes the trash and everything in it from the tree of the directory at the path,
+// so that commands that walk a repository do not act on removed secrets.
+// Only the tree of the root directory of a repository can contain the trash.
+func excludeTrash(tree *api.Tree, dirPath string) {
+	if _, rel := splitRepoPath(dirPath); rel != "" || tree.RootDir == nil {
+		return
+	}
+
+	for i, dir := range tree.RootDir.SubDirs {
+		if !strings.EqualFold(dir.Name, trashDirName) {
+			continue
+		}
+		tree.RootDir.SubDirs = append(tree.RootDir.SubDirs[:i:i], tree.RootDir.SubDirs[i+1:]...)
+
+		removed := map[uuid.UUID]bool{}
+		var remove func(dir *api.Dir)
+		remove = func(dir *api.Dir) {
+			removed[dir.DirID] = true
+			delete(tree.Dirs, dir.DirID)
+			for _, subDir := range dir.SubDirs {
+				remove(subDir)
+			}
+		}
+		remove(dir)
+		for id, secret := range tree.Secrets {
+			if removed[secret.DirID] {
+				delete(tree.Secrets, id)
+			}
+		}
+		return
+	}
+}
+
+// moveToTrash moves a secret, or the directory when dir is set, to the trash of its repository.
+// The access rules on a directory and its subdirectories are kept on the directories in the trash,
+// so that they can be restored with it.
+func moveToTrash(client secrethub.ClientInterface, path string, dir *api.Dir, removedAt time.Time) error {
+	target := trashPath(path, removedAt)
+
+	var plan *copyPlan
+	var err error
+	if dir != nil {
+		plan, err = planDirTransfer(dir, path, target)
+	} else {
+		plan, err = planSecretTransfer(path, target)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = plan.execute(client, false)
+	if err != nil {
+		return err
+	}
+
+	if dir != nil {
+		err = copyACLRules(client, path, target)
+		if err != nil {
+			repo, _ := splitRepoPath(path)
+			_ = client.Dirs().Delete(trashPath(repo, removedAt))
+			return err
+		}
+
+		err = client.Dirs().Delete(path)
+	} else {
+		err = client.Secrets().Delete(path)
+	}
+	return err
+}
+
+// copyACLRules sets the access rules on the directory at the source path and its subdirectories
+// on the directory at the destination path and its corresponding subdirectories.
+func copyACLRules(client secrethub.ClientInterface, source, destination string) error {
+	rules, err := client.AccessRules().List(source, -1, false)
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	tree, err := client.Dirs().GetTree(source, -1, false)
+	if err != nil {
+		return err
+	}
+
+	targets := map[uuid.UUID]string{}
+	var walk func(dir *api.Dir, target string)
+	walk = func(dir *api.Dir, target string) {
+		targets[dir.DirID] = target
+		for _, subDir := range dir.SubDirs {
+			walk(subDir, target+"/"+subDir.Name)
+		}
+	}
+	walk(tree.RootDir, destination)
+
+	for _, rule := range rules {
+		target, ok := targets[rule.DirID]
+		if !ok {
+			return api.ErrDirNotFound
+		}
+		_, err = client.AccessRules().Set(target, rule.Permission.String(), rule.Account.Name.Value())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listTrash returns the removals in the trash of a repository, the most recent first.
+// Directories in the trash that were not created by rm are ignored.
+func listTrash(client secrethub.ClientInterface, repoPath string) ([]trashRemoval, error) {
+	trashDir := api.JoinPaths(repoPath, trashDirName)
+	tree, err := client.Dirs().GetTree(trashDir, -1, false)
+	if api.IsErrNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var removals []trashRemoval
+	for _, dir := range tree.RootDir.SubDirs {
+		removedAt, err := time.Parse(trashTimeFormat, dir.Name)
+		if err != nil {
+			continue
+		}
+
+		removal := trashRemoval{
+			path:      api.JoinPaths(trashDir, dir.Name),
+			removedAt: removedAt,
+		}
+		for _, secretPath := range secretPathsInDir(dir, removal.path) {
+			removal.secrets = append(removal.secrets, trashedSecret{
+				path:      repoPath + strings.TrimPrefix(secretPath, removal.path),
+				trashPath: secretPath,
+			})
+		}
+		removals = append(removals, removal)
+	}
+
+	sort.Slice(removals, func(i, j int) bool {
+		return removals[i].removedAt.After(removals[j].removedAt)
+	})
+	return removals, nil
+}
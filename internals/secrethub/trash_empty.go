@@ -0,0 +1,101 @@
+package secrethub
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// TrashEmptyCommand permanently removes the secrets in the trash of a repository.
+type TrashEmptyCommand struct {
+	io        ui.IO
+	path      api.RepoPath
+	olderThan dayDuration
+	force     bool
+	now       func() time.Time
+	newClient newClientFunc
+}
+
+// NewTrashEmptyCommand creates a new TrashEmptyCommand.
+func NewTrashEmptyCommand(io ui.IO, newClient newClientFunc) *TrashEmptyCommand {
+	return &TrashEmptyCommand{
+		io:        io,
+		now:       time.Now,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *TrashEmptyCommand) Register(r command.Registerer) {
+	clause := r.Command("empty", "Permanently remove the secrets in the trash of a repository.")
+	clause.HelpLong("Permanently removes the secrets in the trash of a repository, so they can no longer be restored. " +
+		"Use --older-than to only remove the secrets that were removed longer ago, " +
+		"e.g. to keep a restore window of 30 days with `" + ApplicationName + " trash empty <namespace>/<repo> --older-than 30d`.")
+	clause.Arg("repo-path", "The path to the repository.").Required().PlaceHolder(repoPathPlaceHolder).SetValue(&cmd.path)
+	clause.Flag("older-than", "Only remove the secrets that were removed longer ago than this period, e.g. 30d.").SetValue(&cmd.olderThan)
+	registerForceFlag(clause).BoolVar(&cmd.force)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run permanently removes the secrets in the trash.
+func (cmd *TrashEmptyCommand) Run() error {
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	removals, err := listTrash(client, cmd.path.Value())
+	if err != nil {
+		return err
+	}
+
+	if cmd.olderThan > 0 {
+		before := cmd.now().Add(-time.Duration(cmd.olderThan))
+		old := removals[:0]
+		for _, removal := range removals {
+			if removal.removedAt.Before(before) {
+				old = append(old, removal)
+			}
+		}
+		removals = old
+	}
+
+	count := 0
+	for _, removal := range removals {
+		count += len(removal.secrets)
+	}
+	if len(removals) == 0 {
+		fmt.Fprintln(cmd.io.Output(), "There are no secrets to remove from the trash.")
+		return nil
+	}
+
+	ok, err := askRmConfirmation(
+		cmd.io,
+		fmt.Sprintf("This will permanently remove %s from the trash of %s. "+
+			"Please type in the name of the repository to confirm", pluralize("secret", "secrets", count), cmd.path),
+		cmd.force,
+		cmd.path.GetRepo(),
+		cmd.path.String(),
+	)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	for _, removal := range removals {
+		err = client.Dirs().Delete(removal.path)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Permanently removed %s from the trash.\n", pluralize("secret", "secrets", count))
+	return nil
+}
@@ -0,0 +1,71 @@
+package secrethub
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// TrashLsCommand lists the removed secrets of a repository.
+type TrashLsCommand struct {
+	io            ui.IO
+	path          api.RepoPath
+	useTimestamps bool
+	newClient     newClientFunc
+}
+
+// NewTrashLsCommand creates a new TrashLsCommand.
+func NewTrashLsCommand(io ui.IO, newClient newClientFunc) *TrashLsCommand {
+	return &TrashLsCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *TrashLsCommand) Register(r command.Registerer) {
+	clause := r.Command("ls", "List the removed secrets of a repository.")
+	clause.Alias("list")
+	clause.Arg("repo-path", "The path to the repository.").Required().PlaceHolder(repoPathPlaceHolder).SetValue(&cmd.path)
+	registerTimestampFlag(clause).BoolVar(&cmd.useTimestamps)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run lists the removed secrets, the most recently removed first.
+func (cmd *TrashLsCommand) Run() error {
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	removals, err := listTrash(client, cmd.path.Value())
+	if err != nil {
+		return err
+	}
+
+	if len(removals) == 0 {
+		fmt.Fprintf(cmd.io.Output(), "The trash of %s is empty.\n", cmd.path)
+		return nil
+	}
+
+	return printTrash(cmd.io.Output(), removals, cmd.useTimestamps)
+}
+
+// printTrash writes a table with the original paths of the removed secrets and when they were removed.
+func printTrash(w io.Writer, removals []trashRemoval, timestamps bool) error {
+	timeFormatter := NewTimeFormatter(timestamps)
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\t%s\n", "PATH", "REMOVED")
+	for _, removal := range removals {
+		for _, secret := range removal.secrets {
+			fmt.Fprintf(tw, "%s\t%s\n", secret.path, timeFormatter.Format(removal.removedAt.Local()))
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,324 @@
+package secrethub
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+)
+
+func TestTrashPath(t *testing.T) {
+	removedAt := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, trashPath("company/app/db/password", removedAt), "company/app/.trash/20200601T120000Z/db/password")
+	assert.Equal(t, trashPath("company/app/db", removedAt), "company/app/.trash/20200601T120000Z/db")
+}
+
+func TestIsInTrash(t *testing.T) {
+	cases := map[string]bool{
+		"company/app/.trash":                           true,
+		"company/app/.trash/20200601T120000Z/password": true,
+		"company/app/.Trash/20200601T120000Z":          true,
+		"company/app/db/.trash":                        false,
+		"company/app/.trashcan":                        false,
+		"company/app":                                  false,
+	}
+
+	for path, expected := range cases {
+		t.Run(path, func(t *testing.T) {
+			assert.Equal(t, isInTrash(path), expected)
+		})
+	}
+}
+
+func TestPlanRestore(t *testing.T) {
+	removals := []trashRemoval{
+		{
+			path:      "company/app/.trash/20200601T120000Z",
+			removedAt: time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC),
+			secrets: []trashedSecret{
+				{path: "company/app/db/password", trashPath: "company/app/.trash/20200601T120000Z/db/password"},
+			},
+		},
+		{
+			path:      "company/app/.trash/20200501T120000Z",
+			removedAt: time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC),
+			secrets: []trashedSecret{
+				{path: "company/app/db/password", trashPath: "company/app/.trash/20200501T120000Z/db/password"},
+				{path: "company/app/db/user", trashPath: "company/app/.trash/20200501T120000Z/db/user"},
+				{path: "company/app/key", trashPath: "company/app/.trash/20200501T120000Z/key"},
+			},
+		},
+	}
+
+	cases := map[string]struct {
+		path            string
+		expectedRemoval string
+		expected        *copyPlan
+	}{
+		"most recent removal": {
+			path:            "company/app/db/password",
+			expectedRemoval: "company/app/.trash/20200601T120000Z",
+			expected: &copyPlan{
+				dirs: []string{"company/app/db"},
+				transfers: []secretTransfer{
+					{source: "company/app/.trash/20200601T120000Z/db/password", destination: "company/app/db/password"},
+				},
+			},
+		},
+		"directory": {
+			path:            "company/app/db",
+			expectedRemoval: "company/app/.trash/20200601T120000Z",
+			expected: &copyPlan{
+				dirs: []string{"company/app/db"},
+				transfers: []secretTransfer{
+					{source: "company/app/.trash/20200601T120000Z/db/password", destination: "company/app/db/password"},
+				},
+			},
+		},
+		"older removal": {
+			path:            "company/app/key",
+			expectedRemoval: "company/app/.trash/20200501T120000Z",
+			expected: &copyPlan{
+				transfers: []secretTransfer{
+					{source: "company/app/.trash/20200501T120000Z/key", destination: "company/app/key"},
+				},
+			},
+		},
+		"not in trash": {
+			path: "company/app/token",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			plan, removal := planRestore(removals, tc.path)
+			assert.Equal(t, plan, tc.expected)
+			if tc.expected != nil {
+				assert.Equal(t, removal.path, tc.expectedRemoval)
+			}
+		})
+	}
+}
+
+func TestRmCommand_Run_Trash(t *testing.T) {
+	removedAt := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		path            string
+		recursive       bool
+		permanent       bool
+		expectedSecrets map[string][]string
+		expectedRules   map[string]map[string]api.Permission
+	}{
+		"secret": {
+			path: "company/app/db/password",
+			expectedSecrets: map[string][]string{
+				"company/app/.trash/20200601T120000Z/db/password": {"v1", "v2", "v3"},
+				"company/app/db/user":                             {"app"},
+				"company/app/api/key":                             {"k1", "k2"},
+			},
+		},
+		"directory": {
+			path:      "company/app/db",
+			recursive: true,
+			expectedSecrets: map[string][]string{
+				"company/app/.trash/20200601T120000Z/db/password": {"v1", "v2", "v3"},
+				"company/app/.trash/20200601T120000Z/db/user":     {"app"},
+				"company/app/api/key":                             {"k1", "k2"},
+			},
+			expectedRules: map[string]map[string]api.Permission{
+				"company/app/.trash/20200601T120000Z/db": {"dev1": api.PermissionRead},
+			},
+		},
+		"permanent": {
+			path:      "company/app/db/password",
+			permanent: true,
+			expectedSecrets: map[string][]string{
+				"company/app/db/user": {"app"},
+				"company/app/api/key": {"k1", "k2"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			store := newFakeSecretStore()
+			store.rules["company/app/db"] = map[string]api.Permission{"dev1": api.PermissionRead}
+			io := fakeui.NewIO(t)
+			cmd := RmCommand{
+				io:        io,
+				path:      api.Path(tc.path),
+				recursive: tc.recursive,
+				permanent: tc.permanent,
+				force:     true,
+				now: func() time.Time {
+					return removedAt
+				},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return store.client(), nil
+				},
+			}
+
+			err := cmd.Run()
+			assert.OK(t, err)
+			assert.Equal(t, store.secrets, tc.expectedSecrets)
+			if tc.expectedRules != nil {
+				assert.Equal(t, store.rules, tc.expectedRules)
+			}
+		})
+	}
+}
+
+func TestRmCommand_Run_TrashConfirmation(t *testing.T) {
+	// Setup
+	store := newFakeSecretStore()
+	io := fakeui.NewIO(t)
+	io.PromptIn.Buffer = bytes.NewBufferString("user\n")
+	cmd := RmCommand{
+		io:   io,
+		path: "company/app/db/password",
+		now:  time.Now,
+		newClient: func() (secrethub.ClientInterface, error) {
+			return store.client(), nil
+		},
+	}
+
+	// Act
+	err := cmd.Run()
+
+	// Assert
+	assert.OK(t, err)
+	assert.Equal(t, store.secrets["company/app/db/password"], []string{"v1", "v2", "v3"})
+	assert.Equal(t, io.Out.String(), "Name does not match. Aborting.\n")
+}
+
+func TestRestoreCommand_Run(t *testing.T) {
+	store := newFakeSecretStore()
+	store.dirs["company/app/.trash"] = true
+	store.dirs["company/app/.trash/20200601T120000Z"] = true
+	store.dirs["company/app/.trash/20200601T120000Z/db"] = true
+	store.secrets["company/app/.trash/20200601T120000Z/db/token"] = []string{"t1", "t2"}
+	store.secrets["company/app/.trash/20200601T120000Z/db/host"] = []string{"localhost"}
+	store.rules["company/app/.trash/20200601T120000Z/db"] = map[string]api.Permission{"dev1": api.PermissionRead}
+
+	io := fakeui.NewIO(t)
+	cmd := RestoreCommand{
+		io:   io,
+		path: "company/app/db/token",
+		newClient: func() (secrethub.ClientInterface, error) {
+			return store.client(), nil
+		},
+	}
+
+	err := cmd.Run()
+	assert.OK(t, err)
+	assert.Equal(t, store.secrets["company/app/db/token"], []string{"t1", "t2"})
+	assert.Equal(t, store.secrets["company/app/.trash/20200601T120000Z/db/token"], []string(nil))
+	assert.Equal(t, store.secrets["company/app/.trash/20200601T120000Z/db/host"], []string{"localhost"})
+	assert.Equal(t, io.Out.String(), "Restored 1 secret to company/app/db/token.\n")
+	assert.Equal(t, store.rules["company/app/db"], map[string]api.Permission(nil))
+}
+
+func TestRestoreCommand_Run_Dir(t *testing.T) {
+	// Setup
+	store := newFakeSecretStore()
+	store.dirs["company/app/.trash"] = true
+	store.dirs["company/app/.trash/20200601T120000Z"] = true
+	store.dirs["company/app/.trash/20200601T120000Z/prod"] = true
+	store.secrets["company/app/.trash/20200601T120000Z/prod/token"] = []string{"t1"}
+	store.rules["company/app/.trash/20200601T120000Z/prod"] = map[string]api.Permission{"dev1": api.PermissionWrite}
+
+	io := fakeui.NewIO(t)
+	cmd := RestoreCommand{
+		io:   io,
+		path: "company/app/prod",
+		newClient: func() (secrethub.ClientInterface, error) {
+			return store.client(), nil
+		},
+	}
+
+	// Act
+	err := cmd.Run()
+
+	// Assert
+	assert.OK(t, err)
+	assert.Equal(t, store.secrets["company/app/prod/token"], []string{"t1"})
+	assert.Equal(t, store.rules, map[string]map[string]api.Permission{
+		"company/app/prod": {"dev1": api.PermissionWrite},
+	})
+	assert.Equal(t, store.dirs["company/app/.trash/20200601T120000Z"], false)
+}
+
+func TestExcludeTrash(t *testing.T) {
+	// Setup
+	rootID := uuid.New()
+	trashID := uuid.New()
+	removalID := uuid.New()
+	prodID := uuid.New()
+	removal := &api.Dir{Name: "20200601T120000Z", DirID: removalID, ParentID: &trashID}
+	trash := &api.Dir{Name: trashDirName, DirID: trashID, ParentID: &rootID, SubDirs: []*api.Dir{removal}}
+	prod := &api.Dir{Name: "prod", DirID: prodID, ParentID: &rootID}
+	root := &api.Dir{Name: "repo", DirID: rootID, SubDirs: []*api.Dir{trash, prod}}
+	secretID := uuid.New()
+	trashedID := uuid.New()
+	tree := &api.Tree{
+		ParentPath: "namespace",
+		RootDir:    root,
+		Dirs: map[uuid.UUID]*api.Dir{
+			rootID:    root,
+			trashID:   trash,
+			removalID: removal,
+			prodID:    prod,
+		},
+		Secrets: map[uuid.UUID]*api.Secret{
+			secretID:  {Name: "token", SecretID: secretID, DirID: prodID},
+			trashedID: {Name: "token", SecretID: trashedID, DirID: removalID},
+		},
+	}
+
+	// Act
+	excludeTrash(tree, "namespace/repo/prod")
+	excludeTrash(tree, "namespace/repo")
+
+	// Assert
+	assert.Equal(t, root.SubDirs, []*api.Dir{prod})
+	assert.Equal(t, len(tree.Dirs), 2)
+	assert.Equal(t, len(tree.Secrets), 1)
+	assert.Equal(t, tree.Secrets[secretID].Name, "token")
+}
+
+func TestTrashEmptyCommand_Run(t *testing.T) {
+	store := newFakeSecretStore()
+	store.dirs["company/app/.trash"] = true
+	store.dirs["company/app/.trash/20200601T120000Z"] = true
+	store.dirs["company/app/.trash/20200401T120000Z"] = true
+	store.secrets["company/app/.trash/20200601T120000Z/token"] = []string{"t1"}
+	store.secrets["company/app/.trash/20200401T120000Z/host"] = []string{"localhost"}
+
+	io := fakeui.NewIO(t)
+	cmd := TrashEmptyCommand{
+		io:        io,
+		path:      "company/app",
+		olderThan: dayDuration(30 * 24 * time.Hour),
+		force:     true,
+		now: func() time.Time {
+			return time.Date(2020, 6, 2, 12, 0, 0, 0, time.UTC)
+		},
+		newClient: func() (secrethub.ClientInterface, error) {
+			return store.client(), nil
+		},
+	}
+
+	err := cmd.Run()
+	assert.OK(t, err)
+	assert.Equal(t, store.secrets["company/app/.trash/20200601T120000Z/token"], []string{"t1"})
+	assert.Equal(t, store.secrets["company/app/.trash/20200401T120000Z/host"], []string(nil))
+	assert.Equal(t, io.Out.String(), "Permanently removed 1 secret from the trash.\n")
+}
@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"strings"
+	"time"
 
 	"github.com/secrethub/secrethub-cli/internals/cli/clip"
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
 
 	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
 )
 
 var (
@@ -17,6 +20,10 @@ var (
 	errEmptySecret                     = errMain.Code("cannot_write_empty_secret").Error("secret is empty or contains only whitespace")
 	errClipAndInFile                   = errMain.Code("clip_and_in_file").Error("clip and in-file cannot be used together")
 	errMultilineWithNonInteractiveFlag = errMain.Code("multiline_flag_conflict").Error("multiline cannot be used together with clip or in-file")
+	errSplitWithoutDocument            = errMain.Code("split_without_document").Error("split can only be used together with from-json or from-yaml")
+	errBinaryInteractive               = errMain.Code("binary_interactive").Error("binary can only be used together with in-file or piped input")
+	ErrConcurrentWrite                 = errMain.Code("concurrent_write").ErrorPref("%s was written by someone else while the value was added: version %d is based on version %d and does not contain the changes in between. Check the secret and write it again.")
+	ErrSecretChanged                   = errMain.Code("secret_changed").ErrorPref("%s was written by someone else while the value was added: the latest version is %d instead of %d. Nothing has been written, run the command again.")
 )
 
 // WriteCommand is a command to write content to a secret.
@@ -26,7 +33,15 @@ type WriteCommand struct {
 	inFile       string
 	multiline    bool
 	useClipboard bool
+	clearClip    bool
 	noTrim       bool
+	binary       bool
+	appendData   bool
+	prependData  bool
+	fromJSON     string
+	fromYAML     string
+	split        bool
+	expiresIn    dayDuration
 	clipper      clip.Clipper
 	newClient    newClientFunc
 }
@@ -45,9 +60,17 @@ func (cmd *WriteCommand) Register(r command.Registerer) {
 	clause := r.Command("write", "Write a secret.")
 	clause.Arg("secret-path", "The path to the secret").Required().PlaceHolder(secretPathPlaceHolder).SetValue(&cmd.path)
 	clause.Flag("clip", "Use clipboard content as input.").Short('c').BoolVar(&cmd.useClipboard)
+	clause.Flag("from-clipboard", "Use clipboard content as input and clear the clipboard after the secret has been written, e.g. to store a credential received out-of-band.").BoolVar(&cmd.clearClip)
 	clause.Flag("multiline", "Prompt for multiple lines of input, until an EOF is reached. On Linux/Mac, press CTRL-D to end input. On Windows, press CTRL-Z and then ENTER to end input.").Short('m').BoolVar(&cmd.multiline)
 	clause.Flag("no-trim", "Do not trim leading and trailing whitespace in the secret.").BoolVar(&cmd.noTrim)
 	clause.Flag("in-file", "Use the contents of this file as the value of the secret.").Short('i').StringVar(&cmd.inFile)
+	clause.Flag("append", "Add the value to the end of the current value of the secret, on a new line, e.g. to add an authorized key or CIDR range.").BoolVar(&cmd.appendData)
+	clause.Flag("prepend", "Add the value to the start of the current value of the secret, on a new line.").BoolVar(&cmd.prependData)
+	clause.Flag("binary", "Write the contents of --in-file or stdin exactly as they are, e.g. a keystore or license file. Implies --no-trim.").BoolVar(&cmd.binary)
+	clause.Flag("from-json", "Use the contents of this JSON file, or - to read from stdin, as the value of the secret. The contents must be valid JSON.").PlaceHolder("FILE").StringVar(&cmd.fromJSON)
+	clause.Flag("from-yaml", "Use the contents of this YAML file, or - to read from stdin, as the value of the secret. The contents must be valid YAML.").PlaceHolder("FILE").StringVar(&cmd.fromYAML)
+	clause.Flag("split", "Write every top-level key of the --from-json or --from-yaml document as a separate secret in the directory at the given path, instead of writing the document as a single secret.").BoolVar(&cmd.split)
+	clause.Flag("expires-in", "Record that the secret expires after this period, e.g. 90d. Use `"+ApplicationName+" expiring` to list the secrets that expire soon.").PlaceHolder("90d").SetValue(&cmd.expiresIn)
 
 	command.BindAction(clause, cmd.Run)
 }
@@ -63,6 +86,10 @@ func (cmd *WriteCommand) Run() error {
 		return errCannotWriteToVersion
 	}
 
+	if cmd.clearClip {
+		cmd.useClipboard = true
+	}
+
 	if cmd.multiline && (cmd.useClipboard || cmd.inFile != "") {
 		return errMultilineWithNonInteractiveFlag
 	}
@@ -71,12 +98,49 @@ func (cmd *WriteCommand) Run() error {
 		return errClipAndInFile
 	}
 
+	if cmd.fromJSON != "" && cmd.fromYAML != "" {
+		return ErrFlagsConflict("--from-json and --from-yaml")
+	}
+
+	documentFile, documentFormat := cmd.document()
+	if documentFile != "" && (cmd.useClipboard || cmd.inFile != "" || cmd.multiline) {
+		return ErrFlagsConflict("--from-json or --from-yaml and --clip, --in-file or --multiline")
+	}
+
+	if cmd.split && documentFile == "" {
+		return errSplitWithoutDocument
+	}
+
+	if cmd.appendData && cmd.prependData {
+		return ErrFlagsConflict("--append and --prepend")
+	}
+
+	if cmd.split && (cmd.appendData || cmd.prependData) {
+		return ErrFlagsConflict("--split and --append or --prepend")
+	}
+
+	if cmd.binary && (cmd.useClipboard || cmd.multiline || documentFile != "" || (cmd.inFile == "" && !cmd.io.IsInputPiped())) {
+		return errBinaryInteractive
+	}
+
 	var data []byte
-	if cmd.useClipboard {
+	var clipboardData []byte
+	if documentFile == "-" {
+		data, err = ioutil.ReadAll(cmd.io.Input())
+		if err != nil {
+			return ui.ErrReadInput(err)
+		}
+	} else if documentFile != "" {
+		data, err = ioutil.ReadFile(documentFile)
+		if err != nil {
+			return ErrReadFile(documentFile, err)
+		}
+	} else if cmd.useClipboard {
 		data, err = cmd.clipper.ReadAll()
 		if err != nil {
 			return err
 		}
+		clipboardData = data
 	} else if cmd.inFile != "" {
 		data, err = ioutil.ReadFile(cmd.inFile)
 		if err != nil {
@@ -101,12 +165,32 @@ func (cmd *WriteCommand) Run() error {
 		data = []byte(str)
 	}
 
-	if !cmd.noTrim {
+	if cmd.split {
+		values, err := splitDocument(documentFile, data, documentFormat)
+		if err != nil {
+			return err
+		}
+		return cmd.writeValues(values)
+	}
+
+	if documentFile != "" {
+		err = validateDocument(documentFile, data, documentFormat)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !cmd.noTrim && !cmd.binary {
 		// The data needs to be sanitized and trimmed for whitespace.
 		data = bytes.TrimSpace(data)
 	}
 
-	if len(bytes.TrimSpace(data)) == 0 {
+	empty := len(bytes.TrimSpace(data)) == 0
+	if cmd.binary {
+		// Binary data can consist of bytes that happen to be whitespace.
+		empty = len(data) == 0
+	}
+	if empty {
 		return errEmptySecret
 	}
 
@@ -120,15 +204,174 @@ func (cmd *WriteCommand) Run() error {
 		return err
 	}
 
-	version, err := client.Secrets().Write(cmd.path.Value(), data)
+	if cmd.appendData || cmd.prependData {
+		version, err := cmd.writeCombined(client, data)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.io.Output(), "Write complete! The given value has been added to %s:%d\n", cmd.path, version.Version)
+	} else {
+		version, err := client.Secrets().Write(cmd.path.Value(), data)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(cmd.io.Output(), "Write complete! The given value has been written to %s:%d\n", cmd.path, version.Version)
+		if err != nil {
+			return err
+		}
+	}
+
+	if cmd.clearClip {
+		cleared, err := clearClipboardIfUnchanged(clipboardData, cmd.clipper)
+		if err != nil {
+			return err
+		}
+		if cleared {
+			fmt.Fprintln(cmd.io.Output(), "The clipboard has been cleared.")
+		}
+	}
+
+	return cmd.setExpiry(client, cmd.path.Value())
+}
+
+// writeCombined writes a new version of the secret with the data appended or prepended to the latest version.
+// Writes cannot be made conditional, so a version written by someone else in the meantime is detected afterwards.
+func (cmd *WriteCommand) writeCombined(client secrethub.ClientInterface, data []byte) (*api.SecretVersion, error) {
+	latest := 0
+	combined := data
+	current, err := client.Secrets().Versions().GetWithData(cmd.path.Value())
+	if err == nil {
+		latest = current.Version
+		combined = combineSecretData(current.Data, data, cmd.prependData, !cmd.binary)
+	} else if !api.IsErrNotFound(err) {
+		return nil, err
+	}
+
+	// The API cannot write conditionally, so the latest version is checked again right before writing
+	// to refuse writes based on an outdated value. The version that is written is also checked, to
+	// report writes that happened in the remaining time between the check and the write.
+	version, err := client.Secrets().Versions().GetWithoutData(cmd.path.Value())
+	if err == nil {
+		if version.Version != latest {
+			return nil, ErrSecretChanged(cmd.path, version.Version, latest)
+		}
+	} else if !api.IsErrNotFound(err) {
+		return nil, err
+	} else if latest != 0 {
+		return nil, ErrSecretChanged(cmd.path, 0, latest)
+	}
+
+	version, err = client.Secrets().Write(cmd.path.Value(), combined)
+	if err != nil {
+		return nil, err
+	}
+
+	if version.Version != latest+1 {
+		return nil, ErrConcurrentWrite(cmd.path, version.Version, latest)
+	}
+	return version, nil
+}
+
+// combineSecretData adds data to the end of the current value, or to the start when prepend is set.
+// When lines is set, a newline is inserted between them when the first part does not end with one.
+func combineSecretData(current, data []byte, prepend bool, lines bool) []byte {
+	first, second := current, data
+	if prepend {
+		first, second = data, current
+	}
+
+	combined := make([]byte, 0, len(first)+len(second)+1)
+	combined = append(combined, first...)
+	if lines && len(first) > 0 && !bytes.HasSuffix(first, []byte("\n")) {
+		combined = append(combined, '\n')
+	}
+	return append(combined, second...)
+}
+
+// document returns the file of the --from-json or --from-yaml flag and its format.
+func (cmd *WriteCommand) document() (string, string) {
+	if cmd.fromJSON != "" {
+		return cmd.fromJSON, documentFormatJSON
+	}
+	if cmd.fromYAML != "" {
+		return cmd.fromYAML, documentFormatYAML
+	}
+	return "", ""
+}
+
+// writeValues writes the values of a split document as separate secrets in the directory at the path.
+func (cmd *WriteCommand) writeValues(values []documentValue) error {
+	paths := make([]api.SecretPath, len(values))
+	for i, value := range values {
+		path, err := documentSecretPath(cmd.path.Value(), value.key)
+		if err != nil {
+			return err
+		}
+		paths[i] = path
+
+		if !cmd.noTrim {
+			values[i].data = bytes.TrimSpace(value.data)
+		}
+		if len(bytes.TrimSpace(values[i].data)) == 0 {
+			return ErrEmptyDocumentValue(value.key)
+		}
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Writing %s...\n", pluralize("secret value", "secret values", len(values)))
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	err = client.Dirs().CreateAll(cmd.path.Value())
 	if err != nil {
 		return err
 	}
 
-	_, err = fmt.Fprintf(cmd.io.Output(), "Write complete! The given value has been written to %s:%d\n", cmd.path, version.Version)
+	written := make([]string, len(values))
+	for i, value := range values {
+		version, err := client.Secrets().Write(paths[i].Value(), value.data)
+		if err != nil {
+			return err
+		}
+		written[i] = fmt.Sprintf("%s:%d", paths[i], version.Version)
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Write complete! The given values have been written to:\n%s\n", strings.Join(written, "\n"))
+
+	secretPaths := make([]string, len(paths))
+	for i, path := range paths {
+		secretPaths[i] = path.Value()
+	}
+	return cmd.setExpiry(client, secretPaths...)
+}
+
+// setExpiry records that the secrets at the paths expire when --expires-in is set.
+func (cmd *WriteCommand) setExpiry(client secrethub.ClientInterface, paths ...string) error {
+	if cmd.expiresIn == 0 {
+		return nil
+	}
+
+	expires := time.Now().UTC().Add(time.Duration(cmd.expiresIn)).Truncate(time.Second)
+	metadata, err := readRepoMetadata(client, paths[0])
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		metadata.set(path, map[string]string{expiresLabel: expires.Format(time.RFC3339)})
+	}
+	err = writeRepoMetadata(client, paths[0], metadata)
 	if err != nil {
 		return err
 	}
 
+	if len(paths) == 1 {
+		fmt.Fprintf(cmd.io.Output(), "The secret expires at %s.\n", expires.Format(time.RFC3339))
+	} else {
+		fmt.Fprintf(cmd.io.Output(), "The secrets expire at %s.\n", expires.Format(time.RFC3339))
+	}
 	return nil
 }
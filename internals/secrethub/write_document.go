@@ -0,0 +1,146 @@
+package secrethub
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Errors
+var (
+	ErrInvalidDocument    = errMain.Code("invalid_document").ErrorPref("%s is not valid %s: %s")
+	ErrDocumentNotObject  = errMain.Code("document_not_object").ErrorPref("%s must contain an object with at least one key to split it into secrets")
+	ErrInvalidDocumentKey = errMain.Code("invalid_document_key").ErrorPref("cannot write key %q as a secret: %s")
+	ErrEmptyDocumentValue = errMain.Code("empty_document_value").ErrorPref("the value of key %q is empty or contains only whitespace")
+)
+
+const (
+	documentFormatJSON = "JSON"
+	documentFormatYAML = "YAML"
+)
+
+// documentValue is the value of a top-level key of a document, to be written as a separate secret.
+type documentValue struct {
+	key  string
+	data []byte
+}
+
+// validateDocument checks that the data is a valid document in the given format.
+func validateDocument(name string, data []byte, format string) error {
+	var err error
+	switch format {
+	case documentFormatJSON:
+		var doc interface{}
+		err = json.Unmarshal(data, &doc)
+	case documentFormatYAML:
+		var doc interface{}
+		err = yaml.Unmarshal(data, &doc)
+	}
+	if err != nil {
+		return ErrInvalidDocument(name, format, err)
+	}
+	return nil
+}
+
+// splitDocument returns the values of the top-level keys of a document, sorted by key.
+// String values are returned as is, other values are encoded in the format of the document.
+func splitDocument(name string, data []byte, format string) ([]documentValue, error) {
+	err := validateDocument(name, data, format)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []documentValue
+	switch format {
+	case documentFormatJSON:
+		values, err = splitJSON(data)
+	case documentFormatYAML:
+		values, err = splitYAML(data)
+	}
+	if err != nil || len(values) == 0 {
+		return nil, ErrDocumentNotObject(name)
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].key < values[j].key
+	})
+	return values, nil
+}
+
+func splitJSON(data []byte) ([]documentValue, error) {
+	doc := map[string]json.RawMessage{}
+	err := json.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]documentValue, 0, len(doc))
+	for key, raw := range doc {
+		value := documentValue{key: key}
+		switch {
+		case bytes.Equal(raw, []byte("null")):
+		case raw[0] == '"':
+			var str string
+			err = json.Unmarshal(raw, &str)
+			if err != nil {
+				return nil, err
+			}
+			value.data = []byte(str)
+		default:
+			var compacted bytes.Buffer
+			err = json.Compact(&compacted, raw)
+			if err != nil {
+				return nil, err
+			}
+			value.data = compacted.Bytes()
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func splitYAML(data []byte) ([]documentValue, error) {
+	var doc yaml.MapSlice
+	err := yaml.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]documentValue, 0, len(doc))
+	for _, item := range doc {
+		value := documentValue{key: fmt.Sprint(item.Key)}
+		switch v := item.Value.(type) {
+		case nil:
+		case string:
+			value.data = []byte(v)
+		case yaml.MapSlice, []interface{}:
+			out, err := yaml.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			value.data = bytes.TrimSuffix(out, []byte("\n"))
+		default:
+			value.data = []byte(fmt.Sprint(v))
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// documentSecretPath returns the path of the secret for a key of a document that is written to the directory.
+func documentSecretPath(dirPath string, key string) (api.SecretPath, error) {
+	if strings.Contains(key, "/") {
+		return "", ErrInvalidDocumentKey(key, "keys cannot contain a /")
+	}
+	path, err := api.NewSecretPath(api.JoinPaths(dirPath, key))
+	if err != nil {
+		return "", ErrInvalidDocumentKey(key, err)
+	}
+	return path, nil
+}
@@ -0,0 +1,149 @@
+package secrethub
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestSplitDocument(t *testing.T) {
+	cases := map[string]struct {
+		data     string
+		format   string
+		expected []documentValue
+		err      error
+	}{
+		"json": {
+			data:   `{"user": "app", "port": 5432, "tls": true, "hosts": ["a", "b"], "options": {"timeout": 10}}`,
+			format: documentFormatJSON,
+			expected: []documentValue{
+				{key: "hosts", data: []byte(`["a","b"]`)},
+				{key: "options", data: []byte(`{"timeout":10}`)},
+				{key: "port", data: []byte("5432")},
+				{key: "tls", data: []byte("true")},
+				{key: "user", data: []byte("app")},
+			},
+		},
+		"json null": {
+			data:   `{"user": null}`,
+			format: documentFormatJSON,
+			expected: []documentValue{
+				{key: "user"},
+			},
+		},
+		"json array": {
+			data:   `["a", "b"]`,
+			format: documentFormatJSON,
+			err:    ErrDocumentNotObject("creds.json"),
+		},
+		"json empty object": {
+			data:   `{}`,
+			format: documentFormatJSON,
+			err:    ErrDocumentNotObject("creds.json"),
+		},
+		"yaml": {
+			data:   "user: app\nport: 5432\nhosts:\n- a\n- b\noptions:\n  timeout: 10\n",
+			format: documentFormatYAML,
+			expected: []documentValue{
+				{key: "hosts", data: []byte("- a\n- b")},
+				{key: "options", data: []byte("timeout: 10")},
+				{key: "port", data: []byte("5432")},
+				{key: "user", data: []byte("app")},
+			},
+		},
+		"yaml scalar": {
+			data:   "app",
+			format: documentFormatYAML,
+			err:    ErrDocumentNotObject("creds.json"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := splitDocument("creds.json", []byte(tc.data), tc.format)
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
+
+func TestValidateDocument(t *testing.T) {
+	assert.OK(t, validateDocument("creds.json", []byte(`{"user": "app"}`), documentFormatJSON))
+	assert.Equal(t, validateDocument("creds.json", []byte(`{"user": `), documentFormatJSON) != nil, true)
+	assert.OK(t, validateDocument("creds.yml", []byte("user: app\n"), documentFormatYAML))
+	assert.Equal(t, validateDocument("creds.yml", []byte("user: [app\n"), documentFormatYAML) != nil, true)
+}
+
+func TestWriteCommand_Run_Split(t *testing.T) {
+	cases := map[string]struct {
+		in       string
+		expected map[string]string
+		out      string
+		err      error
+	}{
+		"success": {
+			in: `{"user": "app", "password": " secret\n"}`,
+			expected: map[string]string{
+				"company/app/db/password": "secret",
+				"company/app/db/user":     "app",
+			},
+			out: "Writing 2 secret values...\n" +
+				"Write complete! The given values have been written to:\n" +
+				"company/app/db/password:1\n" +
+				"company/app/db/user:1\n",
+		},
+		"empty value": {
+			in:       `{"user": "app", "password": ""}`,
+			expected: map[string]string{},
+			err:      ErrEmptyDocumentValue("password"),
+		},
+		"invalid key": {
+			in:       `{"db/user": "app"}`,
+			expected: map[string]string{},
+			err:      ErrInvalidDocumentKey("db/user", "keys cannot contain a /"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			written := map[string]string{}
+			io := fakeui.NewIO(t)
+			io.In.Piped = true
+			io.In.Buffer = bytes.NewBufferString(tc.in)
+
+			cmd := WriteCommand{
+				io:       io,
+				path:     "company/app/db",
+				fromJSON: "-",
+				split:    true,
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						DirService: &fakeclient.DirService{
+							CreateAllFunc: func(path string) error {
+								assert.Equal(t, path, "company/app/db")
+								return nil
+							},
+						},
+						SecretService: &fakeclient.SecretService{
+							WriteFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+								written[path] = string(data)
+								return &api.SecretVersion{Version: 1}, nil
+							},
+						},
+					}, nil
+				},
+			}
+
+			err := cmd.Run()
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, written, tc.expected)
+			assert.Equal(t, io.Out.String(), tc.out)
+		})
+	}
+}
@@ -192,6 +192,54 @@ func TestWriteCommand_Run(t *testing.T) {
 			},
 			err: errClipAndInFile,
 		},
+		"binary piped": {
+			cmd: WriteCommand{
+				path:   "namespace/repo/secret",
+				binary: true,
+			},
+			in:    "\x00\x01 binary\n",
+			piped: true,
+			writeFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+				return &api.SecretVersion{
+					Version: 1,
+				}, nil
+			},
+			path: "namespace/repo/secret",
+			data: []byte("\x00\x01 binary\n"),
+			out:  "Writing secret value...\nWrite complete! The given value has been written to namespace/repo/secret:1\n",
+		},
+		"binary whitespace only": {
+			cmd: WriteCommand{
+				path:   "namespace/repo/secret",
+				binary: true,
+			},
+			in:    "\n",
+			piped: true,
+			writeFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+				return &api.SecretVersion{
+					Version: 1,
+				}, nil
+			},
+			path: "namespace/repo/secret",
+			data: []byte("\n"),
+			out:  "Writing secret value...\nWrite complete! The given value has been written to namespace/repo/secret:1\n",
+		},
+		"binary not piped": {
+			cmd: WriteCommand{
+				path:   "namespace/repo/secret",
+				binary: true,
+			},
+			err: errBinaryInteractive,
+		},
+		"binary and clip": {
+			cmd: WriteCommand{
+				path:         "namespace/repo/secret",
+				binary:       true,
+				useClipboard: true,
+			},
+			piped: true,
+			err:   errBinaryInteractive,
+		},
 	}
 
 	for name, tc := range cases {
@@ -234,3 +282,178 @@ func TestWriteCommand_Run(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteCommand_Run_FromClipboard(t *testing.T) {
+	cases := map[string]struct {
+		clipboardAfterRead string
+		expectedClipboard  string
+		out                string
+	}{
+		"clipboard cleared": {
+			expectedClipboard: "",
+			out:               "Writing secret value...\nWrite complete! The given value has been written to namespace/repo/secret:1\nThe clipboard has been cleared.\n",
+		},
+		"clipboard changed before write completed": {
+			clipboardAfterRead: "copied afterwards",
+			expectedClipboard:  "copied afterwards",
+			out:                "Writing secret value...\nWrite complete! The given value has been written to namespace/repo/secret:1\n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			clipper := fakeclip.NewWithValue([]byte("secret value\n"))
+			io := fakeui.NewIO(t)
+
+			cmd := WriteCommand{
+				io:        io,
+				path:      "namespace/repo/secret",
+				clearClip: true,
+				clipper:   clipper,
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						SecretService: &fakeclient.SecretService{
+							WriteFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+								assert.Equal(t, data, []byte("secret value"))
+								if tc.clipboardAfterRead != "" {
+									err := clipper.WriteAll([]byte(tc.clipboardAfterRead))
+									assert.OK(t, err)
+								}
+								return &api.SecretVersion{Version: 1}, nil
+							},
+						},
+					}, nil
+				},
+			}
+
+			err := cmd.Run()
+			assert.OK(t, err)
+			assert.Equal(t, io.Out.String(), tc.out)
+
+			actual, err := clipper.ReadAll()
+			assert.OK(t, err)
+			assert.Equal(t, string(actual), tc.expectedClipboard)
+		})
+	}
+}
+
+func TestCombineSecretData(t *testing.T) {
+	cases := map[string]struct {
+		current  string
+		data     string
+		prepend  bool
+		lines    bool
+		expected string
+	}{
+		"append line": {
+			current:  "ssh-ed25519 AAAA alice",
+			data:     "ssh-ed25519 BBBB bob",
+			lines:    true,
+			expected: "ssh-ed25519 AAAA alice\nssh-ed25519 BBBB bob",
+		},
+		"append after newline": {
+			current:  "10.0.0.0/8\n",
+			data:     "192.168.0.0/16",
+			lines:    true,
+			expected: "10.0.0.0/8\n192.168.0.0/16",
+		},
+		"prepend line": {
+			current:  "10.0.0.0/8",
+			data:     "192.168.0.0/16",
+			prepend:  true,
+			lines:    true,
+			expected: "192.168.0.0/16\n10.0.0.0/8",
+		},
+		"append binary": {
+			current:  "\x00\x01",
+			data:     "\x02",
+			expected: "\x00\x01\x02",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual := combineSecretData([]byte(tc.current), []byte(tc.data), tc.prepend, tc.lines)
+			assert.Equal(t, string(actual), tc.expected)
+		})
+	}
+}
+
+func TestWriteCommand_Run_Append(t *testing.T) {
+	cases := map[string]struct {
+		current      *api.SecretVersion
+		getErr       error
+		latest       *api.SecretVersion
+		writeVersion int
+		expectedData string
+		out          string
+		err          error
+	}{
+		"append": {
+			current:      &api.SecretVersion{Version: 2, Data: []byte("10.0.0.0/8")},
+			writeVersion: 3,
+			expectedData: "10.0.0.0/8\n192.168.0.0/16",
+			out:          "Writing secret value...\nWrite complete! The given value has been added to namespace/repo/secret:3\n",
+		},
+		"new secret": {
+			getErr:       api.ErrSecretNotFound,
+			writeVersion: 1,
+			expectedData: "192.168.0.0/16",
+			out:          "Writing secret value...\nWrite complete! The given value has been added to namespace/repo/secret:1\n",
+		},
+		"changed before write": {
+			current: &api.SecretVersion{Version: 2, Data: []byte("10.0.0.0/8")},
+			latest:  &api.SecretVersion{Version: 3},
+			out:     "Writing secret value...\n",
+			err:     ErrSecretChanged("namespace/repo/secret", 3, 2),
+		},
+		"concurrent write": {
+			current:      &api.SecretVersion{Version: 2, Data: []byte("10.0.0.0/8")},
+			writeVersion: 4,
+			expectedData: "10.0.0.0/8\n192.168.0.0/16",
+			out:          "Writing secret value...\n",
+			err:          ErrConcurrentWrite("namespace/repo/secret", 4, 2),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var written []byte
+			io := fakeui.NewIO(t)
+			io.In.Piped = true
+			io.In.Buffer = bytes.NewBufferString("192.168.0.0/16\n")
+
+			cmd := WriteCommand{
+				io:         io,
+				path:       "namespace/repo/secret",
+				appendData: true,
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						SecretService: &fakeclient.SecretService{
+							WriteFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+								written = data
+								return &api.SecretVersion{Version: tc.writeVersion}, nil
+							},
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+									return tc.current, tc.getErr
+								},
+								GetWithoutDataFunc: func(path string) (*api.SecretVersion, error) {
+									if tc.latest != nil {
+										return tc.latest, nil
+									}
+									return tc.current, tc.getErr
+								},
+							},
+						},
+					}, nil
+				},
+			}
+
+			err := cmd.Run()
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, string(written), tc.expectedData)
+			assert.Equal(t, io.Out.String(), tc.out)
+		})
+	}
+}